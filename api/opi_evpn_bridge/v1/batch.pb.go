@@ -0,0 +1,719 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// (-- api-linter: core::0233::request-requests-behavior=disabled
+//     aip.dev/not-precedent: The batch items reuse the single item requests. --)
+// (-- api-linter: core::0235::request-names-behavior=disabled
+//     aip.dev/not-precedent: The batch items reuse the single item requests. --)
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.32.0
+// 	protoc        (unknown)
+// source: opi_evpn_bridge/v1/batch.proto
+
+package v1
+
+import (
+	_go "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+	_ "google.golang.org/genproto/googleapis/api/annotations"
+	status "google.golang.org/genproto/googleapis/rpc/status"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Create a batch of Logical Bridges
+type BatchCreateLogicalBridgesRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The Logical Bridges to create, at most 1000
+	Requests []*_go.CreateLogicalBridgeRequest `protobuf:"bytes,1,rep,name=requests,proto3" json:"requests,omitempty"`
+}
+
+func (x *BatchCreateLogicalBridgesRequest) Reset() {
+	*x = BatchCreateLogicalBridgesRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_opi_evpn_bridge_v1_batch_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BatchCreateLogicalBridgesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchCreateLogicalBridgesRequest) ProtoMessage() {}
+
+func (x *BatchCreateLogicalBridgesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_opi_evpn_bridge_v1_batch_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchCreateLogicalBridgesRequest.ProtoReflect.Descriptor instead.
+func (*BatchCreateLogicalBridgesRequest) Descriptor() ([]byte, []int) {
+	return file_opi_evpn_bridge_v1_batch_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *BatchCreateLogicalBridgesRequest) GetRequests() []*_go.CreateLogicalBridgeRequest {
+	if x != nil {
+		return x.Requests
+	}
+	return nil
+}
+
+// The outcome of the creation of a batch of Logical Bridges
+type BatchCreateLogicalBridgesResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// One status per request in the same order,
+	// a successful status carries the Logical Bridge in its details
+	Statuses []*status.Status `protobuf:"bytes,1,rep,name=statuses,proto3" json:"statuses,omitempty"`
+}
+
+func (x *BatchCreateLogicalBridgesResponse) Reset() {
+	*x = BatchCreateLogicalBridgesResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_opi_evpn_bridge_v1_batch_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BatchCreateLogicalBridgesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchCreateLogicalBridgesResponse) ProtoMessage() {}
+
+func (x *BatchCreateLogicalBridgesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_opi_evpn_bridge_v1_batch_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchCreateLogicalBridgesResponse.ProtoReflect.Descriptor instead.
+func (*BatchCreateLogicalBridgesResponse) Descriptor() ([]byte, []int) {
+	return file_opi_evpn_bridge_v1_batch_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *BatchCreateLogicalBridgesResponse) GetStatuses() []*status.Status {
+	if x != nil {
+		return x.Statuses
+	}
+	return nil
+}
+
+// Delete a batch of Logical Bridges
+type BatchDeleteLogicalBridgesRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The Logical Bridges to delete, at most 1000
+	Requests []*_go.DeleteLogicalBridgeRequest `protobuf:"bytes,1,rep,name=requests,proto3" json:"requests,omitempty"`
+}
+
+func (x *BatchDeleteLogicalBridgesRequest) Reset() {
+	*x = BatchDeleteLogicalBridgesRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_opi_evpn_bridge_v1_batch_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BatchDeleteLogicalBridgesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchDeleteLogicalBridgesRequest) ProtoMessage() {}
+
+func (x *BatchDeleteLogicalBridgesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_opi_evpn_bridge_v1_batch_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchDeleteLogicalBridgesRequest.ProtoReflect.Descriptor instead.
+func (*BatchDeleteLogicalBridgesRequest) Descriptor() ([]byte, []int) {
+	return file_opi_evpn_bridge_v1_batch_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *BatchDeleteLogicalBridgesRequest) GetRequests() []*_go.DeleteLogicalBridgeRequest {
+	if x != nil {
+		return x.Requests
+	}
+	return nil
+}
+
+// The outcome of the deletion of a batch of Logical Bridges
+type BatchDeleteLogicalBridgesResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// One status per request in the same order
+	Statuses []*status.Status `protobuf:"bytes,1,rep,name=statuses,proto3" json:"statuses,omitempty"`
+}
+
+func (x *BatchDeleteLogicalBridgesResponse) Reset() {
+	*x = BatchDeleteLogicalBridgesResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_opi_evpn_bridge_v1_batch_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BatchDeleteLogicalBridgesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchDeleteLogicalBridgesResponse) ProtoMessage() {}
+
+func (x *BatchDeleteLogicalBridgesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_opi_evpn_bridge_v1_batch_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchDeleteLogicalBridgesResponse.ProtoReflect.Descriptor instead.
+func (*BatchDeleteLogicalBridgesResponse) Descriptor() ([]byte, []int) {
+	return file_opi_evpn_bridge_v1_batch_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *BatchDeleteLogicalBridgesResponse) GetStatuses() []*status.Status {
+	if x != nil {
+		return x.Statuses
+	}
+	return nil
+}
+
+// Create a batch of Bridge Ports
+type BatchCreateBridgePortsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The Bridge Ports to create, at most 1000
+	Requests []*_go.CreateBridgePortRequest `protobuf:"bytes,1,rep,name=requests,proto3" json:"requests,omitempty"`
+}
+
+func (x *BatchCreateBridgePortsRequest) Reset() {
+	*x = BatchCreateBridgePortsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_opi_evpn_bridge_v1_batch_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BatchCreateBridgePortsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchCreateBridgePortsRequest) ProtoMessage() {}
+
+func (x *BatchCreateBridgePortsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_opi_evpn_bridge_v1_batch_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchCreateBridgePortsRequest.ProtoReflect.Descriptor instead.
+func (*BatchCreateBridgePortsRequest) Descriptor() ([]byte, []int) {
+	return file_opi_evpn_bridge_v1_batch_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *BatchCreateBridgePortsRequest) GetRequests() []*_go.CreateBridgePortRequest {
+	if x != nil {
+		return x.Requests
+	}
+	return nil
+}
+
+// The outcome of the creation of a batch of Bridge Ports
+type BatchCreateBridgePortsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// One status per request in the same order,
+	// a successful status carries the Bridge Port in its details
+	Statuses []*status.Status `protobuf:"bytes,1,rep,name=statuses,proto3" json:"statuses,omitempty"`
+}
+
+func (x *BatchCreateBridgePortsResponse) Reset() {
+	*x = BatchCreateBridgePortsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_opi_evpn_bridge_v1_batch_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BatchCreateBridgePortsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchCreateBridgePortsResponse) ProtoMessage() {}
+
+func (x *BatchCreateBridgePortsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_opi_evpn_bridge_v1_batch_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchCreateBridgePortsResponse.ProtoReflect.Descriptor instead.
+func (*BatchCreateBridgePortsResponse) Descriptor() ([]byte, []int) {
+	return file_opi_evpn_bridge_v1_batch_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *BatchCreateBridgePortsResponse) GetStatuses() []*status.Status {
+	if x != nil {
+		return x.Statuses
+	}
+	return nil
+}
+
+// Delete a batch of Bridge Ports
+type BatchDeleteBridgePortsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The Bridge Ports to delete, at most 1000
+	Requests []*_go.DeleteBridgePortRequest `protobuf:"bytes,1,rep,name=requests,proto3" json:"requests,omitempty"`
+}
+
+func (x *BatchDeleteBridgePortsRequest) Reset() {
+	*x = BatchDeleteBridgePortsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_opi_evpn_bridge_v1_batch_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BatchDeleteBridgePortsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchDeleteBridgePortsRequest) ProtoMessage() {}
+
+func (x *BatchDeleteBridgePortsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_opi_evpn_bridge_v1_batch_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchDeleteBridgePortsRequest.ProtoReflect.Descriptor instead.
+func (*BatchDeleteBridgePortsRequest) Descriptor() ([]byte, []int) {
+	return file_opi_evpn_bridge_v1_batch_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *BatchDeleteBridgePortsRequest) GetRequests() []*_go.DeleteBridgePortRequest {
+	if x != nil {
+		return x.Requests
+	}
+	return nil
+}
+
+// The outcome of the deletion of a batch of Bridge Ports
+type BatchDeleteBridgePortsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// One status per request in the same order
+	Statuses []*status.Status `protobuf:"bytes,1,rep,name=statuses,proto3" json:"statuses,omitempty"`
+}
+
+func (x *BatchDeleteBridgePortsResponse) Reset() {
+	*x = BatchDeleteBridgePortsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_opi_evpn_bridge_v1_batch_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BatchDeleteBridgePortsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchDeleteBridgePortsResponse) ProtoMessage() {}
+
+func (x *BatchDeleteBridgePortsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_opi_evpn_bridge_v1_batch_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchDeleteBridgePortsResponse.ProtoReflect.Descriptor instead.
+func (*BatchDeleteBridgePortsResponse) Descriptor() ([]byte, []int) {
+	return file_opi_evpn_bridge_v1_batch_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *BatchDeleteBridgePortsResponse) GetStatuses() []*status.Status {
+	if x != nil {
+		return x.Statuses
+	}
+	return nil
+}
+
+var File_opi_evpn_bridge_v1_batch_proto protoreflect.FileDescriptor
+
+var file_opi_evpn_bridge_v1_batch_proto_rawDesc = []byte{
+	0x0a, 0x1e, 0x6f, 0x70, 0x69, 0x5f, 0x65, 0x76, 0x70, 0x6e, 0x5f, 0x62, 0x72, 0x69, 0x64, 0x67,
+	0x65, 0x2f, 0x76, 0x31, 0x2f, 0x62, 0x61, 0x74, 0x63, 0x68, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x12, 0x12, 0x6f, 0x70, 0x69, 0x5f, 0x65, 0x76, 0x70, 0x6e, 0x5f, 0x62, 0x72, 0x69, 0x64, 0x67,
+	0x65, 0x2e, 0x76, 0x31, 0x1a, 0x16, 0x6c, 0x32, 0x5f, 0x78, 0x70, 0x75, 0x5f, 0x69, 0x6e, 0x66,
+	0x72, 0x61, 0x5f, 0x6d, 0x67, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1c, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x61, 0x6e, 0x6e, 0x6f, 0x74, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x17, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2f, 0x72, 0x70, 0x63, 0x2f, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x22, 0x7c, 0x0a, 0x20, 0x42, 0x61, 0x74, 0x63, 0x68, 0x43, 0x72, 0x65, 0x61,
+	0x74, 0x65, 0x4c, 0x6f, 0x67, 0x69, 0x63, 0x61, 0x6c, 0x42, 0x72, 0x69, 0x64, 0x67, 0x65, 0x73,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x58, 0x0a, 0x08, 0x72, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x3c, 0x2e, 0x6f, 0x70, 0x69, 0x5f,
+	0x61, 0x70, 0x69, 0x2e, 0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x2e, 0x65, 0x76, 0x70, 0x6e,
+	0x5f, 0x67, 0x77, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x43, 0x72, 0x65,
+	0x61, 0x74, 0x65, 0x4c, 0x6f, 0x67, 0x69, 0x63, 0x61, 0x6c, 0x42, 0x72, 0x69, 0x64, 0x67, 0x65,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x52, 0x08, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x73, 0x22, 0x53, 0x0a, 0x21, 0x42, 0x61, 0x74, 0x63, 0x68, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65,
+	0x4c, 0x6f, 0x67, 0x69, 0x63, 0x61, 0x6c, 0x42, 0x72, 0x69, 0x64, 0x67, 0x65, 0x73, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2e, 0x0a, 0x08, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73,
+	0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x08, 0x73, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x65, 0x73, 0x22, 0x7c, 0x0a, 0x20, 0x42, 0x61, 0x74, 0x63, 0x68, 0x44,
+	0x65, 0x6c, 0x65, 0x74, 0x65, 0x4c, 0x6f, 0x67, 0x69, 0x63, 0x61, 0x6c, 0x42, 0x72, 0x69, 0x64,
+	0x67, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x58, 0x0a, 0x08, 0x72, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x3c, 0x2e, 0x6f,
+	0x70, 0x69, 0x5f, 0x61, 0x70, 0x69, 0x2e, 0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x2e, 0x65,
+	0x76, 0x70, 0x6e, 0x5f, 0x67, 0x77, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e,
+	0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x4c, 0x6f, 0x67, 0x69, 0x63, 0x61, 0x6c, 0x42, 0x72, 0x69,
+	0x64, 0x67, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x52, 0x08, 0x72, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x73, 0x22, 0x53, 0x0a, 0x21, 0x42, 0x61, 0x74, 0x63, 0x68, 0x44, 0x65, 0x6c,
+	0x65, 0x74, 0x65, 0x4c, 0x6f, 0x67, 0x69, 0x63, 0x61, 0x6c, 0x42, 0x72, 0x69, 0x64, 0x67, 0x65,
+	0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2e, 0x0a, 0x08, 0x73, 0x74, 0x61,
+	0x74, 0x75, 0x73, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52,
+	0x08, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x65, 0x73, 0x22, 0x76, 0x0a, 0x1d, 0x42, 0x61, 0x74,
+	0x63, 0x68, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x42, 0x72, 0x69, 0x64, 0x67, 0x65, 0x50, 0x6f,
+	0x72, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x55, 0x0a, 0x08, 0x72, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x39, 0x2e, 0x6f,
+	0x70, 0x69, 0x5f, 0x61, 0x70, 0x69, 0x2e, 0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x2e, 0x65,
+	0x76, 0x70, 0x6e, 0x5f, 0x67, 0x77, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e,
+	0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x42, 0x72, 0x69, 0x64, 0x67, 0x65, 0x50, 0x6f, 0x72, 0x74,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x52, 0x08, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x73, 0x22, 0x50, 0x0a, 0x1e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65,
+	0x42, 0x72, 0x69, 0x64, 0x67, 0x65, 0x50, 0x6f, 0x72, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x2e, 0x0a, 0x08, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x65, 0x73, 0x18,
+	0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x72,
+	0x70, 0x63, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x08, 0x73, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x65, 0x73, 0x22, 0x76, 0x0a, 0x1d, 0x42, 0x61, 0x74, 0x63, 0x68, 0x44, 0x65, 0x6c, 0x65,
+	0x74, 0x65, 0x42, 0x72, 0x69, 0x64, 0x67, 0x65, 0x50, 0x6f, 0x72, 0x74, 0x73, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x55, 0x0a, 0x08, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x73,
+	0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x39, 0x2e, 0x6f, 0x70, 0x69, 0x5f, 0x61, 0x70, 0x69,
+	0x2e, 0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x2e, 0x65, 0x76, 0x70, 0x6e, 0x5f, 0x67, 0x77,
+	0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65,
+	0x42, 0x72, 0x69, 0x64, 0x67, 0x65, 0x50, 0x6f, 0x72, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x52, 0x08, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x73, 0x22, 0x50, 0x0a, 0x1e, 0x42,
+	0x61, 0x74, 0x63, 0x68, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x42, 0x72, 0x69, 0x64, 0x67, 0x65,
+	0x50, 0x6f, 0x72, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2e, 0x0a,
+	0x08, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x12, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x53, 0x74, 0x61,
+	0x74, 0x75, 0x73, 0x52, 0x08, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x65, 0x73, 0x32, 0x87, 0x03,
+	0x0a, 0x19, 0x4c, 0x6f, 0x67, 0x69, 0x63, 0x61, 0x6c, 0x42, 0x72, 0x69, 0x64, 0x67, 0x65, 0x42,
+	0x61, 0x74, 0x63, 0x68, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0xb3, 0x01, 0x0a, 0x19,
+	0x42, 0x61, 0x74, 0x63, 0x68, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4c, 0x6f, 0x67, 0x69, 0x63,
+	0x61, 0x6c, 0x42, 0x72, 0x69, 0x64, 0x67, 0x65, 0x73, 0x12, 0x34, 0x2e, 0x6f, 0x70, 0x69, 0x5f,
+	0x65, 0x76, 0x70, 0x6e, 0x5f, 0x62, 0x72, 0x69, 0x64, 0x67, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x42,
+	0x61, 0x74, 0x63, 0x68, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4c, 0x6f, 0x67, 0x69, 0x63, 0x61,
+	0x6c, 0x42, 0x72, 0x69, 0x64, 0x67, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x35, 0x2e, 0x6f, 0x70, 0x69, 0x5f, 0x65, 0x76, 0x70, 0x6e, 0x5f, 0x62, 0x72, 0x69, 0x64, 0x67,
+	0x65, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65,
+	0x4c, 0x6f, 0x67, 0x69, 0x63, 0x61, 0x6c, 0x42, 0x72, 0x69, 0x64, 0x67, 0x65, 0x73, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x29, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x23, 0x3a, 0x01,
+	0x2a, 0x22, 0x1e, 0x2f, 0x76, 0x31, 0x2f, 0x6c, 0x6f, 0x67, 0x69, 0x63, 0x61, 0x6c, 0x42, 0x72,
+	0x69, 0x64, 0x67, 0x65, 0x73, 0x3a, 0x62, 0x61, 0x74, 0x63, 0x68, 0x43, 0x72, 0x65, 0x61, 0x74,
+	0x65, 0x12, 0xb3, 0x01, 0x0a, 0x19, 0x42, 0x61, 0x74, 0x63, 0x68, 0x44, 0x65, 0x6c, 0x65, 0x74,
+	0x65, 0x4c, 0x6f, 0x67, 0x69, 0x63, 0x61, 0x6c, 0x42, 0x72, 0x69, 0x64, 0x67, 0x65, 0x73, 0x12,
+	0x34, 0x2e, 0x6f, 0x70, 0x69, 0x5f, 0x65, 0x76, 0x70, 0x6e, 0x5f, 0x62, 0x72, 0x69, 0x64, 0x67,
+	0x65, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65,
+	0x4c, 0x6f, 0x67, 0x69, 0x63, 0x61, 0x6c, 0x42, 0x72, 0x69, 0x64, 0x67, 0x65, 0x73, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x35, 0x2e, 0x6f, 0x70, 0x69, 0x5f, 0x65, 0x76, 0x70, 0x6e,
+	0x5f, 0x62, 0x72, 0x69, 0x64, 0x67, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x61, 0x74, 0x63, 0x68,
+	0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x4c, 0x6f, 0x67, 0x69, 0x63, 0x61, 0x6c, 0x42, 0x72, 0x69,
+	0x64, 0x67, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x29, 0x82, 0xd3,
+	0xe4, 0x93, 0x02, 0x23, 0x3a, 0x01, 0x2a, 0x22, 0x1e, 0x2f, 0x76, 0x31, 0x2f, 0x6c, 0x6f, 0x67,
+	0x69, 0x63, 0x61, 0x6c, 0x42, 0x72, 0x69, 0x64, 0x67, 0x65, 0x73, 0x3a, 0x62, 0x61, 0x74, 0x63,
+	0x68, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x32, 0xec, 0x02, 0x0a, 0x16, 0x42, 0x72, 0x69, 0x64,
+	0x67, 0x65, 0x50, 0x6f, 0x72, 0x74, 0x42, 0x61, 0x74, 0x63, 0x68, 0x53, 0x65, 0x72, 0x76, 0x69,
+	0x63, 0x65, 0x12, 0xa7, 0x01, 0x0a, 0x16, 0x42, 0x61, 0x74, 0x63, 0x68, 0x43, 0x72, 0x65, 0x61,
+	0x74, 0x65, 0x42, 0x72, 0x69, 0x64, 0x67, 0x65, 0x50, 0x6f, 0x72, 0x74, 0x73, 0x12, 0x31, 0x2e,
+	0x6f, 0x70, 0x69, 0x5f, 0x65, 0x76, 0x70, 0x6e, 0x5f, 0x62, 0x72, 0x69, 0x64, 0x67, 0x65, 0x2e,
+	0x76, 0x31, 0x2e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x42, 0x72,
+	0x69, 0x64, 0x67, 0x65, 0x50, 0x6f, 0x72, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x32, 0x2e, 0x6f, 0x70, 0x69, 0x5f, 0x65, 0x76, 0x70, 0x6e, 0x5f, 0x62, 0x72, 0x69, 0x64,
+	0x67, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x43, 0x72, 0x65, 0x61, 0x74,
+	0x65, 0x42, 0x72, 0x69, 0x64, 0x67, 0x65, 0x50, 0x6f, 0x72, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x22, 0x26, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x20, 0x3a, 0x01, 0x2a, 0x22,
+	0x1b, 0x2f, 0x76, 0x31, 0x2f, 0x62, 0x72, 0x69, 0x64, 0x67, 0x65, 0x50, 0x6f, 0x72, 0x74, 0x73,
+	0x3a, 0x62, 0x61, 0x74, 0x63, 0x68, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x12, 0xa7, 0x01, 0x0a,
+	0x16, 0x42, 0x61, 0x74, 0x63, 0x68, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x42, 0x72, 0x69, 0x64,
+	0x67, 0x65, 0x50, 0x6f, 0x72, 0x74, 0x73, 0x12, 0x31, 0x2e, 0x6f, 0x70, 0x69, 0x5f, 0x65, 0x76,
+	0x70, 0x6e, 0x5f, 0x62, 0x72, 0x69, 0x64, 0x67, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x61, 0x74,
+	0x63, 0x68, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x42, 0x72, 0x69, 0x64, 0x67, 0x65, 0x50, 0x6f,
+	0x72, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x32, 0x2e, 0x6f, 0x70, 0x69,
+	0x5f, 0x65, 0x76, 0x70, 0x6e, 0x5f, 0x62, 0x72, 0x69, 0x64, 0x67, 0x65, 0x2e, 0x76, 0x31, 0x2e,
+	0x42, 0x61, 0x74, 0x63, 0x68, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x42, 0x72, 0x69, 0x64, 0x67,
+	0x65, 0x50, 0x6f, 0x72, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x26,
+	0x82, 0xd3, 0xe4, 0x93, 0x02, 0x20, 0x3a, 0x01, 0x2a, 0x22, 0x1b, 0x2f, 0x76, 0x31, 0x2f, 0x62,
+	0x72, 0x69, 0x64, 0x67, 0x65, 0x50, 0x6f, 0x72, 0x74, 0x73, 0x3a, 0x62, 0x61, 0x74, 0x63, 0x68,
+	0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x42, 0x41, 0x5a, 0x3f, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62,
+	0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6f, 0x70, 0x69, 0x70, 0x72, 0x6f, 0x6a, 0x65, 0x63, 0x74, 0x2f,
+	0x6f, 0x70, 0x69, 0x2d, 0x65, 0x76, 0x70, 0x6e, 0x2d, 0x62, 0x72, 0x69, 0x64, 0x67, 0x65, 0x2f,
+	0x61, 0x70, 0x69, 0x2f, 0x6f, 0x70, 0x69, 0x5f, 0x65, 0x76, 0x70, 0x6e, 0x5f, 0x62, 0x72, 0x69,
+	0x64, 0x67, 0x65, 0x2f, 0x76, 0x31, 0x3b, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x33,
+}
+
+var (
+	file_opi_evpn_bridge_v1_batch_proto_rawDescOnce sync.Once
+	file_opi_evpn_bridge_v1_batch_proto_rawDescData = file_opi_evpn_bridge_v1_batch_proto_rawDesc
+)
+
+func file_opi_evpn_bridge_v1_batch_proto_rawDescGZIP() []byte {
+	file_opi_evpn_bridge_v1_batch_proto_rawDescOnce.Do(func() {
+		file_opi_evpn_bridge_v1_batch_proto_rawDescData = protoimpl.X.CompressGZIP(file_opi_evpn_bridge_v1_batch_proto_rawDescData)
+	})
+	return file_opi_evpn_bridge_v1_batch_proto_rawDescData
+}
+
+var file_opi_evpn_bridge_v1_batch_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_opi_evpn_bridge_v1_batch_proto_goTypes = []interface{}{
+	(*BatchCreateLogicalBridgesRequest)(nil),  // 0: opi_evpn_bridge.v1.BatchCreateLogicalBridgesRequest
+	(*BatchCreateLogicalBridgesResponse)(nil), // 1: opi_evpn_bridge.v1.BatchCreateLogicalBridgesResponse
+	(*BatchDeleteLogicalBridgesRequest)(nil),  // 2: opi_evpn_bridge.v1.BatchDeleteLogicalBridgesRequest
+	(*BatchDeleteLogicalBridgesResponse)(nil), // 3: opi_evpn_bridge.v1.BatchDeleteLogicalBridgesResponse
+	(*BatchCreateBridgePortsRequest)(nil),     // 4: opi_evpn_bridge.v1.BatchCreateBridgePortsRequest
+	(*BatchCreateBridgePortsResponse)(nil),    // 5: opi_evpn_bridge.v1.BatchCreateBridgePortsResponse
+	(*BatchDeleteBridgePortsRequest)(nil),     // 6: opi_evpn_bridge.v1.BatchDeleteBridgePortsRequest
+	(*BatchDeleteBridgePortsResponse)(nil),    // 7: opi_evpn_bridge.v1.BatchDeleteBridgePortsResponse
+	(*_go.CreateLogicalBridgeRequest)(nil),    // 8: opi_api.network.evpn_gw.v1alpha1.CreateLogicalBridgeRequest
+	(*status.Status)(nil),                     // 9: google.rpc.Status
+	(*_go.DeleteLogicalBridgeRequest)(nil),    // 10: opi_api.network.evpn_gw.v1alpha1.DeleteLogicalBridgeRequest
+	(*_go.CreateBridgePortRequest)(nil),       // 11: opi_api.network.evpn_gw.v1alpha1.CreateBridgePortRequest
+	(*_go.DeleteBridgePortRequest)(nil),       // 12: opi_api.network.evpn_gw.v1alpha1.DeleteBridgePortRequest
+}
+var file_opi_evpn_bridge_v1_batch_proto_depIdxs = []int32{
+	8,  // 0: opi_evpn_bridge.v1.BatchCreateLogicalBridgesRequest.requests:type_name -> opi_api.network.evpn_gw.v1alpha1.CreateLogicalBridgeRequest
+	9,  // 1: opi_evpn_bridge.v1.BatchCreateLogicalBridgesResponse.statuses:type_name -> google.rpc.Status
+	10, // 2: opi_evpn_bridge.v1.BatchDeleteLogicalBridgesRequest.requests:type_name -> opi_api.network.evpn_gw.v1alpha1.DeleteLogicalBridgeRequest
+	9,  // 3: opi_evpn_bridge.v1.BatchDeleteLogicalBridgesResponse.statuses:type_name -> google.rpc.Status
+	11, // 4: opi_evpn_bridge.v1.BatchCreateBridgePortsRequest.requests:type_name -> opi_api.network.evpn_gw.v1alpha1.CreateBridgePortRequest
+	9,  // 5: opi_evpn_bridge.v1.BatchCreateBridgePortsResponse.statuses:type_name -> google.rpc.Status
+	12, // 6: opi_evpn_bridge.v1.BatchDeleteBridgePortsRequest.requests:type_name -> opi_api.network.evpn_gw.v1alpha1.DeleteBridgePortRequest
+	9,  // 7: opi_evpn_bridge.v1.BatchDeleteBridgePortsResponse.statuses:type_name -> google.rpc.Status
+	0,  // 8: opi_evpn_bridge.v1.LogicalBridgeBatchService.BatchCreateLogicalBridges:input_type -> opi_evpn_bridge.v1.BatchCreateLogicalBridgesRequest
+	2,  // 9: opi_evpn_bridge.v1.LogicalBridgeBatchService.BatchDeleteLogicalBridges:input_type -> opi_evpn_bridge.v1.BatchDeleteLogicalBridgesRequest
+	4,  // 10: opi_evpn_bridge.v1.BridgePortBatchService.BatchCreateBridgePorts:input_type -> opi_evpn_bridge.v1.BatchCreateBridgePortsRequest
+	6,  // 11: opi_evpn_bridge.v1.BridgePortBatchService.BatchDeleteBridgePorts:input_type -> opi_evpn_bridge.v1.BatchDeleteBridgePortsRequest
+	1,  // 12: opi_evpn_bridge.v1.LogicalBridgeBatchService.BatchCreateLogicalBridges:output_type -> opi_evpn_bridge.v1.BatchCreateLogicalBridgesResponse
+	3,  // 13: opi_evpn_bridge.v1.LogicalBridgeBatchService.BatchDeleteLogicalBridges:output_type -> opi_evpn_bridge.v1.BatchDeleteLogicalBridgesResponse
+	5,  // 14: opi_evpn_bridge.v1.BridgePortBatchService.BatchCreateBridgePorts:output_type -> opi_evpn_bridge.v1.BatchCreateBridgePortsResponse
+	7,  // 15: opi_evpn_bridge.v1.BridgePortBatchService.BatchDeleteBridgePorts:output_type -> opi_evpn_bridge.v1.BatchDeleteBridgePortsResponse
+	12, // [12:16] is the sub-list for method output_type
+	8,  // [8:12] is the sub-list for method input_type
+	8,  // [8:8] is the sub-list for extension type_name
+	8,  // [8:8] is the sub-list for extension extendee
+	0,  // [0:8] is the sub-list for field type_name
+}
+
+func init() { file_opi_evpn_bridge_v1_batch_proto_init() }
+func file_opi_evpn_bridge_v1_batch_proto_init() {
+	if File_opi_evpn_bridge_v1_batch_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_opi_evpn_bridge_v1_batch_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BatchCreateLogicalBridgesRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_opi_evpn_bridge_v1_batch_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BatchCreateLogicalBridgesResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_opi_evpn_bridge_v1_batch_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BatchDeleteLogicalBridgesRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_opi_evpn_bridge_v1_batch_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BatchDeleteLogicalBridgesResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_opi_evpn_bridge_v1_batch_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BatchCreateBridgePortsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_opi_evpn_bridge_v1_batch_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BatchCreateBridgePortsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_opi_evpn_bridge_v1_batch_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BatchDeleteBridgePortsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_opi_evpn_bridge_v1_batch_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BatchDeleteBridgePortsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_opi_evpn_bridge_v1_batch_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   2,
+		},
+		GoTypes:           file_opi_evpn_bridge_v1_batch_proto_goTypes,
+		DependencyIndexes: file_opi_evpn_bridge_v1_batch_proto_depIdxs,
+		MessageInfos:      file_opi_evpn_bridge_v1_batch_proto_msgTypes,
+	}.Build()
+	File_opi_evpn_bridge_v1_batch_proto = out.File
+	file_opi_evpn_bridge_v1_batch_proto_rawDesc = nil
+	file_opi_evpn_bridge_v1_batch_proto_goTypes = nil
+	file_opi_evpn_bridge_v1_batch_proto_depIdxs = nil
+}
@@ -8,23 +8,35 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"io"
-	"log"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
+	"cloud.google.com/go/longrunning/autogen/longrunningpb"
 	pc "github.com/opiproject/opi-api/inventory/v1/gen/go"
 	pe "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+	epb "github.com/opiproject/opi-evpn-bridge/api/opi_evpn_bridge/v1"
+	"github.com/opiproject/opi-evpn-bridge/pkg/audit"
 	"github.com/opiproject/opi-evpn-bridge/pkg/bridge"
 	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/health"
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/taskmanager"
+	"github.com/opiproject/opi-evpn-bridge/pkg/logging"
+	"github.com/opiproject/opi-evpn-bridge/pkg/metrics"
+	"github.com/opiproject/opi-evpn-bridge/pkg/modules"
+	"github.com/opiproject/opi-evpn-bridge/pkg/operations"
 	"github.com/opiproject/opi-evpn-bridge/pkg/port"
 	"github.com/opiproject/opi-evpn-bridge/pkg/svi"
 	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
@@ -32,66 +44,149 @@ import (
 	"github.com/opiproject/opi-smbios-bridge/pkg/inventory"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"go.uber.org/zap"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 
-	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/logging"
+	grpclogging "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/logging"
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
-	ci_linux "github.com/opiproject/opi-evpn-bridge/pkg/LinuxCIModule"
-	gen_linux "github.com/opiproject/opi-evpn-bridge/pkg/LinuxGeneralModule"
-	frr "github.com/opiproject/opi-evpn-bridge/pkg/frr"
+	// The modules register themselves for the build environments they belong to
+	_ "github.com/opiproject/opi-evpn-bridge/pkg/LinuxCIModule"
+	_ "github.com/opiproject/opi-evpn-bridge/pkg/LinuxGeneralModule"
+	_ "github.com/opiproject/opi-evpn-bridge/pkg/frr"
+	"github.com/opiproject/opi-evpn-bridge/pkg/netlink"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 )
 
+// logger is the logger of the grpc subsystem, which also covers the startup, the reloads and the shutdown of the bridge
+var logger = logging.Logger(logging.Grpc).Sugar()
+
 var rootCmd = &cobra.Command{
 	Use:   "opi-evpn-bridge",
 	Short: "evpn bridge",
 	Long:  "evpn bridge application",
 
+	PreRun: func(_ *cobra.Command, _ []string) {
+		// setup file and console logger
+		setupLogger(logfile)
+		config.Initcfg()
+	},
+
 	Run: func(_ *cobra.Command, _ []string) {
 
 		taskmanager.TaskMan.StartTaskManager()
 
+		utils.SetPageTokenKey(config.GlobalConfig.PageTokenKey)
+
+		if err := logging.Initialize(logLevels(&config.GlobalConfig)); err != nil {
+			logger.Panicw("Failed to apply the log levels", "error", err)
+		}
+
+		if err := audit.Initialize(config.GlobalConfig.Audit); err != nil {
+			logger.Panicw("Failed to initialize the audit trail", "error", err)
+		}
+		// The failed and dropped realizations are recorded from the task results
+		taskmanager.TaskMan.AddResultListener(infradb.AuditTaskResult)
+
 		err := infradb.NewInfraDB(config.GlobalConfig.DBAddress, config.GlobalConfig.Database)
 		if err != nil {
-			log.Panicf("Error: %v", err)
+			logger.Panicw("Failed to connect to infradb", "address", config.GlobalConfig.DBAddress, "database", config.GlobalConfig.Database, "error", err)
+		}
+		monitor := newHealthMonitor()
+		go runGatewayServer(config.GlobalConfig.GRPCPort, config.GlobalConfig.HTTPPort, config.GlobalConfig.TLSFiles, config.GlobalConfig.GatewayTLSFiles, monitor)
+
+		if err := modules.Initialize(config.GlobalConfig.Buildenv, config.GlobalConfig.Modules); err != nil {
+			logger.Panicw("Failed to initialize the modules", "buildenv", config.GlobalConfig.Buildenv, "error", err)
 		}
-		go runGatewayServer(config.GlobalConfig.GRPCPort, config.GlobalConfig.HTTPPort)
-
-		switch config.GlobalConfig.Buildenv {
-		case "ci":
-			gen_linux.Initialize()
-			ci_linux.Initialize()
-			frr.Initialize()
-		default:
-			log.Panic(" ERROR: Could not find Build env ")
+
+		// The objects stored by a previous run are realized again, or adopted when preserved
+		if err := infradb.ReplayStored(); err != nil {
+			logger.Panicw("Failed to replay the stored objects", "error", err)
 		}
 
 		// Create GRD VRF configuration during startup
 		if err := createGrdVrf(); err != nil {
-			log.Panicf("Error: %v", err)
+			logger.Panicw("Failed to create the GRD VRF", logging.Kind("vrf"), logging.Name(grdVrfName), "error", err)
 		}
-		runGrpcServer(config.GlobalConfig.GRPCPort, config.GlobalConfig.TLSFiles)
+
+		config.OnReload(applyReloadedConfig)
+		config.Watch()
+		go monitor.Run(context.Background())
+		runGrpcServer(config.GlobalConfig.GRPCPort, config.GlobalConfig.TLSFiles, monitor)
 
 	},
 }
 
+// logLevels maps the logging subsystems to their level in the config
+func logLevels(cfg *config.Config) map[string]string {
+	return map[string]string{
+		logging.DB:      cfg.LogLevel.DB,
+		logging.Grpc:    cfg.LogLevel.Grpc,
+		logging.Linux:   cfg.LogLevel.Linux,
+		logging.Netlink: cfg.LogLevel.Netlink,
+		logging.P4:      cfg.LogLevel.P4,
+	}
+}
+
+// applyReloadedConfig applies the log levels and the subscriber priorities of the reloaded config
+func applyReloadedConfig(cfg *config.Config) {
+	if err := logging.Initialize(logLevels(cfg)); err != nil {
+		logger.Errorw("Failed to apply the log levels", "error", err)
+	}
+	for _, subscriberConfig := range cfg.Subscribers {
+		for _, eventType := range subscriberConfig.Events {
+			eventbus.EBus.SetPriority(subscriberConfig.Name, eventType, subscriberConfig.Priority)
+		}
+	}
+}
+
+// validateConfigCmd checks the config file without starting the bridge
+var validateConfigCmd = &cobra.Command{
+	Use:   "validate-config",
+	Short: "Validate the config file",
+	Long:  "Validate the config file against the schema, the supported values and the interfaces of the host",
+
+	Run: func(_ *cobra.Command, _ []string) {
+		err := config.ValidateFile(config.GlobalConfig.CfgFile)
+		var validationErr *config.ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			fmt.Printf("%s is not valid:\n", config.GlobalConfig.CfgFile)
+			for _, problem := range validationErr.Problems {
+				fmt.Printf("  - %s\n", problem)
+			}
+			os.Exit(1)
+		case err != nil:
+			fmt.Printf("Failed to validate %s: %v\n", config.GlobalConfig.CfgFile, err)
+			os.Exit(1)
+		}
+		fmt.Printf("%s is valid\n", config.GlobalConfig.CfgFile)
+		os.Exit(0)
+	},
+}
+
 // initialize the cobra configuration and bind the flags
 func initialize() error {
-	cobra.OnInitialize(config.Initcfg)
+	// The config is loaded by the root command only, validate-config reports its problems instead
+	rootCmd.AddCommand(validateConfigCmd)
 
 	rootCmd.PersistentFlags().StringVarP(&config.GlobalConfig.CfgFile, "config", "c", "config.yaml", "config file path")
 	rootCmd.PersistentFlags().Uint16Var(&config.GlobalConfig.GRPCPort, "grpcport", 50151, "The gRPC server port")
 	rootCmd.PersistentFlags().Uint16Var(&config.GlobalConfig.HTTPPort, "httpport", 8082, "The HTTP server port")
 	rootCmd.PersistentFlags().StringVar(&config.GlobalConfig.TLSFiles, "tlsfiles", "", "TLS files in server_cert:server_key:ca_cert format.")
+	rootCmd.PersistentFlags().StringVar(&config.GlobalConfig.GatewayTLSFiles, "gatewaytlsfiles", "",
+		"TLS files of the HTTP gateway client identity in client_cert:client_key:ca_cert format, required with tlsfiles.")
 	rootCmd.PersistentFlags().StringVar(&config.GlobalConfig.DBAddress, "dbaddress", "127.0.0.1:6379", "db address in ip_address:port format")
 	rootCmd.PersistentFlags().StringVar(&config.GlobalConfig.Database, "database", "redis", "Database connection string")
+	rootCmd.PersistentFlags().StringVar(&config.GlobalConfig.ShutdownMode, "shutdownmode", config.ShutdownCleanup,
+		"cleanup deletes the realized objects on shutdown, preserve leaves the kernel and FRR state in place")
 
 	// Bind command-line flags to config fields
 	if err := viper.GetViper().BindPFlags(rootCmd.PersistentFlags()); err != nil {
-		log.Printf("Error binding flags to Viper: %v\n", err)
+		logger.Errorw("Failed to bind the flags to the config", "error", err)
 		return err
 	}
 
@@ -100,126 +195,199 @@ func initialize() error {
 
 const logfile string = "opi-evpn-bridge.log"
 
-var logger *log.Logger
-
 // setupLogger sets the config for logger
 func setupLogger(filename string) {
 	var err error
 	filename = filepath.Clean(filename)
 	out, err := os.OpenFile(filename, os.O_TRUNC|os.O_CREATE|os.O_WRONLY, 0600)
 	if err != nil {
-		log.Panic(err)
+		logger.Panicw("Failed to open the log file", "file", filename, "error", err)
 	}
-	logger = log.New(io.MultiWriter(out), "", log.Lshortfile|log.LstdFlags)
-	log.SetOutput(logger.Writer())
+	logging.SetOutput(out)
+	// The libraries that use the standard logger write to the log file too
+	zap.RedirectStdLog(logging.Logger(logging.Grpc))
 }
 
+const (
+	// drainTimeout bounds the wait for the queued tasks to be realized on a preserving shutdown
+	drainTimeout = 30 * time.Second
+	// grpcStopTimeout bounds the wait for the calls in progress before the gRPC server is stopped
+	grpcStopTimeout = 10 * time.Second
+)
+
+// grpcServer is the running gRPC server, stopped on a preserving shutdown
+var grpcServer atomic.Pointer[grpc.Server]
+
+var cleanUpOnce sync.Once
+
+// cleanUp shuts the bridge down according to the shutdown mode, only once
 func cleanUp() {
-	log.Println("Defer function called")
+	cleanUpOnce.Do(func() {
+		logger.Infow("Shutting down", "mode", config.Current().ShutdownMode)
+		if config.Current().ShutdownMode == config.ShutdownPreserve {
+			shutdownPreservingState()
+		} else {
+			shutdownDeletingState()
+		}
+		audit.DeInitialize()
+		logging.Sync()
+	})
+}
+
+// shutdownDeletingState deletes all the realized objects and the state they have programmed
+func shutdownDeletingState() {
 	if err := infradb.DeleteAllResources(); err != nil {
-		log.Println("Failed to delete all the resources: ", err)
+		logger.Errorw("Failed to delete all the resources", "error", err)
+	}
+	modules.DeInitialize()
+
+	if err := infradb.Close(); err != nil {
+		logger.Errorw("Failed to close infradb", "error", err)
 	}
-	switch config.GlobalConfig.Buildenv {
-	case "ci":
-		gen_linux.DeInitialize()
-		ci_linux.DeInitialize()
-		frr.DeInitialize()
-	default:
-		log.Panic(" ERROR: Could not find Build env ")
+}
+
+// shutdownPreservingState stops serving and realizing the intent but leaves the kernel and FRR state
+// in place, so that a restarted bridge adopts it without disrupting the dataplane
+func shutdownPreservingState() {
+	logger.Info("Shutting down, the kernel and FRR state is preserved")
+	stopGrpcServer()
+	if pending := taskmanager.TaskMan.Drain(drainTimeout); pending != 0 {
+		logger.Warnw("Tasks have not been realized before the shutdown", "pending", pending)
 	}
+	modules.Stop()
 
 	if err := infradb.Close(); err != nil {
-		log.Println("Failed to close infradb")
+		logger.Errorw("Failed to close infradb", "error", err)
+	}
+}
+
+// stopGrpcServer waits for the calls in progress, up to grpcStopTimeout, and stops the gRPC server
+func stopGrpcServer() {
+	s := grpcServer.Load()
+	if s == nil {
+		return
+	}
+	stopped := make(chan struct{})
+	go func() {
+		s.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(grpcStopTimeout):
+		logger.Warnw("gRPC calls are still in progress, stopping the server", "timeout", grpcStopTimeout)
+		s.Stop()
 	}
 }
 
 // main function
 func main() {
-	// setup file and console logger
-	setupLogger(logfile)
-
 	// initialize  cobra config
 	if err := initialize(); err != nil {
-		// log.Println(err)
-		log.Panicf("Error in initialize(): %v", err)
+		logger.Panicw("Failed to initialize the command", "error", err)
 	}
 
 	sigChan := make(chan os.Signal, 1)
-	// Notify sigChan on SIGINT or SIGTERM.
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	// Notify sigChan on SIGINT, SIGTERM or SIGHUP.
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
 
 	// This goroutine executes a blocking receive for signals.
-	// When it gets one it will then exit the program.
+	// SIGHUP reloads the config, the other signals exit the program.
 	go func() {
-		sig := <-sigChan
-		switch sig {
-		case syscall.SIGINT:
-			cleanUp()
-			fmt.Println("Received SIGINT, shutting down.")
-		case syscall.SIGTERM:
-			cleanUp()
-			fmt.Println("Received SIGTERM, shutting down.")
-		default:
-			fmt.Println("Received unknown signal.")
-		}
-		// Perform any cleanup tasks here.
-		// ...
+		for sig := range sigChan {
+			switch sig {
+			case syscall.SIGHUP:
+				logger.Info("Received SIGHUP, reloading the config")
+				if _, err := config.Reload(); err != nil {
+					logger.Errorw("Failed to reload the config", "error", err)
+				}
+				continue
+			case syscall.SIGINT:
+				cleanUp()
+				fmt.Println("Received SIGINT, shutting down.")
+			case syscall.SIGTERM:
+				cleanUp()
+				fmt.Println("Received SIGTERM, shutting down.")
+			default:
+				fmt.Println("Received unknown signal.")
+			}
+			// Perform any cleanup tasks here.
+			// ...
 
-		// Exit the program.
-		os.Exit(0)
+			// Exit the program.
+			os.Exit(0)
+		}
 	}()
 
 	// start the main cmd
 	if err := rootCmd.Execute(); err != nil {
-		log.Panicf("Error in Execute(): %v", err)
+		logger.Panicw("Failed to execute the command", "error", err)
 	}
 	defer cleanUp()
 }
 
 // runGrpcServer start the grpc server for all the components
-func runGrpcServer(grpcPort uint16, tlsFiles string) {
+func runGrpcServer(grpcPort uint16, tlsFiles string, monitor *health.Monitor) {
 	if config.GlobalConfig.Tracer {
 		tp := utils.InitTracerProvider("opi-evpn-bridge")
 		defer func() {
 			if err := tp.Shutdown(context.Background()); err != nil {
-				log.Panicf("Tracer Provider Shutdown: %v", err)
+				logger.Panicw("Failed to shut the tracer provider down", "error", err)
 			}
 		}()
 	}
 
 	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", grpcPort))
 	if err != nil {
-		log.Panicf("failed to listen: %v", err)
+		logger.Panicw("Failed to listen", "port", grpcPort, "error", err)
 	}
 
 	var serverOptions []grpc.ServerOption
 	if tlsFiles == "" {
-		log.Println("TLS files are not specified. Use insecure connection.")
+		logger.Info("TLS files are not specified. Use insecure connection.")
 	} else {
-		log.Println("Use TLS certificate files:", tlsFiles)
 		config, err := utils.ParseTLSFiles(tlsFiles)
 		if err != nil {
-			log.Panic("Failed to parse string with tls paths:", err)
+			logger.Panicw("Failed to parse string with tls paths", "tlsFiles", tlsFiles, "error", err)
 		}
-		log.Println("TLS config:", config)
+		logger.Infow("Use TLS certificate files", "serverCert", config.ServerCertPath, "serverKey", config.ServerKeyPath, "caCert", config.CaCertPath)
 		var option grpc.ServerOption
 		if option, err = utils.SetupTLSCredentials(config); err != nil {
-			log.Panic("Failed to setup TLS:", err)
+			logger.Panicw("Failed to setup TLS", "error", err)
 		}
 		serverOptions = append(serverOptions, option)
 	}
 
+	authorizer, err := utils.NewAuthorizer(config.GlobalConfig.Auth)
+	if err != nil {
+		logger.Panicw("Failed to setup authorization", "error", err)
+	}
+	if !config.GlobalConfig.Auth.Enabled {
+		logger.Warn("Authorization is disabled. Every client is allowed to call all the methods.")
+	}
+	loggingOptions := []grpclogging.Option{
+		grpclogging.WithLogOnEvents(
+			grpclogging.StartCall,
+			grpclogging.FinishCall,
+			grpclogging.PayloadReceived,
+			grpclogging.PayloadSent,
+		),
+		// Record the authenticated client on every call
+		grpclogging.WithFieldsFromContext(utils.IdentityLogFields),
+	}
+
 	serverOptions = append(serverOptions,
 		grpc.StatsHandler(otelgrpc.NewServerHandler()),
-		grpc.UnaryInterceptor(
-			logging.UnaryServerInterceptor(utils.InterceptorLogger(log.Default()),
-				logging.WithLogOnEvents(
-					logging.StartCall,
-					logging.FinishCall,
-					logging.PayloadReceived,
-					logging.PayloadSent,
-				),
-			)),
+		grpc.ChainUnaryInterceptor(
+			metrics.UnaryServerInterceptor(),
+			authorizer.UnaryServerInterceptor(),
+			grpclogging.UnaryServerInterceptor(utils.InterceptorLogger(logging.Logger(logging.Grpc)), loggingOptions...),
+		),
+		grpc.ChainStreamInterceptor(
+			metrics.StreamServerInterceptor(),
+			authorizer.StreamServerInterceptor(),
+			grpclogging.StreamServerInterceptor(utils.InterceptorLogger(logging.Logger(logging.Grpc)), loggingOptions...),
+		),
 	)
 	s := grpc.NewServer(serverOptions...)
 
@@ -231,35 +399,78 @@ func runGrpcServer(grpcPort uint16, tlsFiles string) {
 	pe.RegisterBridgePortServiceServer(s, portServer)
 	pe.RegisterVrfServiceServer(s, vrfServer)
 	pe.RegisterSviServiceServer(s, sviServer)
+	epb.RegisterLogicalBridgeBatchServiceServer(s, bridgeServer)
+	epb.RegisterBridgePortBatchServiceServer(s, portServer)
+	longrunningpb.RegisterOperationsServer(s, operations.NewServer())
 	pc.RegisterInventoryServiceServer(s, &inventory.Server{})
+	audit.RegisterAdminServer(s, audit.NewServer(audit.Trail))
+	logging.RegisterAdminServer(s, logging.NewServer())
+	config.RegisterAdminServer(s, config.NewServer())
+	netlink.RegisterAdminServer(s, netlink.NewServer(netlink.DefaultWatcher()))
+	healthpb.RegisterHealthServer(s, monitor.Server())
 
 	reflection.Register(s)
 
-	log.Printf("gRPC server listening at %v", lis.Addr())
+	grpcServer.Store(s)
+	logger.Infow("gRPC server listening", "address", lis.Addr().String())
 	if err := s.Serve(lis); err != nil {
-		log.Panicf("failed to serve: %v", err)
+		logger.Panicw("Failed to serve", "error", err)
 	}
 }
 
-// runGatewayServer
-func runGatewayServer(grpcPort uint16, httpPort uint16) {
+// runGatewayServer starts the HTTP gateway that proxies the REST calls to the gRPC server.
+// With TLS the gateway dials the gRPC server with its own client certificate, that is
+// mapped to a low privileged role in the auth config, and forwards the bearer token of
+// the HTTP caller so that the calls are authorized with the caller identity.
+func runGatewayServer(grpcPort uint16, httpPort uint16, tlsFiles string, gatewayTLSFiles string, monitor *health.Monitor) {
 	ctx := context.Background()
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
 	// Register gRPC server endpoint
 	// Note: Make sure the gRPC server is running properly and accessible
-	mux := runtime.NewServeMux()
+	mux := runtime.NewServeMux(runtime.WithIncomingHeaderMatcher(gatewayHeaderMatcher))
 	opts := []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	if tlsFiles != "" {
+		if gatewayTLSFiles == "" {
+			logger.Panic("The HTTP gateway needs its own client certificate when TLS is enabled, set gatewaytlsfiles")
+		}
+		serverConfig, err := utils.ParseTLSFiles(tlsFiles)
+		if err != nil {
+			logger.Panicw("Failed to parse string with tls paths", "tlsFiles", tlsFiles, "error", err)
+		}
+		config, err := utils.ParseTLSFiles(gatewayTLSFiles)
+		if err != nil {
+			logger.Panicw("Failed to parse string with gateway tls paths", "gatewayTLSFiles", gatewayTLSFiles, "error", err)
+		}
+		if config.ServerCertPath == serverConfig.ServerCertPath {
+			logger.Panic("The HTTP gateway must not use the server certificate as its client certificate")
+		}
+		// The gateway dials localhost so the server certificate is verified with the name it has been issued for
+		serverName, err := utils.CertificateServerName(serverConfig.ServerCertPath)
+		if err != nil {
+			logger.Panicw("Failed to read the server name of the server certificate", "serverCert", serverConfig.ServerCertPath, "error", err)
+		}
+		option, err := utils.SetupTLSDialCredentials(config, serverName)
+		if err != nil {
+			logger.Panicw("Failed to setup TLS for the HTTP gateway", "error", err)
+		}
+		opts = []grpc.DialOption{option}
+	}
 
-	// TODO: add/replace with more/less registrations, once opi-api compiler fixed
-	err := pc.RegisterInventoryServiceHandlerFromEndpoint(ctx, mux, fmt.Sprintf(":%d", grpcPort), opts)
+	err := registerGatewayHandlers(ctx, mux, fmt.Sprintf("localhost:%d", grpcPort), opts)
 	if err != nil {
-		log.Panic("cannot register handler server")
+		logger.Panicw("Failed to register the gateway handlers", "error", err)
+	}
+	if err := registerHealthHandlers(mux, monitor); err != nil {
+		logger.Panicw("Failed to register the health handlers", "error", err)
+	}
+	if err := registerMetricsHandler(mux); err != nil {
+		logger.Panicw("Failed to register the metrics handler", "error", err)
 	}
 
 	// Start HTTP server (and proxy calls to gRPC server endpoint)
-	log.Printf("HTTP Server listening at %v", httpPort)
+	logger.Infow("HTTP server listening", "port", httpPort)
 	server := &http.Server{
 		Addr:         fmt.Sprintf(":%d", httpPort),
 		Handler:      mux,
@@ -268,23 +479,121 @@ func runGatewayServer(grpcPort uint16, httpPort uint16) {
 	}
 	err = server.ListenAndServe()
 	if err != nil {
-		log.Panic("cannot start HTTP gateway server")
+		logger.Panicw("Failed to start the HTTP gateway server", "error", err)
+	}
+}
+
+// gatewayHeaderMatcher forwards the operation request of the HTTP caller to the gRPC server
+// on top of the default headers, the Authorization header is always forwarded by the gateway
+func gatewayHeaderMatcher(key string) (string, bool) {
+	if strings.EqualFold(key, operations.MetadataKey) {
+		return operations.MetadataKey, true
 	}
+	return runtime.DefaultHeaderMatcher(key)
 }
 
+// registerGatewayHandlers registers the HTTP handlers of all the services that have a REST mapping
+func registerGatewayHandlers(ctx context.Context, mux *runtime.ServeMux, endpoint string, opts []grpc.DialOption) error {
+	handlers := []func(context.Context, *runtime.ServeMux, string, []grpc.DialOption) error{
+		pc.RegisterInventoryServiceHandlerFromEndpoint,
+		pe.RegisterLogicalBridgeServiceHandlerFromEndpoint,
+		pe.RegisterBridgePortServiceHandlerFromEndpoint,
+		pe.RegisterVrfServiceHandlerFromEndpoint,
+		pe.RegisterSviServiceHandlerFromEndpoint,
+		epb.RegisterLogicalBridgeBatchServiceHandlerFromEndpoint,
+		epb.RegisterBridgePortBatchServiceHandlerFromEndpoint,
+	}
+	for _, register := range handlers {
+		if err := register(ctx, mux, endpoint, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// registerHealthHandlers registers the liveness and readiness probes of the orchestrator
+func registerHealthHandlers(mux *runtime.ServeMux, monitor *health.Monitor) error {
+	if err := mux.HandlePath(http.MethodGet, "/healthz", func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+		monitor.LivenessHandler(w, r)
+	}); err != nil {
+		return err
+	}
+	return mux.HandlePath(http.MethodGet, "/readyz", func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+		monitor.ReadinessHandler(w, r)
+	})
+}
+
+// registerMetricsHandler registers the Prometheus /metrics endpoint
+func registerMetricsHandler(mux *runtime.ServeMux) error {
+	metrics.Registry.MustRegister(
+		metrics.NewObjectCollector(infradb.CountObjects),
+		metrics.NewCacheCollector(infradb.CountCacheReads),
+	)
+	handler := metrics.Handler()
+	return mux.HandlePath(http.MethodGet, "/metrics", func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// newHealthMonitor sets up the checks of the dependencies of the bridge
+// and the services that depend on them
+func newHealthMonitor() *health.Monitor {
+	monitor := health.NewMonitor(health.DefaultInterval)
+	monitor.AddCheck(health.CheckStorage, health.StorageCheck())
+	for _, eventType := range []string{"logical-bridge", "bridge-port", "vrf", "svi"} {
+		monitor.AddCheck(health.CheckSubscribers(eventType), health.SubscribersCheck(eventType, config.GlobalConfig.Subscribers, eventbus.EBus))
+	}
+	monitor.AddCheck(health.CheckTenantBridge, health.TenantBridgeCheck(utils.NewNetlinkWrapperWithArgs(false), "br-tenant"))
+
+	frrChecks := []string{}
+	if config.GlobalConfig.LinuxFrr.Enabled {
+		monitor.AddCheck(health.CheckFrr, health.FrrCheck(utils.NewFrrWrapperWithArgs("localhost", false)))
+		frrChecks = append(frrChecks, health.CheckFrr)
+	}
+
+	bridgeChecks := []string{health.CheckStorage, health.CheckSubscribers("logical-bridge"), health.CheckTenantBridge}
+	portChecks := []string{health.CheckStorage, health.CheckSubscribers("bridge-port"), health.CheckTenantBridge}
+	vrfChecks := append([]string{health.CheckStorage, health.CheckSubscribers("vrf")}, frrChecks...)
+	sviChecks := append([]string{health.CheckStorage, health.CheckSubscribers("svi"), health.CheckTenantBridge}, frrChecks...)
+	monitor.AddService(pe.LogicalBridgeService_ServiceDesc.ServiceName, bridgeChecks...)
+	monitor.AddService(epb.LogicalBridgeBatchService_ServiceDesc.ServiceName, bridgeChecks...)
+	monitor.AddService(pe.BridgePortService_ServiceDesc.ServiceName, portChecks...)
+	monitor.AddService(epb.BridgePortBatchService_ServiceDesc.ServiceName, portChecks...)
+	monitor.AddService(pe.VrfService_ServiceDesc.ServiceName, vrfChecks...)
+	monitor.AddService(pe.SviService_ServiceDesc.ServiceName, sviChecks...)
+	return monitor
+}
+
+// grdVrfName is the name of the VRF of the global routing domain
+const grdVrfName = "//network.opiproject.org/vrfs/GRD"
+
 // createGrdVrf creates the grd vrf with vni 0
 func createGrdVrf() error {
-	grdVrf, err := infradb.NewVrfWithArgs("//network.opiproject.org/vrfs/GRD", nil, nil, nil)
+	// The GRD stored by a previous run is kept, it has been replayed with the other objects
+	vlog := logger.With(logging.Kind("vrf"), logging.Name(grdVrfName))
+	_, err := infradb.GetVrf(grdVrfName)
+	if err == nil {
+		vlog.Info("CreateGrdVrf(): The GRD VRF object already exists")
+		return nil
+	}
+	if err != infradb.ErrKeyNotFound {
+		vlog.Errorw("CreateGrdVrf(): Error in looking up the GRD VRF object", "error", err)
+		return err
+	}
+
+	grdVrf, err := infradb.NewVrfWithArgs(grdVrfName, nil, nil, nil)
 	if err != nil {
-		log.Printf("CreateGrdVrf(): Error in initializing GRD VRF object %+v\n", err)
+		vlog.Errorw("CreateGrdVrf(): Error in initializing GRD VRF object", "error", err)
 		return err
 	}
 
+	grdVrf.Metadata.Audit.CreatedBy = common.SystemIdentity
 	err = infradb.CreateVrf(grdVrf)
 	if err != nil {
-		log.Printf("CreateGrdVrf(): Error in creating GRD VRF object %+v\n", err)
+		vlog.Errorw("CreateGrdVrf(): Error in creating GRD VRF object", "error", err)
 		return err
 	}
+	vlog.Infow("CreateGrdVrf(): The GRD VRF object has been created", logging.ResourceVersion(grdVrf.ResourceVersion))
 
 	return nil
 }
@@ -0,0 +1,272 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package main is the main package of the application
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	pe "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+	pc "github.com/opiproject/opi-api/network/opinetcommon/v1alpha1/gen/go"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/bridge"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+	"github.com/opiproject/opi-evpn-bridge/pkg/operations"
+	"github.com/opiproject/opi-evpn-bridge/pkg/port"
+	"github.com/opiproject/opi-evpn-bridge/pkg/svi"
+	"github.com/opiproject/opi-evpn-bridge/pkg/vrf"
+)
+
+var testPrefix = &pc.IPPrefix{
+	Addr: &pc.IPAddress{
+		Af:     pc.IpAf_IP_AF_INET,
+		V4OrV6: &pc.IPAddress_V4Addr{V4Addr: 167772162},
+	},
+	Len: 24,
+}
+
+// newTestGateway starts a gRPC server with all the evpn-gw services and an HTTP gateway in front of it
+func newTestGateway(t *testing.T) *httptest.Server {
+	eb := eventbus.EBus
+	for _, objType := range []string{"logical-bridge", "bridge-port", "vrf", "svi"} {
+		eb.StartSubscriber("dummy", objType, 1, nil)
+	}
+	if err := infradb.NewInfraDB("", "gomap"); err != nil {
+		t.Fatal(err)
+	}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer()
+	pe.RegisterLogicalBridgeServiceServer(s, bridge.NewServer())
+	pe.RegisterBridgePortServiceServer(s, port.NewServer())
+	pe.RegisterVrfServiceServer(s, vrf.NewServer())
+	pe.RegisterSviServiceServer(s, svi.NewServer())
+	go func() {
+		_ = s.Serve(lis)
+	}()
+	t.Cleanup(s.Stop)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	mux := runtime.NewServeMux()
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	if err := registerGatewayHandlers(ctx, mux, lis.Addr().String(), opts); err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// doRequest sends a REST request to the gateway and decodes the response into out
+func doRequest(t *testing.T, method, url string, in proto.Message, out proto.Message) int {
+	var body io.Reader
+	if in != nil {
+		data, err := protojson.Marshal(in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(context.Background(), method, url, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode == http.StatusOK && out != nil {
+		if err := protojson.Unmarshal(data, out); err != nil {
+			t.Fatalf("failed to decode %s: %v", data, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func Test_GatewayHandlers(t *testing.T) {
+	server := newTestGateway(t)
+
+	tests := []struct {
+		collection string
+		idParam    string
+		id         string
+		fullName   string
+		in         proto.Message
+		newObject  func() proto.Message
+		newList    func() proto.Message
+		listNames  func(proto.Message) []string
+	}{
+		{
+			collection: "vrfs",
+			idParam:    "vrfId",
+			id:         "gw-vrf",
+			fullName:   "//network.opiproject.org/vrfs/gw-vrf",
+			in:         &pe.Vrf{Spec: &pe.VrfSpec{Vni: proto.Uint32(2000), LoopbackIpPrefix: testPrefix, VtepIpPrefix: testPrefix}},
+			newObject:  func() proto.Message { return &pe.Vrf{} },
+			newList:    func() proto.Message { return &pe.ListVrfsResponse{} },
+			listNames: func(m proto.Message) []string {
+				names := []string{}
+				for _, obj := range m.(*pe.ListVrfsResponse).Vrfs {
+					names = append(names, obj.Name)
+				}
+				return names
+			},
+		},
+		{
+			collection: "logicalBridges",
+			idParam:    "logicalBridgeId",
+			id:         "gw-bridge",
+			fullName:   "//network.opiproject.org/bridges/gw-bridge",
+			in:         &pe.LogicalBridge{Spec: &pe.LogicalBridgeSpec{VlanId: 33, Vni: proto.Uint32(22), VtepIpPrefix: testPrefix}},
+			newObject:  func() proto.Message { return &pe.LogicalBridge{} },
+			newList:    func() proto.Message { return &pe.ListLogicalBridgesResponse{} },
+			listNames: func(m proto.Message) []string {
+				names := []string{}
+				for _, obj := range m.(*pe.ListLogicalBridgesResponse).LogicalBridges {
+					names = append(names, obj.Name)
+				}
+				return names
+			},
+		},
+		{
+			collection: "bridgePorts",
+			idParam:    "bridgePortId",
+			id:         "gw-port",
+			fullName:   "//network.opiproject.org/ports/gw-port",
+			in: &pe.BridgePort{Spec: &pe.BridgePortSpec{
+				MacAddress:     []byte{0xCB, 0xB8, 0x33, 0x4C, 0x88, 0x4F},
+				Ptype:          pe.BridgePortType_BRIDGE_PORT_TYPE_TRUNK,
+				LogicalBridges: []string{"//network.opiproject.org/bridges/gw-parent-bridge"},
+			}},
+			newObject: func() proto.Message { return &pe.BridgePort{} },
+			newList:   func() proto.Message { return &pe.ListBridgePortsResponse{} },
+			listNames: func(m proto.Message) []string {
+				names := []string{}
+				for _, obj := range m.(*pe.ListBridgePortsResponse).BridgePorts {
+					names = append(names, obj.Name)
+				}
+				return names
+			},
+		},
+		{
+			collection: "svis",
+			idParam:    "sviId",
+			id:         "gw-svi",
+			fullName:   "//network.opiproject.org/svis/gw-svi",
+			in: &pe.Svi{Spec: &pe.SviSpec{
+				Vrf:           "//network.opiproject.org/vrfs/gw-parent-vrf",
+				LogicalBridge: "//network.opiproject.org/bridges/gw-parent-bridge",
+				MacAddress:    []byte{0xCB, 0xB8, 0x33, 0x4C, 0x88, 0x4F},
+				GwIpPrefix:    []*pc.IPPrefix{testPrefix},
+			}},
+			newObject: func() proto.Message { return &pe.Svi{} },
+			newList:   func() proto.Message { return &pe.ListSvisResponse{} },
+			listNames: func(m proto.Message) []string {
+				names := []string{}
+				for _, obj := range m.(*pe.ListSvisResponse).Svis {
+					names = append(names, obj.Name)
+				}
+				return names
+			},
+		},
+	}
+
+	// the Bridge Ports and the SVIs reference these objects, which therefore cannot be deleted
+	code := doRequest(t, http.MethodPost, server.URL+"/v1/vrfs?vrfId=gw-parent-vrf",
+		&pe.Vrf{Spec: &pe.VrfSpec{Vni: proto.Uint32(1000), LoopbackIpPrefix: testPrefix, VtepIpPrefix: testPrefix}}, nil)
+	assert.Equal(t, http.StatusOK, code)
+	code = doRequest(t, http.MethodPost, server.URL+"/v1/logicalBridges?logicalBridgeId=gw-parent-bridge",
+		&pe.LogicalBridge{Spec: &pe.LogicalBridgeSpec{VlanId: 22, Vni: proto.Uint32(11), VtepIpPrefix: testPrefix}}, nil)
+	assert.Equal(t, http.StatusOK, code)
+
+	for _, tt := range tests {
+		t.Run(tt.collection, func(t *testing.T) {
+			created := tt.newObject()
+			url := fmt.Sprintf("%s/v1/%s?%s=%s", server.URL, tt.collection, tt.idParam, tt.id)
+			code := doRequest(t, http.MethodPost, url, tt.in, created)
+			assert.Equal(t, http.StatusOK, code, "create")
+			name := created.ProtoReflect().Get(created.ProtoReflect().Descriptor().Fields().ByName("name")).String()
+			assert.Equal(t, tt.fullName, name)
+
+			fetched := tt.newObject()
+			code = doRequest(t, http.MethodGet, fmt.Sprintf("%s/v1/%s/%s", server.URL, tt.collection, tt.id), nil, fetched)
+			assert.Equal(t, http.StatusOK, code, "get")
+			assert.True(t, proto.Equal(created, fetched), "get: expected %v received %v", created, fetched)
+
+			list := tt.newList()
+			code = doRequest(t, http.MethodGet, fmt.Sprintf("%s/v1/%s", server.URL, tt.collection), nil, list)
+			assert.Equal(t, http.StatusOK, code, "list")
+			assert.Contains(t, tt.listNames(list), tt.fullName)
+
+			code = doRequest(t, http.MethodGet, fmt.Sprintf("%s/v1/%s/unknown-id", server.URL, tt.collection), nil, nil)
+			assert.Equal(t, http.StatusNotFound, code, "get unknown")
+
+			code = doRequest(t, http.MethodDelete, fmt.Sprintf("%s/v1/%s/%s", server.URL, tt.collection, tt.id), nil, nil)
+			assert.Equal(t, http.StatusOK, code, "delete")
+		})
+	}
+}
+
+func Test_GatewayHeaderMatcher(t *testing.T) {
+	tests := map[string]struct {
+		header  string
+		key     string
+		forward bool
+	}{
+		"operation request": {
+			header:  "X-Opi-Operation",
+			key:     operations.MetadataKey,
+			forward: true,
+		},
+		"permanent header": {
+			header:  "Content-Type",
+			key:     runtime.MetadataPrefix + "Content-Type",
+			forward: true,
+		},
+		"grpc metadata": {
+			header:  "Grpc-Metadata-Trace",
+			key:     "Trace",
+			forward: true,
+		},
+		"other header": {
+			header: "X-Other",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			key, forward := gatewayHeaderMatcher(tt.header)
+			assert.Equal(t, tt.forward, forward)
+			assert.Equal(t, tt.key, key)
+		})
+	}
+}
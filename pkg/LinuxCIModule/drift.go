@@ -0,0 +1,111 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package linuxcimodule is the main package of the application
+package linuxcimodule
+
+import (
+	"fmt"
+	"math"
+	"path"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/vishvananda/netlink/nl"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
+	"github.com/opiproject/opi-evpn-bridge/pkg/logging"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
+)
+
+// driftDetector compares the bridge ports realized by the LCI to the kernel
+var driftDetector *utils.DriftDetector
+
+// remediateDrift realizes again the bridge ports that have drifted
+var remediateDrift atomic.Bool
+
+// startDriftDetection starts the drift detector if enabled in the config
+func startDriftDetection() {
+	cfg := config.Current().Drift
+	if !cfg.Enabled {
+		return
+	}
+	remediateDrift.Store(cfg.Remediate)
+	driftDetector = utils.NewDriftDetector(ctx, nlink, lciComp, time.Duration(cfg.Interval)*time.Second, checkDrift)
+	driftDetector.Start()
+}
+
+// stopDriftDetection stops the drift detector, if started
+func stopDriftDetection() {
+	if driftDetector != nil {
+		driftDetector.Stop()
+		driftDetector = nil
+	}
+}
+
+// realized checks the LCI has realized the bridge port successfully
+func realized(bp *infradb.BridgePort) bool {
+	if bp.Status.BPOperStatus == infradb.BridgePortOperStatusToBeDeleted {
+		return false
+	}
+	for _, comp := range bp.Status.Components {
+		if comp.Name == lciComp {
+			return comp.CompStatus == common.ComponentStatusSuccess
+		}
+	}
+	return false
+}
+
+// checkDrift compares the bridge ports realized by the LCI to the kernel
+func checkDrift() {
+	bps, err := infradb.GetAllBPs()
+	if err != nil {
+		return
+	}
+	vlans, err := nlink.BridgeVlanList(ctx)
+	if err != nil {
+		logger.Errorw("LCI: Failed to list the bridge vlans", "error", err)
+		return
+	}
+	for _, bp := range bps {
+		if !realized(bp) {
+			continue
+		}
+		drift := bpDrift(bp, vlans)
+		if len(drift) != 0 {
+			logger.Warnw("LCI: bridge-port has drifted", logging.Name(bp.Name), "drift", drift)
+		}
+		if err := infradb.ReportDrift("bridge-port", bp.Name, bp.ResourceVersion, lciComp, strings.Join(drift, "; "), remediateDrift.Load()); err != nil {
+			logger.Errorw("LCI: Failed to report the drift of bridge-port", logging.Name(bp.Name), "error", err)
+		}
+	}
+}
+
+// bpDrift returns how the link of the bridge port, its master and vlans differ from the kernel
+func bpDrift(bp *infradb.BridgePort, vlans map[int32][]*nl.BridgeVlanInfo) []string {
+	bridge, drift := utils.LinkDrift(ctx, nlink, "br-tenant", nil, 0)
+	if bridge == nil {
+		return drift
+	}
+	iface, ifaceDrift := utils.LinkDrift(ctx, nlink, path.Base(bp.Name), bridge, 0)
+	drift = append(drift, ifaceDrift...)
+	if iface == nil {
+		return drift
+	}
+	for _, bridgeRefName := range bp.Spec.LogicalBridges {
+		BrObj, err := infradb.GetLB(bridgeRefName)
+		if err != nil {
+			drift = append(drift, fmt.Sprintf("logical bridge %s not found", bridgeRefName))
+			continue
+		}
+		if BrObj.Spec.VlanID > math.MaxUint16 {
+			continue
+		}
+		drift = append(drift, utils.VlanDrift(vlans, iface, uint16(BrObj.Spec.VlanID), bp.Spec.Ptype == infradb.Access)...)
+	}
+	return drift
+}
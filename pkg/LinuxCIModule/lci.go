@@ -10,7 +10,6 @@ import (
 	"fmt"
 
 	// "io/ioutil"
-	"log"
 	"math"
 	"path"
 	"time"
@@ -19,10 +18,15 @@ import (
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+	"github.com/opiproject/opi-evpn-bridge/pkg/logging"
+	"github.com/opiproject/opi-evpn-bridge/pkg/modules"
 	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
 	// "gopkg.in/yaml.v2"
 )
 
+// logger is the logger of the linux subsystem
+var logger = logging.Logger(logging.Linux).Sugar()
+
 // ModulelciHandler interface
 type ModulelciHandler struct{}
 
@@ -32,10 +36,10 @@ const lciComp string = "lci"
 func (h *ModulelciHandler) HandleEvent(eventType string, objectData *eventbus.ObjectData) {
 	switch eventType {
 	case "bridge-port":
-		log.Printf("LCI recevied %s %s\n", eventType, objectData.Name)
+		logger.Infow("LCI received event", logging.Kind(eventType), logging.Name(objectData.Name), logging.ResourceVersion(objectData.ResourceVersion), logging.NotificationID(objectData.NotificationID))
 		handlebp(objectData)
 	default:
-		log.Printf("LCI: error: Unknown event type %s", eventType)
+		logger.Errorw("LCI: Unknown event type", "eventType", eventType)
 	}
 }
 
@@ -46,7 +50,7 @@ func handlebp(objectData *eventbus.ObjectData) {
 	var comp common.Component
 	BP, err := infradb.GetBP(objectData.Name)
 	if err != nil {
-		log.Printf("LCI : GetBP error: %s\n", err)
+		logger.Errorw("LCI: GetBP error", "error", err)
 		comp.Name = lciComp
 		comp.CompStatus = common.ComponentStatusError
 		comp.Details = fmt.Sprintf("LCI : GetBP error: %s\n", err)
@@ -57,12 +61,12 @@ func handlebp(objectData *eventbus.ObjectData) {
 		}
 		err := infradb.UpdateBPStatus(objectData.Name, objectData.ResourceVersion, objectData.NotificationID, nil, comp)
 		if err != nil {
-			log.Printf("error in updating bp status: %s\n", err)
+			logger.Errorw("error in updating bp status", "error", err)
 		}
 		return
 	}
 	if objectData.ResourceVersion != BP.ResourceVersion {
-		log.Printf("LVM: Mismatch in resoruce version %+v\n and bp resource version %+v\n", objectData.ResourceVersion, BP.ResourceVersion)
+		logger.Debugw("LVM: Mismatch in resource version", logging.ResourceVersion(objectData.ResourceVersion), "bpResourceVersion", BP.ResourceVersion)
 		comp.Name = lciComp
 		comp.CompStatus = common.ComponentStatusError
 		comp.Details = fmt.Sprintf("LVM: Mismatch in resoruce version %+v\n and bp resource version %+v\n", objectData.ResourceVersion, BP.ResourceVersion)
@@ -73,7 +77,7 @@ func handlebp(objectData *eventbus.ObjectData) {
 		}
 		err := infradb.UpdateBPStatus(objectData.Name, objectData.ResourceVersion, objectData.NotificationID, nil, comp)
 		if err != nil {
-			log.Printf("error in updating bp status: %s\n", err)
+			logger.Errorw("error in updating bp status", "error", err)
 		}
 		return
 	}
@@ -99,10 +103,10 @@ func handlebp(objectData *eventbus.ObjectData) {
 			}
 			comp.CompStatus = common.ComponentStatusError
 		}
-		log.Printf("LCI: %+v \n", comp)
+		logger.Debugw("LCI: component status", logging.Component(comp.Name), "status", comp.CompStatus, "details", comp.Details)
 		err := infradb.UpdateBPStatus(objectData.Name, objectData.ResourceVersion, objectData.NotificationID, BP.Metadata, comp)
 		if err != nil {
-			log.Printf("error in updating bp status: %s\n", err)
+			logger.Errorw("error in updating bp status", "error", err)
 		}
 	} else {
 		details, status := tearDownBp(BP)
@@ -119,10 +123,10 @@ func handlebp(objectData *eventbus.ObjectData) {
 			}
 			comp.CompStatus = common.ComponentStatusError
 		}
-		log.Printf("LCI: %+v \n", comp)
+		logger.Debugw("LCI: component status", logging.Component(comp.Name), "status", comp.CompStatus, "details", comp.Details)
 		err := infradb.UpdateBPStatus(objectData.Name, objectData.ResourceVersion, objectData.NotificationID, nil, comp)
 		if err != nil {
-			log.Printf("error in updating bp status: %s\n", err)
+			logger.Errorw("error in updating bp status", "error", err)
 		}
 	}
 }
@@ -132,26 +136,26 @@ func setUpBp(bp *infradb.BridgePort) (string, bool) {
 	resourceID := path.Base(bp.Name)
 	bridge, err := nlink.LinkByName(ctx, "br-tenant")
 	if err != nil {
-		log.Printf("LCI: Unable to find key br-tenant\n")
+		logger.Errorw("LCI: Unable to find key", logging.Name("br-tenant"), "error", err)
 		return fmt.Sprintf("LCI: Unable to find key br-tenant, %s", err), false
 	}
 	iface, err := nlink.LinkByName(ctx, resourceID)
 	if err != nil {
-		log.Printf("LCI: Unable to find key %s\n", resourceID)
+		logger.Errorw("LCI: Unable to find key", logging.Name(resourceID), "error", err)
 		return fmt.Sprintf("LCI: Unable to find key %s\n", resourceID), false
 	}
 	if err := nlink.LinkSetMaster(ctx, iface, bridge); err != nil {
-		log.Printf("LCI: Failed to add iface to bridge: %v", err)
+		logger.Errorw("LCI: Failed to add iface to bridge", "error", err)
 		return fmt.Sprintf("LCI: Failed to add iface to bridge: %v", err), false
 	}
 	for _, bridgeRefName := range bp.Spec.LogicalBridges {
 		BrObj, err := infradb.GetLB(bridgeRefName)
 		if err != nil {
-			log.Printf("LCI: unable to find key %s and error is %v", bridgeRefName, err)
+			logger.Errorw("LCI: Unable to find key", logging.Name(bridgeRefName), "error", err)
 			return fmt.Sprintf("LCI: unable to find key %s and error is %v", bridgeRefName, err), false
 		}
 		if BrObj.Spec.VlanID > math.MaxUint16 {
-			log.Printf("LVM : VlanID %v value passed in Logical Bridge create is greater than 16 bit value\n", BrObj.Spec.VlanID)
+			logger.Infow("LCI: VlanID value passed in Logical Bridge create is greater than 16 bit value", logging.Name(BrObj.Name), "vlanID", BrObj.Spec.VlanID)
 			return fmt.Sprintf("LVM : VlanID %v value passed in Logical Bridge create is greater than 16 bit value\n", BrObj.Spec.VlanID), false
 		}
 		//TODO: Update opi-api to change vlanid to int16 in LogiclaBridge "https://linter.aip.dev/141/forbidden-types"
@@ -159,22 +163,22 @@ func setUpBp(bp *infradb.BridgePort) (string, bool) {
 		switch bp.Spec.Ptype {
 		case infradb.Access:
 			if err := nlink.BridgeVlanAdd(ctx, iface, vid, true, true, false, false); err != nil {
-				log.Printf("Failed to add vlan to bridge: %v", err)
+				logger.Errorw("Failed to add vlan to bridge", "error", err)
 				return fmt.Sprintf("Failed to add vlan to bridge: %v", err), false
 			}
 		case infradb.Trunk:
 			// Example: bridge vlan add dev eth2 vid 20
 			if err := nlink.BridgeVlanAdd(ctx, iface, vid, false, false, false, false); err != nil {
-				log.Printf("Failed to add vlan to bridge: %v", err)
+				logger.Errorw("Failed to add vlan to bridge", "error", err)
 				return fmt.Sprintf("Failed to add vlan to bridge: %v", err), false
 			}
 		default:
-			log.Printf("Only ACCESS or TRUNK supported and not (%d)", bp.Spec.Ptype)
+			logger.Infow("LCI: Only ACCESS or TRUNK supported", logging.Name(bp.Name), "ptype", bp.Spec.Ptype)
 			return fmt.Sprintf("Only ACCESS or TRUNK supported and not (%d)", bp.Spec.Ptype), false
 		}
 	}
 	if err := nlink.LinkSetUp(ctx, iface); err != nil {
-		log.Printf("Failed to up iface link: %v", err)
+		logger.Errorw("Failed to up iface link", "error", err)
 		return fmt.Sprintf("Failed to up iface link: %v", err), false
 	}
 	return "", true
@@ -185,32 +189,32 @@ func tearDownBp(bp *infradb.BridgePort) (string, bool) {
 	resourceID := path.Base(bp.Name)
 	iface, err := nlink.LinkByName(ctx, resourceID)
 	if err != nil {
-		log.Printf("LCI: Unable to find key %s\n", resourceID)
+		logger.Errorw("LCI: Unable to find key", logging.Name(resourceID), "error", err)
 		return fmt.Sprintf("LCI: Unable to find key %s\n", resourceID), false
 	}
 	if err := nlink.LinkSetDown(ctx, iface); err != nil {
-		log.Printf("LCI: Failed to down link: %v", err)
+		logger.Errorw("LCI: Failed to down link", "error", err)
 		return fmt.Sprintf("LCI: Failed to down link: %v", err), false
 	}
 	for _, bridgeRefName := range bp.Spec.LogicalBridges {
 		BrObj, err := infradb.GetLB(bridgeRefName)
 		if err != nil {
-			log.Printf("LCI: unable to find key %s and error is %v", bridgeRefName, err)
+			logger.Errorw("LCI: Unable to find key", logging.Name(bridgeRefName), "error", err)
 			return fmt.Sprintf("LCI: unable to find key %s and error is %v", bridgeRefName, err), false
 		}
 		if BrObj.Spec.VlanID > math.MaxUint16 {
-			log.Printf("LVM : VlanID %v value passed in Logical Bridge create is greater than 16 bit value\n", BrObj.Spec.VlanID)
+			logger.Infow("LCI: VlanID value passed in Logical Bridge create is greater than 16 bit value", logging.Name(BrObj.Name), "vlanID", BrObj.Spec.VlanID)
 			return fmt.Sprintf("LVM : VlanID %v value passed in Logical Bridge create is greater than 16 bit value\n", BrObj.Spec.VlanID), false
 		}
 		//TODO: Update opi-api to change vlanid to uint16 in LogiclaBridge
 		vid := uint16(BrObj.Spec.VlanID)
 		if err := nlink.BridgeVlanDel(ctx, iface, vid, true, true, false, false); err != nil {
-			log.Printf("LCI: Failed to delete vlan to bridge: %v", err)
+			logger.Errorw("LCI: Failed to delete vlan to bridge", "error", err)
 			return fmt.Sprintf("LCI: Failed to delete vlan to bridge: %v", err), false
 		}
 	}
 	if err := nlink.LinkDel(ctx, iface); err != nil {
-		log.Printf("Failed to delete link: %v", err)
+		logger.Errorw("Failed to delete link", "error", err)
 		return fmt.Sprintf("Failed to delete link: %v", err), false
 	}
 	return "", true
@@ -219,10 +223,26 @@ func tearDownBp(bp *infradb.BridgePort) (string, bool) {
 var ctx context.Context
 var nlink utils.Netlink
 
+func init() {
+	modules.Register(modules.Module{
+		Name:         lciComp,
+		BuildEnvs:    []string{"ci"},
+		Events:       []string{"bridge-port"},
+		Priority:     2,
+		Initialize:   Initialize,
+		DeInitialize: DeInitialize,
+		// DeInitialize only stops the drift detection and unsubscribes, the realized state is left in place
+		Stop: DeInitialize,
+	})
+	config.OnReload(func(cfg *config.Config) {
+		remediateDrift.Store(cfg.Drift.Remediate)
+	})
+}
+
 // Initialize initializes the config and  subscribers
 func Initialize() {
 	eb := eventbus.EBus
-	for _, subscriberConfig := range config.GlobalConfig.Subscribers {
+	for _, subscriberConfig := range config.Current().Subscribers {
 		if subscriberConfig.Name == "lci" {
 			for _, eventType := range subscriberConfig.Events {
 				eb.StartSubscriber(subscriberConfig.Name, eventType, subscriberConfig.Priority, &ModulelciHandler{})
@@ -231,10 +251,12 @@ func Initialize() {
 	}
 	ctx = context.Background()
 	nlink = utils.NewNetlinkWrapperWithArgs(config.GlobalConfig.Tracer)
+	startDriftDetection()
 }
 
 // DeInitialize function handles stops functionality
 func DeInitialize() {
+	stopDriftDetection()
 	// Unsubscribe to InfraDB notifications
 	eb := eventbus.EBus
 	eb.UnsubscribeModule("lci")
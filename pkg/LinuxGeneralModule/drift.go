@@ -0,0 +1,209 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package linuxgeneralmodule is the main package of the application
+package linuxgeneralmodule
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"path"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netlink/nl"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
+	"github.com/opiproject/opi-evpn-bridge/pkg/logging"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
+)
+
+// driftDetector compares the links of the realized objects to the kernel
+var driftDetector *utils.DriftDetector
+
+// remediateDrift realizes again the objects that have drifted
+var remediateDrift atomic.Bool
+
+// startDriftDetection starts the drift detector if enabled in the config
+func startDriftDetection() {
+	cfg := config.Current().Drift
+	if !cfg.Enabled {
+		return
+	}
+	remediateDrift.Store(cfg.Remediate)
+	driftDetector = utils.NewDriftDetector(ctx, nlink, lgmComp, time.Duration(cfg.Interval)*time.Second, checkDrift)
+	driftDetector.Start()
+}
+
+// stopDriftDetection stops the drift detector, if started
+func stopDriftDetection() {
+	if driftDetector != nil {
+		driftDetector.Stop()
+		driftDetector = nil
+	}
+}
+
+// realized checks the LGM has realized the object successfully
+func realized(components []common.Component) bool {
+	for _, comp := range components {
+		if comp.Name == lgmComp {
+			return comp.CompStatus == common.ComponentStatusSuccess
+		}
+	}
+	return false
+}
+
+// checkDrift compares the vrfs, logical bridges and svis realized by the LGM to the kernel
+func checkDrift() {
+	vlans, err := nlink.BridgeVlanList(ctx)
+	if err != nil {
+		logger.Errorw("LGM: Failed to list the bridge vlans", "error", err)
+		return
+	}
+	if vrfs, err := infradb.GetAllVrfs(); err == nil {
+		for _, vrf := range vrfs {
+			if vrf.Status.VrfOperStatus != infradb.VrfOperStatusToBeDeleted && realized(vrf.Status.Components) {
+				reportDrift("vrf", vrf.Name, vrf.ResourceVersion, vrfDrift(vrf))
+			}
+		}
+	}
+	if lbs, err := infradb.GetAllLBs(); err == nil {
+		for _, lb := range lbs {
+			if lb.Status.LBOperStatus != infradb.LogicalBridgeOperStatusToBeDeleted && realized(lb.Status.Components) {
+				reportDrift("logical-bridge", lb.Name, lb.ResourceVersion, bridgeDrift(lb, vlans))
+			}
+		}
+	}
+	if svis, err := infradb.GetAllSvis(); err == nil {
+		for _, svi := range svis {
+			if svi.Status.SviOperStatus != infradb.SviOperStatusToBeDeleted && realized(svi.Status.Components) {
+				reportDrift("svi", svi.Name, svi.ResourceVersion, sviDrift(svi, vlans))
+			}
+		}
+	}
+}
+
+// reportDrift reports the drift of the object, or its absence, in the status of the LGM
+func reportDrift(objectType, name, resourceVersion string, drift []string) {
+	if len(drift) != 0 {
+		logger.Warnw("LGM: Object has drifted", logging.Kind(objectType), logging.Name(name), "drift", drift)
+	}
+	if err := infradb.ReportDrift(objectType, name, resourceVersion, lgmComp, strings.Join(drift, "; "), remediateDrift.Load()); err != nil {
+		logger.Errorw("LGM: Failed to report the drift", logging.Kind(objectType), logging.Name(name), "error", err)
+	}
+}
+
+// vrfDrift returns how the links of the vrf differ from the kernel
+func vrfDrift(vrf *infradb.Vrf) []string {
+	name := path.Base(vrf.Name)
+	if name == "GRD" {
+		return nil
+	}
+	mtu := int(ipMtu.Load())
+	link, drift := utils.LinkDrift(ctx, nlink, name, nil, mtu)
+	if link == nil {
+		return drift
+	}
+	if vrfLink, ok := link.(*netlink.Vrf); !ok {
+		drift = append(drift, fmt.Sprintf("link %s is a %s instead of a vrf", name, link.Type()))
+	} else if len(vrf.Metadata.RoutingTable) != 0 && vrf.Metadata.RoutingTable[0] != nil && vrfLink.Table != *vrf.Metadata.RoutingTable[0] {
+		drift = append(drift, fmt.Sprintf("link %s has table %d instead of %d", name, vrfLink.Table, *vrf.Metadata.RoutingTable[0]))
+	}
+	if vrf.Spec.LoopbackIP != nil {
+		for _, missing := range missingAddrs(link, []*net.IPNet{vrf.Spec.LoopbackIP}) {
+			drift = append(drift, fmt.Sprintf("address %s not found on link %s", missing, name))
+		}
+	}
+	if reflect.ValueOf(vrf.Spec.Vni).IsZero() {
+		return drift
+	}
+	br, brDrift := utils.LinkDrift(ctx, nlink, brStr+name, link, mtu)
+	drift = append(drift, brDrift...)
+	if br == nil {
+		return drift
+	}
+	vxlan, vxlanDrift := utils.LinkDrift(ctx, nlink, vxlanStr+name, br, mtu)
+	drift = append(drift, vxlanDrift...)
+	if vxlan != nil {
+		drift = append(drift, vxlanAttrsDrift(vxlan, int(*vrf.Spec.Vni), vrf.Spec.VtepIP)...)
+	}
+	return drift
+}
+
+// bridgeDrift returns how the vxlan link of the logical bridge differs from the kernel
+func bridgeDrift(lb *infradb.LogicalBridge, vlans map[int32][]*nl.BridgeVlanInfo) []string {
+	if reflect.ValueOf(lb.Spec.Vni).IsZero() {
+		return nil
+	}
+	brIntf, drift := utils.LinkDrift(ctx, nlink, brTenant, nil, 0)
+	if brIntf == nil {
+		return drift
+	}
+	name := fmt.Sprintf("vxlan-%+v", lb.Spec.VlanID)
+	vxlan, vxlanDrift := utils.LinkDrift(ctx, nlink, name, brIntf, int(ipMtu.Load()))
+	drift = append(drift, vxlanDrift...)
+	if vxlan == nil {
+		return drift
+	}
+	drift = append(drift, vxlanAttrsDrift(vxlan, int(*lb.Spec.Vni), lb.Spec.VtepIP)...)
+	return append(drift, utils.VlanDrift(vlans, vxlan, uint16(lb.Spec.VlanID), true)...)
+}
+
+// vxlanAttrsDrift returns how the vni and the local address of the vxlan link differ from the expected ones
+func vxlanAttrsDrift(link netlink.Link, vni int, vtepIP *net.IPNet) []string {
+	if _, ok := link.(*netlink.Vxlan); !ok {
+		return []string{fmt.Sprintf("link %s is a %s instead of a vxlan", link.Attrs().Name, link.Type())}
+	}
+	var src net.IP
+	if vtepIP != nil {
+		src = vtepIP.IP
+	}
+	return mismatch(vxlanMatches(vni, src), link)
+}
+
+// sviDrift returns how the vlan link of the svi differs from the kernel
+func sviDrift(svi *infradb.Svi, vlans map[int32][]*nl.BridgeVlanInfo) []string {
+	BrObj, err := infradb.GetLB(svi.Spec.LogicalBridge)
+	if err != nil {
+		return []string{fmt.Sprintf("logical bridge %s not found", svi.Spec.LogicalBridge)}
+	}
+	brIntf, drift := utils.LinkDrift(ctx, nlink, brTenant, nil, 0)
+	vrfIntf, vrfDrift := utils.LinkDrift(ctx, nlink, path.Base(svi.Spec.Vrf), nil, 0)
+	drift = append(drift, vrfDrift...)
+	if brIntf == nil || vrfIntf == nil {
+		return drift
+	}
+	drift = append(drift, utils.VlanDrift(vlans, brIntf, uint16(BrObj.Spec.VlanID), false)...)
+	linkSvi := fmt.Sprintf("%+v-%+v", path.Base(svi.Spec.Vrf), BrObj.Spec.VlanID)
+	vlanLink, vlanDrift := utils.LinkDrift(ctx, nlink, linkSvi, vrfIntf, int(ipMtu.Load()))
+	drift = append(drift, vlanDrift...)
+	if vlanLink == nil {
+		return drift
+	}
+	if _, ok := vlanLink.(*netlink.Vlan); !ok {
+		drift = append(drift, fmt.Sprintf("link %s is a %s instead of a vlan", linkSvi, vlanLink.Type()))
+	}
+	drift = append(drift, mismatch(vlanMatches(int(BrObj.Spec.VlanID), brIntf.Attrs().Index), vlanLink)...)
+	if svi.Spec.MacAddress != nil && !bytes.Equal(vlanLink.Attrs().HardwareAddr, *svi.Spec.MacAddress) {
+		drift = append(drift, fmt.Sprintf("link %s has mac %s instead of %s", linkSvi, vlanLink.Attrs().HardwareAddr, *svi.Spec.MacAddress))
+	}
+	for _, missing := range missingAddrs(vlanLink, svi.Spec.GatewayIPs) {
+		drift = append(drift, fmt.Sprintf("address %s not found on link %s", missing, linkSvi))
+	}
+	return drift
+}
+
+// mismatch returns the mismatch of the link with the expected attributes, if any
+func mismatch(matches func(netlink.Link) error, link netlink.Link) []string {
+	if err := matches(link); err != nil {
+		return []string{fmt.Sprintf("link %s has %v", link.Attrs().Name, err)}
+	}
+	return nil
+}
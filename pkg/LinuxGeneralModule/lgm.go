@@ -7,20 +7,23 @@ package linuxgeneralmodule
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
 	"math"
 	"math/rand"
 	"net"
 	"os/exec"
 	"reflect"
 	"strconv"
+	"sync/atomic"
 	"time"
 
 	"github.com/opiproject/opi-evpn-bridge/pkg/config"
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+	"github.com/opiproject/opi-evpn-bridge/pkg/logging"
+	"github.com/opiproject/opi-evpn-bridge/pkg/modules"
 	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
 	"github.com/vishvananda/netlink"
 	"golang.org/x/sys/unix"
@@ -28,6 +31,9 @@ import (
 	"path"
 )
 
+// logger is the logger of the linux subsystem
+var logger = logging.Logger(logging.Linux).Sugar()
+
 // ModulelgmHandler enmpty interface
 type ModulelgmHandler struct{}
 
@@ -40,6 +46,9 @@ const brStr string = "br-"
 // vxlanStr string constant
 const vxlanStr string = "vxlan-"
 
+// vrfPrefix is the prefix of the vrf names in the infradb
+const vrfPrefix string = "//network.opiproject.org/vrfs/"
+
 // routingTableMax max value of routing table
 const routingTableMax = 4000
 
@@ -55,7 +64,7 @@ func run(cmd []string, flag bool) (string, int) {
 		if flag {
 			panic(fmt.Sprintf("LGM: Command %s': exit code %s;", out, err.Error()))
 		}
-		log.Printf("LGM: Command %s': exit code %s;\n", out, err)
+		logger.Infow("LGM: Command has failed", "command", cmd, "output", string(out), "error", err)
 		return "Error", -1
 	}
 	output := string(out)
@@ -66,16 +75,20 @@ func run(cmd []string, flag bool) (string, int) {
 func (h *ModulelgmHandler) HandleEvent(eventType string, objectData *eventbus.ObjectData) {
 	switch eventType {
 	case "vrf":
-		log.Printf("LGM recevied %s %s\n", eventType, objectData.Name)
+		logger.Infow("LGM received event", logging.Kind(eventType), logging.Name(objectData.Name), logging.ResourceVersion(objectData.ResourceVersion), logging.NotificationID(objectData.NotificationID))
 		handlevrf(objectData)
+		// The routing tables assigned or released for the vrf are persisted together
+		if err := RouteTableGen.Flush(); err != nil {
+			logger.Errorw("LGM: Failed to persist the routing tables", "error", err)
+		}
 	case "svi":
-		log.Printf("LGM recevied %s %s\n", eventType, objectData.Name)
+		logger.Infow("LGM received event", logging.Kind(eventType), logging.Name(objectData.Name), logging.ResourceVersion(objectData.ResourceVersion), logging.NotificationID(objectData.NotificationID))
 		handlesvi(objectData)
 	case "logical-bridge":
-		log.Printf("LGM recevied %s %s\n", eventType, objectData.Name)
+		logger.Infow("LGM received event", logging.Kind(eventType), logging.Name(objectData.Name), logging.ResourceVersion(objectData.ResourceVersion), logging.NotificationID(objectData.NotificationID))
 		handleLB(objectData)
 	default:
-		log.Printf("LGM: error: Unknown event type %s", eventType)
+		logger.Errorw("LGM: Unknown event type", logging.Kind(eventType))
 	}
 }
 
@@ -86,7 +99,7 @@ func handleLB(objectData *eventbus.ObjectData) {
 	var comp common.Component
 	lb, err := infradb.GetLB(objectData.Name)
 	if err != nil {
-		log.Printf("LGM: GetLB error: %s %s\n", err, objectData.Name)
+		logger.Errorw("LGM: Failed to get the Logical Bridge", logging.Name(objectData.Name), "error", err)
 		comp.Name = lgmComp
 		comp.CompStatus = common.ComponentStatusError
 		comp.Details = fmt.Sprintf("LGM: GetLB error: %s %s\n", err, objectData.Name)
@@ -97,12 +110,12 @@ func handleLB(objectData *eventbus.ObjectData) {
 		}
 		err := infradb.UpdateLBStatus(objectData.Name, objectData.ResourceVersion, objectData.NotificationID, nil, comp)
 		if err != nil {
-			log.Printf("error in updating lb status: %s\n", err)
+			logger.Errorw("LGM: Failed to update the Logical Bridge status", logging.Name(objectData.Name), "error", err)
 		}
 		return
 	}
 	if objectData.ResourceVersion != lb.ResourceVersion {
-		log.Printf("LGM: Mismatch in resoruce version %+v\n and lb resource version %+v\n", objectData.ResourceVersion, lb.ResourceVersion)
+		logger.Debugw("LGM: Mismatch in resource version", logging.ResourceVersion(objectData.ResourceVersion), "lbResourceVersion", lb.ResourceVersion)
 		comp.Name = lgmComp
 		comp.CompStatus = common.ComponentStatusError
 		comp.Details = fmt.Sprintf("LGM: Mismatch in resoruce version %+v\n and lb resource version %+v\n", objectData.ResourceVersion, lb.ResourceVersion)
@@ -113,7 +126,7 @@ func handleLB(objectData *eventbus.ObjectData) {
 		}
 		err := infradb.UpdateLBStatus(objectData.Name, objectData.ResourceVersion, objectData.NotificationID, nil, comp)
 		if err != nil {
-			log.Printf("error in updating lb status: %s\n", err)
+			logger.Errorw("LGM: Failed to update the Logical Bridge status", logging.Name(objectData.Name), "error", err)
 		}
 		return
 	}
@@ -125,7 +138,13 @@ func handleLB(objectData *eventbus.ObjectData) {
 		}
 	}
 	if lb.Status.LBOperStatus != infradb.LogicalBridgeOperStatusToBeDeleted {
-		details, status := setUpBridge(lb)
+		var details string
+		var status bool
+		if changes := lb.Changes(); changes != nil {
+			details, status = updateBridge(lb, changes)
+		} else {
+			details, status = setUpBridge(lb)
+		}
 		comp.Name = lgmComp
 		comp.Details = details
 		if status {
@@ -139,10 +158,10 @@ func handleLB(objectData *eventbus.ObjectData) {
 			}
 			comp.CompStatus = common.ComponentStatusError
 		}
-		log.Printf("LGM: %+v \n", comp)
+		logger.Debugw("LGM: component status", logging.Component(comp.Name), "status", comp.CompStatus, "details", comp.Details)
 		err := infradb.UpdateLBStatus(objectData.Name, objectData.ResourceVersion, objectData.NotificationID, nil, comp)
 		if err != nil {
-			log.Printf("error in updating lb status: %s\n", err)
+			logger.Errorw("LGM: Failed to update the Logical Bridge status", logging.Name(objectData.Name), "error", err)
 		}
 	} else {
 		details, status := tearDownBridge(lb)
@@ -159,10 +178,10 @@ func handleLB(objectData *eventbus.ObjectData) {
 				comp.Timer *= 2
 			}
 		}
-		log.Printf("LGM: %+v\n", comp)
+		logger.Debugw("LGM: component status", logging.Component(comp.Name), "status", comp.CompStatus, "details", comp.Details)
 		err := infradb.UpdateLBStatus(objectData.Name, objectData.ResourceVersion, objectData.NotificationID, nil, comp)
 		if err != nil {
-			log.Printf("error in updating lb status: %s\n", err)
+			logger.Errorw("LGM: Failed to update the Logical Bridge status", logging.Name(objectData.Name), "error", err)
 		}
 	}
 }
@@ -174,7 +193,7 @@ func handlesvi(objectData *eventbus.ObjectData) {
 	var comp common.Component
 	svi, err := infradb.GetSvi(objectData.Name)
 	if err != nil {
-		log.Printf("LGM: GetSvi error: %s %s\n", err, objectData.Name)
+		logger.Errorw("LGM: Failed to get the SVI", logging.Name(objectData.Name), "error", err)
 		comp.Name = lgmComp
 		comp.CompStatus = common.ComponentStatusError
 		if comp.Timer == 0 {
@@ -184,12 +203,12 @@ func handlesvi(objectData *eventbus.ObjectData) {
 		}
 		err := infradb.UpdateSviStatus(objectData.Name, objectData.ResourceVersion, objectData.NotificationID, nil, comp)
 		if err != nil {
-			log.Printf("error in updating svi status: %s\n", err)
+			logger.Errorw("LGM: Failed to update the SVI status", logging.Name(objectData.Name), "error", err)
 		}
 		return
 	}
 	if objectData.ResourceVersion != svi.ResourceVersion {
-		log.Printf("LGM: Mismatch in resoruce version %+v\n and svi resource version %+v\n", objectData.ResourceVersion, svi.ResourceVersion)
+		logger.Debugw("LGM: Mismatch in resource version", logging.ResourceVersion(objectData.ResourceVersion), "sviResourceVersion", svi.ResourceVersion)
 		comp.Name = lgmComp
 		comp.CompStatus = common.ComponentStatusError
 		comp.Details = fmt.Sprintf("LGM: Mismatch in resoruce version %+v\n and svi resource version %+v\n", objectData.ResourceVersion, svi.ResourceVersion)
@@ -200,7 +219,7 @@ func handlesvi(objectData *eventbus.ObjectData) {
 		}
 		err := infradb.UpdateSviStatus(objectData.Name, objectData.ResourceVersion, objectData.NotificationID, nil, comp)
 		if err != nil {
-			log.Printf("error in updating svi status: %s\n", err)
+			logger.Errorw("LGM: Failed to update the SVI status", logging.Name(objectData.Name), "error", err)
 		}
 		return
 	}
@@ -212,7 +231,13 @@ func handlesvi(objectData *eventbus.ObjectData) {
 		}
 	}
 	if svi.Status.SviOperStatus != infradb.SviOperStatusToBeDeleted {
-		details, status := setUpSvi(svi)
+		var details string
+		var status bool
+		if changes := svi.Changes(); changes != nil {
+			details, status = updateSvi(svi, changes)
+		} else {
+			details, status = setUpSvi(svi)
+		}
 		comp.Name = lgmComp
 		comp.Details = details
 		if status {
@@ -226,10 +251,10 @@ func handlesvi(objectData *eventbus.ObjectData) {
 			}
 			comp.CompStatus = common.ComponentStatusError
 		}
-		log.Printf("LGM: %+v \n", comp)
+		logger.Debugw("LGM: component status", logging.Component(comp.Name), "status", comp.CompStatus, "details", comp.Details)
 		err := infradb.UpdateSviStatus(objectData.Name, objectData.ResourceVersion, objectData.NotificationID, nil, comp)
 		if err != nil {
-			log.Printf("error in updating svi status: %s\n", err)
+			logger.Errorw("LGM: Failed to update the SVI status", logging.Name(objectData.Name), "error", err)
 		}
 	} else {
 		details, status := tearDownSvi(svi)
@@ -246,10 +271,10 @@ func handlesvi(objectData *eventbus.ObjectData) {
 				comp.Timer *= 2
 			}
 		}
-		log.Printf("LGM: %+v \n", comp)
+		logger.Debugw("LGM: component status", logging.Component(comp.Name), "status", comp.CompStatus, "details", comp.Details)
 		err := infradb.UpdateSviStatus(objectData.Name, objectData.ResourceVersion, objectData.NotificationID, nil, comp)
 		if err != nil {
-			log.Printf("error in updating svi status: %s\n", err)
+			logger.Errorw("LGM: Failed to update the SVI status", logging.Name(objectData.Name), "error", err)
 		}
 	}
 }
@@ -261,7 +286,7 @@ func handlevrf(objectData *eventbus.ObjectData) {
 	var comp common.Component
 	vrf, err := infradb.GetVrf(objectData.Name)
 	if err != nil {
-		log.Printf("LGM: GetVRF error: %s %s\n", err, objectData.Name)
+		logger.Errorw("LGM: Failed to get the VRF", logging.Name(objectData.Name), "error", err)
 		comp.Name = lgmComp
 		comp.CompStatus = common.ComponentStatusError
 		comp.Details = fmt.Sprintf("LGM: GetVRF error: %s %s\n", err, objectData.Name)
@@ -272,12 +297,12 @@ func handlevrf(objectData *eventbus.ObjectData) {
 		}
 		err := infradb.UpdateVrfStatus(objectData.Name, objectData.ResourceVersion, objectData.NotificationID, nil, comp)
 		if err != nil {
-			log.Printf("error in updating vrf status: %s\n", err)
+			logger.Errorw("LGM: Failed to update the VRF status", logging.Name(objectData.Name), "error", err)
 		}
 		return
 	}
 	if objectData.ResourceVersion != vrf.ResourceVersion {
-		log.Printf("LGM: Mismatch in resoruce version %+v\n and vrf resource version %+v\n", objectData.ResourceVersion, vrf.ResourceVersion)
+		logger.Debugw("LGM: Mismatch in resource version", logging.ResourceVersion(objectData.ResourceVersion), "vrfResourceVersion", vrf.ResourceVersion)
 		comp.Name = lgmComp
 		comp.CompStatus = common.ComponentStatusError
 		comp.Details = fmt.Sprintf("LGM: Mismatch in resoruce version %+v\n and vrf resource version %+v\n", objectData.ResourceVersion, vrf.ResourceVersion)
@@ -288,7 +313,7 @@ func handlevrf(objectData *eventbus.ObjectData) {
 		}
 		err := infradb.UpdateVrfStatus(objectData.Name, objectData.ResourceVersion, objectData.NotificationID, nil, comp)
 		if err != nil {
-			log.Printf("error in updating vrf status: %s\n", err)
+			logger.Errorw("LGM: Failed to update the VRF status", logging.Name(objectData.Name), "error", err)
 		}
 		return
 	}
@@ -300,7 +325,13 @@ func handlevrf(objectData *eventbus.ObjectData) {
 		}
 	}
 	if vrf.Status.VrfOperStatus != infradb.VrfOperStatusToBeDeleted {
-		details, status := setUpVrf(vrf)
+		var details string
+		var status bool
+		if changes := vrf.Changes(); changes != nil {
+			details, status = updateVrf(vrf, changes)
+		} else {
+			details, status = setUpVrf(vrf)
+		}
 		comp.Name = lgmComp
 		comp.Details = details
 		if status {
@@ -314,10 +345,10 @@ func handlevrf(objectData *eventbus.ObjectData) {
 			}
 			comp.CompStatus = common.ComponentStatusError
 		}
-		log.Printf("LGM: %+v \n", comp)
+		logger.Debugw("LGM: component status", logging.Component(comp.Name), "status", comp.CompStatus, "details", comp.Details)
 		err := infradb.UpdateVrfStatus(objectData.Name, objectData.ResourceVersion, objectData.NotificationID, vrf.Metadata, comp)
 		if err != nil {
-			log.Printf("error in updating vrf status: %s\n", err)
+			logger.Errorw("LGM: Failed to update the VRF status", logging.Name(objectData.Name), "error", err)
 		}
 	} else {
 		details, status := tearDownVrf(vrf)
@@ -334,16 +365,16 @@ func handlevrf(objectData *eventbus.ObjectData) {
 				comp.Timer *= 2
 			}
 		}
-		log.Printf("LGM: %+v\n", comp)
+		logger.Debugw("LGM: component status", logging.Component(comp.Name), "status", comp.CompStatus, "details", comp.Details)
 		err := infradb.UpdateVrfStatus(objectData.Name, objectData.ResourceVersion, objectData.NotificationID, nil, comp)
 		if err != nil {
-			log.Printf("error in updating vrf status: %s\n", err)
+			logger.Errorw("LGM: Failed to update the VRF status", logging.Name(objectData.Name), "error", err)
 		}
 	}
 }
 
-// ipMtu variable int
-var ipMtu int
+// ipMtu variable int, changed when the config is reloaded
+var ipMtu atomic.Int64
 
 // brTenant variable string
 var brTenant string
@@ -357,11 +388,32 @@ var nlink utils.Netlink
 // RouteTableGen table id generate variable
 var RouteTableGen utils.IDPool
 
+func init() {
+	// The LGM sets up br-tenant that the other modules rely on so it is initialized first
+	modules.Register(modules.Module{
+		Name:         lgmComp,
+		BuildEnvs:    []string{"ci"},
+		Events:       []string{"vrf", "svi", "logical-bridge"},
+		Priority:     1,
+		Initialize:   Initialize,
+		DeInitialize: DeInitialize,
+		Stop:         Stop,
+	})
+	config.OnReload(applyReloadedConfig)
+}
+
+// applyReloadedConfig applies the runtime changes of the config,
+// the new MTU applies to the objects realized from now on
+func applyReloadedConfig(cfg *config.Config) {
+	ipMtu.Store(int64(cfg.LinuxFrr.IPMtu))
+	remediateDrift.Store(cfg.Drift.Remediate)
+}
+
 // Initialize initializes the config, logger and subscribers
 func Initialize() {
 	eb := eventbus.EBus
 	var ok bool
-	for _, subscriberConfig := range config.GlobalConfig.Subscribers {
+	for _, subscriberConfig := range config.Current().Subscribers {
 		if subscriberConfig.Name == lgmComp {
 			for _, eventType := range subscriberConfig.Events {
 				eb.StartSubscriber(subscriberConfig.Name, eventType, subscriberConfig.Priority, &ModulelgmHandler{})
@@ -369,31 +421,41 @@ func Initialize() {
 		}
 	}
 	brTenant = "br-tenant"
-	ipMtu = config.GlobalConfig.LinuxFrr.IPMtu
+	ipMtu.Store(int64(config.Current().LinuxFrr.IPMtu))
 	ctx = context.Background()
 	if RouteTableGen, ok = utils.IDPoolInit("RTtable", routingTableMin, routingTableMax); !ok {
-		log.Printf("LGM: Failed in the assigning id \n")
+		logger.Errorw("LGM: Failed in the assigning id")
 		return
 	}
 	nlink = utils.NewNetlinkWrapperWithArgs(false)
+	reserveRoutingTables()
 	// Set up the static configuration parts
 	_, err := nlink.LinkByName(ctx, brTenant)
 	if err != nil {
 		setUpTenantBridge()
 	}
+	startDriftDetection()
 }
 
 // DeInitialize function handles stops functionality
 func DeInitialize() {
 	eb := eventbus.EBus
+	stopDriftDetection()
 	err := TearDownTenantBridge()
 	if err != nil {
-		log.Printf("LGM: Failed to tear down br-tenant: %v\n", err)
+		logger.Errorw("LGM: Failed to tear down br-tenant", "error", err)
 	}
 	eb.UnsubscribeModule("lgm")
 }
+
+// Stop unsubscribes the LGM but leaves br-tenant and the realized links in place
+func Stop() {
+	stopDriftDetection()
+	eventbus.EBus.UnsubscribeModule(lgmComp)
+}
+
 func setUpTenantBridge() {
-	brTenantMtu := ipMtu + 20
+	brTenantMtu := int(ipMtu.Load()) + 20
 	vlanfiltering := true
 	bridge := &netlink.Bridge{LinkAttrs: netlink.LinkAttrs{Name: brTenant},
 		VlanDefaultPVID: new(uint16),
@@ -401,21 +463,156 @@ func setUpTenantBridge() {
 	}
 
 	if err := nlink.LinkAdd(ctx, bridge); err != nil {
-		log.Fatalf("LGM: Failed to create br-tenant: %v\n", err)
+		logger.Fatalw("LGM: Failed to create br-tenant", "error", err)
 	}
 
 	if err := nlink.LinkSetMTU(ctx, bridge, brTenantMtu); err != nil {
-		log.Fatalf("LGM : Unable to set MTU %v to br-tenant: %v\n", brTenantMtu, err)
+		logger.Fatalw("LGM: Unable to set MTU to br-tenant", "mtu", brTenantMtu, "error", err)
 	}
 
 	if err := nlink.LinkSetUp(ctx, bridge); err != nil {
-		log.Fatalf("LGM: Failed to set up br-tenant: %v\n", err)
+		logger.Fatalw("LGM: Failed to set up br-tenant", "error", err)
+	}
+}
+
+// reserveRoutingTables restores the routing tables persisted by a previous run and reserves
+// the ones recorded in the metadata of the vrfs, the tables of the vrf links found in the kernel
+// take precedence so that they are not assigned to another vrf. The vrf links
+// of the vrfs that are not in the infra db are left out, they do not hold a table
+func reserveRoutingTables() {
+	if err := RouteTableGen.Persist(infradb.IDPoolStore{}); err != nil {
+		logger.Errorw("LGM: Failed to restore the routing tables", "error", err)
+	}
+	defer func() {
+		if err := RouteTableGen.Flush(); err != nil {
+			logger.Errorw("LGM: Failed to persist the routing tables", "error", err)
+		}
+	}()
+	known := make(map[string]bool)
+	if vrfs, err := infradb.GetAllVrfs(); err == nil {
+		for _, vrf := range vrfs {
+			known[vrf.Name] = true
+			if path.Base(vrf.Name) == "GRD" || len(vrf.Metadata.RoutingTable) == 0 || vrf.Metadata.RoutingTable[0] == nil {
+				continue
+			}
+			if table := *vrf.Metadata.RoutingTable[0]; table != 0 {
+				RouteTableGen.ReserveID(vrf.Name, table)
+			}
+		}
+	}
+	links, err := nlink.LinkList(ctx)
+	if err != nil {
+		logger.Errorw("LGM: Failed to list the links", "error", err)
+		return
+	}
+	tables := make(map[string]uint32)
+	for _, link := range links {
+		if vrfLink, ok := link.(*netlink.Vrf); ok && known[vrfPrefix+vrfLink.Name] {
+			tables[vrfPrefix+vrfLink.Name] = vrfLink.Table
+		}
+	}
+	if fixed := RouteTableGen.CheckConsistency(tables); len(fixed) != 0 {
+		logger.Warnw("LGM: The routing tables have been aligned with the kernel", "vrfs", fixed)
+	}
+}
+
+// vrfRoutingTable returns the routing table of the vrf, the table of the existing vrf link
+// or the one recorded in the metadata if any so that a restart keeps the routes in place
+func vrfRoutingTable(vrf *infradb.Vrf) uint32 {
+	if link, err := nlink.LinkByName(ctx, path.Base(vrf.Name)); err == nil {
+		if vrfLink, ok := link.(*netlink.Vrf); ok && RouteTableGen.ReserveID(vrf.Name, vrfLink.Table) {
+			return vrfLink.Table
+		}
+	}
+	if len(vrf.Metadata.RoutingTable) != 0 && vrf.Metadata.RoutingTable[0] != nil {
+		if table := *vrf.Metadata.RoutingTable[0]; table != 0 && RouteTableGen.ReserveID(vrf.Name, table) {
+			return table
+		}
+	}
+	return RouteTableGen.GetID(vrf.Name)
+}
+
+// addOrAdoptLink creates the link, or adopts the link of the same name and type
+// left in place by a previous run of the bridge. An existing link whose attributes
+// do not match is deleted and created again. It returns the link to use and whether it has been adopted.
+func addOrAdoptLink(link netlink.Link, matches func(existing netlink.Link) error) (netlink.Link, bool, error) {
+	err := nlink.LinkAdd(ctx, link)
+	if err == nil {
+		return link, false, nil
+	}
+	if !errors.Is(err, unix.EEXIST) {
+		return nil, false, err
+	}
+	existing, lerr := nlink.LinkByName(ctx, link.Attrs().Name)
+	if lerr != nil {
+		return nil, false, err
+	}
+	if existing.Type() != link.Type() {
+		return nil, false, fmt.Errorf("link %s already exists with type %s instead of %s", link.Attrs().Name, existing.Type(), link.Type())
+	}
+	if matches != nil {
+		if mismatch := matches(existing); mismatch != nil {
+			logger.Infow("LGM: Recreating the existing link", "type", existing.Type(), "link", link.Attrs().Name, "mismatch", mismatch)
+			if err := nlink.LinkDel(ctx, existing); err != nil {
+				return nil, false, err
+			}
+			if err := nlink.LinkAdd(ctx, link); err != nil {
+				return nil, false, err
+			}
+			return link, false, nil
+		}
+	}
+	logger.Infow("LGM: Adopting the existing link", "type", existing.Type(), "link", link.Attrs().Name)
+	return existing, true, nil
+}
+
+// vxlanMatches checks an existing vxlan link has the expected VNI and source address
+func vxlanMatches(vni int, src net.IP) func(netlink.Link) error {
+	return func(link netlink.Link) error {
+		vxlan, ok := link.(*netlink.Vxlan)
+		if !ok {
+			return nil
+		}
+		if vxlan.VxlanId != vni {
+			return fmt.Errorf("vni %d instead of %d", vxlan.VxlanId, vni)
+		}
+		if !vxlan.SrcAddr.Equal(src) {
+			return fmt.Errorf("local %s instead of %s", vxlan.SrcAddr, src)
+		}
+		return nil
+	}
+}
+
+// vrfMatches checks an existing vrf link has the expected routing table
+func vrfMatches(table uint32) func(netlink.Link) error {
+	return func(link netlink.Link) error {
+		if vrf, ok := link.(*netlink.Vrf); ok && vrf.Table != table {
+			return fmt.Errorf("table %d instead of %d", vrf.Table, table)
+		}
+		return nil
+	}
+}
+
+// vlanMatches checks an existing vlan link has the expected vlan id and parent link
+func vlanMatches(vlanID int, parentIndex int) func(netlink.Link) error {
+	return func(link netlink.Link) error {
+		vlan, ok := link.(*netlink.Vlan)
+		if !ok {
+			return nil
+		}
+		if vlan.VlanId != vlanID {
+			return fmt.Errorf("vlan id %d instead of %d", vlan.VlanId, vlanID)
+		}
+		if vlan.ParentIndex != parentIndex {
+			return fmt.Errorf("parent link %d instead of %d", vlan.ParentIndex, parentIndex)
+		}
+		return nil
 	}
 }
 
 // routingtableBusy checks if the route is in filterred list
 func routingtableBusy(table uint32) (bool, error) {
-	routeList, err := nlink.RouteListFiltered(ctx, netlink.FAMILY_V4, &netlink.Route{Table: int(table)}, netlink.RT_FILTER_TABLE)
+	routeList, err := nlink.RouteListFiltered(ctx, netlink.FAMILY_ALL, &netlink.Route{Table: int(table)}, netlink.RT_FILTER_TABLE)
 	if err != nil {
 		return false, err
 	}
@@ -428,31 +625,39 @@ func setUpBridge(lb *infradb.LogicalBridge) (string, bool) {
 	if !reflect.ValueOf(lb.Spec.Vni).IsZero() {
 		brIntf, err := nlink.LinkByName(ctx, brTenant)
 		if err != nil {
-			log.Printf("LGM: Failed to get link information for %s: %v\n", brTenant, err)
+			logger.Errorw("LGM: Failed to get link information", "link", brTenant, "error", err)
 			return fmt.Sprintf("LGM: Failed to get link information for %s: %v\n", brTenant, err), false
 		}
-		vxlan := &netlink.Vxlan{LinkAttrs: netlink.LinkAttrs{Name: link, MTU: ipMtu}, VxlanId: int(*lb.Spec.Vni), Port: 4789, Learning: false, SrcAddr: lb.Spec.VtepIP.IP}
-		if err := nlink.LinkAdd(ctx, vxlan); err != nil {
-			log.Printf("LGM: Failed to create Vxlan linki %s: %v\n", link, err)
+		vxlan, adopted, err := addOrAdoptLink(&netlink.Vxlan{LinkAttrs: netlink.LinkAttrs{Name: link, MTU: int(ipMtu.Load())}, VxlanId: int(*lb.Spec.Vni), Port: 4789, Learning: false, SrcAddr: lb.Spec.VtepIP.IP},
+			vxlanMatches(int(*lb.Spec.Vni), lb.Spec.VtepIP.IP))
+		if err != nil {
+			logger.Errorw("LGM: Failed to create Vxlan link", "link", link, "error", err)
 			return fmt.Sprintf("LGM: Failed to create Vxlan linki %s: %v\n", link, err), false
 		}
+		// An adopted link keeps the mtu of the previous run
+		if adopted {
+			if err = nlink.LinkSetMTU(ctx, vxlan, int(ipMtu.Load())); err != nil {
+				logger.Errorw("LGM: Failed to set MTU of the link", "link", link, "error", err)
+				return fmt.Sprintf("LGM: Failed to set MTU for %s: %v\n", link, err), false
+			}
+		}
 		// Example: ip link set vxlan-<lb-vlan-id> master br-tenant addrgenmode none
 		if err = nlink.LinkSetMaster(ctx, vxlan, brIntf); err != nil {
-			log.Printf("LGM: Failed to add Vxlan %s to bridge %s: %v\n", link, brTenant, err)
+			logger.Errorw("LGM: Failed to add Vxlan to bridge", "link", link, "bridge", brTenant, "error", err)
 			return fmt.Sprintf("LGM: Failed to add Vxlan %s to bridge %s: %v\n", link, brTenant, err), false
 		}
 		// Example: ip link set vxlan-<lb-vlan-id> up
 		if err = nlink.LinkSetUp(ctx, vxlan); err != nil {
-			log.Printf("LGM: Failed to up Vxlan link %s: %v\n", link, err)
+			logger.Errorw("LGM: Failed to up Vxlan link", "link", link, "error", err)
 			return fmt.Sprintf("LGM: Failed to up Vxlan link %s: %v\n", link, err), false
 		}
 		// Example: bridge vlan add dev vxlan-<lb-vlan-id> vid <lb-vlan-id> pvid untagged
 		if err = nlink.BridgeVlanAdd(ctx, vxlan, uint16(lb.Spec.VlanID), true, true, false, false); err != nil {
-			log.Printf("LGM: Failed to add vlan to bridge %s: %v\n", brTenant, err)
+			logger.Errorw("LGM: Failed to add vlan to bridge", "link", link, "bridge", brTenant, "error", err)
 			return fmt.Sprintf("LGM: Failed to add vlan to bridge %s: %v\n", brTenant, err), false
 		}
 		if err = nlink.LinkSetBrNeighSuppress(ctx, vxlan, true); err != nil {
-			log.Printf("LGM: Failed to add bridge %v neigh_suppress: %s\n", vxlan, err)
+			logger.Errorw("LGM: Failed to add bridge neigh_suppress", "link", link, "error", err)
 			return fmt.Sprintf("LGM: Failed to add bridge %v neigh_suppress: %s\n", vxlan, err), false
 		}
 
@@ -461,11 +666,23 @@ func setUpBridge(lb *infradb.LogicalBridge) (string, bool) {
 	return "", true
 }
 
+// updateBridge realizes the changes of an updated bridge
+func updateBridge(lb *infradb.LogicalBridge, changes infradb.SpecDiff) (string, bool) {
+	if !changes.Has(infradb.FieldVtepIP) || reflect.ValueOf(lb.Spec.Vni).IsZero() {
+		return "", true
+	}
+	// The source address of a vxlan link cannot be changed so the link is recreated
+	if details, ok := tearDownBridge(lb); !ok {
+		return details, false
+	}
+	return setUpBridge(lb)
+}
+
 // setUpVrf sets up the vrf
 //
 //nolint:funlen,gocognit
 func setUpVrf(vrf *infradb.Vrf) (string, bool) {
-	IPMtu := fmt.Sprintf("%+v", ipMtu)
+	IPMtu := fmt.Sprintf("%+v", int(ipMtu.Load()))
 	if path.Base(vrf.Name) == "GRD" {
 		vrf.Metadata.RoutingTable = make([]*uint32, 2)
 		vrf.Metadata.RoutingTable[0] = new(uint32)
@@ -474,60 +691,59 @@ func setUpVrf(vrf *infradb.Vrf) (string, bool) {
 		*vrf.Metadata.RoutingTable[1] = 255
 		return "", true
 	}
+	// The table is looked up before the metadata is reset, and recorded right away
+	// so that a retry or a restart reuses it
+	routingtable := vrfRoutingTable(vrf)
 	vrf.Metadata.RoutingTable = make([]*uint32, 1)
 	vrf.Metadata.RoutingTable[0] = new(uint32)
-	var routingtable uint32
-	name := vrf.Name
-	routingtable = RouteTableGen.GetID(name)
-	log.Printf("LGM assigned id %+v for vrf name %s\n", routingtable, vrf.Name)
+	*vrf.Metadata.RoutingTable[0] = routingtable
+	logger.Debugw("LGM: Assigned the routing table to the VRF", logging.Name(vrf.Name), "table", routingtable)
 	isbusy, err := routingtableBusy(routingtable)
 	if err != nil {
-		log.Printf("LGM : Error occurred when checking if routing table %d is busy: %+v\n", routingtable, err)
+		logger.Errorw("LGM: Error occurred when checking if routing table is busy", "table", routingtable, "error", err)
 		return "", false
 	}
 	if !isbusy {
-		log.Printf("LGM: Routing Table %d is not busy\n", routingtable)
+		logger.Infow("LGM: Routing Table is not busy", "table", routingtable)
 	}
 	var vtip string
 	if !reflect.ValueOf(vrf.Spec.VtepIP).IsZero() {
 		vtip = fmt.Sprintf("%+v", vrf.Spec.VtepIP.IP)
 		// Verify that the specified VTEP IP exists as local IP
-		err := nlink.RouteListIPTable(ctx, vtip)
-		// Not found similar API in viswananda library so retain the linux commands as it is .. not able to get the route list exact vtip table local
-		if !err {
-			log.Printf(" LGM: VTEP IP not found: %+v\n", vrf.Spec.VtepIP)
+		if !nlink.RouteListIPTable(ctx, vrf.Spec.VtepIP.IP) {
+			logger.Warnw("LGM: VTEP IP not found", logging.Name(vrf.Name))
 			return fmt.Sprintf(" LGM: VTEP IP not found: %+v\n", vrf.Spec.VtepIP), false
 		}
 	}
-	log.Printf("setUpVrf: %s %d\n", vtip, routingtable)
+	logger.Infow("LGM: Setting up the VRF", logging.Name(vrf.Name), "vtepIP", vtip, "table", routingtable)
 	// Create the vrf interface for the specified routing table and add loopback address
 
-	linkAdderr := nlink.LinkAdd(ctx, &netlink.Vrf{
+	_, _, linkAdderr := addOrAdoptLink(&netlink.Vrf{
 		LinkAttrs: netlink.LinkAttrs{Name: path.Base(vrf.Name)},
 		Table:     routingtable,
-	})
+	}, vrfMatches(routingtable))
 	if linkAdderr != nil {
-		log.Printf("LGM: Error in Adding vrf link table %d\n", routingtable)
-		return fmt.Sprintf("LGM: Error in Adding vrf link table %d\n", routingtable), false
+		logger.Errorw("LGM: Error in adding the VRF link", logging.Name(vrf.Name), "table", routingtable, "error", linkAdderr)
+		return fmt.Sprintf("LGM: Error in Adding vrf link table %d: %v\n", routingtable, linkAdderr), false
 	}
 
-	log.Printf("LGM: vrf link %s Added with table id %d\n", vrf.Name, routingtable)
+	logger.Infow("LGM: VRF link added", logging.Name(vrf.Name), "table", routingtable)
 
 	link, linkErr := nlink.LinkByName(ctx, path.Base(vrf.Name))
 	if linkErr != nil {
-		log.Printf("LGM : Link %s not found\n", vrf.Name)
+		logger.Warnw("LGM: Link not found", logging.Name(vrf.Name), "error", linkErr)
 		return fmt.Sprintf("LGM : Link %s not found\n", vrf.Name), false
 	}
 
-	linkmtuErr := nlink.LinkSetMTU(ctx, link, ipMtu)
+	linkmtuErr := nlink.LinkSetMTU(ctx, link, int(ipMtu.Load()))
 	if linkmtuErr != nil {
-		log.Printf("LGM : Unable to set MTU to link %s \n", vrf.Name)
+		logger.Errorw("LGM: Unable to set MTU to link", logging.Name(vrf.Name), "error", linkmtuErr)
 		return fmt.Sprintf("LGM : Unable to set MTU to link %s \n", vrf.Name), false
 	}
 
 	linksetupErr := nlink.LinkSetUp(ctx, link)
 	if linksetupErr != nil {
-		log.Printf("LGM : Unable to set link %s UP \n", vrf.Name)
+		logger.Errorw("LGM: Unable to set link UP", logging.Name(vrf.Name), "error", linksetupErr)
 		return fmt.Sprintf("LGM : Unable to set link %s UP \n", vrf.Name), false
 	}
 	Lbip := fmt.Sprintf("%+v", vrf.Spec.LoopbackIP.IP)
@@ -536,13 +752,18 @@ func setUpVrf(vrf *infradb.Vrf) (string, bool) {
 	var Addrs = &netlink.Addr{
 		IPNet: address,
 	}
+	if address.IP.To4() == nil {
+		// The loopback address is unique so skip the duplicate address detection
+		Addrs.Flags = unix.IFA_F_NODAD
+	}
+	// The address is already set on an adopted link
 	addrErr := nlink.AddrAdd(ctx, link, Addrs)
-	if addrErr != nil {
-		log.Printf("LGM: Unable to set the loopback ip to vrf link %s \n", vrf.Name)
+	if addrErr != nil && !errors.Is(addrErr, unix.EEXIST) {
+		logger.Errorw("LGM: Unable to set the loopback ip to vrf link", logging.Name(vrf.Name), "error", addrErr)
 		return fmt.Sprintf("LGM: Unable to set the loopback ip to vrf link %s \n", vrf.Name), false
 	}
 
-	log.Printf("LGM: Added Address %s dev %s\n", Lbip, vrf.Name)
+	logger.Infow("LGM: Added the loopback address to the VRF link", logging.Name(vrf.Name), "address", Lbip)
 
 	Src1 := net.IPv4(0, 0, 0, 0)
 	route := netlink.Route{
@@ -553,12 +774,32 @@ func setUpVrf(vrf *infradb.Vrf) (string, bool) {
 		Src:      Src1,
 	}
 	routeaddErr := nlink.RouteAdd(ctx, &route)
-	if routeaddErr != nil {
-		log.Printf("LGM : Failed in adding Route throw default %+v\n", routeaddErr)
+	if routeaddErr != nil && !errors.Is(routeaddErr, unix.EEXIST) {
+		logger.Errorw("LGM: Failed in adding Route throw default", "table", routingtable, "error", routeaddErr)
 		return fmt.Sprintf("LGM : Failed in adding Route throw default %+v\n", routeaddErr), false
 	}
 
-	log.Printf("LGM : Added route throw default table %d proto opi_evpn_br metric 9999\n", routingtable)
+	logger.Infow("LGM: Added the default throw route", "table", routingtable)
+
+	_, defaultV6, _ := net.ParseCIDR("::/0")
+	route6 := netlink.Route{
+		Table:    int(routingtable),
+		Type:     unix.RTN_THROW,
+		Protocol: 255,
+		Priority: 9999,
+		Family:   netlink.FAMILY_V6,
+		Dst:      defaultV6,
+	}
+	if routeaddErr := nlink.RouteAdd(ctx, &route6); routeaddErr != nil && !errors.Is(routeaddErr, unix.EEXIST) {
+		// The IPv6 throw route is only mandatory when the VRF has an IPv6 loopback,
+		// as IPv6 might be disabled in the kernel otherwise
+		logger.Errorw("LGM: Failed in adding Route throw default ipv6", "table", routingtable, "error", routeaddErr)
+		if vrf.Spec.LoopbackIP.IP.To4() == nil {
+			return fmt.Sprintf("LGM : Failed in adding Route throw default ipv6 %+v\n", routeaddErr), false
+		}
+	} else {
+		logger.Infow("LGM: Added the default ipv6 throw route", "table", routingtable)
+	}
 	// Disable reverse-path filtering to accept ingress traffic punted by the pipeline
 	// disable_rp_filter("rep-"+vrf.Name)
 	// Configuration specific for VRFs associated with L3 EVPN
@@ -568,88 +809,175 @@ func setUpVrf(vrf *infradb.Vrf) (string, bool) {
 		// name. We need to assign a true random MAC address to avoid collisions when pairing two
 		// servers.
 
-		brErr := nlink.LinkAdd(ctx, &netlink.Bridge{
+		_, brAdopted, brErr := addOrAdoptLink(&netlink.Bridge{
 			LinkAttrs: netlink.LinkAttrs{Name: brStr + path.Base(vrf.Name)},
-		})
+		}, nil)
 		if brErr != nil {
-			log.Printf("LGM : Error in added bridge port\n")
+			logger.Errorw("LGM: Error in adding the bridge link of the VRF", logging.Name(vrf.Name), "error", brErr)
 			return fmt.Sprintf("LGM : Error in added bridge port %v", brErr), false
 		}
-		log.Printf("LGM : Added link br-%s type bridge\n", vrf.Name)
-
-		rmac := fmt.Sprintf("%+v", GenerateMac()) // str(macaddress.MAC(b'\x00'+random.randbytes(5))).replace("-", ":")
-		hw, _ := net.ParseMAC(rmac)
+		logger.Infow("LGM: Added the bridge link of the VRF", logging.Name(vrf.Name))
 
 		linkBr, brErr := nlink.LinkByName(ctx, brStr+path.Base(vrf.Name))
 		if brErr != nil {
-			log.Printf("LGM : Error in getting the br-%s\n", vrf.Name)
+			logger.Errorw("LGM: Error in getting the bridge link of the VRF", logging.Name(vrf.Name), "error", brErr)
 			return fmt.Sprintf("LGM : Error in getting the br-%s\n", vrf.Name), false
 		}
-		hwErr := nlink.LinkSetHardwareAddr(ctx, linkBr, hw)
-		if hwErr != nil {
-			log.Printf("LGM: Failed in the setting Hardware Address\n")
-			return fmt.Sprintf("LGM: Failed in the setting Hardware Address: %v\n", hwErr), false
+		// An adopted bridge keeps its MAC address, the router MAC already advertised to the peers
+		if !brAdopted {
+			rmac := fmt.Sprintf("%+v", GenerateMac()) // str(macaddress.MAC(b'\x00'+random.randbytes(5))).replace("-", ":")
+			hw, _ := net.ParseMAC(rmac)
+			hwErr := nlink.LinkSetHardwareAddr(ctx, linkBr, hw)
+			if hwErr != nil {
+				logger.Errorw("LGM: Failed in the setting Hardware Address", logging.Name(vrf.Name), "error", hwErr)
+				return fmt.Sprintf("LGM: Failed in the setting Hardware Address: %v\n", hwErr), false
+			}
 		}
 
-		linkmtuErr := nlink.LinkSetMTU(ctx, linkBr, ipMtu)
+		linkmtuErr := nlink.LinkSetMTU(ctx, linkBr, int(ipMtu.Load()))
 		if linkmtuErr != nil {
-			log.Printf("LGM : Unable to set MTU to link br-%s \n", vrf.Name)
+			logger.Errorw("LGM: Unable to set MTU to the bridge link of the VRF", logging.Name(vrf.Name), "error", linkmtuErr)
 			return fmt.Sprintf("LGM : Unable to set MTU to link br-%s \n", vrf.Name), false
 		}
 
 		linkMaster, errMaster := nlink.LinkByName(ctx, path.Base(vrf.Name))
 		if errMaster != nil {
-			log.Printf("LGM : Error in getting the %s\n", vrf.Name)
+			logger.Errorw("LGM: Error in getting the VRF link", logging.Name(vrf.Name), "error", errMaster)
 			return fmt.Sprintf("LGM : Error in getting the %s\n", vrf.Name), false
 		}
 
 		err := nlink.LinkSetMaster(ctx, linkBr, linkMaster)
 		if err != nil {
-			log.Printf("LGM : Unable to set the master to br-%s link", vrf.Name)
+			logger.Errorw("LGM: Unable to set the master of the bridge link of the VRF", logging.Name(vrf.Name), "error", err)
 			return fmt.Sprintf("LGM : Unable to set the master to br-%s link", vrf.Name), false
 		}
 
 		linksetupErr = nlink.LinkSetUp(ctx, linkBr)
 		if linksetupErr != nil {
-			log.Printf("LGM : Unable to set link %s UP \n", vrf.Name)
+			logger.Errorw("LGM: Unable to set link UP", logging.Name(vrf.Name), "error", linksetupErr)
 			return fmt.Sprintf("LGM : Unable to set link %s UP \n", vrf.Name), false
 		}
-		log.Printf("LGM: link set  br-%s master  %s up mtu \n", vrf.Name, IPMtu)
+		logger.Infow("LGM: Bridge link of the VRF set up", logging.Name(vrf.Name), "mtu", IPMtu)
 
 		// Create the VXLAN link in the external bridge
+		if details, ok := setUpVrfVxlan(vrf, linkBr); !ok {
+			return details, false
+		}
+	}
+	details := fmt.Sprintf("{\"routingtable\":\"%d\"}", routingtable)
+	return details, true
+}
 
-		SrcVtep := vrf.Spec.VtepIP.IP
-		vxlanErr := nlink.LinkAdd(ctx, &netlink.Vxlan{
-			LinkAttrs: netlink.LinkAttrs{Name: vxlanStr + path.Base(vrf.Name), MTU: ipMtu}, VxlanId: int(*vrf.Spec.Vni), SrcAddr: SrcVtep, Learning: false, Proxy: true, Port: 4789})
-		if vxlanErr != nil {
-			log.Printf("LGM : Error in added vxlan port\n")
-			return fmt.Sprintf("LGM : Error in added vxlan port %v\n", vxlanErr), false
+// setUpVrfVxlan creates the VXLAN link of the vrf in the external bridge
+func setUpVrfVxlan(vrf *infradb.Vrf, linkBr netlink.Link) (string, bool) {
+	SrcVtep := vrf.Spec.VtepIP.IP
+	vxlan, adopted, vxlanErr := addOrAdoptLink(&netlink.Vxlan{
+		LinkAttrs: netlink.LinkAttrs{Name: vxlanStr + path.Base(vrf.Name), MTU: int(ipMtu.Load())}, VxlanId: int(*vrf.Spec.Vni), SrcAddr: SrcVtep, Learning: false, Proxy: true, Port: 4789},
+		vxlanMatches(int(*vrf.Spec.Vni), SrcVtep))
+	if vxlanErr != nil {
+		logger.Errorw("LGM: Error in adding the vxlan link of the VRF", logging.Name(vrf.Name), "error", vxlanErr)
+		return fmt.Sprintf("LGM : Error in added vxlan port %v\n", vxlanErr), false
+	}
+	// An adopted link keeps the mtu of the previous run
+	if adopted {
+		if vxlanErr = nlink.LinkSetMTU(ctx, vxlan, int(ipMtu.Load())); vxlanErr != nil {
+			logger.Errorw("LGM: Unable to set MTU to the vxlan link of the VRF", logging.Name(vrf.Name), "error", vxlanErr)
+			return fmt.Sprintf("LGM : Unable to set MTU to link %s \n", vxlanStr+path.Base(vrf.Name)), false
 		}
+	}
 
-		log.Printf("LGM : link added vxlan-%s type vxlan id %d local %s dstport 4789 nolearning proxy\n", vrf.Name, *vrf.Spec.Vni, vtip)
+	logger.Infow("LGM: Added the vxlan link of the VRF", logging.Name(vrf.Name), "vni", *vrf.Spec.Vni, "srcVtep", SrcVtep)
 
-		linkVxlan, vxlanErr := nlink.LinkByName(ctx, vxlanStr+path.Base(vrf.Name))
-		if vxlanErr != nil {
-			log.Printf("LGM : Error in getting the %s\n", vxlanStr+vrf.Name)
-			return fmt.Sprintf("LGM : Error in getting the %s\n", vxlanStr+vrf.Name), false
-		}
+	linkVxlan, vxlanErr := nlink.LinkByName(ctx, vxlanStr+path.Base(vrf.Name))
+	if vxlanErr != nil {
+		logger.Errorw("LGM: Error in getting the vxlan link of the VRF", logging.Name(vrf.Name), "error", vxlanErr)
+		return fmt.Sprintf("LGM : Error in getting the %s\n", vxlanStr+vrf.Name), false
+	}
 
-		err = nlink.LinkSetMaster(ctx, linkVxlan, linkBr)
-		if err != nil {
-			log.Printf("LGM : Unable to set the master to vxlan-%s link", vrf.Name)
-			return fmt.Sprintf("LGM : Unable to set the master to vxlan-%s link", vrf.Name), false
-		}
+	err := nlink.LinkSetMaster(ctx, linkVxlan, linkBr)
+	if err != nil {
+		logger.Errorw("LGM: Unable to set the master of the vxlan link of the VRF", logging.Name(vrf.Name), "error", err)
+		return fmt.Sprintf("LGM : Unable to set the master to vxlan-%s link", vrf.Name), false
+	}
 
-		log.Printf("LGM: vrf Link vxlan setup master\n")
+	logger.Infow("LGM: Master of the vxlan link of the VRF set up", logging.Name(vrf.Name))
 
-		linksetupErr = nlink.LinkSetUp(ctx, linkVxlan)
-		if linksetupErr != nil {
-			log.Printf("LGM : Unable to set link %s UP \n", vrf.Name)
-			return fmt.Sprintf("LGM : Unable to set link %s UP \n", vrf.Name), false
-		}
+	linksetupErr := nlink.LinkSetUp(ctx, linkVxlan)
+	if linksetupErr != nil {
+		logger.Errorw("LGM: Unable to set link UP", logging.Name(vrf.Name), "error", linksetupErr)
+		return fmt.Sprintf("LGM : Unable to set link %s UP \n", vrf.Name), false
+	}
+	return "", true
+}
+
+// updateVrf realizes the changes of an updated vrf
+func updateVrf(vrf *infradb.Vrf, changes infradb.SpecDiff) (string, bool) {
+	if path.Base(vrf.Name) == "GRD" {
+		return "", true
+	}
+	if len(vrf.Metadata.RoutingTable) == 0 || vrf.Metadata.RoutingTable[0] == nil {
+		logger.Infow("LGM: No routing table found for vrf", logging.Name(vrf.Name))
+		return fmt.Sprintf("LGM : No routing table found for vrf %s\n", vrf.Name), false
 	}
+	routingtable := *vrf.Metadata.RoutingTable[0]
 	details := fmt.Sprintf("{\"routingtable\":\"%d\"}", routingtable)
-	*vrf.Metadata.RoutingTable[0] = routingtable
+
+	link, linkErr := nlink.LinkByName(ctx, path.Base(vrf.Name))
+	if linkErr != nil {
+		logger.Warnw("LGM: Link not found", logging.Name(vrf.Name), "error", linkErr)
+		return fmt.Sprintf("LGM : Link %s not found\n", vrf.Name), false
+	}
+
+	if changes.Has(infradb.FieldLoopbackIP) {
+		if vrf.PrevSpec.LoopbackIP != nil {
+			oldAddr := &netlink.Addr{IPNet: vrf.PrevSpec.LoopbackIP}
+			// The address might have been removed by a previous attempt
+			if err := nlink.AddrDel(ctx, link, oldAddr); err != nil && !errors.Is(err, unix.EADDRNOTAVAIL) {
+				logger.Errorw("LGM: Unable to delete the loopback ip from the VRF link", logging.Name(vrf.Name), "address", oldAddr, "error", err)
+				return fmt.Sprintf("LGM: Unable to delete the loopback ip %s from vrf link %s: %v\n", oldAddr, vrf.Name, err), false
+			}
+			logger.Infow("LGM: Deleted the loopback address from the VRF link", logging.Name(vrf.Name), "address", oldAddr)
+		}
+		if vrf.Spec.LoopbackIP != nil && len(missingAddrs(link, []*net.IPNet{vrf.Spec.LoopbackIP})) != 0 {
+			addr := &netlink.Addr{IPNet: vrf.Spec.LoopbackIP}
+			if vrf.Spec.LoopbackIP.IP.To4() == nil {
+				// The loopback address is unique so skip the duplicate address detection
+				addr.Flags = unix.IFA_F_NODAD
+			}
+			if err := nlink.AddrAdd(ctx, link, addr); err != nil {
+				logger.Errorw("LGM: Unable to set the loopback ip to vrf link", logging.Name(vrf.Name), "error", err)
+				return fmt.Sprintf("LGM: Unable to set the loopback ip to vrf link %s \n", vrf.Name), false
+			}
+			logger.Infow("LGM: Added the loopback address to the VRF link", logging.Name(vrf.Name), "address", addr)
+		}
+	}
+
+	if changes.Has(infradb.FieldVtepIP) && !reflect.ValueOf(vrf.Spec.Vni).IsZero() {
+		if !reflect.ValueOf(vrf.Spec.VtepIP).IsZero() {
+			// Verify that the specified VTEP IP exists as local IP
+			if !nlink.RouteListIPTable(ctx, vrf.Spec.VtepIP.IP) {
+				logger.Warnw("LGM: VTEP IP not found", logging.Name(vrf.Name))
+				return fmt.Sprintf(" LGM: VTEP IP not found: %+v\n", vrf.Spec.VtepIP), false
+			}
+		}
+		// The source address of a vxlan link cannot be changed so the link is recreated
+		linkVxlan, err := nlink.LinkByName(ctx, vxlanStr+path.Base(vrf.Name))
+		if err == nil {
+			if err = nlink.LinkDel(ctx, linkVxlan); err != nil {
+				logger.Errorw("LGM: Failed to delete the vxlan link of the VRF", logging.Name(vrf.Name), "error", err)
+				return fmt.Sprintf("LGM : Failed to delete link %s: %v\n", vxlanStr+vrf.Name, err), false
+			}
+			logger.Infow("LGM: Deleted the vxlan link of the VRF", logging.Name(vrf.Name))
+		}
+		linkBr, brErr := nlink.LinkByName(ctx, brStr+path.Base(vrf.Name))
+		if brErr != nil {
+			logger.Errorw("LGM: Error in getting the bridge link of the VRF", logging.Name(vrf.Name), "error", brErr)
+			return fmt.Sprintf("LGM : Error in getting the br-%s\n", vrf.Name), false
+		}
+		if details, ok := setUpVrfVxlan(vrf, linkBr); !ok {
+			return details, false
+		}
+	}
 	return details, true
 }
 
@@ -657,90 +985,193 @@ func setUpVrf(vrf *infradb.Vrf) (string, bool) {
 func setUpSvi(svi *infradb.Svi) (string, bool) {
 	BrObj, err := infradb.GetLB(svi.Spec.LogicalBridge)
 	if err != nil {
-		log.Printf("LGM: unable to find key %s and error is %v", svi.Spec.LogicalBridge, err)
+		logger.Errorw("LGM: Unable to find the Logical Bridge", "logicalBridge", svi.Spec.LogicalBridge, "error", err)
 		return fmt.Sprintf("LGM: unable to find key %s and error is %v", svi.Spec.LogicalBridge, err), false
 	}
 	linkSvi := fmt.Sprintf("%+v-%+v", path.Base(svi.Spec.Vrf), BrObj.Spec.VlanID)
 	brIntf, err := nlink.LinkByName(ctx, brTenant)
 	if err != nil {
-		log.Printf("LGM : Failed to get link information for %s: %v\n", brTenant, err)
+		logger.Errorw("LGM: Failed to get link information", "link", brTenant, "error", err)
 		return fmt.Sprintf("LGM : Failed to get link information for %s: %v\n", brTenant, err), false
 	}
 	if BrObj.Spec.VlanID > math.MaxUint16 {
-		log.Printf("LGM : VlanID %v value passed in Logical Bridge create is greater than 16 bit value\n", BrObj.Spec.VlanID)
+		logger.Infow("LGM: VlanID value passed in Logical Bridge create is greater than 16 bit value", "vlanID", BrObj.Spec.VlanID)
 		return fmt.Sprintf("LGM : VlanID %v value passed in Logical Bridge create is greater than 16 bit value\n", BrObj.Spec.VlanID), false
 	}
 	vid := uint16(BrObj.Spec.VlanID)
 	if err = nlink.BridgeVlanAdd(ctx, brIntf, vid, false, false, true, false); err != nil {
-		log.Printf("LGM : Failed to add VLAN %d to bridge interface %s: %v\n", vid, brTenant, err)
+		logger.Errorw("LGM: Failed to add VLAN to bridge interface", "vlan", vid, "link", brTenant, "error", err)
 		return fmt.Sprintf("LGM : Failed to add VLAN %d to bridge interface %s: %v\n", vid, brTenant, err), false
 	}
-	log.Printf("LGM Executed : bridge vlan add dev %s vid %d self\n", brTenant, vid)
+	logger.Infow("LGM: Added the VLAN to the bridge", "link", brTenant, "vlan", vid)
 
-	vlanLink := &netlink.Vlan{LinkAttrs: netlink.LinkAttrs{Name: linkSvi, ParentIndex: brIntf.Attrs().Index}, VlanId: int(BrObj.Spec.VlanID)}
-	if err = nlink.LinkAdd(ctx, vlanLink); err != nil {
-		log.Printf("LGM : Failed to add VLAN sub-interface %s: %v\n", linkSvi, err)
+	vlanLink, _, err := addOrAdoptLink(&netlink.Vlan{LinkAttrs: netlink.LinkAttrs{Name: linkSvi, ParentIndex: brIntf.Attrs().Index}, VlanId: int(BrObj.Spec.VlanID)},
+		vlanMatches(int(BrObj.Spec.VlanID), brIntf.Attrs().Index))
+	if err != nil {
+		logger.Errorw("LGM: Failed to add VLAN sub-interface", "link", linkSvi, "error", err)
 		return fmt.Sprintf("LGM : Failed to add VLAN sub-interface %s: %v\n", linkSvi, err), false
 	}
 
-	log.Printf("LGM Executed : ip link add link %s name %s type vlan id %d\n", brTenant, linkSvi, vid)
+	logger.Infow("LGM: Added the VLAN sub-interface", "link", linkSvi, "bridge", brTenant, "vlan", vid)
 	if err = nlink.LinkSetHardwareAddr(ctx, vlanLink, *svi.Spec.MacAddress); err != nil {
-		log.Printf("LGM : Failed to set link %v: %s\n", vlanLink, err)
+		logger.Errorw("LGM: Failed to set the MAC address of the link", "link", linkSvi, "error", err)
 		return fmt.Sprintf("LGM : Failed to set link %v: %s\n", vlanLink, err), false
 	}
 
-	log.Printf("LGM Executed : ip link set %s address %s\n", linkSvi, *svi.Spec.MacAddress)
+	logger.Infow("LGM: Set the MAC address of the link", "link", linkSvi, "mac", svi.Spec.MacAddress.String())
 	vrfIntf, err := nlink.LinkByName(ctx, path.Base(svi.Spec.Vrf))
 	if err != nil {
-		log.Printf("LGM : Failed to get link information for %s: %v\n", path.Base(svi.Spec.Vrf), err)
+		logger.Errorw("LGM: Failed to get link information", "link", path.Base(svi.Spec.Vrf), "error", err)
 		return fmt.Sprintf("LGM : Failed to get link information for %s: %v\n", path.Base(svi.Spec.Vrf), err), false
 	}
 	if err = nlink.LinkSetMaster(ctx, vlanLink, vrfIntf); err != nil {
-		log.Printf("LGM : Failed to set master for %v: %s\n", vlanLink, err)
+		logger.Errorw("LGM: Failed to set the master of the link", "link", linkSvi, "error", err)
 		return fmt.Sprintf("LGM : Failed to set master for %v: %s\n", vlanLink, err), false
 	}
 	if err = nlink.LinkSetUp(ctx, vlanLink); err != nil {
-		log.Printf("LGM : Failed to set up link for %v: %s\n", vlanLink, err)
+		logger.Errorw("LGM: Failed to set up the link", "link", linkSvi, "error", err)
 		return fmt.Sprintf("LGM : Failed to set up link for %v: %s\n", vlanLink, err), false
 	}
-	if err = nlink.LinkSetMTU(ctx, vlanLink, ipMtu); err != nil {
-		log.Printf("LGM : Failed to set MTU for %v: %s\n", vlanLink, err)
+	if err = nlink.LinkSetMTU(ctx, vlanLink, int(ipMtu.Load())); err != nil {
+		logger.Errorw("LGM: Failed to set MTU of the link", "link", linkSvi, "error", err)
 		return fmt.Sprintf("LGM : Failed to set MTU for %v: %s\n", vlanLink, err), false
 	}
 
-	log.Printf("LGM Executed :  ip link set %s master %s up mtu %d\n", linkSvi, path.Base(svi.Spec.Vrf), ipMtu)
+	logger.Infow("LGM: SVI link set up", "link", linkSvi, "master", path.Base(svi.Spec.Vrf), "mtu", ipMtu.Load())
 	// Ignoring the error as CI env doesn't allow to write to the filesystem
 	command := fmt.Sprintf("net.ipv4.conf.%s.arp_accept=1", linkSvi)
 	CP, err1 := run([]string{"sysctl", "-w", command}, false)
 	if err1 != 0 {
-		log.Printf("LGM: Error in executing command %s %s\n", "sysctl -w net.ipv4.conf.linkSvi.arp_accept=1", linkSvi)
-		log.Printf("%s\n", CP)
+		logger.Errorw("LGM: Error in executing command", "command", "sysctl -w "+command, "output", CP)
 		// return  false
 	}
-	for _, ipIntf := range svi.Spec.GatewayIPs {
-		addr := &netlink.Addr{
-			IPNet: &net.IPNet{
-				IP:   ipIntf.IP,
-				Mask: ipIntf.Mask,
-			},
+	// The addresses are already set on an adopted link
+	return addSviGatewayIPs(linkSvi, vlanLink, missingAddrs(vlanLink, svi.Spec.GatewayIPs))
+}
+
+// addSviGatewayIPs adds the gateway addresses to the svi link
+func addSviGatewayIPs(linkSvi string, vlanLink netlink.Link, gatewayIPs []*net.IPNet) (string, bool) {
+	if hasIPv6(gatewayIPs) {
+		// The gateway addresses are shared by all the hosts so skip the duplicate address
+		// detection and announce them with unsolicited neighbor advertisements
+		for _, command := range []string{
+			fmt.Sprintf("net.ipv6.conf.%s.accept_dad=0", linkSvi),
+			fmt.Sprintf("net.ipv6.conf.%s.ndisc_notify=1", linkSvi),
+		} {
+			CP, err1 := run([]string{"sysctl", "-w", command}, false)
+			if err1 != 0 {
+				logger.Errorw("LGM: Error in executing command", "command", "sysctl -w "+command, "output", CP)
+			}
 		}
+	}
+	for _, ipIntf := range gatewayIPs {
+		addr := gatewayAddr(ipIntf)
 		if err := nlink.AddrAdd(ctx, vlanLink, addr); err != nil {
-			log.Printf("LGM: Failed to add ip address %v to %v: %v\n", addr, vlanLink, err)
+			logger.Errorw("LGM: Failed to add the address to the link", "link", linkSvi, "address", addr, "error", err)
 			return fmt.Sprintf("LGM: Failed to add ip address %v to %v: %v\n", addr, vlanLink, err), false
 		}
 
-		log.Printf("LGM Executed :  ip address add %s dev %+v\n", addr, vlanLink)
+		logger.Debugw("LGM: Added the address to the link", "link", linkSvi, "address", addr)
+	}
+	return "", true
+}
+
+// gatewayAddr returns the netlink address of an svi gateway IP
+func gatewayAddr(ipIntf *net.IPNet) *netlink.Addr {
+	addr := &netlink.Addr{
+		IPNet: &net.IPNet{
+			IP:   ipIntf.IP,
+			Mask: ipIntf.Mask,
+		},
+	}
+	if ipIntf.IP.To4() == nil {
+		addr.Flags = unix.IFA_F_NODAD
+	}
+	return addr
+}
+
+// updateSvi realizes the changes of an updated svi
+func updateSvi(svi *infradb.Svi, changes infradb.SpecDiff) (string, bool) {
+	if !changes.Has(infradb.FieldMacAddress) && !changes.Has(infradb.FieldGatewayIPs) {
+		return "", true
+	}
+	BrObj, err := infradb.GetLB(svi.Spec.LogicalBridge)
+	if err != nil {
+		logger.Errorw("LGM: Unable to find the Logical Bridge", "logicalBridge", svi.Spec.LogicalBridge, "error", err)
+		return fmt.Sprintf("LGM: unable to find key %s and error is %v", svi.Spec.LogicalBridge, err), false
+	}
+	linkSvi := fmt.Sprintf("%+v-%+v", path.Base(svi.Spec.Vrf), BrObj.Spec.VlanID)
+	vlanLink, err := nlink.LinkByName(ctx, linkSvi)
+	if err != nil {
+		logger.Errorw("LGM: Failed to get link", "link", linkSvi, "error", err)
+		return fmt.Sprintf("LGM : Failed to get link %s: %v\n", linkSvi, err), false
+	}
+
+	if changes.Has(infradb.FieldMacAddress) {
+		if err = nlink.LinkSetHardwareAddr(ctx, vlanLink, *svi.Spec.MacAddress); err != nil {
+			logger.Errorw("LGM: Failed to set MAC on link", "error", err)
+			return fmt.Sprintf("LGM : Failed to set MAC on link: %v\n", err), false
+		}
+		logger.Infow("LGM: Set the MAC address of the link", "link", linkSvi, "mac", svi.Spec.MacAddress.String())
+	}
+
+	if changes.Has(infradb.FieldGatewayIPs) {
+		added, removed := infradb.DiffIPNets(svi.PrevSpec.GatewayIPs, svi.Spec.GatewayIPs)
+		for _, ipIntf := range removed {
+			addr := gatewayAddr(ipIntf)
+			// The address might have been removed by a previous attempt
+			if err := nlink.AddrDel(ctx, vlanLink, addr); err != nil && !errors.Is(err, unix.EADDRNOTAVAIL) {
+				logger.Errorw("LGM: Failed to delete the address from the link", "link", linkSvi, "address", addr, "error", err)
+				return fmt.Sprintf("LGM: Failed to delete ip address %v from %v: %v\n", addr, vlanLink, err), false
+			}
+			logger.Debugw("LGM: Deleted the address from the link", "link", linkSvi, "address", addr)
+		}
+		if details, ok := addSviGatewayIPs(linkSvi, vlanLink, missingAddrs(vlanLink, added)); !ok {
+			return details, false
+		}
 	}
 	return "", true
 }
 
+// missingAddrs returns the addresses that are not configured on the link yet
+func missingAddrs(link netlink.Link, ipNets []*net.IPNet) []*net.IPNet {
+	addrs, err := nlink.AddrList(ctx, link, netlink.FAMILY_ALL)
+	if err != nil {
+		return ipNets
+	}
+	var missing []*net.IPNet
+	for _, ipNet := range ipNets {
+		found := false
+		for _, addr := range addrs {
+			if addr.IPNet != nil && addr.IP.Equal(ipNet.IP) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, ipNet)
+		}
+	}
+	return missing
+}
+
+// hasIPv6 checks if any of the addresses is an IPv6 address
+func hasIPv6(ipNets []*net.IPNet) bool {
+	for _, ipNet := range ipNets {
+		if ipNet != nil && ipNet.IP.To4() == nil {
+			return true
+		}
+	}
+	return false
+}
+
 // GenerateMac Generates the random mac
 func GenerateMac() net.HardwareAddr {
 	buf := make([]byte, 5)
 	var mac net.HardwareAddr
 	_, err := rand.Read(buf) //nolint:gosec
 	if err != nil {
-		log.Printf("failed to generate random mac %+v\n", err)
+		logger.Errorw("LGM: Failed to generate a random MAC", "error", err)
 	}
 
 	// Set the local bit
@@ -778,7 +1209,7 @@ func NetMaskToInt(mask int) (netmaskint [4]int64) {
 func tearDownVrf(vrf *infradb.Vrf) (string, bool) {
 	link, err1 := nlink.LinkByName(ctx, path.Base(vrf.Name))
 	if err1 != nil {
-		log.Printf("LGM : Link %s not found %+v\n", vrf.Name, err1)
+		logger.Warnw("LGM: Link not found", logging.Name(vrf.Name), "error", err1)
 		return fmt.Sprintf("LGM : Link %s not found %+v\n", vrf.Name, err1), true
 	}
 
@@ -790,41 +1221,41 @@ func tearDownVrf(vrf *infradb.Vrf) (string, bool) {
 	if !reflect.ValueOf(vrf.Spec.Vni).IsZero() {
 		linkVxlan, linkErr := nlink.LinkByName(ctx, vxlanStr+path.Base(vrf.Name))
 		if linkErr != nil {
-			log.Printf("LGM : Link vxlan-%s not found %+v\n", vrf.Name, linkErr)
+			logger.Warnw("LGM: Vxlan link of the VRF not found", logging.Name(vrf.Name), "error", linkErr)
 			return fmt.Sprintf("LGM : Link vxlan-%s not found %+v\n", vrf.Name, linkErr), false
 		}
 		delerr := nlink.LinkDel(ctx, linkVxlan)
 		if delerr != nil {
-			log.Printf("LGM: Error in delete vxlan %+v\n", delerr)
+			logger.Errorw("LGM: Error in delete vxlan", "error", delerr)
 			return fmt.Sprintf("LGM: Error in delete vxlan %+v\n", delerr), false
 		}
-		log.Printf("LGM : Delete vxlan-%s\n", vrf.Name)
+		logger.Infow("LGM: Deleted the vxlan link of the VRF", logging.Name(vrf.Name))
 
 		linkBr, linkbrErr := nlink.LinkByName(ctx, brStr+path.Base(vrf.Name))
 		if linkbrErr != nil {
-			log.Printf("LGM : Link br-%s not found %+v\n", vrf.Name, linkbrErr)
+			logger.Warnw("LGM: Bridge link of the VRF not found", logging.Name(vrf.Name), "error", linkbrErr)
 			return fmt.Sprintf("LGM : Link br-%s not found %+v\n", vrf.Name, linkbrErr), false
 		}
 		delerr = nlink.LinkDel(ctx, linkBr)
 		if delerr != nil {
-			log.Printf("LGM: Error in delete br %+v\n", delerr)
+			logger.Errorw("LGM: Error in delete br", "error", delerr)
 			return fmt.Sprintf("LGM: Error in delete br %+v\n", delerr), false
 		}
-		log.Printf("LGM : Delete br-%s\n", vrf.Name)
+		logger.Infow("LGM: Deleted the bridge link of the VRF", logging.Name(vrf.Name))
 	}
-	routeTable := fmt.Sprintf("%+v", routingtable)
-	flusherr := nlink.RouteFlushTable(ctx, routeTable)
+	flusherr := nlink.RouteFlushTable(ctx, int(routingtable))
 	if flusherr != nil {
-		log.Printf("LGM: Error in flush table  %+v\n", routeTable)
-		return fmt.Sprintf("LGM: Error in flush table  %+v\n", routeTable), false
+		logger.Errorw("LGM: Error in flushing the routing table", "table", routingtable, "error", flusherr)
+		return fmt.Sprintf("LGM: Error in flush table %d: %+v\n", routingtable, flusherr), false
 	}
-	log.Printf("LGM Executed : ip route flush table %s\n", routeTable)
+	logger.Infow("LGM: Flushed the routing table", "table", routingtable)
 	delerr := nlink.LinkDel(ctx, link)
 	if delerr != nil {
-		log.Printf("LGM: Error in delete br %+v\n", delerr)
+		logger.Errorw("LGM: Error in delete br", "error", delerr)
 		return fmt.Sprintf("LGM: Error in delete br %+v\n", delerr), false
 	}
-	log.Printf("LGM :link delete  %s\n", vrf.Name)
+	logger.Infow("LGM: Deleted the VRF link", logging.Name(vrf.Name))
+	RouteTableGen.ReleaseID(vrf.Name)
 	return "", true
 }
 
@@ -832,36 +1263,36 @@ func tearDownVrf(vrf *infradb.Vrf) (string, bool) {
 func tearDownSvi(svi *infradb.Svi) (string, bool) {
 	BrObj, err := infradb.GetLB(svi.Spec.LogicalBridge)
 	if err != nil {
-		log.Printf("LGM: unable to find key %s and error is %v", svi.Spec.LogicalBridge, err)
+		logger.Errorw("LGM: Unable to find the Logical Bridge", "logicalBridge", svi.Spec.LogicalBridge, "error", err)
 		return fmt.Sprintf("LGM: unable to find key %s and error is %v", svi.Spec.LogicalBridge, err), false
 	}
 	brIntf, err := nlink.LinkByName(ctx, brTenant)
 	if err != nil {
-		log.Printf("LGM : Failed to get link information for %s: %v\n", brTenant, err)
+		logger.Errorw("LGM: Failed to get link information", "link", brTenant, "error", err)
 		return fmt.Sprintf("LGM : Failed to get link information for %s: %v\n", brTenant, err), false
 	}
 	if BrObj.Spec.VlanID > math.MaxUint16 {
-		log.Printf("LGM : VlanID %v value passed in Logical Bridge create is greater than 16 bit value\n", BrObj.Spec.VlanID)
+		logger.Infow("LGM: VlanID value passed in Logical Bridge create is greater than 16 bit value", "vlanID", BrObj.Spec.VlanID)
 		return fmt.Sprintf("LGM : VlanID %v value passed in Logical Bridge create is greater than 16 bit value\n", BrObj.Spec.VlanID), false
 	}
 	vid := uint16(BrObj.Spec.VlanID)
 	if err = nlink.BridgeVlanDel(ctx, brIntf, vid, false, false, true, false); err != nil {
-		log.Printf("LGM : Failed to Del VLAN %d to bridge interface %s: %v\n", vid, brTenant, err)
+		logger.Errorw("LGM: Failed to delete the VLAN from the bridge", "link", brTenant, "vlan", vid, "error", err)
 		return fmt.Sprintf("LGM : Failed to Del VLAN %d to bridge interface %s: %v\n", vid, brTenant, err), false
 	}
-	log.Printf("LGM Executed : bridge vlan del dev %s vid %d self\n", brTenant, vid)
+	logger.Infow("LGM: Deleted the VLAN from the bridge", "link", brTenant, "vlan", vid)
 	linkSvi := fmt.Sprintf("%+v-%+v", path.Base(svi.Spec.Vrf), BrObj.Spec.VlanID)
 	Intf, err := nlink.LinkByName(ctx, linkSvi)
 	if err != nil {
-		log.Printf("LGM : Failed to get link %s: %v\n", linkSvi, err)
+		logger.Errorw("LGM: Failed to get link", "link", linkSvi, "error", err)
 		return fmt.Sprintf("LGM : Failed to get link %s: %v\n", linkSvi, err), true
 	}
 
 	if err = nlink.LinkDel(ctx, Intf); err != nil {
-		log.Printf("LGM : Failed to delete link %s: %v\n", linkSvi, err)
+		logger.Errorw("LGM: Failed to delete link", "link", linkSvi, "error", err)
 		return fmt.Sprintf("LGM : Failed to delete link %s: %v\n", linkSvi, err), false
 	}
-	log.Printf("LGM: Executed ip link delete %s\n", linkSvi)
+	logger.Infow("LGM: Deleted the link", "link", linkSvi)
 
 	return "", true
 }
@@ -872,14 +1303,14 @@ func tearDownBridge(lb *infradb.LogicalBridge) (string, bool) {
 	if !reflect.ValueOf(lb.Spec.Vni).IsZero() {
 		Intf, err := nlink.LinkByName(ctx, link)
 		if err != nil {
-			log.Printf("LGM: Failed to get link %s: %v\n", link, err)
+			logger.Errorw("LGM: Failed to get link", "link", link, "error", err)
 			return fmt.Sprintf("LGM: Failed to get link %s: %v\n", link, err), true
 		}
 		if err = nlink.LinkDel(ctx, Intf); err != nil {
-			log.Printf("LGM : Failed to delete link %s: %v\n", link, err)
+			logger.Errorw("LGM: Failed to delete link", "link", link, "error", err)
 			return fmt.Sprintf("LGM: Failed to delete link %s: %v\n", link, err), false
 		}
-		log.Printf("LGM: Executed ip link delete %s", link)
+		logger.Infow("LGM: Deleted the link", "link", link)
 
 		return "", true
 	}
@@ -890,14 +1321,14 @@ func tearDownBridge(lb *infradb.LogicalBridge) (string, bool) {
 func TearDownTenantBridge() error {
 	Intf, err := nlink.LinkByName(ctx, brTenant)
 	if err != nil {
-		log.Printf("LGM: Failed to get br-tenant %s: %v\n", Intf, err)
+		logger.Errorw("LGM: Failed to get br-tenant", "error", err)
 		return err
 	}
 	if err = nlink.LinkDel(ctx, Intf); err != nil {
-		log.Printf("LGM : Failed to delete br-tenant %s: %v\n", Intf, err)
+		logger.Errorw("LGM: Failed to delete br-tenant", "error", err)
 		return err
 	}
-	log.Printf("LGM: Executed ip link delete %s", brTenant)
+	logger.Infow("LGM: Deleted the link", "link", brTenant)
 
 	return nil
 }
@@ -0,0 +1,296 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package linuxgeneralmodule is the main package of the application
+package linuxgeneralmodule
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netlink/nl"
+	"golang.org/x/sys/unix"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils/mocks"
+)
+
+func TestAddOrAdoptLink(t *testing.T) {
+	vrf := &netlink.Vrf{LinkAttrs: netlink.LinkAttrs{Name: "blue"}, Table: 1001}
+	tests := map[string]struct {
+		addErr    error
+		existing  netlink.Link
+		adopted   bool
+		recreated bool
+		expectErr bool
+	}{
+		"created": {},
+		"adopted": {
+			addErr:   unix.EEXIST,
+			existing: &netlink.Vrf{LinkAttrs: netlink.LinkAttrs{Name: "blue", Index: 7}, Table: 1001},
+			adopted:  true,
+		},
+		"recreated with another table": {
+			addErr:    unix.EEXIST,
+			existing:  &netlink.Vrf{LinkAttrs: netlink.LinkAttrs{Name: "blue", Index: 7}, Table: 1002},
+			recreated: true,
+		},
+		"existing link of another type": {
+			addErr:    unix.EEXIST,
+			existing:  &netlink.Bridge{LinkAttrs: netlink.LinkAttrs{Name: "blue"}},
+			expectErr: true,
+		},
+		"failed": {
+			addErr:    unix.EPERM,
+			expectErr: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockNetlink := mocks.NewNetlink(t)
+			nlink = mockNetlink
+			ctx = context.Background()
+			mockNetlink.EXPECT().LinkAdd(mock.Anything, vrf).Return(tt.addErr).Once()
+			if tt.existing != nil {
+				mockNetlink.EXPECT().LinkByName(mock.Anything, "blue").Return(tt.existing, nil).Once()
+			}
+			if tt.recreated {
+				mockNetlink.EXPECT().LinkDel(mock.Anything, tt.existing).Return(nil).Once()
+				mockNetlink.EXPECT().LinkAdd(mock.Anything, vrf).Return(nil).Once()
+			}
+
+			link, adopted, err := addOrAdoptLink(vrf, vrfMatches(vrf.Table))
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("Expected error %v, received %v", tt.expectErr, err)
+			}
+			if adopted != tt.adopted {
+				t.Errorf("Expected adopted %v, received %v", tt.adopted, adopted)
+			}
+			if tt.adopted && link != tt.existing {
+				t.Errorf("Expected the existing link %v, received %v", tt.existing, link)
+			}
+			if tt.recreated && link != vrf {
+				t.Errorf("Expected the new link %v, received %v", vrf, link)
+			}
+		})
+	}
+}
+
+func TestLinkMatches(t *testing.T) {
+	src := net.ParseIP("10.0.0.1")
+	tests := map[string]struct {
+		matches   func(netlink.Link) error
+		link      netlink.Link
+		expectErr bool
+	}{
+		"same vxlan": {
+			matches: vxlanMatches(100, src),
+			link:    &netlink.Vxlan{VxlanId: 100, SrcAddr: net.ParseIP("10.0.0.1")},
+		},
+		"different vni": {
+			matches:   vxlanMatches(100, src),
+			link:      &netlink.Vxlan{VxlanId: 200, SrcAddr: src},
+			expectErr: true,
+		},
+		"different local address": {
+			matches:   vxlanMatches(100, src),
+			link:      &netlink.Vxlan{VxlanId: 100, SrcAddr: net.ParseIP("10.0.0.2")},
+			expectErr: true,
+		},
+		"same vrf": {
+			matches: vrfMatches(1001),
+			link:    &netlink.Vrf{Table: 1001},
+		},
+		"different table": {
+			matches:   vrfMatches(1001),
+			link:      &netlink.Vrf{Table: 1002},
+			expectErr: true,
+		},
+		"same vlan": {
+			matches: vlanMatches(10, 3),
+			link:    &netlink.Vlan{LinkAttrs: netlink.LinkAttrs{ParentIndex: 3}, VlanId: 10},
+		},
+		"different vlan id": {
+			matches:   vlanMatches(10, 3),
+			link:      &netlink.Vlan{LinkAttrs: netlink.LinkAttrs{ParentIndex: 3}, VlanId: 20},
+			expectErr: true,
+		},
+		"different parent": {
+			matches:   vlanMatches(10, 3),
+			link:      &netlink.Vlan{LinkAttrs: netlink.LinkAttrs{ParentIndex: 4}, VlanId: 10},
+			expectErr: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := tt.matches(tt.link); (err != nil) != tt.expectErr {
+				t.Errorf("Expected error %v, received %v", tt.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestVrfRoutingTable(t *testing.T) {
+	recorded := uint32(1005)
+	tests := map[string]struct {
+		existing netlink.Link
+		metadata []*uint32
+		expected uint32
+	}{
+		"existing vrf link": {
+			existing: &netlink.Vrf{LinkAttrs: netlink.LinkAttrs{Name: "blue"}, Table: 1010},
+			metadata: []*uint32{&recorded},
+			expected: 1010,
+		},
+		"recorded in the metadata": {
+			metadata: []*uint32{&recorded},
+			expected: 1005,
+		},
+		"new vrf": {
+			expected: 1000,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockNetlink := mocks.NewNetlink(t)
+			nlink = mockNetlink
+			ctx = context.Background()
+			RouteTableGen, _ = utils.IDPoolInit("RTtable", routingTableMin, routingTableMax)
+			if tt.existing != nil {
+				mockNetlink.EXPECT().LinkByName(mock.Anything, "blue").Return(tt.existing, nil).Once()
+			} else {
+				mockNetlink.EXPECT().LinkByName(mock.Anything, "blue").Return(nil, unix.ENODEV).Once()
+			}
+			vrf := &infradb.Vrf{Name: vrfPrefix + "blue", Metadata: &infradb.VrfMetadata{RoutingTable: tt.metadata}}
+
+			if table := vrfRoutingTable(vrf); table != tt.expected {
+				t.Errorf("Expected routing table %v, received %v", tt.expected, table)
+			}
+			// The table is kept for the vrf and not handed out to another one
+			if table := RouteTableGen.GetID(vrf.Name); table != tt.expected {
+				t.Errorf("Expected routing table %v to be reserved, received %v", tt.expected, table)
+			}
+			if table := RouteTableGen.GetID(vrfPrefix + "red"); table == tt.expected {
+				t.Errorf("Expected another routing table than %v", tt.expected)
+			}
+		})
+	}
+}
+
+type nopHandler struct{}
+
+func (h *nopHandler) HandleEvent(string, *eventbus.ObjectData) {}
+
+func TestReserveRoutingTables(t *testing.T) {
+	prevBus := eventbus.EBus
+	eventbus.EBus = eventbus.NewEventBus()
+	t.Cleanup(func() { eventbus.EBus = prevBus })
+	eventbus.EBus.Subscribe("lgm", "vrf", 1, &nopHandler{})
+	if err := infradb.NewInfraDB("", "gomap"); err != nil {
+		t.Fatalf("Failed to create the infra db: %v", err)
+	}
+	vrf, _ := infradb.NewVrfWithArgs(vrfPrefix+"blue", nil, nil, nil)
+	if err := infradb.CreateVrf(vrf); err != nil {
+		t.Fatalf("Failed to create the vrf: %v", err)
+	}
+
+	mockNetlink := mocks.NewNetlink(t)
+	nlink = mockNetlink
+	ctx = context.Background()
+	RouteTableGen, _ = utils.IDPoolInit("RTtable", routingTableMin, routingTableMax)
+	mockNetlink.EXPECT().LinkList(mock.Anything).Return([]netlink.Link{
+		&netlink.Vrf{LinkAttrs: netlink.LinkAttrs{Name: "blue"}, Table: 1010},
+		&netlink.Vrf{LinkAttrs: netlink.LinkAttrs{Name: "stale"}, Table: 1020},
+	}, nil).Once()
+
+	reserveRoutingTables()
+
+	if table := RouteTableGen.GetID(vrf.Name); table != 1010 {
+		t.Errorf("Expected routing table 1010 to be reserved for the vrf, received %v", table)
+	}
+	// The vrf link of a vrf that is not in the infra db does not hold a table
+	if table := RouteTableGen.GetID(vrfPrefix + "stale"); table == 1020 {
+		t.Errorf("Expected routing table 1020 not to be reserved for a vrf that is not in the infra db")
+	}
+}
+
+func TestBridgeDrift(t *testing.T) {
+	vni := uint32(100)
+	vtep := &net.IPNet{IP: net.ParseIP("10.0.0.1").To4(), Mask: net.CIDRMask(32, 32)}
+	lb := &infradb.LogicalBridge{Name: "//network.opiproject.org/bridges/lb10", Spec: &infradb.LogicalBridgeSpec{VlanID: 10, Vni: &vni, VtepIP: vtep}}
+	bridge := &netlink.Bridge{LinkAttrs: netlink.LinkAttrs{Name: brTenant, Index: 2, Flags: net.FlagUp}}
+	vxlan := &netlink.Vxlan{LinkAttrs: netlink.LinkAttrs{Name: "vxlan-10", Index: 5, Flags: net.FlagUp, MasterIndex: 2, MTU: 1500}, VxlanId: 100, SrcAddr: vtep.IP}
+	untagged := &nl.BridgeVlanInfo{Vid: 10, Flags: nl.BRIDGE_VLAN_INFO_PVID | nl.BRIDGE_VLAN_INFO_UNTAGGED}
+	tests := map[string]struct {
+		vxlan netlink.Link
+		vlans map[int32][]*nl.BridgeVlanInfo
+		drift []string
+	}{
+		"as expected": {
+			vxlan: vxlan,
+			vlans: map[int32][]*nl.BridgeVlanInfo{5: {untagged}},
+		},
+		"vxlan missing": {
+			drift: []string{"link vxlan-10 not found"},
+		},
+		"other vni and vlan missing": {
+			vxlan: &netlink.Vxlan{LinkAttrs: vxlan.LinkAttrs, VxlanId: 200, SrcAddr: vtep.IP},
+			drift: []string{"link vxlan-10 has vni 200 instead of 100", "vlan 10 not found on link vxlan-10"},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockNetlink := mocks.NewNetlink(t)
+			nlink = mockNetlink
+			ctx = context.Background()
+			ipMtu.Store(1500)
+			mockNetlink.EXPECT().LinkByName(mock.Anything, brTenant).Return(bridge, nil).Once()
+			if tt.vxlan != nil {
+				mockNetlink.EXPECT().LinkByName(mock.Anything, "vxlan-10").Return(tt.vxlan, nil).Once()
+			} else {
+				mockNetlink.EXPECT().LinkByName(mock.Anything, "vxlan-10").Return(nil, unix.ENODEV).Once()
+			}
+
+			if drift := bridgeDrift(lb, tt.vlans); !reflect.DeepEqual(drift, tt.drift) {
+				t.Errorf("Expected drift %v, received %v", tt.drift, drift)
+			}
+		})
+	}
+}
+
+func TestSetUpBridgeAdopted(t *testing.T) {
+	vni := uint32(100)
+	vtep := &net.IPNet{IP: net.ParseIP("10.0.0.1").To4(), Mask: net.CIDRMask(32, 32)}
+	lb := &infradb.LogicalBridge{Name: "//network.opiproject.org/bridges/lb10", Spec: &infradb.LogicalBridgeSpec{VlanID: 10, Vni: &vni, VtepIP: vtep}}
+	bridge := &netlink.Bridge{LinkAttrs: netlink.LinkAttrs{Name: brTenant, Index: 2, Flags: net.FlagUp}}
+	existing := &netlink.Vxlan{LinkAttrs: netlink.LinkAttrs{Name: "vxlan-10", Index: 5, MTU: 1400}, VxlanId: 100, SrcAddr: vtep.IP}
+
+	mockNetlink := mocks.NewNetlink(t)
+	nlink = mockNetlink
+	ctx = context.Background()
+	ipMtu.Store(1500)
+	mockNetlink.EXPECT().LinkByName(mock.Anything, brTenant).Return(bridge, nil).Once()
+	mockNetlink.EXPECT().LinkAdd(mock.Anything, mock.Anything).Return(unix.EEXIST).Once()
+	mockNetlink.EXPECT().LinkByName(mock.Anything, "vxlan-10").Return(existing, nil).Once()
+	// The adopted link gets the mtu the drift is checked against
+	mockNetlink.EXPECT().LinkSetMTU(mock.Anything, existing, 1500).Return(nil).Once()
+	mockNetlink.EXPECT().LinkSetMaster(mock.Anything, existing, bridge).Return(nil).Once()
+	mockNetlink.EXPECT().LinkSetUp(mock.Anything, existing).Return(nil).Once()
+	mockNetlink.EXPECT().BridgeVlanAdd(mock.Anything, existing, uint16(10), true, true, false, false).Return(nil).Once()
+	mockNetlink.EXPECT().LinkSetBrNeighSuppress(mock.Anything, existing, true).Return(nil).Once()
+
+	if details, ok := setUpBridge(lb); !ok {
+		t.Errorf("Expected the bridge to be set up, received %v", details)
+	}
+}
@@ -0,0 +1,567 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package audit keeps a tamper-evident trail of the mutations of the intent objects
+package audit
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/logging"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
+)
+
+// logger is the logger of the grpc subsystem
+var logger = logging.Logger(logging.Grpc).Sugar()
+
+const (
+	// SourceGrpc marks the records of the mutations requested through the gRPC API
+	SourceGrpc = "grpc"
+	// SourceInfraDB marks the records of the realization outcomes reported by infradb
+	SourceInfraDB = "infradb"
+
+	// ActionCreate an object has been created
+	ActionCreate = "create"
+	// ActionUpdate an object has been updated
+	ActionUpdate = "update"
+	// ActionDelete an object has been marked for deletion
+	ActionDelete = "delete"
+	// ActionRealized all the subscribers have realized an object
+	ActionRealized = "realized"
+	// ActionRemoved all the subscribers have removed an object and it is gone from the store
+	ActionRemoved = "removed"
+
+	// ResultRealized the subscribers have realized or removed the object
+	ResultRealized = "OK"
+	// ResultFailed a subscriber has failed to realize or remove the object, or its task has been dropped
+	ResultFailed = "Failed"
+
+	defaultFile       = "opi-evpn-bridge-audit.log"
+	anchorSuffix      = ".anchor"
+	defaultMaxSize    = 10 // megabytes
+	defaultMaxBackups = 5
+)
+
+// Record is a single entry of the audit trail.
+// Each record holds the HMAC of the previous one so that removing or
+// altering a record breaks the chain, and the records cannot be rewritten
+// without the key of the trail.
+type Record struct {
+	Sequence        uint64          `json:"seq"`
+	Time            time.Time       `json:"time"`
+	Source          string          `json:"source"`
+	Action          string          `json:"action"`
+	ObjectType      string          `json:"objectType"`
+	Object          string          `json:"object"`
+	Identity        string          `json:"identity,omitempty"`
+	Request         json.RawMessage `json:"request,omitempty"`
+	Before          json.RawMessage `json:"before,omitempty"`
+	After           json.RawMessage `json:"after,omitempty"`
+	Result          string          `json:"result"`
+	Error           string          `json:"error,omitempty"`
+	ResourceVersion string          `json:"resourceVersion,omitempty"`
+	PrevHash        string          `json:"prevHash"`
+	Hash            string          `json:"hash"`
+}
+
+// computeHash returns the HMAC of the record content, including the hash of the previous record
+func (r *Record) computeHash(key []byte) (string, error) {
+	c := *r
+	c.Hash = ""
+	data, err := json.Marshal(&c)
+	if err != nil {
+		return "", err
+	}
+	return sign(key, data), nil
+}
+
+// anchor is stored outside the audit files and holds the range of the records
+// that are kept, so that the truncation of the trail or the removal of its
+// oldest backup is detected. It is signed with the key of the trail.
+type anchor struct {
+	First uint64 `json:"first"`
+	Last  uint64 `json:"last"`
+	Hash  string `json:"hash"`
+	MAC   string `json:"mac"`
+}
+
+func (a *anchor) computeMAC(key []byte) string {
+	return sign(key, []byte(fmt.Sprintf("%d:%d:%s", a.First, a.Last, a.Hash)))
+}
+
+func sign(key, data []byte) string {
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Query selects the records of the audit trail.
+// The zero value of each field matches all the records.
+type Query struct {
+	ObjectType string
+	Object     string
+	Identity   string
+	Since      time.Time
+	Until      time.Time
+	// Limit keeps only the most recent matching records
+	Limit int
+}
+
+func (q *Query) match(r *Record) bool {
+	switch {
+	case q.ObjectType != "" && q.ObjectType != r.ObjectType:
+		return false
+	case q.Object != "" && q.Object != r.Object:
+		return false
+	case q.Identity != "" && q.Identity != r.Identity:
+		return false
+	case !q.Since.IsZero() && r.Time.Before(q.Since):
+		return false
+	case !q.Until.IsZero() && r.Time.After(q.Until):
+		return false
+	}
+	return true
+}
+
+// Logger writes the audit records as JSON lines to a file that is rotated
+// when it reaches its maximum size. All the methods are safe to call on a
+// nil Logger, which is what is used when the audit trail is disabled.
+type Logger struct {
+	mtx        sync.Mutex
+	path       string
+	anchorPath string
+	key        []byte
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	size       int64
+	// first is the sequence of the oldest record kept
+	first    uint64
+	seq      uint64
+	lastHash string
+}
+
+// errFound stops the walk through the records
+var errFound = errors.New("found")
+
+// Trail holds the audit trail of the bridge
+var Trail *Logger
+
+// Initialize sets up the audit trail from the config
+func Initialize(cfg config.AuditConfig) error {
+	if !cfg.Enabled {
+		logger.Info("Audit trail is disabled")
+		return nil
+	}
+	l, err := NewLogger(cfg.File, cfg.Anchor, []byte(cfg.Key), cfg.MaxSize, cfg.MaxBackups)
+	if err != nil {
+		return err
+	}
+	Trail = l
+	return nil
+}
+
+// DeInitialize closes the audit trail
+func DeInitialize() {
+	if err := Trail.Close(); err != nil {
+		logger.Errorw("Audit: Failed to close the audit trail", "error", err)
+	}
+}
+
+// NewLogger opens the audit file and continues the chain of the records it already holds
+// from its anchor. The records are signed with the key and the anchor is stored next
+// to the audit file when its path is empty. The maximum size is in megabytes, and zero
+// values select the defaults.
+func NewLogger(path, anchorPath string, key []byte, maxSize, maxBackups int) (*Logger, error) {
+	if len(key) == 0 {
+		return nil, errors.New("audit trail needs a key")
+	}
+	if path == "" {
+		path = defaultFile
+	}
+	if anchorPath == "" {
+		anchorPath = path + anchorSuffix
+	}
+	if maxSize <= 0 {
+		maxSize = defaultMaxSize
+	}
+	if maxBackups <= 0 {
+		maxBackups = defaultMaxBackups
+	}
+	l := &Logger{
+		path:       filepath.Clean(path),
+		anchorPath: filepath.Clean(anchorPath),
+		key:        key,
+		maxSize:    int64(maxSize) * 1024 * 1024,
+		maxBackups: maxBackups,
+	}
+
+	// The anchor, and not the files that may have been truncated, tells where the chain continues
+	a, err := l.readAnchor()
+	if err != nil {
+		return nil, err
+	}
+	if a == nil {
+		first, err := l.firstRecord()
+		if err != nil {
+			return nil, err
+		}
+		if first != nil {
+			return nil, fmt.Errorf("audit anchor %s is missing for the records of %s", l.anchorPath, l.path)
+		}
+	} else {
+		l.first = a.First
+		l.seq = a.Last
+		l.lastHash = a.Hash
+	}
+
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Close closes the audit file
+func (l *Logger) Close() error {
+	if l == nil {
+		return nil
+	}
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+// Write chains the record to the trail and appends it to the audit file
+func (l *Logger) Write(r *Record) error {
+	if l == nil {
+		return nil
+	}
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	if l.file == nil {
+		return errors.New("audit trail is closed")
+	}
+
+	r.Sequence = l.seq + 1
+	r.PrevHash = l.lastHash
+	hash, err := r.computeHash(l.key)
+	if err != nil {
+		return err
+	}
+	r.Hash = hash
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	if l.size > 0 && l.size+int64(len(data)) > l.maxSize {
+		if err := l.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := l.file.Write(data)
+	l.size += int64(n)
+	if err != nil {
+		return err
+	}
+	l.seq = r.Sequence
+	l.lastHash = r.Hash
+	if l.first == 0 {
+		l.first = r.Sequence
+	}
+	return l.writeAnchor()
+}
+
+// Query returns the records that match the query, oldest first
+func (l *Logger) Query(q Query) ([]*Record, error) {
+	if l == nil {
+		return nil, nil
+	}
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	var records []*Record
+	err := l.walk(func(r *Record) error {
+		if q.match(r) {
+			records = append(records, r)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if q.Limit > 0 && len(records) > q.Limit {
+		records = records[len(records)-q.Limit:]
+	}
+	return records, nil
+}
+
+// Verify checks the hash chain of the records that are still kept against
+// the anchor and returns how many records have been verified. The oldest
+// kept record starts the chain as the records before it have been rotated out.
+func (l *Logger) Verify() (int, error) {
+	if l == nil {
+		return 0, nil
+	}
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	var prev *Record
+	count := 0
+	err := l.walk(func(r *Record) error {
+		hash, err := r.computeHash(l.key)
+		if err != nil {
+			return err
+		}
+		if !hmac.Equal([]byte(hash), []byte(r.Hash)) {
+			return fmt.Errorf("record %d has been altered", r.Sequence)
+		}
+		if prev == nil && r.Sequence != l.first {
+			return fmt.Errorf("records before %d are missing or altered", r.Sequence)
+		}
+		if prev != nil && (r.PrevHash != prev.Hash || r.Sequence != prev.Sequence+1) {
+			return fmt.Errorf("records between %d and %d are missing or altered", prev.Sequence, r.Sequence)
+		}
+		prev = r
+		count++
+		return nil
+	})
+	if err != nil {
+		return count, err
+	}
+	if l.seq != 0 && (prev == nil || prev.Sequence != l.seq || prev.Hash != l.lastHash) {
+		last := uint64(0)
+		if prev != nil {
+			last = prev.Sequence
+		}
+		return count, fmt.Errorf("records after %d are missing or altered", last)
+	}
+	return count, nil
+}
+
+// walk reads the records of the backups and of the current file, oldest first
+func (l *Logger) walk(fn func(r *Record) error) error {
+	for i := l.maxBackups; i >= 0; i-- {
+		file := l.path
+		if i > 0 {
+			file = l.backup(i)
+		}
+		if err := readRecords(file, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *Logger) backup(i int) string {
+	return fmt.Sprintf("%s.%d", l.path, i)
+}
+
+func (l *Logger) open() error {
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	l.file = f
+	l.size = info.Size()
+	return nil
+}
+
+// rotate shifts the backups, dropping the oldest one, and starts a new audit file
+func (l *Logger) rotate() error {
+	if err := l.file.Close(); err != nil {
+		return err
+	}
+	l.file = nil
+	if err := os.Remove(l.backup(l.maxBackups)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for i := l.maxBackups - 1; i >= 1; i-- {
+		if err := os.Rename(l.backup(i), l.backup(i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err := os.Rename(l.path, l.backup(1)); err != nil {
+		return err
+	}
+	// The oldest backup may have been dropped, the oldest kept record is anchored by the next write
+	first, err := l.firstRecord()
+	if err != nil {
+		return err
+	}
+	if first != nil {
+		l.first = first.Sequence
+	}
+	return l.open()
+}
+
+// firstRecord returns the oldest record of the backups and of the current file
+func (l *Logger) firstRecord() (*Record, error) {
+	var first *Record
+	err := l.walk(func(r *Record) error {
+		first = r
+		return errFound
+	})
+	if err != nil && err != errFound {
+		return nil, err
+	}
+	return first, nil
+}
+
+// readAnchor returns the anchor of the trail, or nil if there is none yet
+func (l *Logger) readAnchor() (*anchor, error) {
+	data, err := os.ReadFile(l.anchorPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	a := &anchor{}
+	if err := json.Unmarshal(data, a); err != nil {
+		return nil, fmt.Errorf("malformed audit anchor %s: %w", l.anchorPath, err)
+	}
+	if !hmac.Equal([]byte(a.computeMAC(l.key)), []byte(a.MAC)) {
+		return nil, fmt.Errorf("audit anchor %s has been altered or the key has changed", l.anchorPath)
+	}
+	return a, nil
+}
+
+// writeAnchor replaces the anchor with the range of the records kept
+func (l *Logger) writeAnchor() error {
+	a := &anchor{First: l.first, Last: l.seq, Hash: l.lastHash}
+	a.MAC = a.computeMAC(l.key)
+	data, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+	tmp := l.anchorPath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, l.anchorPath)
+}
+
+// readRecords calls fn for each record of the file, a missing file holds no records
+func readRecords(file string, fn func(r *Record) error) error {
+	f, err := os.Open(filepath.Clean(file))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		r := &Record{}
+		if err := json.Unmarshal(scanner.Bytes(), r); err != nil {
+			return fmt.Errorf("malformed audit record in %s: %w", file, err)
+		}
+		if err := fn(r); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// marshal returns the JSON form of a message for the record
+func marshal(m proto.Message) json.RawMessage {
+	if m == nil || !m.ProtoReflect().IsValid() {
+		return nil
+	}
+	data, err := protojson.Marshal(m)
+	if err != nil {
+		logger.Errorw("Audit: Failed to marshal", "type", fmt.Sprintf("%T", m), "error", err)
+		return nil
+	}
+	return data
+}
+
+func write(r *Record) {
+	r.Time = time.Now().UTC()
+	if err := Trail.Write(r); err != nil {
+		logger.Errorw("Audit: Failed to write the audit record", "action", r.Action, logging.Name(r.Object), "error", err)
+	}
+}
+
+// Mutation records a mutation of an object requested by a client of the gRPC API
+// with the stored object before and after the mutation
+func Mutation(ctx context.Context, action, objectType, name string, request, before, after proto.Message, resourceVersion string, err error) {
+	if Trail == nil {
+		return
+	}
+	r := &Record{
+		Source:          SourceGrpc,
+		Action:          action,
+		ObjectType:      objectType,
+		Object:          name,
+		Identity:        utils.ClientIdentity(ctx),
+		Request:         marshal(request),
+		Before:          marshal(before),
+		After:           marshal(after),
+		Result:          status.Code(err).String(),
+		ResourceVersion: resourceVersion,
+	}
+	if err != nil {
+		r.Error = err.Error()
+	}
+	write(r)
+}
+
+// Realization records the outcome of the realization of an object by the subscribers
+// on behalf of the client that has mutated it last, err is the error of a realization
+// that a subscriber has failed or that has been dropped
+func Realization(action, objectType, name, identity, resourceVersion string, err error) {
+	if Trail == nil {
+		return
+	}
+	r := &Record{
+		Source:          SourceInfraDB,
+		Action:          action,
+		ObjectType:      objectType,
+		Object:          name,
+		Identity:        identity,
+		Result:          ResultRealized,
+		ResourceVersion: resourceVersion,
+	}
+	if err != nil {
+		r.Result = ResultFailed
+		r.Error = err.Error()
+	}
+	write(r)
+}
@@ -0,0 +1,445 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package audit keeps a tamper-evident trail of the mutations of the intent objects
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
+)
+
+var testKey = []byte("audit-test-key")
+
+func newTestLogger(t *testing.T, maxBackups int) (*Logger, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "audit.log")
+	l, err := NewLogger(path, "", testKey, 0, maxBackups)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = l.Close() })
+	return l, path
+}
+
+func writeRecords(t *testing.T, l *Logger, objects ...string) {
+	t.Helper()
+	for _, object := range objects {
+		r := &Record{
+			Time:       time.Now().UTC(),
+			Source:     SourceGrpc,
+			Action:     ActionCreate,
+			ObjectType: "vrf",
+			Object:     object,
+			Identity:   "netops",
+			Result:     codes.OK.String(),
+		}
+		if err := l.Write(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLogger_Chain(t *testing.T) {
+	l, path := newTestLogger(t, 0)
+	writeRecords(t, l, "blue", "red")
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The chain continues after the trail is reopened
+	l, err := NewLogger(path, "", testKey, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	writeRecords(t, l, "green")
+
+	records, err := l.Query(Query{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("Expected 3 records, received %d", len(records))
+	}
+	for i, r := range records {
+		if r.Sequence != uint64(i+1) {
+			t.Errorf("Expected sequence %d, received %d", i+1, r.Sequence)
+		}
+		if i > 0 && r.PrevHash != records[i-1].Hash {
+			t.Errorf("Record %d is not chained to the previous one", r.Sequence)
+		}
+	}
+	if count, err := l.Verify(); err != nil || count != 3 {
+		t.Errorf("Expected 3 verified records, received %d: %v", count, err)
+	}
+}
+
+func TestLogger_Rotate(t *testing.T) {
+	l, path := newTestLogger(t, 2)
+	l.maxSize = 1 // a record per file
+	writeRecords(t, l, "a", "b", "c", "d")
+
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("Expected only 2 backups to be kept, received %v", err)
+	}
+	records, err := l.Query(Query{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	objects := []string{}
+	for _, r := range records {
+		objects = append(objects, r.Object)
+	}
+	if strings.Join(objects, ",") != "b,c,d" {
+		t.Errorf("Expected the records b,c,d to be kept, received %v", objects)
+	}
+	if count, err := l.Verify(); err != nil || count != 3 {
+		t.Errorf("Expected 3 verified records, received %d: %v", count, err)
+	}
+
+	// Removing the oldest backup kept is not a rotation
+	if err := os.Remove(path + ".2"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.Verify(); err == nil || err.Error() != "records before 3 are missing or altered" {
+		t.Errorf("Expected the removed backup to be detected, received %v", err)
+	}
+}
+
+func TestNewLogger(t *testing.T) {
+	tests := map[string]struct {
+		key    []byte
+		tamper func(path string) error
+		errMsg string
+	}{
+		"no key": {
+			key:    nil,
+			errMsg: "audit trail needs a key",
+		},
+		"other key": {
+			key:    []byte("other-key"),
+			errMsg: "audit anchor %s.anchor has been altered or the key has changed",
+		},
+		"altered anchor": {
+			key: testKey,
+			tamper: func(path string) error {
+				data, err := os.ReadFile(filepath.Clean(path + anchorSuffix))
+				if err != nil {
+					return err
+				}
+				data = []byte(strings.Replace(string(data), `"last":2`, `"last":1`, 1))
+				return os.WriteFile(path+anchorSuffix, data, 0600)
+			},
+			errMsg: "audit anchor %s.anchor has been altered or the key has changed",
+		},
+		"removed anchor": {
+			key: testKey,
+			tamper: func(path string) error {
+				return os.Remove(path + anchorSuffix)
+			},
+			errMsg: "audit anchor %s.anchor is missing for the records of %[1]s",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			l, path := newTestLogger(t, 0)
+			writeRecords(t, l, "a", "b")
+			if err := l.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if tt.tamper != nil {
+				if err := tt.tamper(path); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			_, err := NewLogger(path, "", tt.key, 0, 0)
+			errMsg := tt.errMsg
+			if strings.Contains(errMsg, "%") {
+				errMsg = fmt.Sprintf(errMsg, path)
+			}
+			if err == nil || err.Error() != errMsg {
+				t.Errorf("Expected error %v, received %v", errMsg, err)
+			}
+		})
+	}
+}
+
+func TestLogger_Query(t *testing.T) {
+	l, _ := newTestLogger(t, 0)
+	writeRecords(t, l, "blue", "red", "blue", "blue")
+	if err := l.Write(&Record{Time: time.Now().UTC(), Source: SourceInfraDB, Action: ActionRealized, ObjectType: "svi", Object: "blue", Identity: "monitoring"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		query Query
+		seqs  []uint64
+	}{
+		"all records": {
+			query: Query{},
+			seqs:  []uint64{1, 2, 3, 4, 5},
+		},
+		"by object type": {
+			query: Query{ObjectType: "svi"},
+			seqs:  []uint64{5},
+		},
+		"by object": {
+			query: Query{ObjectType: "vrf", Object: "blue"},
+			seqs:  []uint64{1, 3, 4},
+		},
+		"by identity": {
+			query: Query{Identity: "monitoring"},
+			seqs:  []uint64{5},
+		},
+		"with limit": {
+			query: Query{Object: "blue", Limit: 2},
+			seqs:  []uint64{4, 5},
+		},
+		"in the future": {
+			query: Query{Since: time.Now().Add(time.Hour)},
+			seqs:  []uint64{},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			records, err := l.Query(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			seqs := []uint64{}
+			for _, r := range records {
+				seqs = append(seqs, r.Sequence)
+			}
+			if len(seqs) != len(tt.seqs) {
+				t.Fatalf("Expected records %v, received %v", tt.seqs, seqs)
+			}
+			for i := range seqs {
+				if seqs[i] != tt.seqs[i] {
+					t.Errorf("Expected records %v, received %v", tt.seqs, seqs)
+				}
+			}
+		})
+	}
+}
+
+func TestLogger_Verify(t *testing.T) {
+	tests := map[string]struct {
+		tamper func(lines []string) []string
+		errMsg string
+	}{
+		"altered record": {
+			tamper: func(lines []string) []string {
+				lines[1] = strings.Replace(lines[1], `"identity":"netops"`, `"identity":"someone"`, 1)
+				return lines
+			},
+			errMsg: "record 2 has been altered",
+		},
+		"removed record": {
+			tamper: func(lines []string) []string {
+				return append(lines[:1], lines[2:]...)
+			},
+			errMsg: "records between 1 and 3 are missing or altered",
+		},
+		"removed first record": {
+			tamper: func(lines []string) []string {
+				return lines[1:]
+			},
+			errMsg: "records before 2 are missing or altered",
+		},
+		"truncated trail": {
+			tamper: func(lines []string) []string {
+				return lines[:2]
+			},
+			errMsg: "records after 2 are missing or altered",
+		},
+		"emptied trail": {
+			tamper: func(lines []string) []string {
+				return nil
+			},
+			errMsg: "records after 0 are missing or altered",
+		},
+		"rewritten chain": {
+			tamper: func(lines []string) []string {
+				// The chain is consistent but it is not signed with the key of the trail
+				prevHash := ""
+				for i, line := range lines {
+					r := &Record{}
+					if err := json.Unmarshal([]byte(line), r); err != nil {
+						panic(err)
+					}
+					r.Identity = "someone"
+					r.PrevHash = prevHash
+					r.Hash, _ = r.computeHash([]byte("other-key"))
+					prevHash = r.Hash
+					data, _ := json.Marshal(r)
+					lines[i] = string(data)
+				}
+				return lines
+			},
+			errMsg: "record 1 has been altered",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			l, path := newTestLogger(t, 0)
+			writeRecords(t, l, "a", "b", "c")
+
+			data, err := os.ReadFile(filepath.Clean(path))
+			if err != nil {
+				t.Fatal(err)
+			}
+			lines := tt.tamper(strings.Split(strings.TrimSpace(string(data)), "\n"))
+			content := strings.Join(lines, "\n")
+			if content != "" {
+				content += "\n"
+			}
+			if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = l.Verify()
+			if err == nil || err.Error() != tt.errMsg {
+				t.Errorf("Expected error %v, received %v", tt.errMsg, err)
+			}
+		})
+	}
+}
+
+func TestMutation(t *testing.T) {
+	l, _ := newTestLogger(t, 0)
+	Trail = l
+	defer func() { Trail = nil }()
+
+	ctx := utils.ContextWithIdentity(context.Background(), utils.Identity{Name: "netops", Role: utils.RoleAdmin})
+	after, _ := structpb.NewStruct(map[string]interface{}{"vni": 100})
+	Mutation(ctx, ActionCreate, "vrf", "//network.opiproject.org/vrfs/blue", nil, nil, after, "1", nil)
+	Mutation(context.Background(), ActionDelete, "vrf", "//network.opiproject.org/vrfs/red", nil, nil, nil, "",
+		status.Error(codes.NotFound, "unable to find key"))
+
+	records, err := l.Query(Query{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 records, received %d", len(records))
+	}
+	if r := records[0]; r.Identity != "netops" || r.Result != "OK" || string(r.After) != `{"vni":100}` {
+		t.Errorf("Unexpected create record %+v", r)
+	}
+	if r := records[1]; r.Identity != utils.AnonymousIdentity || r.Result != "NotFound" || r.Error == "" {
+		t.Errorf("Unexpected delete record %+v", r)
+	}
+}
+
+type testRecordStream struct {
+	grpc.ServerStream
+	records []*structpb.Struct
+}
+
+func (s *testRecordStream) Context() context.Context {
+	return context.Background()
+}
+
+func (s *testRecordStream) SendMsg(m interface{}) error {
+	s.records = append(s.records, m.(*structpb.Struct))
+	return nil
+}
+
+func TestServer_ListAuditRecords(t *testing.T) {
+	l, _ := newTestLogger(t, 0)
+	writeRecords(t, l, "blue", "red", "blue")
+
+	tests := map[string]struct {
+		in      map[string]interface{}
+		trail   *Logger
+		errCode codes.Code
+		objects []string
+	}{
+		"filter by object": {
+			in:      map[string]interface{}{"object": "blue"},
+			trail:   l,
+			errCode: codes.OK,
+			objects: []string{"blue", "blue"},
+		},
+		"limit": {
+			in:      map[string]interface{}{"limit": 1},
+			trail:   l,
+			errCode: codes.OK,
+			objects: []string{"blue"},
+		},
+		"negative limit": {
+			in:      map[string]interface{}{"limit": -1},
+			trail:   l,
+			errCode: codes.InvalidArgument,
+		},
+		"malformed timestamp": {
+			in:      map[string]interface{}{"since": "yesterday"},
+			trail:   l,
+			errCode: codes.InvalidArgument,
+		},
+		"unknown field": {
+			in:      map[string]interface{}{"color": "blue"},
+			trail:   l,
+			errCode: codes.InvalidArgument,
+		},
+		"disabled trail": {
+			in:      map[string]interface{}{},
+			trail:   nil,
+			errCode: codes.FailedPrecondition,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			in, err := structpb.NewStruct(tt.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			stream := &testRecordStream{}
+			err = NewServer(tt.trail).ListAuditRecords(in, stream)
+			if er := status.Code(err); er != tt.errCode {
+				t.Errorf("Expected error code %v, received %v: %v", tt.errCode, er, err)
+			}
+			if len(stream.records) != len(tt.objects) {
+				t.Fatalf("Expected %d records, received %d", len(tt.objects), len(stream.records))
+			}
+			for i, r := range stream.records {
+				if object := r.GetFields()["object"].GetStringValue(); object != tt.objects[i] {
+					t.Errorf("Expected object %v, received %v", tt.objects[i], object)
+				}
+			}
+		})
+	}
+}
+
+func TestServer_VerifyAuditLog(t *testing.T) {
+	l, _ := newTestLogger(t, 0)
+	writeRecords(t, l, "blue", "red")
+
+	out, err := NewServer(l).VerifyAuditLog(context.Background(), &emptypb.Empty{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !out.GetFields()["valid"].GetBoolValue() || out.GetFields()["records"].GetNumberValue() != 2 {
+		t.Errorf("Unexpected verification result %v", out)
+	}
+}
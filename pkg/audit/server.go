@@ -0,0 +1,178 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package audit keeps a tamper-evident trail of the mutations of the intent objects
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// ServiceName is the name of the audit admin service
+const ServiceName = "opi_evpn_bridge.v1.AuditService"
+
+// AdminServer is the server API for the AuditService
+type AdminServer interface {
+	ListAuditRecords(*structpb.Struct, grpc.ServerStream) error
+	VerifyAuditLog(context.Context, *emptypb.Empty) (*structpb.Struct, error)
+}
+
+// AdminServiceDesc describes the AuditService.
+// There are no generated messages for the audit records so the query and
+// the records are carried as google.protobuf.Struct with the JSON field names
+// of the Record.
+var AdminServiceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*AdminServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "VerifyAuditLog",
+			Handler:    verifyAuditLogHandler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ListAuditRecords",
+			Handler:       listAuditRecordsHandler,
+			ServerStreams: true,
+		},
+	},
+}
+
+// RegisterAdminServer registers the AuditService to the gRPC server
+func RegisterAdminServer(s grpc.ServiceRegistrar, srv AdminServer) {
+	s.RegisterService(&AdminServiceDesc, srv)
+}
+
+func verifyAuditLogHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).VerifyAuditLog(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/" + ServiceName + "/VerifyAuditLog",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).VerifyAuditLog(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func listAuditRecordsHandler(srv interface{}, stream grpc.ServerStream) error {
+	in := new(structpb.Struct)
+	if err := stream.RecvMsg(in); err != nil {
+		return err
+	}
+	return srv.(AdminServer).ListAuditRecords(in, stream)
+}
+
+// Server implements the AuditService on top of an audit trail
+type Server struct {
+	trail *Logger
+}
+
+// NewServer creates the audit admin server of the trail
+func NewServer(trail *Logger) *Server {
+	return &Server{trail: trail}
+}
+
+// ListAuditRecords streams the records that match the query, oldest first.
+// The query fields are objectType, object, identity, since and until as
+// RFC 3339 timestamps, and limit to keep only the most recent records.
+func (s *Server) ListAuditRecords(in *structpb.Struct, stream grpc.ServerStream) error {
+	if s.trail == nil {
+		return status.Error(codes.FailedPrecondition, "audit trail is disabled")
+	}
+	q, err := parseQuery(in)
+	if err != nil {
+		logger.Errorw("ListAuditRecords(): Validation failure", "error", err)
+		return err
+	}
+	records, err := s.trail.Query(q)
+	if err != nil {
+		logger.Errorw("ListAuditRecords(): Failed to read the audit trail", "error", err)
+		return status.Errorf(codes.Internal, "failed to read the audit trail: %v", err)
+	}
+	for _, r := range records {
+		msg, err := recordToStruct(r)
+		if err != nil {
+			return status.Errorf(codes.Internal, "failed to convert audit record %d: %v", r.Sequence, err)
+		}
+		if err := stream.SendMsg(msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// VerifyAuditLog checks the hash chain of the audit trail
+func (s *Server) VerifyAuditLog(_ context.Context, _ *emptypb.Empty) (*structpb.Struct, error) {
+	if s.trail == nil {
+		return nil, status.Error(codes.FailedPrecondition, "audit trail is disabled")
+	}
+	count, err := s.trail.Verify()
+	result := map[string]interface{}{
+		"valid":   err == nil,
+		"records": count,
+	}
+	if err != nil {
+		logger.Infow("VerifyAuditLog(): Verification failure", "error", err)
+		result["error"] = err.Error()
+	}
+	return structpb.NewStruct(result)
+}
+
+func parseQuery(in *structpb.Struct) (Query, error) {
+	q := Query{}
+	fields := in.GetFields()
+	for key, value := range fields {
+		var err error
+		switch key {
+		case "objectType":
+			q.ObjectType = value.GetStringValue()
+		case "object":
+			q.Object = value.GetStringValue()
+		case "identity":
+			q.Identity = value.GetStringValue()
+		case "since":
+			q.Since, err = time.Parse(time.RFC3339, value.GetStringValue())
+		case "until":
+			q.Until, err = time.Parse(time.RFC3339, value.GetStringValue())
+		case "limit":
+			if value.GetNumberValue() < 0 {
+				return q, status.Error(codes.InvalidArgument, "negative limit is not allowed")
+			}
+			q.Limit = int(value.GetNumberValue())
+		default:
+			return q, status.Errorf(codes.InvalidArgument, "unknown query field %s", key)
+		}
+		if err != nil {
+			return q, status.Errorf(codes.InvalidArgument, "invalid %s timestamp: %v", key, err)
+		}
+	}
+	return q, nil
+}
+
+func recordToStruct(r *Record) (*structpb.Struct, error) {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return structpb.NewStruct(fields)
+}
@@ -0,0 +1,273 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package batch applies the batch create and delete requests of the resources
+package batch
+
+import (
+	"context"
+	"errors"
+
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/audit"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/logging"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
+)
+
+// logger is the logger of the grpc subsystem
+var logger = logging.Logger(logging.Grpc).Sugar()
+
+// MaxSize is the maximum number of items accepted in a single batch request
+const MaxSize = 1000
+
+// Object is a resource of the API
+type Object interface {
+	proto.Message
+	GetName() string
+}
+
+// DeleteRequest is a request to delete a resource of the API
+type DeleteRequest interface {
+	proto.Message
+	GetName() string
+	GetAllowMissing() bool
+}
+
+// Create describes how the items of a batch of one kind of resource are created.
+// Obj is the resource of the API and Dom the domain object stored in the infra db.
+type Create[Req proto.Message, Obj Object, Dom any] struct {
+	// Kind is the kind of resource, as in the method names and the errors
+	Kind string
+	// ObjectType is the object type of the audit records
+	ObjectType string
+	// Prepare validates the request, sets the full name of the resource and returns it
+	Prepare func(req Req) (Obj, error)
+	// Get returns the resource stored with the name
+	Get func(name string) (Obj, error)
+	// Build validates the spec of the resource and translates it to the domain object
+	Build func(obj Obj, createdBy string) (Dom, error)
+	// Store creates all or none of the domain objects
+	Store func(doms []Dom) []error
+	// Result returns the created resource and its resource version
+	Result func(dom Dom) (Obj, string)
+}
+
+// Apply creates a batch of resources.
+// All the items are validated before anything is stored and either all or none
+// of the new resources are created. Already existing resources are returned as they are.
+func (c *Create[Req, Obj, Dom]) Apply(ctx context.Context, in []Req) ([]*spb.Status, error) {
+	method := "BatchCreate" + c.Kind + "s"
+	blog := logger.With("method", method, logging.Kind(c.ObjectType))
+	if len(in) > MaxSize {
+		return nil, status.Errorf(codes.InvalidArgument, "batch size exceeds the maximum of %d items", MaxSize)
+	}
+
+	results := make([]*spb.Status, len(in))
+	names := make(map[string]bool, len(in))
+	pending := []int{}
+	objs := []Obj{}
+	doms := []Dom{}
+
+	for i, req := range in {
+		// check input correctness
+		obj, err := c.Prepare(req)
+		if err != nil {
+			blog.Errorw("Validation failure", "error", err)
+			results[i] = itemStatus(nil, err)
+			continue
+		}
+		name := obj.GetName()
+		if names[name] {
+			err := status.Errorf(codes.InvalidArgument, "duplicate %s %s in batch", c.Kind, name)
+			results[i] = itemStatus(nil, err)
+			continue
+		}
+		names[name] = true
+
+		// idempotent API when called with same key, should return same object
+		existing, err := c.Get(name)
+		if err == nil {
+			blog.Infow("Already existing", logging.Name(name))
+			results[i] = itemStatus(existing, nil)
+			continue
+		}
+		if err != infradb.ErrKeyNotFound {
+			blog.Errorw("Failed to interact with store", "error", err)
+			results[i] = itemStatus(nil, err)
+			continue
+		}
+
+		// check parameters and translate pb to domain object
+		dom, err := c.Build(obj, utils.ClientIdentity(ctx))
+		if err != nil {
+			results[i] = itemStatus(nil, err)
+			continue
+		}
+		pending = append(pending, i)
+		objs = append(objs, obj)
+		doms = append(doms, dom)
+	}
+
+	if abort(results) || len(doms) == 0 {
+		return results, nil
+	}
+
+	// Note: The status of the objects will be generated in infraDB operation not here
+	errs := c.Store(doms)
+	for j, i := range pending {
+		if errs[j] != nil {
+			blog.Errorw("Create to DB failure", logging.Name(objs[j].GetName()), "error", errs[j])
+			audit.Mutation(ctx, audit.ActionCreate, c.ObjectType, objs[j].GetName(), objs[j], nil, nil, "", Error(errs[j]))
+			results[i] = itemStatus(nil, Error(errs[j]))
+			continue
+		}
+		response, resourceVersion := c.Result(doms[j])
+		audit.Mutation(ctx, audit.ActionCreate, c.ObjectType, objs[j].GetName(), objs[j], nil, response, resourceVersion, nil)
+		results[i] = itemStatus(response, nil)
+	}
+	return results, nil
+}
+
+// Delete describes how the items of a batch of one kind of resource are deleted
+type Delete[Req DeleteRequest, Obj Object] struct {
+	// Kind is the kind of resource, as in the method names and the errors
+	Kind string
+	// ObjectType is the object type of the audit records
+	ObjectType string
+	// Validate validates the request
+	Validate func(req Req) error
+	// Get returns the resource stored with the name
+	Get func(name string) (Obj, error)
+	// Store deletes all or none of the resources
+	Store func(names []string, deletedBy string) []error
+	// ResourceVersion returns the resource version of the resource once deleted
+	ResourceVersion func(name string) string
+}
+
+// Apply deletes a batch of resources.
+// Either all or none of the resources are deleted.
+func (d *Delete[Req, Obj]) Apply(ctx context.Context, in []Req) ([]*spb.Status, error) {
+	method := "BatchDelete" + d.Kind + "s"
+	blog := logger.With("method", method, logging.Kind(d.ObjectType))
+	if len(in) > MaxSize {
+		return nil, status.Errorf(codes.InvalidArgument, "batch size exceeds the maximum of %d items", MaxSize)
+	}
+
+	results := make([]*spb.Status, len(in))
+	names := make(map[string]bool, len(in))
+	pending := []int{}
+	pendingNames := []string{}
+	before := []Obj{}
+
+	for i, req := range in {
+		// check input correctness
+		if err := d.Validate(req); err != nil {
+			blog.Errorw("Validation failure", "error", err)
+			results[i] = itemStatus(nil, err)
+			continue
+		}
+		name := req.GetName()
+		if names[name] {
+			err := status.Errorf(codes.InvalidArgument, "duplicate %s %s in batch", d.Kind, name)
+			results[i] = itemStatus(nil, err)
+			continue
+		}
+		names[name] = true
+
+		// fetch object from the database
+		obj, err := d.Get(name)
+		if err != nil {
+			if err != infradb.ErrKeyNotFound {
+				blog.Errorw("Failed to interact with store", "error", err)
+				results[i] = itemStatus(nil, err)
+				continue
+			}
+			if !req.GetAllowMissing() {
+				err = status.Errorf(codes.NotFound, "unable to find key %s", name)
+				blog.Warnw("Not found", logging.Name(name), "error", err)
+				results[i] = itemStatus(nil, err)
+				continue
+			}
+			results[i] = itemStatus(&emptypb.Empty{}, nil)
+			continue
+		}
+		pending = append(pending, i)
+		pendingNames = append(pendingNames, name)
+		before = append(before, obj)
+	}
+
+	if abort(results) || len(pendingNames) == 0 {
+		return results, nil
+	}
+
+	// Note: The status of the objects will be generated in infraDB operation not here
+	errs := d.Store(pendingNames, utils.ClientIdentity(ctx))
+	for j, i := range pending {
+		audit.Mutation(ctx, audit.ActionDelete, d.ObjectType, pendingNames[j], nil, before[j], nil, d.ResourceVersion(pendingNames[j]), Error(errs[j]))
+		if errs[j] != nil {
+			blog.Errorw("Delete from DB failure", logging.Name(pendingNames[j]), "error", errs[j])
+			results[i] = itemStatus(nil, Error(errs[j]))
+			continue
+		}
+		results[i] = itemStatus(&emptypb.Empty{}, nil)
+	}
+	return results, nil
+}
+
+// Error maps the infradb batch errors to gRPC status codes
+func Error(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, infradb.ErrBatchAborted):
+		return status.Error(codes.Aborted, err.Error())
+	case errors.Is(err, infradb.ErrVniInUse), errors.Is(err, infradb.ErrKeyExists):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, infradb.ErrLogicalBridgeNotEmpty), errors.Is(err, infradb.ErrLogicalBridgeNotFound):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, infradb.ErrKeyNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	default:
+		return err
+	}
+}
+
+// itemStatus converts the outcome of a single batch item to a google.rpc.Status,
+// a successful status carries the resulting object in its details
+func itemStatus(obj proto.Message, err error) *spb.Status {
+	if err != nil {
+		return status.Convert(err).Proto()
+	}
+	detail, err := anypb.New(obj)
+	if err != nil {
+		return status.Convert(err).Proto()
+	}
+	return &spb.Status{Code: int32(codes.OK), Details: []*anypb.Any{detail}}
+}
+
+// abort sets an Aborted status to all the pending (nil) items of a batch
+// when any other item has failed and reports if the batch has been aborted
+func abort(results []*spb.Status) bool {
+	failed := false
+	for _, result := range results {
+		if result != nil && codes.Code(result.Code) != codes.OK {
+			failed = true
+			break
+		}
+	}
+	if failed {
+		for i, result := range results {
+			if result == nil {
+				results[i] = status.New(codes.Aborted, infradb.ErrBatchAborted.Error()).Proto()
+			}
+		}
+	}
+	return failed
+}
@@ -0,0 +1,79 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package bridge is the main package of the application
+package bridge
+
+import (
+	"context"
+
+	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+	"go.einride.tech/aip/resourceid"
+
+	epb "github.com/opiproject/opi-evpn-bridge/api/opi_evpn_bridge/v1"
+	"github.com/opiproject/opi-evpn-bridge/pkg/batch"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+// BatchCreateLogicalBridges creates a batch of LogicalBridges.
+// All the items are validated before anything is stored and either all or none
+// of the new LogicalBridges are created. Already existing LogicalBridges are returned as they are.
+func (s *Server) BatchCreateLogicalBridges(ctx context.Context, in *epb.BatchCreateLogicalBridgesRequest) (*epb.BatchCreateLogicalBridgesResponse, error) {
+	create := batch.Create[*pb.CreateLogicalBridgeRequest, *pb.LogicalBridge, *infradb.LogicalBridge]{
+		Kind:       "LogicalBridge",
+		ObjectType: "logical-bridge",
+		Prepare: func(req *pb.CreateLogicalBridgeRequest) (*pb.LogicalBridge, error) {
+			if err := s.validateCreateLogicalBridgeRequest(req); err != nil {
+				return nil, err
+			}
+			// see https://google.aip.dev/133#user-specified-ids
+			resourceID := resourceid.NewSystemGenerated()
+			if req.LogicalBridgeId != "" {
+				resourceID = req.LogicalBridgeId
+			}
+			req.LogicalBridge.Name = resourceIDToFullName(resourceID)
+			return req.LogicalBridge, nil
+		},
+		Get: s.getLogicalBridge,
+		Build: func(lb *pb.LogicalBridge, createdBy string) (*infradb.LogicalBridge, error) {
+			if err := s.validateLogicalBridgeSpec(lb); err != nil {
+				return nil, err
+			}
+			domainLB, err := infradb.NewLogicalBridge(lb)
+			if err != nil {
+				return nil, err
+			}
+			domainLB.Metadata.Audit.CreatedBy = createdBy
+			return domainLB, nil
+		},
+		Store: infradb.CreateLBs,
+		Result: func(domainLB *infradb.LogicalBridge) (*pb.LogicalBridge, string) {
+			return domainLB.ToPb(), domainLB.ResourceVersion
+		},
+	}
+	statuses, err := create.Apply(ctx, in.GetRequests())
+	if err != nil {
+		return nil, err
+	}
+	return &epb.BatchCreateLogicalBridgesResponse{Statuses: statuses}, nil
+}
+
+// BatchDeleteLogicalBridges deletes a batch of LogicalBridges.
+// Either all or none of the LogicalBridges are deleted.
+func (s *Server) BatchDeleteLogicalBridges(ctx context.Context, in *epb.BatchDeleteLogicalBridgesRequest) (*epb.BatchDeleteLogicalBridgesResponse, error) {
+	del := batch.Delete[*pb.DeleteLogicalBridgeRequest, *pb.LogicalBridge]{
+		Kind:            "LogicalBridge",
+		ObjectType:      "logical-bridge",
+		Validate:        s.validateDeleteLogicalBridgeRequest,
+		Get:             s.getLogicalBridge,
+		Store:           infradb.DeleteLBs,
+		ResourceVersion: s.resourceVersion,
+	}
+	statuses, err := del.Apply(ctx, in.GetRequests())
+	if err != nil {
+		return nil, err
+	}
+	return &epb.BatchDeleteLogicalBridgesResponse{Statuses: statuses}, nil
+}
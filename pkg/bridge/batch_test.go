@@ -0,0 +1,212 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package bridge is the main package of the application
+package bridge
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/stretchr/testify/assert"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+
+	epb "github.com/opiproject/opi-evpn-bridge/api/opi_evpn_bridge/v1"
+	"github.com/opiproject/opi-evpn-bridge/pkg/batch"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+func batchCreateRequest(id string, vlan uint32, vni *uint32) *pb.CreateLogicalBridgeRequest {
+	return &pb.CreateLogicalBridgeRequest{
+		LogicalBridgeId: id,
+		LogicalBridge:   &pb.LogicalBridge{Spec: &pb.LogicalBridgeSpec{VlanId: vlan, Vni: vni}},
+	}
+}
+
+// statusCodes returns the codes of the statuses of the batch items
+func statusCodes(statuses []*spb.Status) []codes.Code {
+	results := []codes.Code{}
+	for _, s := range statuses {
+		results = append(results, codes.Code(s.Code))
+	}
+	return results
+}
+
+func Test_BatchCreateLogicalBridges(t *testing.T) {
+	tests := map[string]struct {
+		in      []*pb.CreateLogicalBridgeRequest
+		out     []codes.Code
+		created []string
+	}{
+		"successful call": {
+			in: []*pb.CreateLogicalBridgeRequest{
+				batchCreateRequest("batch-lb1", 10, proto.Uint32(100)),
+				batchCreateRequest("batch-lb2", 20, proto.Uint32(200)),
+				batchCreateRequest("batch-lb3", 30, nil),
+			},
+			out:     []codes.Code{codes.OK, codes.OK, codes.OK},
+			created: []string{"batch-lb1", "batch-lb2", "batch-lb3"},
+		},
+		"already existing item is returned": {
+			in: []*pb.CreateLogicalBridgeRequest{
+				batchCreateRequest(testLogicalBridgeID, 22, proto.Uint32(11)),
+				batchCreateRequest("batch-lb1", 10, proto.Uint32(100)),
+			},
+			out:     []codes.Code{codes.OK, codes.OK},
+			created: []string{testLogicalBridgeID, "batch-lb1"},
+		},
+		"invalid item aborts the batch": {
+			in: []*pb.CreateLogicalBridgeRequest{
+				batchCreateRequest("batch-lb1", 10, proto.Uint32(100)),
+				batchCreateRequest("batch-lb2", 4096, nil),
+			},
+			out: []codes.Code{codes.Aborted, codes.InvalidArgument},
+		},
+		"duplicate name in batch": {
+			in: []*pb.CreateLogicalBridgeRequest{
+				batchCreateRequest("batch-lb1", 10, nil),
+				batchCreateRequest("batch-lb1", 20, nil),
+			},
+			out: []codes.Code{codes.Aborted, codes.InvalidArgument},
+		},
+		"vni in use by another item of the batch": {
+			in: []*pb.CreateLogicalBridgeRequest{
+				batchCreateRequest("batch-lb1", 10, proto.Uint32(100)),
+				batchCreateRequest("batch-lb2", 20, proto.Uint32(100)),
+			},
+			out: []codes.Code{codes.Aborted, codes.AlreadyExists},
+		},
+		"vni in use by an existing object": {
+			in: []*pb.CreateLogicalBridgeRequest{
+				batchCreateRequest("batch-lb1", 10, proto.Uint32(11)),
+			},
+			out: []codes.Code{codes.AlreadyExists},
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			env := newTestEnv(ctx, t)
+			defer env.Close()
+
+			testLogicalBridgeFull := pb.LogicalBridge{
+				Name: testLogicalBridgeName,
+				Spec: testLogicalBridge.Spec,
+			}
+			_, _ = env.opi.createLogicalBridge(ctx, &testLogicalBridgeFull)
+
+			client := epb.NewLogicalBridgeBatchServiceClient(env.conn)
+			response, err := client.BatchCreateLogicalBridges(ctx, &epb.BatchCreateLogicalBridgesRequest{Requests: tt.in})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.out, statusCodes(response.GetStatuses()))
+
+			for _, req := range tt.in {
+				_, err := infradb.GetLB(resourceIDToFullName(req.LogicalBridgeId))
+				if slices.Contains(tt.created, req.LogicalBridgeId) {
+					assert.NoError(t, err, req.LogicalBridgeId)
+				} else {
+					assert.Equal(t, infradb.ErrKeyNotFound, err, req.LogicalBridgeId)
+				}
+			}
+		})
+	}
+}
+
+func Test_BatchDeleteLogicalBridges(t *testing.T) {
+	tests := map[string]struct {
+		in  []*pb.DeleteLogicalBridgeRequest
+		out []codes.Code
+	}{
+		"successful call": {
+			in: []*pb.DeleteLogicalBridgeRequest{
+				{Name: testLogicalBridgeName},
+				{Name: resourceIDToFullName("unknown-id"), AllowMissing: true},
+			},
+			out: []codes.Code{codes.OK, codes.OK},
+		},
+		"unknown key aborts the batch": {
+			in: []*pb.DeleteLogicalBridgeRequest{
+				{Name: testLogicalBridgeName},
+				{Name: resourceIDToFullName("unknown-id")},
+			},
+			out: []codes.Code{codes.Aborted, codes.NotFound},
+		},
+		"duplicate name in batch": {
+			in: []*pb.DeleteLogicalBridgeRequest{
+				{Name: testLogicalBridgeName},
+				{Name: testLogicalBridgeName},
+			},
+			out: []codes.Code{codes.Aborted, codes.InvalidArgument},
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			env := newTestEnv(ctx, t)
+			defer env.Close()
+
+			testLogicalBridgeFull := pb.LogicalBridge{
+				Name: testLogicalBridgeName,
+				Spec: testLogicalBridge.Spec,
+			}
+			_, _ = env.opi.createLogicalBridge(ctx, &testLogicalBridgeFull)
+
+			client := epb.NewLogicalBridgeBatchServiceClient(env.conn)
+			response, err := client.BatchDeleteLogicalBridges(ctx, &epb.BatchDeleteLogicalBridgesRequest{Requests: tt.in})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.out, statusCodes(response.GetStatuses()))
+
+			lb, err := infradb.GetLB(testLogicalBridgeName)
+			assert.NoError(t, err)
+			deleted := lb.Status.LBOperStatus == infradb.LogicalBridgeOperStatusToBeDeleted
+			assert.Equal(t, tt.out[0] == codes.OK, deleted)
+		})
+	}
+}
+
+func Test_BatchCreateLogicalBridges_MaxBatchSize(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(ctx, t)
+	defer env.Close()
+
+	in := make([]*pb.CreateLogicalBridgeRequest, batch.MaxSize+1)
+	for i := range in {
+		in[i] = batchCreateRequest("", 10, nil)
+	}
+	_, err := env.opi.BatchCreateLogicalBridges(ctx, &epb.BatchCreateLogicalBridgesRequest{Requests: in})
+	assert.Error(t, err)
+}
+
+func Test_BatchLogicalBridgesGateway(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(ctx, t)
+	defer env.Close()
+
+	mux := runtime.NewServeMux()
+	err := epb.RegisterLogicalBridgeBatchServiceHandlerClient(ctx, mux, epb.NewLogicalBridgeBatchServiceClient(env.conn))
+	assert.NoError(t, err)
+
+	body := `{"requests": [{"logicalBridgeId": "batch-lb1", "logicalBridge": {"spec": {"vlanId": 10}}}]}`
+	req := httptest.NewRequest(http.MethodPost, "/v1/logicalBridges:batchCreate", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	_, err = infradb.GetLB(resourceIDToFullName("batch-lb1"))
+	assert.NoError(t, err)
+}
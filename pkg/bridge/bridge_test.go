@@ -12,7 +12,10 @@ import (
 	"reflect"
 	"testing"
 
+	"cloud.google.com/go/longrunning/autogen/longrunningpb"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/emptypb"
@@ -21,6 +24,7 @@ import (
 	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
 	pc "github.com/opiproject/opi-api/network/opinetcommon/v1alpha1/gen/go"
 
+	"github.com/opiproject/opi-evpn-bridge/pkg/operations"
 	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
 	"github.com/opiproject/opi-evpn-bridge/pkg/utils/mocks"
 )
@@ -171,7 +175,7 @@ func Test_CreateLogicalBridge(t *testing.T) {
 					Name: testLogicalBridgeName,
 					Spec: testLogicalBridge.Spec,
 				}
-				_, _ = env.opi.createLogicalBridge(&testLogicalBridgeFull)
+				_, _ = env.opi.createLogicalBridge(ctx, &testLogicalBridgeFull)
 			}
 			if tt.out != nil {
 				tt.out = utils.ProtoClone(tt.out)
@@ -258,7 +262,7 @@ func Test_DeleteLogicalBridge(t *testing.T) {
 				Name: testLogicalBridgeName,
 				Spec: testLogicalBridge.Spec,
 			}
-			_, _ = env.opi.createLogicalBridge(&testLogicalBridgeFull)
+			_, _ = env.opi.createLogicalBridge(ctx, &testLogicalBridgeFull)
 
 			if tt.on != nil {
 				tt.on(env.mockNetlink, env.mockFrr, tt.errMsg)
@@ -290,6 +294,15 @@ func Test_UpdateLogicalBridge(t *testing.T) {
 		Vni:    proto.Uint32(11),
 		VlanId: 22,
 	}
+	updatedVtepIPPrefix := &pc.IPPrefix{
+		Addr: &pc.IPAddress{
+			Af: pc.IpAf_IP_AF_INET,
+			V4OrV6: &pc.IPAddress_V4Addr{
+				V4Addr: 167772163,
+			},
+		},
+		Len: 24,
+	}
 	tests := map[string]struct {
 		mask    *fieldmaskpb.FieldMask
 		in      *pb.LogicalBridge
@@ -323,6 +336,49 @@ func Test_UpdateLogicalBridge(t *testing.T) {
 			start:   false,
 			exist:   true,
 		},
+		"immutable vlan id": {
+			mask: &fieldmaskpb.FieldMask{Paths: []string{"spec.vlan_id"}},
+			in: &pb.LogicalBridge{
+				Name: testLogicalBridgeName,
+				Spec: &pb.LogicalBridgeSpec{VlanId: 23},
+			},
+			out:     nil,
+			errCode: codes.InvalidArgument,
+			errMsg:  fmt.Sprintf("VlanId of Logical Bridge %v cannot be changed", testLogicalBridgeName),
+			start:   false,
+			exist:   true,
+		},
+		"immutable vni": {
+			mask: &fieldmaskpb.FieldMask{Paths: []string{"spec.vni"}},
+			in: &pb.LogicalBridge{
+				Name: testLogicalBridgeName,
+				Spec: &pb.LogicalBridgeSpec{VlanId: 22, Vni: proto.Uint32(12)},
+			},
+			out:     nil,
+			errCode: codes.InvalidArgument,
+			errMsg:  fmt.Sprintf("Vni of Logical Bridge %v cannot be changed", testLogicalBridgeName),
+			start:   false,
+			exist:   true,
+		},
+		"valid request vtep ip": {
+			mask: &fieldmaskpb.FieldMask{Paths: []string{"spec.vtep_ip_prefix"}},
+			in: &pb.LogicalBridge{
+				Name: testLogicalBridgeName,
+				Spec: &pb.LogicalBridgeSpec{VlanId: 22, VtepIpPrefix: updatedVtepIPPrefix},
+			},
+			out: &pb.LogicalBridge{
+				Spec: &pb.LogicalBridgeSpec{
+					Vni:          testLogicalBridge.Spec.Vni,
+					VlanId:       testLogicalBridge.Spec.VlanId,
+					VtepIpPrefix: updatedVtepIPPrefix,
+				},
+				Status: testLogicalBridge.Status,
+			},
+			errCode: codes.OK,
+			errMsg:  "",
+			start:   false,
+			exist:   true,
+		},
 	}
 
 	// run tests
@@ -338,7 +394,7 @@ func Test_UpdateLogicalBridge(t *testing.T) {
 					Name: testLogicalBridgeName,
 					Spec: testLogicalBridge.Spec,
 				}
-				_, _ = env.opi.createLogicalBridge(&testLogicalBridgeFull)
+				_, _ = env.opi.createLogicalBridge(ctx, &testLogicalBridgeFull)
 			}
 			if tt.out != nil {
 				tt.out = utils.ProtoClone(tt.out)
@@ -407,7 +463,7 @@ func Test_GetLogicalBridge(t *testing.T) {
 				Name: testLogicalBridgeName,
 				Spec: testLogicalBridge.Spec,
 			}
-			_, _ = env.opi.createLogicalBridge(&testLogicalBridgeFull)
+			_, _ = env.opi.createLogicalBridge(ctx, &testLogicalBridgeFull)
 
 			request := &pb.GetLogicalBridgeRequest{Name: tt.in}
 			response, err := client.GetLogicalBridge(ctx, request)
@@ -457,8 +513,8 @@ func Test_ListLogicalBridges(t *testing.T) {
 		"pagination error": {
 			in:      "",
 			out:     nil,
-			errCode: codes.NotFound,
-			errMsg:  fmt.Sprintf("unable to find pagination token %s", "unknown-pagination-token"),
+			errCode: codes.InvalidArgument,
+			errMsg:  fmt.Sprintf("invalid pagination token %s", "unknown-pagination-token"),
 			size:    0,
 			token:   "unknown-pagination-token",
 		},
@@ -500,10 +556,14 @@ func Test_ListLogicalBridges(t *testing.T) {
 				Name: testLogicalBridgeName,
 				Spec: testLogicalBridge.Spec,
 			}
-			_, _ = env.opi.createLogicalBridge(&testLogicalBridgeFull)
-			env.opi.Pagination["existing-pagination-token"] = 1
+			_, _ = env.opi.createLogicalBridge(ctx, &testLogicalBridgeFull)
+			token := tt.token
+			if token == "existing-pagination-token" {
+				// cursor positioned after the only object in the store
+				token, _ = utils.PageTokenAfter(&testLogicalBridgeFull, "", "")
+			}
 
-			request := &pb.ListLogicalBridgesRequest{PageSize: tt.size, PageToken: tt.token}
+			request := &pb.ListLogicalBridgesRequest{PageSize: tt.size, PageToken: token}
 			response, err := client.ListLogicalBridges(ctx, request)
 			if !utils.EqualProtoSlices(response.GetLogicalBridges(), tt.out) {
 				t.Error("response: expected", tt.out, "received", response.GetLogicalBridges())
@@ -527,3 +587,30 @@ func Test_ListLogicalBridges(t *testing.T) {
 		})
 	}
 }
+
+func Test_CreateLogicalBridge_Operation(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(ctx, t)
+	defer env.Close()
+	client := pb.NewLogicalBridgeServiceClient(env.conn)
+
+	var header metadata.MD
+	ctx = metadata.AppendToOutgoingContext(ctx, operations.MetadataKey, "true")
+	request := &pb.CreateLogicalBridgeRequest{LogicalBridge: utils.ProtoClone(&testLogicalBridge), LogicalBridgeId: testLogicalBridgeID}
+	_, err := client.CreateLogicalBridge(ctx, request, grpc.Header(&header))
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	names := header.Get(operations.MetadataKey)
+	if len(names) != 1 {
+		t.Fatal("expected an operation name in the response header, received", header)
+	}
+	op, err := operations.NewServer().GetOperation(ctx, &longrunningpb.GetOperationRequest{Name: names[0]})
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if op.Done {
+		t.Error("expected the operation to wait for the realization of the object")
+	}
+}
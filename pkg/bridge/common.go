@@ -9,9 +9,8 @@ package bridge
 import (
 	"context"
 	"fmt"
-	"log"
 	"net"
-	"sort"
+	"strings"
 	"testing"
 
 	"go.einride.tech/aip/resourcename"
@@ -21,18 +20,20 @@ import (
 
 	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
 
+	epb "github.com/opiproject/opi-evpn-bridge/api/opi_evpn_bridge/v1"
+	"github.com/opiproject/opi-evpn-bridge/pkg/audit"
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
 	"github.com/opiproject/opi-evpn-bridge/pkg/utils/mocks"
 )
 
-func sortLogicalBridges(bridges []*pb.LogicalBridge) {
-	sort.Slice(bridges, func(i int, j int) bool {
-		return bridges[i].Name < bridges[j].Name
-	})
-}
+func (s *Server) createLogicalBridge(ctx context.Context, lb *pb.LogicalBridge) (response *pb.LogicalBridge, err error) {
+	var resourceVersion string
+	defer func() {
+		audit.Mutation(ctx, audit.ActionCreate, "logical-bridge", lb.Name, lb, nil, response, resourceVersion, err)
+	}()
 
-func (s *Server) createLogicalBridge(lb *pb.LogicalBridge) (*pb.LogicalBridge, error) {
 	// check parameters
 	if err := s.validateLogicalBridgeSpec(lb); err != nil {
 		return nil, err
@@ -43,16 +44,23 @@ func (s *Server) createLogicalBridge(lb *pb.LogicalBridge) (*pb.LogicalBridge, e
 	if err != nil {
 		return nil, err
 	}
+	domainLB.Metadata.Audit.CreatedBy = utils.ClientIdentity(ctx)
 	// Note: The status of the object will be generated in infraDB operation not here
 	if err := infradb.CreateLB(domainLB); err != nil {
 		return nil, err
 	}
+	resourceVersion = domainLB.ResourceVersion
 	return domainLB.ToPb(), nil
 }
 
-func (s *Server) deleteLogicalBridge(name string) error {
+func (s *Server) deleteLogicalBridge(ctx context.Context, name string) (err error) {
+	before, _ := s.getLogicalBridge(name)
+	defer func() {
+		audit.Mutation(ctx, audit.ActionDelete, "logical-bridge", name, nil, before, nil, s.resourceVersion(name), err)
+	}()
+
 	// Note: The status of the object will be generated in infraDB operation not here
-	if err := infradb.DeleteLB(name); err != nil {
+	if err := infradb.DeleteLB(name, utils.ClientIdentity(ctx)); err != nil {
 		return err
 	}
 	return nil
@@ -79,7 +87,13 @@ func (s *Server) getAllLogicalBridges() ([]*pb.LogicalBridge, error) {
 	return lbs, nil
 }
 
-func (s *Server) updateLogicalBridge(lb *pb.LogicalBridge) (*pb.LogicalBridge, error) {
+func (s *Server) updateLogicalBridge(ctx context.Context, lb *pb.LogicalBridge) (response *pb.LogicalBridge, err error) {
+	before, _ := s.getLogicalBridge(lb.Name)
+	var resourceVersion string
+	defer func() {
+		audit.Mutation(ctx, audit.ActionUpdate, "logical-bridge", lb.Name, lb, before, response, resourceVersion, err)
+	}()
+
 	// check parameters
 	if err := s.validateLogicalBridgeSpec(lb); err != nil {
 		return nil, err
@@ -90,13 +104,25 @@ func (s *Server) updateLogicalBridge(lb *pb.LogicalBridge) (*pb.LogicalBridge, e
 	if err != nil {
 		return nil, err
 	}
+	domainLB.Metadata.Audit.UpdatedBy = utils.ClientIdentity(ctx)
 	// Note: The status of the object will be generated in infraDB operation not here
 	if err := infradb.UpdateLB(domainLB); err != nil {
 		return nil, err
 	}
+	resourceVersion = domainLB.ResourceVersion
 	return domainLB.ToPb(), nil
 }
 
+// resourceVersion returns the resource version of the stored object
+// or an empty string if the object does not exist anymore
+func (s *Server) resourceVersion(name string) string {
+	obj, err := infradb.GetLB(name)
+	if err != nil {
+		return ""
+	}
+	return obj.ResourceVersion
+}
+
 func resourceIDToFullName(resourceID string) string {
 	return resourcename.Join(
 		"//network.opiproject.org/",
@@ -104,6 +130,15 @@ func resourceIDToFullName(resourceID string) string {
 	)
 }
 
+// fullName converts the relative name that the HTTP gateway takes from the URL path,
+// e.g. "logicalBridges/{id}", to the full resource name
+func fullName(name string) string {
+	if strings.HasPrefix(name, "logicalBridges/") {
+		return resourceIDToFullName(strings.TrimPrefix(name, "logicalBridges/"))
+	}
+	return name
+}
+
 // TODO: Move all these functions to a common place and replace them by one function
 // for all the objects.
 func checkTobeDeletedStatus(lb *pb.LogicalBridge) error {
@@ -126,7 +161,7 @@ type testEnv struct {
 func (e *testEnv) Close() {
 	err := e.conn.Close()
 	if err != nil {
-		log.Fatal(err)
+		logger.Fatal(err)
 	}
 }
 
@@ -162,7 +197,7 @@ func newTestEnv(ctx context.Context, t *testing.T) *testEnv {
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
 		grpc.WithContextDialer(dialer(env.opi)))
 	if err != nil {
-		log.Fatal(err)
+		logger.Fatal(err)
 	}
 	env.conn = conn
 	return env
@@ -173,10 +208,11 @@ func dialer(opi *Server) func(context.Context, string) (net.Conn, error) {
 	server := grpc.NewServer()
 
 	pb.RegisterLogicalBridgeServiceServer(server, opi)
+	epb.RegisterLogicalBridgeBatchServiceServer(server, opi)
 
 	go func() {
 		if err := server.Serve(listener); err != nil {
-			log.Fatal(err)
+			logger.Fatal(err)
 		}
 	}()
 
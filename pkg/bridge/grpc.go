@@ -8,114 +8,132 @@ package bridge
 
 import (
 	"context"
-	"log"
 	"reflect"
 
-	"github.com/google/uuid"
+	"github.com/opiproject/opi-evpn-bridge/pkg/logging"
 	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
 
 	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
 
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/operations"
 	"go.einride.tech/aip/resourceid"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/emptypb"
 )
 
 // CreateLogicalBridge executes the creation of the LogicalBridge
-func (s *Server) CreateLogicalBridge(_ context.Context, in *pb.CreateLogicalBridgeRequest) (*pb.LogicalBridge, error) {
+func (s *Server) CreateLogicalBridge(ctx context.Context, in *pb.CreateLogicalBridgeRequest) (*pb.LogicalBridge, error) {
 	// check input correctness
 	if err := s.validateCreateLogicalBridgeRequest(in); err != nil {
-		log.Printf("CreateLogicalBridge(): validation failure: %v", err)
+		logger.Errorw("CreateLogicalBridge(): Validation failure", "error", err)
 		return nil, err
 	}
 
 	// see https://google.aip.dev/133#user-specified-ids
 	resourceID := resourceid.NewSystemGenerated()
 	if in.LogicalBridgeId != "" {
-		log.Printf("CreateLogicalBridge(): client provided the ID of a resource %v, ignoring the name field %v", in.LogicalBridgeId, in.LogicalBridge.Name)
+		logger.Infow("CreateLogicalBridge(): Client provided the ID of a resource, ignoring the name field", "logicalBridgeId", in.LogicalBridgeId, logging.Name(in.LogicalBridge.Name))
 		resourceID = in.LogicalBridgeId
 	}
 	in.LogicalBridge.Name = resourceIDToFullName(resourceID)
+	op := operations.Ops.Begin(ctx, in.LogicalBridge.Name)
 	// idempotent API when called with same key, should return same object
 	lbObj, err := s.getLogicalBridge(in.LogicalBridge.Name)
 	if err != nil {
 		if err != infradb.ErrKeyNotFound {
-			log.Printf("CreateLogicalBridge(): Failed to interact with store: %v", err)
+			logger.Errorw("CreateLogicalBridge(): Failed to interact with store", "error", err)
+			op.Abort(err)
 			return nil, err
 		}
 	} else {
-		log.Printf("CreateLogicalBridge(): Already existing LogicalBridge with id %v", in.LogicalBridge.Name)
+		logger.Infow("CreateLogicalBridge(): Logical Bridge already exists", logging.Name(in.LogicalBridge.Name))
+		op.Done(lbObj)
 		return lbObj, nil
 	}
 
 	// Store the domain object into DB
-	response, err := s.createLogicalBridge(in.LogicalBridge)
+	response, err := s.createLogicalBridge(ctx, in.LogicalBridge)
 	if err != nil {
-		log.Printf("CreateLogicalBridge(): LogicalBridge with id %v, Create Logical Bridge to DB failure: %v", in.LogicalBridge.Name, err)
+		logger.Errorw("CreateLogicalBridge(): Create Logical Bridge to DB failure", logging.Name(in.LogicalBridge.Name), "error", err)
+		op.Abort(err)
 		return nil, err
 	}
+	op.Commit(s.resourceVersion(response.Name), func() (proto.Message, error) {
+		return s.getLogicalBridge(response.Name)
+	})
 	return response, nil
 }
 
 // DeleteLogicalBridge deletes a LogicalBridge
-func (s *Server) DeleteLogicalBridge(_ context.Context, in *pb.DeleteLogicalBridgeRequest) (*emptypb.Empty, error) {
+func (s *Server) DeleteLogicalBridge(ctx context.Context, in *pb.DeleteLogicalBridgeRequest) (*emptypb.Empty, error) {
 	// check input correctness
 	if err := s.validateDeleteLogicalBridgeRequest(in); err != nil {
-		log.Printf("DeleteLogicalBridge(): validation failure: %v", err)
+		logger.Errorw("DeleteLogicalBridge(): Validation failure", "error", err)
 		return nil, err
 	}
+	in.Name = fullName(in.Name)
+	op := operations.Ops.Begin(ctx, in.Name)
 	// fetch object from the database
 	_, err := s.getLogicalBridge(in.Name)
 	if err != nil {
 		if err != infradb.ErrKeyNotFound {
-			log.Printf("Failed to interact with store: %v", err)
+			logger.Errorw("DeleteLogicalBridge(): Failed to interact with store", "error", err)
+			op.Abort(err)
 			return nil, err
 		}
 		if !in.AllowMissing {
 			err = status.Errorf(codes.NotFound, "unable to find key %s", in.Name)
-			log.Printf("DeleteLogicalBridge(): LogicalBridge with id %v: Not Found %v", in.Name, err)
+			logger.Warnw("DeleteLogicalBridge(): Logical Bridge not found", logging.Name(in.Name), "error", err)
+			op.Abort(err)
 			return nil, err
 		}
+		op.Done(&emptypb.Empty{})
 		return &emptypb.Empty{}, nil
 	}
 
-	if err := s.deleteLogicalBridge(in.Name); err != nil {
-		log.Printf("DeleteLogicalBridge(): LogicalBridge with id %v, Delete Logical Bridge from DB failure: %v", in.Name, err)
+	if err := s.deleteLogicalBridge(ctx, in.Name); err != nil {
+		logger.Errorw("DeleteLogicalBridge(): Delete Logical Bridge from DB failure", logging.Name(in.Name), "error", err)
+		op.Abort(err)
 		return nil, err
 	}
+	op.Commit(s.resourceVersion(in.Name), func() (proto.Message, error) {
+		return &emptypb.Empty{}, nil
+	})
 
 	return &emptypb.Empty{}, nil
 }
 
 // UpdateLogicalBridge updates a LogicalBridge
-func (s *Server) UpdateLogicalBridge(_ context.Context, in *pb.UpdateLogicalBridgeRequest) (*pb.LogicalBridge, error) {
+func (s *Server) UpdateLogicalBridge(ctx context.Context, in *pb.UpdateLogicalBridgeRequest) (*pb.LogicalBridge, error) {
 	// check input correctness
 	if err := s.validateUpdateLogicalBridgeRequest(in); err != nil {
-		log.Printf("UpdateLogicalBridge(): validation failure: %v", err)
+		logger.Errorw("UpdateLogicalBridge(): Validation failure", "error", err)
 		return nil, err
 	}
+	in.LogicalBridge.Name = fullName(in.LogicalBridge.Name)
 
 	// fetch object from the database
 	lbObj, err := s.getLogicalBridge(in.LogicalBridge.Name)
 	if err != nil {
 		if err != infradb.ErrKeyNotFound {
-			log.Printf("UpdateLogicalBridge(): Failed to interact with store: %v", err)
+			logger.Errorw("UpdateLogicalBridge(): Failed to interact with store", "error", err)
 			return nil, err
 		}
 		if !in.AllowMissing {
 			err = status.Errorf(codes.NotFound, "unable to find key %s", in.LogicalBridge.Name)
-			log.Printf("UpdateLogicalBridge(): LogicalBridge with id %v: Not Found %v", in.LogicalBridge.Name, err)
+			logger.Warnw("UpdateLogicalBridge(): Logical Bridge not found", logging.Name(in.LogicalBridge.Name), "error", err)
 			return nil, err
 		}
 
-		log.Printf("UpdateLogicalBridge(): Logical Bridge with id %v is not found so it will be created", in.LogicalBridge.Name)
+		logger.Warnw("UpdateLogicalBridge(): Logical Bridge is not found so it will be created", logging.Name(in.LogicalBridge.Name))
 
 		// Store the domain object into DB
-		response, err := s.createLogicalBridge(in.LogicalBridge)
+		response, err := s.createLogicalBridge(ctx, in.LogicalBridge)
 		if err != nil {
-			log.Printf("UpdateLogicalBridge(): LogicalBridge with id %v, Create Logical Bridge to DB failure: %v", in.LogicalBridge.Name, err)
+			logger.Errorw("UpdateLogicalBridge(): Create Logical Bridge to DB failure", logging.Name(in.LogicalBridge.Name), "error", err)
 			return nil, err
 		}
 		return response, nil
@@ -123,7 +141,7 @@ func (s *Server) UpdateLogicalBridge(_ context.Context, in *pb.UpdateLogicalBrid
 
 	// Check if the object for update is currently in TO_BE_DELETED status
 	if err := checkTobeDeletedStatus(lbObj); err != nil {
-		log.Printf("UpdateLogicalBridge(): Logical Bridge with id %v, Error: %v", in.LogicalBridge.Name, err)
+		logger.Errorw("UpdateLogicalBridge(): Logical Bridge cannot be updated", logging.Name(in.LogicalBridge.Name), "error", err)
 		return nil, err
 	}
 
@@ -140,9 +158,15 @@ func (s *Server) UpdateLogicalBridge(_ context.Context, in *pb.UpdateLogicalBrid
 		return lbObj, nil
 	}
 
-	response, err := s.updateLogicalBridge(updatedlbObj)
+	// Check that the update does not change any immutable field
+	if err := s.validateLogicalBridgeImmutableFields(lbObj, updatedlbObj); err != nil {
+		logger.Errorw("UpdateLogicalBridge(): Logical Bridge cannot be updated", logging.Name(in.LogicalBridge.Name), "error", err)
+		return nil, err
+	}
+
+	response, err := s.updateLogicalBridge(ctx, updatedlbObj)
 	if err != nil {
-		log.Printf("UpdateLogicalBridge(): LogicalBridge with id %v, Update Logical Bridge to DB failure: %v", in.LogicalBridge.Name, err)
+		logger.Errorw("UpdateLogicalBridge(): Update Logical Bridge to DB failure", logging.Name(in.LogicalBridge.Name), "error", err)
 		return nil, err
 	}
 
@@ -153,18 +177,19 @@ func (s *Server) UpdateLogicalBridge(_ context.Context, in *pb.UpdateLogicalBrid
 func (s *Server) GetLogicalBridge(_ context.Context, in *pb.GetLogicalBridgeRequest) (*pb.LogicalBridge, error) {
 	// check input correctness
 	if err := s.validateGetLogicalBridgeRequest(in); err != nil {
-		log.Printf("GetLogicalBridge(): validation failure: %v", err)
+		logger.Errorw("GetLogicalBridge(): Validation failure", "error", err)
 		return nil, err
 	}
+	in.Name = fullName(in.Name)
 	// fetch object from the database
 	lbObj, err := s.getLogicalBridge(in.Name)
 	if err != nil {
 		if err != infradb.ErrKeyNotFound {
-			log.Printf("Failed to interact with store: %v", err)
+			logger.Errorw("GetLogicalBridge(): Failed to interact with store", "error", err)
 			return nil, err
 		}
 		err = status.Errorf(codes.NotFound, "unable to find key %s", in.Name)
-		log.Printf("GetLogicalBridge(): LogicalBridge with id %v: Not Found %v", in.Name, err)
+		logger.Warnw("GetLogicalBridge(): Logical Bridge not found", logging.Name(in.Name), "error", err)
 		return nil, err
 	}
 
@@ -172,36 +197,29 @@ func (s *Server) GetLogicalBridge(_ context.Context, in *pb.GetLogicalBridgeRequ
 }
 
 // ListLogicalBridges lists logical bridges
-func (s *Server) ListLogicalBridges(_ context.Context, in *pb.ListLogicalBridgesRequest) (*pb.ListLogicalBridgesResponse, error) {
+func (s *Server) ListLogicalBridges(ctx context.Context, in *pb.ListLogicalBridgesRequest) (*pb.ListLogicalBridgesResponse, error) {
 	// check input correctness
 	if err := s.validateListLogicalBridgesRequest(in); err != nil {
-		log.Printf("ListLogicalBridges(): validation failure: %v", err)
-		return nil, err
-	}
-	// fetch pagination from the database, calculate size and offset
-	size, offset, err := utils.ExtractPagination(in.PageSize, in.PageToken, s.Pagination)
-	if err != nil {
+		logger.Errorw("ListLogicalBridges(): Validation failure", "error", err)
 		return nil, err
 	}
 	// fetch object from the database
 	Blobarray, err := s.getAllLogicalBridges()
 	if err != nil {
 		if err != infradb.ErrKeyNotFound {
-			log.Printf("Failed to interact with store: %v", err)
+			logger.Errorw("ListLogicalBridges(): Failed to interact with store", "error", err)
 			return nil, err
 		}
 		err := status.Errorf(codes.NotFound, "Error: %v", err)
-		log.Printf("ListLogicalBridges(): %v", err)
+		logger.Infow("ListLogicalBridges(): No Logical Bridges found", "error", err)
 		return nil, err
 	}
-	// sort is needed, since MAP is unsorted in golang, and we might get different results
-	sortLogicalBridges(Blobarray)
-	log.Printf("Limiting result len(%d) to [%d:%d]", len(Blobarray), offset, size)
-	Blobarray, hasMoreElements := utils.LimitPagination(Blobarray, offset, size)
-	token := ""
-	if hasMoreElements {
-		token = uuid.New().String()
-		s.Pagination[token] = offset + size
+	// filter, sort and paginate, since MAP is unsorted in golang, and we might get different results
+	filter, orderBy := utils.ExtractListOptions(ctx)
+	Blobarray, token, err := utils.ListPage(Blobarray, in.PageSize, in.PageToken, filter, orderBy)
+	if err != nil {
+		logger.Infow("ListLogicalBridges(): Invalid list options", "error", err)
+		return nil, err
 	}
 	return &pb.ListLogicalBridgesResponse{LogicalBridges: Blobarray, NextPageToken: token}, nil
 }
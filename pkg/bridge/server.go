@@ -11,19 +11,24 @@ import (
 	"go.opentelemetry.io/otel/trace"
 
 	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+
+	epb "github.com/opiproject/opi-evpn-bridge/api/opi_evpn_bridge/v1"
+	"github.com/opiproject/opi-evpn-bridge/pkg/logging"
 )
 
+// logger is the logger of the grpc subsystem
+var logger = logging.Logger(logging.Grpc).Sugar()
+
 // Server represents the Server object
 type Server struct {
 	pb.UnimplementedLogicalBridgeServiceServer
-	Pagination map[string]int
-	tracer     trace.Tracer
+	epb.UnimplementedLogicalBridgeBatchServiceServer
+	tracer trace.Tracer
 }
 
 // NewServer creates initialized instance of EVPN server
 func NewServer() *Server {
 	return &Server{
-		Pagination: make(map[string]int),
-		tracer:     otel.Tracer(""),
+		tracer: otel.Tracer(""),
 	}
 }
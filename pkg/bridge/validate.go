@@ -18,6 +18,7 @@ import (
 	"google.golang.org/grpc/status"
 
 	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
 )
 
 func (s *Server) validateCreateLogicalBridgeRequest(in *pb.CreateLogicalBridgeRequest) error {
@@ -48,7 +49,25 @@ func (s *Server) validateLogicalBridgeSpec(lb *pb.LogicalBridge) error {
 		return status.Errorf(codes.InvalidArgument, msg)
 	}
 
-	// Dimitris: Should I validate the vtep_ip_prefix ?
+	// Validate that the vtep IP is a valid IPv4 or IPv6 prefix
+	if lb.Spec.VtepIpPrefix != nil {
+		if _, err := common.ConvertToIPNet(lb.Spec.VtepIpPrefix); err != nil {
+			msg := fmt.Sprintf("Invalid VTEP IP prefix: %v", err)
+			return status.Errorf(codes.InvalidArgument, msg)
+		}
+	}
+	return nil
+}
+
+func (s *Server) validateLogicalBridgeImmutableFields(stored, updated *pb.LogicalBridge) error {
+	if stored.Spec.VlanId != updated.Spec.VlanId {
+		msg := fmt.Sprintf("VlanId of Logical Bridge %v cannot be changed", stored.Name)
+		return status.Errorf(codes.InvalidArgument, msg)
+	}
+	if (stored.Spec.Vni == nil) != (updated.Spec.Vni == nil) || stored.Spec.GetVni() != updated.Spec.GetVni() {
+		msg := fmt.Sprintf("Vni of Logical Bridge %v cannot be changed", stored.Name)
+		return status.Errorf(codes.InvalidArgument, msg)
+	}
 	return nil
 }
 
@@ -8,9 +8,9 @@ package config
 
 import (
 	"fmt"
-	"log"
 	"net"
 	"strconv"
+	"sync/atomic"
 
 	"github.com/spf13/viper"
 )
@@ -40,8 +40,8 @@ type P4Config struct {
 	Config  P4FilesConfig `yaml:"config"`
 }
 
-// loglevelConfig log level config structure
-type loglevelConfig struct {
+// LogLevelConfig log level config structure, the level of each logging subsystem
+type LogLevelConfig struct {
 	DB      string `yaml:"db"`
 	Grpc    string `yaml:"grpc"`
 	Linux   string `yaml:"linux"`
@@ -63,45 +63,119 @@ type InterfaceConfig struct {
 		Rep string `yaml:"rep"`
 		Vsi int    `yaml:"vsi"`
 	} `yaml:"phyports"`
-	Other    map[string]interface{} `yaml:",inline"`
-	GrpcAcc  string                 `yaml:"grpcacc"`
-	GrpcHost string                 `yaml:"grpchost"`
-	VrfMux   string                 `yaml:"vrfmux"`
-	PortMux  string                 `yaml:"port-mux"`
+	GrpcAcc  string `yaml:"grpcacc"`
+	GrpcHost string `yaml:"grpchost"`
+	VrfMux   string `yaml:"vrfmux"`
+	PortMux  string `yaml:"portmux"`
 }
 
 // NetlinkConfig netlink config structure
 type NetlinkConfig struct {
 	Enabled         bool `yaml:"enabled"`
 	PollInterval    int  `yaml:"pollinterval"`
+	ResyncInterval  int  `yaml:"resyncinterval"`
 	GrdDefaultRoute bool `yaml:"grddefaultroute"`
 	EnableEcmp      bool `yaml:"enableecmp"`
 }
 
+// DriftConfig drift detection config structure, the realized objects are compared
+// to the kernel every interval seconds and on each link change
+type DriftConfig struct {
+	Enabled   bool `yaml:"enabled"`
+	Interval  int  `yaml:"interval"`
+	Remediate bool `yaml:"remediate"`
+}
+
+// AuthClientConfig maps a client, identified by the subject alternative name
+// or the common name of its certificate or by a bearer token, to a role
+type AuthClientConfig struct {
+	Name  string `yaml:"name"`
+	Cert  string `yaml:"cert"`
+	Token string `yaml:"token"`
+	Role  string `yaml:"role"`
+}
+
+// AuthMethodConfig sets the role required by the gRPC methods matching the pattern
+type AuthMethodConfig struct {
+	Method string `yaml:"method"`
+	Role   string `yaml:"role"`
+}
+
+// AuthConfig authorization config structure
+type AuthConfig struct {
+	Enabled bool               `yaml:"enabled"`
+	Clients []AuthClientConfig `yaml:"clients"`
+	Methods []AuthMethodConfig `yaml:"methods"`
+}
+
+// AuditConfig audit trail config structure, the records are signed with the key
+// and the anchor file holds the range of the records kept
+type AuditConfig struct {
+	Enabled    bool   `yaml:"enabled"`
+	File       string `yaml:"file"`
+	Anchor     string `yaml:"anchor"`
+	Key        string `yaml:"key"`
+	MaxSize    int    `yaml:"maxsize"`
+	MaxBackups int    `yaml:"maxbackups"`
+}
+
+const (
+	// ShutdownCleanup deletes the realized objects and the state they have programmed on shutdown
+	ShutdownCleanup = "cleanup"
+	// ShutdownPreserve leaves the kernel and FRR state in place on shutdown, to be adopted on the next start
+	ShutdownPreserve = "preserve"
+)
+
 // Config global config structure
 type Config struct {
-	CfgFile     string
-	GRPCPort    uint16             `yaml:"grpcport"`
-	HTTPPort    uint16             `yaml:"httpport"`
-	TLSFiles    string             `yaml:"tlsfiles"`
-	Database    string             `yaml:"database"`
-	DBAddress   string             `yaml:"dbaddress"`
-	Buildenv    string             `yaml:"buildenv"`
-	Tracer      bool               `yaml:"tracer"`
-	Subscribers []SubscriberConfig `yaml:"subscribers"`
-	Interfaces  InterfaceConfig    `yaml:"interfaces"`
-	LinuxFrr    LinuxFrrConfig     `yaml:"linuxfrr"`
-	Netlink     NetlinkConfig      `yaml:"netlink"`
-	P4          P4Config           `yaml:"p4"`
-	LogLevel    loglevelConfig     `yaml:"loglevel"`
-}
-
-// GlobalConfig global config
+	CfgFile         string
+	GRPCPort        uint16             `yaml:"grpcport"`
+	HTTPPort        uint16             `yaml:"httpport"`
+	TLSFiles        string             `yaml:"tlsfiles"`
+	GatewayTLSFiles string             `yaml:"gatewaytlsfiles"`
+	Database        string             `yaml:"database"`
+	DBAddress       string             `yaml:"dbaddress"`
+	Buildenv        string             `yaml:"buildenv"`
+	Modules         []string           `yaml:"modules"`
+	Tracer          bool               `yaml:"tracer"`
+	PageTokenKey    string             `yaml:"pagetokenkey"`
+	ShutdownMode    string             `yaml:"shutdownmode"`
+	Subscribers     []SubscriberConfig `yaml:"subscribers"`
+	Interfaces      InterfaceConfig    `yaml:"interfaces"`
+	LinuxFrr        LinuxFrrConfig     `yaml:"linuxfrr"`
+	Netlink         NetlinkConfig      `yaml:"netlink"`
+	Drift           DriftConfig        `yaml:"drift"`
+	P4              P4Config           `yaml:"p4"`
+	LogLevel        LogLevelConfig     `yaml:"loglevel"`
+	Auth            AuthConfig         `yaml:"auth"`
+	Audit           AuditConfig        `yaml:"audit"`
+}
+
+// GlobalConfig global config, as loaded at startup.
+// The runtime changes of the reloads are only applied to the Current config.
 var GlobalConfig Config
 
+// current is the latest config, swapped as a whole on each reload
+var current atomic.Pointer[Config]
+
+// Current returns the latest config, with the runtime changes of the reloads applied.
+// The returned config is shared and must not be modified.
+func Current() *Config {
+	if cfg := current.Load(); cfg != nil {
+		return cfg
+	}
+	return &GlobalConfig
+}
+
+// setCurrent publishes a copy of the config as the current one
+func setCurrent(cfg Config) {
+	current.Store(&cfg)
+}
+
 // SetConfig sets the global config
 func SetConfig(cfg Config) error {
 	GlobalConfig = cfg
+	setCurrent(cfg)
 	return nil
 }
 
@@ -121,7 +195,7 @@ func Initcfg() {
 	}
 
 	if err := LoadConfig(); err != nil {
-		log.Panic(err)
+		logger.Panicw("Failed to load the config", "error", err)
 	}
 }
 
@@ -131,31 +205,62 @@ func LoadConfig() error {
 		// Handle errors
 		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
 			// Cfg file not found
-			fmt.Println("Config file not found in default paths. Using defaults.")
+			logger.Info("Config file not found in default paths. Using defaults.")
 		} else if _, ok := err.(viper.ConfigParseError); ok {
 			// Cfg file parsing error
-			log.Panicf("Error parsing config file: %v", err)
+			logger.Panicw("Failed to parse the config file", "error", err)
 		} else {
 			// Other errors
-			log.Panicf("Error reading config file: %v", err)
+			logger.Panicw("Failed to read the config file", "error", err)
 		}
 	}
 
-	fmt.Println("Using config file:", viper.ConfigFileUsed())
-
-	if err := viper.Unmarshal(&GlobalConfig); err != nil {
-		log.Println(err)
+	file := viper.ConfigFileUsed()
+	if err := viper.Unmarshal(&GlobalConfig, yamlTags); err != nil {
+		logger.Errorw("Failed to decode the config", "file", file, "error", err)
 		return err
 	}
 
 	if err := ValidateConfig(); err != nil {
-		log.Panic(err)
+		logger.Panicw("Invalid config", "file", file, "error", err)
 	}
 
-	log.Printf("config %+v", GlobalConfig)
+	if file != "" {
+		if err := validateSchema(file, &GlobalConfig); err != nil {
+			logger.Panicw("Invalid config", "file", file, "error", err)
+		}
+		// The interfaces may be created after the start, they are only checked by validate-config
+		for _, problem := range GlobalConfig.interfaceProblems() {
+			logger.Warnw("Config problem", "file", file, "problem", problem)
+		}
+	}
+
+	logger.Infow("Config has been loaded", "file", file, "config", GlobalConfig.Redacted())
+	setCurrent(GlobalConfig)
 	return nil
 }
 
+// redactedSecret replaces the secrets of the config when it is printed
+const redactedSecret = "REDACTED"
+
+// Redacted returns a copy of the config with the page token key, the audit key
+// and the tokens of the clients replaced, to be printed
+func (c Config) Redacted() Config {
+	if c.PageTokenKey != "" {
+		c.PageTokenKey = redactedSecret
+	}
+	if c.Audit.Key != "" {
+		c.Audit.Key = redactedSecret
+	}
+	c.Auth.Clients = append([]AuthClientConfig(nil), c.Auth.Clients...)
+	for i := range c.Auth.Clients {
+		if c.Auth.Clients[i].Token != "" {
+			c.Auth.Clients[i].Token = redactedSecret
+		}
+	}
+	return c
+}
+
 // GetConfig gets the global config
 func GetConfig() *Config {
 	return &GlobalConfig
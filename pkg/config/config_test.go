@@ -1,12 +1,28 @@
 package config
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/modules"
 )
 
+func init() {
+	modules.Register(modules.Module{Name: "lgm", BuildEnvs: []string{"ci"}, Events: []string{"vrf", "svi", "logical-bridge"}})
+	modules.Register(modules.Module{Name: "lci", BuildEnvs: []string{"ci"}, Events: []string{"bridge-port"}})
+	modules.Register(modules.Module{Name: "frr", BuildEnvs: []string{"ci"}, Events: []string{"vrf", "svi"}})
+}
+
 func TestInitcfg_WithDefaultPath(t *testing.T) {
 	// Ensure no custom config file is set
 	GlobalConfig.CfgFile = ""
@@ -36,6 +52,33 @@ func TestSetAndGetConfig(t *testing.T) {
 	assert.Equal(t, &cfg, GetConfig())
 }
 
+func TestRedacted(t *testing.T) {
+	cfg := Config{
+		GRPCPort:     50051,
+		PageTokenKey: "page-secret",
+		Audit:        AuditConfig{Enabled: true, Key: "audit-secret"},
+		Auth: AuthConfig{
+			Clients: []AuthClientConfig{
+				{Name: "admin", Token: "admin-secret", Role: "admin"},
+				{Name: "viewer", Cert: "viewer.opi.com", Role: "viewer"},
+			},
+		},
+	}
+
+	redacted := cfg.Redacted()
+	assert.Equal(t, uint16(50051), redacted.GRPCPort)
+	assert.Equal(t, redactedSecret, redacted.PageTokenKey)
+	assert.Equal(t, redactedSecret, redacted.Audit.Key)
+	assert.Equal(t, redactedSecret, redacted.Auth.Clients[0].Token)
+	assert.Equal(t, "", redacted.Auth.Clients[1].Token)
+	assert.Equal(t, "viewer.opi.com", redacted.Auth.Clients[1].Cert)
+	assert.NotContains(t, fmt.Sprintf("%+v", redacted), "secret")
+
+	// the config itself keeps its secrets
+	assert.Equal(t, "page-secret", cfg.PageTokenKey)
+	assert.Equal(t, "admin-secret", cfg.Auth.Clients[0].Token)
+}
+
 func setupViperConfig(values map[string]interface{}) {
 	viper.Reset()
 	for key, value := range values {
@@ -99,3 +142,226 @@ func TestValidateConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestDiff(t *testing.T) {
+	current := Config{
+		GRPCPort:    50051,
+		Subscribers: []SubscriberConfig{{Name: "lgm", Priority: 1, Events: []string{"vrf"}}, {Name: "frr", Priority: 2, Events: []string{"vrf"}}},
+		LinuxFrr:    LinuxFrrConfig{IPMtu: 1500, LocalAs: 65000},
+		LogLevel:    LogLevelConfig{DB: "info"},
+	}
+	tests := []struct {
+		name            string
+		change          func(cfg *Config)
+		applied         []string
+		restartRequired []string
+	}{
+		{
+			name:   "No change",
+			change: func(*Config) {},
+		},
+		{
+			name: "Runtime changes",
+			change: func(cfg *Config) {
+				cfg.LinuxFrr.IPMtu = 9000
+				cfg.LogLevel.DB = "debug"
+				cfg.ShutdownMode = ShutdownPreserve
+			},
+			applied: []string{"linuxfrr.ipmtu", "shutdownmode", "loglevel.db"},
+		},
+		{
+			name: "Subscriber priority",
+			change: func(cfg *Config) {
+				cfg.Subscribers = []SubscriberConfig{{Name: "frr", Priority: 1, Events: []string{"vrf"}}, {Name: "lgm", Priority: 2, Events: []string{"vrf"}}}
+			},
+			applied: []string{"subscribers.frr.priority", "subscribers.lgm.priority"},
+		},
+		{
+			name: "Subscriber events",
+			change: func(cfg *Config) {
+				cfg.Subscribers = []SubscriberConfig{{Name: "lgm", Priority: 3, Events: []string{"vrf", "svi"}}, {Name: "frr", Priority: 2, Events: []string{"vrf"}}}
+			},
+			restartRequired: []string{"subscribers"},
+		},
+		{
+			name:            "Restart required",
+			change:          func(cfg *Config) { cfg.GRPCPort = 50052; cfg.LinuxFrr.LocalAs = 65001; cfg.LinuxFrr.IPMtu = 9000 },
+			applied:         []string{"linuxfrr.ipmtu"},
+			restartRequired: []string{"grpcport", "linuxfrr.localas"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := current
+			next.Subscribers = append([]SubscriberConfig{}, current.Subscribers...)
+			tt.change(&next)
+			result := Diff(&current, &next)
+			assert.Equal(t, tt.applied, result.Applied)
+			assert.Equal(t, tt.restartRequired, result.RestartRequired)
+		})
+	}
+}
+
+func TestReload(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	write := func(content string) {
+		assert.NoError(t, os.WriteFile(file, []byte(content), 0600))
+	}
+	const base = "grpcport: 50151\nhttpport: 8082\ndbaddress: 127.0.0.1:6379\nbuildenv: ci\nlinuxfrr:\n  ipmtu: %d\n  localas: %d\n"
+	viper.Reset()
+	viper.SetConfigFile(file)
+	write(fmt.Sprintf(base, 1500, 65000))
+	assert.NoError(t, LoadConfig())
+
+	var applied *Config
+	reloadHandlers = nil
+	OnReload(func(cfg *Config) { applied = cfg })
+
+	write(fmt.Sprintf(base, 9000, 65001))
+	reply, err := NewServer().ReloadConfig(context.Background(), &emptypb.Empty{})
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"linuxfrr.ipmtu"}, reply.AsMap()["applied"])
+	assert.Equal(t, []interface{}{"linuxfrr.localas"}, reply.AsMap()["restartRequired"])
+	assert.Equal(t, Current(), applied)
+	assert.Equal(t, 9000, Current().LinuxFrr.IPMtu)
+	assert.Equal(t, 65000, Current().LinuxFrr.LocalAs)
+	// The startup config is left untouched
+	assert.Equal(t, 1500, GlobalConfig.LinuxFrr.IPMtu)
+
+	write("grpcport: 50151\nhttpport: 8082\ndbaddress: localhost\n")
+	_, err = NewServer().ReloadConfig(context.Background(), &emptypb.Empty{})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	assert.Equal(t, 9000, Current().LinuxFrr.IPMtu)
+}
+
+func TestValidateFile(t *testing.T) {
+	const valid = `grpcport: 50151
+httpport: 8082
+dbaddress: 127.0.0.1:6379
+buildenv: ci
+subscribers:
+ - name: "lgm"
+   priority: 1
+   events: ["vrf", "svi", "logical-bridge"]
+ - name: "frr"
+   priority: 2
+   events: ["vrf", "svi"]
+interfaces:
+    vrfmux: "vrfmux0"
+linuxfrr:
+    enabled: true
+    defaultvtep: "vxlan-vtep"
+    ipmtu: 1500
+    localas: 65000
+`
+	tests := []struct {
+		name     string
+		replace  []string
+		problems []string
+	}{
+		{
+			name: "Valid Config",
+		},
+		{
+			name:     "Unknown Key",
+			replace:  []string{"vrfmux:", "vrf-mux:"},
+			problems: []string{`line 13: unknown key "interfaces.vrf-mux"`},
+		},
+		{
+			name:     "Unknown Subscriber",
+			replace:  []string{`"frr"`, `"lvm"`},
+			problems: []string{`subscriber "lvm" is not an enabled module, expected one of [frr lci lgm]`},
+		},
+		{
+			name:     "Subscriber Of A Disabled Module",
+			replace:  []string{"buildenv: ci", "buildenv: ci\nmodules: [lgm, lci]"},
+			problems: []string{`subscriber "frr" is not an enabled module, expected one of [lci lgm]`},
+		},
+		{
+			name:     "Unknown Build Env",
+			replace:  []string{"buildenv: ci", "buildenv: e2000"},
+			problems: []string{`buildenv "e2000" is not supported, expected one of [ci]`},
+		},
+		{
+			name:     "Unknown Module",
+			replace:  []string{"buildenv: ci", "buildenv: ci\nmodules: [lgm, lvm]"},
+			problems: []string{`modules: module "lvm" is not registered`},
+		},
+		{
+			name:     "Unknown Event Type",
+			replace:  []string{`["vrf", "svi"]`, `["vrf", "bridge-port"]`},
+			problems: []string{`subscriber "frr" does not handle event "bridge-port", expected one of [vrf svi]`},
+		},
+		{
+			name:    "Duplicate Priority",
+			replace: []string{"priority: 2", "priority: 1"},
+			problems: []string{
+				`subscribers "lgm" and "frr" have the same priority 1 for event "vrf"`,
+				`subscribers "lgm" and "frr" have the same priority 1 for event "svi"`,
+			},
+		},
+		{
+			name:     "Invalid MTU",
+			replace:  []string{"ipmtu: 1500", "ipmtu: 10000"},
+			problems: []string{"linuxfrr.ipmtu 10000 must be between 1280 and 9216"},
+		},
+		{
+			name:     "Invalid AS",
+			replace:  []string{"localas: 65000", "localas: 0"},
+			problems: []string{"linuxfrr.localas 0 must be between 1 and 4294967294"},
+		},
+		{
+			name:     "Invalid Poll Interval",
+			replace:  []string{"buildenv: ci", "buildenv: ci\nnetlink:\n    enabled: true"},
+			problems: []string{"netlink.pollinterval 0 must be at least 1 second"},
+		},
+		{
+			name:     "Invalid Resync Interval",
+			replace:  []string{"buildenv: ci", "buildenv: ci\nnetlink:\n    enabled: true\n    pollinterval: 1\n    resyncinterval: -1"},
+			problems: []string{"netlink.resyncinterval -1 must not be negative"},
+		},
+		{
+			name:     "Invalid Drift Interval",
+			replace:  []string{"buildenv: ci", "buildenv: ci\ndrift:\n    enabled: true"},
+			problems: []string{"drift.interval 0 must be at least 1 second"},
+		},
+		{
+			name:     "Audit Without Key",
+			replace:  []string{"buildenv: ci", "buildenv: ci\naudit:\n    enabled: true"},
+			problems: []string{"audit.key is required when audit is enabled"},
+		},
+		{
+			name:     "Invalid Shutdown Mode",
+			replace:  []string{"buildenv: ci", "buildenv: ci\nshutdownmode: keep"},
+			problems: []string{`shutdownmode "keep" is not valid, expected one of [cleanup preserve]`},
+		},
+		{
+			name:     "Nonexistent Interface",
+			replace:  []string{`"vrfmux0"`, `"enp0s1f0d4"`},
+			problems: []string{`interfaces.vrfmux: interface "enp0s1f0d4" does not exist`},
+		},
+	}
+
+	defer func(exists func(string) bool) { linkExists = exists }(linkExists)
+	linkExists = func(name string) bool { return name == "vrfmux0" || name == "vxlan-vtep" }
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := valid
+			if len(tt.replace) != 0 {
+				content = strings.Replace(content, tt.replace[0], tt.replace[1], 1)
+			}
+			file := filepath.Join(t.TempDir(), "config.yaml")
+			assert.NoError(t, os.WriteFile(file, []byte(content), 0600))
+
+			err := ValidateFile(file)
+			if tt.problems == nil {
+				assert.NoError(t, err)
+				return
+			}
+			var validationErr *ValidationError
+			assert.ErrorAs(t, err, &validationErr)
+			assert.Equal(t, tt.problems, validationErr.Problems)
+		})
+	}
+}
@@ -0,0 +1,203 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package config introduces the configuration from file or runtime param
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/logging"
+)
+
+// logger is the logger of the grpc subsystem, which loads and serves the config and its reload
+var logger = logging.Logger(logging.Grpc).Sugar()
+
+// ReloadResult reports the outcome of a reload of the config file
+type ReloadResult struct {
+	// Applied lists the keys whose change has been applied at runtime
+	Applied []string
+	// RestartRequired lists the keys whose change is ignored until the next restart
+	RestartRequired []string
+}
+
+// ReloadHandler applies the runtime changes of the config to a module
+type ReloadHandler func(cfg *Config)
+
+// runtimeKey is a config key that can be changed without restart
+type runtimeKey struct {
+	key string
+	// apply copies the value of the key from src to dst
+	apply func(dst, src *Config)
+}
+
+// runtimeKeys lists the keys that are safe to change at runtime,
+// a change of any other key is only reported.
+// The FRR local AS is not part of them as the BGP instances of the
+// realized VRFs would keep the old one and could not be deleted anymore.
+var runtimeKeys = []runtimeKey{
+	{"linuxfrr.ipmtu", func(dst, src *Config) { dst.LinuxFrr.IPMtu = src.LinuxFrr.IPMtu }},
+	{"netlink.pollinterval", func(dst, src *Config) { dst.Netlink.PollInterval = src.Netlink.PollInterval }},
+	{"drift.remediate", func(dst, src *Config) { dst.Drift.Remediate = src.Drift.Remediate }},
+	{"shutdownmode", func(dst, src *Config) { dst.ShutdownMode = src.ShutdownMode }},
+	{"loglevel.db", func(dst, src *Config) { dst.LogLevel.DB = src.LogLevel.DB }},
+	{"loglevel.grpc", func(dst, src *Config) { dst.LogLevel.Grpc = src.LogLevel.Grpc }},
+	{"loglevel.linux", func(dst, src *Config) { dst.LogLevel.Linux = src.LogLevel.Linux }},
+	{"loglevel.netlink", func(dst, src *Config) { dst.LogLevel.Netlink = src.LogLevel.Netlink }},
+	{"loglevel.p4", func(dst, src *Config) { dst.LogLevel.P4 = src.LogLevel.P4 }},
+}
+
+var (
+	reloadMtx      sync.Mutex
+	reloadHandlers []ReloadHandler
+)
+
+// OnReload registers a handler called with the new config after each reload
+// that has applied changes at runtime.
+// The handlers are meant to be registered once, by the init function of their package.
+func OnReload(handler ReloadHandler) {
+	reloadMtx.Lock()
+	defer reloadMtx.Unlock()
+	reloadHandlers = append(reloadHandlers, handler)
+}
+
+// Reload reads the config file again, validates it and applies the changes that are safe at runtime.
+// The changes are applied to a copy of the current config which then replaces it, see Current.
+// The current config is left untouched when the file cannot be read or is invalid.
+func Reload() (*ReloadResult, error) {
+	reloadMtx.Lock()
+	defer reloadMtx.Unlock()
+
+	if err := viper.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	if err := ValidateConfig(); err != nil {
+		return nil, err
+	}
+	next := Config{}
+	if err := viper.Unmarshal(&next, yamlTags); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	if err := validateSchema(viper.ConfigFileUsed(), &next); err != nil {
+		return nil, err
+	}
+	cfg := *Current()
+	next.CfgFile = cfg.CfgFile
+
+	result := Diff(&cfg, &next)
+	if len(result.Applied) != 0 {
+		for _, k := range runtimeKeys {
+			k.apply(&cfg, &next)
+		}
+		applySubscriberPriorities(&cfg, &next)
+		setCurrent(cfg)
+		for _, handler := range reloadHandlers {
+			handler(Current())
+		}
+	}
+	logger.Infow("Config has been reloaded", "applied", result.Applied, "restartRequired", result.RestartRequired)
+	return result, nil
+}
+
+// Watch reloads the config each time the config file changes
+func Watch() {
+	viper.OnConfigChange(func(e fsnotify.Event) {
+		logger.Infow("Config file has changed", "file", e.Name)
+		if _, err := Reload(); err != nil {
+			logger.Errorw("Failed to reload the config", "error", err)
+		}
+	})
+	viper.WatchConfig()
+}
+
+// Diff returns the keys that differ between the current and the next config,
+// split between the ones that can be applied at runtime and the ones that require a restart
+func Diff(current, next *Config) *ReloadResult {
+	result := &ReloadResult{}
+	// Once the runtime keys are aligned any remaining difference requires a restart
+	aligned := *next
+	for _, k := range runtimeKeys {
+		changed := *current
+		k.apply(&changed, next)
+		if !reflect.DeepEqual(changed, *current) {
+			result.Applied = append(result.Applied, k.key)
+		}
+		k.apply(&aligned, current)
+	}
+	priorities, sameSubscribers := subscriberPriorityChanges(current.Subscribers, next.Subscribers)
+	result.Applied = append(result.Applied, priorities...)
+	if sameSubscribers {
+		aligned.Subscribers = current.Subscribers
+	}
+	result.RestartRequired = changedKeys(reflect.ValueOf(*current), reflect.ValueOf(aligned), "")
+	return result
+}
+
+// subscriberPriorityChanges returns the keys of the subscribers whose priority has changed
+// and whether the subscribers are the same otherwise, with the same events
+func subscriberPriorityChanges(current, next []SubscriberConfig) ([]string, bool) {
+	if len(current) != len(next) {
+		return nil, false
+	}
+	byName := make(map[string]SubscriberConfig, len(current))
+	for _, s := range current {
+		byName[s.Name] = s
+	}
+	var keys []string
+	for _, s := range next {
+		c, ok := byName[s.Name]
+		if !ok || !reflect.DeepEqual(c.Events, s.Events) {
+			return nil, false
+		}
+		if c.Priority != s.Priority {
+			keys = append(keys, fmt.Sprintf("subscribers.%s.priority", s.Name))
+		}
+	}
+	sort.Strings(keys)
+	return keys, true
+}
+
+// applySubscriberPriorities copies the priorities of the subscribers when only they have changed
+func applySubscriberPriorities(dst, src *Config) {
+	if _, same := subscriberPriorityChanges(dst.Subscribers, src.Subscribers); !same {
+		return
+	}
+	priorities := make(map[string]int, len(src.Subscribers))
+	for _, s := range src.Subscribers {
+		priorities[s.Name] = s.Priority
+	}
+	subscribers := make([]SubscriberConfig, len(dst.Subscribers))
+	for i, s := range dst.Subscribers {
+		s.Priority = priorities[s.Name]
+		subscribers[i] = s
+	}
+	dst.Subscribers = subscribers
+}
+
+// changedKeys walks the sections of the config and returns the yaml keys whose value differs
+func changedKeys(a, b reflect.Value, prefix string) []string {
+	var keys []string
+	for i := 0; i < a.NumField(); i++ {
+		field := a.Type().Field(i)
+		name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if name == "" {
+			continue
+		}
+		if reflect.DeepEqual(a.Field(i).Interface(), b.Field(i).Interface()) {
+			continue
+		}
+		if field.Type.Kind() == reflect.Struct && prefix == "" {
+			keys = append(keys, changedKeys(a.Field(i), b.Field(i), name+".")...)
+			continue
+		}
+		keys = append(keys, prefix+name)
+	}
+	return keys
+}
@@ -0,0 +1,92 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package config introduces the configuration from file or runtime param
+package config
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// ServiceName is the name of the config admin service
+const ServiceName = "opi_evpn_bridge.v1.ConfigService"
+
+// AdminServer is the server API for the ConfigService
+type AdminServer interface {
+	ReloadConfig(context.Context, *emptypb.Empty) (*structpb.Struct, error)
+}
+
+// AdminServiceDesc describes the ConfigService.
+// There are no generated messages for the reload result so it is carried
+// as google.protobuf.Struct with the applied and restartRequired lists of keys.
+var AdminServiceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*AdminServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ReloadConfig",
+			Handler:    reloadConfigHandler,
+		},
+	},
+	Streams: []grpc.StreamDesc{},
+}
+
+// RegisterAdminServer registers the ConfigService to the gRPC server
+func RegisterAdminServer(s grpc.ServiceRegistrar, srv AdminServer) {
+	s.RegisterService(&AdminServiceDesc, srv)
+}
+
+func reloadConfigHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).ReloadConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/" + ServiceName + "/ReloadConfig",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).ReloadConfig(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Server implements the ConfigService
+type Server struct {
+	reload func() (*ReloadResult, error)
+}
+
+// NewServer creates the config admin server that reloads the config file
+func NewServer() *Server {
+	return &Server{reload: Reload}
+}
+
+// ReloadConfig reloads the config file and reports the changes that have been applied
+// and the ones that require a restart
+func (s *Server) ReloadConfig(_ context.Context, _ *emptypb.Empty) (*structpb.Struct, error) {
+	result, err := s.reload()
+	if err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "%v", err)
+	}
+	return structpb.NewStruct(map[string]interface{}{
+		"applied":         keyList(result.Applied),
+		"restartRequired": keyList(result.RestartRequired),
+	})
+}
+
+func keyList(keys []string) []interface{} {
+	list := make([]interface{}, len(keys))
+	for i, key := range keys {
+		list[i] = key
+	}
+	return list
+}
@@ -0,0 +1,262 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package config introduces the configuration from file or runtime param
+package config
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/modules"
+)
+
+// ValidationError lists all the problems found in the config
+type ValidationError struct {
+	Problems []string
+}
+
+// Error returns the problems found in the config
+func (e *ValidationError) Error() string {
+	return "invalid config: " + strings.Join(e.Problems, "; ")
+}
+
+const (
+	minMtu         = 1280
+	maxMtu         = 9216
+	maxAs          = 4294967294
+	minPollSeconds = 1
+)
+
+var logLevels = []string{"debug", "info", "warn", "error"}
+
+var shutdownModes = []string{ShutdownCleanup, ShutdownPreserve}
+
+// yamlTags makes viper decode the config with the keys of the yaml tags, the same as the schema
+func yamlTags(c *mapstructure.DecoderConfig) {
+	c.TagName = "yaml"
+}
+
+// linkExists reports whether the network interface exists on the host
+var linkExists = func(name string) bool {
+	_, err := net.InterfaceByName(name)
+	return err == nil
+}
+
+// ValidateFile checks the config file against the schema and the values it holds,
+// including the existence of the interfaces it refers to, and returns all the problems found
+func ValidateFile(file string) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	v := viper.New()
+	v.SetConfigFile(file)
+	if err := v.ReadInConfig(); err != nil {
+		return err
+	}
+	cfg := Config{}
+	if err := v.Unmarshal(&cfg, yamlTags); err != nil {
+		return &ValidationError{Problems: []string{err.Error()}}
+	}
+	problems := append(schemaProblems(data), cfg.problems()...)
+	problems = append(problems, cfg.interfaceProblems()...)
+	if len(problems) != 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// validateSchema checks the config file against the schema and the values the config holds
+func validateSchema(file string, cfg *Config) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	problems := append(schemaProblems(data), cfg.problems()...)
+	if len(problems) != 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// schemaProblems returns the keys of the config file that are not part of the Config structure
+func schemaProblems(data []byte) []string {
+	root := yaml.Node{}
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return []string{err.Error()}
+	}
+	if len(root.Content) == 0 {
+		return nil
+	}
+	return unknownKeys(root.Content[0], reflect.TypeOf(Config{}), "")
+}
+
+// unknownKeys walks the yaml node along the type and returns the keys that have no matching field
+func unknownKeys(node *yaml.Node, t reflect.Type, prefix string) []string {
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+		fields := make(map[string]reflect.Type, t.NumField())
+		for i := 0; i < t.NumField(); i++ {
+			if name := strings.Split(t.Field(i).Tag.Get("yaml"), ",")[0]; name != "" {
+				fields[name] = t.Field(i).Type
+			}
+		}
+		var problems []string
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			fieldType, ok := fields[key]
+			if !ok {
+				problems = append(problems, fmt.Sprintf("line %d: unknown key %q", node.Content[i].Line, prefix+key))
+				continue
+			}
+			problems = append(problems, unknownKeys(node.Content[i+1], fieldType, prefix+key+".")...)
+		}
+		return problems
+	case reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			return nil
+		}
+		var problems []string
+		for i, item := range node.Content {
+			problems = append(problems, unknownKeys(item, t.Elem(), fmt.Sprintf("%s%d.", prefix, i))...)
+		}
+		return problems
+	default:
+		return nil
+	}
+}
+
+// problems returns the values of the config that are out of range or inconsistent
+func (c *Config) problems() []string {
+	var problems []string
+	// The subscribers are only checked once the enabled modules are known
+	if buildEnvs := modules.BuildEnvs(); !slices.Contains(buildEnvs, c.Buildenv) {
+		problems = append(problems, fmt.Sprintf("buildenv %q is not supported, expected one of %v", c.Buildenv, buildEnvs))
+	} else if enabled, err := modules.Enabled(c.Buildenv, c.Modules); err != nil {
+		problems = append(problems, fmt.Sprintf("modules: %v", err))
+	} else {
+		// subscriberEvents maps the enabled modules to the event types they handle
+		subscriberEvents := make(map[string][]string, len(enabled))
+		for _, m := range enabled {
+			subscriberEvents[m.Name] = m.Events
+		}
+		problems = append(problems, subscriberProblems(c.Subscribers, subscriberEvents)...)
+	}
+
+	if c.ShutdownMode != "" && !slices.Contains(shutdownModes, c.ShutdownMode) {
+		problems = append(problems, fmt.Sprintf("shutdownmode %q is not valid, expected one of %v", c.ShutdownMode, shutdownModes))
+	}
+	if c.LinuxFrr.IPMtu < minMtu || c.LinuxFrr.IPMtu > maxMtu {
+		problems = append(problems, fmt.Sprintf("linuxfrr.ipmtu %d must be between %d and %d", c.LinuxFrr.IPMtu, minMtu, maxMtu))
+	}
+	if c.LinuxFrr.Enabled {
+		if c.LinuxFrr.LocalAs < 1 || c.LinuxFrr.LocalAs > maxAs {
+			problems = append(problems, fmt.Sprintf("linuxfrr.localas %d must be between 1 and %d", c.LinuxFrr.LocalAs, maxAs))
+		}
+		if c.LinuxFrr.DefaultVtep == "" {
+			problems = append(problems, "linuxfrr.defaultvtep is required when linuxfrr is enabled")
+		}
+	}
+	if c.Netlink.Enabled && c.Netlink.PollInterval < minPollSeconds {
+		problems = append(problems, fmt.Sprintf("netlink.pollinterval %d must be at least %d second", c.Netlink.PollInterval, minPollSeconds))
+	}
+	if c.Netlink.ResyncInterval < 0 {
+		problems = append(problems, fmt.Sprintf("netlink.resyncinterval %d must not be negative", c.Netlink.ResyncInterval))
+	}
+	if c.Drift.Enabled && c.Drift.Interval < minPollSeconds {
+		problems = append(problems, fmt.Sprintf("drift.interval %d must be at least %d second", c.Drift.Interval, minPollSeconds))
+	}
+	if c.Audit.Enabled && c.Audit.Key == "" {
+		problems = append(problems, "audit.key is required when audit is enabled")
+	}
+
+	levels := map[string]string{
+		"db": c.LogLevel.DB, "grpc": c.LogLevel.Grpc, "linux": c.LogLevel.Linux,
+		"netlink": c.LogLevel.Netlink, "p4": c.LogLevel.P4,
+	}
+	for _, subsystem := range sortedKeys(levels) {
+		if level := levels[subsystem]; level != "" && !slices.Contains(logLevels, level) {
+			problems = append(problems, fmt.Sprintf("loglevel.%s %q is not valid, expected one of %v", subsystem, level, logLevels))
+		}
+	}
+	return problems
+}
+
+// subscriberProblems checks the subscribers are enabled modules, their event types and priorities
+func subscriberProblems(subscribers []SubscriberConfig, subscriberEvents map[string][]string) []string {
+	var problems []string
+	names := make(map[string]bool, len(subscribers))
+	// priorities maps each event type to the subscriber of each priority
+	priorities := make(map[string]map[int]string)
+	for _, s := range subscribers {
+		events, ok := subscriberEvents[s.Name]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("subscriber %q is not an enabled module, expected one of %v", s.Name, sortedKeys(subscriberEvents)))
+			continue
+		}
+		if names[s.Name] {
+			problems = append(problems, fmt.Sprintf("subscriber %q is configured more than once", s.Name))
+			continue
+		}
+		names[s.Name] = true
+		if s.Priority < 1 {
+			problems = append(problems, fmt.Sprintf("subscriber %q priority %d must be positive", s.Name, s.Priority))
+		}
+		for _, event := range s.Events {
+			if !slices.Contains(events, event) {
+				problems = append(problems, fmt.Sprintf("subscriber %q does not handle event %q, expected one of %v", s.Name, event, events))
+				continue
+			}
+			if priorities[event] == nil {
+				priorities[event] = make(map[int]string)
+			}
+			if other, ok := priorities[event][s.Priority]; ok {
+				problems = append(problems, fmt.Sprintf("subscribers %q and %q have the same priority %d for event %q", other, s.Name, s.Priority, event))
+				continue
+			}
+			priorities[event][s.Priority] = s.Name
+		}
+	}
+	return problems
+}
+
+// interfaceProblems returns the interfaces the config refers to that do not exist on the host
+func (c *Config) interfaceProblems() []string {
+	var problems []string
+	check := func(key, name string) {
+		if name != "" && !linkExists(name) {
+			problems = append(problems, fmt.Sprintf("%s: interface %q does not exist", key, name))
+		}
+	}
+	for i, port := range c.Interfaces.PhyPorts {
+		check(fmt.Sprintf("interfaces.phyports.%d.rep", i), port.Rep)
+	}
+	check("interfaces.vrfmux", c.Interfaces.VrfMux)
+	check("interfaces.portmux", c.Interfaces.PortMux)
+	if c.LinuxFrr.Enabled {
+		check("linuxfrr.defaultvtep", c.LinuxFrr.DefaultVtep)
+	}
+	return problems
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -10,7 +10,6 @@ import (
 	"encoding/json"
 	"fmt"
 
-	"log"
 	"os"
 	"os/exec"
 	"os/user"
@@ -24,9 +23,14 @@ import (
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/actionbus"
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+	"github.com/opiproject/opi-evpn-bridge/pkg/logging"
+	"github.com/opiproject/opi-evpn-bridge/pkg/modules"
 	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
 )
 
+// logger is the logger of the linux subsystem
+var logger = logging.Logger(logging.Linux).Sugar()
+
 // frrComp string constant
 const frrComp string = "frr"
 
@@ -99,13 +103,13 @@ func NewModuleFrrActionHandlerWithArgs(runningFrrConfFile, basicFrrConfFile, bac
 func (h *moduleFrrHandler) HandleEvent(eventType string, objectData *eventbus.ObjectData) {
 	switch eventType {
 	case "vrf": // "VRF_added":
-		log.Printf("FRR recevied %s %s\n", eventType, objectData.Name)
+		logger.Infow("FRR received event", logging.Kind(eventType), logging.Name(objectData.Name), logging.ResourceVersion(objectData.ResourceVersion), logging.NotificationID(objectData.NotificationID))
 		handlevrf(objectData)
 	case "svi":
-		log.Printf("FRR recevied %s %s\n", eventType, objectData.Name)
+		logger.Infow("FRR received event", logging.Kind(eventType), logging.Name(objectData.Name), logging.ResourceVersion(objectData.ResourceVersion), logging.NotificationID(objectData.NotificationID))
 		handlesvi(objectData)
 	default:
-		log.Printf("error: Unknown event type %s", eventType)
+		logger.Errorw("FRR: Unknown event type", "eventType", eventType)
 	}
 }
 
@@ -113,10 +117,10 @@ func (h *moduleFrrHandler) HandleEvent(eventType string, objectData *eventbus.Ob
 func (h *ModuleFrrActionHandler) HandleAction(actionType string, actionData *actionbus.ActionData) {
 	switch actionType {
 	case "preReplay":
-		log.Printf("Module FRR received %s\n", actionType)
+		logger.Infow("FRR: Module received action", "actionType", actionType)
 		h.handlePreReplay(actionData)
 	default:
-		log.Printf("error: Unknown action type %s", actionType)
+		logger.Errorw("FRR: Unknown action type", "actionType", actionType)
 	}
 }
 
@@ -132,57 +136,57 @@ func (h *ModuleFrrActionHandler) handlePreReplay(actionData *actionbus.ActionDat
 	// Backup the current running config
 	deferErr = os.Rename(h.runningFrrConfFile, h.backupFrrConfFile)
 	if deferErr != nil {
-		log.Printf("FRR: handlePreReplay(): Failed to backup running config of FRR: %s\n", deferErr)
+		logger.Errorw("FRR: handlePreReplay(): Failed to backup running config of FRR", "error", deferErr)
 		return
 	}
 
 	// Create a new running config based on the basic/initial FRR config
 	input, deferErr := os.ReadFile(h.basicFrrConfFile)
 	if deferErr != nil {
-		log.Printf("FRR: handlePreReplay(): Failed to read content of %s: %s\n", h.basicFrrConfFile, deferErr)
+		logger.Errorw("FRR: handlePreReplay(): Failed to read content", "file", h.basicFrrConfFile, "error", deferErr)
 		return
 	}
 
 	deferErr = os.WriteFile(h.runningFrrConfFile, input, 0600)
 	if deferErr != nil {
-		log.Printf("FRR: handlePreReplay(): Failed to write content to %s: %s\n", h.runningFrrConfFile, deferErr)
+		logger.Errorw("FRR: handlePreReplay(): Failed to write content", "file", h.runningFrrConfFile, "error", deferErr)
 		return
 	}
 
 	// Change ownership of the frr.conf to frr:frr
 	group, deferErr := user.Lookup("frr")
 	if deferErr != nil {
-		log.Printf("FRR: handlePreReplay(): Failed to lookup user frr %s\n", deferErr)
+		logger.Errorw("FRR: handlePreReplay(): Failed to lookup user frr", "error", deferErr)
 		return
 	}
 
 	uid, deferErr := strconv.Atoi(group.Uid)
 	if deferErr != nil {
-		log.Printf("FRR: handlePreReplay(): Failed to convert frr user string in linux to int %s\n", deferErr)
+		logger.Errorw("FRR: handlePreReplay(): Failed to convert frr user string in linux to int", "error", deferErr)
 		return
 	}
 
 	gid, deferErr := strconv.Atoi(group.Gid)
 	if deferErr != nil {
-		log.Printf("FRR: handlePreReplay(): Failed to convert frr group string in linux to int %s\n", deferErr)
+		logger.Errorw("FRR: handlePreReplay(): Failed to convert frr group string in linux to int", "error", deferErr)
 		return
 	}
 
 	deferErr = os.Chown(h.runningFrrConfFile, uid, gid)
 	if deferErr != nil {
-		log.Printf("FRR: handlePreReplay(): Failed to chown of %s to frr:frr : %s\n", h.runningFrrConfFile, deferErr)
+		logger.Errorw("FRR: handlePreReplay(): Failed to chown to frr:frr", "file", h.runningFrrConfFile, "error", deferErr)
 		return
 	}
 
 	// Restart FRR daemon
 	_, errCmd := utils.Run([]string{"systemctl", "restart", "frr"}, false)
 	if errCmd != 0 {
-		log.Println("FRR: handlePreReplay(): Failed to restart FRR daemon")
+		logger.Error("FRR: handlePreReplay(): Failed to restart FRR daemon")
 		deferErr = fmt.Errorf("restart FRR daemon failed")
 		return
 	}
 
-	log.Println("FRR: handlePreReplay(): The pre-replay procedure has executed successfully")
+	logger.Info("FRR: handlePreReplay(): The pre-replay procedure has executed successfully")
 }
 
 // handlesvi handles the svi functionality
@@ -192,7 +196,7 @@ func handlesvi(objectData *eventbus.ObjectData) {
 	var comp common.Component
 	svi, err := infradb.GetSvi(objectData.Name)
 	if err != nil {
-		log.Printf("GetSvi error: %s %s\n", err, objectData.Name)
+		logger.Errorw("FRR: GetSvi error", logging.Name(objectData.Name), "error", err)
 		comp.Name = frrComp
 		comp.CompStatus = common.ComponentStatusError
 		comp.Details = fmt.Sprintf("GetSvi error: %s %s\n", err, objectData.Name)
@@ -203,13 +207,13 @@ func handlesvi(objectData *eventbus.ObjectData) {
 		}
 		err := infradb.UpdateSviStatus(objectData.Name, objectData.ResourceVersion, objectData.NotificationID, nil, comp)
 		if err != nil {
-			log.Printf("error in updating svi status: %s\n", err)
+			logger.Errorw("error in updating svi status", "error", err)
 		}
 		return
 	}
 
 	if objectData.ResourceVersion != svi.ResourceVersion {
-		log.Printf("FRR: Mismatch in resoruce version %+v\n and svi resource version %+v\n", objectData.ResourceVersion, svi.ResourceVersion)
+		logger.Debugw("FRR: Mismatch in resource version", logging.ResourceVersion(objectData.ResourceVersion), "sviResourceVersion", svi.ResourceVersion)
 		comp.Name = frrComp
 		comp.CompStatus = common.ComponentStatusError
 		comp.Details = fmt.Sprintf("FRR: Mismatch in resoruce version %+v\n and svi resource version %+v\n", objectData.ResourceVersion, svi.ResourceVersion)
@@ -220,7 +224,7 @@ func handlesvi(objectData *eventbus.ObjectData) {
 		}
 		err := infradb.UpdateSviStatus(objectData.Name, objectData.ResourceVersion, objectData.NotificationID, nil, comp)
 		if err != nil {
-			log.Printf("error in updating svi status: %s\n", err)
+			logger.Errorw("error in updating svi status", "error", err)
 		}
 		return
 	}
@@ -232,7 +236,13 @@ func handlesvi(objectData *eventbus.ObjectData) {
 		}
 	}
 	if svi.Status.SviOperStatus != infradb.SviOperStatusToBeDeleted {
-		details, status := setUpSvi(svi)
+		var details string
+		var status bool
+		if changes := svi.Changes(); changes != nil {
+			details, status = updateSvi(svi, changes)
+		} else {
+			details, status = setUpSvi(svi)
+		}
 		comp.Name = frrComp
 		comp.Details = details
 		if status {
@@ -246,14 +256,14 @@ func handlesvi(objectData *eventbus.ObjectData) {
 			}
 			comp.CompStatus = common.ComponentStatusError
 		}
-		log.Printf("%+v\n", comp)
+		logger.Debugw("FRR: component status", logging.Component(comp.Name), "status", comp.CompStatus, "details", comp.Details)
 
 		// Checking the timer to decide if we need to replay or not
 		comp.CheckReplayThreshold(replayThreshold)
 
 		err := infradb.UpdateSviStatus(objectData.Name, objectData.ResourceVersion, objectData.NotificationID, nil, comp)
 		if err != nil {
-			log.Printf("error in updating svi status: %s\n", err)
+			logger.Errorw("error in updating svi status", "error", err)
 		}
 	} else {
 		details, status := tearDownSvi(svi)
@@ -270,14 +280,14 @@ func handlesvi(objectData *eventbus.ObjectData) {
 			}
 			comp.CompStatus = common.ComponentStatusError
 		}
-		log.Printf("%+v\n", comp)
+		logger.Debugw("FRR: component status", logging.Component(comp.Name), "status", comp.CompStatus, "details", comp.Details)
 
 		// Checking the timer to decide if we need to replay or not
 		comp.CheckReplayThreshold(replayThreshold)
 
 		err := infradb.UpdateSviStatus(objectData.Name, objectData.ResourceVersion, objectData.NotificationID, nil, comp)
 		if err != nil {
-			log.Printf("error in updating svi status: %s\n", err)
+			logger.Errorw("error in updating svi status", "error", err)
 		}
 	}
 }
@@ -289,7 +299,7 @@ func handlevrf(objectData *eventbus.ObjectData) {
 	var comp common.Component
 	vrf, err := infradb.GetVrf(objectData.Name)
 	if err != nil {
-		log.Printf("GetVRF error: %s %s\n", err, objectData.Name)
+		logger.Errorw("FRR: GetVRF error", logging.Name(objectData.Name), "error", err)
 		comp.Name = frrComp
 		comp.CompStatus = common.ComponentStatusError
 		comp.Details = fmt.Sprintf("GetVRF error: %s %s\n", err, objectData.Name)
@@ -300,13 +310,13 @@ func handlevrf(objectData *eventbus.ObjectData) {
 		}
 		err := infradb.UpdateVrfStatus(objectData.Name, objectData.ResourceVersion, objectData.NotificationID, nil, comp)
 		if err != nil {
-			log.Printf("error in updating vrf status: %s\n", err)
+			logger.Errorw("error in updating vrf status", "error", err)
 		}
 		return
 	}
 
 	if objectData.ResourceVersion != vrf.ResourceVersion {
-		log.Printf("FRR: Mismatch in resoruce version %+v\n and vrf resource version %+v\n", objectData.ResourceVersion, vrf.ResourceVersion)
+		logger.Debugw("FRR: Mismatch in resource version", logging.ResourceVersion(objectData.ResourceVersion), "vrfResourceVersion", vrf.ResourceVersion)
 		comp.Name = frrComp
 		comp.CompStatus = common.ComponentStatusError
 		comp.Details = fmt.Sprintf("FRR: Mismatch in resoruce version %+v\n and vrf resource version %+v\n", objectData.ResourceVersion, vrf.ResourceVersion)
@@ -317,7 +327,7 @@ func handlevrf(objectData *eventbus.ObjectData) {
 		}
 		err := infradb.UpdateVrfStatus(objectData.Name, objectData.ResourceVersion, objectData.NotificationID, nil, comp)
 		if err != nil {
-			log.Printf("error in updating vrf status: %s\n", err)
+			logger.Errorw("error in updating vrf status", "error", err)
 		}
 		return
 	}
@@ -329,7 +339,11 @@ func handlevrf(objectData *eventbus.ObjectData) {
 		}
 	}
 	if vrf.Status.VrfOperStatus != infradb.VrfOperStatusToBeDeleted {
-		detail, status := setUpVrf(vrf)
+		detail, status := comp.Details, true
+		// Only the loopback IP of an updated vrf is used in the FRR configuration
+		if changes := vrf.Changes(); changes == nil || changes.Has(infradb.FieldLoopbackIP) {
+			detail, status = setUpVrf(vrf)
+		}
 		comp.Name = frrComp
 		comp.Details = detail
 		if status {
@@ -343,14 +357,14 @@ func handlevrf(objectData *eventbus.ObjectData) {
 			}
 			comp.CompStatus = common.ComponentStatusError
 		}
-		log.Printf("%+v\n", comp)
+		logger.Debugw("FRR: component status", logging.Component(comp.Name), "status", comp.CompStatus, "details", comp.Details)
 
 		// Checking the timer to decide if we need to replay or not
 		comp.CheckReplayThreshold(replayThreshold)
 
 		err := infradb.UpdateVrfStatus(objectData.Name, objectData.ResourceVersion, objectData.NotificationID, nil, comp)
 		if err != nil {
-			log.Printf("error in updating vrf status: %s\n", err)
+			logger.Errorw("error in updating vrf status", "error", err)
 		}
 	} else {
 		details, status := tearDownVrf(vrf)
@@ -367,14 +381,14 @@ func handlevrf(objectData *eventbus.ObjectData) {
 			}
 			comp.CompStatus = common.ComponentStatusError
 		}
-		log.Printf("%+v\n", comp)
+		logger.Debugw("FRR: component status", logging.Component(comp.Name), "status", comp.CompStatus, "details", comp.Details)
 
 		// Checking the timer to decide if we need to replay or not
 		comp.CheckReplayThreshold(replayThreshold)
 
 		err := infradb.UpdateVrfStatus(objectData.Name, objectData.ResourceVersion, objectData.NotificationID, nil, comp)
 		if err != nil {
-			log.Printf("error in updating vrf status: %s\n", err)
+			logger.Errorw("error in updating vrf status", "error", err)
 		}
 	}
 }
@@ -390,7 +404,7 @@ func run(cmd []string, flag bool) (string, int) {
 		if flag {
 			panic(fmt.Sprintf("FRR: Command %s': exit code %s;", out, err.Error()))
 		}
-		log.Printf("FRR: Command %s': exit code %s;", out, err)
+		logger.Infow("FRR: Command failed", "output", out, "error", err)
 		return "Error", -1
 	}
 	output := string(out)
@@ -423,28 +437,45 @@ var ctx context.Context
 // frr variable of type utils wrapper
 var frr utils.Frr
 
+func init() {
+	modules.Register(modules.Module{
+		Name:         frrComp,
+		BuildEnvs:    []string{"ci"},
+		Events:       []string{"vrf", "svi"},
+		Priority:     3,
+		Initialize:   Initialize,
+		DeInitialize: DeInitialize,
+		// DeInitialize only unsubscribes, the realized state is left in place
+		Stop: DeInitialize,
+	})
+}
+
 // Initialize function handles init functionality
 func Initialize() {
 	frrEnabled := config.GlobalConfig.LinuxFrr.Enabled
 	if !frrEnabled {
-		log.Println("FRR Module disabled")
+		logger.Info("FRR Module disabled")
 		return
 	}
 	defaultVtep = config.GlobalConfig.LinuxFrr.DefaultVtep
 	localas = config.GlobalConfig.LinuxFrr.LocalAs
 	portMux = config.GlobalConfig.Interfaces.PortMux
 	vrfMux = config.GlobalConfig.Interfaces.VrfMux
-	log.Printf(" frr vtep: %+v port-mux %+v vrf-mux: +%v", defaultVtep, portMux, vrfMux)
+	logger.Debugw("FRR: Config", "vtep", defaultVtep, "portMux", portMux, "vrfMux", vrfMux)
 	// Subscribe to InfraDB notifications
-	subscribeInfradb(&config.GlobalConfig)
+	subscribeInfradb(config.Current())
 
 	ctx = context.Background()
 	frr = utils.NewFrrWrapperWithArgs("localhost", config.GlobalConfig.Tracer)
 
-	// Make sure IPv4 forwarding is enabled.
+	// Make sure IPv4 and IPv6 forwarding is enabled.
 	detail, flag := run([]string{"sysctl", "-w", " net.ipv4.ip_forward=1"}, false)
 	if flag != 0 {
-		log.Println("Error in running command", detail)
+		logger.Errorln("Error in running command", detail)
+	}
+	detail, flag = run([]string{"sysctl", "-w", "net.ipv6.conf.all.forwarding=1"}, false)
+	if flag != 0 {
+		logger.Errorln("Error in running command", detail)
 	}
 }
 
@@ -452,7 +483,7 @@ func Initialize() {
 func DeInitialize() {
 	frrEnabled := config.GlobalConfig.LinuxFrr.Enabled
 	if !frrEnabled {
-		log.Println("FRR Module disabled")
+		logger.Info("FRR Module disabled")
 		return
 	}
 	// Unsubscribe to InfraDB notifications
@@ -473,40 +504,38 @@ func setUpVrf(vrf *infradb.Vrf) (string, bool) {
 
 		_, err := frr.FrrZebraCmd(ctx, fmt.Sprintf("configure terminal\n %s\n %s\n exit-vrf\n exit", vrfName, vniID), false)
 		if err != nil {
-			log.Printf("FRR: Error Executing frr config t %s %s exit-vrf exit \n Error: is%v\n", vrfName, vniID, err)
+			logger.Errorw("FRR: Error executing frr config t vrf vni exit-vrf exit", "vrfName", vrfName, "vni", vniID, "error", err)
 			return fmt.Sprintf("FRR: Error Executing frr config t %s %s exit-vrf exit \n Error: %v \n", vrfName, vniID, err), false
 		}
 		err = frr.Save(ctx)
 		if err != nil {
-			log.Printf("FRR(setUpVrf): Failed to run save command: %v\n", err)
+			logger.Errorw("FRR(setUpVrf): Failed to run save command", "error", err)
 		}
-		log.Printf("FRR: Executed frr config t %s %s exit-vrf exit\n", vrfName, vniID)
-		var lbIP string
-
-		if vrf.Spec.LoopbackIP != nil {
-			lbIP = "0.0.0.0"
-		} else {
+		logger.Infow("FRR: Executed frr config t vrf vni exit-vrf exit", "vrfName", vrfName, "vni", vniID)
+		// The BGP router id is always an IPv4 address
+		lbIP := "0.0.0.0"
+		if vrf.Spec.LoopbackIP != nil && vrf.Spec.LoopbackIP.IP.To4() != nil {
 			lbIP = fmt.Sprintf("%+v", vrf.Spec.LoopbackIP.IP)
 		}
-		_, err = frr.FrrBgpCmd(ctx, fmt.Sprintf("configure terminal\n router bgp %+v vrf %s\n bgp router-id %s\n no bgp ebgp-requires-policy\n no bgp hard-administrative-reset\n no bgp graceful-restart notification\n address-family ipv4 unicast\n redistribute connected\n redistribute static\n exit-address-family\n address-family l2vpn evpn\n advertise ipv4 unicast\n exit-address-family\n exit", localas, path.Base(vrf.Name), lbIP), false)
+		_, err = frr.FrrBgpCmd(ctx, fmt.Sprintf("configure terminal\n router bgp %+v vrf %s\n bgp router-id %s\n no bgp ebgp-requires-policy\n no bgp hard-administrative-reset\n no bgp graceful-restart notification\n address-family ipv4 unicast\n redistribute connected\n redistribute static\n exit-address-family\n address-family ipv6 unicast\n redistribute connected\n redistribute static\n exit-address-family\n address-family l2vpn evpn\n advertise ipv4 unicast\n advertise ipv6 unicast\n exit-address-family\n exit", localas, path.Base(vrf.Name), lbIP), false)
 		if err != nil {
-			log.Printf("FRR: Error Executing config t bgpVrfName router bgp %+v vrf %s bgp_route_id %s no bgp ebgp-requires-policy exit-vrf exit Error %v \n", localas, vrf.Name, lbIP, err)
+			logger.Errorw("FRR: Error executing config t router bgp vrf bgp router-id no bgp ebgp-requires-policy exit-vrf exit", "localas", localas, logging.Name(vrf.Name), "routerID", lbIP, "error", err)
 			return fmt.Sprintf("FRR: Error Executing config t bgpVrfName router bgp %+v vrf %s bgp_route_id %s no bgp ebgp-requires-policy exit-vrf exit Error %v \n", localas, vrf.Name, lbIP, err), false
 		}
 		err = frr.Save(ctx)
 		if err != nil {
-			log.Printf("FRR(setUpVrf): Failed to run save command: %v\n", err)
+			logger.Errorw("FRR(setUpVrf): Failed to run save command", "error", err)
 		}
-		log.Printf("FRR: Executed config t bgpVrfName router bgp %+v vrf %s bgp_route_id %s no bgp ebgp-requires-policy exit-vrf exit\n", localas, vrf.Name, lbIP)
+		logger.Debugw("FRR: Executed config t router bgp vrf bgp router-id no bgp ebgp-requires-policy exit-vrf exit", "localas", localas, logging.Name(vrf.Name), "routerID", lbIP)
 		// Update the vrf with attributes from FRR
 		cmd := fmt.Sprintf("show bgp l2vpn evpn vni %d json", *vrf.Spec.Vni)
 		cp, err := frr.FrrBgpCmd(ctx, cmd, true)
 		if err != nil {
-			log.Printf("FRR Error-show bgp l2vpn evpn vni %v cp %v", err, cp)
+			logger.Errorw("FRR: Error executing show bgp l2vpn evpn vni", "output", cp, "error", err)
 		}
 		err = frr.Save(ctx)
 		if err != nil {
-			log.Printf("FRR(setUpVrf): Failed to run save command: %v\n", err)
+			logger.Errorw("FRR(setUpVrf): Failed to run save command", "error", err)
 		}
 		hname, _ := os.Hostname()
 		l2VpnCmd := strings.Split(cp, "json")
@@ -515,17 +544,17 @@ func setUpVrf(vrf *infradb.Vrf) (string, bool) {
 		var bgpL2vpn bgpl2VpnCmd
 		err1 := json.Unmarshal([]byte(cp), &bgpL2vpn)
 		if err1 != nil {
-			log.Printf("FRR: unable to get the command %s\n", cmd)
+			logger.Errorw("FRR: Unable to get the command", "cmd", cmd, "error", err1)
 			return fmt.Sprintf("FRR: Failed in unmarshal the command %s\n", cmd), false
 		}
 		cmd = fmt.Sprintf("show bgp vrf %s json", path.Base(vrf.Name))
 		cp, err = frr.FrrBgpCmd(ctx, cmd, true)
 		if err != nil {
-			log.Printf("FRR:  unable to get the command %s-%v", cmd, err)
+			logger.Errorw("FRR: Unable to get the command", "cmd", cmd, "error", err)
 		}
 		err = frr.Save(ctx)
 		if err != nil {
-			log.Printf("FRR(setUpVrf): Failed to run save command: %v\n", err)
+			logger.Errorw("FRR(setUpVrf): Failed to run save command", "error", err)
 		}
 
 		bgpCmd := strings.Split(cp, "json")
@@ -535,12 +564,12 @@ func setUpVrf(vrf *infradb.Vrf) (string, bool) {
 		var bgpVrf bgpVrfCmd
 		err1 = json.Unmarshal([]byte(cp), &bgpVrf)
 		if err1 != nil {
-			log.Printf("FRR: unable to get the command %s \"%s\"\n", cp, cmd)
+			logger.Errorw("FRR: Unable to get the command", "output", cp, "cmd", cmd, "error", err1)
 			return fmt.Sprintf("FRR: unable to unmarshal \"%s\"\n", cmd), false
 		}
-		log.Printf("FRR: Executed show bgp vrf %s json\n", vrf.Name)
+		logger.Infow("FRR: Executed show bgp vrf json", logging.Name(vrf.Name))
 		details := fmt.Sprintf("{ \"rd\":\"%s\",\"rmac\":\"%s\",\"importRts\":[\"%s\"],\"exportRts\":[\"%s\"],\"localAS\":%d }", bgpL2vpn.Rd, bgpL2vpn.Rmac, bgpL2vpn.ImportRts, bgpL2vpn.ExportRts, bgpVrf.LocalAS)
-		log.Printf("FRR Details %s\n", details)
+		logger.Infow("FRR: Details", "details", details)
 		return details, true
 	}
 	return "", true
@@ -550,13 +579,12 @@ func setUpVrf(vrf *infradb.Vrf) (string, bool) {
 func setUpSvi(svi *infradb.Svi) (string, bool) {
 	brObj, err := infradb.GetLB(svi.Spec.LogicalBridge)
 	if err != nil {
-		log.Printf("FRR: unable to find key %s and error is %v", svi.Spec.LogicalBridge, err)
+		logger.Errorw("FRR: Unable to find key", logging.Name(svi.Spec.LogicalBridge), "error", err)
 		return fmt.Sprintf("FRR: unable to find key %s and error is %v", svi.Spec.LogicalBridge, err), false
 	}
 	linkSvi := fmt.Sprintf("%+v-%+v", path.Base(svi.Spec.Vrf), brObj.Spec.VlanID)
 	if svi.Spec.EnableBgp && len(svi.Spec.GatewayIPs) != 0 {
-		// gwIP := fmt.Sprintf("%s", svi.Spec.GatewayIPs[0].IP.To4())
-		gwIP := string(svi.Spec.GatewayIPs[0].IP.To4())
+		gwIP := svi.Spec.GatewayIPs[0].IP.String()
 		remoteAs := fmt.Sprintf("%d", *svi.Spec.RemoteAs)
 		bgpVrfName := fmt.Sprintf("router bgp %+v vrf %s\n", localas, path.Base(svi.Spec.Vrf))
 		neighlink := fmt.Sprintf("neighbor %s peer-group\n", linkSvi)
@@ -564,29 +592,60 @@ func setUpSvi(svi *infradb.Svi) (string, bool) {
 		neighlinkGw := fmt.Sprintf("neighbor %s update-source %s\n", linkSvi, gwIP)
 		neighlinkOv := fmt.Sprintf("neighbor %s as-override\n", linkSvi)
 		neighlinkSr := fmt.Sprintf("neighbor %s soft-reconfiguration inbound\n", linkSvi)
-		bgpListen := fmt.Sprintf(" bgp listen range %s peer-group %s\n", svi.Spec.GatewayIPs[0], linkSvi)
+		// Listen on the first gateway prefix of each address family and
+		// activate the peer group for IPv6 as only IPv4 is activated by default
+		var bgpListen, bgpIPv6 string
+		var listenV4, listenV6 bool
+		for _, gwIPNet := range svi.Spec.GatewayIPs {
+			if gwIPNet.IP.To4() != nil {
+				if !listenV4 {
+					bgpListen += fmt.Sprintf(" bgp listen range %s peer-group %s\n", gwIPNet, linkSvi)
+					listenV4 = true
+				}
+				continue
+			}
+			if !listenV6 {
+				bgpListen += fmt.Sprintf(" bgp listen range %s peer-group %s\n", gwIPNet, linkSvi)
+				bgpIPv6 = fmt.Sprintf(" address-family ipv6 unicast\n neighbor %s activate\n neighbor %s soft-reconfiguration inbound\n exit-address-family\n", linkSvi, linkSvi)
+				listenV6 = true
+			}
+		}
 
-		_, err := frr.FrrBgpCmd(ctx, fmt.Sprintf("configure terminal\n %s bgp disable-ebgp-connected-route-check\n %s %s %s %s %s %s exit", bgpVrfName, neighlink, neighlinkRe, neighlinkGw, neighlinkOv, neighlinkSr, bgpListen), false)
+		_, err := frr.FrrBgpCmd(ctx, fmt.Sprintf("configure terminal\n %s bgp disable-ebgp-connected-route-check\n %s %s %s %s %s %s %s exit", bgpVrfName, neighlink, neighlinkRe, neighlinkGw, neighlinkOv, neighlinkSr, bgpListen, bgpIPv6), false)
 
 		if err != nil {
-			log.Printf("FRR: Error in conf svi %s %s command %s\n", svi.Name, path.Base(svi.Spec.Vrf), err)
+			logger.Errorw("FRR: Error in conf svi command", logging.Name(svi.Name), "vrf", path.Base(svi.Spec.Vrf), "error", err)
 			return fmt.Sprintf("FRR: Error in conf svi %s %s command %s\n", svi.Name, path.Base(svi.Spec.Vrf), err), false
 		}
 		err = frr.Save(ctx)
 		if err != nil {
-			log.Printf("FRR(setUpSvi): Failed to run save command: %v\n", err)
+			logger.Errorw("FRR(setUpSvi): Failed to run save command", "error", err)
 		}
 		return "", true
 	}
 	return "", true
 }
 
+// updateSvi realizes the changes of an updated svi
+func updateSvi(svi *infradb.Svi, changes infradb.SpecDiff) (string, bool) {
+	if !changes.Has(infradb.FieldEnableBgp) && !changes.Has(infradb.FieldRemoteAs) && !changes.Has(infradb.FieldGatewayIPs) {
+		return "", true
+	}
+	// The peer group is configured from scratch as the listen ranges depend on the gateway IPs
+	prevSvi := *svi
+	prevSvi.Spec = svi.PrevSpec
+	if details, ok := tearDownSvi(&prevSvi); !ok {
+		return details, false
+	}
+	return setUpSvi(svi)
+}
+
 // tearDownSvi tears down svi
 func tearDownSvi(svi *infradb.Svi) (string, bool) {
 	// linkSvi := fmt.Sprintf("%+v-%+v", path.Base(svi.Spec.Vrf), strings.Split(path.Base(svi.Spec.LogicalBridge), "vlan")[1])
 	brObj, err := infradb.GetLB(svi.Spec.LogicalBridge)
 	if err != nil {
-		log.Printf("LCI: unable to find key %s and error is %v", svi.Spec.LogicalBridge, err)
+		logger.Errorw("FRR: Unable to find key", logging.Name(svi.Spec.LogicalBridge), "error", err)
 		return fmt.Sprintf("LCI: unable to find key %s and error is %v", svi.Spec.LogicalBridge, err), false
 	}
 	linkSvi := fmt.Sprintf("%+v-%+v", path.Base(svi.Spec.Vrf), brObj.Spec.VlanID)
@@ -597,14 +656,14 @@ func tearDownSvi(svi *infradb.Svi) (string, bool) {
 		_, err := frr.FrrBgpCmd(ctx, fmt.Sprintf("configure terminal\n %s\n %s\n exit", bgpVrfName, noNeigh), false)
 
 		if err != nil {
-			log.Printf("FRR: Error in conf Delete vrf/VNI command %s\n", err)
+			logger.Errorw("FRR: Error in conf Delete vrf/VNI command", "error", err)
 			return fmt.Sprintf("FRR: Error in conf Delete vrf/VNI command %s\n", err), false
 		}
 		err = frr.Save(ctx)
 		if err != nil {
-			log.Printf("FRR(tearDownSvi): Failed to run save command: %v\n", err)
+			logger.Errorw("FRR(tearDownSvi): Failed to run save command", "error", err)
 		}
-		log.Printf("FRR: Executed vtysh -c conf t -c router bgp %+v vrf %s -c no  neighbor %s peer-group -c exit\n", localas, path.Base(svi.Spec.Vrf), linkSvi)
+		logger.Debugw("FRR: Executed vtysh -c conf t -c router bgp vrf -c no neighbor peer-group -c exit", "localas", localas, "vrf", path.Base(svi.Spec.Vrf), "linkSvi", linkSvi)
 		return "", true
 	}
 	return "", true
@@ -619,37 +678,37 @@ func tearDownVrf(vrf *infradb.Vrf) (string, bool) {
 
 	_, err := frr.FrrZebraCmd(ctx, fmt.Sprintf("show vrf %s vni\n", path.Base(vrf.Name)), true)
 	if err != nil {
-		log.Printf("FRR: Error  %s\n", err)
+		logger.Errorw("FRR: Error", "error", err)
 		return "", true
 	}
 	err = frr.Save(ctx)
 	if err != nil {
-		log.Printf("FRR(tearDownVrf): Failed to run save command: %v\n", err)
+		logger.Errorw("FRR(tearDownVrf): Failed to run save command", "error", err)
 	}
 	// Clean up FRR last
 	if vrf != nil && *vrf.Spec.Vni != 0 {
-		log.Printf("FRR Deleted event")
+		logger.Infow("FRR: Deleted event")
 		delCmd1 := fmt.Sprintf("no router bgp %+v vrf %s", localas, path.Base(vrf.Name))
 		delCmd2 := fmt.Sprintf("no vrf %s", path.Base(vrf.Name))
 		_, err = frr.FrrBgpCmd(ctx, fmt.Sprintf("configure terminal\n %s\n exit\n", delCmd1), false)
 		if err != nil {
-			log.Printf("FRR: Error  %s\n", err)
+			logger.Errorw("FRR: Error", "error", err)
 			return fmt.Sprintf("FRR: Error  %s\n", err), false
 		}
 		err = frr.Save(ctx)
 		if err != nil {
-			log.Printf("FRR(tearDownVrf): Failed to run save command: %v\n", err)
+			logger.Errorw("FRR(tearDownVrf): Failed to run save command", "error", err)
 		}
 		_, err = frr.FrrZebraCmd(ctx, fmt.Sprintf("configure terminal\n %s\n exit\n", delCmd2), false)
 		if err != nil {
-			log.Printf("FRR: Error  %s\n", err)
+			logger.Errorw("FRR: Error", "error", err)
 			return fmt.Sprintf("FRR: Error  %s\n", err), false
 		}
 		err = frr.Save(ctx)
 		if err != nil {
-			log.Printf("FRR(tearDownVrf): Failed to run save command: %v\n", err)
+			logger.Errorw("FRR(tearDownVrf): Failed to run save command", "error", err)
 		}
-		log.Printf("FRR: Executed vtysh -c conf t -c %s -c %s -c exit\n", delCmd1, delCmd2)
+		logger.Infow("FRR: Executed vtysh -c conf t", "delCmd1", delCmd1, "delCmd2", delCmd2)
 	}
 	return "", true
 }
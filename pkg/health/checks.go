@@ -0,0 +1,83 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package health reports the health of the bridge on the gRPC health service and on HTTP probes
+package health
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"slices"
+	"time"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
+)
+
+const (
+	// CheckStorage is the name of the storage backend check
+	CheckStorage = "storage"
+	// CheckTenantBridge is the name of the br-tenant check
+	CheckTenantBridge = "br-tenant"
+	// CheckFrr is the name of the FRR check
+	CheckFrr = "frr"
+
+	// subscriberTimeout matches the time the task manager waits for a subscriber to report a status
+	subscriberTimeout = 30 * time.Second
+)
+
+// CheckSubscribers returns the name of the check of the subscribers of the event type
+func CheckSubscribers(eventType string) string {
+	return "subscribers/" + eventType
+}
+
+// StorageCheck checks that the storage backend is reachable
+func StorageCheck() CheckFunc {
+	return func(_ context.Context) error {
+		return infradb.Ping()
+	}
+}
+
+// SubscribersCheck checks that all the subscribers configured for the event type
+// are registered and are not stuck on an event
+func SubscribersCheck(eventType string, subscribers []config.SubscriberConfig, eb *eventbus.EventBus) CheckFunc {
+	return func(_ context.Context) error {
+		for _, subscriberConfig := range subscribers {
+			if !slices.Contains(subscriberConfig.Events, eventType) {
+				continue
+			}
+			sub := eb.FindSubscriber(eventType, subscriberConfig.Name)
+			if sub == nil {
+				return fmt.Errorf("subscriber %s is not registered", subscriberConfig.Name)
+			}
+			if busy := sub.Busy(); busy > subscriberTimeout {
+				return fmt.Errorf("subscriber %s has not responded for %v", subscriberConfig.Name, busy.Round(time.Second))
+			}
+		}
+		return nil
+	}
+}
+
+// TenantBridgeCheck checks that the tenant bridge exists and is up
+func TenantBridgeCheck(nlink utils.Netlink, name string) CheckFunc {
+	return func(ctx context.Context) error {
+		link, err := nlink.LinkByName(ctx, name)
+		if err != nil {
+			return fmt.Errorf("%s not found: %w", name, err)
+		}
+		if link.Attrs().Flags&net.FlagUp == 0 {
+			return fmt.Errorf("%s is down", name)
+		}
+		return nil
+	}
+}
+
+// FrrCheck checks that the vty ports of FRR answer
+func FrrCheck(frr *utils.FrrWrapper) CheckFunc {
+	return func(ctx context.Context) error {
+		return frr.Ping(ctx)
+	}
+}
@@ -0,0 +1,202 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package health reports the health of the bridge on the gRPC health service and on HTTP probes
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/logging"
+)
+
+// logger is the logger of the grpc subsystem
+var logger = logging.Logger(logging.Grpc).Sugar()
+
+const (
+	// DefaultInterval is the time between two runs of the checks
+	DefaultInterval = 5 * time.Second
+	// checkTimeout bounds the time a single check may take
+	checkTimeout = 3 * time.Second
+)
+
+// CheckFunc checks a dependency of the bridge and returns why it is unhealthy
+type CheckFunc func(ctx context.Context) error
+
+type check struct {
+	name string
+	fn   CheckFunc
+}
+
+// Monitor runs the checks periodically and reflects their results
+// in the serving status of the gRPC services that depend on them.
+// The overall status, the empty service name, depends on all the checks.
+type Monitor struct {
+	mtx      sync.RWMutex
+	server   *health.Server
+	interval time.Duration
+	checks   []check
+	services map[string][]string
+	results  map[string]error
+	ran      bool
+	shutdown bool
+}
+
+// NewMonitor creates a monitor that runs the checks at the given interval
+func NewMonitor(interval time.Duration) *Monitor {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	m := &Monitor{
+		server:   health.NewServer(),
+		interval: interval,
+		services: make(map[string][]string),
+		results:  make(map[string]error),
+	}
+	// Nothing is served until the checks have run once
+	m.server.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	return m
+}
+
+// Server returns the gRPC health server to register
+func (m *Monitor) Server() *health.Server {
+	return m.server
+}
+
+// AddCheck adds a named check
+func (m *Monitor) AddCheck(name string, fn CheckFunc) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.checks = append(m.checks, check{name: name, fn: fn})
+}
+
+// AddService makes the serving status of a gRPC service depend on the named checks
+func (m *Monitor) AddService(service string, checks ...string) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.services[service] = checks
+	m.server.SetServingStatus(service, healthpb.HealthCheckResponse_NOT_SERVING)
+}
+
+// Run runs the checks until the context is canceled
+func (m *Monitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	for {
+		m.RunChecks(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunChecks runs all the checks once and updates the serving statuses
+func (m *Monitor) RunChecks(ctx context.Context) {
+	m.mtx.RLock()
+	checks := append([]check{}, m.checks...)
+	m.mtx.RUnlock()
+
+	results := make(map[string]error, len(checks))
+	for _, c := range checks {
+		checkCtx, cancel := context.WithTimeout(ctx, checkTimeout)
+		err := c.fn(checkCtx)
+		cancel()
+		if err != nil {
+			logger.Errorw("Health: Check has failed", "check", c.name, "error", err)
+		}
+		results[c.name] = err
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.results = results
+	m.ran = true
+	if m.shutdown {
+		return
+	}
+	m.server.SetServingStatus("", servingStatus(results, nil))
+	for service, deps := range m.services {
+		m.server.SetServingStatus(service, servingStatus(results, deps))
+	}
+}
+
+// Shutdown reports all the services as not serving from now on
+func (m *Monitor) Shutdown() {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.shutdown = true
+	m.server.Shutdown()
+}
+
+// servingStatus returns the status from the results of the checks,
+// all the checks are taken into account when deps is nil
+func servingStatus(results map[string]error, deps []string) healthpb.HealthCheckResponse_ServingStatus {
+	if deps == nil {
+		for _, err := range results {
+			if err != nil {
+				return healthpb.HealthCheckResponse_NOT_SERVING
+			}
+		}
+		return healthpb.HealthCheckResponse_SERVING
+	}
+	for _, dep := range deps {
+		if err, ok := results[dep]; !ok || err != nil {
+			return healthpb.HealthCheckResponse_NOT_SERVING
+		}
+	}
+	return healthpb.HealthCheckResponse_SERVING
+}
+
+// Report is the outcome of the last run of the checks
+type Report struct {
+	Ready  bool              `json:"ready"`
+	Checks map[string]string `json:"checks"`
+}
+
+// Report returns the outcome of the last run of the checks
+func (m *Monitor) Report() Report {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	r := Report{Ready: m.ran && !m.shutdown, Checks: make(map[string]string, len(m.results))}
+	for name, err := range m.results {
+		if err != nil {
+			r.Checks[name] = err.Error()
+			r.Ready = false
+		} else {
+			r.Checks[name] = "ok"
+		}
+	}
+	return r
+}
+
+// LivenessHandler answers the /healthz probe, the process is alive as long as it serves HTTP
+func (m *Monitor) LivenessHandler(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("ok\n"))
+}
+
+// ReadinessHandler answers the /readyz probe with the outcome of the checks,
+// the status is 503 until all the checks have passed
+func (m *Monitor) ReadinessHandler(w http.ResponseWriter, _ *http.Request) {
+	report := m.Report()
+	w.Header().Set("Content-Type", "application/json")
+	if report.Ready {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		logger.Errorw("Health: Failed to write the readiness report", "error", err)
+	}
+}
@@ -0,0 +1,200 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package health reports the health of the bridge on the gRPC health service and on HTTP probes
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/vishvananda/netlink"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils/mocks"
+)
+
+var errUnreachable = errors.New("unreachable")
+
+func servingStatusOf(t *testing.T, m *Monitor, service string) healthpb.HealthCheckResponse_ServingStatus {
+	t.Helper()
+	resp, err := m.Server().Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.GetStatus()
+}
+
+func TestMonitor_RunChecks(t *testing.T) {
+	tests := map[string]struct {
+		storage  error
+		frr      error
+		overall  healthpb.HealthCheckResponse_ServingStatus
+		bridge   healthpb.HealthCheckResponse_ServingStatus
+		vrf      healthpb.HealthCheckResponse_ServingStatus
+		ready    bool
+		httpCode int
+	}{
+		"all healthy": {
+			overall:  healthpb.HealthCheckResponse_SERVING,
+			bridge:   healthpb.HealthCheckResponse_SERVING,
+			vrf:      healthpb.HealthCheckResponse_SERVING,
+			ready:    true,
+			httpCode: http.StatusOK,
+		},
+		"frr down": {
+			frr:      errUnreachable,
+			overall:  healthpb.HealthCheckResponse_NOT_SERVING,
+			bridge:   healthpb.HealthCheckResponse_SERVING,
+			vrf:      healthpb.HealthCheckResponse_NOT_SERVING,
+			ready:    false,
+			httpCode: http.StatusServiceUnavailable,
+		},
+		"storage down": {
+			storage:  errUnreachable,
+			overall:  healthpb.HealthCheckResponse_NOT_SERVING,
+			bridge:   healthpb.HealthCheckResponse_NOT_SERVING,
+			vrf:      healthpb.HealthCheckResponse_NOT_SERVING,
+			ready:    false,
+			httpCode: http.StatusServiceUnavailable,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := NewMonitor(0)
+			m.AddCheck(CheckStorage, func(context.Context) error { return tt.storage })
+			m.AddCheck(CheckFrr, func(context.Context) error { return tt.frr })
+			m.AddService("bridge", CheckStorage)
+			m.AddService("vrf", CheckStorage, CheckFrr)
+
+			if s := servingStatusOf(t, m, "vrf"); s != healthpb.HealthCheckResponse_NOT_SERVING {
+				t.Errorf("Expected services not to be served before the first run, received %v", s)
+			}
+
+			m.RunChecks(context.Background())
+
+			if s := servingStatusOf(t, m, ""); s != tt.overall {
+				t.Errorf("Expected overall status %v, received %v", tt.overall, s)
+			}
+			if s := servingStatusOf(t, m, "bridge"); s != tt.bridge {
+				t.Errorf("Expected bridge status %v, received %v", tt.bridge, s)
+			}
+			if s := servingStatusOf(t, m, "vrf"); s != tt.vrf {
+				t.Errorf("Expected vrf status %v, received %v", tt.vrf, s)
+			}
+
+			rec := httptest.NewRecorder()
+			m.ReadinessHandler(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+			if rec.Code != tt.httpCode {
+				t.Errorf("Expected HTTP status %v, received %v", tt.httpCode, rec.Code)
+			}
+			report := Report{}
+			if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+				t.Fatal(err)
+			}
+			if report.Ready != tt.ready {
+				t.Errorf("Expected ready %v, received %v", tt.ready, report.Ready)
+			}
+			if tt.frr != nil && report.Checks[CheckFrr] != tt.frr.Error() {
+				t.Errorf("Expected the frr failure to be reported, received %v", report.Checks)
+			}
+		})
+	}
+}
+
+func TestMonitor_Shutdown(t *testing.T) {
+	m := NewMonitor(0)
+	m.AddCheck(CheckStorage, func(context.Context) error { return nil })
+	m.AddService("bridge", CheckStorage)
+	m.RunChecks(context.Background())
+
+	m.Shutdown()
+	m.RunChecks(context.Background())
+
+	if s := servingStatusOf(t, m, "bridge"); s != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("Expected bridge not to be served after shutdown, received %v", s)
+	}
+	if m.Report().Ready {
+		t.Error("Expected not to be ready after shutdown")
+	}
+
+	rec := httptest.NewRecorder()
+	m.LivenessHandler(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected to be alive after shutdown, received %v", rec.Code)
+	}
+}
+
+type testHandler struct{}
+
+func (h *testHandler) HandleEvent(string, *eventbus.ObjectData) {}
+
+func TestSubscribersCheck(t *testing.T) {
+	subscribers := []config.SubscriberConfig{
+		{Name: "lgm", Priority: 1, Events: []string{"vrf", "svi"}},
+		{Name: "frr", Priority: 2, Events: []string{"vrf"}},
+	}
+	eb := eventbus.NewEventBus()
+	eb.StartSubscriber("lgm", "vrf", 1, &testHandler{})
+	eb.StartSubscriber("lgm", "svi", 1, &testHandler{})
+
+	tests := map[string]struct {
+		eventType string
+		expectErr bool
+	}{
+		"all subscribers registered": {eventType: "svi", expectErr: false},
+		"missing subscriber":         {eventType: "vrf", expectErr: true},
+		"no subscribers configured":  {eventType: "bridge-port", expectErr: false},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := SubscribersCheck(tt.eventType, subscribers, eb)(context.Background())
+			if (err != nil) != tt.expectErr {
+				t.Errorf("Expected error %v, received %v", tt.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestTenantBridgeCheck(t *testing.T) {
+	tests := map[string]struct {
+		link      netlink.Link
+		err       error
+		expectErr bool
+	}{
+		"bridge up": {
+			link:      &netlink.Bridge{LinkAttrs: netlink.LinkAttrs{Name: "br-tenant", Flags: net.FlagUp}},
+			expectErr: false,
+		},
+		"bridge down": {
+			link:      &netlink.Bridge{LinkAttrs: netlink.LinkAttrs{Name: "br-tenant"}},
+			expectErr: true,
+		},
+		"bridge missing": {
+			err:       errors.New("Link not found"),
+			expectErr: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			nlink := mocks.NewNetlink(t)
+			nlink.EXPECT().LinkByName(mock.Anything, "br-tenant").Return(tt.link, tt.err).Once()
+
+			err := TenantBridgeCheck(nlink, "br-tenant")(context.Background())
+			if (err != nil) != tt.expectErr {
+				t.Errorf("Expected error %v, received %v", tt.expectErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,432 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"errors"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/taskmanager"
+	"github.com/opiproject/opi-evpn-bridge/pkg/logging"
+)
+
+// ErrBatchAborted is reported for the items of a batch that have not been
+// applied because another item of the same batch has failed
+var ErrBatchAborted = errors.New("the batch has been aborted due to a failure of another item")
+
+// storeTxn keeps track of the changes of a batch so that they can be undone
+// if a later write to the store fails. The store has no transactions, so this
+// together with the globalLock is what makes a batch all-or-nothing.
+type storeTxn struct {
+	undo []func() error
+}
+
+// create stores a new key that will be deleted on rollback
+func (t *storeTxn) create(key string, value interface{}) error {
+	if err := infradb.client.Set(key, value); err != nil {
+		return err
+	}
+	t.undo = append(t.undo, func() error {
+		return infradb.client.Delete(key)
+	})
+	return nil
+}
+
+// update stores a key that will be reverted to its previous value on rollback
+func (t *storeTxn) update(key string, value, previous interface{}) error {
+	if err := infradb.client.Set(key, value); err != nil {
+		return err
+	}
+	t.undo = append(t.undo, func() error {
+		return infradb.client.Set(key, previous)
+	})
+	return nil
+}
+
+// rollback undoes all the changes in reverse order
+func (t *storeTxn) rollback() {
+	for i := len(t.undo) - 1; i >= 0; i-- {
+		if err := t.undo[i](); err != nil {
+			logger.Errorw("rollback(): Failed to undo a batch change", "error", err)
+		}
+	}
+	t.undo = nil
+}
+
+// batchFailed fills the errors of the items that have not failed with ErrBatchAborted
+// and reports if any of the items has failed
+func batchFailed(errs []error) bool {
+	failed := false
+	for _, err := range errs {
+		if err != nil {
+			failed = true
+			break
+		}
+	}
+	if failed {
+		for i := range errs {
+			if errs[i] == nil {
+				errs[i] = ErrBatchAborted
+			}
+		}
+	}
+	return failed
+}
+
+// batchError sets the same error to all the items of a batch
+func batchError(errs []error, err error) []error {
+	for i := range errs {
+		errs[i] = err
+	}
+	return errs
+}
+
+// CreateLBs creates a batch of logical bridge objects.
+// All the objects are validated before anything gets stored and either all
+// or none of them are created. The returned slice holds the error of each item
+// in the order of the input and is all nil on success.
+// nolint: funlen, gocognit
+func CreateLBs(lbs []*LogicalBridge) []error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	errs := make([]error, len(lbs))
+
+	subscribers := eventbus.EBus.GetSubscribers("logical-bridge")
+	if len(subscribers) == 0 {
+		logger.Info("CreateLBs(): No subscribers for Logical Bridge objects")
+		return batchError(errs, errors.New("no subscribers found for logical bridge"))
+	}
+
+	logger.Infow("CreateLBs(): Create Logical Bridges", "count", len(lbs))
+
+	vpns := make(map[uint32]bool)
+	if _, err := infradb.client.Get("vpns", &vpns); err != nil {
+		logger.Error(err)
+		return batchError(errs, err)
+	}
+	lbsMap := make(map[string]bool)
+	if _, err := infradb.client.Get("lbs", &lbsMap); err != nil {
+		logger.Error(err)
+		return batchError(errs, err)
+	}
+
+	// Validate all the items up front, including VNIs repeated inside the batch
+	newVpns := make(map[uint32]bool)
+	for i, lb := range lbs {
+		if _, ok := lbsMap[lb.Name]; ok {
+			errs[i] = ErrKeyExists
+			continue
+		}
+		if lb.Spec.Vni == nil {
+			continue
+		}
+		_, inUse := vpns[*lb.Spec.Vni]
+		_, inBatch := newVpns[*lb.Spec.Vni]
+		if inUse || inBatch {
+			logger.Debugw("CreateLBs(): VNI already in use", logging.Name(lb.Name), "vni", *lb.Spec.Vni)
+			errs[i] = ErrVniInUse
+			continue
+		}
+		newVpns[*lb.Spec.Vni] = false
+	}
+	if batchFailed(errs) {
+		return errs
+	}
+
+	txn := &storeTxn{}
+	for _, lb := range lbs {
+		if err := txn.create(lb.Name, lb); err != nil {
+			logger.Error(err)
+			txn.rollback()
+			return batchError(errs, err)
+		}
+	}
+
+	// Store the VNIs and the names to the DB maps once for the whole batch
+	if len(newVpns) != 0 {
+		prevVpns := make(map[uint32]bool, len(vpns))
+		for vni := range vpns {
+			prevVpns[vni] = false
+		}
+		for vni := range newVpns {
+			vpns[vni] = false
+		}
+		if err := txn.update("vpns", &vpns, &prevVpns); err != nil {
+			logger.Error(err)
+			txn.rollback()
+			return batchError(errs, err)
+		}
+	}
+
+	prevLbsMap := make(map[string]bool, len(lbsMap))
+	for name := range lbsMap {
+		prevLbsMap[name] = false
+	}
+	for _, lb := range lbs {
+		lbsMap[lb.Name] = false
+	}
+	if err := txn.update("lbs", &lbsMap, &prevLbsMap); err != nil {
+		logger.Error(err)
+		txn.rollback()
+		return batchError(errs, err)
+	}
+
+	tasks := make([]taskmanager.TaskRequest, 0, len(lbs))
+	for _, lb := range lbs {
+		tasks = append(tasks, taskmanager.TaskRequest{Name: lb.Name, ObjectType: "logical-bridge", ResourceVersion: lb.ResourceVersion, Subs: subscribers})
+	}
+	taskmanager.TaskMan.CreateTasks(tasks)
+
+	return errs
+}
+
+// DeleteLBs deletes a batch of logical bridge objects on behalf of the deletedBy client.
+// Either all or none of the objects are marked for deletion.
+func DeleteLBs(names []string, deletedBy string) []error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	errs := make([]error, len(names))
+
+	subscribers := eventbus.EBus.GetSubscribers("logical-bridge")
+	if len(subscribers) == 0 {
+		logger.Info("DeleteLBs(): No subscribers for Logical Bridge objects")
+		return batchError(errs, errors.New("no subscribers found for logical bridge"))
+	}
+
+	lbs := make([]*LogicalBridge, len(names))
+	for i, name := range names {
+		lb := &LogicalBridge{}
+		found, err := infradb.client.Get(name, lb)
+		switch {
+		case err != nil:
+			logger.Error(err)
+			errs[i] = err
+		case !found:
+			errs[i] = ErrKeyNotFound
+		case lb.Svi != "":
+			logger.Debugw("DeleteLBs(): Can not delete Logical Bridge. Associated with SVI interfaces", logging.Name(lb.Name))
+			errs[i] = ErrLogicalBridgeNotEmpty
+		case len(lb.BridgePorts) != 0 || len(lb.MacTable) != 0:
+			logger.Debugw("DeleteLBs(): Can not delete Logical Bridge. Associated with Bridge Ports", logging.Name(lb.Name))
+			errs[i] = ErrLogicalBridgeNotEmpty
+		}
+		lbs[i] = lb
+	}
+	if batchFailed(errs) {
+		return errs
+	}
+
+	txn := &storeTxn{}
+	tasks := make([]taskmanager.TaskRequest, 0, len(lbs))
+	for _, lb := range lbs {
+		prev := *lb
+		prevStatus := *lb.Status
+		prevStatus.Components = append([]common.Component{}, lb.Status.Components...)
+		prev.Status = &prevStatus
+		prevMeta := *lb.Metadata
+		prev.Metadata = &prevMeta
+
+		for i := range subscribers {
+			lb.Status.Components[i].CompStatus = common.ComponentStatusPending
+		}
+		lb.ResourceVersion = generateVersion()
+		lb.Status.LBOperStatus = LogicalBridgeOperStatusToBeDeleted
+		lb.Metadata.Audit.DeletedBy = deletedBy
+
+		if err := txn.update(lb.Name, lb, &prev); err != nil {
+			logger.Error(err)
+			txn.rollback()
+			return batchError(errs, err)
+		}
+		tasks = append(tasks, taskmanager.TaskRequest{Name: lb.Name, ObjectType: "logical-bridge", ResourceVersion: lb.ResourceVersion, Subs: subscribers})
+	}
+	taskmanager.TaskMan.CreateTasks(tasks)
+
+	return errs
+}
+
+// CreateBPs creates a batch of bridge port objects.
+// All the objects are validated before anything gets stored and either all
+// or none of them are created. The returned slice holds the error of each item
+// in the order of the input and is all nil on success.
+// nolint: funlen, gocognit
+func CreateBPs(bps []*BridgePort) []error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	errs := make([]error, len(bps))
+
+	subscribers := eventbus.EBus.GetSubscribers("bridge-port")
+	if len(subscribers) == 0 {
+		logger.Info("CreateBPs(): No subscribers for Bridge Port objects")
+		return batchError(errs, errors.New("no subscribers found for bridge port"))
+	}
+
+	logger.Infow("CreateBPs(): Create Bridge Ports", "count", len(bps))
+
+	lbsMap := make(map[string]bool)
+	if _, err := infradb.client.Get("lbs", &lbsMap); err != nil {
+		logger.Error(err)
+		return batchError(errs, err)
+	}
+	bpsMap := make(map[string]bool)
+	if _, err := infradb.client.Get("bps", &bpsMap); err != nil {
+		logger.Error(err)
+		return batchError(errs, err)
+	}
+
+	// The Logical Bridges are loaded once and shared by all the Bridge Ports of the batch
+	// so that references from several ports to the same bridge are stored together
+	lbs := make(map[string]*LogicalBridge)
+	prevLbs := make(map[string]*LogicalBridge)
+	for i, bp := range bps {
+		if _, ok := bpsMap[bp.Name]; ok {
+			errs[i] = ErrKeyExists
+			continue
+		}
+
+		// If Transparent Trunk then all the Logical Bridges are included by default
+		if bp.TransparentTrunk {
+			for lbName := range lbsMap {
+				bp.Spec.LogicalBridges = append(bp.Spec.LogicalBridges, lbName)
+			}
+		}
+
+		for _, lbName := range bp.Spec.LogicalBridges {
+			lb, ok := lbs[lbName]
+			if !ok {
+				lb = &LogicalBridge{}
+				prev := &LogicalBridge{}
+				found, err := infradb.client.Get(lbName, lb)
+				if err == nil && found {
+					_, err = infradb.client.Get(lbName, prev)
+				}
+				if err != nil {
+					logger.Error(err)
+					errs[i] = err
+					break
+				}
+				if !found {
+					logger.Debugw("CreateBPs(): The Logical Bridge has not been found", logging.Name(bp.Name), "logicalBridge", lbName)
+					errs[i] = ErrLogicalBridgeNotFound
+					break
+				}
+				lbs[lbName] = lb
+				prevLbs[lbName] = prev
+			}
+			bp.Vlans = append(bp.Vlans, &lb.Spec.VlanID)
+
+			// Store Bridge Port reference to the Logical Bridge object
+			if err := lb.AddBridgePort(bp.Name, bp.Spec.MacAddress.String()); err != nil {
+				logger.Errorw("CreateBPs(): Failed to add the Bridge Port to the Logical Bridge", logging.Name(bp.Name), "logicalBridge", lb.Name, "error", err)
+				errs[i] = err
+				break
+			}
+		}
+	}
+	if batchFailed(errs) {
+		return errs
+	}
+
+	txn := &storeTxn{}
+	for name, lb := range lbs {
+		if err := txn.update(name, lb, prevLbs[name]); err != nil {
+			logger.Error(err)
+			txn.rollback()
+			return batchError(errs, err)
+		}
+	}
+	for _, bp := range bps {
+		if err := txn.create(bp.Name, bp); err != nil {
+			logger.Error(err)
+			txn.rollback()
+			return batchError(errs, err)
+		}
+	}
+
+	prevBpsMap := make(map[string]bool, len(bpsMap))
+	for name := range bpsMap {
+		prevBpsMap[name] = false
+	}
+	for _, bp := range bps {
+		bpsMap[bp.Name] = false
+	}
+	if err := txn.update("bps", &bpsMap, &prevBpsMap); err != nil {
+		logger.Error(err)
+		txn.rollback()
+		return batchError(errs, err)
+	}
+
+	tasks := make([]taskmanager.TaskRequest, 0, len(bps))
+	for _, bp := range bps {
+		tasks = append(tasks, taskmanager.TaskRequest{Name: bp.Name, ObjectType: "bridge-port", ResourceVersion: bp.ResourceVersion, Subs: subscribers})
+	}
+	taskmanager.TaskMan.CreateTasks(tasks)
+
+	return errs
+}
+
+// DeleteBPs deletes a batch of bridge port objects on behalf of the deletedBy client.
+// Either all or none of the objects are marked for deletion.
+func DeleteBPs(names []string, deletedBy string) []error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	errs := make([]error, len(names))
+
+	subscribers := eventbus.EBus.GetSubscribers("bridge-port")
+	if len(subscribers) == 0 {
+		logger.Info("DeleteBPs(): No subscribers for Bridge Port objects")
+		return batchError(errs, errors.New("no subscribers found for bridge port"))
+	}
+
+	bps := make([]*BridgePort, len(names))
+	for i, name := range names {
+		bp := &BridgePort{}
+		found, err := infradb.client.Get(name, bp)
+		switch {
+		case err != nil:
+			logger.Error(err)
+			errs[i] = err
+		case !found:
+			errs[i] = ErrKeyNotFound
+		}
+		bps[i] = bp
+	}
+	if batchFailed(errs) {
+		return errs
+	}
+
+	txn := &storeTxn{}
+	tasks := make([]taskmanager.TaskRequest, 0, len(bps))
+	for _, bp := range bps {
+		prev := *bp
+		prevStatus := *bp.Status
+		prevStatus.Components = append([]common.Component{}, bp.Status.Components...)
+		prev.Status = &prevStatus
+		prevMeta := *bp.Metadata
+		prev.Metadata = &prevMeta
+
+		for i := range subscribers {
+			bp.Status.Components[i].CompStatus = common.ComponentStatusPending
+		}
+		bp.ResourceVersion = generateVersion()
+		bp.Status.BPOperStatus = BridgePortOperStatusToBeDeleted
+		bp.Metadata.Audit.DeletedBy = deletedBy
+
+		if err := txn.update(bp.Name, bp, &prev); err != nil {
+			logger.Error(err)
+			txn.rollback()
+			return batchError(errs, err)
+		}
+		tasks = append(tasks, taskmanager.TaskRequest{Name: bp.Name, ObjectType: "bridge-port", ResourceVersion: bp.ResourceVersion, Subs: subscribers})
+	}
+	taskmanager.TaskMan.CreateTasks(tasks)
+
+	return errs
+}
@@ -7,10 +7,8 @@ package infradb
 
 import (
 	// "encoding/binary"
-	"encoding/binary"
 	"errors"
 	"fmt"
-	"log"
 	"net"
 
 	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
@@ -48,12 +46,16 @@ type LogicalBridgeSpec struct {
 }
 
 // LogicalBridgeMetadata holds Logical Bridge Metadata
-type LogicalBridgeMetadata struct{}
+type LogicalBridgeMetadata struct {
+	Audit common.Audit
+}
 
 // LogicalBridge holds Logical Bridge info
 type LogicalBridge struct {
-	Name            string
-	Spec            *LogicalBridgeSpec
+	Name string
+	Spec *LogicalBridgeSpec
+	// PrevSpec holds the last realized spec while an update is being realized
+	PrevSpec        *LogicalBridgeSpec
 	Status          *LogicalBridgeStatus
 	Metadata        *LogicalBridgeMetadata
 	Svi             string
@@ -72,9 +74,12 @@ func NewLogicalBridge(in *pb.LogicalBridge) (*LogicalBridge, error) {
 
 	// Parse vtep IP
 	if in.Spec.VtepIpPrefix != nil {
-		vtepip := make(net.IP, 4)
-		binary.BigEndian.PutUint32(vtepip, in.Spec.VtepIpPrefix.Addr.GetV4Addr())
-		vip = &net.IPNet{IP: vtepip, Mask: net.CIDRMask(int(in.Spec.VtepIpPrefix.Len), 32)}
+		var err error
+		vip, err = common.ConvertToIPNet(in.Spec.VtepIpPrefix)
+		if err != nil {
+			logger.Infow("NewLogicalBridge(): Invalid vtep ip prefix", "error", err)
+			return &LogicalBridge{}, err
+		}
 	} else {
 		tmpVtepIP := utils.GetIPAddress(config.GlobalConfig.LinuxFrr.DefaultVtep)
 		vip = &tmpVtepIP
@@ -82,7 +87,7 @@ func NewLogicalBridge(in *pb.LogicalBridge) (*LogicalBridge, error) {
 
 	subscribers := eventbus.EBus.GetSubscribers("logical-bridge")
 	if len(subscribers) == 0 {
-		log.Println("NewLogicalBridge(): No subscribers for Logical Bridge objects")
+		logger.Info("NewLogicalBridge(): No subscribers for Logical Bridge objects")
 		return &LogicalBridge{}, errors.New("no subscribers found for logical bridge")
 	}
 
@@ -250,7 +255,7 @@ func (in *LogicalBridge) prepareObjectsForReplay(componentName string, lbSubs []
 	lbComponents := in.Status.Components
 	tempSubs := []*eventbus.Subscriber{}
 	for i, comp := range lbComponents {
-		if comp.Name == componentName || comp.CompStatus != common.ComponentStatusSuccess {
+		if componentName == allComponents || comp.Name == componentName || comp.CompStatus != common.ComponentStatusSuccess {
 			in.Status.Components[i] = common.Component{Name: comp.Name, CompStatus: common.ComponentStatusPending, Details: ""}
 			tempSubs = append(tempSubs, lbSubs[i])
 		}
@@ -259,6 +264,8 @@ func (in *LogicalBridge) prepareObjectsForReplay(componentName string, lbSubs []
 		in.Status.LBOperStatus = LogicalBridgeOperStatusDown
 	}
 
+	// The replayed components realize the object as a whole
+	in.PrevSpec = nil
 	in.ResourceVersion = generateVersion()
 	return tempSubs
 }
@@ -0,0 +1,150 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+
+	"github.com/philippgille/gokv"
+)
+
+// CacheStats holds the hit/miss counters of the infradb object cache
+type CacheStats struct {
+	Hits    uint64
+	Misses  uint64
+	Entries int
+}
+
+// cachedStore is a read-through cache in front of the backend store.
+// Every Set goes to the backend first and then refreshes the cached
+// copy, every Delete drops it. Values are kept in their encoded form
+// so that each Get hands out a fresh object that the caller can freely
+// modify without corrupting the cache.
+type cachedStore struct {
+	backend gokv.Store
+	mtx     sync.RWMutex
+	entries map[string][]byte
+	hits    atomic.Uint64
+	misses  atomic.Uint64
+}
+
+// build time check that struct implements interface
+var _ gokv.Store = (*cachedStore)(nil)
+
+// newCachedStore wraps a backend store with the read-through cache
+func newCachedStore(backend gokv.Store) *cachedStore {
+	return &cachedStore{
+		backend: backend,
+		entries: make(map[string][]byte),
+	}
+}
+
+// Set stores the value in the backend and refreshes the cached copy
+func (c *cachedStore) Set(k string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	if err := c.backend.Set(k, v); err != nil {
+		c.invalidate(k)
+		return err
+	}
+
+	c.mtx.Lock()
+	c.entries[k] = data
+	c.mtx.Unlock()
+
+	return nil
+}
+
+// Get returns the value from the cache and falls back to the backend on a miss
+func (c *cachedStore) Get(k string, v interface{}) (bool, error) {
+	c.mtx.RLock()
+	data, ok := c.entries[k]
+	c.mtx.RUnlock()
+
+	if ok {
+		c.hits.Add(1)
+		return true, json.Unmarshal(data, v)
+	}
+	c.misses.Add(1)
+
+	found, err := c.backend.Get(k, v)
+	if err != nil || !found {
+		return found, err
+	}
+
+	data, err = json.Marshal(v)
+	if err != nil {
+		// The object has been read fine, it just can not be cached
+		return true, nil
+	}
+
+	c.mtx.Lock()
+	c.entries[k] = data
+	c.mtx.Unlock()
+
+	return true, nil
+}
+
+// Delete removes the value from the backend and the cache
+func (c *cachedStore) Delete(k string) error {
+	c.invalidate(k)
+	err := c.backend.Delete(k)
+	// Drop again in case a concurrent read has repopulated the entry
+	c.invalidate(k)
+	return err
+}
+
+// Close flushes the cache and closes the backend store
+func (c *cachedStore) Close() error {
+	c.flush()
+	return c.backend.Close()
+}
+
+// invalidate drops a single entry from the cache
+func (c *cachedStore) invalidate(k string) {
+	c.mtx.Lock()
+	delete(c.entries, k)
+	c.mtx.Unlock()
+}
+
+// flush drops all the entries from the cache
+func (c *cachedStore) flush() {
+	c.mtx.Lock()
+	c.entries = make(map[string][]byte)
+	c.mtx.Unlock()
+}
+
+// stats returns a snapshot of the cache counters
+func (c *cachedStore) stats() CacheStats {
+	c.mtx.RLock()
+	entries := len(c.entries)
+	c.mtx.RUnlock()
+
+	return CacheStats{
+		Hits:    c.hits.Load(),
+		Misses:  c.misses.Load(),
+		Entries: entries,
+	}
+}
+
+// GetCacheStats returns the hit/miss counters of the infradb object cache
+func GetCacheStats() CacheStats {
+	if infradb == nil || infradb.cache == nil {
+		return CacheStats{}
+	}
+	return infradb.cache.stats()
+}
+
+// CountCacheReads returns the hits, the misses and the number of entries of the object cache
+// for the metrics collector
+func CountCacheReads() (uint64, uint64, int) {
+	stats := GetCacheStats()
+	return stats.Hits, stats.Misses, stats.Entries
+}
@@ -0,0 +1,100 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/philippgille/gokv/gomap"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/metrics"
+)
+
+func TestCachedStore_ReadThrough(t *testing.T) {
+	backend := gomap.NewStore(gomap.DefaultOptions)
+	vni := uint32(100)
+	assert.NoError(t, backend.Set("lb1", &LogicalBridge{Name: "lb1", Spec: &LogicalBridgeSpec{VlanID: 10, Vni: &vni}}))
+
+	cache := newCachedStore(backend)
+
+	lb := LogicalBridge{}
+	found, err := cache.Get("lb1", &lb)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, uint32(10), lb.Spec.VlanID)
+	assert.Equal(t, CacheStats{Hits: 0, Misses: 1, Entries: 1}, cache.stats())
+
+	lb = LogicalBridge{}
+	found, err = cache.Get("lb1", &lb)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, vni, *lb.Spec.Vni)
+	assert.Equal(t, CacheStats{Hits: 1, Misses: 1, Entries: 1}, cache.stats())
+}
+
+func TestCachedStore_CopiesAreIndependent(t *testing.T) {
+	cache := newCachedStore(gomap.NewStore(gomap.DefaultOptions))
+	assert.NoError(t, cache.Set("lb1", &LogicalBridge{Name: "lb1", Spec: &LogicalBridgeSpec{VlanID: 10}}))
+
+	lb := LogicalBridge{}
+	_, err := cache.Get("lb1", &lb)
+	assert.NoError(t, err)
+	lb.Spec.VlanID = 20
+
+	again := LogicalBridge{}
+	_, err = cache.Get("lb1", &again)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(10), again.Spec.VlanID, "Expected the cached object to be unaffected by caller changes")
+}
+
+func TestCachedStore_Invalidation(t *testing.T) {
+	backend := gomap.NewStore(gomap.DefaultOptions)
+	cache := newCachedStore(backend)
+
+	assert.NoError(t, cache.Set("lb1", &LogicalBridge{Name: "lb1", Spec: &LogicalBridgeSpec{VlanID: 10}}))
+	assert.NoError(t, cache.Set("lb1", &LogicalBridge{Name: "lb1", Spec: &LogicalBridgeSpec{VlanID: 30}}))
+
+	lb := LogicalBridge{}
+	found, err := cache.Get("lb1", &lb)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, uint32(30), lb.Spec.VlanID, "Expected update to refresh the cached copy")
+
+	assert.NoError(t, cache.Delete("lb1"))
+	found, err = cache.Get("lb1", &lb)
+	assert.NoError(t, err)
+	assert.False(t, found, "Expected deleted object not to be served from the cache")
+
+	found, err = backend.Get("lb1", &lb)
+	assert.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestCacheCollector(t *testing.T) {
+	assert.NoError(t, NewInfraDB("", "gomap"))
+	assert.NoError(t, infradb.client.Set("lb1", &LogicalBridge{Name: "lb1", Spec: &LogicalBridgeSpec{VlanID: 10}}))
+	lb := LogicalBridge{}
+	for i := 0; i < 3; i++ {
+		_, err := infradb.client.Get("lb2", &lb)
+		assert.NoError(t, err)
+		_, err = infradb.client.Get("lb1", &lb)
+		assert.NoError(t, err)
+	}
+
+	expected := `
+# HELP opi_evpn_bridge_infradb_cache_hits_total Number of infradb object reads served by the cache.
+# TYPE opi_evpn_bridge_infradb_cache_hits_total counter
+opi_evpn_bridge_infradb_cache_hits_total 3
+# HELP opi_evpn_bridge_infradb_cache_misses_total Number of infradb object reads that went to the store.
+# TYPE opi_evpn_bridge_infradb_cache_misses_total counter
+opi_evpn_bridge_infradb_cache_misses_total 3
+`
+	collector := metrics.NewCacheCollector(CountCacheReads)
+	assert.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(expected),
+		"opi_evpn_bridge_infradb_cache_hits_total", "opi_evpn_bridge_infradb_cache_misses_total"))
+}
@@ -6,6 +6,9 @@
 package common
 
 import (
+	"encoding/binary"
+	"errors"
+	"fmt"
 	"net"
 	"reflect"
 	"time"
@@ -38,6 +41,24 @@ type Component struct {
 	Replay bool
 }
 
+// SystemIdentity is recorded for the mutations that the bridge does on its own
+const SystemIdentity = "system"
+
+// Audit holds the identities of the clients that have mutated an object
+type Audit struct {
+	CreatedBy string
+	UpdatedBy string
+	DeletedBy string
+}
+
+// LastMutatedBy returns the client that has created or updated the object last
+func (a *Audit) LastMutatedBy() string {
+	if a.UpdatedBy != "" {
+		return a.UpdatedBy
+	}
+	return a.CreatedBy
+}
+
 // CheckReplayThreshold checks if the replay threshold has been exceeded
 func (c *Component) CheckReplayThreshold(replayThreshold time.Duration) {
 	c.Replay = (c.Timer > replayThreshold)
@@ -57,6 +78,17 @@ func ConvertToIPPrefix(ipNet *net.IPNet) *pc.IPPrefix {
 	}
 
 	maskLen, _ := ipNet.Mask.Size()
+	if ipNet.IP.To4() == nil {
+		return &pc.IPPrefix{
+			Addr: &pc.IPAddress{
+				Af: pc.IpAf_IP_AF_INET6,
+				V4OrV6: &pc.IPAddress_V6Addr{
+					V6Addr: append([]byte{}, ipNet.IP.To16()...),
+				},
+			},
+			Len: int32(maskLen),
+		}
+	}
 	return &pc.IPPrefix{
 		Addr: &pc.IPAddress{
 			Af: pc.IpAf_IP_AF_INET,
@@ -67,3 +99,43 @@ func ConvertToIPPrefix(ipNet *net.IPNet) *pc.IPPrefix {
 		Len: int32(maskLen),
 	}
 }
+
+// ConvertToIPNet converts IPPrefix type to IPNet.
+// The address family is taken from the Af field and, when that is unspecified, from the address itself.
+// A prefix without an address is an IPv4 prefix of the unspecified address.
+func ConvertToIPNet(prefix *pc.IPPrefix) (*net.IPNet, error) {
+	if prefix == nil {
+		return nil, errors.New("missing ip prefix")
+	}
+
+	af := prefix.GetAddr().GetAf()
+	if af == pc.IpAf_IP_AF_UNSPECIFIED {
+		af = pc.IpAf_IP_AF_INET
+		if _, ok := prefix.GetAddr().GetV4OrV6().(*pc.IPAddress_V6Addr); ok {
+			af = pc.IpAf_IP_AF_INET6
+		}
+	}
+
+	switch af {
+	case pc.IpAf_IP_AF_INET:
+		if prefix.Len < 0 || prefix.Len > 32 {
+			return nil, fmt.Errorf("invalid ipv4 prefix length %d", prefix.Len)
+		}
+		ip := make(net.IP, net.IPv4len)
+		binary.BigEndian.PutUint32(ip, prefix.GetAddr().GetV4Addr())
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(int(prefix.Len), 32)}, nil
+	case pc.IpAf_IP_AF_INET6:
+		addr := prefix.GetAddr().GetV6Addr()
+		if len(addr) != net.IPv6len {
+			return nil, fmt.Errorf("invalid ipv6 address length %d", len(addr))
+		}
+		if prefix.Len < 0 || prefix.Len > 128 {
+			return nil, fmt.Errorf("invalid ipv6 prefix length %d", prefix.Len)
+		}
+		ip := make(net.IP, net.IPv6len)
+		copy(ip, addr)
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(int(prefix.Len), 128)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported address family %v", af)
+	}
+}
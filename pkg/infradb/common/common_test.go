@@ -45,6 +45,78 @@ func TestConvertToIPPrefix_ValidIPv6(t *testing.T) {
 	result := ConvertToIPPrefix(ipNet)
 
 	assert.NotNil(t, result, "Expected non-nil result")
-	assert.Equal(t, pc.IpAf_IP_AF_INET, result.Addr.Af, "Expected IPv4 family for V4 input")
+	assert.Equal(t, pc.IpAf_IP_AF_INET6, result.Addr.Af, "Expected IPv6 address family")
 	assert.Equal(t, int32(64), result.Len, "Expected prefix length of 64")
+	assert.Equal(t, []byte(net.ParseIP("2001:db8::")), result.Addr.GetV6Addr(), "Expected correct V6 address conversion")
+}
+
+func TestConvertToIPNet(t *testing.T) {
+	tests := map[string]struct {
+		in      *pc.IPPrefix
+		out     string
+		wantErr bool
+	}{
+		"ipv4": {
+			in: &pc.IPPrefix{
+				Addr: &pc.IPAddress{Af: pc.IpAf_IP_AF_INET, V4OrV6: &pc.IPAddress_V4Addr{V4Addr: 167772162}},
+				Len:  24,
+			},
+			out: "10.0.0.2/24",
+		},
+		"ipv6": {
+			in: &pc.IPPrefix{
+				Addr: &pc.IPAddress{Af: pc.IpAf_IP_AF_INET6, V4OrV6: &pc.IPAddress_V6Addr{V6Addr: net.ParseIP("2001:db8::1")}},
+				Len:  64,
+			},
+			out: "2001:db8::1/64",
+		},
+		"ipv6 without address family": {
+			in: &pc.IPPrefix{
+				Addr: &pc.IPAddress{V4OrV6: &pc.IPAddress_V6Addr{V6Addr: net.ParseIP("2001:db8::1")}},
+				Len:  128,
+			},
+			out: "2001:db8::1/128",
+		},
+		"ipv6 short address": {
+			in: &pc.IPPrefix{
+				Addr: &pc.IPAddress{Af: pc.IpAf_IP_AF_INET6, V4OrV6: &pc.IPAddress_V6Addr{V6Addr: []byte{0x20, 0x01}}},
+				Len:  64,
+			},
+			wantErr: true,
+		},
+		"ipv4 prefix too long": {
+			in: &pc.IPPrefix{
+				Addr: &pc.IPAddress{Af: pc.IpAf_IP_AF_INET, V4OrV6: &pc.IPAddress_V4Addr{V4Addr: 167772162}},
+				Len:  64,
+			},
+			wantErr: true,
+		},
+		"missing address": {
+			in:  &pc.IPPrefix{Len: 24},
+			out: "0.0.0.0/24",
+		},
+		"missing prefix": {
+			in:      nil,
+			wantErr: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ipNet, err := ConvertToIPNet(tt.in)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.out, ipNet.String())
+
+			// converting back gives the original prefix
+			prefix := ConvertToIPPrefix(ipNet)
+			assert.Equal(t, tt.in.Len, prefix.Len)
+			if tt.in.GetAddr().GetAf() != pc.IpAf_IP_AF_UNSPECIFIED {
+				assert.Equal(t, tt.in.Addr.Af, prefix.Addr.Af)
+			}
+		})
+	}
 }
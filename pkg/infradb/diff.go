@@ -0,0 +1,133 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"bytes"
+	"net"
+)
+
+// Names of the spec fields that can change in an update
+const (
+	// FieldVtepIP the VTEP IP of a Logical Bridge or a VRF
+	FieldVtepIP = "VtepIP"
+	// FieldLoopbackIP the loopback IP of a VRF
+	FieldLoopbackIP = "LoopbackIP"
+	// FieldMacAddress the MAC address of an SVI
+	FieldMacAddress = "MacAddress"
+	// FieldGatewayIPs the gateway IPs of an SVI
+	FieldGatewayIPs = "GatewayIPs"
+	// FieldEnableBgp the BGP enablement of an SVI
+	FieldEnableBgp = "EnableBgp"
+	// FieldRemoteAs the remote AS of an SVI
+	FieldRemoteAs = "RemoteAs"
+)
+
+// SpecDiff holds the spec fields that an update has changed.
+// A nil SpecDiff means that the object is not being updated,
+// so the subscribers have to realize the whole object.
+type SpecDiff map[string]bool
+
+// Has checks if the field has changed
+func (d SpecDiff) Has(field string) bool {
+	return d[field]
+}
+
+// Changes returns the spec fields that have changed since the last realized spec
+// or nil if the Logical Bridge is not being updated
+func (in *LogicalBridge) Changes() SpecDiff {
+	if in.PrevSpec == nil {
+		return nil
+	}
+	diff := SpecDiff{}
+	if !ipNetEqual(in.PrevSpec.VtepIP, in.Spec.VtepIP) {
+		diff[FieldVtepIP] = true
+	}
+	return diff
+}
+
+// Changes returns the spec fields that have changed since the last realized spec
+// or nil if the VRF is not being updated
+func (in *Vrf) Changes() SpecDiff {
+	if in.PrevSpec == nil {
+		return nil
+	}
+	diff := SpecDiff{}
+	if !ipNetEqual(in.PrevSpec.LoopbackIP, in.Spec.LoopbackIP) {
+		diff[FieldLoopbackIP] = true
+	}
+	if !ipNetEqual(in.PrevSpec.VtepIP, in.Spec.VtepIP) {
+		diff[FieldVtepIP] = true
+	}
+	return diff
+}
+
+// Changes returns the spec fields that have changed since the last realized spec
+// or nil if the SVI is not being updated
+func (in *Svi) Changes() SpecDiff {
+	if in.PrevSpec == nil {
+		return nil
+	}
+	diff := SpecDiff{}
+	if !macEqual(in.PrevSpec.MacAddress, in.Spec.MacAddress) {
+		diff[FieldMacAddress] = true
+	}
+	added, removed := DiffIPNets(in.PrevSpec.GatewayIPs, in.Spec.GatewayIPs)
+	if len(added) != 0 || len(removed) != 0 {
+		diff[FieldGatewayIPs] = true
+	}
+	if in.PrevSpec.EnableBgp != in.Spec.EnableBgp {
+		diff[FieldEnableBgp] = true
+	}
+	if !uint32PtrEqual(in.PrevSpec.RemoteAs, in.Spec.RemoteAs) {
+		diff[FieldRemoteAs] = true
+	}
+	return diff
+}
+
+// DiffIPNets returns the addresses that have been added and removed between the old and the new list
+func DiffIPNets(oldIPs, newIPs []*net.IPNet) (added, removed []*net.IPNet) {
+	for _, newIP := range newIPs {
+		if !containsIPNet(oldIPs, newIP) {
+			added = append(added, newIP)
+		}
+	}
+	for _, oldIP := range oldIPs {
+		if !containsIPNet(newIPs, oldIP) {
+			removed = append(removed, oldIP)
+		}
+	}
+	return added, removed
+}
+
+func containsIPNet(ipNets []*net.IPNet, ipNet *net.IPNet) bool {
+	for _, i := range ipNets {
+		if ipNetEqual(i, ipNet) {
+			return true
+		}
+	}
+	return false
+}
+
+func ipNetEqual(a, b *net.IPNet) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.IP.Equal(b.IP) && bytes.Equal(a.Mask, b.Mask)
+}
+
+func macEqual(a, b *net.HardwareAddr) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return bytes.Equal(*a, *b)
+}
+
+func uint32PtrEqual(a, b *uint32) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,103 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func mustParseCIDR(t *testing.T, cidr string) *net.IPNet {
+	ip, ipNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ipNet.IP = ip
+	return ipNet
+}
+
+func TestSvi_Changes(t *testing.T) {
+	mac := net.HardwareAddr{0xCB, 0xB8, 0x33, 0x4C, 0x88, 0x4F}
+	otherMac := net.HardwareAddr{0xCB, 0xB8, 0x33, 0x4C, 0x88, 0x50}
+	remoteAs := uint32(65000)
+	otherRemoteAs := uint32(65001)
+	spec := func() *SviSpec {
+		return &SviSpec{
+			MacAddress: &mac,
+			GatewayIPs: []*net.IPNet{mustParseCIDR(t, "10.0.0.1/24")},
+			RemoteAs:   &remoteAs,
+		}
+	}
+
+	tests := map[string]struct {
+		update func(s *SviSpec)
+		prev   bool
+		out    SpecDiff
+	}{
+		"not an update": {
+			update: func(_ *SviSpec) {},
+			prev:   false,
+			out:    nil,
+		},
+		"no changes": {
+			update: func(_ *SviSpec) {},
+			prev:   true,
+			out:    SpecDiff{},
+		},
+		"mac address": {
+			update: func(s *SviSpec) { s.MacAddress = &otherMac },
+			prev:   true,
+			out:    SpecDiff{FieldMacAddress: true},
+		},
+		"gateway ips": {
+			update: func(s *SviSpec) { s.GatewayIPs = append(s.GatewayIPs, mustParseCIDR(t, "2001:db8::1/64")) },
+			prev:   true,
+			out:    SpecDiff{FieldGatewayIPs: true},
+		},
+		"bgp": {
+			update: func(s *SviSpec) {
+				s.EnableBgp = true
+				s.RemoteAs = &otherRemoteAs
+			},
+			prev: true,
+			out:  SpecDiff{FieldEnableBgp: true, FieldRemoteAs: true},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			svi := &Svi{Spec: spec()}
+			tt.update(svi.Spec)
+			if tt.prev {
+				svi.PrevSpec = spec()
+			}
+			assert.Equal(t, tt.out, svi.Changes())
+		})
+	}
+}
+
+func TestDiffIPNets(t *testing.T) {
+	oldIPs := []*net.IPNet{mustParseCIDR(t, "10.0.0.1/24"), mustParseCIDR(t, "2001:db8::1/64")}
+	newIPs := []*net.IPNet{mustParseCIDR(t, "10.0.0.1/24"), mustParseCIDR(t, "2001:db8::2/64")}
+
+	added, removed := DiffIPNets(oldIPs, newIPs)
+	assert.Equal(t, []*net.IPNet{newIPs[1]}, added)
+	assert.Equal(t, []*net.IPNet{oldIPs[1]}, removed)
+
+	added, removed = DiffIPNets(oldIPs, oldIPs)
+	assert.Empty(t, added)
+	assert.Empty(t, removed)
+}
+
+func TestRealizedSpec(t *testing.T) {
+	prev := &VrfSpec{}
+	spec := &VrfSpec{}
+
+	assert.Same(t, prev, realizedSpec(prev, spec, false), "Expected the spec of the update in progress")
+	assert.Same(t, spec, realizedSpec(nil, spec, true), "Expected the realized spec")
+	assert.Nil(t, realizedSpec(nil, spec, false), "Expected no spec while the object is created")
+}
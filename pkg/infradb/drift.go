@@ -0,0 +1,150 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/taskmanager"
+	"github.com/opiproject/opi-evpn-bridge/pkg/logging"
+)
+
+// driftPrefix starts the part of the component details that reports the drift from the kernel
+const driftPrefix = "drift: "
+
+// withDrift returns the details of a component with the drift, if any, in place of the previous one
+func withDrift(details, drift string) string {
+	if i := strings.Index(details, driftPrefix); i >= 0 {
+		details = strings.TrimSuffix(details[:i], "\n")
+	}
+	if drift == "" {
+		return details
+	}
+	if details == "" {
+		return driftPrefix + drift
+	}
+	return details + "\n" + driftPrefix + drift
+}
+
+// reportDrift records the drift in the details of the component, which is set in pending state
+// for remediation. It returns false when there is nothing to record, i.e. the component
+// has not realized the object successfully or the same drift has already been reported.
+func reportDrift(components []common.Component, componentName, drift string, remediate bool) bool {
+	for i, comp := range components {
+		if comp.Name != componentName {
+			continue
+		}
+		if comp.CompStatus != common.ComponentStatusSuccess {
+			return false
+		}
+		details := withDrift(comp.Details, drift)
+		if remediate && drift != "" {
+			components[i] = common.Component{Name: comp.Name, CompStatus: common.ComponentStatusPending, Details: details}
+			return true
+		}
+		if details == comp.Details {
+			return false
+		}
+		components[i].Details = details
+		return true
+	}
+	return false
+}
+
+// ReportDrift records the drift of a realized object from the kernel, as found by the component,
+// in the details of the component status, an empty drift clears the one reported before.
+// With remediate the component is asked to realize the object again.
+// Nothing is recorded if the object has changed since it has been checked
+// or if the component has not realized it successfully.
+//
+// nolint: funlen
+func ReportDrift(objectType, name, resourceVersion, componentName, drift string, remediate bool) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	var (
+		object     interface{}
+		version    string
+		components []common.Component
+		// setDown sets the oper status down while the object is realized again
+		setDown func()
+		found   bool
+		err     error
+	)
+	switch objectType {
+	case "vrf":
+		vrf := &Vrf{}
+		found, err = infradb.client.Get(name, vrf)
+		object, version, components = vrf, vrf.ResourceVersion, vrf.Status.Components
+		setDown = func() {
+			if vrf.Status.VrfOperStatus == VrfOperStatusUp {
+				vrf.Status.VrfOperStatus = VrfOperStatusDown
+			}
+		}
+	case "logical-bridge":
+		lb := &LogicalBridge{}
+		found, err = infradb.client.Get(name, lb)
+		object, version, components = lb, lb.ResourceVersion, lb.Status.Components
+		setDown = func() {
+			if lb.Status.LBOperStatus == LogicalBridgeOperStatusUp {
+				lb.Status.LBOperStatus = LogicalBridgeOperStatusDown
+			}
+		}
+	case "svi":
+		svi := &Svi{}
+		found, err = infradb.client.Get(name, svi)
+		object, version, components = svi, svi.ResourceVersion, svi.Status.Components
+		setDown = func() {
+			if svi.Status.SviOperStatus == SviOperStatusUp {
+				svi.Status.SviOperStatus = SviOperStatusDown
+			}
+		}
+	case "bridge-port":
+		bp := &BridgePort{}
+		found, err = infradb.client.Get(name, bp)
+		object, version, components = bp, bp.ResourceVersion, bp.Status.Components
+		setDown = func() {
+			if bp.Status.BPOperStatus == BridgePortOperStatusUp {
+				bp.Status.BPOperStatus = BridgePortOperStatusDown
+			}
+		}
+	default:
+		return fmt.Errorf("unknown object type %s", objectType)
+	}
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+	if !found || version != resourceVersion {
+		return nil
+	}
+	if !reportDrift(components, componentName, drift, remediate) {
+		return nil
+	}
+
+	remediating := remediate && drift != ""
+	if remediating {
+		setDown()
+	}
+	if err := infradb.client.Set(name, object); err != nil {
+		logger.Error(err)
+		return err
+	}
+	ulog := logger.With(logging.Kind(objectType), logging.Name(name), logging.ResourceVersion(version), logging.Component(componentName))
+	if !remediating {
+		ulog.Infow("ReportDrift(): The drift has been recorded", "drift", drift)
+		return nil
+	}
+	for _, sub := range eventbus.EBus.GetSubscribers(objectType) {
+		if sub.Name == componentName {
+			ulog.Infow("ReportDrift(): The object is realized again", "drift", drift)
+			taskmanager.TaskMan.CreateTask(name, objectType, version, []*eventbus.Subscriber{sub})
+		}
+	}
+	return nil
+}
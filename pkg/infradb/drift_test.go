@@ -0,0 +1,77 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
+)
+
+func TestWithDrift(t *testing.T) {
+	tests := map[string]struct {
+		details string
+		drift   string
+		out     string
+	}{
+		"no details":         {drift: "link vxlan-10 not found", out: "drift: link vxlan-10 not found"},
+		"details kept":       {details: `{"routingtable":"1000"}`, drift: "mtu 1400", out: "{\"routingtable\":\"1000\"}\ndrift: mtu 1400"},
+		"drift replaced":     {details: "{\"routingtable\":\"1000\"}\ndrift: mtu 1400", drift: "mtu 1300", out: "{\"routingtable\":\"1000\"}\ndrift: mtu 1300"},
+		"drift cleared":      {details: "{\"routingtable\":\"1000\"}\ndrift: mtu 1400", out: `{"routingtable":"1000"}`},
+		"only drift cleared": {details: "drift: mtu 1400", out: ""},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.out, withDrift(tt.details, tt.drift))
+		})
+	}
+}
+
+func TestReportDrift(t *testing.T) {
+	tests := map[string]struct {
+		status    common.ComponentStatus
+		details   string
+		drift     string
+		remediate bool
+		recorded  bool
+		out       common.Component
+	}{
+		"drift recorded": {
+			status: common.ComponentStatusSuccess, drift: "link vxlan-10 not found", recorded: true,
+			out: common.Component{Name: "lgm", CompStatus: common.ComponentStatusSuccess, Details: "drift: link vxlan-10 not found"},
+		},
+		"same drift": {
+			status: common.ComponentStatusSuccess, details: "drift: link vxlan-10 not found", drift: "link vxlan-10 not found",
+			out: common.Component{Name: "lgm", CompStatus: common.ComponentStatusSuccess, Details: "drift: link vxlan-10 not found"},
+		},
+		"drift cleared": {
+			status: common.ComponentStatusSuccess, details: "drift: link vxlan-10 not found", recorded: true,
+			out: common.Component{Name: "lgm", CompStatus: common.ComponentStatusSuccess},
+		},
+		"remediated": {
+			status: common.ComponentStatusSuccess, drift: "link vxlan-10 not found", remediate: true, recorded: true,
+			out: common.Component{Name: "lgm", CompStatus: common.ComponentStatusPending, Details: "drift: link vxlan-10 not found"},
+		},
+		"not realized": {
+			status: common.ComponentStatusError, details: "failed", drift: "link vxlan-10 not found", remediate: true,
+			out: common.Component{Name: "lgm", CompStatus: common.ComponentStatusError, Details: "failed"},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			components := []common.Component{
+				{Name: "lgm", CompStatus: tt.status, Details: tt.details},
+				{Name: "frr", CompStatus: common.ComponentStatusSuccess},
+			}
+			assert.Equal(t, tt.recorded, reportDrift(components, "lgm", tt.drift, tt.remediate))
+			assert.Equal(t, tt.out, components[0])
+			assert.Equal(t, common.Component{Name: "frr", CompStatus: common.ComponentStatusSuccess}, components[1])
+		})
+	}
+}
@@ -7,23 +7,29 @@ package infradb
 
 import (
 	"errors"
-	"log"
 	"sync"
 	"time"
 
+	"github.com/opiproject/opi-evpn-bridge/pkg/audit"
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/taskmanager"
+	"github.com/opiproject/opi-evpn-bridge/pkg/logging"
 	"github.com/opiproject/opi-evpn-bridge/pkg/storage"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
 	"github.com/philippgille/gokv"
 )
 
+// logger is the logger of the db subsystem
+var logger = logging.Logger(logging.DB).Sugar()
+
 var infradb *InfraDB
 var globalLock sync.Mutex
 
 // InfraDB structure
 type InfraDB struct {
 	client gokv.Store
+	cache  *cachedStore
 }
 
 var (
@@ -43,6 +49,12 @@ var (
 	ErrRoutingTableInUse = errors.New("the routing table is already in use")
 	// ErrVniInUse vni is in use
 	ErrVniInUse = errors.New("the VNI is already in use")
+	// ErrKeyExists key already exists
+	ErrKeyExists = errors.New("key already exists")
+	// ErrImmutableField an update has tried to change a field that cannot be changed
+	ErrImmutableField = errors.New("the field cannot be changed")
+	// ErrUpdateToBeDeleted an update has been requested for an object that is being deleted
+	ErrUpdateToBeDeleted = errors.New("the object is being deleted")
 	// Add more error constants as needed
 )
 
@@ -50,12 +62,16 @@ var (
 func NewInfraDB(address string, dbtype string) error {
 	store, err := storage.NewStore(dbtype, address)
 	if err != nil {
-		log.Println(err)
+		logger.Error(err)
 		return err
 	}
 
+	// All the reads and writes go through the cache so that hot
+	// objects are served from memory instead of the backend store
+	cache := newCachedStore(store.GetClient())
 	infradb = &InfraDB{
-		client: store.GetClient(),
+		client: cache,
+		cache:  cache,
 	}
 	return nil
 }
@@ -65,6 +81,63 @@ func Close() error {
 	return infradb.client.Close()
 }
 
+// Ping checks that the backend store is reachable, the reads bypass the cache
+func Ping() error {
+	if infradb == nil {
+		return errors.New("infradb is not initialized")
+	}
+	var lbs map[string]bool
+	_, err := infradb.cache.backend.Get("lbs", &lbs)
+	return err
+}
+
+// operStatusNames names the oper status values, which are the same for all the kinds of objects
+var operStatusNames = map[int32]string{
+	int32(LogicalBridgeOperStatusUnspecified): "unspecified",
+	int32(LogicalBridgeOperStatusUp):          "up",
+	int32(LogicalBridgeOperStatusDown):        "down",
+	int32(LogicalBridgeOperStatusToBeDeleted): "to_be_deleted",
+}
+
+// CountObjects returns the number of objects in the store by kind and oper status
+func CountObjects() (map[string]map[string]int, error) {
+	counts := map[string]map[string]int{
+		"logical-bridge": {},
+		"bridge-port":    {},
+		"vrf":            {},
+		"svi":            {},
+	}
+	lbs, err := GetAllLBs()
+	if err != nil && !errors.Is(err, ErrKeyNotFound) {
+		return nil, err
+	}
+	for _, lb := range lbs {
+		counts["logical-bridge"][operStatusNames[int32(lb.Status.LBOperStatus)]]++
+	}
+	bps, err := GetAllBPs()
+	if err != nil && !errors.Is(err, ErrKeyNotFound) {
+		return nil, err
+	}
+	for _, bp := range bps {
+		counts["bridge-port"][operStatusNames[int32(bp.Status.BPOperStatus)]]++
+	}
+	vrfs, err := GetAllVrfs()
+	if err != nil && !errors.Is(err, ErrKeyNotFound) {
+		return nil, err
+	}
+	for _, vrf := range vrfs {
+		counts["vrf"][operStatusNames[int32(vrf.Status.VrfOperStatus)]]++
+	}
+	svis, err := GetAllSvis()
+	if err != nil && !errors.Is(err, ErrKeyNotFound) {
+		return nil, err
+	}
+	for _, svi := range svis {
+		counts["svi"][operStatusNames[int32(svi.Status.SviOperStatus)]]++
+	}
+	return counts, nil
+}
+
 // CreateLB creates an infradb logical bridge object
 func CreateLB(lb *LogicalBridge) error {
 	globalLock.Lock()
@@ -74,17 +147,17 @@ func CreateLB(lb *LogicalBridge) error {
 
 	subscribers := eventbus.EBus.GetSubscribers("logical-bridge")
 	if len(subscribers) == 0 {
-		log.Println("CreateLB(): No subscribers for Logical Bridge objects")
+		logger.Info("CreateLB(): No subscribers for Logical Bridge objects")
 		return errors.New("no subscribers found for logical bridge")
 	}
 
-	log.Printf("CreateLB(): Create Logical Bridge: %+v\n", lb)
+	logger.Debugw("CreateLB(): Create Logical Bridge", logging.Object("logical-bridge", lb.Name, lb.ResourceVersion)...)
 
 	// Check if VNI is already used
 	if lb.Spec.Vni != nil {
 		found, err := infradb.client.Get("vpns", &vpns)
 		if err != nil {
-			log.Println(err)
+			logger.Error(err)
 			return err
 		}
 
@@ -93,7 +166,7 @@ func CreateLB(lb *LogicalBridge) error {
 		} else {
 			_, ok := vpns[*lb.Spec.Vni]
 			if ok {
-				log.Printf("CreateLB(): VNI already in use: %+v\n", lb.Spec.Vni)
+				logger.Debugw("CreateLB(): VNI already in use", logging.Name(lb.Name), "vni", *lb.Spec.Vni)
 				return ErrVniInUse
 			}
 			vpns[*lb.Spec.Vni] = false
@@ -102,7 +175,7 @@ func CreateLB(lb *LogicalBridge) error {
 
 	err := infradb.client.Set(lb.Name, lb)
 	if err != nil {
-		log.Println(err)
+		logger.Error(err)
 		return err
 	}
 
@@ -110,7 +183,7 @@ func CreateLB(lb *LogicalBridge) error {
 	if lb.Spec.Vni != nil {
 		err = infradb.client.Set("vpns", &vpns)
 		if err != nil {
-			log.Println(err)
+			logger.Error(err)
 			return err
 		}
 	}
@@ -119,7 +192,7 @@ func CreateLB(lb *LogicalBridge) error {
 	lbs := make(map[string]bool)
 	_, err = infradb.client.Get("lbs", &lbs)
 	if err != nil {
-		log.Println(err)
+		logger.Error(err)
 		return err
 	}
 	// The reason that we use a map and not a list is
@@ -129,7 +202,7 @@ func CreateLB(lb *LogicalBridge) error {
 	lbs[lb.Name] = false
 	err = infradb.client.Set("lbs", &lbs)
 	if err != nil {
-		log.Println(err)
+		logger.Error(err)
 		return err
 	}
 
@@ -138,21 +211,21 @@ func CreateLB(lb *LogicalBridge) error {
 	return nil
 }
 
-// DeleteLB deletes a logical bridge infradb object
-func DeleteLB(name string) error {
+// DeleteLB deletes a logical bridge infradb object on behalf of the deletedBy client
+func DeleteLB(name string, deletedBy string) error {
 	globalLock.Lock()
 	defer globalLock.Unlock()
 
 	subscribers := eventbus.EBus.GetSubscribers("logical-bridge")
 	if len(subscribers) == 0 {
-		log.Println("DeleteLB(): No subscribers for Logical Bridge objects")
+		logger.Info("DeleteLB(): No subscribers for Logical Bridge objects")
 		return errors.New("no subscribers found for logical bridge")
 	}
 
 	lb := LogicalBridge{}
 	found, err := infradb.client.Get(name, &lb)
 	if err != nil {
-		log.Println(err)
+		logger.Error(err)
 		return err
 	}
 	if !found {
@@ -160,12 +233,12 @@ func DeleteLB(name string) error {
 	}
 
 	if lb.Svi != "" {
-		log.Printf("DeleteLB(): Can not delete Logical Bridge %+v. Associated with SVI interfaces", lb.Name)
+		logger.Debugw("DeleteLB(): Can not delete Logical Bridge. Associated with SVI interfaces", logging.Name(lb.Name))
 		return ErrLogicalBridgeNotEmpty
 	}
 
 	if len(lb.BridgePorts) != 0 || len(lb.MacTable) != 0 {
-		log.Printf("DeleteLB(): Can not delete Logical Bridge %+v. Associated with Bridge Ports", lb.Name)
+		logger.Debugw("DeleteLB(): Can not delete Logical Bridge. Associated with Bridge Ports", logging.Name(lb.Name))
 		return ErrLogicalBridgeNotEmpty
 	}
 
@@ -174,6 +247,7 @@ func DeleteLB(name string) error {
 	}
 	lb.ResourceVersion = generateVersion()
 	lb.Status.LBOperStatus = LogicalBridgeOperStatusToBeDeleted
+	lb.Metadata.Audit.DeletedBy = deletedBy
 
 	err = infradb.client.Set(lb.Name, lb)
 	if err != nil {
@@ -209,12 +283,12 @@ func GetAllLBs() ([]*LogicalBridge, error) {
 	found, err := infradb.client.Get("lbs", &lbsMap)
 
 	if err != nil {
-		log.Println(err)
+		logger.Error(err)
 		return nil, err
 	}
 
 	if !found {
-		log.Println("GetAllLogicalBridges(): No Logical Bridges have been found")
+		logger.Debug("GetAllLogicalBridges(): No Logical Bridges have been found")
 		return nil, ErrKeyNotFound
 	}
 
@@ -223,12 +297,12 @@ func GetAllLBs() ([]*LogicalBridge, error) {
 		found, err := infradb.client.Get(key, lb)
 
 		if err != nil {
-			log.Printf("GetAllLogicalBridges(): Failed to get the Logical Bridge %s from store: %v", key, err)
+			logger.Errorw("GetAllLogicalBridges(): Failed to get the Logical Bridge from store", logging.Name(key), "error", err)
 			return nil, err
 		}
 
 		if !found {
-			log.Printf("GetAllLogicalBridges():Logical Bridge %s not found", key)
+			logger.Warnw("GetAllLogicalBridges(): Logical Bridge not found", logging.Name(key))
 			return nil, ErrKeyNotFound
 		}
 		lbs = append(lbs, lb)
@@ -244,13 +318,40 @@ func UpdateLB(lb *LogicalBridge) error {
 
 	subscribers := eventbus.EBus.GetSubscribers("logical-bridge")
 	if len(subscribers) == 0 {
-		log.Println("UpdateLB(): No subscribers for Logical Bridge objects")
+		logger.Info("UpdateLB(): No subscribers for Logical Bridge objects")
 		return errors.New("no subscribers found for logical bridge")
 	}
 
-	err := infradb.client.Set(lb.Name, lb)
+	oldLB := LogicalBridge{}
+	found, err := infradb.client.Get(lb.Name, &oldLB)
 	if err != nil {
-		log.Println(err)
+		logger.Error(err)
+		return err
+	}
+	if !found {
+		logger.Infow("UpdateLB(): Logical Bridge does not exist", logging.Name(lb.Name))
+		return ErrKeyNotFound
+	}
+	if oldLB.Status.LBOperStatus == LogicalBridgeOperStatusToBeDeleted {
+		logger.Infow("UpdateLB(): Logical Bridge is being deleted", logging.Name(lb.Name))
+		return ErrUpdateToBeDeleted
+	}
+	if !uint32PtrEqual(oldLB.Spec.Vni, lb.Spec.Vni) || oldLB.Spec.VlanID != lb.Spec.VlanID {
+		logger.Errorw("UpdateLB(): The VNI and the VLAN of Logical Bridge cannot be changed", logging.Name(lb.Name))
+		return ErrImmutableField
+	}
+
+	// The relations and the metadata are not part of the spec so they are kept
+	lb.BridgePorts = oldLB.BridgePorts
+	lb.MacTable = oldLB.MacTable
+	lb.Svi = oldLB.Svi
+	oldLB.Metadata.Audit.UpdatedBy = lb.Metadata.Audit.UpdatedBy
+	lb.Metadata = oldLB.Metadata
+	lb.PrevSpec = realizedSpec(oldLB.PrevSpec, oldLB.Spec, oldLB.checkForAllSuccess())
+
+	err = infradb.client.Set(lb.Name, lb)
+	if err != nil {
+		logger.Error(err)
 		return err
 	}
 
@@ -258,6 +359,21 @@ func UpdateLB(lb *LogicalBridge) error {
 
 	return nil
 }
+
+// realizedSpec returns the spec that the subscribers have realized before an update:
+// the spec of an update that is still being realized, or the current spec if it has been realized.
+// It returns nil when the object is still being created so that the subscribers realize it as a whole.
+func realizedSpec[T any](prevSpec, spec *T, realized bool) *T {
+	switch {
+	case prevSpec != nil:
+		return prevSpec
+	case realized:
+		return spec
+	default:
+		return nil
+	}
+}
+
 func removeVniFromVpns(vni uint32) error {
 	vpns := make(map[uint32]bool)
 	if vni != 0 {
@@ -286,6 +402,9 @@ func UpdateLBStatus(name string, resourceVersion string, notificationID string,
 	globalLock.Lock()
 	defer globalLock.Unlock()
 
+	ulog := logger.With(logging.Kind("logical-bridge"), logging.Name(name), logging.ResourceVersion(resourceVersion),
+		logging.NotificationID(notificationID), logging.Component(component.Name))
+
 	var allCompSuccess bool
 
 	// When we get an error from an operation to the Database then we just return it. The
@@ -293,28 +412,28 @@ func UpdateLBStatus(name string, resourceVersion string, notificationID string,
 	lb := LogicalBridge{}
 	found, err := infradb.client.Get(name, &lb)
 	if err != nil {
-		log.Println(err)
+		logger.Error(err)
 		return err
 	}
 
 	if !found {
 		// No Logical Bridge object has been found in the database so we will instruct TaskManager to drop the Task that is related with this status update.
 		taskmanager.TaskMan.StatusUpdated(name, "logical-bridge", lb.ResourceVersion, notificationID, true, &component)
-		log.Printf("UpdateLBStatus(): No Logical Bridge object has been found in DB with Name %s\n", name)
+		ulog.Warn("UpdateLBStatus(): No Logical Bridge object has been found in DB")
 		return nil
 	}
 
 	if lb.ResourceVersion != resourceVersion {
 		// Logical Bridge object in the database with different resourceVersion so we will instruct TaskManager to drop the Task that is related with this status update.
 		taskmanager.TaskMan.StatusUpdated(lb.Name, "logical-bridge", lb.ResourceVersion, notificationID, true, &component)
-		log.Printf("UpdateLBStatus(): Invalid resourceVersion %s for Logical Bridge %+v\n", resourceVersion, lb)
+		ulog.Infow("UpdateLBStatus(): Invalid resourceVersion", "currentResourceVersion", lb.ResourceVersion)
 		return nil
 	}
 
 	if component.Replay {
 		// One of the components has requested a replay of the DB.
 		// The task related to the status update will be dropped.
-		log.Printf("UpdateLBStatus(): Component %s has requested a replay\n", component.Name)
+		ulog.Info("UpdateLBStatus(): Component has requested a replay")
 		taskmanager.TaskMan.StatusUpdated(lb.Name, "logical-bridge", lb.ResourceVersion, notificationID, true, &component)
 		go startReplayProcedure(component.Name)
 		return nil
@@ -334,7 +453,7 @@ func UpdateLBStatus(name string, resourceVersion string, notificationID string,
 		if lb.Status.LBOperStatus == LogicalBridgeOperStatusToBeDeleted {
 			err = infradb.client.Delete(lb.Name)
 			if err != nil {
-				log.Println(err)
+				logger.Error(err)
 				return err
 			}
 
@@ -349,38 +468,41 @@ func UpdateLBStatus(name string, resourceVersion string, notificationID string,
 			lbs := make(map[string]bool)
 			found, err = infradb.client.Get("lbs", &lbs)
 			if err != nil {
-				log.Println(err)
+				logger.Error(err)
 				return err
 			}
 			if !found {
-				log.Println("UpdateLBStatus(): No Logical Bridges have been found")
+				logger.Debug("UpdateLBStatus(): No Logical Bridges have been found")
 				return ErrKeyNotFound
 			}
 
 			delete(lbs, lb.Name)
 			err = infradb.client.Set("lbs", &lbs)
 			if err != nil {
-				log.Println(err)
+				logger.Error(err)
 				return err
 			}
 
-			log.Printf("UpdateLBStatus(): Logical Bridge %s has been deleted\n", name)
+			ulog.Info("UpdateLBStatus(): Logical Bridge has been deleted")
+			audit.Realization(audit.ActionRemoved, "logical-bridge", name, lb.Metadata.Audit.DeletedBy, lb.ResourceVersion, nil)
 		} else {
 			lb.Status.LBOperStatus = LogicalBridgeOperStatusUp
+			lb.PrevSpec = nil
 			err = infradb.client.Set(lb.Name, lb)
 			if err != nil {
-				log.Println(err)
+				logger.Error(err)
 				return err
 			}
-			log.Printf("UpdateLBStatus(): Logical Bridge %s has been updated: %+v\n", name, lb)
+			ulog.Info("UpdateLBStatus(): Logical Bridge has been realized")
+			audit.Realization(audit.ActionRealized, "logical-bridge", name, lb.Metadata.Audit.LastMutatedBy(), lb.ResourceVersion, nil)
 		}
 	} else {
 		err = infradb.client.Set(lb.Name, lb)
 		if err != nil {
-			log.Println(err)
+			logger.Error(err)
 			return err
 		}
-		log.Printf("UpdateLBStatus(): Logical Bridge %s has been updated: %+v\n", name, lb)
+		ulog.Debugw("UpdateLBStatus(): Logical Bridge has been updated", "status", component.CompStatus)
 	}
 
 	taskmanager.TaskMan.StatusUpdated(lb.Name, "logical-bridge", lb.ResourceVersion, notificationID, false, &component)
@@ -395,7 +517,7 @@ func CreateBP(bp *BridgePort) error {
 
 	subscribers := eventbus.EBus.GetSubscribers("bridge-port")
 	if len(subscribers) == 0 {
-		log.Println("CreateBP(): No subscribers for Bridge Port objects")
+		logger.Info("CreateBP(): No subscribers for Bridge Port objects")
 		return errors.New("no subscribers found for bridge port")
 	}
 
@@ -403,18 +525,18 @@ func CreateBP(bp *BridgePort) error {
 	// The way to do this is to create a MAP of MACs and store it in the DB
 	// and then check if MAC exist in this MAP everytime a new BP gets created.
 
-	log.Printf("CreateBP(): Create Bridge Port: %+v\n", bp)
+	logger.Debugw("CreateBP(): Create Bridge Port", logging.Object("bridge-port", bp.Name, bp.ResourceVersion)...)
 
 	// If Transparent Trunk then all the Logical Bridges are included by default
 	if bp.TransparentTrunk {
 		lbs := make(map[string]bool)
 		found, err := infradb.client.Get("lbs", &lbs)
 		if err != nil {
-			log.Println(err)
+			logger.Error(err)
 			return err
 		}
 		if !found {
-			log.Println("CreateBP(): No Logical Bridges have been found")
+			logger.Debug("CreateBP(): No Logical Bridges have been found")
 			return ErrKeyNotFound
 		}
 
@@ -430,11 +552,11 @@ func CreateBP(bp *BridgePort) error {
 		lb := LogicalBridge{}
 		found, err := infradb.client.Get(lbName, &lb)
 		if err != nil {
-			log.Println(err)
+			logger.Error(err)
 			return err
 		}
 		if !found {
-			log.Printf("CreateBP(): The Logical Bridge with name %+v has not been found\n", lbName)
+			logger.Debugw("CreateBP(): The Logical Bridge has not been found", logging.Name(bp.Name), "logicalBridge", lbName)
 			return ErrLogicalBridgeNotFound
 		}
 		bp.Vlans = append(bp.Vlans, &lb.Spec.VlanID)
@@ -442,14 +564,14 @@ func CreateBP(bp *BridgePort) error {
 		// Store Bridge Port reference to the Logical Bridge object
 		err = lb.AddBridgePort(bp.Name, bp.Spec.MacAddress.String())
 		if err != nil {
-			log.Printf("CreateBP(): Error: %+v", err)
+			logger.Errorw("CreateBP(): Failed to add the Bridge Port to the Logical Bridge", logging.Name(bp.Name), "logicalBridge", lb.Name, "error", err)
 			return err
 		}
 
 		// Save Logical Bridge object back to DB
 		err = infradb.client.Set(lb.Name, lb)
 		if err != nil {
-			log.Println(err)
+			logger.Error(err)
 			return err
 		}
 	}
@@ -457,7 +579,7 @@ func CreateBP(bp *BridgePort) error {
 	// Store Bridge Port object to Database
 	err := infradb.client.Set(bp.Name, bp)
 	if err != nil {
-		log.Println(err)
+		logger.Error(err)
 		return err
 	}
 
@@ -465,7 +587,7 @@ func CreateBP(bp *BridgePort) error {
 	bps := make(map[string]bool)
 	_, err = infradb.client.Get("bps", &bps)
 	if err != nil {
-		log.Println(err)
+		logger.Error(err)
 		return err
 	}
 	// The reason that we use a map and not a list is
@@ -475,7 +597,7 @@ func CreateBP(bp *BridgePort) error {
 	bps[bp.Name] = false
 	err = infradb.client.Set("bps", &bps)
 	if err != nil {
-		log.Println(err)
+		logger.Error(err)
 		return err
 	}
 
@@ -484,14 +606,14 @@ func CreateBP(bp *BridgePort) error {
 	return nil
 }
 
-// DeleteBP deletes a bridge port infradb object
-func DeleteBP(name string) error {
+// DeleteBP deletes a bridge port infradb object on behalf of the deletedBy client
+func DeleteBP(name string, deletedBy string) error {
 	globalLock.Lock()
 	defer globalLock.Unlock()
 
 	subscribers := eventbus.EBus.GetSubscribers("bridge-port")
 	if len(subscribers) == 0 {
-		log.Println("DeleteBP(): No subscribers for Bridge Port objects")
+		logger.Info("DeleteBP(): No subscribers for Bridge Port objects")
 		return errors.New("no subscribers found for bridge port")
 	}
 
@@ -509,6 +631,7 @@ func DeleteBP(name string) error {
 	}
 	bp.ResourceVersion = generateVersion()
 	bp.Status.BPOperStatus = BridgePortOperStatusToBeDeleted
+	bp.Metadata.Audit.DeletedBy = deletedBy
 
 	err = infradb.client.Set(bp.Name, bp)
 	if err != nil {
@@ -544,12 +667,12 @@ func GetAllBPs() ([]*BridgePort, error) {
 	found, err := infradb.client.Get("bps", &bpsMap)
 
 	if err != nil {
-		log.Println(err)
+		logger.Error(err)
 		return nil, err
 	}
 
 	if !found {
-		log.Println("GetAllBPs(): No Bridge Ports have been found")
+		logger.Debug("GetAllBPs(): No Bridge Ports have been found")
 		return nil, ErrKeyNotFound
 	}
 
@@ -558,12 +681,12 @@ func GetAllBPs() ([]*BridgePort, error) {
 		found, err := infradb.client.Get(key, bp)
 
 		if err != nil {
-			log.Printf("GetAllBPs(): Failed to get the Bridge Port %s from store: %v", key, err)
+			logger.Errorw("GetAllBPs(): Failed to get the Bridge Port from store", logging.Name(key), "error", err)
 			return nil, err
 		}
 
 		if !found {
-			log.Printf("GetAllBPs(): Bridge Port %s not found", key)
+			logger.Warnw("GetAllBPs(): Bridge Port not found", logging.Name(key))
 			return nil, ErrKeyNotFound
 		}
 		bps = append(bps, bp)
@@ -582,13 +705,36 @@ func UpdateBP(bp *BridgePort) error {
 
 	subscribers := eventbus.EBus.GetSubscribers("bridge-port")
 	if len(subscribers) == 0 {
-		log.Println("UpdateBP(): No subscribers for Bridge Port objects")
+		logger.Info("UpdateBP(): No subscribers for Bridge Port objects")
 		return errors.New("no subscribers found for bridge port")
 	}
 
-	err := infradb.client.Set(bp.Name, bp)
+	oldBP := BridgePort{}
+	found, err := infradb.client.Get(bp.Name, &oldBP)
 	if err != nil {
-		log.Println(err)
+		logger.Error(err)
+		return err
+	}
+	if !found {
+		logger.Infow("UpdateBP(): Bridge Port does not exist", logging.Name(bp.Name))
+		return ErrKeyNotFound
+	}
+	if oldBP.Status.BPOperStatus == BridgePortOperStatusToBeDeleted {
+		logger.Infow("UpdateBP(): Bridge Port is being deleted", logging.Name(bp.Name))
+		return ErrUpdateToBeDeleted
+	}
+
+	// The metadata, as the VPort reported by the vendor module, is not part of the spec so it is kept
+	if oldBP.Metadata != nil {
+		if bp.Metadata != nil {
+			oldBP.Metadata.Audit.UpdatedBy = bp.Metadata.Audit.UpdatedBy
+		}
+		bp.Metadata = oldBP.Metadata
+	}
+
+	err = infradb.client.Set(bp.Name, bp)
+	if err != nil {
+		logger.Error(err)
 		return err
 	}
 
@@ -605,6 +751,9 @@ func UpdateBPStatus(name string, resourceVersion string, notificationID string,
 	globalLock.Lock()
 	defer globalLock.Unlock()
 
+	ulog := logger.With(logging.Kind("bridge-port"), logging.Name(name), logging.ResourceVersion(resourceVersion),
+		logging.NotificationID(notificationID), logging.Component(component.Name))
+
 	var allCompSuccess bool
 
 	// When we get an error from an operation to the Database then we just return it. The
@@ -612,28 +761,28 @@ func UpdateBPStatus(name string, resourceVersion string, notificationID string,
 	bp := BridgePort{}
 	found, err := infradb.client.Get(name, &bp)
 	if err != nil {
-		log.Println(err)
+		logger.Error(err)
 		return err
 	}
 
 	if !found {
 		// No Bridge Port object has been found in the database so we will instruct TaskManager to drop the Task that is related with this status update.
 		taskmanager.TaskMan.StatusUpdated(name, "bridge-port", bp.ResourceVersion, notificationID, true, &component)
-		log.Printf("UpdateBPStatus(): No Bridge Port object has been found in DB with Name %s\n", name)
+		ulog.Warn("UpdateBPStatus(): No Bridge Port object has been found in DB")
 		return nil
 	}
 
 	if bp.ResourceVersion != resourceVersion {
 		// Bridge Port object in the database with different resourceVersion so we will instruct TaskManager to drop the Task that is related with this status update.
 		taskmanager.TaskMan.StatusUpdated(bp.Name, "bridge-port", bp.ResourceVersion, notificationID, true, &component)
-		log.Printf("UpdateBPStatus(): Invalid resourceVersion %s for Bridge Port %+v\n", resourceVersion, bp)
+		ulog.Infow("UpdateBPStatus(): Invalid resourceVersion", "currentResourceVersion", bp.ResourceVersion)
 		return nil
 	}
 
 	if component.Replay {
 		// One of the components has requested a replay of the DB.
 		// The task related to the status update will be dropped.
-		log.Printf("UpdateBPStatus(): Component %s has requested a replay\n", component.Name)
+		ulog.Info("UpdateBPStatus(): Component has requested a replay")
 		taskmanager.TaskMan.StatusUpdated(bp.Name, "bridge-port", bp.ResourceVersion, notificationID, true, &component)
 		go startReplayProcedure(component.Name)
 		return nil
@@ -657,21 +806,21 @@ func UpdateBPStatus(name string, resourceVersion string, notificationID string,
 				lb := LogicalBridge{}
 				_, err := infradb.client.Get(lbName, &lb)
 				if err != nil {
-					log.Println(err)
+					logger.Error(err)
 					return err
 				}
 
 				// Store Bridge Port reference to the Logical Bridge object
 				err = lb.DeleteBridgePort(bp.Name, bp.Spec.MacAddress.String())
 				if err != nil {
-					log.Printf("UpdateBPStatus(): Error: %+v", err)
+					ulog.Errorw("UpdateBPStatus(): Failed to remove the Bridge Port from the Logical Bridge", "logicalBridge", lb.Name, "error", err)
 					return err
 				}
 
 				// Save Logical Bridge object back to DB
 				err = infradb.client.Set(lb.Name, lb)
 				if err != nil {
-					log.Println(err)
+					logger.Error(err)
 					return err
 				}
 			}
@@ -679,7 +828,7 @@ func UpdateBPStatus(name string, resourceVersion string, notificationID string,
 			// Delete the Bridge Port object from the DB
 			err = infradb.client.Delete(bp.Name)
 			if err != nil {
-				log.Println(err)
+				logger.Error(err)
 				return err
 			}
 
@@ -687,38 +836,40 @@ func UpdateBPStatus(name string, resourceVersion string, notificationID string,
 			bps := make(map[string]bool)
 			found, err = infradb.client.Get("bps", &bps)
 			if err != nil {
-				log.Println(err)
+				logger.Error(err)
 				return err
 			}
 			if !found {
-				log.Println("UpdateBPStatus(): No Bridge Ports have been found")
+				logger.Debug("UpdateBPStatus(): No Bridge Ports have been found")
 				return ErrKeyNotFound
 			}
 
 			delete(bps, bp.Name)
 			err = infradb.client.Set("bps", &bps)
 			if err != nil {
-				log.Println(err)
+				logger.Error(err)
 				return err
 			}
 
-			log.Printf("UpdateBPStatus(): Bridge Port %s has been deleted\n", name)
+			ulog.Info("UpdateBPStatus(): Bridge Port has been deleted")
+			audit.Realization(audit.ActionRemoved, "bridge-port", name, bp.Metadata.Audit.DeletedBy, bp.ResourceVersion, nil)
 		} else {
 			bp.Status.BPOperStatus = BridgePortOperStatusUp
 			err = infradb.client.Set(bp.Name, bp)
 			if err != nil {
-				log.Println(err)
+				logger.Error(err)
 				return err
 			}
-			log.Printf("UpdateBPStatus(): Bridge Port %s has been updated: %+v\n", name, bp)
+			ulog.Info("UpdateBPStatus(): Bridge Port has been realized")
+			audit.Realization(audit.ActionRealized, "bridge-port", name, bp.Metadata.Audit.LastMutatedBy(), bp.ResourceVersion, nil)
 		}
 	} else {
 		err = infradb.client.Set(bp.Name, bp)
 		if err != nil {
-			log.Println(err)
+			logger.Error(err)
 			return err
 		}
-		log.Printf("UpdateBPStatus(): Bridge Port %s has been updated: %+v\n", name, bp)
+		ulog.Debugw("UpdateBPStatus(): Bridge Port has been updated", "status", component.CompStatus)
 	}
 
 	taskmanager.TaskMan.StatusUpdated(bp.Name, "bridge-port", bp.ResourceVersion, notificationID, false, &component)
@@ -735,11 +886,11 @@ func CreateVrf(vrf *Vrf) error {
 
 	subscribers := eventbus.EBus.GetSubscribers("vrf")
 	if len(subscribers) == 0 {
-		log.Println("CreateVrf(): No subscribers for Vrf objects")
+		logger.Info("CreateVrf(): No subscribers for Vrf objects")
 		return errors.New("no subscribers found for vrf")
 	}
 
-	log.Printf("CreateVrf(): Create Vrf: %+v\n", vrf)
+	logger.Debugw("CreateVrf(): Create Vrf", logging.Object("vrf", vrf.Name, vrf.ResourceVersion)...)
 
 	// TODO: Move the check for VNI in a common place
 	// and use that comomn code also for checking the LB vni
@@ -747,7 +898,7 @@ func CreateVrf(vrf *Vrf) error {
 	if vrf.Spec.Vni != nil {
 		found, err := infradb.client.Get("vpns", &vpns)
 		if err != nil {
-			log.Println(err)
+			logger.Error(err)
 			return err
 		}
 
@@ -756,7 +907,7 @@ func CreateVrf(vrf *Vrf) error {
 		} else {
 			_, ok := vpns[*vrf.Spec.Vni]
 			if ok {
-				log.Printf("CreateVrf(): VNI already in use: %+v\n", *vrf.Spec.Vni)
+				logger.Debugw("CreateVrf(): VNI already in use", logging.Name(vrf.Name), "vni", *vrf.Spec.Vni)
 				return ErrVniInUse
 			}
 			vpns[*vrf.Spec.Vni] = false
@@ -765,7 +916,7 @@ func CreateVrf(vrf *Vrf) error {
 
 	err := infradb.client.Set(vrf.Name, vrf)
 	if err != nil {
-		log.Println(err)
+		logger.Error(err)
 		return err
 	}
 
@@ -773,7 +924,7 @@ func CreateVrf(vrf *Vrf) error {
 	if vrf.Spec.Vni != nil {
 		err = infradb.client.Set("vpns", &vpns)
 		if err != nil {
-			log.Println(err)
+			logger.Error(err)
 			return err
 		}
 	}
@@ -782,7 +933,7 @@ func CreateVrf(vrf *Vrf) error {
 	vrfs := make(map[string]bool)
 	_, err = infradb.client.Get("vrfs", &vrfs)
 	if err != nil {
-		log.Println(err)
+		logger.Error(err)
 		return err
 	}
 	// The reason that we use a map and not a list is
@@ -792,7 +943,7 @@ func CreateVrf(vrf *Vrf) error {
 	vrfs[vrf.Name] = false
 	err = infradb.client.Set("vrfs", &vrfs)
 	if err != nil {
-		log.Println(err)
+		logger.Error(err)
 		return err
 	}
 
@@ -801,14 +952,14 @@ func CreateVrf(vrf *Vrf) error {
 	return nil
 }
 
-// DeleteVrf deletes a vrf infradb object
-func DeleteVrf(name string) error {
+// DeleteVrf deletes a vrf infradb object on behalf of the deletedBy client
+func DeleteVrf(name string, deletedBy string) error {
 	globalLock.Lock()
 	defer globalLock.Unlock()
 
 	subscribers := eventbus.EBus.GetSubscribers("vrf")
 	if len(subscribers) == 0 {
-		log.Println("DeleteVrf(): No subscribers for Vrf objects")
+		logger.Info("DeleteVrf(): No subscribers for Vrf objects")
 		return errors.New("no subscribers found for vrf")
 	}
 
@@ -822,7 +973,7 @@ func DeleteVrf(name string) error {
 	}
 
 	if len(vrf.Svis) != 0 {
-		log.Printf("DeleteVrf(): Can not delete VRF %+v. Associated with SVI interfaces", vrf.Name)
+		logger.Debugw("DeleteVrf(): Can not delete VRF. Associated with SVI interfaces", logging.Name(vrf.Name))
 		return ErrVrfNotEmpty
 	}
 
@@ -831,6 +982,7 @@ func DeleteVrf(name string) error {
 	}
 	vrf.ResourceVersion = generateVersion()
 	vrf.Status.VrfOperStatus = VrfOperStatusToBeDeleted
+	vrf.Metadata.Audit.DeletedBy = deletedBy
 
 	err = infradb.client.Set(vrf.Name, vrf)
 	if err != nil {
@@ -866,12 +1018,12 @@ func GetAllVrfs() ([]*Vrf, error) {
 	found, err := infradb.client.Get("vrfs", &vrfsMap)
 
 	if err != nil {
-		log.Println(err)
+		logger.Error(err)
 		return nil, err
 	}
 
 	if !found {
-		log.Println("GetAllVrfs(): No VRFs have been found")
+		logger.Debug("GetAllVrfs(): No VRFs have been found")
 		return nil, ErrKeyNotFound
 	}
 
@@ -880,12 +1032,12 @@ func GetAllVrfs() ([]*Vrf, error) {
 		found, err := infradb.client.Get(key, vrf)
 
 		if err != nil {
-			log.Printf("GetAllVrfs(): Failed to get the VRF %s from store: %v", key, err)
+			logger.Errorw("GetAllVrfs(): Failed to get the VRF from store", logging.Name(key), "error", err)
 			return nil, err
 		}
 
 		if !found {
-			log.Printf("GetAllVrfs(): VRF %s not found", key)
+			logger.Warnw("GetAllVrfs(): VRF not found", logging.Name(key))
 			return nil, ErrKeyNotFound
 		}
 		vrfs = append(vrfs, vrf)
@@ -901,13 +1053,38 @@ func UpdateVrf(vrf *Vrf) error {
 
 	subscribers := eventbus.EBus.GetSubscribers("vrf")
 	if len(subscribers) == 0 {
-		log.Println("CreateVrf(): No subscribers for Vrf objects")
+		logger.Info("UpdateVrf(): No subscribers for Vrf objects")
 		return errors.New("no subscribers found for vrf")
 	}
 
-	err := infradb.client.Set(vrf.Name, vrf)
+	oldVrf := Vrf{}
+	found, err := infradb.client.Get(vrf.Name, &oldVrf)
 	if err != nil {
-		log.Println(err)
+		logger.Error(err)
+		return err
+	}
+	if !found {
+		logger.Infow("UpdateVrf(): VRF does not exist", logging.Name(vrf.Name))
+		return ErrKeyNotFound
+	}
+	if oldVrf.Status.VrfOperStatus == VrfOperStatusToBeDeleted {
+		logger.Infow("UpdateVrf(): VRF is being deleted", logging.Name(vrf.Name))
+		return ErrUpdateToBeDeleted
+	}
+	if !uint32PtrEqual(oldVrf.Spec.Vni, vrf.Spec.Vni) {
+		logger.Errorw("UpdateVrf(): The VNI of VRF cannot be changed", logging.Name(vrf.Name))
+		return ErrImmutableField
+	}
+
+	// The relations and the metadata are not part of the spec so they are kept
+	vrf.Svis = oldVrf.Svis
+	oldVrf.Metadata.Audit.UpdatedBy = vrf.Metadata.Audit.UpdatedBy
+	vrf.Metadata = oldVrf.Metadata
+	vrf.PrevSpec = realizedSpec(oldVrf.PrevSpec, oldVrf.Spec, oldVrf.checkForAllSuccess())
+
+	err = infradb.client.Set(vrf.Name, vrf)
+	if err != nil {
+		logger.Error(err)
 		return err
 	}
 
@@ -922,6 +1099,9 @@ func UpdateVrfStatus(name string, resourceVersion string, notificationID string,
 	globalLock.Lock()
 	defer globalLock.Unlock()
 
+	ulog := logger.With(logging.Kind("vrf"), logging.Name(name), logging.ResourceVersion(resourceVersion),
+		logging.NotificationID(notificationID), logging.Component(component.Name))
+
 	var allCompSuccess bool
 
 	// When we get an error from an operation to the Database then we just return it. The
@@ -929,21 +1109,21 @@ func UpdateVrfStatus(name string, resourceVersion string, notificationID string,
 	vrf := Vrf{}
 	found, err := infradb.client.Get(name, &vrf)
 	if err != nil {
-		log.Println(err)
+		logger.Error(err)
 		return err
 	}
 
 	if !found {
 		// No VRF object has been found in the database so we will instruct TaskManager to drop the Task that is related with this status update.
 		taskmanager.TaskMan.StatusUpdated(name, "vrf", vrf.ResourceVersion, notificationID, true, &component)
-		log.Printf("UpdateVrfStatus(): No VRF object has been found in DB with Name %s\n", name)
+		ulog.Warn("UpdateVrfStatus(): No VRF object has been found in DB")
 		return nil
 	}
 
 	if vrf.ResourceVersion != resourceVersion {
 		// VRF object in the database with different resourceVersion so we will instruct TaskManager to drop the Task that is related with this status update.
 		taskmanager.TaskMan.StatusUpdated(vrf.Name, "vrf", vrf.ResourceVersion, notificationID, true, &component)
-		log.Printf("UpdateVrfStatus(): Invalid resourceVersion %s for VRF %+v\n", resourceVersion, vrf)
+		ulog.Infow("UpdateVrfStatus(): Invalid resourceVersion", "currentResourceVersion", vrf.ResourceVersion)
 		return nil
 	}
 
@@ -951,7 +1131,7 @@ func UpdateVrfStatus(name string, resourceVersion string, notificationID string,
 	if component.Replay {
 		// One of the components has requested a replay of the DB.
 		// The task related to the status update will be dropped.
-		log.Printf("UpdateVrfStatus(): Component %s has requested a replay\n", component.Name)
+		ulog.Info("UpdateVrfStatus(): Component has requested a replay")
 		taskmanager.TaskMan.StatusUpdated(vrf.Name, "vrf", vrf.ResourceVersion, notificationID, true, &component)
 		go startReplayProcedure(component.Name)
 		return nil
@@ -971,7 +1151,7 @@ func UpdateVrfStatus(name string, resourceVersion string, notificationID string,
 		if vrf.Status.VrfOperStatus == VrfOperStatusToBeDeleted {
 			err = infradb.client.Delete(vrf.Name)
 			if err != nil {
-				log.Println(err)
+				logger.Error(err)
 				return err
 			}
 
@@ -987,38 +1167,41 @@ func UpdateVrfStatus(name string, resourceVersion string, notificationID string,
 			vrfs := make(map[string]bool)
 			found, err = infradb.client.Get("vrfs", &vrfs)
 			if err != nil {
-				log.Println(err)
+				logger.Error(err)
 				return err
 			}
 			if !found {
-				log.Println("UpdateVrfStatus(): No VRFs have been found")
+				logger.Debug("UpdateVrfStatus(): No VRFs have been found")
 				return ErrKeyNotFound
 			}
 
 			delete(vrfs, vrf.Name)
 			err = infradb.client.Set("vrfs", &vrfs)
 			if err != nil {
-				log.Println(err)
+				logger.Error(err)
 				return err
 			}
 
-			log.Printf("UpdateVrfStatus(): VRF %s has been deleted\n", name)
+			ulog.Info("UpdateVrfStatus(): VRF has been deleted")
+			audit.Realization(audit.ActionRemoved, "vrf", name, vrf.Metadata.Audit.DeletedBy, vrf.ResourceVersion, nil)
 		} else {
 			vrf.Status.VrfOperStatus = VrfOperStatusUp
+			vrf.PrevSpec = nil
 			err = infradb.client.Set(vrf.Name, vrf)
 			if err != nil {
-				log.Println(err)
+				logger.Error(err)
 				return err
 			}
-			log.Printf("UpdateVrfStatus(): VRF %s has been updated: %+v\n", name, vrf)
+			ulog.Info("UpdateVrfStatus(): VRF has been realized")
+			audit.Realization(audit.ActionRealized, "vrf", name, vrf.Metadata.Audit.LastMutatedBy(), vrf.ResourceVersion, nil)
 		}
 	} else {
 		err = infradb.client.Set(vrf.Name, vrf)
 		if err != nil {
-			log.Println(err)
+			logger.Error(err)
 			return err
 		}
-		log.Printf("UpdateVrfStatus(): VRF %s has been updated: %+v\n", name, vrf)
+		ulog.Debugw("UpdateVrfStatus(): VRF has been updated", "status", component.CompStatus)
 	}
 
 	taskmanager.TaskMan.StatusUpdated(vrf.Name, "vrf", vrf.ResourceVersion, notificationID, false, &component)
@@ -1033,21 +1216,21 @@ func CreateSvi(svi *Svi) error {
 
 	subscribers := eventbus.EBus.GetSubscribers("svi")
 	if len(subscribers) == 0 {
-		log.Println("CreateSvi(): No subscribers for SVI objects")
+		logger.Info("CreateSvi(): No subscribers for SVI objects")
 		return errors.New("no subscribers found for svi")
 	}
 
-	log.Printf("CreateSvi(): Create SVI: %+v\n", svi)
+	logger.Debugw("CreateSvi(): Create SVI", logging.Object("svi", svi.Name, svi.ResourceVersion)...)
 
 	// Checking if the VRF exists
 	vrf := Vrf{}
 	found, err := infradb.client.Get(svi.Spec.Vrf, &vrf)
 	if err != nil {
-		log.Println(err)
+		logger.Error(err)
 		return err
 	}
 	if !found {
-		log.Printf("CreateSvi(): The VRF with name %+v has not been found\n", svi.Spec.Vrf)
+		logger.Debugw("CreateSvi(): The VRF has not been found", logging.Name(svi.Name), "vrf", svi.Spec.Vrf)
 		return ErrVrfNotFound
 	}
 
@@ -1055,42 +1238,42 @@ func CreateSvi(svi *Svi) error {
 	lb := LogicalBridge{}
 	found, err = infradb.client.Get(svi.Spec.LogicalBridge, &lb)
 	if err != nil {
-		log.Println(err)
+		logger.Error(err)
 		return err
 	}
 	if !found {
-		log.Printf("CreateSvi(): The Logical Bridge with name %+v has not been found\n", svi.Spec.LogicalBridge)
+		logger.Debugw("CreateSvi(): The Logical Bridge has not been found", logging.Name(svi.Name), "logicalBridge", svi.Spec.LogicalBridge)
 		return ErrLogicalBridgeNotFound
 	}
 
 	// Store svi reference to the VRF object
 	if err := vrf.AddSvi(svi.Name); err != nil {
-		log.Println(err)
+		logger.Error(err)
 		return err
 	}
 
 	err = infradb.client.Set(vrf.Name, vrf)
 	if err != nil {
-		log.Println(err)
+		logger.Error(err)
 		return err
 	}
 
 	// Store svi reference to the Logical Bridge object
 	if err := lb.AddSvi(svi.Name); err != nil {
-		log.Println(err)
+		logger.Error(err)
 		return err
 	}
 
 	err = infradb.client.Set(lb.Name, lb)
 	if err != nil {
-		log.Println(err)
+		logger.Error(err)
 		return err
 	}
 
 	// Store SVI object to Database
 	err = infradb.client.Set(svi.Name, svi)
 	if err != nil {
-		log.Println(err)
+		logger.Error(err)
 		return err
 	}
 
@@ -1098,7 +1281,7 @@ func CreateSvi(svi *Svi) error {
 	svis := make(map[string]bool)
 	_, err = infradb.client.Get("svis", &svis)
 	if err != nil {
-		log.Println(err)
+		logger.Error(err)
 		return err
 	}
 	// The reason that we use a map and not a list is
@@ -1108,7 +1291,7 @@ func CreateSvi(svi *Svi) error {
 	svis[svi.Name] = false
 	err = infradb.client.Set("svis", &svis)
 	if err != nil {
-		log.Println(err)
+		logger.Error(err)
 		return err
 	}
 
@@ -1117,14 +1300,14 @@ func CreateSvi(svi *Svi) error {
 	return nil
 }
 
-// DeleteSvi deletes a svi infradb object
-func DeleteSvi(name string) error {
+// DeleteSvi deletes a svi infradb object on behalf of the deletedBy client
+func DeleteSvi(name string, deletedBy string) error {
 	globalLock.Lock()
 	defer globalLock.Unlock()
 
 	subscribers := eventbus.EBus.GetSubscribers("svi")
 	if len(subscribers) == 0 {
-		log.Println("DeleteSvi(): No subscribers for SVI objects")
+		logger.Info("DeleteSvi(): No subscribers for SVI objects")
 		return errors.New("no subscribers found for svi")
 	}
 
@@ -1142,6 +1325,7 @@ func DeleteSvi(name string) error {
 	}
 	svi.ResourceVersion = generateVersion()
 	svi.Status.SviOperStatus = SviOperStatusToBeDeleted
+	svi.Metadata.Audit.DeletedBy = deletedBy
 
 	err = infradb.client.Set(svi.Name, svi)
 	if err != nil {
@@ -1177,12 +1361,12 @@ func GetAllSvis() ([]*Svi, error) {
 	found, err := infradb.client.Get("svis", &svisMap)
 
 	if err != nil {
-		log.Println(err)
+		logger.Error(err)
 		return nil, err
 	}
 
 	if !found {
-		log.Println("GetAllSvis(): No Svis have been found")
+		logger.Debug("GetAllSvis(): No Svis have been found")
 		return nil, ErrKeyNotFound
 	}
 
@@ -1191,12 +1375,12 @@ func GetAllSvis() ([]*Svi, error) {
 		found, err := infradb.client.Get(key, svi)
 
 		if err != nil {
-			log.Printf("GetAllSvis(): Failed to get the SVI %s from store: %v", key, err)
+			logger.Errorw("GetAllSvis(): Failed to get the SVI from store", logging.Name(key), "error", err)
 			return nil, err
 		}
 
 		if !found {
-			log.Printf("GetAllSvis(): SVI %s not found", key)
+			logger.Warnw("GetAllSvis(): SVI not found", logging.Name(key))
 			return nil, ErrKeyNotFound
 		}
 		svis = append(svis, svi)
@@ -1210,7 +1394,7 @@ func DeleteAllResources() error {
 	duration := 10 * time.Second
 	bps, _ := GetAllBPs()
 	for _, bp := range bps {
-		err := DeleteBP(bp.Name)
+		err := DeleteBP(bp.Name, common.SystemIdentity)
 		if err != nil {
 			return err
 		}
@@ -1227,7 +1411,7 @@ func DeleteAllResources() error {
 	}
 	svis, _ := GetAllSvis()
 	for _, svi := range svis {
-		err := DeleteSvi(svi.Name)
+		err := DeleteSvi(svi.Name, common.SystemIdentity)
 		if err != nil {
 			return err
 		}
@@ -1244,7 +1428,7 @@ func DeleteAllResources() error {
 	}
 	vrfs, _ := GetAllVrfs()
 	for _, vrf := range vrfs {
-		err := DeleteVrf(vrf.Name)
+		err := DeleteVrf(vrf.Name, common.SystemIdentity)
 		if err != nil {
 			return err
 		}
@@ -1261,7 +1445,7 @@ func DeleteAllResources() error {
 	}
 	lbs, _ := GetAllLBs()
 	for _, lb := range lbs {
-		err := DeleteLB(lb.Name)
+		err := DeleteLB(lb.Name, common.SystemIdentity)
 		if err != nil {
 			return err
 		}
@@ -1286,13 +1470,36 @@ func UpdateSvi(svi *Svi) error {
 
 	subscribers := eventbus.EBus.GetSubscribers("svi")
 	if len(subscribers) == 0 {
-		log.Println("UpdateSvi(): No subscribers for SVI objects")
+		logger.Info("UpdateSvi(): No subscribers for SVI objects")
 		return errors.New("no subscribers found for svi")
 	}
 
-	err := infradb.client.Set(svi.Name, svi)
+	oldSvi := Svi{}
+	found, err := infradb.client.Get(svi.Name, &oldSvi)
 	if err != nil {
-		log.Println(err)
+		logger.Error(err)
+		return err
+	}
+	if !found {
+		logger.Infow("UpdateSvi(): SVI does not exist", logging.Name(svi.Name))
+		return ErrKeyNotFound
+	}
+	if oldSvi.Status.SviOperStatus == SviOperStatusToBeDeleted {
+		logger.Infow("UpdateSvi(): SVI is being deleted", logging.Name(svi.Name))
+		return ErrUpdateToBeDeleted
+	}
+	if oldSvi.Spec.Vrf != svi.Spec.Vrf || oldSvi.Spec.LogicalBridge != svi.Spec.LogicalBridge {
+		logger.Errorw("UpdateSvi(): The VRF and the Logical Bridge of SVI cannot be changed", logging.Name(svi.Name))
+		return ErrImmutableField
+	}
+
+	oldSvi.Metadata.Audit.UpdatedBy = svi.Metadata.Audit.UpdatedBy
+	svi.Metadata = oldSvi.Metadata
+	svi.PrevSpec = realizedSpec(oldSvi.PrevSpec, oldSvi.Spec, oldSvi.checkForAllSuccess())
+
+	err = infradb.client.Set(svi.Name, svi)
+	if err != nil {
+		logger.Error(err)
 		return err
 	}
 
@@ -1309,6 +1516,9 @@ func UpdateSviStatus(name string, resourceVersion string, notificationID string,
 	globalLock.Lock()
 	defer globalLock.Unlock()
 
+	ulog := logger.With(logging.Kind("svi"), logging.Name(name), logging.ResourceVersion(resourceVersion),
+		logging.NotificationID(notificationID), logging.Component(component.Name))
+
 	var allCompSuccess bool
 
 	// When we get an error from an operation to the Database then we just return it. The
@@ -1316,28 +1526,28 @@ func UpdateSviStatus(name string, resourceVersion string, notificationID string,
 	svi := Svi{}
 	found, err := infradb.client.Get(name, &svi)
 	if err != nil {
-		log.Println(err)
+		logger.Error(err)
 		return err
 	}
 
 	if !found {
 		// No Svi object has been found in the database so we will instruct TaskManager to drop the Task that is related with this status update.
 		taskmanager.TaskMan.StatusUpdated(name, "svi", svi.ResourceVersion, notificationID, true, &component)
-		log.Printf("UpdateSviStatus(): No SVI object has been found in DB with Name %s\n", name)
+		ulog.Warn("UpdateSviStatus(): No SVI object has been found in DB")
 		return nil
 	}
 
 	if svi.ResourceVersion != resourceVersion {
 		// Svi object in the database with different resourceVersion so we will instruct TaskManager to drop the Task that is related with this status update.
 		taskmanager.TaskMan.StatusUpdated(svi.Name, "svi", svi.ResourceVersion, notificationID, true, &component)
-		log.Printf("UpdateSviStatus(): Invalid resourceVersion %s for SVI %+v\n", resourceVersion, svi)
+		ulog.Infow("UpdateSviStatus(): Invalid resourceVersion", "currentResourceVersion", svi.ResourceVersion)
 		return nil
 	}
 
 	if component.Replay {
 		// One of the components has requested a replay of the DB.
 		// The task related to the status update will be dropped.
-		log.Printf("UpdateSviStatus(): Component %s has requested a replay\n", component.Name)
+		ulog.Info("UpdateSviStatus(): Component has requested a replay")
 		taskmanager.TaskMan.StatusUpdated(svi.Name, "svi", svi.ResourceVersion, notificationID, true, &component)
 		go startReplayProcedure(component.Name)
 		return nil
@@ -1362,7 +1572,7 @@ func UpdateSviStatus(name string, resourceVersion string, notificationID string,
 			vrf := Vrf{}
 			_, err := infradb.client.Get(svi.Spec.Vrf, &vrf)
 			if err != nil {
-				log.Println(err)
+				logger.Error(err)
 				return err
 			}
 
@@ -1370,38 +1580,38 @@ func UpdateSviStatus(name string, resourceVersion string, notificationID string,
 			lb := LogicalBridge{}
 			_, err = infradb.client.Get(svi.Spec.LogicalBridge, &lb)
 			if err != nil {
-				log.Println(err)
+				logger.Error(err)
 				return err
 			}
 
 			// Delete the referenced SVI from the VRF and store the VRF to the DB
 			if err := vrf.DeleteSvi(svi.Name); err != nil {
-				log.Println(err)
+				logger.Error(err)
 				return err
 			}
 
 			err = infradb.client.Set(vrf.Name, vrf)
 			if err != nil {
-				log.Println(err)
+				logger.Error(err)
 				return err
 			}
 
 			// Delete the referenced SVI from the Logical Bridge and store the Logical Bridge to the DB
 			if err := lb.DeleteSvi(svi.Name); err != nil {
-				log.Println(err)
+				logger.Error(err)
 				return err
 			}
 
 			err = infradb.client.Set(lb.Name, lb)
 			if err != nil {
-				log.Println(err)
+				logger.Error(err)
 				return err
 			}
 
 			// Delete the SVI object from the DB
 			err = infradb.client.Delete(svi.Name)
 			if err != nil {
-				log.Println(err)
+				logger.Error(err)
 				return err
 			}
 
@@ -1409,38 +1619,41 @@ func UpdateSviStatus(name string, resourceVersion string, notificationID string,
 			svis := make(map[string]bool)
 			found, err = infradb.client.Get("svis", &svis)
 			if err != nil {
-				log.Println(err)
+				logger.Error(err)
 				return err
 			}
 			if !found {
-				log.Println("UpdateSviStatus(): No Svis have been found")
+				logger.Debug("UpdateSviStatus(): No Svis have been found")
 				return ErrKeyNotFound
 			}
 
 			delete(svis, svi.Name)
 			err = infradb.client.Set("svis", &svis)
 			if err != nil {
-				log.Println(err)
+				logger.Error(err)
 				return err
 			}
 
-			log.Printf("UpdateSviStatus(): Svi %s has been deleted\n", name)
+			ulog.Info("UpdateSviStatus(): Svi has been deleted")
+			audit.Realization(audit.ActionRemoved, "svi", name, svi.Metadata.Audit.DeletedBy, svi.ResourceVersion, nil)
 		} else {
 			svi.Status.SviOperStatus = SviOperStatusUp
+			svi.PrevSpec = nil
 			err = infradb.client.Set(svi.Name, svi)
 			if err != nil {
-				log.Println(err)
+				logger.Error(err)
 				return err
 			}
-			log.Printf("UpdateSviStatus(): SVI %s has been updated: %+v\n", name, svi)
+			ulog.Info("UpdateSviStatus(): SVI has been realized")
+			audit.Realization(audit.ActionRealized, "svi", name, svi.Metadata.Audit.LastMutatedBy(), svi.ResourceVersion, nil)
 		}
 	} else {
 		err = infradb.client.Set(svi.Name, svi)
 		if err != nil {
-			log.Println(err)
+			logger.Error(err)
 			return err
 		}
-		log.Printf("UpdateSviStatus(): SVI %s has been updated: %+v\n", name, svi)
+		ulog.Debugw("UpdateSviStatus(): SVI has been updated", "status", component.CompStatus)
 	}
 
 	taskmanager.TaskMan.StatusUpdated(svi.Name, "svi", svi.ResourceVersion, notificationID, false, &component)
@@ -1456,7 +1669,7 @@ func SaveRoutingTable(rtNum uint32) error {
 	rts := make(map[uint32]bool)
 	found, err := infradb.client.Get("rts", &rts)
 	if err != nil {
-		log.Println(err)
+		logger.Error(err)
 		return err
 	}
 
@@ -1464,7 +1677,7 @@ func SaveRoutingTable(rtNum uint32) error {
 		rts[rtNum] = false
 		err = infradb.client.Set("rts", &rts)
 		if err != nil {
-			log.Println(err)
+			logger.Error(err)
 			return err
 		}
 		return nil
@@ -1472,14 +1685,14 @@ func SaveRoutingTable(rtNum uint32) error {
 
 	_, ok := rts[rtNum]
 	if ok {
-		log.Printf("SaveRoutingTable(): Routing Table %+v in use\n", rtNum)
+		logger.Debugw("SaveRoutingTable(): Routing Table in use", "table", rtNum)
 		return ErrRoutingTableInUse
 	}
 
 	rts[rtNum] = false
 	err = infradb.client.Set("rts", &rts)
 	if err != nil {
-		log.Println(err)
+		logger.Error(err)
 		return err
 	}
 	return nil
@@ -1493,26 +1706,57 @@ func DeleteRoutingTable(rtNum uint32) error {
 	rts := make(map[uint32]bool)
 	found, err := infradb.client.Get("rts", &rts)
 	if err != nil {
-		log.Println(err)
+		logger.Error(err)
 		return err
 	}
 
 	if !found {
-		log.Println("DeleteRoutingTable(): No routing tables have been found")
+		logger.Debug("DeleteRoutingTable(): No routing tables have been found")
 		return ErrKeyNotFound
 	}
 
 	_, ok := rts[rtNum]
 	if !ok {
-		log.Printf("DeleteRoutingTable(): Routing Table %+v not found\n", rtNum)
+		logger.Warnw("DeleteRoutingTable(): Routing Table not found", "table", rtNum)
 		return ErrKeyNotFound
 	}
 
 	delete(rts, rtNum)
 	err = infradb.client.Set("rts", &rts)
 	if err != nil {
-		log.Println(err)
+		logger.Error(err)
+		return err
+	}
+	return nil
+}
+
+// IDPoolStore persists the id pools of the modules in the infra db
+type IDPoolStore struct{}
+
+// build time check that struct implements interface
+var _ utils.IDPoolStore = IDPoolStore{}
+
+// Set saves the state of an id pool to DB
+func (IDPoolStore) Set(key string, value interface{}) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	if err := infradb.client.Set(key, value); err != nil {
+		logger.Error(err)
 		return err
 	}
 	return nil
 }
+
+// Get reads the state of an id pool from DB
+func (IDPoolStore) Get(key string, value interface{}) (bool, error) {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	found, err := infradb.client.Get(key, value)
+	if err != nil {
+		logger.Error(err)
+		return false, err
+	}
+	return found, nil
+}
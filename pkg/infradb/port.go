@@ -9,7 +9,6 @@ import (
 	//	"fmt"
 	"errors"
 
-	"log"
 	"net"
 
 	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
@@ -62,6 +61,7 @@ type BridgePortMetadata struct {
 	// Dimitris: We assume that this is Vendor specific
 	// so it will be generated by the LVM
 	VPort string
+	Audit common.Audit
 }
 
 // BridgePort holds Bridge Port info
@@ -89,7 +89,7 @@ func NewBridgePort(in *pb.BridgePort) (*BridgePort, error) {
 
 	subscribers := eventbus.EBus.GetSubscribers("bridge-port")
 	if len(subscribers) == 0 {
-		log.Println("NewBridgePort(): No subscribers for Bridge Port objects")
+		logger.Info("NewBridgePort(): No subscribers for Bridge Port objects")
 		return &BridgePort{}, errors.New("no subscribers found for bridge port")
 	}
 
@@ -225,7 +225,7 @@ func (in *BridgePort) prepareObjectsForReplay(componentName string, bpSubs []*ev
 	bpComponents := in.Status.Components
 	tempSubs := []*eventbus.Subscriber{}
 	for i, comp := range bpComponents {
-		if comp.Name == componentName || comp.CompStatus != common.ComponentStatusSuccess {
+		if componentName == allComponents || comp.Name == componentName || comp.CompStatus != common.ComponentStatusSuccess {
 			in.Status.Components[i] = common.Component{Name: comp.Name, CompStatus: common.ComponentStatusPending, Details: ""}
 			tempSubs = append(tempSubs, bpSubs[i])
 		}
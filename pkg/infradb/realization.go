@@ -0,0 +1,61 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"github.com/opiproject/opi-evpn-bridge/pkg/audit"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/taskmanager"
+)
+
+// AuditTaskResult records the realizations that a subscriber has failed and the ones whose task
+// has been dropped to the audit trail. The successful realizations are recorded by the status
+// updates, which still hold the objects that are removed. It is a result listener of the task manager.
+func AuditTaskResult(result *taskmanager.TaskResult) {
+	if result.Err == nil {
+		return
+	}
+	action, identity := realizationAudit(result.ObjectType, result.Name)
+	audit.Realization(action, result.ObjectType, result.Name, identity, result.ResourceVersion, result.Err)
+}
+
+// realizationAudit returns the action of the realization of the stored object and the identity
+// of the client that has requested it. The store is read without the global lock as the task
+// manager calls its listeners while the status updates wait for it.
+func realizationAudit(objectType, name string) (string, string) {
+	var a *common.Audit
+	removed := false
+	switch objectType {
+	case "logical-bridge":
+		lb := LogicalBridge{}
+		if found, err := infradb.client.Get(name, &lb); err == nil && found && lb.Metadata != nil {
+			a, removed = &lb.Metadata.Audit, lb.Status != nil && lb.Status.LBOperStatus == LogicalBridgeOperStatusToBeDeleted
+		}
+	case "bridge-port":
+		bp := BridgePort{}
+		if found, err := infradb.client.Get(name, &bp); err == nil && found && bp.Metadata != nil {
+			a, removed = &bp.Metadata.Audit, bp.Status != nil && bp.Status.BPOperStatus == BridgePortOperStatusToBeDeleted
+		}
+	case "vrf":
+		vrf := Vrf{}
+		if found, err := infradb.client.Get(name, &vrf); err == nil && found && vrf.Metadata != nil {
+			a, removed = &vrf.Metadata.Audit, vrf.Status != nil && vrf.Status.VrfOperStatus == VrfOperStatusToBeDeleted
+		}
+	case "svi":
+		svi := Svi{}
+		if found, err := infradb.client.Get(name, &svi); err == nil && found && svi.Metadata != nil {
+			a, removed = &svi.Metadata.Audit, svi.Status != nil && svi.Status.SviOperStatus == SviOperStatusToBeDeleted
+		}
+	}
+	switch {
+	case a == nil:
+		// The object is gone from the store, its task has been dropped
+		return audit.ActionRealized, ""
+	case removed:
+		return audit.ActionRemoved, a.DeletedBy
+	default:
+		return audit.ActionRealized, a.LastMutatedBy()
+	}
+}
@@ -0,0 +1,84 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/audit"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/taskmanager"
+)
+
+// failingHandler fails the realization of every vrf it is notified of
+type failingHandler struct{}
+
+func (h *failingHandler) HandleEvent(_ string, objectData *eventbus.ObjectData) {
+	comp := common.Component{Name: "lgm", CompStatus: common.ComponentStatusError, Details: "no route table left", Timer: time.Hour}
+	_ = UpdateVrfStatus(objectData.Name, objectData.ResourceVersion, objectData.NotificationID, nil, comp)
+}
+
+func TestAuditTaskResult(t *testing.T) {
+	prevBus, prevTasks := eventbus.EBus, taskmanager.TaskMan
+	eventbus.EBus = eventbus.NewEventBus()
+	taskmanager.TaskMan = taskmanager.NewTaskManager()
+	t.Cleanup(func() {
+		taskmanager.TaskMan.Drain(0)
+		eventbus.EBus, taskmanager.TaskMan = prevBus, prevTasks
+	})
+
+	trail, err := audit.NewLogger(filepath.Join(t.TempDir(), "audit.log"), "", []byte("test key"), 0, 0)
+	assert.NoError(t, err)
+	audit.Trail = trail
+	t.Cleanup(func() {
+		audit.Trail = nil
+		_ = trail.Close()
+	})
+
+	results := make(chan *taskmanager.TaskResult, 1)
+	taskmanager.TaskMan.AddResultListener(AuditTaskResult)
+	taskmanager.TaskMan.AddResultListener(func(result *taskmanager.TaskResult) {
+		select {
+		case results <- result:
+		default:
+		}
+	})
+	taskmanager.TaskMan.StartTaskManager()
+
+	eventbus.EBus.StartSubscriber("lgm", "vrf", 1, &failingHandler{})
+	assert.NoError(t, NewInfraDB("", "gomap"))
+	t.Cleanup(func() { _ = Close() })
+
+	vrf, err := NewVrfWithArgs("//network.opiproject.org/vrfs/blue", nil, nil, nil)
+	assert.NoError(t, err)
+	vrf.ResourceVersion = "1"
+	vrf.Metadata.Audit.CreatedBy = "netops"
+	assert.NoError(t, CreateVrf(vrf))
+
+	select {
+	case result := <-results:
+		assert.Error(t, result.Err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("Expected the failed realization to be reported")
+	}
+
+	records, err := trail.Query(audit.Query{})
+	assert.NoError(t, err)
+	if assert.Len(t, records, 1) {
+		r := records[0]
+		assert.Equal(t, audit.SourceInfraDB, r.Source)
+		assert.Equal(t, audit.ActionRealized, r.Action)
+		assert.Equal(t, vrf.Name, r.Object)
+		assert.Equal(t, "netops", r.Identity)
+		assert.Equal(t, "1", r.ResourceVersion)
+		assert.Equal(t, audit.ResultFailed, r.Result)
+		assert.Equal(t, "component lgm has failed: no route table left", r.Error)
+	}
+}
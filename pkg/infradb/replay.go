@@ -7,13 +7,36 @@ package infradb
 
 import (
 	"fmt"
-	"log"
 
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/actionbus"
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/taskmanager"
+	"github.com/opiproject/opi-evpn-bridge/pkg/logging"
 )
 
+// allComponents replays the objects to all the components, see ReplayStored
+const allComponents = "*"
+
+// storedObjectTypes lists the types of the stored objects in the order they depend on each other
+var storedObjectTypes = []string{"vrf", "logical-bridge", "svi", "bridge-port"}
+
+// ReplayStored creates a task for each object stored in the infra db, to be realized again by all
+// the components. It is called on start so that the modules realize, or adopt when the state has
+// been preserved, the objects of the previous run of a persistent db.
+func ReplayStored() error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	objectsToReplay, subsForReplay, err := gatherObjectsAndSubsToReplay(allComponents, storedObjectTypes)
+	if err != nil {
+		logger.Errorw("ReplayStored(): Failed to gather the stored objects", "error", err)
+		return err
+	}
+	createReplayTasks(objectsToReplay, subsForReplay)
+	logger.Infow("ReplayStored(): The stored objects are replayed", "count", len(objectsToReplay))
+	return nil
+}
+
 func startReplayProcedure(componentName string) {
 	globalLock.Lock()
 
@@ -24,10 +47,10 @@ func startReplayProcedure(componentName string) {
 
 	defer func() {
 		globalLock.Unlock()
-		log.Println("startReplayProcedure(): unblocking the TaskManager to continue")
+		logger.Info("startReplayProcedure(): unblocking the TaskManager to continue")
 		taskmanager.TaskMan.ReplayFinished()
 		if deferErr != nil {
-			log.Println("startReplayProcedure(): The replay procedure has failed")
+			logger.Error("startReplayProcedure(): The replay procedure has failed")
 			return
 		}
 		createReplayTasks(objectsToReplay, subsForReplay)
@@ -44,7 +67,7 @@ func startReplayProcedure(componentName string) {
 
 	if preSubscriber == nil {
 		deferErr = fmt.Errorf("no pre-replay subscriber for %s", componentName)
-		log.Printf("startReplayProcedure(): Error %+v\n", deferErr)
+		logger.Errorw("startReplayProcedure(): Replay has failed", logging.Component(componentName), "error", deferErr)
 		return
 	}
 
@@ -52,7 +75,7 @@ func startReplayProcedure(componentName string) {
 	actionData := actionbus.NewActionData()
 	deferErr = actionbus.ABus.Publish(actionData, preSubscriber)
 	if deferErr != nil {
-		log.Printf("startReplayProcedure(): Error %+v\n", deferErr)
+		logger.Errorw("startReplayProcedure(): Replay has failed", logging.Component(componentName), "error", deferErr)
 		return
 	}
 
@@ -61,17 +84,17 @@ func startReplayProcedure(componentName string) {
 	close(actionData.ErrCh)
 
 	if deferErr != nil {
-		log.Printf("startReplayProcedure(): Error %+v\n", deferErr)
+		logger.Errorw("startReplayProcedure(): Replay has failed", logging.Component(componentName), "error", deferErr)
 		return
 	}
 
-	log.Printf("startReplayProcedure(): Component %s has successfully executed pre-replay steps", componentName)
+	logger.Infow("startReplayProcedure(): Component has successfully executed pre-replay steps", logging.Component(componentName))
 
 	objectTypesToReplay := getObjectTypesToReplay(componentName)
 
 	objectsToReplay, subsForReplay, deferErr = gatherObjectsAndSubsToReplay(componentName, objectTypesToReplay)
 	if deferErr != nil {
-		log.Printf("startReplayProcedure(): Error %+v\n", deferErr)
+		logger.Errorw("startReplayProcedure(): Replay has failed", logging.Component(componentName), "error", deferErr)
 		return
 	}
 }
@@ -120,7 +143,7 @@ func gatherObjectsAndSubsToReplay(componentName string, objectTypesToReplay []st
 			}
 
 			if !found {
-				log.Println("gatherObjectsAndSubsToReplay(): No VRFs have been found")
+				logger.Debug("gatherObjectsAndSubsToReplay(): No VRFs have been found")
 				continue
 			}
 
@@ -156,7 +179,7 @@ func gatherObjectsAndSubsToReplay(componentName string, objectTypesToReplay []st
 			}
 
 			if !found {
-				log.Println("gatherObjectsAndSubsToReplay(): No Logical Bridges have been found")
+				logger.Debug("gatherObjectsAndSubsToReplay(): No Logical Bridges have been found")
 				continue
 			}
 			for key := range lbsMap {
@@ -189,7 +212,7 @@ func gatherObjectsAndSubsToReplay(componentName string, objectTypesToReplay []st
 			}
 
 			if !found {
-				log.Println("gatherObjectsAndSubsToReplay(): No SVIs have been found")
+				logger.Debug("gatherObjectsAndSubsToReplay(): No SVIs have been found")
 				continue
 			}
 			for key := range svisMap {
@@ -214,7 +237,7 @@ func gatherObjectsAndSubsToReplay(componentName string, objectTypesToReplay []st
 				subsForReplay = append(subsForReplay, tempSubs)
 				objectsToReplay = append(objectsToReplay, svi)
 			}
-		case "bp":
+		case "bridge-port":
 			bpsMap := make(map[string]bool)
 			found, err := infradb.client.Get("bps", &bpsMap)
 			if err != nil {
@@ -222,7 +245,7 @@ func gatherObjectsAndSubsToReplay(componentName string, objectTypesToReplay []st
 			}
 
 			if !found {
-				log.Println("gatherObjectsAndSubsToReplay(): No Bridge Ports have been found")
+				logger.Debug("gatherObjectsAndSubsToReplay(): No Bridge Ports have been found")
 				continue
 			}
 			for key := range bpsMap {
@@ -266,7 +289,7 @@ func createReplayTasks(objectsToReplay []interface{}, subsForReplay [][]*eventbu
 		case *BridgePort:
 			taskmanager.TaskMan.CreateTask(tempObj.Name, "bridge-port", tempObj.ResourceVersion, subsForReplay[i])
 		default:
-			log.Printf("createReplayTasks: Unknown object type %+v\n", tempObj)
+			logger.Debugw("createReplayTasks(): Unknown object type", "type", fmt.Sprintf("%T", tempObj))
 		}
 	}
 }
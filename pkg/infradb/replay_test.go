@@ -0,0 +1,52 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2023-2024 Intel Corporation, or its subsidiaries.
+// Copyright (c) 2024 Ericsson AB
+
+package infradb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+)
+
+type nopHandler struct{}
+
+func (h *nopHandler) HandleEvent(string, *eventbus.ObjectData) {}
+
+func TestReplayStored(t *testing.T) {
+	prevBus := eventbus.EBus
+	eventbus.EBus = eventbus.NewEventBus()
+	t.Cleanup(func() { eventbus.EBus = prevBus })
+
+	eventbus.EBus.Subscribe("lgm", "vrf", 1, &nopHandler{})
+	eventbus.EBus.Subscribe("frr", "vrf", 2, &nopHandler{})
+	assert.NoError(t, NewInfraDB("", "gomap"))
+	t.Cleanup(func() { _ = Close() })
+
+	vrf, err := NewVrfWithArgs("//network.opiproject.org/vrfs/blue", nil, nil, nil)
+	assert.NoError(t, err)
+	vrf.ResourceVersion = "1"
+	assert.NoError(t, CreateVrf(vrf))
+	for _, name := range []string{"lgm", "frr"} {
+		assert.NoError(t, UpdateVrfStatus(vrf.Name, "1", "", nil, common.Component{Name: name, CompStatus: common.ComponentStatusSuccess}))
+	}
+
+	stored, err := GetVrf(vrf.Name)
+	assert.NoError(t, err)
+	assert.Equal(t, VrfOperStatus(VrfOperStatusUp), stored.Status.VrfOperStatus)
+
+	assert.NoError(t, ReplayStored())
+
+	replayed, err := GetVrf(vrf.Name)
+	assert.NoError(t, err)
+	assert.NotEqual(t, "1", replayed.ResourceVersion, "Expected the replayed object to get a new resource version")
+	assert.Equal(t, VrfOperStatus(VrfOperStatusDown), replayed.Status.VrfOperStatus)
+	assert.Equal(t, []common.Component{
+		{Name: "lgm", CompStatus: common.ComponentStatusPending},
+		{Name: "frr", CompStatus: common.ComponentStatusPending},
+	}, replayed.Status.Components)
+}
@@ -7,12 +7,15 @@ package actionbus
 
 import (
 	"fmt"
-	"log"
 	"sync"
 
+	"github.com/opiproject/opi-evpn-bridge/pkg/logging"
 	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
 )
 
+// logger is the logger of the db subsystem
+var logger = logging.Logger(logging.DB).Sugar()
+
 // ABus holds the ActionBus object
 var ABus = NewActionBus()
 
@@ -49,17 +52,17 @@ func (a *ActionBus) StartSubscriber(moduleName, actionType string, actionHandler
 			for {
 				select {
 				case action := <-subscriber.Ch:
-					log.Printf("\nSubscriber %s for %s received \n", moduleName, actionType)
+					logger.Infow("Subscriber has received an action", logging.Component(moduleName), "action", actionType)
 
 					handlerKey := utils.ComposeHandlerName(moduleName, actionType)
 					if handler, ok := a.actionHandlers[handlerKey]; ok {
 						if actionData, ok := action.(*ActionData); ok {
 							handler.HandleAction(actionType, actionData)
 						} else {
-							log.Println("error: unexpected action type")
+							logger.Error("error: unexpected action type")
 						}
 					} else {
-						log.Println("error: no action handler found")
+						logger.Error("error: no action handler found")
 					}
 				case <-subscriber.Quit:
 					close(subscriber.Ch)
@@ -101,7 +104,7 @@ func (a *ActionBus) Subscribe(moduleName, actionType string, actionHandler Actio
 	handlerKey := utils.ComposeHandlerName(moduleName, actionType)
 	a.actionHandlers[handlerKey] = actionHandler
 
-	log.Printf("Subscriber %s registered for action %s\n", moduleName, actionType)
+	logger.Infow("Subscriber registered for action", logging.Component(moduleName), "action", actionType)
 	return subscriber
 }
 
@@ -119,7 +122,7 @@ func (a *ActionBus) Publish(actionData *ActionData, subscriber *Subscriber) erro
 
 	select {
 	case subscriber.Ch <- actionData:
-		log.Printf("Publish(): Notification is sent to subscriber %s\n", subscriber.Name)
+		logger.Infow("Publish(): Notification is sent to subscriber", logging.Component(subscriber.Name))
 	default:
 		err = fmt.Errorf("channel for subscriber %s is busy", subscriber.Name)
 	}
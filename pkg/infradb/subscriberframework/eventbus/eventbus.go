@@ -7,13 +7,19 @@ package eventbus
 
 import (
 	"fmt"
-	"log"
 	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/opiproject/opi-evpn-bridge/pkg/logging"
+	"github.com/opiproject/opi-evpn-bridge/pkg/metrics"
 	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
 )
 
+// logger is the logger of the db subsystem
+var logger = logging.Logger(logging.DB).Sugar()
+
 // EBus holds the EventBus object
 var EBus = NewEventBus()
 
@@ -21,16 +27,24 @@ var EBus = NewEventBus()
 type EventBus struct {
 	subscribers   map[string][]*Subscriber
 	eventHandlers map[string]EventHandler
-	subscriberL   sync.RWMutex
-	mutex         sync.RWMutex
+	// priorities holds the current priority of the subscribers by handler name,
+	// it orders the subscribers of each event type
+	priorities  map[string]int
+	subscriberL sync.RWMutex
+	mutex       sync.RWMutex
 }
 
 // Subscriber holds the info for each subscriber
 type Subscriber struct {
-	Name     string
-	Ch       chan interface{}
-	Quit     chan bool
+	Name string
+	Ch   chan interface{}
+	Quit chan bool
+	// Priority is the priority the subscriber has been registered with,
+	// a change of priority is only recorded by the event bus
 	Priority int
+	// busySince is the time in nanoseconds at which the subscriber has
+	// started to handle its current event, zero when it is idle
+	busySince atomic.Int64
 }
 
 // EventHandler handles the events that arrive
@@ -54,12 +68,16 @@ func (e *EventBus) StartSubscriber(moduleName, eventType string, priority int, e
 			for {
 				select {
 				case event := <-subscriber.Ch:
-					log.Printf("\nSubscriber %s for %s received \n", moduleName, eventType)
-
 					handlerKey := utils.ComposeHandlerName(moduleName, eventType)
 					if handler, ok := e.eventHandlers[handlerKey]; ok {
 						if objectData, ok := event.(*ObjectData); ok {
+							logger.Debugw("Subscriber has received an event", logging.Component(moduleName), logging.Kind(eventType),
+								logging.Name(objectData.Name), logging.ResourceVersion(objectData.ResourceVersion), logging.NotificationID(objectData.NotificationID))
+							start := time.Now()
+							subscriber.busySince.Store(start.UnixNano())
 							handler.HandleEvent(eventType, objectData)
+							subscriber.busySince.Store(0)
+							metrics.SubscriberLatency.WithLabelValues(moduleName, eventType).Observe(metrics.Since(start))
 						} else {
 							subscriber.Ch <- "error: unexpected event type"
 						}
@@ -68,7 +86,7 @@ func (e *EventBus) StartSubscriber(moduleName, eventType string, priority int, e
 						subscriber.Ch <- "error: no event handler found"
 					}
 				case <-subscriber.Quit:
-					log.Printf("\nSubscriber %s  quit \n", subscriber.Name)
+					logger.Infow("Subscriber quit", logging.Component(subscriber.Name))
 					close(subscriber.Ch)
 					return
 				}
@@ -82,6 +100,7 @@ func NewEventBus() *EventBus {
 	return &EventBus{
 		subscribers:   make(map[string][]*Subscriber),
 		eventHandlers: make(map[string]EventHandler),
+		priorities:    make(map[string]int),
 	}
 }
 
@@ -101,16 +120,50 @@ func (e *EventBus) Subscribe(moduleName, eventType string, priority int, eventHa
 
 	handlerKey := utils.ComposeHandlerName(moduleName, eventType)
 	e.eventHandlers[handlerKey] = eventHandler
+	e.priorities[handlerKey] = priority
 
 	// Sort subscribers based on priority
-	sort.Slice(e.subscribers[eventType], func(i, j int) bool {
-		return e.subscribers[eventType][i].Priority < e.subscribers[eventType][j].Priority
-	})
+	e.sortSubscribers(eventType, e.subscribers[eventType])
 
-	log.Printf("Subscriber %s registered for event %s with priority %d\n", moduleName, eventType, priority)
+	logger.Infow("Subscriber registered for event", logging.Component(moduleName), logging.Kind(eventType), "priority", priority)
 	return subscriber
 }
 
+// SetPriority changes the priority of the subscriber of the module registered with the given eventType.
+// The tasks that are already queued keep notifying the subscribers in the former order.
+func (e *EventBus) SetPriority(moduleName, eventType string, priority int) bool {
+	e.subscriberL.Lock()
+	defer e.subscriberL.Unlock()
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	found := false
+	for _, sub := range e.subscribers[eventType] {
+		if sub.Name == moduleName {
+			found = true
+		}
+	}
+	if !found {
+		return false
+	}
+	e.priorities[utils.ComposeHandlerName(moduleName, eventType)] = priority
+	// The slice returned to the callers of GetSubscribers is left untouched
+	subscribers := make([]*Subscriber, len(e.subscribers[eventType]))
+	copy(subscribers, e.subscribers[eventType])
+	e.sortSubscribers(eventType, subscribers)
+	e.subscribers[eventType] = subscribers
+	logger.Infow("Subscriber priority for event changed", logging.Component(moduleName), logging.Kind(eventType), "priority", priority)
+	return true
+}
+
+// sortSubscribers orders the subscribers of the eventType by their current priority
+func (e *EventBus) sortSubscribers(eventType string, subscribers []*Subscriber) {
+	sort.SliceStable(subscribers, func(i, j int) bool {
+		return e.priorities[utils.ComposeHandlerName(subscribers[i].Name, eventType)] <
+			e.priorities[utils.ComposeHandlerName(subscribers[j].Name, eventType)]
+	})
+}
+
 // GetSubscribers api is used to fetch the list of subscribers registered with given eventType is priority order
 // first in list has the higher priority followed by others and so on
 func (e *EventBus) GetSubscribers(eventType string) []*Subscriber {
@@ -120,17 +173,19 @@ func (e *EventBus) GetSubscribers(eventType string) []*Subscriber {
 	return e.subscribers[eventType]
 }
 
-// subscriberExist checks if the subscriber exist
-func (e *EventBus) subscriberExist(eventType string, moduleName string) bool {
-	subList := e.GetSubscribers(eventType)
-	if len(subList) != 0 {
-		for _, s := range subList {
-			if s.Name == moduleName {
-				return true
-			}
+// FindSubscriber returns the subscriber of the module registered with the given eventType if any
+func (e *EventBus) FindSubscriber(eventType string, moduleName string) *Subscriber {
+	for _, s := range e.GetSubscribers(eventType) {
+		if s.Name == moduleName {
+			return s
 		}
 	}
-	return false
+	return nil
+}
+
+// subscriberExist checks if the subscriber exist
+func (e *EventBus) subscriberExist(eventType string, moduleName string) bool {
+	return e.FindSubscriber(eventType, moduleName) != nil
 }
 
 // UnsubscribeModule unsubs the whole module
@@ -144,12 +199,12 @@ func (e *EventBus) UnsubscribeModule(moduleName string) bool {
 
 					handlerKey := utils.ComposeHandlerName(moduleName, eventName)
 					e.eventHandlers[handlerKey] = nil
-					log.Printf("\n Module %s is unsubscribed for event %s", sub.Name, eventName)
+					logger.Infow("Module is unsubscribed for event", logging.Component(sub.Name), logging.Kind(eventName))
 				}
 			}
 		}
 	}
-	log.Printf("\nSubscriber %s is unsubscribed for all events\n", moduleName)
+	logger.Infow("Subscriber is unsubscribed for all events", logging.Component(moduleName))
 	return false
 }
 
@@ -159,7 +214,8 @@ func (e *EventBus) Publish(objectData *ObjectData, subscriber *Subscriber) error
 
 	select {
 	case subscriber.Ch <- objectData:
-		log.Printf("Publish(): Notification is sent to subscriber %s\n", subscriber.Name)
+		logger.Debugw("Publish(): Notification is sent to subscriber", logging.Component(subscriber.Name), logging.Name(objectData.Name),
+			logging.ResourceVersion(objectData.ResourceVersion), logging.NotificationID(objectData.NotificationID))
 	default:
 		err = fmt.Errorf("channel for subscriber %s is busy", subscriber.Name)
 	}
@@ -171,7 +227,17 @@ func (e *EventBus) Unsubscribe(subscriber *Subscriber) {
 	e.mutex.Lock()
 	defer e.mutex.Unlock()
 	subscriber.Quit <- true
-	log.Printf("\nSubscriber %s is unsubscribed for all events\n", subscriber.Name)
+	logger.Infow("Subscriber is unsubscribed for all events", logging.Component(subscriber.Name))
+}
+
+// Busy returns for how long the subscriber has been handling its current event,
+// zero when it is waiting for events
+func (s *Subscriber) Busy() time.Duration {
+	since := s.busySince.Load()
+	if since == 0 {
+		return 0
+	}
+	return time.Since(time.Unix(0, since))
 }
 
 // Unsubscribe closes the event channel
@@ -189,7 +255,7 @@ func (e *EventBus) UnsubscribeEvent(subscriber *Subscriber, eventType string) {
 			if sub == subscriber {
 				e.subscribers[eventType] = append(subscribers[:i], subscribers[i+1:]...)
 				subscriber.Quit <- true
-				log.Printf("\nSubscriber %s is unsubscribed for event %s\n", subscriber.Name, eventType)
+				logger.Infow("Subscriber is unsubscribed for event", logging.Component(subscriber.Name), logging.Kind(eventType))
 				break
 			}
 		}
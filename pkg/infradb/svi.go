@@ -6,11 +6,9 @@
 package infradb
 
 import (
-	"encoding/binary"
 	//	"fmt"
 	"errors"
 
-	"log"
 	"net"
 
 	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
@@ -52,12 +50,15 @@ type SviSpec struct {
 
 // SviMetadata holds SVI Metadata
 type SviMetadata struct {
+	Audit common.Audit
 }
 
 // Svi holds SVI info
 type Svi struct {
-	Name            string
-	Spec            *SviSpec
+	Name string
+	Spec *SviSpec
+	// PrevSpec holds the last realized spec while an update is being realized
+	PrevSpec        *SviSpec
 	Status          *SviStatus
 	Metadata        *SviMetadata
 	ResourceVersion string
@@ -76,15 +77,17 @@ func NewSvi(in *pb.Svi) (*Svi, error) {
 
 	// Parse Gateway IPs
 	for _, gwIPPrefix := range in.Spec.GwIpPrefix {
-		gatewayIP := make(net.IP, 4)
-		binary.BigEndian.PutUint32(gatewayIP, gwIPPrefix.Addr.GetV4Addr())
-		gwIP := net.IPNet{IP: gatewayIP, Mask: net.CIDRMask(int(gwIPPrefix.Len), 32)}
-		gwIPs = append(gwIPs, &gwIP)
+		gwIP, err := common.ConvertToIPNet(gwIPPrefix)
+		if err != nil {
+			logger.Infow("NewSvi(): Invalid gateway ip prefix", "error", err)
+			return &Svi{}, err
+		}
+		gwIPs = append(gwIPs, gwIP)
 	}
 
 	subscribers := eventbus.EBus.GetSubscribers("svi")
 	if len(subscribers) == 0 {
-		log.Println("NewSvi(): No subscribers for SVI objects")
+		logger.Info("NewSvi(): No subscribers for SVI objects")
 		return &Svi{}, errors.New("no subscribers found for svi")
 	}
 
@@ -207,7 +210,7 @@ func (in *Svi) prepareObjectsForReplay(componentName string, sviSubs []*eventbus
 	sviComponents := in.Status.Components
 	tempSubs := []*eventbus.Subscriber{}
 	for i, comp := range sviComponents {
-		if comp.Name == componentName || comp.CompStatus != common.ComponentStatusSuccess {
+		if componentName == allComponents || comp.Name == componentName || comp.CompStatus != common.ComponentStatusSuccess {
 			in.Status.Components[i] = common.Component{Name: comp.Name, CompStatus: common.ComponentStatusPending, Details: ""}
 			tempSubs = append(tempSubs, sviSubs[i])
 		}
@@ -216,6 +219,8 @@ func (in *Svi) prepareObjectsForReplay(componentName string, sviSubs []*eventbus
 		in.Status.SviOperStatus = SviOperStatusDown
 	}
 
+	// The replayed components realize the object as a whole
+	in.PrevSpec = nil
 	in.ResourceVersion = generateVersion()
 	return tempSubs
 }
@@ -0,0 +1,101 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package taskmanager manages the tasks that are created for realization of intents
+package taskmanager
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/logging"
+)
+
+var (
+	// ErrTaskDropped the task has been dropped before all the subscribers have processed it
+	ErrTaskDropped = errors.New("the task has been dropped")
+	// ErrTaskCanceled the task has been canceled
+	ErrTaskCanceled = errors.New("the task has been canceled")
+)
+
+// TaskResult reports the outcome of the processing of a Task
+type TaskResult struct {
+	Name            string
+	ObjectType      string
+	ResourceVersion string
+	// Err is nil when all the subscribers have processed the task successfully
+	Err error
+	// Failures is the number of times a subscriber has failed to process the task,
+	// the task is retried after a ComponentError
+	Failures int
+}
+
+// ComponentError is reported when a subscriber has failed to process a task
+type ComponentError struct {
+	Component string
+	Details   string
+}
+
+// Error returns the error message
+func (e *ComponentError) Error() string {
+	if e.Details == "" {
+		return fmt.Sprintf("component %s has failed", e.Component)
+	}
+	return fmt.Sprintf("component %s has failed: %s", e.Component, e.Details)
+}
+
+// ResultListener is called for every task result.
+// It is called from the task processing loop so it must not block.
+type ResultListener func(result *TaskResult)
+
+// AddResultListener registers a listener for the task results
+func (t *TaskManager) AddResultListener(listener ResultListener) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	t.listeners = append(t.listeners, listener)
+}
+
+// CancelTask cancels the task of an object with the given resource version.
+// The cancellation is best effort: a task that is currently being processed by
+// a subscriber is dropped the next time it is dequeued.
+func (t *TaskManager) CancelTask(name, resourceVersion string) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	t.canceled[taskKey(name, resourceVersion)] = true
+	logger.Infow("CancelTask(): Task has been canceled", logging.Name(name), logging.ResourceVersion(resourceVersion))
+}
+
+// isCanceled checks if the task has been canceled
+func (t *TaskManager) isCanceled(task *Task) bool {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	return t.canceled[taskKey(task.name, task.resourceVersion)]
+}
+
+// notifyResult reports the result of a task to the listeners
+func (t *TaskManager) notifyResult(task *Task, err error) {
+	t.mtx.Lock()
+	// The task is retried after a component error so it stays canceled until then
+	var compErr *ComponentError
+	if !errors.As(err, &compErr) {
+		delete(t.canceled, taskKey(task.name, task.resourceVersion))
+	}
+	listeners := append([]ResultListener{}, t.listeners...)
+	t.mtx.Unlock()
+
+	result := &TaskResult{
+		Name:            task.name,
+		ObjectType:      task.objectType,
+		ResourceVersion: task.resourceVersion,
+		Err:             err,
+		Failures:        task.failures,
+	}
+	for _, listener := range listeners {
+		listener(result)
+	}
+}
+
+func taskKey(name, resourceVersion string) string {
+	return name + "/" + resourceVersion
+}
@@ -6,24 +6,37 @@
 package taskmanager
 
 import (
-	"log"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
+	"github.com/opiproject/opi-evpn-bridge/pkg/logging"
+	"github.com/opiproject/opi-evpn-bridge/pkg/metrics"
 
 	// Typo
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
 )
 
+// logger is the logger of the db subsystem
+var logger = logging.Logger(logging.DB).Sugar()
+
 // TaskMan holds a TaskManager object
-var TaskMan = newTaskManager()
+var TaskMan = NewTaskManager()
 
 // TaskManager holds fields crucial for task manager functionality
 type TaskManager struct {
 	taskQueue      *TaskQueue
 	taskStatusChan chan *TaskStatus
 	replayChan     chan struct{}
+
+	mtx       sync.Mutex
+	listeners []ResultListener
+	canceled  map[string]bool
+
+	// stopChan is closed to stop the processing of the tasks
+	stopChan chan struct{}
+	stopOnce sync.Once
 }
 
 // Task corresponds to an onject to be realized
@@ -35,6 +48,13 @@ type Task struct {
 	// systemTimer is used only when we want to retry a Task due to unavailability of the Subscriber or not receiving a TaskStatus
 	systemTimer time.Duration
 	subs        []*eventbus.Subscriber
+	// failures counts the component errors reported for the task
+	failures int
+}
+
+// fields returns the logging fields of the task
+func (t *Task) fields() []interface{} {
+	return logging.Object(t.objectType, t.name, t.resourceVersion)
 }
 
 // TaskStatus holds info related to the status that has been received
@@ -47,12 +67,24 @@ type TaskStatus struct {
 	component       *common.Component
 }
 
-// newTaskManager return the new task manager object
-func newTaskManager() *TaskManager {
+// fields returns the logging fields of the task status
+func (ts *TaskStatus) fields() []interface{} {
+	fields := []interface{}{logging.Kind(ts.objectType), logging.Name(ts.name), logging.ResourceVersion(ts.resourceVersion),
+		logging.NotificationID(ts.notificationID), "dropTask", ts.dropTask}
+	if ts.component != nil {
+		fields = append(fields, logging.Component(ts.component.Name), "status", ts.component.CompStatus)
+	}
+	return fields
+}
+
+// NewTaskManager return the new task manager object
+func NewTaskManager() *TaskManager {
 	return &TaskManager{
 		taskQueue:      NewTaskQueue(),
 		taskStatusChan: make(chan *TaskStatus),
 		replayChan:     make(chan struct{}),
+		canceled:       make(map[string]bool),
+		stopChan:       make(chan struct{}),
 	}
 }
 
@@ -83,7 +115,7 @@ func newTaskStatus(name, objectType, resourceVersion, notificationID string, dro
 // StartTaskManager starts task manager
 func (t *TaskManager) StartTaskManager() {
 	go t.processTasks()
-	log.Println("Task Manager has started")
+	logger.Info("Task Manager has started")
 }
 
 // CreateTask creates a task and adds it to the queue
@@ -92,13 +124,42 @@ func (t *TaskManager) CreateTask(name, objectType, resourceVersion string, subs
 	// The reason that we use a go routing to enqueue the task is because we do not want the main thread to block
 	// if the queue is full but only the go routine to block
 	go t.taskQueue.Enqueue(task)
-	log.Printf("CreateTask(): New Task has been created: %+v\n", task)
+	logger.Debugw("CreateTask(): New Task has been created", task.fields()...)
+}
+
+// TaskRequest describes a task to be created as part of a batch
+type TaskRequest struct {
+	Name            string
+	ObjectType      string
+	ResourceVersion string
+	Subs            []*eventbus.Subscriber
+}
+
+// CreateTasks creates a batch of tasks and adds them to the queue together,
+// keeping the order in which they have been requested
+func (t *TaskManager) CreateTasks(requests []TaskRequest) {
+	if len(requests) == 0 {
+		return
+	}
+
+	tasks := make([]*Task, 0, len(requests))
+	for _, req := range requests {
+		tasks = append(tasks, newTask(req.Name, req.ObjectType, req.ResourceVersion, req.Subs))
+	}
+	// A single go routine enqueues the whole batch so that the tasks do not get interleaved
+	// with each other and the main thread does not block if the queue is full
+	go func() {
+		for _, task := range tasks {
+			t.taskQueue.Enqueue(task)
+		}
+	}()
+	logger.Infow("CreateTasks(): New Tasks have been created", "count", len(tasks))
 }
 
 // StatusUpdated creates a task status and sends it for handling
 func (t *TaskManager) StatusUpdated(name, objectType, resourceVersion, notificationID string, dropTask bool, component *common.Component) {
 	taskStatus := newTaskStatus(name, objectType, resourceVersion, notificationID, dropTask, component)
-	log.Printf("StatusUpdated(): New Task Status has been created: %+v\n", taskStatus)
+	logger.Debugw("StatusUpdated(): New Task Status has been created", taskStatus.fields()...)
 
 	// We need to have a default case here so the call is not stuck if we send to channel but there is nobody reading from the channel.
 	// e.g. a subscriber got stuck and doesn't reply. The task will be requeued after the timer gets expired. In the meanwhile
@@ -106,25 +167,59 @@ func (t *TaskManager) StatusUpdated(name, objectType, resourceVersion, notificat
 	// as the timer has not expired and the queue is empty (We assume that there is only one task in the queue).
 	select {
 	case t.taskStatusChan <- taskStatus:
-		log.Printf("StatusUpdated(): Task Status has been sent to channel: %+v\n", taskStatus)
+		logger.Debugw("StatusUpdated(): Task Status has been sent to channel", taskStatus.fields()...)
 	default:
-		log.Printf("StatusUpdated(): Task Status has not been sent to channel. Channel not available: %+v\n", taskStatus)
+		logger.Warnw("StatusUpdated(): Task Status has not been sent to channel. Channel not available", taskStatus.fields()...)
+	}
+}
+
+// drainPollInterval is the interval at which Drain checks for the pending tasks
+const drainPollInterval = 100 * time.Millisecond
+
+// Drain waits for the pending tasks to be processed, up to the timeout, then stops the processing
+// of the tasks and returns the number of tasks that have not been processed.
+// The tasks waiting for a retry timer to expire are not pending and are not waited for.
+func (t *TaskManager) Drain(timeout time.Duration) int {
+	deadline := time.Now().Add(timeout)
+	for t.taskQueue.Pending() != 0 && time.Now().Before(deadline) {
+		time.Sleep(drainPollInterval)
 	}
+	t.stopOnce.Do(func() { close(t.stopChan) })
+	pending := t.taskQueue.Pending()
+	logger.Infow("Drain(): Task Manager has been drained", "pending", pending)
+	return pending
 }
 
 // ReplayFinished notifies that the replay of objects has finished
 func (t *TaskManager) ReplayFinished() {
 	t.replayChan <- struct{}{}
-	log.Println("ReplayFinished(): Replay has finished.")
+	logger.Info("ReplayFinished(): Replay has finished.")
 }
 
 // processTasks processes the task
 func (t *TaskManager) processTasks() {
 	var taskStatus *TaskStatus
+	inProgress := false
 
 	for {
-		task := t.taskQueue.Dequeue()
-		log.Printf("processTasks(): Task has been dequeued for processing: %+v\n", task)
+		if inProgress {
+			// The previous task has been realized, requeued or dropped
+			t.taskQueue.Done()
+		}
+		task, ok := t.taskQueue.DequeueOrStop(t.stopChan)
+		if !ok {
+			logger.Info("processTasks(): Task Manager has stopped")
+			return
+		}
+		inProgress = true
+		tlog := logger.With(task.fields()...)
+		tlog.Debug("processTasks(): Task has been dequeued for processing")
+
+		if t.isCanceled(task) {
+			tlog.Info("processTasks(): The task has been canceled and will be dropped")
+			t.notifyResult(task, ErrTaskCanceled)
+			continue
+		}
 
 		// A new sub-list of the initial subscribers list will be generated based on the value of the subIndex.
 		// This sub-list can be equal to the initial list (subIndex is equal to zero) or smaller than the initial
@@ -141,22 +236,23 @@ func (t *TaskManager) processTasks() {
 				// (e.g. Maybe you have a timeout on the subscribers and you got the notification after the timeout have passed)
 				NotificationID: uuid.NewString(),
 			}
+			nlog := tlog.With(logging.Component(sub.Name), logging.NotificationID(objectData.NotificationID))
 			if err := eventbus.EBus.Publish(objectData, sub); err != nil {
-				log.Printf("processTasks(): Failed to sent notification: %+v\n", err)
-				log.Printf("processTasks(): Notification not sent to subscriber %+v with data %+v. The Task %+v will be requeued.\n", sub, objectData, task)
+				nlog.Warnw("processTasks(): Failed to send the notification, the Task will be requeued", "error", err)
 				// We keep this subIndex in order to know from which subscriber to start iterating after the requeue of the Task
 				// so we do start again from the subscriber that returned an error or was unavailable for any reason. The increasing
 				// of the subIndex value will be always correct as after the requeue of the task we generate and iterate on a new sub-list
 				// of the remaining subscribers which is equal or smaller than the initial subscribers list.
 				task.subIndex += i
 				task.systemTimer *= 2
-				log.Printf("processTasks(): The Task will be requeued after %+v\n", task.systemTimer)
+				nlog.Debugw("processTasks(): The Task will be requeued", "after", task.systemTimer)
+				metrics.TaskRetries.WithLabelValues(task.objectType, "subscriber_busy").Inc()
 				time.AfterFunc(task.systemTimer, func() {
 					t.taskQueue.Enqueue(task)
 				})
 				break loopTwo
 			}
-			log.Printf("processTasks(): Notification has been sent to subscriber %+v with data %+v\n", sub, objectData)
+			nlog.Debug("processTasks(): Notification has been sent to subscriber")
 
 		loopThree:
 			for {
@@ -167,22 +263,23 @@ func (t *TaskManager) processTasks() {
 				select {
 				case taskStatus = <-t.taskStatusChan:
 
-					log.Printf("processTasks(): Task Status has been received from the channel %+v\n", taskStatus)
+					nlog.Debugw("processTasks(): Task Status has been received from the channel", "status", taskStatus.component.CompStatus)
 					if taskStatus.notificationID == objectData.NotificationID {
-						log.Printf("processTasks(): received notification id %+v equals the sent notification id %+v\n", taskStatus.notificationID, objectData.NotificationID)
+						nlog.Debug("processTasks(): received notification id equals the sent notification id")
 						break loopThree
 					}
-					log.Printf("processTasks(): received notification id %+v doesn't equal the sent notification id %+v\n", taskStatus.notificationID, objectData.NotificationID)
+					nlog.Debugw("processTasks(): received notification id doesn't equal the sent notification id", "receivedNotificationID", taskStatus.notificationID)
 
 				// We need a timeout in case that the subscriber doesn't update the status at all for whatever reason.
 				// If that occurs then we just requeue the task with a timer
 				case <-time.After(30 * time.Second):
-					log.Printf("processTasks(): No task status has been received in the channel from subscriber %+v. The task %+v will be requeued. Task Status %+v\n", sub, task, taskStatus)
+					nlog.Warn("processTasks(): No task status has been received in the channel from subscriber. The task will be requeued")
 					// We keep this subIndex in order to know from which subscriber to start iterating after the requeue of the Task
 					// so we do start again from the subscriber that returned an error or was unavailable for any reason.
 					task.subIndex += i
 					task.systemTimer *= 2
-					log.Printf("processTasks(): The Task will be requeued after %+v\n", task.systemTimer)
+					nlog.Debugw("processTasks(): The Task will be requeued", "after", task.systemTimer)
+					metrics.TaskRetries.WithLabelValues(task.objectType, "timeout").Inc()
 					time.AfterFunc(task.systemTimer, func() {
 						t.taskQueue.Enqueue(task)
 					})
@@ -194,7 +291,10 @@ func (t *TaskManager) processTasks() {
 			// the task of the object is referring to an old already updated object or the object is no longer in the database (has been deleted)
 			// or a replay procedure has been requested
 			if t.checkStatus(taskStatus) {
-				log.Println("processTasks(): Move to the next Task in the queue")
+				if taskStatus != nil {
+					t.notifyResult(task, ErrTaskDropped)
+				}
+				tlog.Debug("processTasks(): Move to the next Task in the queue")
 				break loopTwo
 			}
 
@@ -203,11 +303,18 @@ func (t *TaskManager) processTasks() {
 
 			switch taskStatus.component.CompStatus {
 			case common.ComponentStatusSuccess:
-				log.Printf("processTasks(): Subscriber %+v has processed the task %+v successfully\n", sub, task)
+				nlog.Info("processTasks(): Subscriber has processed the task successfully")
+				if i == len(subsToIterate)-1 {
+					t.notifyResult(task, nil)
+				}
 				continue loopTwo
 			case common.ComponentStatusError:
-				log.Printf("processTasks(): Subscriber %+v has not processed the task %+v successfully\n", sub, task)
-				log.Printf("processTasks(): The Task will be requeued after %+v\n", taskStatus.component.Timer)
+				nlog.Warnw("processTasks(): Subscriber has not processed the task successfully, the Task will be requeued",
+					"after", taskStatus.component.Timer, "details", taskStatus.component.Details)
+				task.failures++
+				t.notifyResult(task, &ComponentError{Component: taskStatus.component.Name, Details: taskStatus.component.Details})
+				metrics.SubscriberErrors.WithLabelValues(sub.Name, task.objectType).Inc()
+				metrics.TaskRetries.WithLabelValues(task.objectType, "component_error").Inc()
 				// We keep this subIndex in order to know from which subscriber to start iterating after the requeue of the Task
 				// so we do start again from the subscriber that returned an error or was unavailable for any reason. The increasing
 				// of the subIndex value will be always correct as after the requeue of the task we generate and iterate on a new sub-list
@@ -218,8 +325,8 @@ func (t *TaskManager) processTasks() {
 				})
 				break loopTwo
 			default:
-				log.Printf("processTasks(): Subscriber %+v has not provided designated status for the task %+v\n", sub, task)
-				log.Printf("processTasks(): The task %+v will be dropped\n", task)
+				nlog.Error("processTasks(): Subscriber has not provided designated status for the task, the task will be dropped")
+				t.notifyResult(task, ErrTaskDropped)
 				break loopTwo
 			}
 		}
@@ -235,9 +342,9 @@ func (t *TaskManager) checkStatus(taskStatus *TaskStatus) bool {
 
 	if taskStatus.dropTask {
 		if taskStatus.component.Replay {
-			log.Println("checkStatus(): Wait for the replay DB procedure to finish and move to the next Task in the queue")
+			logger.Info("checkStatus(): Wait for the replay DB procedure to finish and move to the next Task in the queue")
 			<-t.replayChan
-			log.Println("checkStatus(): Replay has finished. Continuing processing tasks")
+			logger.Info("checkStatus(): Replay has finished. Continuing processing tasks")
 		}
 		return true
 	}
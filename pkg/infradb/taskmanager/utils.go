@@ -5,9 +5,18 @@
 // Package taskmanager manages the tasks that are created for realization of intents
 package taskmanager
 
+import (
+	"sync/atomic"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/metrics"
+)
+
 // TaskQueue represents a queue of tasks
 type TaskQueue struct {
 	channel chan *Task
+	// pending counts the tasks that have been enqueued and not processed yet,
+	// including the one in progress
+	pending atomic.Int64
 }
 
 // NewTaskQueue initializes a TaskQueue
@@ -19,12 +28,37 @@ func NewTaskQueue() *TaskQueue {
 
 // Enqueue push tasks into the queue
 func (q *TaskQueue) Enqueue(task *Task) {
+	q.pending.Add(1)
 	q.channel <- task
+	metrics.TaskQueueDepth.Set(float64(len(q.channel)))
 }
 
 // Dequeue pops task from queue
 func (q *TaskQueue) Dequeue() *Task {
-	return <-q.channel
+	task := <-q.channel
+	metrics.TaskQueueDepth.Set(float64(len(q.channel)))
+	return task
+}
+
+// DequeueOrStop pops task from queue, it returns false once stop is closed
+func (q *TaskQueue) DequeueOrStop(stop <-chan struct{}) (*Task, bool) {
+	select {
+	case task := <-q.channel:
+		metrics.TaskQueueDepth.Set(float64(len(q.channel)))
+		return task, true
+	case <-stop:
+		return nil, false
+	}
+}
+
+// Done marks the last dequeued task as processed
+func (q *TaskQueue) Done() {
+	q.pending.Add(-1)
+}
+
+// Pending returns the number of tasks that have not been processed yet
+func (q *TaskQueue) Pending() int {
+	return int(q.pending.Load())
 }
 
 // Close closes queue channel
@@ -6,10 +6,8 @@
 package infradb
 
 import (
-	"encoding/binary"
 	"errors"
 	"fmt"
-	"log"
 	"net"
 
 	// "time"
@@ -53,12 +51,15 @@ type VrfMetadata struct {
 	// and that can be considered a legit value. Using *uint32 the default value
 	// will be nil
 	RoutingTable []*uint32
+	Audit        common.Audit
 }
 
 // Vrf holds VRF info
 type Vrf struct {
-	Name            string
-	Spec            *VrfSpec
+	Name string
+	Spec *VrfSpec
+	// PrevSpec holds the last realized spec while an update is being realized
+	PrevSpec        *VrfSpec
 	Status          *VrfStatus
 	Metadata        *VrfMetadata
 	Svis            map[string]bool
@@ -100,7 +101,7 @@ func NewVrfWithArgs(name string, vni *uint32, loopbackIP, vtepIP *net.IPNet) (*V
 
 	subscribers := eventbus.EBus.GetSubscribers("vrf")
 	if len(subscribers) == 0 {
-		log.Println("NewVrfWithArgs(): No subscribers for Vrf objects")
+		logger.Info("NewVrfWithArgs(): No subscribers for Vrf objects")
 	}
 
 	for _, sub := range subscribers {
@@ -126,15 +127,19 @@ func NewVrf(in *pb.Vrf) (*Vrf, error) {
 	var vip *net.IPNet
 	components := make([]common.Component, 0)
 
-	loopip := make(net.IP, 4)
-	binary.BigEndian.PutUint32(loopip, in.Spec.LoopbackIpPrefix.Addr.GetV4Addr())
-	lip := net.IPNet{IP: loopip, Mask: net.CIDRMask(int(in.Spec.LoopbackIpPrefix.Len), 32)}
+	lip, err := common.ConvertToIPNet(in.Spec.LoopbackIpPrefix)
+	if err != nil {
+		logger.Infow("NewVrf(): Invalid loopback ip prefix", "error", err)
+		return &Vrf{}, err
+	}
 
 	// Parse vtep IP
 	if in.Spec.VtepIpPrefix != nil {
-		vtepip := make(net.IP, 4)
-		binary.BigEndian.PutUint32(vtepip, in.Spec.VtepIpPrefix.Addr.GetV4Addr())
-		vip = &net.IPNet{IP: vtepip, Mask: net.CIDRMask(int(in.Spec.VtepIpPrefix.Len), 32)}
+		vip, err = common.ConvertToIPNet(in.Spec.VtepIpPrefix)
+		if err != nil {
+			logger.Infow("NewVrf(): Invalid vtep ip prefix", "error", err)
+			return &Vrf{}, err
+		}
 	} else {
 		tmpVtepIP := utils.GetIPAddress(config.GlobalConfig.LinuxFrr.DefaultVtep)
 		vip = &tmpVtepIP
@@ -142,7 +147,7 @@ func NewVrf(in *pb.Vrf) (*Vrf, error) {
 
 	subscribers := eventbus.EBus.GetSubscribers("vrf")
 	if len(subscribers) == 0 {
-		log.Println("NewVrf(): No subscribers for Vrf objects")
+		logger.Info("NewVrf(): No subscribers for Vrf objects")
 		return &Vrf{}, errors.New("no subscribers found for vrf")
 	}
 
@@ -155,7 +160,7 @@ func NewVrf(in *pb.Vrf) (*Vrf, error) {
 		Name: in.Name,
 		Spec: &VrfSpec{
 			Vni:        in.Spec.Vni,
-			LoopbackIP: &lip,
+			LoopbackIP: lip,
 			VtepIP:     vip,
 		},
 		Status: &VrfStatus{
@@ -279,7 +284,7 @@ func (in *Vrf) prepareObjectsForReplay(componentName string, vrfSubs []*eventbus
 	vrfComponents := in.Status.Components
 	tempSubs := []*eventbus.Subscriber{}
 	for i, comp := range vrfComponents {
-		if comp.Name == componentName || comp.CompStatus != common.ComponentStatusSuccess {
+		if componentName == allComponents || comp.Name == componentName || comp.CompStatus != common.ComponentStatusSuccess {
 			in.Status.Components[i] = common.Component{Name: comp.Name, CompStatus: common.ComponentStatusPending, Details: ""}
 			tempSubs = append(tempSubs, vrfSubs[i])
 		}
@@ -288,6 +293,8 @@ func (in *Vrf) prepareObjectsForReplay(componentName string, vrfSubs []*eventbus
 		in.Status.VrfOperStatus = VrfOperStatusDown
 	}
 
+	// The replayed components realize the object as a whole
+	in.PrevSpec = nil
 	in.ResourceVersion = generateVersion()
 	return tempSubs
 }
@@ -0,0 +1,191 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package logging provides the leveled structured loggers of the subsystems of the bridge
+package logging
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// The subsystems match the fields of the loglevel config
+const (
+	// DB is the subsystem of infradb, the task manager and the event bus
+	DB = "db"
+	// Grpc is the subsystem of the gRPC and HTTP servers, of the config and of the startup and shutdown of the bridge
+	Grpc = "grpc"
+	// Linux is the subsystem of the Linux modules, lgm, lci and frr
+	Linux = "linux"
+	// Netlink is the subsystem of the netlink watcher
+	Netlink = "netlink"
+	// P4 is the subsystem of the P4 modules
+	P4 = "p4"
+)
+
+// Subsystems lists all the subsystems
+var Subsystems = []string{DB, Grpc, Linux, Netlink, P4}
+
+// output lets the loggers that are created at package initialization
+// write to the output that is only set up at startup
+type output struct {
+	mtx sync.Mutex
+	w   io.Writer
+}
+
+func (o *output) Write(p []byte) (int, error) {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+	return o.w.Write(p)
+}
+
+func (o *output) Sync() error {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+	if s, ok := o.w.(zapcore.WriteSyncer); ok {
+		return s.Sync()
+	}
+	return nil
+}
+
+var (
+	out     = &output{w: os.Stderr}
+	encoder = zapcore.NewJSONEncoder(zapcore.EncoderConfig{
+		TimeKey:        "time",
+		LevelKey:       "level",
+		NameKey:        "subsystem",
+		CallerKey:      "caller",
+		MessageKey:     "msg",
+		StacktraceKey:  "stacktrace",
+		LineEnding:     zapcore.DefaultLineEnding,
+		EncodeLevel:    zapcore.LowercaseLevelEncoder,
+		EncodeTime:     zapcore.ISO8601TimeEncoder,
+		EncodeDuration: zapcore.StringDurationEncoder,
+		EncodeCaller:   zapcore.ShortCallerEncoder,
+	})
+
+	mtx     sync.Mutex
+	levels  = make(map[string]zap.AtomicLevel)
+	loggers = make(map[string]*zap.Logger)
+)
+
+// SetOutput sets where all the loggers write their records
+func SetOutput(w io.Writer) {
+	out.mtx.Lock()
+	defer out.mtx.Unlock()
+	out.w = w
+}
+
+// Logger returns the logger of the subsystem, its level can be changed at runtime
+func Logger(subsystem string) *zap.Logger {
+	mtx.Lock()
+	defer mtx.Unlock()
+
+	if l, ok := loggers[subsystem]; ok {
+		return l
+	}
+	level := zap.NewAtomicLevelAt(zapcore.InfoLevel)
+	core := zapcore.NewCore(encoder, out, level)
+	l := zap.New(core, zap.AddCaller()).Named(subsystem)
+	levels[subsystem] = level
+	loggers[subsystem] = l
+	return l
+}
+
+// Initialize sets the levels of the subsystems, an empty level keeps the info level
+func Initialize(subsystemLevels map[string]string) error {
+	for subsystem, level := range subsystemLevels {
+		if level == "" {
+			continue
+		}
+		if err := SetLevel(subsystem, level); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetLevel changes the level of a subsystem
+func SetLevel(subsystem, level string) error {
+	lvl, err := zapcore.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	if !isSubsystem(subsystem) {
+		return fmt.Errorf("unknown subsystem %q", subsystem)
+	}
+	Logger(subsystem)
+
+	mtx.Lock()
+	defer mtx.Unlock()
+	levels[subsystem].SetLevel(lvl)
+	return nil
+}
+
+// Levels returns the current level of each subsystem
+func Levels() map[string]string {
+	for _, subsystem := range Subsystems {
+		Logger(subsystem)
+	}
+
+	mtx.Lock()
+	defer mtx.Unlock()
+	result := make(map[string]string, len(levels))
+	for subsystem, level := range levels {
+		result[subsystem] = level.Level().String()
+	}
+	return result
+}
+
+// Sync flushes the buffered records of all the loggers
+func Sync() {
+	mtx.Lock()
+	defer mtx.Unlock()
+	for _, l := range loggers {
+		_ = l.Sync()
+	}
+}
+
+func isSubsystem(subsystem string) bool {
+	for _, s := range Subsystems {
+		if s == subsystem {
+			return true
+		}
+	}
+	return false
+}
+
+// Kind is the field of the kind of an object
+func Kind(kind string) zap.Field {
+	return zap.String("kind", kind)
+}
+
+// Name is the field of the name of an object
+func Name(name string) zap.Field {
+	return zap.String("name", name)
+}
+
+// ResourceVersion is the field of the resource version of an object
+func ResourceVersion(resourceVersion string) zap.Field {
+	return zap.String("resourceVersion", resourceVersion)
+}
+
+// NotificationID is the field of the notification sent to a subscriber
+func NotificationID(notificationID string) zap.Field {
+	return zap.String("notificationID", notificationID)
+}
+
+// Component is the field of the subscriber component
+func Component(component string) zap.Field {
+	return zap.String("component", component)
+}
+
+// Object returns the fields that identify an object, to be passed to the sugared loggers
+func Object(kind, name, resourceVersion string) []interface{} {
+	return []interface{}{Kind(kind), Name(name), ResourceVersion(resourceVersion)}
+}
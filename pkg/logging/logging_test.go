@@ -0,0 +1,123 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package logging provides the leveled structured loggers of the subsystems of the bridge
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestSetLevel(t *testing.T) {
+	tests := map[string]struct {
+		subsystem string
+		level     string
+		expectErr bool
+	}{
+		"debug level":       {subsystem: Netlink, level: "debug", expectErr: false},
+		"error level":       {subsystem: DB, level: "error", expectErr: false},
+		"unknown subsystem": {subsystem: "storage", level: "debug", expectErr: true},
+		"unknown level":     {subsystem: Linux, level: "verbose", expectErr: true},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			before := Levels()
+			err := SetLevel(tt.subsystem, tt.level)
+			if (err != nil) != tt.expectErr {
+				t.Errorf("Expected error %v, received %v", tt.expectErr, err)
+			}
+			after := Levels()
+			if !tt.expectErr && after[tt.subsystem] != tt.level {
+				t.Errorf("Expected level %v, received %v", tt.level, after[tt.subsystem])
+			}
+			if tt.expectErr && len(after) != len(before) {
+				t.Errorf("Expected the levels to be unchanged, received %v", after)
+			}
+			_ = SetLevel(tt.subsystem, before[tt.subsystem])
+		})
+	}
+}
+
+func TestLogger(t *testing.T) {
+	buf := &bytes.Buffer{}
+	SetOutput(buf)
+	defer SetOutput(os.Stderr)
+	defer func() { _ = SetLevel(P4, "info") }()
+
+	if err := SetLevel(P4, "warn"); err != nil {
+		t.Fatal(err)
+	}
+	Logger(P4).Info("hidden", Name("blue"))
+	if buf.Len() != 0 {
+		t.Errorf("Expected info records to be dropped at warn level, received %v", buf.String())
+	}
+
+	Logger(P4).Sugar().Warnw("Failed to realize", append(Object("vrf", "blue", "v1"), Component("lgm"))...)
+	record := map[string]interface{}{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{
+		"level":           "warn",
+		"subsystem":       P4,
+		"msg":             "Failed to realize",
+		"kind":            "vrf",
+		"name":            "blue",
+		"resourceVersion": "v1",
+		"component":       "lgm",
+	}
+	for key, value := range expected {
+		if record[key] != value {
+			t.Errorf("Expected %v to be %v, received %v", key, value, record[key])
+		}
+	}
+}
+
+func TestServer(t *testing.T) {
+	s := NewServer()
+	defer func() { _ = SetLevel(Grpc, "info") }()
+
+	tests := map[string]struct {
+		subsystem string
+		level     string
+		code      codes.Code
+	}{
+		"valid level":       {subsystem: Grpc, level: "debug", code: codes.OK},
+		"missing level":     {subsystem: Grpc, level: "", code: codes.InvalidArgument},
+		"unknown subsystem": {subsystem: "storage", level: "debug", code: codes.InvalidArgument},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			in, err := structpb.NewStruct(map[string]interface{}{"subsystem": tt.subsystem, "level": tt.level})
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = s.SetLogLevel(context.Background(), in)
+			if status.Code(err) != tt.code {
+				t.Errorf("Expected code %v, received %v", tt.code, err)
+			}
+		})
+	}
+
+	levels, err := s.GetLogLevels(context.Background(), &emptypb.Empty{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(levels.GetFields()) != len(Subsystems) {
+		t.Errorf("Expected the levels of %v, received %v", Subsystems, levels)
+	}
+	if level := levels.GetFields()[Grpc].GetStringValue(); level != "debug" {
+		t.Errorf("Expected grpc level debug, received %v", level)
+	}
+}
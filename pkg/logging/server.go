@@ -0,0 +1,122 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package logging provides the leveled structured loggers of the subsystems of the bridge
+package logging
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// ServiceName is the name of the logging admin service
+const ServiceName = "opi_evpn_bridge.v1.LoggingService"
+
+// AdminServer is the server API for the LoggingService
+type AdminServer interface {
+	GetLogLevels(context.Context, *emptypb.Empty) (*structpb.Struct, error)
+	SetLogLevel(context.Context, *structpb.Struct) (*structpb.Struct, error)
+}
+
+// AdminServiceDesc describes the LoggingService.
+// There are no generated messages for the log levels so they are carried
+// as google.protobuf.Struct, a map of the subsystems to their level.
+var AdminServiceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*AdminServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetLogLevels",
+			Handler:    getLogLevelsHandler,
+		},
+		{
+			MethodName: "SetLogLevel",
+			Handler:    setLogLevelHandler,
+		},
+	},
+	Streams: []grpc.StreamDesc{},
+}
+
+// RegisterAdminServer registers the LoggingService to the gRPC server
+func RegisterAdminServer(s grpc.ServiceRegistrar, srv AdminServer) {
+	s.RegisterService(&AdminServiceDesc, srv)
+}
+
+func getLogLevelsHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).GetLogLevels(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/" + ServiceName + "/GetLogLevels",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).GetLogLevels(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func setLogLevelHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(structpb.Struct)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).SetLogLevel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/" + ServiceName + "/SetLogLevel",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).SetLogLevel(ctx, req.(*structpb.Struct))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Server implements the LoggingService
+type Server struct{}
+
+// NewServer creates the logging admin server
+func NewServer() *Server {
+	return &Server{}
+}
+
+// GetLogLevels returns the level of each subsystem
+func (s *Server) GetLogLevels(_ context.Context, _ *emptypb.Empty) (*structpb.Struct, error) {
+	return levelsStruct()
+}
+
+// SetLogLevel changes the level of a subsystem at runtime.
+// The request holds the subsystem and the level fields, the response the levels of all the subsystems.
+func (s *Server) SetLogLevel(_ context.Context, in *structpb.Struct) (*structpb.Struct, error) {
+	subsystem := in.GetFields()["subsystem"].GetStringValue()
+	level := in.GetFields()["level"].GetStringValue()
+	if subsystem == "" || level == "" {
+		return nil, status.Error(codes.InvalidArgument, "subsystem and level are required")
+	}
+	if err := SetLevel(subsystem, level); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+	Logger(Grpc).Info("Log level has been changed", zap.String("target", subsystem), zap.String("level", level))
+	return levelsStruct()
+}
+
+func levelsStruct() (*structpb.Struct, error) {
+	levels := Levels()
+	fields := make(map[string]interface{}, len(levels))
+	for subsystem, level := range levels {
+		fields[subsystem] = level
+	}
+	return structpb.NewStruct(fields)
+}
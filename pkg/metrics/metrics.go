@@ -0,0 +1,266 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package metrics holds the Prometheus metrics of the bridge
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+const namespace = "opi_evpn_bridge"
+
+// Registry holds all the metrics of the bridge together with the Go runtime and process metrics
+var Registry = prometheus.NewRegistry()
+
+var (
+	// GrpcRequests counts the handled gRPC calls by method and status code
+	GrpcRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "grpc",
+		Name:      "requests_total",
+		Help:      "Number of handled gRPC calls by method and status code.",
+	}, []string{"method", "code"})
+
+	// GrpcLatency observes the time to handle the gRPC calls by method
+	GrpcLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "grpc",
+		Name:      "request_duration_seconds",
+		Help:      "Time to handle the gRPC calls by method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method"})
+
+	// TaskQueueDepth is the number of tasks waiting in the task manager queue
+	TaskQueueDepth = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "taskmanager",
+		Name:      "queue_depth",
+		Help:      "Number of tasks waiting in the task manager queue.",
+	})
+
+	// TaskRetries counts the tasks that have been requeued by object type and reason
+	TaskRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "taskmanager",
+		Name:      "retries_total",
+		Help:      "Number of tasks requeued by object type and reason.",
+	}, []string{"object_type", "reason"})
+
+	// SubscriberLatency observes the time the subscribers take to handle an event
+	SubscriberLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "subscriber",
+		Name:      "handling_duration_seconds",
+		Help:      "Time the subscribers take to handle an event by subscriber and event type.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"subscriber", "event_type"})
+
+	// SubscriberErrors counts the events the subscribers have failed to realize
+	SubscriberErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "subscriber",
+		Name:      "errors_total",
+		Help:      "Number of events the subscribers have failed to realize by subscriber and event type.",
+	}, []string{"subscriber", "event_type"})
+
+	// FrrCommandLatency observes the time of the commands sent to the FRR daemons
+	FrrCommandLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "frr",
+		Name:      "command_duration_seconds",
+		Help:      "Time of the commands sent to the FRR daemons by daemon.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"daemon"})
+
+	// FrrCommandFailures counts the commands sent to the FRR daemons that have failed
+	FrrCommandFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "frr",
+		Name:      "command_failures_total",
+		Help:      "Number of commands sent to the FRR daemons that have failed by daemon.",
+	}, []string{"daemon"})
+
+	// NetlinkPollDuration observes the time of a resync of the netlink watcher with the kernel
+	NetlinkPollDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "netlink",
+		Name:      "poll_duration_seconds",
+		Help:      "Time of a resync of the netlink watcher with the kernel.",
+		Buckets:   prometheus.DefBuckets,
+	})
+
+	// NetlinkTableSize is the number of entries of the netlink watcher tables
+	NetlinkTableSize = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "netlink",
+		Name:      "table_entries",
+		Help:      "Number of entries of the netlink watcher tables by table.",
+	}, []string{"table"})
+
+	// NetlinkKernelEvents counts the kernel changes received by the netlink watcher
+	NetlinkKernelEvents = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "netlink",
+		Name:      "kernel_events_total",
+		Help:      "Number of kernel changes received by the netlink watcher by type.",
+	}, []string{"type"})
+
+	// NetlinkEventsDropped counts the netlink watcher events dropped for the subscribers that fell behind
+	NetlinkEventsDropped = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "netlink",
+		Name:      "events_dropped_total",
+		Help:      "Number of netlink watcher events dropped for the subscribers that fell behind by event.",
+	}, []string{"event"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		GrpcRequests,
+		GrpcLatency,
+		TaskQueueDepth,
+		TaskRetries,
+		SubscriberLatency,
+		SubscriberErrors,
+		FrrCommandLatency,
+		FrrCommandFailures,
+		NetlinkPollDuration,
+		NetlinkTableSize,
+		NetlinkKernelEvents,
+		NetlinkEventsDropped,
+	)
+}
+
+// Handler returns the HTTP handler of the /metrics endpoint
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// Since returns the seconds elapsed since start to be observed
+func Since(start time.Time) float64 {
+	return time.Since(start).Seconds()
+}
+
+// UnaryServerInterceptor returns the unary interceptor that counts and times the gRPC calls
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		observeCall(info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns the stream interceptor that counts and times the gRPC calls
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, stream)
+		observeCall(info.FullMethod, start, err)
+		return err
+	}
+}
+
+func observeCall(method string, start time.Time, err error) {
+	GrpcLatency.WithLabelValues(method).Observe(Since(start))
+	GrpcRequests.WithLabelValues(method, status.Code(err).String()).Inc()
+}
+
+// ObjectCounter returns the number of intent objects by kind and oper status
+type ObjectCounter func() (map[string]map[string]int, error)
+
+// objectCollector reads the object counts from the store on each scrape
+type objectCollector struct {
+	desc  *prometheus.Desc
+	count ObjectCounter
+}
+
+// NewObjectCollector creates the collector of the number of intent objects
+func NewObjectCollector(count ObjectCounter) prometheus.Collector {
+	return &objectCollector{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "infradb", "objects"),
+			"Number of intent objects by kind and oper status.",
+			[]string{"kind", "oper_status"}, nil,
+		),
+		count: count,
+	}
+}
+
+// Describe sends the description of the object counts
+func (c *objectCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+// Collect sends the object counts, the scrape fails when the store cannot be read
+func (c *objectCollector) Collect(ch chan<- prometheus.Metric) {
+	counts, err := c.count()
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(c.desc, err)
+		return
+	}
+	for kind, statuses := range counts {
+		for operStatus, count := range statuses {
+			ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(count), kind, operStatus)
+		}
+	}
+}
+
+// CacheCounter returns the hits, the misses and the number of entries of the object cache
+type CacheCounter func() (uint64, uint64, int)
+
+// cacheCollector reads the counters of the object cache on each scrape
+type cacheCollector struct {
+	hits    *prometheus.Desc
+	misses  *prometheus.Desc
+	entries *prometheus.Desc
+	count   CacheCounter
+}
+
+// NewCacheCollector creates the collector of the counters of the infradb object cache
+func NewCacheCollector(count CacheCounter) prometheus.Collector {
+	return &cacheCollector{
+		hits: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "infradb", "cache_hits_total"),
+			"Number of infradb object reads served by the cache.",
+			nil, nil,
+		),
+		misses: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "infradb", "cache_misses_total"),
+			"Number of infradb object reads that went to the store.",
+			nil, nil,
+		),
+		entries: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "infradb", "cache_entries"),
+			"Number of infradb objects held by the cache.",
+			nil, nil,
+		),
+		count: count,
+	}
+}
+
+// Describe sends the descriptions of the cache counters
+func (c *cacheCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.hits
+	ch <- c.misses
+	ch <- c.entries
+}
+
+// Collect sends the cache counters
+func (c *cacheCollector) Collect(ch chan<- prometheus.Metric) {
+	hits, misses, entries := c.count()
+	ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(hits))
+	ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(misses))
+	ch <- prometheus.MustNewConstMetric(c.entries, prometheus.GaugeValue, float64(entries))
+}
@@ -0,0 +1,93 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package metrics holds the Prometheus metrics of the bridge
+package metrics
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	const method = "/opi_api.network.evpn_gw.v1alpha1.VrfService/GetVrf"
+	tests := map[string]struct {
+		err  error
+		code codes.Code
+	}{
+		"successful call": {err: nil, code: codes.OK},
+		"failed call":     {err: status.Error(codes.NotFound, "unable to find key"), code: codes.NotFound},
+	}
+
+	interceptor := UnaryServerInterceptor()
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			before := testutil.ToFloat64(GrpcRequests.WithLabelValues(method, tt.code.String()))
+			handler := func(context.Context, interface{}) (interface{}, error) { return nil, tt.err }
+
+			_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+			if !errors.Is(err, tt.err) {
+				t.Errorf("Expected error %v, received %v", tt.err, err)
+			}
+			if after := testutil.ToFloat64(GrpcRequests.WithLabelValues(method, tt.code.String())); after != before+1 {
+				t.Errorf("Expected %v calls with code %v, received %v", before+1, tt.code, after)
+			}
+		})
+	}
+	if n := testutil.CollectAndCount(GrpcLatency, namespace+"_grpc_request_duration_seconds"); n == 0 {
+		t.Error("Expected the call latency to be observed")
+	}
+}
+
+func TestObjectCollector(t *testing.T) {
+	collector := NewObjectCollector(func() (map[string]map[string]int, error) {
+		return map[string]map[string]int{
+			"vrf": {"up": 2, "down": 1},
+			"svi": {},
+		}, nil
+	})
+	expected := `
+# HELP opi_evpn_bridge_infradb_objects Number of intent objects by kind and oper status.
+# TYPE opi_evpn_bridge_infradb_objects gauge
+opi_evpn_bridge_infradb_objects{kind="vrf",oper_status="down"} 1
+opi_evpn_bridge_infradb_objects{kind="vrf",oper_status="up"} 2
+`
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(expected)); err != nil {
+		t.Error(err)
+	}
+
+	failing := NewObjectCollector(func() (map[string]map[string]int, error) {
+		return nil, errors.New("store unreachable")
+	})
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(failing)
+	if _, err := registry.Gather(); err == nil {
+		t.Error("Expected the scrape to fail when the store is unreachable")
+	}
+}
+
+func TestCacheCollector(t *testing.T) {
+	collector := NewCacheCollector(func() (uint64, uint64, int) { return 7, 3, 2 })
+	expected := `
+# HELP opi_evpn_bridge_infradb_cache_entries Number of infradb objects held by the cache.
+# TYPE opi_evpn_bridge_infradb_cache_entries gauge
+opi_evpn_bridge_infradb_cache_entries 2
+# HELP opi_evpn_bridge_infradb_cache_hits_total Number of infradb object reads served by the cache.
+# TYPE opi_evpn_bridge_infradb_cache_hits_total counter
+opi_evpn_bridge_infradb_cache_hits_total 7
+# HELP opi_evpn_bridge_infradb_cache_misses_total Number of infradb object reads that went to the store.
+# TYPE opi_evpn_bridge_infradb_cache_misses_total counter
+opi_evpn_bridge_infradb_cache_misses_total 3
+`
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(expected)); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,166 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package modules holds the registry of the modules that realize the intent on each platform
+package modules
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/logging"
+)
+
+// logger is the logger of the linux subsystem, the modules realize the intent in linux
+var logger = logging.Logger(logging.Linux).Sugar()
+
+// Module is a module of the bridge, registered by its package at initialization
+type Module struct {
+	Name string
+	// BuildEnvs lists the build environments, the platform profiles, the module belongs to
+	BuildEnvs []string
+	// Events lists the event types the module subscribes to
+	Events []string
+	// Priority orders the initialization of the modules, lowest first,
+	// they are deinitialized in the reverse order
+	Priority     int
+	Initialize   func()
+	DeInitialize func()
+	// Stop stops the module but leaves the state it has realized in the kernel
+	// and FRR in place, it is used instead of DeInitialize to preserve the dataplane
+	Stop func()
+}
+
+var (
+	mtx         sync.Mutex
+	registry    = make(map[string]Module)
+	initialized []Module
+)
+
+// Register adds the module to the registry, it panics when the name is already registered
+func Register(m Module) {
+	mtx.Lock()
+	defer mtx.Unlock()
+
+	if _, ok := registry[m.Name]; ok {
+		panic(fmt.Sprintf("modules: module %s is registered twice", m.Name))
+	}
+	registry[m.Name] = m
+}
+
+// Lookup returns the registered module with the given name
+func Lookup(name string) (Module, bool) {
+	mtx.Lock()
+	defer mtx.Unlock()
+
+	m, ok := registry[name]
+	return m, ok
+}
+
+// BuildEnvs returns the build environments of all the registered modules
+func BuildEnvs() []string {
+	mtx.Lock()
+	defer mtx.Unlock()
+
+	set := make(map[string]bool)
+	for _, m := range registry {
+		for _, env := range m.BuildEnvs {
+			set[env] = true
+		}
+	}
+	envs := make([]string, 0, len(set))
+	for env := range set {
+		envs = append(envs, env)
+	}
+	sort.Strings(envs)
+	return envs
+}
+
+// Enabled returns the modules of the build environment ordered by priority.
+// When names is not empty only the named modules are enabled, each one must belong to the build environment.
+func Enabled(buildEnv string, names []string) ([]Module, error) {
+	mtx.Lock()
+	defer mtx.Unlock()
+
+	var enabled []Module
+	for _, m := range registry {
+		if m.belongsTo(buildEnv) && (len(names) == 0 || slices.Contains(names, m.Name)) {
+			enabled = append(enabled, m)
+		}
+	}
+	if len(enabled) == 0 {
+		return nil, fmt.Errorf("no module is registered for build env %q", buildEnv)
+	}
+	for _, name := range names {
+		m, ok := registry[name]
+		if !ok {
+			return nil, fmt.Errorf("module %q is not registered", name)
+		}
+		if !m.belongsTo(buildEnv) {
+			return nil, fmt.Errorf("module %q does not belong to build env %q", name, buildEnv)
+		}
+	}
+	sort.Slice(enabled, func(i, j int) bool {
+		if enabled[i].Priority != enabled[j].Priority {
+			return enabled[i].Priority < enabled[j].Priority
+		}
+		return enabled[i].Name < enabled[j].Name
+	})
+	return enabled, nil
+}
+
+// Initialize initializes the enabled modules of the build environment
+func Initialize(buildEnv string, names []string) error {
+	enabled, err := Enabled(buildEnv, names)
+	if err != nil {
+		return err
+	}
+
+	mtx.Lock()
+	defer mtx.Unlock()
+	for _, m := range enabled {
+		logger.Infow("modules: Initializing", "module", m.Name)
+		if m.Initialize != nil {
+			m.Initialize()
+		}
+		initialized = append(initialized, m)
+	}
+	return nil
+}
+
+// DeInitialize deinitializes the modules that have been initialized, in the reverse order
+func DeInitialize() {
+	mtx.Lock()
+	defer mtx.Unlock()
+
+	for i := len(initialized) - 1; i >= 0; i-- {
+		m := initialized[i]
+		logger.Infow("modules: Deinitializing", "module", m.Name)
+		if m.DeInitialize != nil {
+			m.DeInitialize()
+		}
+	}
+	initialized = nil
+}
+
+// Stop stops the modules that have been initialized, in the reverse order,
+// leaving the state they have realized in place
+func Stop() {
+	mtx.Lock()
+	defer mtx.Unlock()
+
+	for i := len(initialized) - 1; i >= 0; i-- {
+		m := initialized[i]
+		logger.Infow("modules: Stopping", "module", m.Name)
+		if m.Stop != nil {
+			m.Stop()
+		}
+	}
+	initialized = nil
+}
+
+func (m *Module) belongsTo(buildEnv string) bool {
+	return slices.Contains(m.BuildEnvs, buildEnv)
+}
@@ -0,0 +1,130 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package modules holds the registry of the modules that realize the intent on each platform
+package modules
+
+import (
+	"reflect"
+	"testing"
+)
+
+// resetRegistry replaces the registry with the given modules for the duration of the test
+func resetRegistry(t *testing.T, modules ...Module) {
+	t.Helper()
+	saved := registry
+	registry = make(map[string]Module)
+	t.Cleanup(func() { registry = saved })
+	for _, m := range modules {
+		Register(m)
+	}
+}
+
+func TestEnabled(t *testing.T) {
+	resetRegistry(t,
+		Module{Name: "frr", BuildEnvs: []string{"ci", "e2000"}, Priority: 3},
+		Module{Name: "lgm", BuildEnvs: []string{"ci", "e2000"}, Priority: 1},
+		Module{Name: "lci", BuildEnvs: []string{"ci"}, Priority: 2},
+		Module{Name: "vendor", BuildEnvs: []string{"e2000"}, Priority: 2},
+	)
+
+	tests := map[string]struct {
+		buildEnv  string
+		names     []string
+		expected  []string
+		expectErr bool
+	}{
+		"all the modules of ci":     {buildEnv: "ci", expected: []string{"lgm", "lci", "frr"}},
+		"all the modules of e2000":  {buildEnv: "e2000", expected: []string{"lgm", "vendor", "frr"}},
+		"selected modules":          {buildEnv: "ci", names: []string{"frr", "lgm"}, expected: []string{"lgm", "frr"}},
+		"unknown build env":         {buildEnv: "p4", expectErr: true},
+		"unknown module":            {buildEnv: "ci", names: []string{"lgm", "lvm"}, expectErr: true},
+		"module of another profile": {buildEnv: "ci", names: []string{"vendor"}, expectErr: true},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			enabled, err := Enabled(tt.buildEnv, tt.names)
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("Expected error %v, received %v", tt.expectErr, err)
+			}
+			var names []string
+			for _, m := range enabled {
+				names = append(names, m.Name)
+			}
+			if !reflect.DeepEqual(names, tt.expected) {
+				t.Errorf("Expected modules %v, received %v", tt.expected, names)
+			}
+		})
+	}
+
+	if envs := BuildEnvs(); !reflect.DeepEqual(envs, []string{"ci", "e2000"}) {
+		t.Errorf("Expected build envs [ci e2000], received %v", envs)
+	}
+}
+
+func TestInitialize(t *testing.T) {
+	var calls []string
+	module := func(name string, priority int) Module {
+		return Module{
+			Name:         name,
+			BuildEnvs:    []string{"ci"},
+			Priority:     priority,
+			Initialize:   func() { calls = append(calls, "init "+name) },
+			DeInitialize: func() { calls = append(calls, "deinit "+name) },
+		}
+	}
+	resetRegistry(t, module("frr", 3), module("lgm", 1), module("lci", 2))
+
+	if err := Initialize("ci", nil); err != nil {
+		t.Fatal(err)
+	}
+	DeInitialize()
+	DeInitialize()
+
+	expected := []string{"init lgm", "init lci", "init frr", "deinit frr", "deinit lci", "deinit lgm"}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("Expected calls %v, received %v", expected, calls)
+	}
+	if err := Initialize("e2000", nil); err == nil {
+		t.Error("Expected an error for a build env without modules")
+	}
+}
+
+func TestStop(t *testing.T) {
+	var calls []string
+	module := func(name string, priority int, stop bool) Module {
+		m := Module{
+			Name:         name,
+			BuildEnvs:    []string{"ci"},
+			Priority:     priority,
+			DeInitialize: func() { calls = append(calls, "deinit "+name) },
+		}
+		if stop {
+			m.Stop = func() { calls = append(calls, "stop "+name) }
+		}
+		return m
+	}
+	resetRegistry(t, module("frr", 3, true), module("lgm", 1, true), module("netlink", 10, false))
+
+	if err := Initialize("ci", nil); err != nil {
+		t.Fatal(err)
+	}
+	Stop()
+	DeInitialize()
+
+	expected := []string{"stop frr", "stop lgm"}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("Expected calls %v, received %v", expected, calls)
+	}
+}
+
+func TestRegister_Twice(t *testing.T) {
+	resetRegistry(t, Module{Name: "lgm"})
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected a panic when a module is registered twice")
+		}
+	}()
+	Register(Module{Name: "lgm"})
+}
@@ -3,19 +3,24 @@ package netlink
 import (
 	"context"
 	"fmt"
-	"log"
 	"net"
 	"os"
 	"reflect"
+	"strconv"
+	"strings"
 	"sync/atomic"
 
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/logging"
 	eb "github.com/opiproject/opi-evpn-bridge/pkg/netlink/eventbus"
 	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
 	vn "github.com/vishvananda/netlink"
 	"golang.org/x/sys/unix"
 )
 
+// logger is the logger of the netlink subsystem
+var logger = logging.Logger(logging.Netlink).Sugar()
+
 var ctx context.Context
 
 var nlink utils.Netlink
@@ -23,8 +28,8 @@ var nlink utils.Netlink
 // EventBus variable
 var EventBus = eb.NewEventBus()
 
-// pollInterval variable
-var pollInterval int
+// pollInterval variable, in seconds, changed when the config is reloaded
+var pollInterval atomic.Int64
 
 // grd default route bool variable
 var grdDefaultRoute bool
@@ -35,38 +40,11 @@ var enableEcmp bool
 // phyPorts variable
 var phyPorts = make(map[string]int)
 
-// stopMonitoring variable
-var stopMonitoring atomic.Bool
-
-// linkTable wg sync.WaitGroup
-var linkTable []vn.Link
-
-// vrfList netlink libarary var
-var vrfList []vn.Link
-
-// deviceList netlink libarary var
-var deviceList []vn.Link
-
-// vlanList netlink libarary var
-var vlanList []vn.Link
-
-// bridgeList netlink libarary var
-var bridgeList []vn.Link
+// stopMonitoring is closed to stop the polling of the kernel
+var stopMonitoring chan struct{}
 
-// vxlanList netlink libarary var
-var vxlanList []vn.Link
-
-// linkList netlink libarary var
-var linkList []vn.Link
-
-// NameIndex netlink library var
-var nameIndex = make(map[int]string)
-
-// oldgenmap old map
-var oldgenmap = make(map[interface{}]interface{})
-
-// latestgenmap latest map
-var latestgenmap = make(map[interface{}]interface{})
+// monitorDone is closed once the last poll of the kernel has finished
+var monitorDone chan struct{}
 
 const (
 	strNone  = "NONE"
@@ -94,20 +72,57 @@ type Event struct {
 	Operation Operations
 }
 
-// getState gets the state for the neighbor
-func getState(s string) int {
-	neighState := map[string]int{
-		"NONE":       vn.NUD_NONE,
-		"INCOMPLETE": vn.NUD_INCOMPLETE,
-		"REACHABLE":  vn.NUD_REACHABLE,
-		"STALE":      vn.NUD_STALE,
-		"DELAY":      vn.NUD_DELAY,
-		"PROBE":      vn.NUD_PROBE,
-		"FAILED":     vn.NUD_FAILED,
-		"NOARP":      vn.NUD_NOARP,
-		"PERMANENT":  vn.NUD_PERMANENT,
+// linkName returns the name of the link, looked up in the kernel if not known yet
+func linkName(index int) string {
+	if index == 0 {
+		return ""
 	}
-	return neighState[s]
+	if name, ok := watcher.lookupLinkName(index); ok {
+		return name
+	}
+	link, err := vn.LinkByIndex(index)
+	if err != nil {
+		return ""
+	}
+	watcher.setLinkName(index, link.Attrs().Name)
+	return link.Attrs().Name
+}
+
+// linkIndex returns the index of the link, looked up in the kernel once per resync
+func linkIndex(name string) int {
+	if index, ok := watcher.linkIndexes[name]; ok {
+		return index
+	}
+	var index int
+	if link, err := vn.LinkByName(name); err == nil {
+		index = link.Attrs().Index
+		watcher.setLinkName(index, name)
+	}
+	watcher.linkIndexes[name] = index
+	return index
+}
+
+// protoNames are the names of the route and neighbor protocols as printed by iproute2
+var protoNames = map[int]string{
+	unix.RTPROT_UNSPEC:   "unspec",
+	unix.RTPROT_REDIRECT: "redirect",
+	unix.RTPROT_KERNEL:   "kernel",
+	unix.RTPROT_BOOT:     "boot",
+	unix.RTPROT_STATIC:   "static",
+	unix.RTPROT_ZEBRA:    zebraStr,
+	unix.RTPROT_BGP:      "bgp",
+	unix.RTPROT_ISIS:     "isis",
+	unix.RTPROT_OSPF:     "ospf",
+	unix.RTPROT_RIP:      "rip",
+	unix.RTPROT_EIGRP:    "eigrp",
+}
+
+// getProtoName gets the name of the protocol, or its number if unnamed
+func getProtoName(proto int) string {
+	if name, ok := protoNames[proto]; ok {
+		return name
+	}
+	return strconv.Itoa(proto)
 }
 
 // getFlagString return flag of type string
@@ -146,19 +161,28 @@ func getFlag(s string) int {
 	return f
 }
 
-// netMaskToInt converts a CIDR network mask (e.g., 24 for a /24 subnet) to a 4-octet netmask.
-func netMaskToInt(mask int) (netmaskint [4]uint8) {
-	// Perform initial validation and parse the CIDR using a dummy IP.
-	_, ipv4Net, err := net.ParseCIDR(fmt.Sprintf("0.0.0.0/%d", mask))
+// parseRouteDst parses the destination of a route as printed by iproute2.
+// A destination without a prefix length is a host route and "default" is the IPv4 default route.
+func parseRouteDst(dst string) *net.IPNet {
+	if dst == "default" {
+		return &net.IPNet{IP: net.IPv4zero.To4(), Mask: net.CIDRMask(0, 32)}
+	}
+	if !strings.Contains(dst, "/") {
+		ip := net.ParseIP(dst)
+		if ip4 := ip.To4(); ip4 != nil {
+			return &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}
+	}
+	ip, ipNet, err := net.ParseCIDR(dst)
 	if err != nil {
-		return [4]uint8{}
+		logger.Errorw("netlink: Failed to parse route destination", "destination", dst, "error", err)
+		return &net.IPNet{}
 	}
-
-	// Initialize an array to hold the subnet mask.
-	var maskArray [4]uint8
-	copy(maskArray[:], ipv4Net.Mask)
-
-	return maskArray
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	return &net.IPNet{IP: ip, Mask: ipNet.Mask}
 }
 
 // dumpDBs dumps the databse
@@ -168,24 +192,20 @@ func dumpDBs() {
 		panic(err)
 	}
 	if err := os.Truncate("netlink_dump", 0); err != nil {
-		log.Printf("netlink: Failed to truncate: %v", err)
+		logger.Errorw("netlink: Failed to truncate", "error", err)
 	}
 	str := dumpRouteDB()
-	log.Printf("\n")
 	str += dumpNexthDB()
-	log.Printf("\n")
 	str += dumpNeighDB()
-	log.Printf("\n")
 	str += dumpFDB()
-	log.Printf("\n")
 	str += dumpL2NexthDB()
 	_, err = file.WriteString(str)
 	if err != nil {
-		log.Printf("netlink: %v", err)
+		logger.Infow("netlink: Failed to write the dump", "error", err)
 	}
 	err = file.Close()
 	if err != nil {
-		log.Printf("netlink: error closing file: %v", err)
+		logger.Errorw("netlink: error closing file", "error", err)
 	}
 }
 
@@ -199,13 +219,43 @@ func checkProto(proto int) bool {
 }
 
 func notifyDBCompChanges[K comparable, V any](latestDB, oldDB map[K]V, eventType int, ops Operations) {
-	oldgenmap = make(map[interface{}]interface{})
-	latestgenmap = make(map[interface{}]interface{})
+	oldgenmap := make(map[interface{}]interface{})
+	latestgenmap := make(map[interface{}]interface{})
+	// The entries carried over from the current DB are the same and have not changed
 	for k, v := range latestDB {
-		latestgenmap[k] = v
+		if old, ok := oldDB[k]; !ok || any(old) != any(v) {
+			latestgenmap[k] = v
+		}
 	}
 	for k, v := range oldDB {
-		oldgenmap[k] = v
+		if latest, ok := latestDB[k]; !ok || any(latest) != any(v) {
+			oldgenmap[k] = v
+		}
+	}
+	event := Event{
+		EventType: eventType,
+		Operation: ops,
+	}
+	notify_changes(latestgenmap, oldgenmap, event)
+}
+
+// notifyReplacedChanges notifies the changes of the entries replaced in the latest DB,
+// the entries replaced are nil for the keys added
+func notifyReplacedChanges[K comparable, V any](latestDB, replaced map[K]V, eventType int, ops Operations) {
+	oldgenmap := make(map[interface{}]interface{})
+	latestgenmap := make(map[interface{}]interface{})
+	var none V
+	for k, old := range replaced {
+		latest, ok := latestDB[k]
+		if ok && any(latest) == any(old) {
+			continue
+		}
+		if ok {
+			latestgenmap[k] = latest
+		}
+		if any(old) != any(none) {
+			oldgenmap[k] = old
+		}
 	}
 	event := Event{
 		EventType: eventType,
@@ -232,7 +282,7 @@ func notify_changes(new_db map[interface{}]interface{}, old_db map[interface{}]i
 				var ok bool
 				status, ok = v1.(VrfStatusGetter)
 				if !ok {
-					log.Printf("Netlink: Invalid Type")
+					logger.Infow("Netlink: Invalid Type")
 					continue
 				}
 				if status.GetVrfOperStatus() == infradb.VrfOperStatusToBeDeleted {
@@ -274,12 +324,12 @@ func deepCheck(v1 interface{}, v2 interface{}) bool {
 	case *L2NexthopStruct:
 		return t.deepEqual(v2.(*L2NexthopStruct), true)
 	default:
-		log.Printf("netlink: Error Unknown types %T and %T are passed\n", v1, v2)
+		logger.Errorw("netlink: Error Unknown types are passed", "type1", fmt.Sprintf("%T", v1), "type2", fmt.Sprintf("%T", v2))
 		return true
 	}
 }
 
 func notifyAddDel(r interface{}, event string) {
-	log.Printf("netlink: Notify event: %s\n", event)
+	logger.Infow("netlink: Notify event", "event", event)
 	EventBus.Publish(event, r)
 }
@@ -6,18 +6,68 @@ package eventbus
 
 import (
 	"sync"
+	"sync/atomic"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/metrics"
+)
+
+// DefaultQueueSize is the number of events queued for a subscriber before the overflow policy applies
+const DefaultQueueSize = 4096
+
+// OverflowPolicy tells which event is dropped when the queue of a subscriber is full
+type OverflowPolicy int
+
+const (
+	// DropOldest drops the oldest queued event to make room for the new one
+	DropOldest OverflowPolicy = iota
+	// DropNewest drops the new event and keeps the queued ones
+	DropNewest
 )
 
+// Options holds the delivery options of a subscriber
+type Options struct {
+	// QueueSize is the number of events queued for the subscriber, DefaultQueueSize if 0
+	QueueSize int
+	// Overflow is the policy applied when the queue is full
+	Overflow OverflowPolicy
+}
+
+// Event is an event delivered to a subscriber
+type Event struct {
+	// Seq is the sequence number of the event for the subscriber, starting at 1,
+	// a gap in the sequence numbers is the number of events dropped
+	Seq  uint64
+	Data interface{}
+}
+
 // EventBus holds the event bus info
 type EventBus struct {
 	subscribers map[string][]*Subscriber
-	mutex       sync.Mutex
+	mutex       sync.RWMutex
 }
 
-// Subscriber holds the info for each subscriber
+// Subscriber holds the info for each subscriber.
+// The events are queued by Publish and sent on Ch by a goroutine of the subscriber,
+// a slow or stopped consumer only fills its own queue and never blocks the publisher.
 type Subscriber struct {
-	Ch   chan interface{}
+	// Ch receives the events, it is closed once the bus is unsubscribed and the queued events have been received
+	Ch chan Event
+	// Resync is signaled when events have been dropped, the consumer has to read the state again
+	// as the events it receives no longer describe all the changes
+	Resync chan struct{}
+	// Quit is closed by Stop, the queued events are then discarded and Ch closed
 	Quit chan struct{}
+
+	bus       *EventBus
+	eventType string
+	options   Options
+	mutex     sync.Mutex
+	queue     []Event
+	seq       uint64
+	closed    bool
+	wake      chan struct{}
+	stopOnce  sync.Once
+	dropped   atomic.Uint64
 }
 
 // NewEventBus initializes an EventBus object
@@ -27,45 +77,184 @@ func NewEventBus() *EventBus {
 	}
 }
 
-// Subscribe api provides registration of a subscriber to the given eventType
+// Subscribe api provides registration of a subscriber to the given eventType with the default options
 func (e *EventBus) Subscribe(eventType string) *Subscriber {
-	e.mutex.Lock()
-	defer e.mutex.Unlock()
+	return e.SubscribeWithOptions(eventType, Options{})
+}
 
+// SubscribeWithOptions api provides registration of a subscriber to the given eventType
+func (e *EventBus) SubscribeWithOptions(eventType string, options Options) *Subscriber {
+	if options.QueueSize <= 0 {
+		options.QueueSize = DefaultQueueSize
+	}
 	subscriber := &Subscriber{
-		Ch:   make(chan interface{}),
-		Quit: make(chan struct{}),
+		Ch:        make(chan Event),
+		Resync:    make(chan struct{}, 1),
+		Quit:      make(chan struct{}),
+		bus:       e,
+		eventType: eventType,
+		options:   options,
+		wake:      make(chan struct{}, 1),
 	}
+	go subscriber.deliver()
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
 
 	e.subscribers[eventType] = append(e.subscribers[eventType], subscriber)
 
 	return subscriber
 }
 
-// Publish api notifies the subscribers with certain eventType
+// Publish api notifies the subscribers with certain eventType, it never blocks on the subscribers
 func (e *EventBus) Publish(eventType string, data interface{}) {
-	e.mutex.Lock()
-	defer e.mutex.Unlock()
-
-	subscribers, ok := e.subscribers[eventType]
-	if !ok {
-		return
-	}
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
 
-	for _, sub := range subscribers {
-		sub.Ch <- data
+	for _, sub := range e.subscribers[eventType] {
+		sub.enqueue(data)
 	}
 }
 
-// Unsubscribe closes all subscriber channels and empties the subscriber map.
+// Unsubscribe removes all the subscribers, their channels are closed once they have received the queued events
 func (e *EventBus) Unsubscribe() {
 	e.mutex.Lock()
 	defer e.mutex.Unlock()
 
 	for eventName, subs := range e.subscribers {
 		for _, sub := range subs {
-			close(sub.Ch) // Close each channel
+			sub.close()
 		}
 		delete(e.subscribers, eventName) // Remove the entry from the map
 	}
 }
+
+// RemoveSubscriber removes the subscriber from the bus, the other subscribers of its eventType are kept
+func (e *EventBus) RemoveSubscriber(sub *Subscriber) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	subs := e.subscribers[sub.eventType]
+	for i, s := range subs {
+		if s != sub {
+			continue
+		}
+		subs = append(subs[:i], subs[i+1:]...)
+		if len(subs) == 0 {
+			delete(e.subscribers, sub.eventType)
+		} else {
+			e.subscribers[sub.eventType] = subs
+		}
+		return
+	}
+}
+
+// Stop stops the delivery to the subscriber and removes it from the bus, the queued events are discarded
+// and Ch is closed. The consumers that stop receiving before the bus is unsubscribed call it to release the subscriber.
+func (s *Subscriber) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.Quit)
+		s.bus.RemoveSubscriber(s)
+	})
+}
+
+// Seq returns the sequence number of the last event queued for the subscriber
+func (s *Subscriber) Seq() uint64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.seq
+}
+
+// Dropped returns the number of events dropped for the subscriber
+func (s *Subscriber) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Pending returns the number of events queued for the subscriber
+func (s *Subscriber) Pending() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return len(s.queue)
+}
+
+// enqueue queues the event, applying the overflow policy when the queue is full
+func (s *Subscriber) enqueue(data interface{}) {
+	select {
+	case <-s.Quit:
+		return
+	default:
+	}
+	s.mutex.Lock()
+	if s.closed {
+		s.mutex.Unlock()
+		return
+	}
+	s.seq++
+	event := Event{Seq: s.seq, Data: data}
+	overflow := len(s.queue) >= s.options.QueueSize
+	if !overflow {
+		s.queue = append(s.queue, event)
+	} else if s.options.Overflow == DropOldest {
+		s.queue = append(s.queue[1:], event)
+	}
+	s.mutex.Unlock()
+
+	if overflow {
+		s.dropped.Add(1)
+		metrics.NetlinkEventsDropped.WithLabelValues(s.eventType).Inc()
+		select {
+		case s.Resync <- struct{}{}:
+		default:
+		}
+	}
+	s.notify()
+}
+
+// close stops queuing the events, the queued ones are still delivered
+func (s *Subscriber) close() {
+	s.mutex.Lock()
+	s.closed = true
+	s.mutex.Unlock()
+	s.notify()
+}
+
+// notify wakes up the delivery goroutine
+func (s *Subscriber) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// deliver sends the queued events on Ch until the subscriber is closed and its queue empty, or stopped
+func (s *Subscriber) deliver() {
+	defer close(s.Ch)
+	for {
+		s.mutex.Lock()
+		if len(s.queue) == 0 {
+			closed := s.closed
+			s.mutex.Unlock()
+			if closed {
+				return
+			}
+			select {
+			case <-s.wake:
+			case <-s.Quit:
+				return
+			}
+			continue
+		}
+		event := s.queue[0]
+		s.queue[0] = Event{}
+		s.queue = s.queue[1:]
+		s.mutex.Unlock()
+
+		select {
+		case s.Ch <- event:
+		case <-s.Quit:
+			return
+		}
+	}
+}
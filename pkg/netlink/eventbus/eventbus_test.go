@@ -0,0 +1,219 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+
+// Package eventbus handles pub sub
+package eventbus
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// receiveAll receives the events of the subscriber until its channel is closed
+func receiveAll(t *testing.T, sub *Subscriber) []Event {
+	var events []Event
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case e, ok := <-sub.Ch:
+			if !ok {
+				return events
+			}
+			events = append(events, e)
+		case <-timeout:
+			t.Fatalf("Expected the channel to be closed, received %v events", len(events))
+		}
+	}
+}
+
+// waitPending waits for the subscriber to have pending events queued
+func waitPending(t *testing.T, sub *Subscriber, pending int) {
+	deadline := time.Now().Add(10 * time.Second)
+	for sub.Pending() != pending {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %v pending events, received %v", pending, sub.Pending())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestPublishInOrder(t *testing.T) {
+	bus := NewEventBus()
+	sub := bus.Subscribe("route_added")
+	other := bus.Subscribe("route_deleted")
+	for i := 1; i <= 100; i++ {
+		bus.Publish("route_added", i)
+	}
+	bus.Publish("nexthop_added", 0)
+	bus.Unsubscribe()
+
+	events := receiveAll(t, sub)
+	if len(events) != 100 {
+		t.Fatalf("Expected 100 events, received %v", len(events))
+	}
+	for i, e := range events {
+		if e.Seq != uint64(i+1) || e.Data != i+1 {
+			t.Errorf("Expected event %v with sequence number %v, received %+v", i+1, i+1, e)
+		}
+	}
+	if events := receiveAll(t, other); len(events) != 0 {
+		t.Errorf("Expected no event for the other type, received %v", events)
+	}
+	if sub.Dropped() != 0 {
+		t.Errorf("Expected no dropped event, received %v", sub.Dropped())
+	}
+}
+
+func TestOverflow(t *testing.T) {
+	tests := map[string]struct {
+		overflow OverflowPolicy
+		received []uint64
+	}{
+		"drop oldest": {
+			overflow: DropOldest,
+			received: []uint64{1, 4, 5},
+		},
+		"drop newest": {
+			overflow: DropNewest,
+			received: []uint64{1, 2, 3},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			bus := NewEventBus()
+			sub := bus.SubscribeWithOptions("route_added", Options{QueueSize: 2, Overflow: tt.overflow})
+			// The first event is held by the delivery until the consumer receives it
+			bus.Publish("route_added", 1)
+			waitPending(t, sub, 0)
+			for i := 2; i <= 5; i++ {
+				bus.Publish("route_added", i)
+			}
+			select {
+			case <-sub.Resync:
+			default:
+				t.Errorf("Expected a resync to be signaled")
+			}
+			if sub.Dropped() != 2 {
+				t.Errorf("Expected 2 dropped events, received %v", sub.Dropped())
+			}
+			bus.Unsubscribe()
+
+			var received []uint64
+			for _, e := range receiveAll(t, sub) {
+				received = append(received, e.Seq)
+			}
+			if !reflect.DeepEqual(received, tt.received) {
+				t.Errorf("Expected events %v, received %v", tt.received, received)
+			}
+		})
+	}
+}
+
+func TestSlowSubscriber(t *testing.T) {
+	bus := NewEventBus()
+	slow := bus.SubscribeWithOptions("route_added", Options{QueueSize: 10})
+	fast := bus.Subscribe("route_added")
+
+	var received []Event
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for e := range fast.Ch {
+			received = append(received, e)
+		}
+	}()
+	// The publisher is not blocked by the subscriber that does not receive
+	for i := 0; i < 1000; i++ {
+		bus.Publish("route_added", i)
+	}
+	bus.Unsubscribe()
+	<-done
+
+	if len(received) != 1000 {
+		t.Errorf("Expected 1000 events for the fast subscriber, received %v", len(received))
+	}
+	if slow.Dropped() == 0 {
+		t.Errorf("Expected dropped events for the slow subscriber")
+	}
+	slow.Stop()
+	receiveAll(t, slow)
+}
+
+func TestStop(t *testing.T) {
+	bus := NewEventBus()
+	sub := bus.Subscribe("route_added")
+	bus.Publish("route_added", 1)
+	sub.Stop()
+	sub.Stop()
+	receiveAll(t, sub)
+
+	bus.Publish("route_added", 2)
+	if sub.Pending() > 1 || sub.Dropped() != 0 {
+		t.Errorf("Expected the events to be discarded after stop, received %v pending and %v dropped", sub.Pending(), sub.Dropped())
+	}
+	if subs := bus.subscribers["route_added"]; len(subs) != 0 {
+		t.Errorf("Expected the stopped subscriber to be removed, received %v", subs)
+	}
+	bus.Unsubscribe()
+}
+
+func TestRemoveSubscriber(t *testing.T) {
+	bus := NewEventBus()
+	first := bus.Subscribe("route_added")
+	second := bus.Subscribe("route_added")
+	first.Stop()
+	receiveAll(t, first)
+
+	bus.Publish("route_added", 1)
+	if subs := bus.subscribers["route_added"]; len(subs) != 1 || subs[0] != second {
+		t.Errorf("Expected only the second subscriber, received %v", subs)
+	}
+	if first.Seq() != 0 || second.Seq() != 1 {
+		t.Errorf("Expected the event queued for the second subscriber only, received %v and %v", first.Seq(), second.Seq())
+	}
+	bus.Unsubscribe()
+	if events := receiveAll(t, second); len(events) != 1 {
+		t.Errorf("Expected 1 event, received %v", events)
+	}
+}
+
+// TestConcurrentPublish publishes from several goroutines while the bus is unsubscribed, run with -race
+func TestConcurrentPublish(t *testing.T) {
+	bus := NewEventBus()
+	subs := []*Subscriber{
+		bus.Subscribe("route_added"),
+		bus.SubscribeWithOptions("route_added", Options{QueueSize: 1, Overflow: DropNewest}),
+	}
+	var consumers sync.WaitGroup
+	for _, sub := range subs {
+		consumers.Add(1)
+		go func(sub *Subscriber) {
+			defer consumers.Done()
+			var last uint64
+			for e := range sub.Ch {
+				if e.Seq <= last {
+					t.Errorf("Expected increasing sequence numbers, received %v after %v", e.Seq, last)
+				}
+				last = e.Seq
+			}
+		}(sub)
+	}
+
+	var publishers sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		publishers.Add(1)
+		go func() {
+			defer publishers.Done()
+			for j := 0; j < 1000; j++ {
+				bus.Publish("route_added", j)
+			}
+		}()
+	}
+	time.Sleep(time.Millisecond)
+	bus.Unsubscribe()
+	publishers.Wait()
+	consumers.Wait()
+}
@@ -1,11 +1,11 @@
 package netlink
 
 import (
-	"encoding/json"
 	"fmt"
-	"log"
+	"strings"
 
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	vn "github.com/vishvananda/netlink"
 )
 
 // FdbKey structure key for sorting theFDB entries
@@ -14,17 +14,6 @@ type FdbKey struct {
 	Mac    string
 }
 
-// FdbIPStruct fdb ip structure
-type FdbIPStruct struct {
-	Mac    string
-	Ifname string
-	Vlan   int
-	Flags  []string
-	Master string
-	State  string
-	Dst    string
-}
-
 // FdbEntryStruct structure
 type FdbEntryStruct struct {
 	VlanID   int
@@ -39,15 +28,12 @@ type FdbEntryStruct struct {
 	Err      error
 }
 
+// tenantBridge is the bridge of the fdb entries
+const tenantBridge = "br-tenant"
+
 // fdbOperations add, update, delete
 var fdbOperations = Operations{Add: FdbEntryAdded, Update: FdbEntryUpdated, Delete: FdbEntryDeleted}
 
-// fDB Variable
-var fDB = make(map[FdbKey]*FdbEntryStruct)
-
-// latestFDB Variable
-var latestFDB = make(map[FdbKey]*FdbEntryStruct)
-
 // Event Operations
 const (
 	// FdbEntryAdded event const
@@ -59,12 +45,12 @@ const (
 )
 
 // ParseFdb parse the fdb
-func ParseFdb(fdbIP FdbIPStruct) *FdbEntryStruct {
+func ParseFdb(fdbNeigh vn.Neigh) *FdbEntryStruct {
 	var fdbentry FdbEntryStruct
-	fdbentry.VlanID = fdbIP.Vlan
-	fdbentry.Mac = fdbIP.Mac
-	fdbentry.Key = FdbKey{fdbIP.Vlan, fdbIP.Mac}
-	fdbentry.State = fdbIP.State
+	fdbentry.VlanID = fdbNeigh.Vlan
+	fdbentry.Mac = fdbNeigh.HardwareAddr.String()
+	fdbentry.Key = FdbKey{fdbentry.VlanID, fdbentry.Mac}
+	fdbentry.State = getFdbStateStr(fdbNeigh.State)
 	fdbentry.Nexthop = &L2NexthopStruct{}
 	lbs, _ := infradb.GetAllLBs()
 	for _, lb := range lbs {
@@ -79,9 +65,7 @@ func ParseFdb(fdbIP FdbIPStruct) *FdbEntryStruct {
 			fdbentry.bp, _ = infradb.GetBP(bp)
 		}
 	}
-	dev := fdbIP.Ifname
-	dst := fdbIP.Dst
-	fdbentry.Nexthop.ParseL2NH(fdbentry.VlanID, dev, dst, fdbentry.lb, fdbentry.bp)
+	fdbentry.Nexthop.ParseL2NH(fdbentry.VlanID, linkName(fdbNeigh.LinkIndex), fdbNeigh.IP, fdbentry.lb, fdbentry.bp)
 	fdbentry.Type = fdbentry.Nexthop.Type
 	return &fdbentry
 }
@@ -95,37 +79,35 @@ func (fdb *FdbEntryStruct) preFilterMac() bool {
 	return false
 }
 
-// readFDB read the fdb from db
-func readFDB() []*FdbEntryStruct {
-	var fdbs []FdbIPStruct
-	var macs []*FdbEntryStruct
-	var fs *FdbEntryStruct
-
-	cp, err := nlink.ReadFDB(ctx)
-	if err != nil || len(cp) <= 3 {
-		return macs
+// getFdbStateStr gets the state of the fdb entry as printed by bridge fdb
+func getFdbStateStr(state int) string {
+	switch {
+	case state&vn.NUD_PERMANENT != 0:
+		return "permanent"
+	case state&vn.NUD_NOARP != 0:
+		return "static"
+	case state&vn.NUD_STALE != 0:
+		return "stale"
 	}
+	return ""
+}
 
-	var rawMessages []json.RawMessage
-	err = json.Unmarshal([]byte(cp), &rawMessages)
+// readFDB read the fdb from db, the fdb last read is kept if it cannot be read
+func readFDB() []*FdbEntryStruct {
+	fdbs, err := nlink.ReadFDB(ctx, tenantBridge)
 	if err != nil {
-		log.Printf("netlink fdb: JSON unmarshal error: %v %v : %v\n", err, cp, rawMessages)
-		return macs
-	}
-	cps := make([]string, 0, len(rawMessages))
-	for _, rawMsg := range rawMessages {
-		cps = append(cps, string(rawMsg))
-	}
-	for i := 0; i < len(cps); i++ {
-		var fi FdbIPStruct
-		err := json.Unmarshal([]byte(cps[i]), &fi)
-		if err != nil {
-			log.Printf("netlink: error-%v", err)
-		}
-		fdbs = append(fdbs, fi)
+		logger.Errorw("netlink: Failed to read the fdb", "error", err)
+	} else {
+		watcher.kernel.setFdb(fdbs)
 	}
+	return parseFdbEntries(watcher.kernel.fdbEntries())
+}
+
+// parseFdbEntries parses the fdb entries of the kernel to keep
+func parseFdbEntries(fdbs []vn.Neigh) []*FdbEntryStruct {
+	var macs []*FdbEntryStruct
 	for _, m := range fdbs {
-		fs = ParseFdb(m)
+		fs := ParseFdb(m)
 		if fs.preFilterMac() {
 			macs = append(macs, fs)
 		}
@@ -138,7 +120,7 @@ func (fdb *FdbEntryStruct) addFdbEntry() {
 	fdb.addL2Nexthop()
 	// TODO
 	// logger.debug(f"Adding {m.format()}.")
-	latestFDB[fdb.Key] = fdb
+	watcher.latestFDB[fdb.Key] = fdb
 }
 
 // annotate the route
@@ -152,7 +134,7 @@ func (fdb *FdbEntryStruct) annotate() {
 	if l2n != nil {
 		fdb.Metadata["nh_id"] = l2n.ID
 		if l2n.Type == VXLAN {
-			fdbEntry := latestFDB[FdbKey{None, fdb.Mac}]
+			fdbEntry := watcher.latestFDB[FdbKey{None, fdb.Mac}]
 			l2n.Dst = fdbEntry.Nexthop.Dst
 		}
 		switch l2n.Type {
@@ -201,16 +183,15 @@ func (fdb *FdbEntryStruct) deepEqual(fdbOld *FdbEntryStruct, nc bool) bool {
 
 // dumpFDB dump the fdb entries
 func dumpFDB() string {
-	var s string
-	log.Printf("netlink: Dump fDB table:\n")
-	s = "fDB table:\n"
-	for _, n := range latestFDB {
+	var s strings.Builder
+	logger.Infow("netlink: Dump fDB table")
+	s.WriteString("fDB table:\n")
+	for _, n := range watcher.latestFDB {
 		str := fmt.Sprintf("MacAddr(vlan=%d mac=%s state=%s type=%d l2nh_id=%d) ", n.VlanID, n.Mac, n.State, n.Type, n.Nexthop.ID)
-		log.Println(str)
-		s += str
-		s += "\n"
+		logger.Debug(str)
+		s.WriteString(str)
+		s.WriteString("\n")
 	}
-	log.Printf("\n\n\n")
-	s += "\n\n"
-	return s
+	s.WriteString("\n\n")
+	return s.String()
 }
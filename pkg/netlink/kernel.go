@@ -0,0 +1,429 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package netlink handles the netlink related functionality
+package netlink
+
+import (
+	"net"
+	"sort"
+
+	vn "github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
+)
+
+// kernelLink is the part of a link the tables depend on
+type kernelLink struct {
+	name   string
+	master int
+	up     bool
+}
+
+// fdbEntryKey identifies an fdb entry of the kernel
+type fdbEntryKey struct {
+	dev  int
+	vlan int
+	mac  string
+	dst  string
+}
+
+// kernelState is the state of the kernel the tables are derived from. The full resyncs read it again
+// and the kernel changes update it in between, the tables are then derived again for the changed entries
+// without reading the kernel. Only the resyncs touch it, under resyncMtx.
+type kernelState struct {
+	// routes are the routes by table and destination, the routes of a destination differ by metric
+	routes map[int]map[string][]vn.Route
+	// via counts the routes of each key with a nexthop on the link by link
+	via map[int]map[RouteKey]int
+	// neighs are the IP neighbors of the vrfs by destination and link, the GRD has all of them
+	neighs map[string]map[NeighKey]utils.Neigh
+	// fdb are the dynamic fdb entries of the ports of br-tenant
+	fdb map[fdbEntryKey]vn.Neigh
+	// links are the links by index
+	links map[int]kernelLink
+}
+
+// newKernelState returns an empty kernel state
+func newKernelState() kernelState {
+	return kernelState{
+		routes: make(map[int]map[string][]vn.Route),
+		via:    make(map[int]map[RouteKey]int),
+		neighs: make(map[string]map[NeighKey]utils.Neigh),
+		fdb:    make(map[fdbEntryKey]vn.Neigh),
+		links:  make(map[int]kernelLink),
+	}
+}
+
+// routeDst returns the destination of the route as in the keys of the routes
+func routeDst(r *vn.Route) string {
+	return parseRouteDst(getRouteDst(r)).String()
+}
+
+// setRoutes replaces the routes of the table
+func (k *kernelState) setRoutes(table int, routes []vn.Route) {
+	k.unindexTable(table)
+	byDst := make(map[string][]vn.Route, len(routes))
+	for i := range routes {
+		key := RouteKey{Table: table, Dst: routeDst(&routes[i])}
+		byDst[key.Dst] = append(byDst[key.Dst], routes[i])
+		k.indexRoute(key, &routes[i], 1)
+	}
+	k.routes[table] = byDst
+}
+
+// keepTables forgets the routes of the tables that are not kept, the routes of the tables of deleted vrfs
+// are flushed without notification
+func (k *kernelState) keepTables(tables map[int]bool) {
+	for table := range k.routes {
+		if !tables[table] {
+			k.unindexTable(table)
+			delete(k.routes, table)
+		}
+	}
+}
+
+// unindexTable removes the routes of the table from the routes by link
+func (k *kernelState) unindexTable(table int) {
+	for dst, routes := range k.routes[table] {
+		for i := range routes {
+			k.indexRoute(RouteKey{Table: table, Dst: dst}, &routes[i], -1)
+		}
+	}
+}
+
+// indexRoute counts the route of the key on the links of its nexthops, or uncounts it for a negative delta
+func (k *kernelState) indexRoute(key RouteKey, r *vn.Route, delta int) {
+	count := func(index int) {
+		byKey, ok := k.via[index]
+		if !ok {
+			byKey = make(map[RouteKey]int)
+			k.via[index] = byKey
+		}
+		if byKey[key] += delta; byKey[key] <= 0 {
+			delete(byKey, key)
+			if len(byKey) == 0 {
+				delete(k.via, index)
+			}
+		}
+	}
+	count(r.LinkIndex)
+	for _, nh := range r.MultiPath {
+		if nh.LinkIndex != r.LinkIndex {
+			count(nh.LinkIndex)
+		}
+	}
+}
+
+// bestRoute returns the route of lowest metric among the routes of a destination, the kernel forwards with it
+func bestRoute(routes []vn.Route) vn.Route {
+	best := routes[0]
+	for _, r := range routes[1:] {
+		if r.Priority < best.Priority {
+			best = r
+		}
+	}
+	return best
+}
+
+// tableRoutes returns the routes of the table, the route of lowest metric of each destination,
+// in the order of their destinations for the nexthops shared by the routes to be derived the same way
+func (k *kernelState) tableRoutes(table int) []vn.Route {
+	dsts := make([]string, 0, len(k.routes[table]))
+	for dst := range k.routes[table] {
+		dsts = append(dsts, dst)
+	}
+	sort.Strings(dsts)
+	routes := make([]vn.Route, 0, len(dsts))
+	for _, dst := range dsts {
+		routes = append(routes, bestRoute(k.routes[table][dst]))
+	}
+	return routes
+}
+
+// route returns the route of lowest metric of the key
+func (k *kernelState) route(key RouteKey) (vn.Route, bool) {
+	routes := k.routes[key.Table][key.Dst]
+	if len(routes) == 0 {
+		return vn.Route{}, false
+	}
+	return bestRoute(routes), true
+}
+
+// applyRoute applies the route change, it returns the key of the route and whether it has changed
+func (k *kernelState) applyRoute(u vn.RouteUpdate) (RouteKey, bool) {
+	// The routes cloned for the exceptions of IPv6 are not in the routing tables
+	if u.Flags&unix.RTM_F_CLONED != 0 {
+		return RouteKey{}, false
+	}
+	key := RouteKey{Table: u.Table, Dst: routeDst(&u.Route)}
+	byDst, ok := k.routes[u.Table]
+	if !ok {
+		byDst = make(map[string][]vn.Route)
+		k.routes[u.Table] = byDst
+	}
+	routes := byDst[key.Dst]
+	i := 0
+	for ; i < len(routes) && routes[i].Priority != u.Priority; i++ {
+	}
+	switch {
+	case u.Type == unix.RTM_DELROUTE && i == len(routes):
+		return key, false
+	case u.Type == unix.RTM_DELROUTE:
+		k.indexRoute(key, &routes[i], -1)
+		routes = append(routes[:i:i], routes[i+1:]...)
+	case i == len(routes):
+		routes = append(routes, u.Route)
+		k.indexRoute(key, &u.Route, 1)
+	default:
+		k.indexRoute(key, &routes[i], -1)
+		routes[i] = u.Route
+		k.indexRoute(key, &u.Route, 1)
+	}
+	if len(routes) == 0 {
+		delete(byDst, key.Dst)
+	} else {
+		byDst[key.Dst] = routes
+	}
+	return key, true
+}
+
+// routesVia returns the keys of the routes with a nexthop on the link
+func (k *kernelState) routesVia(index int) []RouteKey {
+	keys := make([]RouteKey, 0, len(k.via[index]))
+	for key := range k.via[index] {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// dropRoutesVia forgets the IPv4 routes on the link and returns their keys. The kernel flushes the IPv4 routes
+// of a link that goes down without notification, the multipath routes only lose a nexthop and are kept.
+func (k *kernelState) dropRoutesVia(index int) []RouteKey {
+	var keys []RouteKey
+	for _, key := range k.routesVia(index) {
+		byDst := k.routes[key.Table]
+		routes := byDst[key.Dst]
+		kept := routes[:0:0]
+		for i := range routes {
+			if routes[i].LinkIndex != index || routes[i].Family != vn.FAMILY_V4 {
+				kept = append(kept, routes[i])
+			} else {
+				k.indexRoute(key, &routes[i], -1)
+			}
+		}
+		if len(kept) == len(routes) {
+			continue
+		}
+		keys = append(keys, key)
+		if len(kept) == 0 {
+			delete(byDst, key.Dst)
+		} else {
+			byDst[key.Dst] = kept
+		}
+	}
+	return keys
+}
+
+// gateways returns the keys of the routes of the tables by gateway, the key of a gateway is the one
+// of the neighbor resolving it in the vrf
+func (k *kernelState) gateways(tables map[int]string) map[NeighKey][]RouteKey {
+	gateways := make(map[NeighKey][]RouteKey)
+	add := func(vrf string, gw net.IP, dev int, key RouteKey) {
+		if gw != nil {
+			neighKey := NeighKey{Dst: gw.String(), VrfName: vrf, Dev: dev}
+			gateways[neighKey] = append(gateways[neighKey], key)
+		}
+	}
+	for table, vrf := range tables {
+		for dst, routes := range k.routes[table] {
+			key := RouteKey{Table: table, Dst: dst}
+			for _, r := range routes {
+				add(vrf, r.Gw, r.LinkIndex, key)
+				for _, nh := range r.MultiPath {
+					add(vrf, nh.Gw, nh.LinkIndex, key)
+				}
+			}
+		}
+	}
+	return gateways
+}
+
+// setNeighs replaces the neighbors of the vrf
+func (k *kernelState) setNeighs(vrf string, neighs []utils.Neigh) {
+	byKey := make(map[NeighKey]utils.Neigh, len(neighs))
+	for _, n := range neighs {
+		byKey[NeighKey{Dst: n.IP.String(), Dev: n.LinkIndex}] = n
+	}
+	k.neighs[vrf] = byKey
+}
+
+// vrfNeighs returns the neighbors of the vrf
+func (k *kernelState) vrfNeighs(vrf string) []utils.Neigh {
+	neighs := make([]utils.Neigh, 0, len(k.neighs[vrf]))
+	for _, n := range k.neighs[vrf] {
+		neighs = append(neighs, n)
+	}
+	return neighs
+}
+
+// neighValid checks the neighbor resolves its address
+func neighValid(n *utils.Neigh) bool {
+	return n.State != vn.NUD_NONE && n.State != vn.NUD_INCOMPLETE && n.State != vn.NUD_FAILED
+}
+
+// applyNeigh applies the neighbor change to the neighbors of the vrf, it reports whether the neighbor has changed
+// as seen by the tables, the kernel notifies the changes of the reachability state periodically for each neighbor in use
+func (k *kernelState) applyNeigh(u utils.NeighUpdate, vrf string) bool {
+	neighs, ok := k.neighs[vrf]
+	if !ok {
+		neighs = make(map[NeighKey]utils.Neigh)
+		k.neighs[vrf] = neighs
+	}
+	key := NeighKey{Dst: u.IP.String(), Dev: u.LinkIndex}
+	old, ok := neighs[key]
+	if u.Type == unix.RTM_DELNEIGH {
+		delete(neighs, key)
+		return ok
+	}
+	neighs[key] = u.Neigh
+	return !ok || neighValid(&old) != neighValid(&u.Neigh) || old.HardwareAddr.String() != u.HardwareAddr.String() || old.Protocol != u.Protocol
+}
+
+// moveNeighs moves the neighbors of the link from a vrf to another, one of them empty for none,
+// the neighbors of the link are taken from the GRD, which has them all. It returns the keys of the
+// neighbors in the vrfs.
+func (k *kernelState) moveNeighs(index int, grd, from, to string) []NeighKey {
+	var keys []NeighKey
+	if from != "" {
+		for key := range k.neighs[from] {
+			if key.Dev == index {
+				delete(k.neighs[from], key)
+				keys = append(keys, NeighKey{Dst: key.Dst, VrfName: from, Dev: index})
+			}
+		}
+	}
+	if to != "" {
+		if _, ok := k.neighs[to]; !ok {
+			k.neighs[to] = make(map[NeighKey]utils.Neigh)
+		}
+		for key, n := range k.neighs[grd] {
+			if key.Dev == index {
+				k.neighs[to][key] = n
+				keys = append(keys, NeighKey{Dst: key.Dst, VrfName: to, Dev: index})
+			}
+		}
+	}
+	return keys
+}
+
+// linkNeighs returns the keys of the neighbors of the link in all the vrfs, and forgets them if the link is deleted
+func (k *kernelState) linkNeighs(index int, deleted bool) []NeighKey {
+	var keys []NeighKey
+	for vrf, neighs := range k.neighs {
+		for key := range neighs {
+			if key.Dev == index {
+				keys = append(keys, NeighKey{Dst: key.Dst, VrfName: vrf, Dev: index})
+				if deleted {
+					delete(neighs, key)
+				}
+			}
+		}
+	}
+	return keys
+}
+
+// fdbKey returns the key of the fdb entry
+func fdbKey(n *vn.Neigh) fdbEntryKey {
+	return fdbEntryKey{dev: n.LinkIndex, vlan: n.Vlan, mac: n.HardwareAddr.String(), dst: n.IP.String()}
+}
+
+// setFdb replaces the fdb entries
+func (k *kernelState) setFdb(fdb []vn.Neigh) {
+	k.fdb = make(map[fdbEntryKey]vn.Neigh, len(fdb))
+	for _, n := range fdb {
+		k.fdb[fdbKey(&n)] = n
+	}
+}
+
+// fdbEntries returns the fdb entries in the order of their keys, the l2 nexthops refer to them in that order
+func (k *kernelState) fdbEntries() []vn.Neigh {
+	keys := make([]fdbEntryKey, 0, len(k.fdb))
+	for key := range k.fdb {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.dev != b.dev {
+			return a.dev < b.dev
+		}
+		if a.vlan != b.vlan {
+			return a.vlan < b.vlan
+		}
+		if a.mac != b.mac {
+			return a.mac < b.mac
+		}
+		return a.dst < b.dst
+	})
+	fdb := make([]vn.Neigh, 0, len(keys))
+	for _, key := range keys {
+		fdb = append(fdb, k.fdb[key])
+	}
+	return fdb
+}
+
+// applyFdb applies the change of an fdb entry, it reports whether the entry has changed,
+// the static entries are not read either
+func (k *kernelState) applyFdb(u utils.NeighUpdate) bool {
+	key := fdbKey(&u.Neigh.Neigh)
+	old, ok := k.fdb[key]
+	if u.Type == unix.RTM_DELNEIGH || u.State&(vn.NUD_PERMANENT|vn.NUD_NOARP) != 0 {
+		delete(k.fdb, key)
+		return ok
+	}
+	k.fdb[key] = u.Neigh.Neigh
+	return !ok || getFdbStateStr(old.State) != getFdbStateStr(u.State)
+}
+
+// dropFdb forgets the fdb entries of the link and reports whether it had any
+func (k *kernelState) dropFdb(index int) bool {
+	dropped := false
+	for key := range k.fdb {
+		if key.dev == index {
+			delete(k.fdb, key)
+			dropped = true
+		}
+	}
+	return dropped
+}
+
+// bridgePort checks the link is a port of the bridge
+func (k *kernelState) bridgePort(index int, bridge string) bool {
+	master := k.links[index].master
+	return master != 0 && k.links[master].name == bridge
+}
+
+// masterName returns the name of the master of the link
+func (k *kernelState) masterName(index int) string {
+	master := k.links[index].master
+	if master == 0 {
+		return ""
+	}
+	return k.links[master].name
+}
+
+// setLinks replaces the links
+func (k *kernelState) setLinks(links []vn.Link) {
+	k.links = make(map[int]kernelLink, len(links))
+	for _, link := range links {
+		k.links[link.Attrs().Index] = newKernelLink(link)
+	}
+}
+
+// newKernelLink returns the part of the link the tables depend on
+func newKernelLink(link vn.Link) kernelLink {
+	attrs := link.Attrs()
+	return kernelLink{name: attrs.Name, master: attrs.MasterIndex, up: attrs.Flags&net.FlagUp != 0}
+}
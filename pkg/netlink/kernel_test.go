@@ -0,0 +1,124 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package netlink handles the netlink related functionality
+package netlink
+
+import (
+	"net"
+	"reflect"
+	"testing"
+
+	vn "github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
+)
+
+func TestKernelStateApplyNeigh(t *testing.T) {
+	mac, _ := net.ParseMAC("aa:bb:cc:dd:ee:ff")
+	otherMac, _ := net.ParseMAC("aa:bb:cc:dd:ee:00")
+	neigh := func(msgType uint16, state int, hwAddr net.HardwareAddr) utils.NeighUpdate {
+		return utils.NeighUpdate{Type: msgType, Neigh: utils.Neigh{Neigh: vn.Neigh{LinkIndex: 10, IP: net.ParseIP("10.0.0.2"), State: state, HardwareAddr: hwAddr}}}
+	}
+
+	tests := map[string]struct {
+		updates  []utils.NeighUpdate
+		expected []bool
+	}{
+		"reachability": {
+			updates: []utils.NeighUpdate{
+				neigh(unix.RTM_NEWNEIGH, vn.NUD_REACHABLE, mac),
+				neigh(unix.RTM_NEWNEIGH, vn.NUD_STALE, mac),
+				neigh(unix.RTM_NEWNEIGH, vn.NUD_DELAY, mac),
+			},
+			expected: []bool{true, false, false},
+		},
+		"resolution": {
+			updates: []utils.NeighUpdate{
+				neigh(unix.RTM_NEWNEIGH, vn.NUD_INCOMPLETE, nil),
+				neigh(unix.RTM_NEWNEIGH, vn.NUD_REACHABLE, mac),
+				neigh(unix.RTM_NEWNEIGH, vn.NUD_FAILED, mac),
+			},
+			expected: []bool{true, true, true},
+		},
+		"mac moved": {
+			updates: []utils.NeighUpdate{
+				neigh(unix.RTM_NEWNEIGH, vn.NUD_REACHABLE, mac),
+				neigh(unix.RTM_NEWNEIGH, vn.NUD_REACHABLE, otherMac),
+			},
+			expected: []bool{true, true},
+		},
+		"deleted": {
+			updates: []utils.NeighUpdate{
+				neigh(unix.RTM_NEWNEIGH, vn.NUD_STALE, mac),
+				neigh(unix.RTM_DELNEIGH, vn.NUD_STALE, mac),
+				neigh(unix.RTM_DELNEIGH, vn.NUD_STALE, mac),
+			},
+			expected: []bool{true, true, false},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			k := newKernelState()
+			for i, u := range tt.updates {
+				if changed := k.applyNeigh(u, "blue"); changed != tt.expected[i] {
+					t.Errorf("Expected update %d changed %v, received %v", i, tt.expected[i], changed)
+				}
+			}
+		})
+	}
+}
+
+func TestKernelStateApplyRoute(t *testing.T) {
+	route := func(metric int, link int) vn.Route {
+		return vn.Route{
+			Dst: &net.IPNet{IP: net.IPv4(10, 0, 0, 0).To4(), Mask: net.CIDRMask(24, 32)}, LinkIndex: link, Table: 1001,
+			Family: vn.FAMILY_V4, Priority: metric,
+		}
+	}
+	key := RouteKey{Table: 1001, Dst: "10.0.0.0/24"}
+
+	k := newKernelState()
+	for _, u := range []vn.RouteUpdate{
+		{Type: unix.RTM_NEWROUTE, Route: route(20, 7)},
+		{Type: unix.RTM_NEWROUTE, Route: route(10, 8)},
+	} {
+		if changed, ok := k.applyRoute(u); changed != key || !ok {
+			t.Errorf("Expected route %v to change, received %v %v", key, changed, ok)
+		}
+	}
+	if r, _ := k.route(key); r.LinkIndex != 8 {
+		t.Errorf("Expected the route of lowest metric, received %v", r)
+	}
+	if keys := k.routesVia(7); !reflect.DeepEqual(keys, []RouteKey{key}) {
+		t.Errorf("Expected route %v via link 7, received %v", key, keys)
+	}
+	// The cloned routes are not in the tables
+	cloned := vn.RouteUpdate{Type: unix.RTM_NEWROUTE, Route: route(5, 9)}
+	cloned.Flags = unix.RTM_F_CLONED
+	if _, ok := k.applyRoute(cloned); ok {
+		t.Errorf("Expected the cloned route to be ignored")
+	}
+	// The IPv4 routes of a link that goes down are flushed without notification
+	if keys := k.dropRoutesVia(8); !reflect.DeepEqual(keys, []RouteKey{key}) {
+		t.Errorf("Expected route %v to be dropped, received %v", key, keys)
+	}
+	if r, _ := k.route(key); r.LinkIndex != 7 {
+		t.Errorf("Expected the route of higher metric to be left, received %v", r)
+	}
+	if _, ok := k.applyRoute(vn.RouteUpdate{Type: unix.RTM_DELROUTE, Route: route(20, 7)}); !ok {
+		t.Errorf("Expected route %v to be deleted", key)
+	}
+	if _, ok := k.route(key); ok {
+		t.Errorf("Expected route %v to be deleted", key)
+	}
+	if len(k.via) != 0 {
+		t.Errorf("Expected no route by link, received %v", k.via)
+	}
+	if _, ok := k.applyRoute(vn.RouteUpdate{Type: unix.RTM_DELROUTE, Route: route(20, 7)}); ok {
+		t.Errorf("Expected the deletion of a missing route to change nothing")
+	}
+}
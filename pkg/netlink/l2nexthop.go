@@ -2,8 +2,8 @@ package netlink
 
 import (
 	"fmt"
-	"log"
 	"net"
+	"strings"
 
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
 	vn "github.com/vishvananda/netlink"
@@ -31,18 +31,6 @@ type L2NexthopStruct struct {
 	Metadata map[interface{}]interface{}
 }
 
-// l2nexthopID
-var l2nexthopID = 16
-
-// l2Nexthops Variable
-var l2Nexthops = make(map[L2NexthopKey]*L2NexthopStruct)
-
-// latestL2Nexthop Variable
-var latestL2Nexthop = make(map[L2NexthopKey]*L2NexthopStruct)
-
-// l2NhIDCache
-var l2NhIDCache = make(map[L2NexthopKey]int)
-
 // l2NexthopOperations add, update, delete
 var l2NexthopOperations = Operations{Add: L2NexthopAdded, Update: L2NexthopUpdated, Delete: L2NexthopDeleted}
 
@@ -58,10 +46,10 @@ const (
 
 // nolint
 // ParseL2NH parse the l2hn
-func (l2n *L2NexthopStruct) ParseL2NH(vlanID int, dev string, dst string, lb *infradb.LogicalBridge, bp *infradb.BridgePort) {
+func (l2n *L2NexthopStruct) ParseL2NH(vlanID int, dev string, dst net.IP, lb *infradb.LogicalBridge, bp *infradb.BridgePort) {
 	l2n.Dev = dev
 	l2n.VlanID = vlanID
-	l2n.Dst = net.IP(dst)
+	l2n.Dst = dst
 	l2n.Key = L2NexthopKey{l2n.Dev, l2n.VlanID, string(l2n.Dst)}
 	l2n.lb = lb
 	l2n.bp = bp
@@ -77,21 +65,20 @@ func (l2n *L2NexthopStruct) ParseL2NH(vlanID int, dev string, dst string, lb *in
 	}
 }
 
-// L2NHAssignID get nexthop id
+// l2NexthopIDKey returns the key of the l2 nexthop in the id pool,
+// it is quoted so that it can be persisted, the destination is a raw IP
+func l2NexthopIDKey(key L2NexthopKey) string {
+	return fmt.Sprintf("%#v", key)
+}
+
+// L2NHAssignID get nexthop id, the same id is returned for the same key
 func L2NHAssignID(key L2NexthopKey) int {
-	id := l2NhIDCache[key]
-	if id == 0 {
-		// Assigne a free id and insert it into the cache
-		id = l2nexthopID
-		l2NhIDCache[key] = id
-		l2nexthopID++
-	}
-	return id
+	return int(watcher.l2NhIDPool.GetID(l2NexthopIDKey(key)))
 }
 
 // addL2Nexthop add the l2 nexthop
 func (fdb *FdbEntryStruct) addL2Nexthop() {
-	latestNexthop, ok := latestL2Nexthop[fdb.Nexthop.Key]
+	latestNexthop, ok := watcher.latestL2Nexthop[fdb.Nexthop.Key]
 	if ok && latestNexthop != nil {
 		latestNexthop.FdbRefs = append(latestNexthop.FdbRefs, fdb)
 		fdb.Nexthop = latestNexthop
@@ -99,7 +86,7 @@ func (fdb *FdbEntryStruct) addL2Nexthop() {
 		latestNexthop = fdb.Nexthop
 		latestNexthop.FdbRefs = append(latestNexthop.FdbRefs, fdb)
 		latestNexthop.ID = L2NHAssignID(latestNexthop.Key)
-		latestL2Nexthop[latestNexthop.Key] = latestNexthop
+		watcher.latestL2Nexthop[latestNexthop.Key] = latestNexthop
 		fdb.Nexthop = latestNexthop
 	}
 }
@@ -127,15 +114,15 @@ func (l2n *L2NexthopStruct) annotate() {
 			if ok {
 				//  # For now pick the first physical nexthop (no ECMP yet)
 				phyNh := r.Nexthops[0]
-				link, _ := vn.LinkByName(nameIndex[phyNh.nexthop.LinkIndex])
+				link, _ := vn.LinkByName(watcher.LinkName(phyNh.nexthop.LinkIndex))
 				l2n.Metadata["phy_smac"] = link.Attrs().HardwareAddr.String()
-				l2n.Metadata["egress_vport"] = phyPorts[nameIndex[phyNh.nexthop.LinkIndex]]
+				l2n.Metadata["egress_vport"] = phyPorts[watcher.LinkName(phyNh.nexthop.LinkIndex)]
 				if phyNh.Neighbor != nil {
 					if phyNh.Neighbor.Type == PHY {
 						l2n.Metadata["phy_dmac"] = phyNh.Neighbor.Neigh0.HardwareAddr.String()
 						l2n.Resolved = true
 					} else {
-						log.Printf("netlink: Error: Neighbor type not PHY\n")
+						logger.Errorw("netlink: Error: Neighbor type not PHY")
 					}
 				}
 			}
@@ -173,22 +160,21 @@ func (l2n *L2NexthopStruct) deepEqual(l2nOld *L2NexthopStruct, nc bool) bool {
 
 // dumpL2NexthDB dump the l2 nexthop entries
 func dumpL2NexthDB() string {
-	var s string
-	log.Printf("netlink: Dump L2 Nexthop table:\n")
-	s = "L2 Nexthop table:\n"
+	var s strings.Builder
+	logger.Infow("netlink: Dump L2 Nexthop table")
+	s.WriteString("L2 Nexthop table:\n")
 	var ip string
-	for _, n := range latestL2Nexthop {
+	for _, n := range watcher.latestL2Nexthop {
 		if n.Dst == nil {
 			ip = strNone
 		} else {
 			ip = n.Dst.String()
 		}
 		str := fmt.Sprintf("L2Nexthop(id=%d dev=%s vlan=%d dst=%s type=%d #fDB entries=%d Resolved=%t) ", n.ID, n.Dev, n.VlanID, ip, n.Type, len(n.FdbRefs), n.Resolved)
-		log.Println(str)
-		s += str
-		s += "\n"
+		logger.Debug(str)
+		s.WriteString(str)
+		s.WriteString("\n")
 	}
-	log.Printf("\n\n\n")
-	s += "\n\n"
-	return s
+	s.WriteString("\n\n")
+	return s.String()
 }
@@ -1,17 +1,18 @@
 package netlink
 
 import (
-	"encoding/json"
 	"fmt"
-	"log"
-	"net"
 	"path"
+	"reflect"
 	"regexp"
 	"strconv"
 	"strings"
 
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/logging"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
 	vn "github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
 )
 
 // NeighKey strcture of neighbor
@@ -21,16 +22,6 @@ type NeighKey struct {
 	Dev     int
 }
 
-// NeighIPStruct nighbor ip structure
-type NeighIPStruct struct {
-	Dst         string
-	Dev         string
-	Lladdr      string
-	ExternLearn string
-	State       []string
-	Protocol    string
-}
-
 // NhRouteInfo neighbor route info
 type NhRouteInfo struct {
 	ID       int
@@ -58,12 +49,6 @@ type NeighList struct {
 	NS []NeighStruct
 }
 
-// latestNeighbors Variable
-var latestNeighbors = make(map[NeighKey]NeighStruct)
-
-// nhIDCache Variable
-var nhIDCache = make(map[NexthopKey]int)
-
 // rtNNeighbor
 const (
 	rtNNeighbor = 1111
@@ -71,7 +56,11 @@ const (
 
 // preFilterNeighbor pre filter the neighbors
 func (neigh NeighStruct) preFilterNeighbor() bool {
-	if neigh.Neigh0.State != vn.NUD_NONE && neigh.Neigh0.State != vn.NUD_INCOMPLETE && neigh.Neigh0.State != vn.NUD_FAILED && nameIndex[neigh.Neigh0.LinkIndex] != "lo" {
+	// IPv6 link-local neighbors cannot be resolved outside of their link, the IPv4 link-local ones are kept
+	if neigh.Neigh0.IP.To4() == nil && neigh.Neigh0.IP.IsLinkLocalUnicast() {
+		return false
+	}
+	if neigh.Neigh0.State != vn.NUD_NONE && neigh.Neigh0.State != vn.NUD_INCOMPLETE && neigh.Neigh0.State != vn.NUD_FAILED && watcher.LinkName(neigh.Neigh0.LinkIndex) != "lo" {
 		return true
 	}
 
@@ -79,31 +68,21 @@ func (neigh NeighStruct) preFilterNeighbor() bool {
 }
 
 // parseNeigh parses the neigh
-func parseNeigh(nm []NeighIPStruct, v string) NeighList {
+func parseNeigh(nm []utils.Neigh, v string) NeighList {
 	var nl NeighList
 	for _, nd := range nm {
 		var ns NeighStruct
 		ns.Neigh0.Type = OTHER
 		ns.Type = OTHER
 		ns.VrfName = v
-		if nd.Dev != "" {
-			vrf, _ := vn.LinkByName(nd.Dev)
-			ns.Neigh0.LinkIndex = vrf.Attrs().Index
-		}
-		if nd.Dst != "" {
-			ipnet := &net.IPNet{
-				IP: net.ParseIP(nd.Dst),
-			}
-			ns.Neigh0.IP = ipnet.IP
-		}
-		if len(nd.State) != 0 {
-			ns.Neigh0.State = getState(nd.State[0])
-		}
-		if nd.Lladdr != "" {
-			ns.Neigh0.HardwareAddr, _ = net.ParseMAC(nd.Lladdr)
-		}
-		if nd.Protocol != "" {
-			ns.Protocol = nd.Protocol
+		ns.Neigh0.LinkIndex = nd.LinkIndex
+		ns.Dev = linkName(nd.LinkIndex)
+		ns.Neigh0.IP = nd.IP
+		ns.Neigh0.Family = nd.Family
+		ns.Neigh0.State = nd.State
+		ns.Neigh0.HardwareAddr = nd.HardwareAddr
+		if nd.Protocol != unix.RTPROT_UNSPEC {
+			ns.Protocol = getProtoName(nd.Protocol)
 		}
 		ns.Key = NeighKey{VrfName: v, Dst: ns.Neigh0.IP.String(), Dev: ns.Neigh0.LinkIndex}
 		if ns.preFilterNeighbor() {
@@ -114,7 +93,7 @@ func parseNeigh(nm []NeighIPStruct, v string) NeighList {
 }
 
 // cmdProcessNb process the neighbor command
-func cmdProcessNb(nbs []NeighIPStruct, v string) NeighList {
+func cmdProcessNb(nbs []utils.Neigh, v string) NeighList {
 	neigh := parseNeigh(nbs, v)
 	return neigh
 }
@@ -123,70 +102,45 @@ func cmdProcessNb(nbs []NeighIPStruct, v string) NeighList {
 func addNeigh(dump NeighList) {
 	for _, n := range dump.NS {
 		n = n.neighborAnnotate()
-		if len(latestNeighbors) == 0 || !CheckNdup(n.Key) {
-			latestNeighbors[n.Key] = n
+		if len(watcher.latestNeighbors) == 0 || !CheckNdup(n.Key) {
+			watcher.latestNeighbors[n.Key] = n
 		}
 	}
 }
 
-// readNeighbors reads the nighbors
+// readNeighbors reads the nighbors, the neighbors last read are kept if they cannot be read
 func readNeighbors(v *infradb.Vrf) {
-	var n NeighList
-	var err error
-	var nb string
-	var nbs []NeighIPStruct
-	if v.Spec.Vni == nil {
-		/* No support for "ip neighbor show" command in netlink library Raised ticket https://github.com/vishvananda/netlink/issues/913 ,
-		   so using ip command as WA */
-		nb, err = nlink.ReadNeigh(ctx, "")
-	} else {
-		nb, err = nlink.ReadNeigh(ctx, path.Base(v.Name))
-	}
-	if len(nb) <= 3 || err != nil {
-		return
+	var vrfName string
+	if v.Spec.Vni != nil {
+		vrfName = path.Base(v.Name)
 	}
-	var rawMessages []json.RawMessage
-	err = json.Unmarshal([]byte(nb), &rawMessages)
+	nbs, err := nlink.ReadNeigh(ctx, vrfName)
 	if err != nil {
-		log.Printf("netlink nb: JSON unmarshal error: %v %v : %v\n", err, nb, rawMessages)
-		return
-	}
-	cps := make([]string, 0, len(rawMessages))
-	for _, rawMsg := range rawMessages {
-		cps = append(cps, string(rawMsg))
-	}
-	for i := 0; i < len(cps); i++ {
-		var ni NeighIPStruct
-		err := json.Unmarshal([]byte(cps[i]), &ni)
-		if err != nil {
-			log.Println("netlink: error-", err)
-		}
-		nbs = append(nbs, ni)
+		logger.Errorw("netlink: Failed to read the neighbors of VRF", logging.Name(v.Name), "error", err)
+	} else {
+		watcher.kernel.setNeighs(v.Name, nbs)
 	}
-	n = cmdProcessNb(nbs, v.Name)
-	addNeigh(n)
+	addNeigh(cmdProcessNb(watcher.kernel.vrfNeighs(v.Name), v.Name))
 }
 
 // CheckNdup checks the duplication of neighbor
 func CheckNdup(tmpKey NeighKey) bool {
-	var dup = false
-	for k := range latestNeighbors {
-		if k == tmpKey {
-			dup = true
-			break
-		}
-	}
+	_, dup := watcher.latestNeighbors[tmpKey]
 	return dup
 }
 
 // checkNeigh checks the nighbor
 func checkNeigh(nk NeighKey) bool {
-	for k := range latestNeighbors {
-		if k == nk {
-			return true
-		}
-	}
-	return false
+	_, ok := watcher.latestNeighbors[nk]
+	return ok
+}
+
+// deepEqual checks the neighbor is the same as the old one but for its reachability state
+func (neigh *NeighStruct) deepEqual(neighOld *NeighStruct) bool {
+	n0, old0 := neigh.Neigh0, neighOld.Neigh0
+	n0.State, old0.State = 0, 0
+	return reflect.DeepEqual(n0, old0) && neigh.Protocol == neighOld.Protocol && neigh.VrfName == neighOld.VrfName &&
+		neigh.Type == neighOld.Type && neigh.Dev == neighOld.Dev && reflect.DeepEqual(neigh.Metadata, neighOld.Metadata)
 }
 
 func (neigh *NeighStruct) printNeigh() string {
@@ -199,7 +153,7 @@ func (neigh *NeighStruct) printNeigh() string {
 	} else {
 		Proto = neigh.Protocol
 	}
-	str := fmt.Sprintf("Neighbor(vrf=%s dst=%s lladdr=%s dev=%s proto=%s state=%s) ", neigh.VrfName, neigh.Neigh0.IP.String(), neigh.Neigh0.HardwareAddr.String(), nameIndex[neigh.Neigh0.LinkIndex], Proto, getStateStr(neigh.Neigh0.State))
+	str := fmt.Sprintf("Neighbor(vrf=%s dst=%s lladdr=%s dev=%s proto=%s state=%s) ", neigh.VrfName, neigh.Neigh0.IP.String(), neigh.Neigh0.HardwareAddr.String(), watcher.LinkName(neigh.Neigh0.LinkIndex), Proto, getStateStr(neigh.Neigh0.State))
 	return str
 }
 
@@ -209,14 +163,14 @@ func (neigh NeighStruct) neighborAnnotate() NeighStruct {
 	var phyFlag bool
 	phyFlag = false
 	for k := range phyPorts {
-		if nameIndex[neigh.Neigh0.LinkIndex] == k {
+		if watcher.LinkName(neigh.Neigh0.LinkIndex) == k {
 			phyFlag = true
 		}
 	}
-	if strings.HasPrefix(nameIndex[neigh.Neigh0.LinkIndex], path.Base(neigh.VrfName)) && neigh.Protocol != zebraStr {
+	if strings.HasPrefix(watcher.LinkName(neigh.Neigh0.LinkIndex), path.Base(neigh.VrfName)) && neigh.Protocol != zebraStr {
 		pattern := fmt.Sprintf(`%s-\d+$`, path.Base(neigh.VrfName))
 		mustcompile := regexp.MustCompile(pattern)
-		s := mustcompile.FindStringSubmatch(nameIndex[neigh.Neigh0.LinkIndex])
+		s := mustcompile.FindStringSubmatch(watcher.LinkName(neigh.Neigh0.LinkIndex))
 		var lb *infradb.LogicalBridge
 		var bp *infradb.BridgePort
 		vID := strings.Split(s[0], "-")[1]
@@ -245,7 +199,7 @@ func (neigh NeighStruct) neighborAnnotate() NeighStruct {
 		} else {
 			neigh.Type = IGNORE
 		}
-	} else if strings.HasPrefix(nameIndex[neigh.Neigh0.LinkIndex], path.Base(neigh.VrfName)) && neigh.Protocol == zebraStr {
+	} else if strings.HasPrefix(watcher.LinkName(neigh.Neigh0.LinkIndex), path.Base(neigh.VrfName)) && neigh.Protocol == zebraStr {
 		pattern := fmt.Sprintf(`%s-\d+$`, path.Base(neigh.VrfName))
 		mustcompile := regexp.MustCompile(pattern)
 		s := mustcompile.FindStringSubmatch(neigh.Dev)
@@ -267,8 +221,15 @@ func (neigh NeighStruct) neighborAnnotate() NeighStruct {
 			if err != nil {
 				panic(err)
 			}
-			fdbEntry := latestFDB[FdbKey{vid, neigh.Neigh0.HardwareAddr.String()}]
-			neigh.Metadata["l2_nh"] = fdbEntry.Nexthop
+			// The fdb is not read again when only the routes of the vrf have changed
+			fdbKey := FdbKey{vid, neigh.Neigh0.HardwareAddr.String()}
+			fdbEntry, ok := watcher.latestFDB[fdbKey]
+			if !ok {
+				fdbEntry = watcher.fDB[fdbKey]
+			}
+			if fdbEntry != nil {
+				neigh.Metadata["l2_nh"] = fdbEntry.Nexthop
+			}
 			neigh.Type = VXLAN // confirm this later
 		}
 	} else if path.Base(neigh.VrfName) == "GRD" && phyFlag && neigh.Protocol != zebraStr {
@@ -277,7 +238,7 @@ func (neigh NeighStruct) neighborAnnotate() NeighStruct {
 		if ok {
 			if r.Nexthops[0].nexthop.LinkIndex == neigh.Neigh0.LinkIndex {
 				neigh.Type = PHY
-				neigh.Metadata["vport_id"] = phyPorts[nameIndex[neigh.Neigh0.LinkIndex]]
+				neigh.Metadata["vport_id"] = phyPorts[watcher.LinkName(neigh.Neigh0.LinkIndex)]
 			} else {
 				neigh.Type = IGNORE
 			}
@@ -290,21 +251,21 @@ func (neigh NeighStruct) neighborAnnotate() NeighStruct {
 
 // dumpNeighDB dump the neighbor entries
 func dumpNeighDB() string {
-	var s string
-	log.Printf("netlink: Dump Neighbor table:\n")
-	s = "Neighbor table:\n"
-	for _, n := range latestNeighbors {
+	var s strings.Builder
+	logger.Infow("netlink: Dump Neighbor table")
+	s.WriteString("Neighbor table:\n")
+	for _, n := range watcher.latestNeighbors {
 		var Proto string
 		if n.Protocol == "" {
 			Proto = strNone
 		} else {
 			Proto = n.Protocol
 		}
-		str := fmt.Sprintf("Neighbor(vrf=%s dst=%s lladdr=%s dev=%s proto=%s state=%s Type : %d) ", n.VrfName, n.Neigh0.IP.String(), n.Neigh0.HardwareAddr.String(), nameIndex[n.Neigh0.LinkIndex], Proto, getStateStr(n.Neigh0.State), n.Type)
-		log.Println(str)
-		s += str
-		s += "\n"
+		str := fmt.Sprintf("Neighbor(vrf=%s dst=%s lladdr=%s dev=%s proto=%s state=%s Type : %d) ", n.VrfName, n.Neigh0.IP.String(), n.Neigh0.HardwareAddr.String(), watcher.LinkName(n.Neigh0.LinkIndex), Proto, getStateStr(n.Neigh0.State), n.Type)
+		logger.Debug(str)
+		s.WriteString(str)
+		s.WriteString("\n")
 	}
-	s += "\n\n"
-	return s
+	s.WriteString("\n\n")
+	return s.String()
 }
@@ -0,0 +1,42 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package netlink handles the netlink related functionality
+package netlink
+
+import (
+	"net"
+	"testing"
+
+	vn "github.com/vishvananda/netlink"
+)
+
+func TestPreFilterNeighbor(t *testing.T) {
+	watcher.setLinkName(20, "br-blue")
+	watcher.setLinkName(21, "lo")
+	tests := map[string]struct {
+		ip        string
+		state     int
+		linkIndex int
+		expected  bool
+	}{
+		"ipv4":                 {ip: "10.0.0.2", state: vn.NUD_REACHABLE, linkIndex: 20, expected: true},
+		"ipv6":                 {ip: "2001:db8::2", state: vn.NUD_REACHABLE, linkIndex: 20, expected: true},
+		"ipv6 link-local":      {ip: "fe80::2", state: vn.NUD_REACHABLE, linkIndex: 20, expected: false},
+		"ipv4 link-local":      {ip: "169.254.0.2", state: vn.NUD_REACHABLE, linkIndex: 20, expected: true},
+		"incomplete":           {ip: "10.0.0.2", state: vn.NUD_INCOMPLETE, linkIndex: 20, expected: false},
+		"failed":               {ip: "10.0.0.2", state: vn.NUD_FAILED, linkIndex: 20, expected: false},
+		"loopback":             {ip: "10.0.0.2", state: vn.NUD_REACHABLE, linkIndex: 21, expected: false},
+		"ipv4 link-local none": {ip: "169.254.0.2", state: vn.NUD_NONE, linkIndex: 20, expected: false},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			neigh := NeighStruct{Neigh0: vn.Neigh{IP: net.ParseIP(tt.ip), State: tt.state, LinkIndex: tt.linkIndex}}
+			if kept := neigh.preFilterNeighbor(); kept != tt.expected {
+				t.Errorf("Expected neighbor %v in state %v to be kept %v, received %v", tt.ip, tt.state, tt.expected, kept)
+			}
+		})
+	}
+}
@@ -7,7 +7,6 @@ package netlink
 
 import (
 	"context"
-	"log"
 
 	"time"
 
@@ -15,24 +14,17 @@ import (
 
 	"github.com/opiproject/opi-evpn-bridge/pkg/config"
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/metrics"
+	"github.com/opiproject/opi-evpn-bridge/pkg/modules"
 	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
 )
 
-// deleteLatestDB deletes the latest db snap
-func deleteLatestDB() {
-	latestRoutes = make(map[RouteKey]*RouteStruct)
-	latestNeighbors = make(map[NeighKey]NeighStruct)
-	latestNexthop = make(map[NexthopKey]*NexthopStruct)
-	latestFDB = make(map[FdbKey]*FdbEntryStruct)
-	latestL2Nexthop = make(map[L2NexthopKey]*L2NexthopStruct)
-}
-
-// notifyDBChanges notify the database changes
-func notifyDBChanges() {
-	notifyDBCompChanges[RouteKey, *RouteStruct](latestRoutes, routes, ROUTE, routeOperations)
-	notifyDBCompChanges[NexthopKey, *NexthopStruct](latestNexthop, nexthops, NEXTHOP, nexthopOperations)
-	notifyDBCompChanges[FdbKey, *FdbEntryStruct](latestFDB, fDB, FDB, fdbOperations)
-	notifyDBCompChanges[L2NexthopKey, *L2NexthopStruct](latestL2Nexthop, l2Nexthops, L2NEXTHOP, l2NexthopOperations)
+// notifyDBChanges notify the changes of the published database from the old one
+func (w *Watcher) notifyDBChanges(old Tables) {
+	notifyDBCompChanges[RouteKey, *RouteStruct](w.routes, old.Routes, ROUTE, routeOperations)
+	notifyDBCompChanges[NexthopKey, *NexthopStruct](w.nexthops, old.Nexthops, NEXTHOP, nexthopOperations)
+	notifyDBCompChanges[FdbKey, *FdbEntryStruct](w.fDB, old.FDB, FDB, fdbOperations)
+	notifyDBCompChanges[L2NexthopKey, *L2NexthopStruct](w.l2Nexthops, old.L2Nexthops, L2NEXTHOP, l2NexthopOperations)
 }
 
 // Filterable method for each type
@@ -41,11 +33,11 @@ type Filterable interface {
 }
 
 // applyInstallFilters install the filters
-func applyInstallFilters() {
-	filterMap(latestRoutes, func(r *RouteStruct) bool { return r.installFilterRoute() })
-	filterMap(latestNexthop, func(n *NexthopStruct) bool { return n.installFilterNH() })
-	filterMap(latestFDB, func(f *FdbEntryStruct) bool { return f.installFilterFDB() })
-	filterMap(latestL2Nexthop, func(l *L2NexthopStruct) bool { return l.installFilterL2N() })
+func (w *Watcher) applyInstallFilters() {
+	filterMap(w.latestRoutes, func(r *RouteStruct) bool { return r.installFilterRoute() })
+	filterMap(w.latestNexthop, func(n *NexthopStruct) bool { return n.installFilterNH() })
+	filterMap(w.latestFDB, func(f *FdbEntryStruct) bool { return f.installFilterFDB() })
+	filterMap(w.latestL2Nexthop, func(l *L2NexthopStruct) bool { return l.installFilterL2N() })
 }
 
 // filterMap install the filters
@@ -90,50 +82,84 @@ func annotateMap[K comparable, V Annotatable](m map[K]V) {
 	}
 }
 
-// annotateDBEntries  annonates the latest db updates
-func annotateDBEntries() {
-	annotateMap(latestNexthop)
-	annotateMap(latestRoutes)
-	annotateMap(latestFDB)
-	annotateMap(latestL2Nexthop)
+// annotate annotates the entries of the latest DB
+func (w *Watcher) annotate() {
+	annotateMap(w.latestNexthop)
+	annotateMap(w.latestRoutes)
+	annotateMap(w.latestFDB)
+	annotateMap(w.latestL2Nexthop)
 }
 
-// readLatestNetlinkState reads the latest netlink state
-func readLatestNetlinkState() {
+// readLatestNetlinkState reads the state of the kernel again and derives the latest DB from it
+func (w *Watcher) readLatestNetlinkState() {
 	grdVrf, err := infradb.GetVrf("//network.opiproject.org/vrfs/GRD")
 	if err == nil {
+		if err := readLinks(); err != nil {
+			logger.Errorw("netlink: Failed to read the links", "error", err)
+		}
+		// The fdb is read first, the neighbors learnt by zebra refer to it
+		for _, m := range readFDB() {
+			m.addFdbEntry()
+		}
 		readNeighbors(grdVrf)
 		readRoutes(grdVrf)
+		tables := make(map[int]bool)
 		vrfs, _ := infradb.GetAllVrfs()
 		for _, v := range vrfs {
 			if v.Name != grdVrf.Name {
 				readNeighbors(v) // viswanantha library
 				readRoutes(v)    // Viswantha library
 			}
+			if v.Metadata != nil {
+				for _, table := range v.Metadata.RoutingTable {
+					if table != nil {
+						tables[int(*table)] = true
+					}
+				}
+			}
 		}
-		m := readFDB()
-		for i := 0; i < len(m); i++ {
-			m[i].addFdbEntry()
-		}
+		w.kernel.keepTables(tables)
 	}
-	dumpDBs()
 }
 
-// resyncWithKernel fun resyncs with kernal db
-func resyncWithKernel() {
+// resync rebuilds the DB from the state of the kernel read again
+func (w *Watcher) resync() {
+	w.resyncMtx.Lock()
+	defer w.resyncMtx.Unlock()
+	start := time.Now()
+	defer func() { metrics.NetlinkPollDuration.Observe(metrics.Since(start)) }()
+
 	// Build a new DB snapshot from netlink and other sources
-	readLatestNetlinkState()
+	w.readLatestNetlinkState()
 	// Annotate the latest DB entries
-	annotateDBEntries()
+	w.annotate()
 	// Filter the latest DB to retain only entries to be installed
-	applyInstallFilters()
-	// Compute changes between current and latest DB versions and inform subscribers about the changes
-	notifyDBChanges()
-	routes = latestRoutes
-	nexthops = latestNexthop
-	fDB = latestFDB
-	l2Nexthops = latestL2Nexthop
-	deleteLatestDB()
+	w.applyInstallFilters()
+	// The tables are dumped on the full resyncs only, the dump is as large as the tables
+	dumpDBs()
+	w.commitLatestDB()
+}
+
+// commitLatestDB publishes the latest DB and notifies its changes
+func (w *Watcher) commitLatestDB() {
+	old := Tables{Routes: w.routes, Nexthops: w.nexthops, FDB: w.fDB, L2Nexthops: w.l2Nexthops}
+	w.publishDB()
+	// Compute changes between the old and the published DB versions and inform subscribers about the changes,
+	// the tables read by a subscriber that falls behind already include the changes of the events it receives
+	w.notifyDBChanges(old)
+}
+
+// publishDB publishes the latest DB, its changes are notified afterwards
+func (w *Watcher) publishDB() {
+	// Release the ids of the deleted nexthops
+	w.releaseNexthopIDs()
+	// Publish the latest DB to the readers
+	w.publishLatestDB()
+	metrics.NetlinkTableSize.WithLabelValues("route").Set(float64(len(w.routes)))
+	metrics.NetlinkTableSize.WithLabelValues("nexthop").Set(float64(len(w.nexthops)))
+	metrics.NetlinkTableSize.WithLabelValues("fdb").Set(float64(len(w.fDB)))
+	metrics.NetlinkTableSize.WithLabelValues("l2nexthop").Set(float64(len(w.l2Nexthops)))
+	w.deleteLatestDB()
 }
 
 // notifyUpdates notifies the db updates
@@ -145,74 +171,143 @@ func notifyUpdates[K comparable, V any](items map[K]V, deletionType string) {
 
 // Usage
 
-// monitorNetlink moniters the netlink
-func monitorNetlink() {
-	for !stopMonitoring.Load() {
-		resyncWithKernel()
-		time.Sleep(time.Duration(pollInterval) * time.Second)
+// monitorNetlink moniters the netlink until stop is closed, then closes done,
+// it follows the kernel changes unless the resync interval is 0
+func monitorNetlink(stop <-chan struct{}, done chan<- struct{}, resyncInterval time.Duration) {
+	defer close(done)
+	if resyncInterval > 0 {
+		followNetlink(stop, resyncInterval)
+		logger.Infow("netlink: Stopped following the kernel changes")
+		return
 	}
-	log.Printf("netlink: Stopped periodic polling. Waiting for Infra DB cleanup to finish")
+	pollNetlink(stop)
+}
+
+// pollNetlink resyncs with the kernel every poll interval until stop is closed
+func pollNetlink(stop <-chan struct{}) {
+	for polling := true; polling; {
+		watcher.resync()
+		select {
+		case <-stop:
+			polling = false
+		case <-time.After(time.Duration(pollInterval.Load()) * time.Second):
+		}
+	}
+	logger.Infow("netlink: Stopped periodic polling")
+}
+
+// notifyResidualDeletes notifies the deletion of the objects still left in the DB
+func notifyResidualDeletes() {
+	logger.Infow("netlink: Waiting for Infra DB cleanup to finish")
 	time.Sleep(2 * time.Second)
-	log.Printf("netlink: One final netlink poll to identify what's still left.")
+	logger.Infow("netlink: One final netlink poll to identify what's still left")
 	// Inform subscribers to delete configuration for any still remaining Netlink DB objects.
-	log.Printf("netlink: Delete any residual objects in DB")
-	notifyUpdates(routes, RouteDeleted)
-	notifyUpdates(nexthops, NexthopDeleted)
-	notifyUpdates(fDB, FdbEntryDeleted)
-	log.Printf("netlink: DB cleanup completed.")
+	logger.Infow("netlink: Delete any residual objects in DB")
+	notifyUpdates(watcher.routes, RouteDeleted)
+	notifyUpdates(watcher.nexthops, NexthopDeleted)
+	notifyUpdates(watcher.fDB, FdbEntryDeleted)
+	logger.Infow("netlink: DB cleanup completed")
 }
 
 // getlink get the link
 func getlink() {
+	if err := readLinks(); err != nil {
+		logger.Fatal("netlink:", err)
+	}
+}
+
+// readLinks reads the links of the kernel again
+func readLinks() error {
 	links, err := vn.LinkList()
 	if err != nil {
-		log.Fatal("netlink:", err)
+		return err
 	}
-	for i := 0; i < len(links); i++ {
-		linkTable = append(linkTable, links[i])
-		nameIndex[links[i].Attrs().Index] = links[i].Attrs().Name
-		switch links[i].Type() {
-		case "vrf":
-			vrfList = append(vrfList, links[i])
-		case "device":
-			deviceList = append(deviceList, links[i])
-		case "vlan":
-			vlanList = append(vlanList, links[i])
-		case "bridge":
-			bridgeList = append(bridgeList, links[i])
-		case "vxlan":
-			vxlanList = append(vxlanList, links[i])
-		default:
-		}
-		linkList = append(linkList, links[i])
+	names := make(map[int]string, len(links))
+	for _, link := range links {
+		names[link.Attrs().Index] = link.Attrs().Name
 	}
+	watcher.setLinkNames(names)
+	watcher.kernel.setLinks(links)
+	return nil
+}
+
+func init() {
+	// The watcher derives its tables from the state programmed by the other modules
+	modules.Register(modules.Module{
+		Name:         "netlink",
+		BuildEnvs:    []string{"ci"},
+		Priority:     10,
+		Initialize:   Initialize,
+		DeInitialize: DeInitialize,
+		Stop:         Stop,
+	})
+	config.OnReload(func(cfg *config.Config) {
+		if pollInterval.Swap(int64(cfg.Netlink.PollInterval)) != int64(cfg.Netlink.PollInterval) {
+			logger.Infow("netlink: Poll interval changed", "pollInterval", cfg.Netlink.PollInterval)
+		}
+	})
 }
 
 // Initialize function intializes config
 func Initialize() {
-	pollInterval = config.GlobalConfig.Netlink.PollInterval
-	log.Printf("netlink: poll interval: %v", pollInterval)
+	pollInterval.Store(int64(config.Current().Netlink.PollInterval))
+	logger.Infow("netlink: Intervals", "pollInterval", pollInterval.Load(), "resyncInterval", config.GlobalConfig.Netlink.ResyncInterval)
 	nlEnabled := config.GlobalConfig.Netlink.Enabled
 
 	grdDefaultRoute = config.GlobalConfig.Netlink.GrdDefaultRoute
 	enableEcmp = config.GlobalConfig.Netlink.EnableEcmp
 
 	if !nlEnabled {
-		log.Printf("netlink: netlink_monitor disabled")
+		logger.Infow("netlink: netlink_monitor disabled")
 		return
 	}
 	for i := 0; i < len(config.GlobalConfig.Interfaces.PhyPorts); i++ {
 		phyPorts[config.GlobalConfig.Interfaces.PhyPorts[i].Rep] = config.GlobalConfig.Interfaces.PhyPorts[i].Vsi
 	}
+	// The nexthops keep their ids across restarts
+	for _, pool := range []*utils.IDPool{&watcher.nhIDPool, &watcher.l2NhIDPool} {
+		if err := pool.Persist(infradb.IDPoolStore{}); err != nil {
+			logger.Errorw("netlink: Failed to start the watcher", "error", err)
+		}
+	}
 	getlink()
 	ctx = context.Background()
 	nlink = utils.NewNetlinkWrapperWithArgs(config.GlobalConfig.Tracer)
-	stopMonitoring.Store(false)
-	go monitorNetlink() // monitor Thread started
+	// The built-in consumer records the derived tables before any other subscriber
+	DefaultRecorder.Start(EventBus)
+	stopMonitoring = make(chan struct{})
+	monitorDone = make(chan struct{})
+	resyncInterval := time.Duration(config.GlobalConfig.Netlink.ResyncInterval) * time.Second
+	go monitorNetlink(stopMonitoring, monitorDone, resyncInterval) // monitor Thread started
 }
 
-// DeInitialize function handles stops functionality
+// DeInitialize stops the polling and notifies the deletion of the residual objects
 func DeInitialize() {
-	// stopMonitoring = true
-	stopMonitoring.Store(true)
+	if !stopPolling() {
+		return
+	}
+	notifyResidualDeletes()
+	EventBus.Unsubscribe()
+	DefaultRecorder.Wait()
+}
+
+// Stop stops the polling without notifying any deletion, the subscribers keep their state
+func Stop() {
+	if !stopPolling() {
+		return
+	}
+	EventBus.Unsubscribe()
+	DefaultRecorder.Wait()
+}
+
+// stopPolling stops the watcher and waits for the last poll to finish,
+// it returns false when the watcher has not been started
+func stopPolling() bool {
+	if stopMonitoring == nil {
+		return false
+	}
+	close(stopMonitoring)
+	<-monitorDone
+	stopMonitoring = nil
+	return true
 }
@@ -0,0 +1,396 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package netlink handles the netlink related functionality
+package netlink
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"reflect"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	vn "github.com/vishvananda/netlink"
+
+	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+	pc "github.com/opiproject/opi-api/network/opinetcommon/v1alpha1/gen/go"
+
+	// The LGM registers itself as a module
+	_ "github.com/opiproject/opi-evpn-bridge/pkg/LinuxGeneralModule"
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/taskmanager"
+	"github.com/opiproject/opi-evpn-bridge/pkg/modules"
+	eb "github.com/opiproject/opi-evpn-bridge/pkg/netlink/eventbus"
+)
+
+func TestRecorder(t *testing.T) {
+	route := &RouteStruct{Key: RouteKey{Table: 1001, Dst: "10.10.10.0/24"}}
+	nexthop := &NexthopStruct{Key: NexthopKey{VrfName: "blue", Dev: 3}}
+	fdb := &FdbEntryStruct{Key: FdbKey{VlanID: 10, Mac: "aa:bb:cc:dd:ee:ff"}}
+	l2nexthop := &L2NexthopStruct{Key: L2NexthopKey{Dev: "br-tenant", VlanID: 10}}
+
+	tests := map[string]struct {
+		events     []string
+		data       []interface{}
+		routes     int
+		nexthops   int
+		fdb        int
+		l2nexthops int
+	}{
+		"added": {
+			events: []string{RouteAdded, NexthopAdded, FdbEntryAdded, L2NexthopAdded},
+			data:   []interface{}{route, nexthop, fdb, l2nexthop},
+			routes: 1, nexthops: 1, fdb: 1, l2nexthops: 1,
+		},
+		"updated": {
+			events: []string{RouteAdded, RouteUpdated, NexthopAdded, NexthopUpdated},
+			data:   []interface{}{route, route, nexthop, nexthop},
+			routes: 1, nexthops: 1,
+		},
+		"deleted": {
+			events: []string{RouteAdded, FdbEntryAdded, RouteDeleted, FdbEntryDeleted},
+			data:   []interface{}{route, fdb, route, fdb},
+		},
+		"unexpected data": {
+			events: []string{RouteAdded},
+			data:   []interface{}{"10.10.10.0/24"},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			bus := eb.NewEventBus()
+			r := NewRecorder()
+			r.Start(bus)
+			for i, event := range tt.events {
+				bus.Publish(event, tt.data[i])
+			}
+			bus.Unsubscribe()
+			r.Wait()
+
+			tables := r.Tables()
+			if len(tables.Routes) != tt.routes {
+				t.Errorf("Expected %v routes, received %v", tt.routes, tables.Routes)
+			}
+			if len(tables.Nexthops) != tt.nexthops {
+				t.Errorf("Expected %v nexthops, received %v", tt.nexthops, tables.Nexthops)
+			}
+			if len(tables.FDB) != tt.fdb {
+				t.Errorf("Expected %v fdb entries, received %v", tt.fdb, tables.FDB)
+			}
+			if len(tables.L2Nexthops) != tt.l2nexthops {
+				t.Errorf("Expected %v l2 nexthops, received %v", tt.l2nexthops, tables.L2Nexthops)
+			}
+			if count := r.Count(tt.events[0]); count == 0 {
+				t.Errorf("Expected event %v to be counted", tt.events[0])
+			}
+		})
+	}
+}
+
+func TestRecorderResync(t *testing.T) {
+	route := &RouteStruct{Key: RouteKey{Table: 1001, Dst: "10.10.10.0/24"}}
+	snapshot := Tables{
+		Routes:     map[RouteKey]*RouteStruct{route.Key: route},
+		Nexthops:   map[NexthopKey]*NexthopStruct{},
+		FDB:        map[FdbKey]*FdbEntryStruct{},
+		L2Nexthops: map[L2NexthopKey]*L2NexthopStruct{},
+	}
+	bus := eb.NewEventBus()
+	r := NewRecorderWithArgs(func() Tables { return snapshot })
+	r.Start(bus)
+
+	// The recorder falls behind while its tables are locked and the events overflow its queues
+	r.mtx.Lock()
+	for i := 0; i < eb.DefaultQueueSize+10; i++ {
+		bus.Publish(NexthopAdded, &NexthopStruct{Key: NexthopKey{VrfName: "blue", Dev: i}})
+	}
+	r.mtx.Unlock()
+	deadline := time.Now().Add(10 * time.Second)
+	for r.Resyncs() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the recorder to record the tables again")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	// The events published after the snapshot are recorded on top of it
+	added := &NexthopStruct{Key: NexthopKey{VrfName: "green"}}
+	bus.Publish(NexthopAdded, added)
+	bus.Unsubscribe()
+	r.Wait()
+
+	// The queued events are included in the snapshot and are not recorded again
+	tables := r.Tables()
+	if !reflect.DeepEqual(tables.Routes, snapshot.Routes) {
+		t.Errorf("Expected the routes %v of the snapshot, received %v", snapshot.Routes, tables.Routes)
+	}
+	if !reflect.DeepEqual(tables.Nexthops, map[NexthopKey]*NexthopStruct{added.Key: added}) {
+		t.Errorf("Expected only nexthop %v published after the snapshot, received %v nexthops", added.Key, len(tables.Nexthops))
+	}
+}
+
+// vrfRealizer stands in for the LGM and sets the routing table of the VRFs it realizes
+type vrfRealizer struct {
+	tables map[string]uint32
+}
+
+func (h *vrfRealizer) HandleEvent(_ string, objectData *eventbus.ObjectData) {
+	table := h.tables[objectData.Name]
+	comp := common.Component{Name: "lgm", CompStatus: common.ComponentStatusSuccess}
+	meta := &infradb.VrfMetadata{RoutingTable: []*uint32{&table}}
+	_ = infradb.UpdateVrfStatus(objectData.Name, objectData.ResourceVersion, objectData.NotificationID, meta, comp)
+}
+
+// netnsEnv is set when the test binary runs in its own network namespace
+const netnsEnv = "OPI_NETLINK_TEST_NETNS"
+
+// runInNetns runs the test or the benchmark again in a child process in a new network namespace,
+// so that all the threads of the modules share it. It reports whether the
+// current process is the one running in the namespace.
+func runInNetns(t testing.TB) bool {
+	if os.Getenv(netnsEnv) != "" {
+		return true
+	}
+	if os.Geteuid() != 0 {
+		t.Skip("network namespaces require root")
+	}
+	args := []string{"-test.run=^" + t.Name() + "$", "-test.v"}
+	_, bench := t.(*testing.B)
+	if bench {
+		args = append(args[1:], "-test.run=^$", "-test.bench=^"+t.Name()+"$", "-test.benchtime="+flag.Lookup("test.benchtime").Value.String())
+	}
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Env = append(os.Environ(), netnsEnv+"=1")
+	cmd.SysProcAttr = &syscall.SysProcAttr{Cloneflags: syscall.CLONE_NEWNET}
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if err := cmd.Start(); err != nil {
+		t.Skipf("cannot create a network namespace: %v", err)
+	}
+	if err := cmd.Wait(); err != nil {
+		t.Fatalf("The test has failed in its network namespace: %v\n%s", err, out.String())
+	}
+	if strings.Contains(out.String(), "--- SKIP") {
+		// The reason of the skip is reported on the line of the test file
+		reason := ""
+		for _, line := range strings.Split(out.String(), "\n") {
+			if strings.Contains(line, "_test.go:") {
+				reason = strings.TrimSpace(line)
+			}
+		}
+		t.Skipf("The test has been skipped in its network namespace: %s", reason)
+	}
+	if bench {
+		// The results are the ones of the child, the benchmark is not run again by the parent
+		for _, line := range strings.Split(out.String(), "\n") {
+			if strings.HasPrefix(line, t.Name()) && strings.Contains(line, "/op") {
+				fmt.Println(line)
+			}
+		}
+		t.SkipNow()
+	}
+	return false
+}
+
+// restoreGlobals restores the buses, the task manager, the recorder and the config once the test is done
+func restoreGlobals(t *testing.T) {
+	ebus, taskMan, bus, recorder, cfg := eventbus.EBus, taskmanager.TaskMan, EventBus, DefaultRecorder, config.GlobalConfig
+	t.Cleanup(func() {
+		eventbus.EBus, taskmanager.TaskMan, EventBus, DefaultRecorder = ebus, taskMan, bus, recorder
+		_ = config.SetConfig(cfg)
+	})
+}
+
+// TestWatcherNetns starts the LGM and the watcher in a network namespace, realizes a VRF
+// and its SVI through the LGM and checks the watcher publishes the connected route of the SVI
+func TestWatcherNetns(t *testing.T) {
+	if !runInNetns(t) {
+		return
+	}
+	lo, err := vn.LinkByName("lo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := vn.LinkSetUp(lo); err != nil {
+		t.Fatal(err)
+	}
+	// The LGM realizes the VRFs on a VLAN aware br-tenant
+	vlanFiltering := true
+	for _, link := range []vn.Link{
+		&vn.Vrf{LinkAttrs: vn.LinkAttrs{Name: "probe-vrf"}, Table: 1000},
+		&vn.Bridge{LinkAttrs: vn.LinkAttrs{Name: "probe-br"}, VlanFiltering: &vlanFiltering},
+	} {
+		if err := vn.LinkAdd(link); err != nil {
+			t.Skipf("cannot create link %v: %v", link.Attrs().Name, err)
+		}
+		if err := vn.LinkDel(link); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// dumpDBs writes to the working directory
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chdir(wd) }()
+
+	restoreGlobals(t)
+	eventbus.EBus = eventbus.NewEventBus()
+	taskmanager.TaskMan = taskmanager.NewTaskManager()
+	EventBus = eb.NewEventBus()
+	DefaultRecorder = NewRecorder()
+	_ = config.SetConfig(config.Config{
+		Buildenv:    "ci",
+		Subscribers: []config.SubscriberConfig{{Name: "lgm", Priority: 1, Events: []string{"vrf", "svi", "logical-bridge"}}},
+		LinuxFrr:    config.LinuxFrrConfig{IPMtu: 1500},
+		Netlink:     config.NetlinkConfig{Enabled: true, PollInterval: 1, ResyncInterval: 1},
+	})
+	if err := infradb.NewInfraDB("", "gomap"); err != nil {
+		t.Fatal(err)
+	}
+	taskmanager.TaskMan.StartTaskManager()
+	defer taskmanager.TaskMan.Drain(0)
+	if err := modules.Initialize("ci", []string{"lgm", "netlink"}); err != nil {
+		t.Fatal(err)
+	}
+	defer modules.Stop()
+
+	const (
+		grd  = "//network.opiproject.org/vrfs/GRD"
+		blue = "//network.opiproject.org/vrfs/blue"
+		lb   = "//network.opiproject.org/bridges/lb-10"
+		svi  = "//network.opiproject.org/svis/blue-10"
+	)
+	_, loopback, _ := net.ParseCIDR("10.0.0.1/32")
+	for _, name := range []string{grd, blue} {
+		obj, err := infradb.NewVrfWithArgs(name, nil, loopback, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := infradb.CreateVrf(obj); err != nil {
+			t.Fatal(err)
+		}
+		waitRealized(t, name)
+	}
+	vrf, err := infradb.GetVrf(blue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := RouteKey{Table: int(*vrf.Metadata.RoutingTable[0]), Dst: "10.10.10.0/24"}
+	if _, ok := DefaultRecorder.Tables().Routes[key]; ok {
+		t.Fatalf("Expected no route %v before the SVI is created", key)
+	}
+
+	bridge, err := infradb.NewLogicalBridge(&pb.LogicalBridge{Name: lb, Spec: &pb.LogicalBridgeSpec{VlanId: 10}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := infradb.CreateLB(bridge); err != nil {
+		t.Fatal(err)
+	}
+	obj, err := infradb.NewSvi(&pb.Svi{Name: svi, Spec: &pb.SviSpec{
+		Vrf:           blue,
+		LogicalBridge: lb,
+		MacAddress:    []byte{0x02, 0x00, 0x00, 0x00, 0x00, 0x10},
+		GwIpPrefix: []*pc.IPPrefix{{
+			Addr: &pc.IPAddress{Af: pc.IpAf_IP_AF_INET, V4OrV6: &pc.IPAddress_V4Addr{V4Addr: 0x0a0a0a01}},
+			Len:  24,
+		}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := infradb.CreateSvi(obj); err != nil {
+		t.Fatal(err)
+	}
+
+	// The watcher polls every second
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, ok := DefaultRecorder.Tables().Routes[key]; ok {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	modules.Stop()
+
+	route, ok := DefaultRecorder.Tables().Routes[key]
+	if !ok {
+		t.Fatalf("Expected route %v to be recorded, received %v", key, DefaultRecorder.Tables().Routes)
+	}
+	if route.Vrf.Name != blue {
+		t.Errorf("Expected the route in VRF blue, received %v", route.Vrf.Name)
+	}
+	if !route.Route0.Dst.Contains(net.ParseIP("10.10.10.1")) {
+		t.Errorf("Expected the route to cover the SVI address, received %v", route.Route0.Dst)
+	}
+	if DefaultRecorder.Count(RouteAdded) == 0 || DefaultRecorder.Count(NexthopAdded) == 0 {
+		t.Errorf("Expected route and nexthop events, received %v routes and %v nexthops", DefaultRecorder.Count(RouteAdded), DefaultRecorder.Count(NexthopAdded))
+	}
+}
+
+// testRealizer realizes the VRFs created by the tests
+var testRealizer = &vrfRealizer{tables: make(map[string]uint32)}
+
+// startRealizer starts the realizer and the task manager once for all the tests
+var startRealizer sync.Once
+
+// createVrfs creates the VRFs with their routing table in a new infra db, the VRFs other than the GRD get a VNI
+func createVrfs(t testing.TB, tables map[string]uint32) {
+	if err := infradb.NewInfraDB("", "gomap"); err != nil {
+		t.Fatal(err)
+	}
+	startRealizer.Do(func() {
+		eventbus.EBus.StartSubscriber("lgm", "vrf", 1, testRealizer)
+		taskmanager.TaskMan.StartTaskManager()
+	})
+	vni := uint32(1000)
+	for name, table := range tables {
+		var vrfVni *uint32
+		if name != "GRD" {
+			vni++
+			vrfVni = new(uint32)
+			*vrfVni = vni
+		}
+		name = "//network.opiproject.org/vrfs/" + name
+		testRealizer.tables[name] = table
+		obj, err := infradb.NewVrfWithArgs(name, vrfVni, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := infradb.CreateVrf(obj); err != nil {
+			t.Fatal(err)
+		}
+		waitRealized(t, name)
+	}
+}
+
+// waitRealized waits for the VRF to get its routing table
+func waitRealized(t testing.TB, name string) {
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		vrf, err := infradb.GetVrf(name)
+		if err == nil && len(vrf.Metadata.RoutingTable) != 0 {
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	t.Fatalf("VRF %v has not been realized", name)
+}
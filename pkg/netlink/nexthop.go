@@ -3,7 +3,6 @@ package netlink
 import (
 	"encoding/json"
 	"fmt"
-	"log"
 	"net"
 	"path"
 	"reflect"
@@ -48,14 +47,11 @@ type NexthopStruct struct {
 // nexthopOperations add, update, delete
 var nexthopOperations = Operations{Add: NexthopAdded, Update: NexthopUpdated, Delete: NexthopDeleted}
 
-// nhNextID Variable
-var nhNextID = 16
-
-// Nexthops Variable
-var nexthops = make(map[NexthopKey]*NexthopStruct)
-
-// latestNexthop Variable
-var latestNexthop = make(map[NexthopKey]*NexthopStruct)
+// nexthopIDMin and nexthopIDMax bound the ids assigned to the nexthops
+const (
+	nexthopIDMin = 16
+	nexthopIDMax = 65535
+)
 
 // Event Operations
 const (
@@ -82,12 +78,8 @@ const ( // NexthopStruct TYPE & L2NEXTHOP TYPE & FDBentry
 
 // checkNhDB checks the neighbor database
 func checkNhDB(nhKey NexthopKey) bool {
-	for k := range latestNexthop {
-		if k == nhKey {
-			return true
-		}
-	}
-	return false
+	_, ok := watcher.latestNexthop[nhKey]
+	return ok
 }
 
 // deepCopyMetadata deep copies the metadata
@@ -112,12 +104,15 @@ func (nexthop *NexthopStruct) tryResolve() []*NexthopStruct {
 		if ch {
 			if nexthop.NhType == VXLAN {
 				nexthop.Metadata["remote_vtep_ip"] = nexthop.nexthop.Gw.String()
-				nh := latestNeighbors[neighborKey]
+				nh := watcher.latestNeighbors[neighborKey]
 				nexthop.Metadata["inner_dmac"] = nh.Neigh0.HardwareAddr.String()
 				VRF, _ := infradb.GetVrf("//network.opiproject.org/vrfs/GRD")
 				r, ok := lookupRoute(nexthop.nexthop.Gw, VRF)
 				if ok {
-					for _, grdNexthop := range r.Nexthops {
+					for i := range r.Nexthops {
+						// The GRD nexthops are resolved on copies, they may have been published already
+						grdNexthop := *r.Nexthops[i]
+						grdNexthop.Metadata = deepCopyMetadata(grdNexthop.Metadata)
 						arrayOfNexthops := grdNexthop.tryResolve()
 						if len(arrayOfNexthops) != 0 {
 							nexthopSt := *nexthop
@@ -137,7 +132,7 @@ func (nexthop *NexthopStruct) tryResolve() []*NexthopStruct {
 				return retNexthopSt
 			} else if nexthop.NhType >= 0 {
 				nexthop.Resolved = true
-				nh := latestNeighbors[neighborKey]
+				nh := watcher.latestNeighbors[neighborKey]
 				nexthop.Neighbor = &nh
 				return []*NexthopStruct{nexthop}
 			}
@@ -153,16 +148,14 @@ func (nexthop *NexthopStruct) tryResolve() []*NexthopStruct {
 	return []*NexthopStruct{nexthop}
 }
 
-// NHAssignID returns the nexthop id
+// nexthopIDKey returns the key of the nexthop in the id pool, it is quoted so that it can be persisted
+func nexthopIDKey(key NexthopKey) string {
+	return fmt.Sprintf("%#v", key)
+}
+
+// NHAssignID returns the nexthop id, the same id is returned for the same key
 func NHAssignID(key NexthopKey) int {
-	id := nhIDCache[key]
-	if id == 0 {
-		// Assigne a free id and insert it into the cache
-		id = nhNextID
-		nhIDCache[key] = id
-		nhNextID++
-	}
-	return id
+	return int(watcher.nhIDPool.GetID(nexthopIDKey(key)))
 }
 
 // addNexthop adds the nexthop
@@ -170,19 +163,20 @@ func NHAssignID(key NexthopKey) int {
 //nolint
 func (nexthop *NexthopStruct) addNexthop(r *RouteStruct) *RouteStruct {
 	if len(r.Nexthops) > 0 && !enableEcmp {
-		log.Printf("ECMP disabled: Ignoring additional nexthop of route")
+		logger.Infow("netlink: ECMP disabled: Ignoring additional nexthop of route")
 		return nil
 	}
 	ch := checkNhDB(nexthop.Key)
 	if ch {
-		NH0 := latestNexthop[nexthop.Key]
+		NH0 := watcher.ownNexthop(watcher.latestNexthop[nexthop.Key])
 		// Links route with existing nexthop
 		NH0.RouteRefs = append(NH0.RouteRefs, r)
 		r.Nexthops = append(r.Nexthops, NH0)
 	} else if nexthop.Resolved {
 		nexthop.RouteRefs = append(nexthop.RouteRefs, r)
 		nexthop.ID = NHAssignID(nexthop.Key)
-		latestNexthop[nexthop.Key] = nexthop
+		watcher.replaceNexthop(nexthop.Key)
+		watcher.latestNexthop[nexthop.Key] = nexthop
 		r.Nexthops = append(r.Nexthops, nexthop)
 	} else {
 		nexthops := nexthop.tryResolve()
@@ -203,9 +197,7 @@ func (nexthop *NexthopStruct) ParseNexthop(v *infradb.Vrf, rc RouteCmdInfo) {
 	nexthop.Weight = 1
 	nexthop.Vrf = v
 	if rc.Dev != "" {
-		vrf, _ := vn.LinkByName(rc.Dev)
-		nexthop.nexthop.LinkIndex = vrf.Attrs().Index
-		nameIndex[nexthop.nexthop.LinkIndex] = vrf.Attrs().Name
+		nexthop.nexthop.LinkIndex = linkIndex(rc.Dev)
 	}
 	if len(rc.Flags) != 0 {
 		nexthop.nexthop.Flags = getFlag(rc.Flags[0])
@@ -235,15 +227,15 @@ func (nexthop *NexthopStruct) ParseNexthop(v *infradb.Vrf, rc RouteCmdInfo) {
 	}
 
 	for k := range phyPorts {
-		if nameIndex[nexthop.nexthop.LinkIndex] == k {
+		if watcher.LinkName(nexthop.nexthop.LinkIndex) == k {
 			phyFlag = true
 		}
 	}
 	if (nexthop.nexthop.Gw != nil && !nexthop.nexthop.Gw.IsUnspecified()) && phyFlag && !nexthop.Local {
 		nexthop.NhType = PHY
-	} else if (nexthop.nexthop.Gw != nil && !nexthop.nexthop.Gw.IsUnspecified()) && nexthop.nexthop.LinkIndex != 0 && strings.HasPrefix(nameIndex[nexthop.nexthop.LinkIndex], path.Base(nexthop.Vrf.Name)+"-") && !nexthop.Local {
+	} else if (nexthop.nexthop.Gw != nil && !nexthop.nexthop.Gw.IsUnspecified()) && nexthop.nexthop.LinkIndex != 0 && strings.HasPrefix(watcher.LinkName(nexthop.nexthop.LinkIndex), path.Base(nexthop.Vrf.Name)+"-") && !nexthop.Local {
 		nexthop.NhType = VRFNEIGHBOR
-	} else if (nexthop.nexthop.Gw != nil && !nexthop.nexthop.Gw.IsUnspecified()) && nameIndex[nexthop.nexthop.LinkIndex] == fmt.Sprintf("br-%s", path.Base(nexthop.Vrf.Name)) && !nexthop.Local {
+	} else if (nexthop.nexthop.Gw != nil && !nexthop.nexthop.Gw.IsUnspecified()) && watcher.LinkName(nexthop.nexthop.LinkIndex) == fmt.Sprintf("br-%s", path.Base(nexthop.Vrf.Name)) && !nexthop.Local {
 		nexthop.NhType = VXLAN
 	} else {
 		nexthop.NhType = ACC
@@ -255,7 +247,7 @@ func (nexthop *NexthopStruct) ParseNexthop(v *infradb.Vrf, rc RouteCmdInfo) {
 func (nexthop *NexthopStruct) annotate() {
 	if nexthop.NhType == VRFNEIGHBOR {
 		nexthop.NhType = SVI
-		link, _ := vn.LinkByName(nameIndex[nexthop.nexthop.LinkIndex])
+		link, _ := vn.LinkByName(watcher.LinkName(nexthop.nexthop.LinkIndex))
 		if nexthop.Neighbor != nil {
 			if nexthop.Neighbor.Type == SVI {
 				nexthop.NhType = SVI
@@ -272,7 +264,7 @@ func (nexthop *NexthopStruct) annotate() {
 				nexthop.Metadata["inner_smac"] = link.Attrs().HardwareAddr.String()
 				L2N, ok := nexthop.Neighbor.Metadata["l2_nh"].(L2NexthopStruct)
 				if !ok {
-					log.Printf("netlink: Neighbor metadata l2_nh is not of L2NexthopStruct type")
+					logger.Infow("netlink: Neighbor metadata l2_nh is not of L2NexthopStruct type")
 					return
 				}
 				if L2N.Resolved {
@@ -287,11 +279,11 @@ func (nexthop *NexthopStruct) annotate() {
 				}
 			} else {
 				nexthop.Resolved = false
-				log.Printf("netlink: Failed to gather data for nexthop on physical port with nexthop is %+v\n", nexthop)
+				logger.Errorw("netlink: Failed to gather data for nexthop on physical port", "nexthopID", nexthop.ID, "key", nexthop.Key)
 			}
 		}
 	} else if nexthop.NhType == PHY {
-		link1, _ := vn.LinkByName(nameIndex[nexthop.nexthop.LinkIndex])
+		link1, _ := vn.LinkByName(watcher.LinkName(nexthop.nexthop.LinkIndex))
 		if link1 == nil {
 			return
 		}
@@ -304,7 +296,7 @@ func (nexthop *NexthopStruct) annotate() {
 			}
 		} else {
 			nexthop.Resolved = false
-			log.Printf("netlink: Failed to gather data for nexthop on physical port with nexthop is %+v\n", nexthop)
+			logger.Errorw("netlink: Failed to gather data for nexthop on physical port", "nexthopID", nexthop.ID, "key", nexthop.Key)
 		}
 	} else if nexthop.NhType == VXLAN {
 		v, _ := infradb.GetVrf(nexthop.Vrf.Name)
@@ -314,22 +306,22 @@ func (nexthop *NexthopStruct) annotate() {
 			if com.Name == "frr" {
 				err := json.Unmarshal([]byte(com.Details), &detail)
 				if err != nil {
-					log.Printf("netlink nexthop: Error: %v %v : %v", err, com.Details, detail)
+					logger.Errorw("netlink: Failed to parse the nexthop details", "details", com.Details, "detail", detail, "error", err)
 					break
 				}
 				mac, ok := detail["rmac"]
 				if !ok {
-					log.Printf("netlink: Key 'rmac' not found")
+					logger.Warnw("netlink: Key 'rmac' not found")
 					break
 				}
 				strRmac, found := mac.(string)
 				if !found || strRmac == "" {
-					log.Printf("netlink: key 'rmac' is empty")
+					logger.Infow("netlink: key 'rmac' is empty")
 					break
 				}
 				rmac, err = net.ParseMAC(strRmac)
 				if err != nil {
-					log.Printf("netlink: Error parsing MAC address: %v", err)
+					logger.Errorw("netlink: Error parsing MAC address", "error", err)
 				}
 			}
 		}
@@ -345,9 +337,9 @@ func (nexthop *NexthopStruct) annotate() {
 			r, ok := lookupRoute(nexthop.nexthop.Gw, v)
 			if ok {
 				phyNh := r.Nexthops[0]
-				link, _ := vn.LinkByName(nameIndex[phyNh.nexthop.LinkIndex])
+				link, _ := vn.LinkByName(watcher.LinkName(phyNh.nexthop.LinkIndex))
 				nexthop.Metadata["phy_smac"] = link.Attrs().HardwareAddr.String()
-				nexthop.Metadata["egress_vport"] = phyPorts[nameIndex[phyNh.nexthop.LinkIndex]]
+				nexthop.Metadata["egress_vport"] = phyPorts[watcher.LinkName(phyNh.nexthop.LinkIndex)]
 				nexthop.Metadata["phy_dmac"] = nexthop.Neighbor.Neigh0.HardwareAddr.String() // link.Attrs().HardwareAddr.String()
 			}
 		}
@@ -355,7 +347,7 @@ func (nexthop *NexthopStruct) annotate() {
 		//nexthop.NhType = ACC
 		link1, err := vn.LinkByName("rep-" + path.Base(nexthop.Vrf.Name))
 		if err != nil {
-			log.Printf("netlink: Error in getting rep information: %v\n", err)
+			logger.Errorw("netlink: Error in getting rep information", "error", err)
 		}
 		if link1 == nil {
 			return
@@ -400,9 +392,14 @@ func (nexthop *NexthopStruct) deepEqual(nhOld *NexthopStruct, nc bool) bool {
 		if len(nexthop.RouteRefs) != len(nhOld.RouteRefs) {
 			return false
 		}
-		for i := range nexthop.RouteRefs {
-			ret := nexthop.RouteRefs[i].deepEqual(nhOld.RouteRefs[i], false)
-			if !ret {
+		// The routes are referred to in the order they have been derived
+		refsOld := make(map[RouteKey]*RouteStruct, len(nhOld.RouteRefs))
+		for _, r := range nhOld.RouteRefs {
+			refsOld[r.Key] = r
+		}
+		for _, r := range nexthop.RouteRefs {
+			rOld, ok := refsOld[r.Key]
+			if !ok || (r != rOld && !r.deepEqual(rOld, false)) {
 				return false
 			}
 		}
@@ -417,16 +414,15 @@ func (nexthop *NexthopStruct) GetVrfOperStatus() infradb.VrfOperStatus {
 
 // dumpNexthDB dump the nexthop entries
 func dumpNexthDB() string {
-	var s string
-	log.Printf("netlink: Dump Nexthop table:\n")
-	s = "Nexthop table:\n"
-	for _, n := range latestNexthop {
-		str := fmt.Sprintf("Nexthop(id=%d vrf=%s dst=%s dev=%s Local=%t weight=%d flags=[%s] #routes=%d Resolved=%t neighbor=%s) ", n.ID, n.Vrf.Name, n.nexthop.Gw.String(), nameIndex[n.nexthop.LinkIndex], n.Local, n.Weight, getFlagString(n.nexthop.Flags), len(n.RouteRefs), n.Resolved, n.Neighbor.printNeigh())
-		log.Println(str)
-		s += str
-		s += "\n"
+	var s strings.Builder
+	logger.Infow("netlink: Dump Nexthop table")
+	s.WriteString("Nexthop table:\n")
+	for _, n := range watcher.latestNexthop {
+		str := fmt.Sprintf("Nexthop(id=%d vrf=%s dst=%s dev=%s Local=%t weight=%d flags=[%s] #routes=%d Resolved=%t neighbor=%s) ", n.ID, n.Vrf.Name, n.nexthop.Gw.String(), watcher.LinkName(n.nexthop.LinkIndex), n.Local, n.Weight, getFlagString(n.nexthop.Flags), len(n.RouteRefs), n.Resolved, n.Neighbor.printNeigh())
+		logger.Debug(str)
+		s.WriteString(str)
+		s.WriteString("\n")
 	}
-	log.Printf("\n\n\n")
-	s += "\n\n"
-	return s
+	s.WriteString("\n\n")
+	return s.String()
 }
@@ -0,0 +1,203 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package netlink handles the netlink related functionality
+package netlink
+
+import (
+	"fmt"
+	"sync"
+
+	eb "github.com/opiproject/opi-evpn-bridge/pkg/netlink/eventbus"
+)
+
+// Tables holds the tables derived from the kernel by the netlink watcher
+type Tables struct {
+	Routes     map[RouteKey]*RouteStruct
+	Nexthops   map[NexthopKey]*NexthopStruct
+	FDB        map[FdbKey]*FdbEntryStruct
+	L2Nexthops map[L2NexthopKey]*L2NexthopStruct
+}
+
+// recordedEvents lists the events published by the netlink watcher
+var recordedEvents = []string{
+	RouteAdded, RouteUpdated, RouteDeleted,
+	NexthopAdded, NexthopUpdated, NexthopDeleted,
+	FdbEntryAdded, FdbEntryUpdated, FdbEntryDeleted,
+	L2NexthopAdded, L2NexthopUpdated, L2NexthopDeleted,
+}
+
+// Recorder is the built-in consumer of the netlink events,
+// it records the derived tables as published on the event bus
+type Recorder struct {
+	mtx    sync.RWMutex
+	tables Tables
+	counts map[string]int
+	wg     sync.WaitGroup
+	// snapshot returns the tables to record again when events have been dropped
+	snapshot func() Tables
+	resyncs  int
+	// subs holds the subscriber of each event, their sequence numbers tag the snapshots
+	subs map[string]*eb.Subscriber
+	// snapshotSeqs holds the sequence number of the last event of each type included in the recorded snapshot
+	snapshotSeqs map[string]uint64
+}
+
+// NewRecorder creates an empty recorder
+func NewRecorder() *Recorder {
+	return &Recorder{
+		tables: Tables{
+			Routes:     make(map[RouteKey]*RouteStruct),
+			Nexthops:   make(map[NexthopKey]*NexthopStruct),
+			FDB:        make(map[FdbKey]*FdbEntryStruct),
+			L2Nexthops: make(map[L2NexthopKey]*L2NexthopStruct),
+		},
+		counts:       make(map[string]int),
+		subs:         make(map[string]*eb.Subscriber),
+		snapshotSeqs: make(map[string]uint64),
+	}
+}
+
+// NewRecorderWithArgs creates an empty recorder that records the tables returned by snapshot
+// again when it falls behind and events have been dropped
+func NewRecorderWithArgs(snapshot func() Tables) *Recorder {
+	r := NewRecorder()
+	r.snapshot = snapshot
+	return r
+}
+
+// DefaultRecorder records the tables of the netlink watcher started by Initialize
+var DefaultRecorder = NewRecorderWithArgs(func() Tables { return watcher.Tables() })
+
+// Start subscribes the recorder to all the events of the bus.
+// The recorder stops once the bus is unsubscribed.
+func (r *Recorder) Start(bus *eb.EventBus) {
+	for _, event := range recordedEvents {
+		sub := bus.Subscribe(event)
+		r.mtx.Lock()
+		r.subs[event] = sub
+		r.mtx.Unlock()
+		r.wg.Add(1)
+		go func(event string, sub *eb.Subscriber) {
+			defer r.wg.Done()
+			for {
+				select {
+				case e, ok := <-sub.Ch:
+					if !ok {
+						return
+					}
+					r.record(event, e)
+				case <-sub.Resync:
+					r.resync(event, sub.Dropped())
+				}
+			}
+		}(event, sub)
+	}
+}
+
+// Wait blocks until the recorder has stopped
+func (r *Recorder) Wait() {
+	r.wg.Wait()
+}
+
+// record applies an event to the tables, the events already included in the recorded snapshot are discarded
+func (r *Recorder) record(event string, e eb.Event) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if e.Seq <= r.snapshotSeqs[event] {
+		return
+	}
+	r.counts[event]++
+	switch d := e.Data.(type) {
+	case *RouteStruct:
+		if event == RouteDeleted {
+			delete(r.tables.Routes, d.Key)
+		} else {
+			r.tables.Routes[d.Key] = d
+		}
+	case *NexthopStruct:
+		if event == NexthopDeleted {
+			delete(r.tables.Nexthops, d.Key)
+		} else {
+			r.tables.Nexthops[d.Key] = d
+		}
+	case *FdbEntryStruct:
+		if event == FdbEntryDeleted {
+			delete(r.tables.FDB, d.Key)
+		} else {
+			r.tables.FDB[d.Key] = d
+		}
+	case *L2NexthopStruct:
+		if event == L2NexthopDeleted {
+			delete(r.tables.L2Nexthops, d.Key)
+		} else {
+			r.tables.L2Nexthops[d.Key] = d
+		}
+	default:
+		logger.Warnw("netlink: recorder received unexpected data", "type", fmt.Sprintf("%T", e.Data), "event", event)
+	}
+}
+
+// resync records the tables again from the snapshot after events have been dropped
+func (r *Recorder) resync(event string, dropped uint64) {
+	if r.snapshot == nil {
+		logger.Warnw("netlink: recorder dropped events, the recorded tables are incomplete", "dropped", dropped, "event", event)
+		return
+	}
+	logger.Warnw("netlink: recorder dropped events, recording the tables again", "dropped", dropped, "event", event)
+	// The watcher publishes its tables before it notifies their changes, so the snapshot taken
+	// next includes the changes of all the events queued so far
+	r.mtx.RLock()
+	seqs := make(map[string]uint64, len(r.subs))
+	for e, sub := range r.subs {
+		seqs[e] = sub.Seq()
+	}
+	r.mtx.RUnlock()
+	tables := r.snapshot()
+
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	r.tables = tables
+	r.snapshotSeqs = seqs
+	r.resyncs++
+}
+
+// Resyncs returns the number of times the tables have been recorded again
+func (r *Recorder) Resyncs() int {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	return r.resyncs
+}
+
+// Tables returns a copy of the recorded tables
+func (r *Recorder) Tables() Tables {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	return Tables{
+		Routes:     copyMap(r.tables.Routes),
+		Nexthops:   copyMap(r.tables.Nexthops),
+		FDB:        copyMap(r.tables.FDB),
+		L2Nexthops: copyMap(r.tables.L2Nexthops),
+	}
+}
+
+// Count returns the number of events of the type that have been recorded
+func (r *Recorder) Count(event string) int {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	return r.counts[event]
+}
+
+func copyMap[K comparable, V any](m map[K]V) map[K]V {
+	c := make(map[K]V, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
@@ -1,27 +1,18 @@
 package netlink
 
 import (
-	"encoding/binary"
-	"encoding/json"
 	"fmt"
-	"log"
 	"net"
 	"path"
 	"reflect"
-	"strconv"
 	"strings"
 
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/logging"
 	vn "github.com/vishvananda/netlink"
 	"golang.org/x/sys/unix"
 )
 
-// routes Variable
-var routes = make(map[RouteKey]*RouteStruct)
-
-// latestRoutes Variable
-var latestRoutes = make(map[RouteKey]*RouteStruct)
-
 // routeOperations add, update, delete
 var routeOperations = Operations{Add: RouteAdded, Update: RouteUpdated, Delete: RouteDeleted}
 
@@ -172,13 +163,8 @@ func readRoutes(v *infradb.Vrf) {
 
 // checkRoute checks the route
 func (route *RouteStruct) checkRoute() bool {
-	rk := route.Key
-	for k := range latestRoutes {
-		if k == rk {
-			return true
-		}
-	}
-	return false
+	_, ok := watcher.latestRoutes[route.Key]
+	return ok
 }
 
 // nolint
@@ -192,7 +178,7 @@ func (route *RouteStruct) setRouteType(v *infradb.Vrf) string {
 		var devs []string
 		if len(route.Nexthops) != 0 {
 			for _, d := range route.Nexthops {
-				devs = append(devs, nameIndex[d.nexthop.LinkIndex])
+				devs = append(devs, watcher.LinkName(d.nexthop.LinkIndex))
 			}
 			if len(devs) == 1 && devs[0] == "br-"+v.Name {
 				return routeTypeEvpnVxlan
@@ -214,12 +200,12 @@ func (route *RouteStruct) setRouteType(v *infradb.Vrf) string {
 func (route *RouteStruct) addRoute() {
 	ch := route.checkRoute()
 	if ch {
-		r0 := latestRoutes[route.Key]
+		r0 := watcher.latestRoutes[route.Key]
 		if route.Route0.Priority >= r0.Route0.Priority {
 			// Route with lower metric exists and takes precedence
-			log.Printf("netlink: Ignoring %+v  with higher metric than %+v\n", route, r0)
+			logger.Debugw("netlink: Ignoring route with higher metric", "route", route.Key, "metric", route.Route0.Priority, "existingMetric", r0.Route0.Priority)
 		} else {
-			log.Printf("netlink: conflicts %+v with higher metric %+v. Will ignore it", route, r0)
+			logger.Debugw("netlink: Route conflicts with higher metric, will ignore it", "route", route.Key, "metric", route.Route0.Priority, "existingMetric", r0.Route0.Priority)
 		}
 	} else {
 		nexthops := route.Nexthops
@@ -228,30 +214,41 @@ func (route *RouteStruct) addRoute() {
 			nexthop.Metadata = make(map[interface{}]interface{})
 			route = nexthop.addNexthop(route)
 		}
-		latestRoutes[route.Key] = route
+		watcher.latestRoutes[route.Key] = route
 	}
 }
 
-// getNeighborRoutes gets the nighbor routes
-func getNeighborRoutes() []RouteCmdInfo {
+// getNeighborRoutes gets the nighbor routes of the vrf
+func getNeighborRoutes(v *infradb.Vrf) []RouteCmdInfo {
 	// Return a list of /32 or /128 routes & Nexthops to be inserted into
 	// the routing tables for Resolved neighbors on connected subnets
 	// on physical and SVI interfaces.
 	var neighborRoutes []RouteCmdInfo
-	for _, n := range latestNeighbors {
-		if n.Type == PHY || n.Type == SVI || n.Type == VXLAN {
-			vrf, _ := infradb.GetVrf(n.VrfName)
-			table := int(*vrf.Metadata.RoutingTable[0])
-
-			//# Create a special route with dst == gateway to resolve
-			//# the nexthop to the existing neighbor
-			n0 := RouteCmdInfo{Type: routeTypeNeighbor, Dst: n.Neigh0.IP.String(), Protocol: "ipu_infra_mgr", Scope: "global", Gateway: n.Neigh0.IP.String(), Dev: nameIndex[n.Neigh0.LinkIndex], VRF: vrf, Table: table}
+	for _, n := range watcher.latestNeighbors {
+		if n.VrfName != v.Name {
+			continue
+		}
+		if n0, ok := neighborRoute(&n); ok {
 			neighborRoutes = append(neighborRoutes, n0)
 		}
 	}
 	return neighborRoutes
 }
 
+// neighborRoute returns the neighbor route of the neighbor, if it has one
+func neighborRoute(n *NeighStruct) (RouteCmdInfo, bool) {
+	if n.Type != PHY && n.Type != SVI && n.Type != VXLAN {
+		return RouteCmdInfo{}, false
+	}
+	vrf, _ := infradb.GetVrf(n.VrfName)
+	table := int(*vrf.Metadata.RoutingTable[0])
+
+	//# Create a special route with dst == gateway to resolve
+	//# the nexthop to the existing neighbor
+	n0 := RouteCmdInfo{Type: routeTypeNeighbor, Dst: n.Neigh0.IP.String(), Protocol: "ipu_infra_mgr", Scope: "global", Gateway: n.Neigh0.IP.String(), Dev: watcher.LinkName(n.Neigh0.LinkIndex), VRF: vrf, Table: table}
+	return n0, true
+}
+
 // ParseRoute parse the routes
 // nolint
 func ParseRoute(v *infradb.Vrf, rc []RouteCmdInfo, t int) RouteList {
@@ -280,41 +277,14 @@ func ParseRoute(v *infradb.Vrf, rc []RouteCmdInfo, t int) RouteList {
 		rs.Route0.Table = t
 		rs.Route0.Priority = 1
 		if r0.Dev != "" {
-			dev, _ := vn.LinkByName(r0.Dev)
-			rs.Route0.LinkIndex = dev.Attrs().Index
+			rs.Route0.LinkIndex = linkIndex(r0.Dev)
 		}
 		if r0.Dst != "" {
-			var mask int
-			split := r0.Dst
-			if strings.Contains(r0.Dst, "/") {
-				split4 := strings.Split(r0.Dst, "/")
-				mask, _ = strconv.Atoi(split4[1])
-				split = split4[0]
-			} else {
-				mask = 32
-			}
-			var nIP *net.IPNet
-			if r0.Dst == "default" {
-				nIP = &net.IPNet{
-					IP:   net.ParseIP("0.0.0.0"),
-					Mask: net.IPv4Mask(0, 0, 0, 0),
-				}
-			} else {
-				mtoip := netMaskToInt(mask)
-				b3 := make([]byte, 8) // Converting int64 to byte
-				binary.LittleEndian.PutUint64(b3, uint64(mtoip[3]))
-				b2 := make([]byte, 8)
-				binary.LittleEndian.PutUint64(b2, uint64(mtoip[2]))
-				b1 := make([]byte, 8)
-				binary.LittleEndian.PutUint64(b1, uint64(mtoip[1]))
-				b0 := make([]byte, 8)
-				binary.LittleEndian.PutUint64(b0, uint64(mtoip[0]))
-				nIP = &net.IPNet{
-					IP:   net.ParseIP(split),
-					Mask: net.IPv4Mask(b0[0], b1[0], b2[0], b3[0]),
-				}
+			rs.Route0.Dst = parseRouteDst(r0.Dst)
+			rs.Route0.Family = vn.FAMILY_V4
+			if rs.Route0.Dst.IP.To4() == nil {
+				rs.Route0.Family = vn.FAMILY_V6
 			}
-			rs.Route0.Dst = nIP
 		}
 		if r0.Metric != 0 {
 			rs.Route0.Priority = r0.Metric
@@ -358,7 +328,7 @@ func ParseRoute(v *infradb.Vrf, rc []RouteCmdInfo, t int) RouteList {
 		if rs.preFilterRoute() {
 			route.RS = append(route.RS, &rs)
 		} else if rs.checkRoute() {
-			rou := latestRoutes[rs.Key]
+			rou := watcher.latestRoutes[rs.Key]
 			route.RS = append(route.RS, rou)
 		}
 
@@ -372,44 +342,26 @@ func cmdProcessRt(v *infradb.Vrf, routeData []RouteCmdInfo, t int) RouteList {
 	return route
 }
 
-// readRouteFromIP reads the routes from ip
+// readRouteFromIP reads the routes of the routing tables of the vrf,
+// the routes last read of a table are kept if it cannot be read
 func readRouteFromIP(v *infradb.Vrf) {
 	var rl RouteList
 	var rm []RouteCmdInfo
 	var rt int
-	var routeData []RouteCmdInfo
 	for _, route := range v.Metadata.RoutingTable {
 		rt = int(*route)
-		raw, err := nlink.ReadRoute(ctx, strconv.Itoa(rt))
-		if err != nil || len(raw) <= 3 {
-			log.Printf("netlink: Err Command route\n")
-			continue
-		}
-		var rawMessages []json.RawMessage
-		err = json.Unmarshal([]byte(raw), &rawMessages)
+		kernelRoutes, err := nlink.ReadRoute(ctx, rt)
 		if err != nil {
-			log.Printf("netlink route: JSON unmarshal error: %v %v : %v", err, raw, rawMessages)
-			continue
-		}
-		cps := make([]string, 0, len(rawMessages))
-		for _, rawMsg := range rawMessages {
-			cps = append(cps, string(rawMsg))
-		}
-		for i := 0; i < len(cps); i++ {
-			var ri RouteCmdInfo
-			err := json.Unmarshal([]byte(cps[i]), &ri)
-			if err != nil {
-				log.Println("error-", err)
-				continue
-			}
-			routeData = append(routeData, ri)
+			logger.Errorw("netlink: Failed to read the routes of table", "table", rt, "error", err)
+		} else {
+			watcher.kernel.setRoutes(rt, kernelRoutes)
 		}
-		rl = cmdProcessRt(v, routeData, rt)
+		rl = cmdProcessRt(v, routeCmdInfos(watcher.kernel.tableRoutes(rt)), rt)
 		for _, r := range rl.RS {
 			r.addRoute()
 		}
 	}
-	nl := getNeighborRoutes() // Add extra routes for Resolved neighbors on connected subnets
+	nl := getNeighborRoutes(v) // Add extra routes for Resolved neighbors on connected subnets
 	for i := 0; i < len(nl); i++ {
 		rm = append(rm, nl[i])
 	}
@@ -419,6 +371,81 @@ func readRouteFromIP(v *infradb.Vrf) {
 	}
 }
 
+// routeCmdInfos converts the routes read from the kernel to the form parsed by ParseRoute
+func routeCmdInfos(kernelRoutes []vn.Route) []RouteCmdInfo {
+	routeData := make([]RouteCmdInfo, 0, len(kernelRoutes))
+	for i := range kernelRoutes {
+		r := &kernelRoutes[i]
+		ri := RouteCmdInfo{
+			Type:     getTypeName(r.Type),
+			Dst:      getRouteDst(r),
+			Dev:      linkName(r.LinkIndex),
+			Protocol: getProtoName(int(r.Protocol)),
+			Scope:    getScopeName(r.Scope),
+			Metric:   r.Priority,
+			Flags:    getFlagNames(r.Flags),
+			Table:    r.Table,
+		}
+		if r.Gw != nil {
+			ri.Gateway = r.Gw.String()
+		}
+		if r.Src != nil {
+			ri.Prefsrc = r.Src.String()
+		}
+		for _, nh := range r.MultiPath {
+			rn := RcNexthop{Dev: linkName(nh.LinkIndex), Flags: getFlagNames(nh.Flags), Weight: nh.Hops + 1}
+			if nh.Gw != nil {
+				rn.Gateway = nh.Gw.String()
+			}
+			ri.Nexthops = append(ri.Nexthops, rn)
+		}
+		routeData = append(routeData, ri)
+	}
+	return routeData
+}
+
+// getRouteDst gets the destination of the route, the default routes have none
+func getRouteDst(r *vn.Route) string {
+	if r.Dst != nil {
+		return r.Dst.String()
+	}
+	if r.Family == vn.FAMILY_V6 {
+		return "::/0"
+	}
+	return "default"
+}
+
+// getTypeName gets the name of the route type
+func getTypeName(t int) string {
+	for name, rt := range rtnType {
+		if rt == t {
+			return name
+		}
+	}
+	return ""
+}
+
+// getScopeName gets the name of the route scope
+func getScopeName(scope vn.Scope) string {
+	for name, rs := range rtnScope {
+		if rs == int(scope) {
+			return name
+		}
+	}
+	return ""
+}
+
+// getFlagNames gets the names of the nexthop flags
+func getFlagNames(flags int) []string {
+	var names []string
+	for _, flag := range []int{unix.RTNH_F_ONLINK, unix.RTNH_F_PERVASIVE} {
+		if flags&flag != 0 {
+			names = append(names, testFlag[flag])
+		}
+	}
+	return names
+}
+
 // getProto gets the route protocol
 func (route *RouteStruct) getProto() string {
 	for p, i := range rtnProto {
@@ -431,13 +458,7 @@ func (route *RouteStruct) getProto() string {
 
 // CheckRdup checks the duplication of routes
 func CheckRdup(tmpKey RouteKey) bool {
-	var dup = false
-	for j := range latestRoutes {
-		if j == tmpKey {
-			dup = true
-			break
-		}
-	}
+	_, dup := watcher.latestRoutes[tmpKey]
 	return dup
 }
 
@@ -480,47 +501,26 @@ func lookupRoute(dst net.IP, v *infradb.Vrf) (*RouteStruct, bool) {
 	// FIXME: If the semantic is to return the current entry of the NetlinkDB
 	//  routing table, a direct lookup in Linux should only be done as fallback
 	//  if there is no match in the DB.
-	var cp string
-	var err error
-	var routeData []RouteCmdInfo
+	var vrfName string
 	if v.Spec.Vni != nil {
-		cp, err = nlink.RouteLookup(ctx, dst.String(), path.Base(v.Name))
-	} else {
-		cp, err = nlink.RouteLookup(ctx, dst.String(), "")
+		vrfName = path.Base(v.Name)
 	}
-	if err != nil || len(cp) <= 3 {
-		log.Printf("netlink : Command error %v\n", err)
+	kernelRoutes, err := nlink.RouteLookup(ctx, dst, vrfName)
+	if err != nil || len(kernelRoutes) == 0 {
+		logger.Errorw("netlink: Failed to lookup route in VRF", "destination", dst, logging.Name(v.Name), "error", err)
 		return &RouteStruct{}, false
 	}
-	var rawMessages []json.RawMessage
-	err = json.Unmarshal([]byte(cp), &rawMessages)
-	if err != nil {
-		log.Printf("netlink route: JSON unmarshal error: %v %v : %v\n", err, cp, rawMessages)
-		return &RouteStruct{}, false
-	}
-	cps := make([]string, 0, len(rawMessages))
-	for _, rawMsg := range rawMessages {
-		cps = append(cps, string(rawMsg))
-	}
-	for i := 0; i < len(cps); i++ {
-		var ri RouteCmdInfo
-		err := json.Unmarshal([]byte(cps[i]), &ri)
-		if err != nil {
-			log.Println("error-", err)
-			return &RouteStruct{}, false
-		}
-		routeData = append(routeData, ri)
-	}
+	routeData := routeCmdInfos(kernelRoutes)
 	r := cmdProcessRt(v, routeData, int(*v.Metadata.RoutingTable[0]))
 	if len(r.RS) != 0 {
 		r0 := r.RS[0]
 		// ###  Search the latestRoutes DB snapshot if that exists, else
 		// ###  the current DB Route table.
 		var routeTable map[RouteKey]*RouteStruct
-		if len(latestRoutes) != 0 {
-			routeTable = latestRoutes
+		if len(watcher.latestRoutes) != 0 {
+			routeTable = watcher.latestRoutes
 		} else {
-			routeTable = routes
+			routeTable = watcher.routes
 		}
 		rDB, ok := routeTable[r0.Key]
 		if ok {
@@ -531,7 +531,7 @@ func lookupRoute(dst net.IP, v *infradb.Vrf) (*RouteStruct, bool) {
 		return r0, true
 	}
 
-	log.Printf("netlink: Failed to lookup route %v in VRF %v", dst, v)
+	logger.Errorw("netlink: Failed to lookup route in VRF", "destination", dst, logging.Name(v.Name))
 	return &RouteStruct{}, false
 }
 
@@ -587,22 +587,21 @@ func (route *RouteStruct) GetVrfOperStatus() infradb.VrfOperStatus {
 
 // dumpRouteDB dump the route database
 func dumpRouteDB() string {
-	var s string
-	log.Printf("netlink: Dump Route table:\n")
-	s = "Route table:\n"
-	for _, n := range latestRoutes {
+	var s strings.Builder
+	logger.Infow("netlink: Dump Route table")
+	s.WriteString("Route table:\n")
+	for _, n := range watcher.latestRoutes {
 		var via string
 		if n.Route0.Gw == nil {
 			via = strNone
 		} else {
 			via = n.Route0.Gw.String()
 		}
-		str := fmt.Sprintf("Route(vrf=%s dst=%s type=%s proto=%s metric=%d  via=%s dev=%s nhid= %+v Table= %d)", n.Vrf.Name, n.Route0.Dst.String(), n.NlType, n.getProto(), n.Route0.Priority, via, nameIndex[n.Route0.LinkIndex], n.Nexthops, n.Route0.Table)
-		log.Println(str)
-		s += str
-		s += "\n"
+		str := fmt.Sprintf("Route(vrf=%s dst=%s type=%s proto=%s metric=%d  via=%s dev=%s nhid= %+v Table= %d)", n.Vrf.Name, n.Route0.Dst.String(), n.NlType, n.getProto(), n.Route0.Priority, via, watcher.LinkName(n.Route0.LinkIndex), n.Nexthops, n.Route0.Table)
+		logger.Debug(str)
+		s.WriteString(str)
+		s.WriteString("\n")
 	}
-	log.Printf("\n\n\n")
-	s += "\n\n"
-	return s
+	s.WriteString("\n\n")
+	return s.String()
 }
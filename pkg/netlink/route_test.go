@@ -0,0 +1,71 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package netlink handles the netlink related functionality
+package netlink
+
+import (
+	"net"
+	"reflect"
+	"testing"
+
+	vn "github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+)
+
+func TestRouteCmdInfos(t *testing.T) {
+	watcher.setLinkName(7, "br-blue")
+	watcher.setLinkName(8, "blue-10")
+	_, dst, _ := net.ParseCIDR("10.10.10.0/24")
+	tests := map[string]struct {
+		route    vn.Route
+		expected RouteCmdInfo
+	}{
+		"evpn route": {
+			route: vn.Route{
+				Dst: dst, Gw: net.ParseIP("10.0.0.2"), LinkIndex: 7, Table: 1001, Priority: 20,
+				Type: unix.RTN_UNICAST, Protocol: unix.RTPROT_BGP, Scope: unix.RT_SCOPE_UNIVERSE, Flags: unix.RTNH_F_ONLINK,
+			},
+			expected: RouteCmdInfo{
+				Type: "unicast", Dst: "10.10.10.0/24", Gateway: "10.0.0.2", Dev: "br-blue", Protocol: "bgp",
+				Scope: "global", Metric: 20, Flags: []string{"onlink"}, Table: 1001,
+			},
+		},
+		"connected route": {
+			route: vn.Route{
+				Dst: dst, Src: net.ParseIP("10.10.10.1"), LinkIndex: 8, Table: 1001,
+				Type: unix.RTN_UNICAST, Protocol: unix.RTPROT_KERNEL, Scope: unix.RT_SCOPE_LINK,
+			},
+			expected: RouteCmdInfo{
+				Type: "unicast", Dst: "10.10.10.0/24", Prefsrc: "10.10.10.1", Dev: "blue-10", Protocol: "kernel",
+				Scope: "link", Table: 1001,
+			},
+		},
+		"ipv6 default multipath route": {
+			route: vn.Route{
+				Family: vn.FAMILY_V6, Table: 1001, Type: unix.RTN_UNICAST, Protocol: 196,
+				MultiPath: []*vn.NexthopInfo{
+					{LinkIndex: 7, Gw: net.ParseIP("fd00::2"), Hops: 0},
+					{LinkIndex: 8, Gw: net.ParseIP("fd00::3"), Hops: 1},
+				},
+			},
+			expected: RouteCmdInfo{
+				Type: "unicast", Dst: "::/0", Protocol: "196", Scope: "global", Table: 1001,
+				Nexthops: []RcNexthop{
+					{Gateway: "fd00::2", Dev: "br-blue", Weight: 1},
+					{Gateway: "fd00::3", Dev: "blue-10", Weight: 2},
+				},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			routeData := routeCmdInfos([]vn.Route{tt.route})
+			if len(routeData) != 1 || !reflect.DeepEqual(routeData[0], tt.expected) {
+				t.Errorf("Expected %+v, received %+v", tt.expected, routeData)
+			}
+		})
+	}
+}
@@ -0,0 +1,342 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package netlink handles the netlink related functionality
+package netlink
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
+)
+
+// ServiceName is the name of the netlink admin service
+const ServiceName = "opi_evpn_bridge.v1.NetlinkService"
+
+// AdminServer is the server API for the NetlinkService
+type AdminServer interface {
+	ShowNetlinkTables(context.Context, *structpb.Struct) (*structpb.Struct, error)
+}
+
+// AdminServiceDesc describes the NetlinkService.
+// There are no generated messages for the derived tables so the query and
+// the tables are carried as google.protobuf.Struct, the routes, nexthops,
+// fdb entries and l2 nexthops grouped per vrf.
+var AdminServiceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*AdminServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ShowNetlinkTables",
+			Handler:    showNetlinkTablesHandler,
+		},
+	},
+	Streams: []grpc.StreamDesc{},
+}
+
+// RegisterAdminServer registers the NetlinkService to the gRPC server
+func RegisterAdminServer(s grpc.ServiceRegistrar, srv AdminServer) {
+	s.RegisterService(&AdminServiceDesc, srv)
+}
+
+func showNetlinkTablesHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(structpb.Struct)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).ShowNetlinkTables(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/" + ServiceName + "/ShowNetlinkTables",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).ShowNetlinkTables(ctx, req.(*structpb.Struct))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Server implements the NetlinkService on top of a watcher
+type Server struct {
+	watcher *Watcher
+	// sviVrf returns the vrf of the svi, the l2 entries of a bridge belong to the vrf of its svi
+	sviVrf func(name string) (string, error)
+}
+
+// NewServer creates the netlink admin server of the watcher
+func NewServer(w *Watcher) *Server {
+	return &Server{watcher: w, sviVrf: getSviVrf}
+}
+
+// getSviVrf returns the vrf of the svi from the infra db
+func getSviVrf(name string) (string, error) {
+	svi, err := infradb.GetSvi(name)
+	if err != nil {
+		return "", err
+	}
+	return svi.Spec.Vrf, nil
+}
+
+// ShowNetlinkTables returns the tables derived by the watcher. The query field vrf is
+// the full name of the vrf to show, all the vrfs are shown without it.
+// The fdb entries and l2 nexthops of the bridges without svi are not in any vrf
+// and are returned at the top level when all the vrfs are shown.
+// The tables are paged like the list methods with the query fields page_size and
+// page_token, the token of the next page is returned as next_page_token.
+func (s *Server) ShowNetlinkTables(_ context.Context, in *structpb.Struct) (*structpb.Struct, error) {
+	var vrf, pageToken string
+	var pageSize int32
+	for key, value := range in.GetFields() {
+		switch key {
+		case "vrf":
+			vrf = value.GetStringValue()
+		case "page_size":
+			pageSize = int32(value.GetNumberValue())
+		case "page_token":
+			pageToken = value.GetStringValue()
+		default:
+			return nil, status.Errorf(codes.InvalidArgument, "unknown query field %s", key)
+		}
+	}
+
+	// The entries are listed in the order of their keys, routes first, then nexthops,
+	// fdb entries and l2 nexthops, so that the page tokens keep their position
+	var entries []tableEntry
+
+	routes := s.watcher.Routes(vrf)
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Key.Table != routes[j].Key.Table {
+			return routes[i].Key.Table < routes[j].Key.Table
+		}
+		return routes[i].Key.Dst < routes[j].Key.Dst
+	})
+	for _, r := range routes {
+		if r.Vrf == nil {
+			continue
+		}
+		entries = append(entries, tableEntry{
+			key:    []interface{}{int64(0), int64(r.Key.Table), r.Key.Dst},
+			vrf:    r.Vrf.Name,
+			table:  "routes",
+			fields: routeFields(r),
+		})
+	}
+
+	nexthops := s.watcher.Nexthops(vrf)
+	sort.Slice(nexthops, func(i, j int) bool { return nexthops[i].ID < nexthops[j].ID })
+	for _, nh := range nexthops {
+		entries = append(entries, tableEntry{
+			key:    []interface{}{int64(1), int64(nh.ID)},
+			vrf:    nh.Key.VrfName,
+			table:  "nexthops",
+			fields: s.nexthopFields(nh),
+		})
+	}
+
+	// The vrfs of the bridges are looked up once per bridge
+	bridgeVrfs := make(map[string]string)
+	bridgeVrf := func(lb *infradb.LogicalBridge) string {
+		if lb == nil || lb.Svi == "" {
+			return ""
+		}
+		name, ok := bridgeVrfs[lb.Name]
+		if !ok {
+			var err error
+			if name, err = s.sviVrf(lb.Svi); err != nil {
+				logger.Warnw("ShowNetlinkTables(): Failed to find the vrf of svi", "svi", lb.Svi, "error", err)
+			}
+			bridgeVrfs[lb.Name] = name
+		}
+		return name
+	}
+	// The l2 entries of the bridges without svi are only shown with all the vrfs
+	shown := func(name string) bool {
+		return vrf == "" || name == vrf
+	}
+
+	fdbEntries := s.watcher.FDB()
+	sort.Slice(fdbEntries, func(i, j int) bool {
+		if fdbEntries[i].Key.VlanID != fdbEntries[j].Key.VlanID {
+			return fdbEntries[i].Key.VlanID < fdbEntries[j].Key.VlanID
+		}
+		return fdbEntries[i].Key.Mac < fdbEntries[j].Key.Mac
+	})
+	for _, f := range fdbEntries {
+		if name := bridgeVrf(f.lb); shown(name) {
+			entries = append(entries, tableEntry{
+				key:    []interface{}{int64(2), int64(f.Key.VlanID), f.Key.Mac},
+				vrf:    name,
+				table:  "fdb",
+				fields: fdbFields(f),
+			})
+		}
+	}
+
+	l2nhs := s.watcher.L2Nexthops()
+	sort.Slice(l2nhs, func(i, j int) bool { return l2nhs[i].ID < l2nhs[j].ID })
+	for _, l2n := range l2nhs {
+		if name := bridgeVrf(l2n.lb); shown(name) {
+			entries = append(entries, tableEntry{
+				key:    []interface{}{int64(3), int64(l2n.ID)},
+				vrf:    name,
+				table:  "l2nexthops",
+				fields: l2NexthopFields(l2n),
+			})
+		}
+	}
+
+	keys := make([][]interface{}, 0, len(entries))
+	for _, e := range entries {
+		keys = append(keys, e.key)
+	}
+	start, end, nextPageToken, err := utils.KeyPage(keys, pageSize, pageToken, vrf)
+	if err != nil {
+		return nil, err
+	}
+
+	vrfs := make(map[string]map[string][]interface{})
+	vrfTables := func(name string) map[string][]interface{} {
+		t, ok := vrfs[name]
+		if !ok {
+			t = map[string][]interface{}{"routes": {}, "nexthops": {}, "fdb": {}, "l2nexthops": {}}
+			vrfs[name] = t
+		}
+		return t
+	}
+	fdb, l2Nexthops := []interface{}{}, []interface{}{}
+	for _, e := range entries[start:end] {
+		switch {
+		case e.vrf != "":
+			t := vrfTables(e.vrf)
+			t[e.table] = append(t[e.table], e.fields)
+		case e.table == "fdb":
+			fdb = append(fdb, e.fields)
+		default:
+			l2Nexthops = append(l2Nexthops, e.fields)
+		}
+	}
+
+	vrfFields := make(map[string]interface{}, len(vrfs))
+	for name, t := range vrfs {
+		vrfFields[name] = map[string]interface{}{
+			"routes":     t["routes"],
+			"nexthops":   t["nexthops"],
+			"fdb":        t["fdb"],
+			"l2nexthops": t["l2nexthops"],
+		}
+	}
+	result := map[string]interface{}{"vrfs": vrfFields}
+	if vrf == "" {
+		result["fdb"] = fdb
+		result["l2nexthops"] = l2Nexthops
+	}
+	if nextPageToken != "" {
+		result["next_page_token"] = nextPageToken
+	}
+	return structpb.NewStruct(result)
+}
+
+// tableEntry is an entry of the netlink tables with the key it is paged by
+type tableEntry struct {
+	key    []interface{}
+	vrf    string
+	table  string
+	fields map[string]interface{}
+}
+
+// routeFields returns the fields of the route shown by the admin service
+func routeFields(r *RouteStruct) map[string]interface{} {
+	ids := make([]interface{}, 0, len(r.Nexthops))
+	for _, nh := range r.Nexthops {
+		ids = append(ids, nh.ID)
+	}
+	return map[string]interface{}{
+		"table":    r.Key.Table,
+		"dst":      r.Key.Dst,
+		"type":     r.NlType,
+		"protocol": getProtoName(int(r.Route0.Protocol)),
+		"metric":   r.Route0.Priority,
+		"nexthops": ids,
+	}
+}
+
+// nexthopFields returns the fields of the nexthop shown by the admin service
+func (s *Server) nexthopFields(nh *NexthopStruct) map[string]interface{} {
+	var gw string
+	if nh.nexthop.Gw != nil {
+		gw = nh.nexthop.Gw.String()
+	}
+	return map[string]interface{}{
+		"id":       nh.ID,
+		"gateway":  gw,
+		"dev":      s.watcher.LinkName(nh.nexthop.LinkIndex),
+		"type":     nh.NhType,
+		"weight":   nh.Weight,
+		"local":    nh.Local,
+		"resolved": nh.Resolved,
+		"routes":   len(nh.RouteRefs),
+		"metadata": metadataFields(nh.Metadata),
+	}
+}
+
+// fdbFields returns the fields of the fdb entry shown by the admin service
+func fdbFields(f *FdbEntryStruct) map[string]interface{} {
+	fields := map[string]interface{}{
+		"vlanID":   f.Key.VlanID,
+		"mac":      f.Key.Mac,
+		"state":    f.State,
+		"type":     f.Type,
+		"metadata": metadataFields(f.Metadata),
+	}
+	if f.Nexthop != nil {
+		fields["l2nexthop"] = f.Nexthop.ID
+	}
+	return fields
+}
+
+// l2NexthopFields returns the fields of the l2 nexthop shown by the admin service
+func l2NexthopFields(l2n *L2NexthopStruct) map[string]interface{} {
+	var dst string
+	if l2n.Dst != nil {
+		dst = l2n.Dst.String()
+	}
+	return map[string]interface{}{
+		"id":       l2n.ID,
+		"dev":      l2n.Dev,
+		"vlanID":   l2n.VlanID,
+		"dst":      dst,
+		"type":     l2n.Type,
+		"resolved": l2n.Resolved,
+		"fdb":      len(l2n.FdbRefs),
+		"metadata": metadataFields(l2n.Metadata),
+	}
+}
+
+// metadataFields returns the scalar metadata as strings,
+// the metadata referring to other entries of the tables are left out
+func metadataFields(metadata map[interface{}]interface{}) map[string]interface{} {
+	fields := make(map[string]interface{}, len(metadata))
+	for k, v := range metadata {
+		if v == nil {
+			continue
+		}
+		switch reflect.TypeOf(v).Kind() {
+		case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.String:
+			fields[fmt.Sprint(k)] = fmt.Sprint(v)
+		default:
+		}
+	}
+	return fields
+}
@@ -0,0 +1,362 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package netlink handles the netlink related functionality
+package netlink
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	vn "github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils/mocks"
+)
+
+// testWatcher returns a watcher with the routes and nexthops of the blue and green vrfs,
+// and the fdb entries of a bridge with svi in the blue vrf and of a bridge without svi
+func testWatcher() *Watcher {
+	blue := &infradb.Vrf{Name: "//network.opiproject.org/vrfs/blue"}
+	green := &infradb.Vrf{Name: "//network.opiproject.org/vrfs/green"}
+	blueNexthop := &NexthopStruct{Vrf: blue, ID: 16, Key: NexthopKey{VrfName: blue.Name, Dev: 7}, Resolved: true,
+		Metadata: map[interface{}]interface{}{"vlanID": uint32(10), "l2_nh": &L2NexthopStruct{}}}
+	blueNexthop.nexthop.LinkIndex = 7
+	blueNexthop.nexthop.Gw = net.ParseIP("10.10.10.2")
+	greenNexthop := &NexthopStruct{Vrf: green, ID: 17, Key: NexthopKey{VrfName: green.Name, Dev: 8}}
+	blueRoute := &RouteStruct{Vrf: blue, Key: RouteKey{Table: 1001, Dst: "10.10.10.0/24"}, NlType: routeTypeConnected,
+		Nexthops: []*NexthopStruct{blueNexthop}}
+	greenRoute := &RouteStruct{Vrf: green, Key: RouteKey{Table: 1002, Dst: "10.10.10.0/24"}, NlType: routeTypeBgp,
+		Nexthops: []*NexthopStruct{greenNexthop}}
+	blueNexthop.RouteRefs = []*RouteStruct{blueRoute}
+	greenNexthop.RouteRefs = []*RouteStruct{greenRoute}
+
+	blueBridge := &infradb.LogicalBridge{Name: "//network.opiproject.org/bridges/blue", Svi: "//network.opiproject.org/svis/blue"}
+	plainBridge := &infradb.LogicalBridge{Name: "//network.opiproject.org/bridges/plain"}
+	blueL2Nexthop := &L2NexthopStruct{Key: L2NexthopKey{Dev: "br-tenant", VlanID: 10}, Dev: "br-tenant", VlanID: 10, ID: 16, lb: blueBridge}
+	plainL2Nexthop := &L2NexthopStruct{Key: L2NexthopKey{Dev: "br-tenant", VlanID: 20}, Dev: "br-tenant", VlanID: 20, ID: 17, lb: plainBridge}
+	blueFdb := &FdbEntryStruct{Key: FdbKey{VlanID: 10, Mac: "aa:bb:cc:dd:ee:ff"}, lb: blueBridge, Nexthop: blueL2Nexthop}
+	plainFdb := &FdbEntryStruct{Key: FdbKey{VlanID: 20, Mac: "aa:bb:cc:dd:ee:00"}, lb: plainBridge, Nexthop: plainL2Nexthop}
+
+	w := NewWatcher()
+	w.routes = map[RouteKey]*RouteStruct{blueRoute.Key: blueRoute, greenRoute.Key: greenRoute}
+	w.nexthops = map[NexthopKey]*NexthopStruct{blueNexthop.Key: blueNexthop, greenNexthop.Key: greenNexthop}
+	w.fDB = map[FdbKey]*FdbEntryStruct{blueFdb.Key: blueFdb, plainFdb.Key: plainFdb}
+	w.l2Nexthops = map[L2NexthopKey]*L2NexthopStruct{blueL2Nexthop.Key: blueL2Nexthop, plainL2Nexthop.Key: plainL2Nexthop}
+	w.nameIndex = map[int]string{7: "br-blue", 8: "br-green"}
+	return w
+}
+
+func TestWatcherAccessors(t *testing.T) {
+	w := testWatcher()
+
+	tests := map[string]struct {
+		vrf      string
+		routes   int
+		nexthops int
+	}{
+		"all vrfs": {
+			routes: 2, nexthops: 2,
+		},
+		"vrf": {
+			vrf:    "//network.opiproject.org/vrfs/blue",
+			routes: 1, nexthops: 1,
+		},
+		"unknown vrf": {
+			vrf: "//network.opiproject.org/vrfs/red",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if routes := w.Routes(tt.vrf); len(routes) != tt.routes {
+				t.Errorf("Expected %v routes, received %v", tt.routes, routes)
+			}
+			if nexthops := w.Nexthops(tt.vrf); len(nexthops) != tt.nexthops {
+				t.Errorf("Expected %v nexthops, received %v", tt.nexthops, nexthops)
+			}
+		})
+	}
+
+	if fdb, l2Nexthops := w.FDB(), w.L2Nexthops(); len(fdb) != 2 || len(l2Nexthops) != 2 {
+		t.Errorf("Expected 2 fdb entries and 2 l2 nexthops, received %v and %v", fdb, l2Nexthops)
+	}
+	if name := w.LinkName(7); name != "br-blue" {
+		t.Errorf("Expected link br-blue, received %v", name)
+	}
+	tables := w.Tables()
+	delete(tables.Routes, RouteKey{Table: 1001, Dst: "10.10.10.0/24"})
+	if len(w.Routes("")) != 2 {
+		t.Errorf("Expected the tables to be copied")
+	}
+	// The entries returned are copies, changing them leaves the current ones alone
+	blue := "//network.opiproject.org/vrfs/blue"
+	route, nexthop := w.Routes(blue)[0], w.Nexthops(blue)[0]
+	route.Nexthops[0], nexthop.RouteRefs = nil, nil
+	nexthop.Metadata["vlanID"] = uint32(20)
+	w.FDB()[0].Key.Mac, w.L2Nexthops()[0].Dev = "", ""
+	if route, nexthop := w.routes[route.Key], w.nexthops[nexthop.Key]; route.Nexthops[0] == nil || nexthop.RouteRefs == nil ||
+		nexthop.Metadata["vlanID"] != uint32(10) {
+		t.Errorf("Expected the route and nexthop to be copied, received %v and %v", route, nexthop)
+	}
+	for _, f := range w.fDB {
+		if f.Key.Mac == "" {
+			t.Errorf("Expected the fdb entries to be copied, received %v", f)
+		}
+	}
+	for _, l2n := range w.l2Nexthops {
+		if l2n.Dev == "" {
+			t.Errorf("Expected the l2 nexthops to be copied, received %v", l2n)
+		}
+	}
+}
+
+func TestWatcherReleaseNexthopIDs(t *testing.T) {
+	w := NewWatcher()
+	kept := &NexthopStruct{Key: NexthopKey{VrfName: "//network.opiproject.org/vrfs/blue", Dev: 7}}
+	deleted := &NexthopStruct{Key: NexthopKey{VrfName: "//network.opiproject.org/vrfs/blue", Dev: 8}}
+	keptL2 := &L2NexthopStruct{Key: L2NexthopKey{Dev: "br-tenant", VlanID: 10}}
+	deletedL2 := &L2NexthopStruct{Key: L2NexthopKey{Dev: "br-tenant", VlanID: 20}}
+	keptID := w.nhIDPool.GetID(nexthopIDKey(kept.Key))
+	w.nhIDPool.GetID(nexthopIDKey(deleted.Key))
+	keptL2ID := w.l2NhIDPool.GetID(l2NexthopIDKey(keptL2.Key))
+	w.l2NhIDPool.GetID(l2NexthopIDKey(deletedL2.Key))
+	w.nexthops = map[NexthopKey]*NexthopStruct{kept.Key: kept, deleted.Key: deleted}
+	w.l2Nexthops = map[L2NexthopKey]*L2NexthopStruct{keptL2.Key: keptL2, deletedL2.Key: deletedL2}
+	w.latestNexthop = map[NexthopKey]*NexthopStruct{kept.Key: kept}
+	w.latestL2Nexthop = map[L2NexthopKey]*L2NexthopStruct{keptL2.Key: keptL2}
+
+	w.releaseNexthopIDs()
+
+	if id := w.nhIDPool.ReleaseID(nexthopIDKey(deleted.Key)); id != 0 {
+		t.Errorf("Expected the id of the deleted nexthop to be released, found %v in use", id)
+	}
+	if id := w.l2NhIDPool.ReleaseID(l2NexthopIDKey(deletedL2.Key)); id != 0 {
+		t.Errorf("Expected the id of the deleted l2 nexthop to be released, found %v in use", id)
+	}
+	if id := w.nhIDPool.ReleaseID(nexthopIDKey(kept.Key)); id != keptID {
+		t.Errorf("Expected the nexthop to keep id %v, received %v", keptID, id)
+	}
+	if id := w.l2NhIDPool.ReleaseID(l2NexthopIDKey(keptL2.Key)); id != keptL2ID {
+		t.Errorf("Expected the l2 nexthop to keep id %v, received %v", keptL2ID, id)
+	}
+}
+
+func TestServerShowNetlinkTables(t *testing.T) {
+	tests := map[string]struct {
+		query      map[string]interface{}
+		errCode    codes.Code
+		vrfs       map[string][]int
+		fdb        int
+		l2nexthops int
+		nextPage   bool
+	}{
+		"all vrfs": {
+			query: map[string]interface{}{},
+			vrfs: map[string][]int{
+				"//network.opiproject.org/vrfs/blue":  {1, 1, 1, 1},
+				"//network.opiproject.org/vrfs/green": {1, 1, 0, 0},
+			},
+			fdb: 1, l2nexthops: 1,
+		},
+		"vrf": {
+			query: map[string]interface{}{"vrf": "//network.opiproject.org/vrfs/green"},
+			vrfs: map[string][]int{
+				"//network.opiproject.org/vrfs/green": {1, 1, 0, 0},
+			},
+		},
+		"unknown vrf": {
+			query: map[string]interface{}{"vrf": "//network.opiproject.org/vrfs/red"},
+			vrfs:  map[string][]int{},
+		},
+		"unknown field": {
+			query:   map[string]interface{}{"table": 1001},
+			errCode: codes.InvalidArgument,
+		},
+		"first page": {
+			query: map[string]interface{}{"page_size": 2},
+			vrfs: map[string][]int{
+				"//network.opiproject.org/vrfs/blue":  {1, 0, 0, 0},
+				"//network.opiproject.org/vrfs/green": {1, 0, 0, 0},
+			},
+			nextPage: true,
+		},
+		"last page": {
+			query: map[string]interface{}{"vrf": "//network.opiproject.org/vrfs/green", "page_size": 2},
+			vrfs: map[string][]int{
+				"//network.opiproject.org/vrfs/green": {1, 1, 0, 0},
+			},
+		},
+		"negative page size": {
+			query:   map[string]interface{}{"page_size": -1},
+			errCode: codes.InvalidArgument,
+		},
+		"invalid page token": {
+			query:   map[string]interface{}{"page_token": "unknown"},
+			errCode: codes.InvalidArgument,
+		},
+	}
+
+	sviVrfs := map[string]string{"//network.opiproject.org/svis/blue": "//network.opiproject.org/vrfs/blue"}
+	s := NewServer(testWatcher())
+	s.sviVrf = func(name string) (string, error) {
+		vrf, ok := sviVrfs[name]
+		if !ok {
+			return "", errors.New("not found")
+		}
+		return vrf, nil
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			query, err := structpb.NewStruct(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := s.ShowNetlinkTables(context.Background(), query)
+			if status.Code(err) != tt.errCode {
+				t.Fatalf("Expected error code %v, received %v", tt.errCode, err)
+			}
+			if err != nil {
+				return
+			}
+			tables := result.AsMap()
+			vrfs := tables["vrfs"].(map[string]interface{})
+			if len(vrfs) != len(tt.vrfs) {
+				t.Errorf("Expected vrfs %v, received %v", tt.vrfs, vrfs)
+			}
+			for vrf, counts := range tt.vrfs {
+				v, ok := vrfs[vrf].(map[string]interface{})
+				if !ok {
+					t.Errorf("Expected vrf %v, received %v", vrf, vrfs)
+					continue
+				}
+				for i, table := range []string{"routes", "nexthops", "fdb", "l2nexthops"} {
+					if entries := v[table].([]interface{}); len(entries) != counts[i] {
+						t.Errorf("Expected %v %v in vrf %v, received %v", counts[i], table, vrf, entries)
+					}
+				}
+			}
+			if fdb, _ := tables["fdb"].([]interface{}); len(fdb) != tt.fdb {
+				t.Errorf("Expected %v fdb entries out of the vrfs, received %v", tt.fdb, fdb)
+			}
+			if l2nexthops, _ := tables["l2nexthops"].([]interface{}); len(l2nexthops) != tt.l2nexthops {
+				t.Errorf("Expected %v l2 nexthops out of the vrfs, received %v", tt.l2nexthops, l2nexthops)
+			}
+			if _, ok := tables["next_page_token"]; ok != tt.nextPage {
+				t.Errorf("Expected next page %v, received %v", tt.nextPage, tables["next_page_token"])
+			}
+		})
+	}
+}
+
+// TestServerShowNetlinkTablesPages follows the page tokens through all the tables
+func TestServerShowNetlinkTablesPages(t *testing.T) {
+	s := NewServer(testWatcher())
+	s.sviVrf = func(string) (string, error) { return "", errors.New("not found") }
+
+	var entries []interface{}
+	fields := map[string]interface{}{"page_size": 1}
+	for pages := 0; ; pages++ {
+		if pages > 10 {
+			t.Fatalf("Expected the pages to end, received %v", entries)
+		}
+		query, err := structpb.NewStruct(fields)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, err := s.ShowNetlinkTables(context.Background(), query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tables := result.AsMap()
+		page := append(tables["fdb"].([]interface{}), tables["l2nexthops"].([]interface{})...)
+		for _, v := range tables["vrfs"].(map[string]interface{}) {
+			for _, table := range []string{"routes", "nexthops", "fdb", "l2nexthops"} {
+				page = append(page, v.(map[string]interface{})[table].([]interface{})...)
+			}
+		}
+		if len(page) != 1 {
+			t.Errorf("Expected 1 entry per page, received %v", page)
+		}
+		entries = append(entries, page...)
+		token, ok := tables["next_page_token"]
+		if !ok {
+			break
+		}
+		fields["page_token"] = token
+	}
+
+	// The fdb entry and the l2 nexthop of the blue bridge are out of the vrfs without its svi
+	if len(entries) != 8 {
+		t.Errorf("Expected 8 entries, received %v", entries)
+	}
+}
+
+// TestWatcherConcurrentReads reads the tables while the watcher resyncs, run with -race
+func TestWatcherConcurrentReads(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chdir(wd) }()
+
+	createVrfs(t, map[string]uint32{"GRD": 254, "blue": 1001})
+	mockNetlink := mocks.NewNetlink(t)
+	mockNetlink.EXPECT().ReadRoute(mock.Anything, 254).Return(benchmarkRoutes(254, 10), nil).Maybe()
+	mockNetlink.EXPECT().ReadRoute(mock.Anything, 1001).Return(benchmarkRoutes(1001, 100), nil).Maybe()
+	mockNetlink.EXPECT().ReadNeigh(mock.Anything, mock.Anything).Return(nil, nil).Maybe()
+	mockNetlink.EXPECT().ReadFDB(mock.Anything, mock.Anything).Return(nil, nil).Maybe()
+	ctx = context.Background()
+	nlink = mockNetlink
+	defer func(w *Watcher) { watcher = w }(watcher)
+	watcher = NewWatcher()
+	getlink()
+
+	s := NewServer(watcher)
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				if _, err := s.ShowNetlinkTables(context.Background(), &structpb.Struct{}); err != nil {
+					t.Errorf("Expected the tables to be shown, received %v", err)
+					return
+				}
+				_ = watcher.Tables()
+			}
+		}()
+	}
+	added := benchmarkRoutes(1001, 101)[100]
+	for i := 0; i < 10; i++ {
+		watcher.resync()
+		watcher.resyncChanges(&changeSet{updates: []interface{}{vn.RouteUpdate{Type: unix.RTM_NEWROUTE, Route: added}}})
+		watcher.resyncChanges(&changeSet{updates: []interface{}{vn.RouteUpdate{Type: unix.RTM_DELROUTE, Route: added}}})
+	}
+	close(stop)
+	wg.Wait()
+
+	if routes := watcher.Routes("//network.opiproject.org/vrfs/blue"); len(routes) != 100 {
+		t.Errorf("Expected the 100 routes of the blue vrf, received %v", len(routes))
+	}
+}
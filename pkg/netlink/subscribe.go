@@ -0,0 +1,195 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package netlink handles the netlink related functionality
+package netlink
+
+import (
+	"time"
+
+	vn "github.com/vishvananda/netlink"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/metrics"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
+)
+
+// eventSettleTime is the time the kernel changes are batched before the tables are updated
+const eventSettleTime = 100 * time.Millisecond
+
+// receiveBufferSize is the size of the socket buffers of the subscriptions,
+// large enough to hold the burst of changes of a full BGP table
+const receiveBufferSize = 8 << 20
+
+// kernelSubscription receives the route, neighbor and link changes of the kernel,
+// the kernel notifies the changes of the local and connected routes of the addresses
+type kernelSubscription struct {
+	routes chan vn.RouteUpdate
+	neighs chan utils.NeighUpdate
+	links  chan vn.LinkUpdate
+	done   chan struct{}
+}
+
+// subscribe subscribes to the route, neighbor and link changes of the kernel
+func subscribe() (*kernelSubscription, error) {
+	s := &kernelSubscription{
+		routes: make(chan vn.RouteUpdate, 1024),
+		neighs: make(chan utils.NeighUpdate, 1024),
+		links:  make(chan vn.LinkUpdate, 64),
+		done:   make(chan struct{}),
+	}
+	// A lost subscription closes its channel, the changes are then caught up by a full resync
+	onError := func(err error) {
+		select {
+		case <-s.done:
+		default:
+			logger.Warnw("netlink: Kernel subscription failed", "error", err)
+		}
+	}
+	subscriptions := []struct {
+		subscribe func() error
+		abort     func()
+	}{
+		{func() error {
+			return vn.RouteSubscribeWithOptions(s.routes, s.done, vn.RouteSubscribeOptions{ErrorCallback: onError, ReceiveBufferSize: receiveBufferSize})
+		}, func() { close(s.routes) }},
+		{func() error {
+			return utils.NeighSubscribeWithOptions(s.neighs, s.done, vn.NeighSubscribeOptions{ErrorCallback: onError, ReceiveBufferSize: receiveBufferSize})
+		}, func() { close(s.neighs) }},
+		{func() error {
+			return vn.LinkSubscribeWithOptions(s.links, s.done, vn.LinkSubscribeOptions{ErrorCallback: onError, ReceiveBufferSize: receiveBufferSize})
+		}, func() { close(s.links) }},
+	}
+	for i, sub := range subscriptions {
+		if err := sub.subscribe(); err != nil {
+			// The channels of the subscriptions not started are not closed by the library
+			for _, rest := range subscriptions[i:] {
+				rest.abort()
+			}
+			s.close()
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// close closes the subscription, the pending changes are discarded
+func (s *kernelSubscription) close() {
+	close(s.done)
+	// The receivers stop once they notice done, they must not block on a full channel meanwhile
+	go func() {
+		for range s.routes {
+		}
+	}()
+	go func() {
+		for range s.neighs {
+		}
+	}()
+	go func() {
+		for range s.links {
+		}
+	}()
+}
+
+// changeSet records the kernel changes received since the tables were last updated, in the order received,
+// they are applied to the kernel state the tables are derived from
+type changeSet struct {
+	updates []interface{}
+}
+
+// newChangeSet returns an empty change set
+func newChangeSet() *changeSet {
+	return &changeSet{}
+}
+
+// empty checks no change has been recorded
+func (c *changeSet) empty() bool {
+	return len(c.updates) == 0
+}
+
+// addRoute records the change of the route
+func (c *changeSet) addRoute(u vn.RouteUpdate) {
+	c.updates = append(c.updates, u)
+}
+
+// addNeigh records the change of the neighbor, or of the fdb for the bridge entries
+func (c *changeSet) addNeigh(u utils.NeighUpdate) {
+	c.updates = append(c.updates, u)
+}
+
+// addLink records the change of the link, the links change the vrf and the type of the other entries
+func (c *changeSet) addLink(u vn.LinkUpdate) {
+	c.updates = append(c.updates, u)
+}
+
+// followNetlink updates the tables on the kernel changes and resyncs fully every resync interval
+// until stop is closed, it polls the kernel while it cannot subscribe to the changes
+func followNetlink(stop <-chan struct{}, resyncInterval time.Duration) {
+	ticker := time.NewTicker(resyncInterval)
+	defer ticker.Stop()
+	var sub *kernelSubscription
+	defer func() {
+		if sub != nil {
+			sub.close()
+		}
+	}()
+	changes := newChangeSet()
+	var settle <-chan time.Time
+	for {
+		if sub == nil {
+			var err error
+			sub, err = subscribe()
+			if err != nil {
+				logger.Warnw("netlink: Polling, failed to subscribe to the kernel changes", "pollInterval", pollInterval.Load(), "error", err)
+				watcher.resync()
+				select {
+				case <-stop:
+					return
+				case <-time.After(time.Duration(pollInterval.Load()) * time.Second):
+				}
+				continue
+			}
+			// The changes missed while not subscribed are caught up by a full resync
+			watcher.resync()
+			changes = newChangeSet()
+			settle = nil
+		}
+		lost := false
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			watcher.resync()
+			changes = newChangeSet()
+			settle = nil
+		case <-settle:
+			settle = nil
+			watcher.resyncChanges(changes)
+			changes = newChangeSet()
+		case u, ok := <-sub.routes:
+			if lost = !ok; ok {
+				metrics.NetlinkKernelEvents.WithLabelValues("route").Inc()
+				changes.addRoute(u)
+			}
+		case u, ok := <-sub.neighs:
+			if lost = !ok; ok {
+				metrics.NetlinkKernelEvents.WithLabelValues("neighbor").Inc()
+				changes.addNeigh(u)
+			}
+		case u, ok := <-sub.links:
+			if lost = !ok; ok {
+				metrics.NetlinkKernelEvents.WithLabelValues("link").Inc()
+				changes.addLink(u)
+			}
+		}
+		if lost {
+			logger.Warnw("netlink: Lost the kernel subscription, subscribing again")
+			sub.close()
+			sub = nil
+			continue
+		}
+		if settle == nil && !changes.empty() {
+			settle = time.After(eventSettleTime)
+		}
+	}
+}
@@ -0,0 +1,328 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package netlink handles the netlink related functionality
+package netlink
+
+import (
+	"context"
+	"io"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	vn "github.com/vishvananda/netlink"
+	"github.com/vishvananda/netlink/nl"
+	"golang.org/x/sys/unix"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/logging"
+	eb "github.com/opiproject/opi-evpn-bridge/pkg/netlink/eventbus"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils/mocks"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestResyncChanges(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chdir(wd) }()
+
+	createVrfs(t, map[string]uint32{"GRD": 254, "blue": 1001})
+	routes := benchmarkRoutes(1001, 11)
+	added, existing := routes[10], routes[0]
+	higherMetric := existing
+	higherMetric.Priority = 10
+	down := &vn.Dummy{LinkAttrs: vn.LinkAttrs{Index: 1, Name: "lo"}}
+
+	tests := map[string]struct {
+		updates []interface{}
+		routes  int
+		added   int
+		deleted int
+	}{
+		"route added": {
+			updates: []interface{}{vn.RouteUpdate{Type: unix.RTM_NEWROUTE, Route: added}},
+			routes:  11, added: 1,
+		},
+		"route deleted": {
+			updates: []interface{}{vn.RouteUpdate{Type: unix.RTM_DELROUTE, Route: existing}},
+			routes:  9, deleted: 1,
+		},
+		"route of higher metric": {
+			updates: []interface{}{vn.RouteUpdate{Type: unix.RTM_NEWROUTE, Route: higherMetric}},
+			routes:  10,
+		},
+		"route added and deleted": {
+			updates: []interface{}{
+				vn.RouteUpdate{Type: unix.RTM_NEWROUTE, Route: added},
+				vn.RouteUpdate{Type: unix.RTM_DELROUTE, Route: added},
+			},
+			routes: 10,
+		},
+		"table of no vrf": {
+			updates: []interface{}{vn.RouteUpdate{Type: unix.RTM_NEWROUTE, Route: benchmarkRoutes(1002, 1)[0]}},
+			routes:  10,
+		},
+		"link down": {
+			updates: []interface{}{vn.LinkUpdate{
+				IfInfomsg: nl.IfInfomsg{IfInfomsg: unix.IfInfomsg{Index: 1}}, Header: unix.NlMsghdr{Type: unix.RTM_NEWLINK}, Link: down,
+			}},
+			routes: 0, deleted: 10,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockNetlink := mocks.NewNetlink(t)
+			mockNetlink.EXPECT().ReadRoute(mock.Anything, 254).Return(nil, nil).Maybe()
+			mockNetlink.EXPECT().ReadRoute(mock.Anything, 1001).Return(routes[:10], nil).Maybe()
+			mockNetlink.EXPECT().ReadNeigh(mock.Anything, mock.Anything).Return(nil, nil).Maybe()
+			mockNetlink.EXPECT().ReadFDB(mock.Anything, mock.Anything).Return(nil, nil).Maybe()
+			ctx = context.Background()
+			nlink = mockNetlink
+			defer func(w *Watcher, bus *eb.EventBus) { watcher, EventBus = w, bus }(watcher, EventBus)
+			watcher = NewWatcher()
+			getlink()
+			watcher.resync()
+			var published []*NexthopStruct
+			for _, nh := range watcher.nexthops {
+				published = append(published, nh)
+			}
+
+			EventBus = eb.NewEventBus()
+			r := NewRecorder()
+			r.Start(EventBus)
+			watcher.resyncChanges(&changeSet{updates: tt.updates})
+			EventBus.Unsubscribe()
+			r.Wait()
+
+			if routes := watcher.Routes("//network.opiproject.org/vrfs/blue"); len(routes) != tt.routes {
+				t.Errorf("Expected %v routes, received %v", tt.routes, len(routes))
+			}
+			if count := r.Count(RouteAdded); count != tt.added {
+				t.Errorf("Expected %v routes added, received %v", tt.added, count)
+			}
+			if count := r.Count(RouteDeleted); count != tt.deleted {
+				t.Errorf("Expected %v routes deleted, received %v", tt.deleted, count)
+			}
+			if count := r.Count(RouteUpdated); count != 0 {
+				t.Errorf("Expected no route updated, received %v", count)
+			}
+			// The nexthops published are copied before being changed
+			for _, nh := range published {
+				if len(nh.RouteRefs) != 10 {
+					t.Errorf("Expected the published nexthop %v to keep its 10 routes, received %v", nh.Key, len(nh.RouteRefs))
+				}
+			}
+			for _, nh := range watcher.Nexthops("//network.opiproject.org/vrfs/blue") {
+				if len(nh.RouteRefs) != tt.routes {
+					t.Errorf("Expected nexthop %v to refer to %v routes, received %v", nh.Key, tt.routes, len(nh.RouteRefs))
+				}
+			}
+		})
+	}
+}
+
+// benchmarkRoutes returns count host routes of the table on the loopback
+func benchmarkRoutes(table, count int) []vn.Route {
+	kernelRoutes := make([]vn.Route, 0, count)
+	for i := 0; i < count; i++ {
+		dst := &net.IPNet{IP: net.IPv4(10, byte(i>>16), byte(i>>8), byte(i)), Mask: net.CIDRMask(32, 32)}
+		kernelRoutes = append(kernelRoutes, vn.Route{
+			Dst: dst, LinkIndex: 1, Family: vn.FAMILY_V4, Table: table, Type: unix.RTN_UNICAST, Protocol: unix.RTPROT_STATIC, Scope: unix.RT_SCOPE_UNIVERSE,
+		})
+	}
+	return kernelRoutes
+}
+
+// BenchmarkResync compares the full resync of the polling watcher to the update of the tables
+// for a route added and deleted, with 100k routes in the blue vrf and the changes in the green vrf
+//
+//	go test ./pkg/netlink -run NONE -bench Resync -benchtime 5x
+func BenchmarkResync(b *testing.B) {
+	wd, err := os.Getwd()
+	if err != nil {
+		b.Fatal(err)
+	}
+	if err := os.Chdir(b.TempDir()); err != nil {
+		b.Fatal(err)
+	}
+	defer func() { _ = os.Chdir(wd) }()
+
+	createVrfs(b, map[string]uint32{"GRD": 254, "blue": 1001, "green": 1002})
+	mockNetlink := mocks.NewNetlink(b)
+	mockNetlink.EXPECT().ReadRoute(mock.Anything, 254).Return(benchmarkRoutes(254, 10), nil).Maybe()
+	mockNetlink.EXPECT().ReadRoute(mock.Anything, 1001).Return(benchmarkRoutes(1001, 100000), nil).Maybe()
+	mockNetlink.EXPECT().ReadRoute(mock.Anything, 1002).Return(benchmarkRoutes(1002, 10), nil).Maybe()
+	mockNetlink.EXPECT().ReadNeigh(mock.Anything, mock.Anything).Return(nil, nil).Maybe()
+	mockNetlink.EXPECT().ReadFDB(mock.Anything, mock.Anything).Return(nil, nil).Maybe()
+	ctx = context.Background()
+	nlink = mockNetlink
+	defer func(w *Watcher) { watcher = w }(watcher)
+	watcher = NewWatcher()
+	getlink()
+
+	watcher.resync()
+	if routes := watcher.Routes(""); len(routes) < 100000 {
+		b.Fatalf("Expected the routes of the blue vrf, received %v routes", len(routes))
+	}
+	b.Run("full", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			watcher.resync()
+		}
+	})
+	route := benchmarkRoutes(1002, 11)[10]
+	b.Run("vrf", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			watcher.resyncChanges(&changeSet{updates: []interface{}{vn.RouteUpdate{Type: unix.RTM_NEWROUTE, Route: route}}})
+			watcher.resyncChanges(&changeSet{updates: []interface{}{vn.RouteUpdate{Type: unix.RTM_DELROUTE, Route: route}}})
+		}
+	})
+}
+
+// BenchmarkFollowNetlink measures the time and the CPU from a kernel change to the event published
+// by the watcher following the kernel in a network namespace, with 100k routes in the table of the blue vrf.
+// The link is a port of the GRD, no vrf device is needed. An operation is a change and its revert,
+// the time includes the settle time of the changes.
+//
+//	go test ./pkg/netlink -run NONE -bench FollowNetlink -benchtime 20x
+func BenchmarkFollowNetlink(b *testing.B) {
+	if !runInNetns(b) {
+		return
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		b.Fatal(err)
+	}
+	if err := os.Chdir(b.TempDir()); err != nil {
+		b.Fatal(err)
+	}
+	defer func() { _ = os.Chdir(wd) }()
+	// The records are encoded but not written
+	logging.SetOutput(io.Discard)
+
+	lo, err := vn.LinkByName("lo")
+	if err != nil {
+		b.Fatal(err)
+	}
+	if err := vn.LinkSetUp(lo); err != nil {
+		b.Fatal(err)
+	}
+	port := &vn.Veth{LinkAttrs: vn.LinkAttrs{Name: "bench0"}, PeerName: "bench1"}
+	if err := vn.LinkAdd(port); err != nil {
+		b.Skipf("cannot create link %v: %v", port.Name, err)
+	}
+	addr, _ := vn.ParseAddr("10.99.0.1/24")
+	if err := vn.AddrAdd(port, addr); err != nil {
+		b.Fatal(err)
+	}
+	for _, name := range []string{port.Name, port.PeerName} {
+		if err := vn.LinkSetUp(&vn.Device{LinkAttrs: vn.LinkAttrs{Name: name}}); err != nil {
+			b.Fatal(err)
+		}
+	}
+	routes := benchmarkRoutes(1001, 100001)
+	for i := range routes[:100000] {
+		if err := vn.RouteAdd(&routes[i]); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	createVrfs(b, map[string]uint32{"GRD": 254, "blue": 1001})
+	ctx = context.Background()
+	nlink = utils.NewNetlinkWrapperWithArgs(false)
+	watcher = NewWatcher()
+	getlink()
+	EventBus = eb.NewEventBus()
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		followNetlink(stop, time.Hour)
+	}()
+	deadline := time.Now().Add(time.Minute)
+	for len(watcher.Routes("//network.opiproject.org/vrfs/blue")) < 100000 {
+		if time.Now().After(deadline) {
+			b.Fatalf("The routes of the blue vrf have not been published")
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	added := EventBus.SubscribeWithOptions(RouteAdded, eb.Options{QueueSize: 200000})
+	deleted := EventBus.SubscribeWithOptions(RouteDeleted, eb.Options{QueueSize: 200000})
+
+	routeKey := func(r vn.Route) RouteKey { return RouteKey{Table: r.Table, Dst: r.Dst.String()} }
+	change := func(b *testing.B, sub *eb.Subscriber, key RouteKey, apply func() error) {
+		if err := apply(); err != nil {
+			b.Fatal(err)
+		}
+		timeout := time.After(10 * time.Second)
+		for {
+			select {
+			case event := <-sub.Ch:
+				if r, ok := event.Data.(*RouteStruct); ok && r.Key == key {
+					return
+				}
+			case <-timeout:
+				b.Fatalf("Expected an event for route %v", key)
+			}
+		}
+	}
+	run := func(b *testing.B, op func(b *testing.B)) {
+		var start, end syscall.Rusage
+		_ = syscall.Getrusage(syscall.RUSAGE_SELF, &start)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			op(b)
+		}
+		b.StopTimer()
+		_ = syscall.Getrusage(syscall.RUSAGE_SELF, &end)
+		cpu := time.Duration(end.Utime.Nano() - start.Utime.Nano() + end.Stime.Nano() - start.Stime.Nano())
+		b.ReportMetric(float64(cpu.Microseconds())/1000/float64(b.N), "cpu-ms/op")
+	}
+
+	vrfRoute, grdRoute := routes[100000], benchmarkRoutes(254, 1)[0]
+	b.Run("vrf", func(b *testing.B) {
+		run(b, func(b *testing.B) {
+			change(b, added, routeKey(vrfRoute), func() error { return vn.RouteAdd(&vrfRoute) })
+			change(b, deleted, routeKey(vrfRoute), func() error { return vn.RouteDel(&vrfRoute) })
+		})
+	})
+	b.Run("grd", func(b *testing.B) {
+		run(b, func(b *testing.B) {
+			change(b, added, routeKey(grdRoute), func() error { return vn.RouteAdd(&grdRoute) })
+			change(b, deleted, routeKey(grdRoute), func() error { return vn.RouteDel(&grdRoute) })
+		})
+	})
+	connected := RouteKey{Table: 254, Dst: "10.99.0.0/24"}
+	b.Run("link", func(b *testing.B) {
+		run(b, func(b *testing.B) {
+			change(b, deleted, connected, func() error { return vn.LinkSetDown(port) })
+			change(b, added, connected, func() error { return vn.LinkSetUp(port) })
+		})
+	})
+
+	close(stop)
+	<-done
+	b.Run("full", func(b *testing.B) {
+		run(b, func(b *testing.B) {
+			change(b, added, routeKey(vrfRoute), func() error {
+				err := vn.RouteAdd(&vrfRoute)
+				watcher.resync()
+				return err
+			})
+			change(b, deleted, routeKey(vrfRoute), func() error {
+				err := vn.RouteDel(&vrfRoute)
+				watcher.resync()
+				return err
+			})
+		})
+	})
+}
@@ -0,0 +1,590 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package netlink handles the netlink related functionality
+package netlink
+
+import (
+	"errors"
+	"net"
+	"path"
+	"time"
+
+	vn "github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/metrics"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
+)
+
+// maxLookupPrefixes is the number of GRD destinations changed above which all the lookups in the GRD are done again
+const maxLookupPrefixes = 64
+
+// vrfIndex finds the vrfs of the kernel changes
+type vrfIndex struct {
+	grd     *infradb.Vrf
+	byName  map[string]*infradb.Vrf
+	byLink  map[string]*infradb.Vrf
+	byTable map[int]*infradb.Vrf
+}
+
+// newVrfIndex indexes the vrfs of the infra db, the GRD is required
+func newVrfIndex() (*vrfIndex, error) {
+	vrfs, err := infradb.GetAllVrfs()
+	if err != nil {
+		return nil, err
+	}
+	index := &vrfIndex{
+		byName:  make(map[string]*infradb.Vrf),
+		byLink:  make(map[string]*infradb.Vrf),
+		byTable: make(map[int]*infradb.Vrf),
+	}
+	for _, v := range vrfs {
+		index.byName[v.Name] = v
+		if path.Base(v.Name) == "GRD" {
+			index.grd = v
+		} else {
+			index.byLink[path.Base(v.Name)] = v
+		}
+		if v.Metadata != nil {
+			for _, table := range v.Metadata.RoutingTable {
+				if table != nil {
+					index.byTable[int(*table)] = v
+				}
+			}
+		}
+	}
+	if index.grd == nil {
+		return nil, errors.New("the GRD is not known")
+	}
+	return index, nil
+}
+
+// derivation are the routes and neighbors to derive again
+type derivation struct {
+	// routes are the routes with their vrf, none if their table is not one of a vrf anymore
+	routes map[RouteKey]*infradb.Vrf
+	neighs map[NeighKey]bool
+}
+
+// newDerivation returns an empty derivation
+func newDerivation() derivation {
+	return derivation{routes: make(map[RouteKey]*infradb.Vrf), neighs: make(map[NeighKey]bool)}
+}
+
+// empty checks there is nothing to derive
+func (d *derivation) empty() bool {
+	return len(d.routes) == 0 && len(d.neighs) == 0
+}
+
+// update derives the entries of the tables changed by the kernel changes again, from the kernel state
+// they have been applied to. The GRD is derived first, the lookups of the other entries in the GRD
+// are done again for the GRD destinations changed.
+type update struct {
+	w     *Watcher
+	index *vrfIndex
+	// grd and vrfs are the entries of the GRD and of the other vrfs to derive again
+	grd  derivation
+	vrfs derivation
+	// links are the links changed, their nexthops are derived again
+	links map[int]bool
+	// fdb is set when the fdb is derived again
+	fdb bool
+	// changedNeighs are the neighbors changed by the last derivation of the neighbors
+	changedNeighs map[NeighKey]bool
+	// lookedUp are the GRD neighbors derived again for a change of the GRD destinations
+	lookedUp map[NeighKey]bool
+	// grdPrefixes are the GRD destinations derived again
+	grdPrefixes []*net.IPNet
+	// annotated are the nexthops created and annotated by the update
+	annotated map[*NexthopStruct]bool
+	// unlinked are the routes replaced, still referred to by the nexthops of unlinkedFrom
+	unlinked     map[*RouteStruct]bool
+	unlinkedFrom map[NexthopKey]bool
+	// neighRoutes are the neighbors by the key of their neighbor route, built when needed
+	neighRoutes map[RouteKey][]NeighKey
+	// gateways are the routes by the neighbor resolving their gateway by vrf, built when needed
+	gateways map[string]map[NeighKey][]RouteKey
+}
+
+// newUpdate returns an update of the watcher with nothing to derive
+func newUpdate(w *Watcher, index *vrfIndex) *update {
+	return &update{
+		w:             w,
+		index:         index,
+		grd:           newDerivation(),
+		vrfs:          newDerivation(),
+		links:         make(map[int]bool),
+		changedNeighs: make(map[NeighKey]bool),
+		lookedUp:      make(map[NeighKey]bool),
+		annotated:     make(map[*NexthopStruct]bool),
+		unlinked:      make(map[*RouteStruct]bool),
+		unlinkedFrom:  make(map[NexthopKey]bool),
+		gateways:      make(map[string]map[NeighKey][]RouteKey),
+	}
+}
+
+// empty checks the kernel changes have changed nothing the tables depend on
+func (d *update) empty() bool {
+	return d.grd.empty() && d.vrfs.empty() && len(d.links) == 0 && !d.fdb
+}
+
+// derivation returns the derivation of the entries of the vrf
+func (d *update) derivation(vrfName string) *derivation {
+	if vrfName == d.index.grd.Name {
+		return &d.grd
+	}
+	return &d.vrfs
+}
+
+// addRoute derives the route again
+func (d *update) addRoute(key RouteKey) {
+	v := d.index.byTable[key.Table]
+	if v == nil {
+		d.vrfs.routes[key] = nil
+		return
+	}
+	d.derivation(v.Name).routes[key] = v
+}
+
+// addNeigh derives the neighbor again
+func (d *update) addNeigh(key NeighKey) {
+	d.derivation(key.VrfName).neighs[key] = true
+}
+
+// apply applies the kernel changes to the kernel state and records the entries to derive again
+func (d *update) apply(c *changeSet) {
+	for _, u := range c.updates {
+		switch u := u.(type) {
+		case vn.RouteUpdate:
+			d.applyRoute(u)
+		case utils.NeighUpdate:
+			d.applyNeigh(u)
+		case vn.LinkUpdate:
+			d.applyLink(u)
+		}
+	}
+}
+
+// applyRoute applies the route change, the routes of the tables of no vrf are not kept
+func (d *update) applyRoute(u vn.RouteUpdate) {
+	if _, ok := d.index.byTable[u.Table]; !ok {
+		return
+	}
+	if key, changed := d.w.kernel.applyRoute(u); changed {
+		d.addRoute(key)
+	}
+}
+
+// applyNeigh applies the neighbor change to the GRD, which has all the neighbors, and to the vrf of the link,
+// or the fdb change if the link is a port of the tenant bridge
+func (d *update) applyNeigh(u utils.NeighUpdate) {
+	k := &d.w.kernel
+	if u.Family == unix.AF_BRIDGE {
+		if k.bridgePort(u.LinkIndex, tenantBridge) && k.applyFdb(u) {
+			d.fdb = true
+		}
+		return
+	}
+	if u.Family != vn.FAMILY_V4 && u.Family != vn.FAMILY_V6 {
+		return
+	}
+	vrfs := []*infradb.Vrf{d.index.grd}
+	if v, ok := d.index.byLink[k.masterName(u.LinkIndex)]; ok {
+		vrfs = append(vrfs, v)
+	}
+	for _, v := range vrfs {
+		if k.applyNeigh(u, v.Name) {
+			d.addNeigh(NeighKey{Dst: u.IP.String(), VrfName: v.Name, Dev: u.LinkIndex})
+		}
+	}
+}
+
+// applyLink applies the link change. The nexthops, routes and neighbors on the link are derived again,
+// the neighbors move with the link from a vrf to another and the fdb is derived again for the ports of the tenant bridge.
+func (d *update) applyLink(u vn.LinkUpdate) {
+	k := &d.w.kernel
+	index := int(u.Index)
+	old, known := k.links[index]
+	oldMaster := k.masterName(index)
+	wasPort := k.bridgePort(index, tenantBridge)
+	deleted := u.Header.Type == unix.RTM_DELLINK
+	if deleted {
+		d.w.deleteLinkName(index)
+		delete(k.links, index)
+	} else {
+		if u.Link == nil {
+			return
+		}
+		link := newKernelLink(u.Link)
+		d.w.setLinkName(index, link.name)
+		k.links[index] = link
+		if known && link == old {
+			return
+		}
+	}
+	d.links[index] = true
+	if deleted || !k.links[index].up {
+		for _, key := range k.dropRoutesVia(index) {
+			d.addRoute(key)
+		}
+	}
+	for _, key := range k.routesVia(index) {
+		d.addRoute(key)
+	}
+	if master := k.masterName(index); master != oldMaster {
+		var from, to string
+		if v, ok := d.index.byLink[oldMaster]; ok {
+			from = v.Name
+		}
+		if v, ok := d.index.byLink[master]; ok {
+			to = v.Name
+		}
+		for _, key := range k.moveNeighs(index, d.index.grd.Name, from, to) {
+			d.addNeigh(key)
+		}
+	}
+	for _, key := range k.linkNeighs(index, deleted) {
+		d.addNeigh(key)
+	}
+	if isPort := k.bridgePort(index, tenantBridge); wasPort || isPort {
+		if !isPort {
+			k.dropFdb(index)
+		}
+		d.fdb = true
+	}
+}
+
+// derive derives the entries changed again, in the current DB changed in place
+func (d *update) derive() {
+	for index := range d.links {
+		index := index
+		d.staleNexthops(func(nh *NexthopStruct) bool { return nh.Key.Dev == index })
+	}
+	// The neighbors of the GRD depend on its routes, which depend on its neighbors
+	for !d.grd.empty() {
+		d.deriveNeighs(&d.grd)
+		prefixes := d.deriveRoutes(&d.grd)
+		d.grdPrefixes = append(d.grdPrefixes, prefixes...)
+		d.lookupNeighs(prefixes)
+	}
+	if !d.fdb {
+		for _, l2n := range d.w.latestL2Nexthop {
+			if l2n.Type == VXLAN && d.grdLookup(l2n.Dst) {
+				d.fdb = true
+				break
+			}
+		}
+	}
+	if d.fdb {
+		d.deriveFdb()
+	}
+	// The VXLAN nexthops of the vrfs are resolved in the GRD
+	grd := d.index.grd.Name
+	d.staleNexthops(func(nh *NexthopStruct) bool {
+		vtep, _ := nh.Metadata["remote_vtep_ip"].(string)
+		return nh.Key.VrfName != grd && nh.NhType == VXLAN && d.grdLookup(net.ParseIP(vtep))
+	})
+	d.deriveNeighs(&d.vrfs)
+	d.deriveRoutes(&d.vrfs)
+}
+
+// grdLookup checks the lookup of the destination in the GRD has changed
+func (d *update) grdLookup(dst net.IP) bool {
+	return lookupChanged(d.grdPrefixes, dst)
+}
+
+// lookupChanged checks the lookup of the destination depends on one of the prefixes changed,
+// all the lookups are taken as changed for many prefixes
+func lookupChanged(prefixes []*net.IPNet, dst net.IP) bool {
+	if len(prefixes) == 0 || dst == nil {
+		return false
+	}
+	if len(prefixes) > maxLookupPrefixes {
+		return true
+	}
+	for _, prefix := range prefixes {
+		if prefix.Contains(dst) {
+			return true
+		}
+	}
+	return false
+}
+
+// lookupNeighs derives again the GRD neighbors on the physical ports resolved by a lookup in the GRD
+// of a destination derived again, once per update for them not to depend on each other forever
+func (d *update) lookupNeighs(prefixes []*net.IPNet) {
+	if len(prefixes) == 0 {
+		return
+	}
+	for key, n := range d.w.latestNeighbors {
+		if key.VrfName != d.index.grd.Name || n.Protocol == zebraStr || d.lookedUp[key] {
+			continue
+		}
+		if _, ok := phyPorts[d.w.LinkName(key.Dev)]; ok && lookupChanged(prefixes, n.Neigh0.IP) {
+			d.lookedUp[key] = true
+			d.addNeigh(key)
+		}
+	}
+}
+
+// deriveNeighs derives the neighbors again, the routes of the neighbors changed are derived again
+func (d *update) deriveNeighs(c *derivation) {
+	for key := range c.neighs {
+		d.deriveNeigh(key)
+	}
+	c.neighs = make(map[NeighKey]bool)
+	d.neighborsChanged()
+}
+
+// deriveNeigh derives the neighbor again from the kernel state
+func (d *update) deriveNeigh(key NeighKey) {
+	d.w.replaceNeighbor(key)
+	old, had := d.w.latestNeighbors[key]
+	delete(d.w.latestNeighbors, key)
+	if n, ok := d.w.kernel.neighs[key.VrfName][NeighKey{Dst: key.Dst, Dev: key.Dev}]; ok {
+		addNeigh(cmdProcessNb([]utils.Neigh{n}, key.VrfName))
+	}
+	n, has := d.w.latestNeighbors[key]
+	if had != has || (has && !n.deepEqual(&old)) {
+		d.changedNeighs[key] = true
+	}
+}
+
+// neighborsChanged derives again the nexthops resolved by the neighbors changed, the routes with a gateway
+// resolved by them and their neighbor routes
+func (d *update) neighborsChanged() {
+	if len(d.changedNeighs) == 0 {
+		return
+	}
+	changed := d.changedNeighs
+	d.changedNeighs = make(map[NeighKey]bool)
+	d.neighRoutes = nil
+	d.staleNexthops(func(nh *NexthopStruct) bool {
+		if changed[NeighKey{Dst: nh.Key.Dst, VrfName: nh.Key.VrfName, Dev: nh.Key.Dev}] {
+			return true
+		}
+		// The VXLAN nexthops are resolved by the neighbor of the remote VTEP on the bridge of the vrf
+		if vtep, ok := nh.Metadata["remote_vtep_ip"].(string); ok && nh.NhType == VXLAN {
+			for key := range changed {
+				if key.VrfName == nh.Key.VrfName && key.Dst == vtep {
+					return true
+				}
+			}
+		}
+		return false
+	})
+	for key := range changed {
+		v, ok := d.index.byName[key.VrfName]
+		if !ok {
+			continue
+		}
+		for _, routeKey := range d.gatewayRoutes(v)[key] {
+			d.addRoute(routeKey)
+		}
+		if v.Metadata != nil && len(v.Metadata.RoutingTable) != 0 && v.Metadata.RoutingTable[0] != nil {
+			d.addRoute(RouteKey{Table: int(*v.Metadata.RoutingTable[0]), Dst: parseRouteDst(key.Dst).String()})
+		}
+	}
+}
+
+// gatewayRoutes returns the routes of the tables of the vrf by the neighbor resolving their gateway
+func (d *update) gatewayRoutes(v *infradb.Vrf) map[NeighKey][]RouteKey {
+	gateways, ok := d.gateways[v.Name]
+	if !ok {
+		tables := make(map[int]string)
+		for _, table := range v.Metadata.RoutingTable {
+			if table != nil {
+				tables[int(*table)] = v.Name
+			}
+		}
+		gateways = d.w.kernel.gateways(tables)
+		d.gateways[v.Name] = gateways
+	}
+	return gateways
+}
+
+// staleNexthops deletes the matching nexthops from the latest DB, their routes are derived again
+func (d *update) staleNexthops(match func(nh *NexthopStruct) bool) {
+	for key, nh := range d.w.latestNexthop {
+		if !match(nh) {
+			continue
+		}
+		for _, r := range nh.RouteRefs {
+			d.addRoute(r.Key)
+		}
+		d.w.replaceNexthop(key)
+		delete(d.w.latestNexthop, key)
+	}
+}
+
+// deriveRoutes derives the routes again and returns their destinations
+func (d *update) deriveRoutes(c *derivation) []*net.IPNet {
+	prefixes := make([]*net.IPNet, 0, len(c.routes))
+	for key, v := range c.routes {
+		d.deriveRoute(key, v)
+		if _, prefix, err := net.ParseCIDR(key.Dst); err == nil {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	c.routes = make(map[RouteKey]*infradb.Vrf)
+	d.compactNexthops()
+	return prefixes
+}
+
+// deriveRoute derives the route again from the kernel state, the route of lowest metric of the kernel
+// or else the neighbor route, as the full resyncs do
+func (d *update) deriveRoute(key RouteKey, v *infradb.Vrf) {
+	d.w.replaceRoute(key)
+	if old, ok := d.w.latestRoutes[key]; ok {
+		d.unlinked[old] = true
+		for _, nh := range old.Nexthops {
+			d.unlinkedFrom[nh.Key] = true
+		}
+		delete(d.w.latestRoutes, key)
+	}
+	if v == nil {
+		return
+	}
+	if r, ok := d.w.kernel.route(key); ok {
+		for _, route := range cmdProcessRt(v, routeCmdInfos([]vn.Route{r}), key.Table).RS {
+			route.addRoute()
+		}
+	}
+	if _, ok := d.w.latestRoutes[key]; !ok {
+		for _, neighKey := range d.neighborRouteKeys()[key] {
+			n := d.w.latestNeighbors[neighKey]
+			if n0, ok := neighborRoute(&n); ok {
+				for _, route := range ParseRoute(v, []RouteCmdInfo{n0}, 0).RS {
+					route.addRoute()
+				}
+			}
+		}
+	}
+	route, ok := d.w.latestRoutes[key]
+	if !ok {
+		return
+	}
+	// The nexthops created for the route are annotated and filtered as the full resyncs do
+	for _, nh := range route.Nexthops {
+		if d.w.currentNexthop(nh.Key) == nh || d.w.copiedNexthops[nh.Key] == nh || d.annotated[nh] {
+			continue
+		}
+		nh.annotate()
+		d.annotated[nh] = true
+		if !nh.installFilterNH() && d.w.latestNexthop[nh.Key] == nh {
+			d.w.replaceNexthop(nh.Key)
+			delete(d.w.latestNexthop, nh.Key)
+		}
+	}
+	route.annotate()
+	if !route.installFilterRoute() {
+		delete(d.w.latestRoutes, key)
+	}
+}
+
+// neighborRouteKeys returns the neighbors of the latest DB by the key of their neighbor route
+func (d *update) neighborRouteKeys() map[RouteKey][]NeighKey {
+	if d.neighRoutes == nil {
+		d.neighRoutes = make(map[RouteKey][]NeighKey)
+		for key, n := range d.w.latestNeighbors {
+			if n0, ok := neighborRoute(&n); ok {
+				routeKey := RouteKey{Table: n0.Table, Dst: parseRouteDst(n0.Dst).String()}
+				d.neighRoutes[routeKey] = append(d.neighRoutes[routeKey], key)
+			}
+		}
+	}
+	return d.neighRoutes
+}
+
+// compactNexthops removes the routes replaced from the nexthops referring to them,
+// the nexthops referred to by no route anymore are deleted
+func (d *update) compactNexthops() {
+	for key := range d.unlinkedFrom {
+		nh, ok := d.w.latestNexthop[key]
+		if !ok {
+			continue
+		}
+		unlinked := false
+		for _, r := range nh.RouteRefs {
+			if d.unlinked[r] {
+				unlinked = true
+				break
+			}
+		}
+		if !unlinked {
+			continue
+		}
+		nh = d.w.ownNexthop(nh)
+		refs := nh.RouteRefs[:0]
+		for _, r := range nh.RouteRefs {
+			if !d.unlinked[r] {
+				refs = append(refs, r)
+			}
+		}
+		nh.RouteRefs = refs
+		if len(refs) == 0 {
+			d.w.replaceNexthop(key)
+			delete(d.w.latestNexthop, key)
+		}
+	}
+	d.unlinked = make(map[*RouteStruct]bool)
+	d.unlinkedFrom = make(map[NexthopKey]bool)
+}
+
+// deriveFdb derives the fdb and the l2 nexthops again, the neighbors learnt by zebra refer to them
+func (d *update) deriveFdb() {
+	d.w.latestFDB = make(map[FdbKey]*FdbEntryStruct)
+	d.w.latestL2Nexthop = make(map[L2NexthopKey]*L2NexthopStruct)
+	for _, m := range parseFdbEntries(d.w.kernel.fdbEntries()) {
+		m.addFdbEntry()
+	}
+	annotateMap(d.w.latestFDB)
+	annotateMap(d.w.latestL2Nexthop)
+	filterMap(d.w.latestFDB, func(f *FdbEntryStruct) bool { return f.installFilterFDB() })
+	filterMap(d.w.latestL2Nexthop, func(l *L2NexthopStruct) bool { return l.installFilterL2N() })
+	for key, n := range d.w.latestNeighbors {
+		if n.Protocol == zebraStr && key.VrfName != d.index.grd.Name {
+			d.addNeigh(key)
+		}
+	}
+}
+
+// resyncChanges applies the kernel changes to the kernel state and updates the tables for them
+func (w *Watcher) resyncChanges(c *changeSet) {
+	index, err := newVrfIndex()
+	if err != nil {
+		w.resync()
+		return
+	}
+	w.resyncMtx.Lock()
+	defer w.resyncMtx.Unlock()
+	start := time.Now()
+
+	d := newUpdate(w, index)
+	d.apply(c)
+	if d.empty() {
+		return
+	}
+	defer func() { metrics.NetlinkPollDuration.Observe(metrics.Since(start)) }()
+	w.mtx.Lock()
+	w.startUpdate()
+	d.derive()
+	w.mtx.Unlock()
+	d.commit()
+}
+
+// commit publishes the latest DB and notifies the changes of the entries replaced by the update
+func (d *update) commit() {
+	replacedRoutes, replacedNexthops := d.w.replacedRoutes, d.w.replacedNexthops
+	fdb, l2Nexthops := d.w.fDB, d.w.l2Nexthops
+	d.w.publishDB()
+	notifyReplacedChanges[RouteKey, *RouteStruct](d.w.routes, replacedRoutes, ROUTE, routeOperations)
+	notifyReplacedChanges[NexthopKey, *NexthopStruct](d.w.nexthops, replacedNexthops, NEXTHOP, nexthopOperations)
+	if d.fdb {
+		notifyDBCompChanges[FdbKey, *FdbEntryStruct](d.w.fDB, fdb, FDB, fdbOperations)
+		notifyDBCompChanges[L2NexthopKey, *L2NexthopStruct](d.w.l2Nexthops, l2Nexthops, L2NEXTHOP, l2NexthopOperations)
+	}
+}
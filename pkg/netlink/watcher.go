@@ -0,0 +1,343 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package netlink handles the netlink related functionality
+package netlink
+
+import (
+	"net"
+	"sync"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
+)
+
+// Watcher holds the databases of the netlink watcher.
+// The resyncs run one at a time under resyncMtx, they build the latest databases, which only they touch,
+// and swap them for the current ones under mtx. The current databases are read under mtx by the accessors,
+// from any goroutine, and without it by the resyncs, their only writer. The link names are under namesMtx.
+// The updates of the kernel changes change the current databases in place under mtx instead, and record
+// the entries they replace to notify the changes. They copy the current entries before changing them,
+// the published entries are never changed.
+type Watcher struct {
+	mtx sync.RWMutex
+	// routes, nexthops, fDB, l2Nexthops and neighbors are the current databases, published by the last resync
+	routes     map[RouteKey]*RouteStruct
+	nexthops   map[NexthopKey]*NexthopStruct
+	fDB        map[FdbKey]*FdbEntryStruct
+	l2Nexthops map[L2NexthopKey]*L2NexthopStruct
+	neighbors  map[NeighKey]NeighStruct
+
+	namesMtx sync.RWMutex
+	// nameIndex are the names of the links by index
+	nameIndex map[int]string
+
+	resyncMtx sync.Mutex
+	// latestRoutes, latestNexthop, latestFDB, latestL2Nexthop and latestNeighbors are the databases being built
+	latestRoutes    map[RouteKey]*RouteStruct
+	latestNexthop   map[NexthopKey]*NexthopStruct
+	latestFDB       map[FdbKey]*FdbEntryStruct
+	latestL2Nexthop map[L2NexthopKey]*L2NexthopStruct
+	latestNeighbors map[NeighKey]NeighStruct
+	// linkIndexes are the indexes of the links looked up by name during the current resync
+	linkIndexes map[string]int
+	// copiedNexthops are the current nexthops copied to the latest databases to be changed by the current resync
+	copiedNexthops map[NexthopKey]*NexthopStruct
+	// replacedRoutes, replacedNexthops and replacedNeighbors are the current entries of the keys changed
+	// by the current update, nil for the keys added. They are nil but during the updates
+	replacedRoutes    map[RouteKey]*RouteStruct
+	replacedNexthops  map[NexthopKey]*NexthopStruct
+	replacedNeighbors map[NeighKey]*NeighStruct
+	// kernel is the state of the kernel the databases are derived from
+	kernel kernelState
+	// nhIDPool and l2NhIDPool assign the nexthop and l2 nexthop ids, persisted once the watcher is initialized.
+	// The ids of the deleted nexthops are released at the end of each resync
+	nhIDPool   utils.IDPool
+	l2NhIDPool utils.IDPool
+}
+
+// NewWatcher creates a watcher with empty databases
+func NewWatcher() *Watcher {
+	w := &Watcher{
+		routes:     make(map[RouteKey]*RouteStruct),
+		nexthops:   make(map[NexthopKey]*NexthopStruct),
+		fDB:        make(map[FdbKey]*FdbEntryStruct),
+		l2Nexthops: make(map[L2NexthopKey]*L2NexthopStruct),
+		neighbors:  make(map[NeighKey]NeighStruct),
+		nameIndex:  make(map[int]string),
+		kernel:     newKernelState(),
+	}
+	w.nhIDPool, _ = utils.IDPoolInit("NHID", nexthopIDMin, nexthopIDMax)
+	w.l2NhIDPool, _ = utils.IDPoolInit("L2NHID", nexthopIDMin, nexthopIDMax)
+	w.deleteLatestDB()
+	return w
+}
+
+// watcher is the watcher started by Initialize
+var watcher = NewWatcher()
+
+// DefaultWatcher returns the watcher started by Initialize
+func DefaultWatcher() *Watcher {
+	return watcher
+}
+
+// deleteLatestDB deletes the latest db snap
+func (w *Watcher) deleteLatestDB() {
+	w.latestRoutes = make(map[RouteKey]*RouteStruct)
+	w.latestNeighbors = make(map[NeighKey]NeighStruct)
+	w.latestNexthop = make(map[NexthopKey]*NexthopStruct)
+	w.latestFDB = make(map[FdbKey]*FdbEntryStruct)
+	w.latestL2Nexthop = make(map[L2NexthopKey]*L2NexthopStruct)
+	w.linkIndexes = make(map[string]int)
+	w.copiedNexthops = make(map[NexthopKey]*NexthopStruct)
+	w.replacedRoutes = nil
+	w.replacedNexthops = nil
+	w.replacedNeighbors = nil
+}
+
+// startUpdate starts an update of the current databases in place, they are the latest ones until published.
+// The fdb and the l2 nexthops are built again if they change
+func (w *Watcher) startUpdate() {
+	w.latestRoutes = w.routes
+	w.latestNexthop = w.nexthops
+	w.latestFDB = w.fDB
+	w.latestL2Nexthop = w.l2Nexthops
+	w.latestNeighbors = w.neighbors
+	w.replacedRoutes = make(map[RouteKey]*RouteStruct)
+	w.replacedNexthops = make(map[NexthopKey]*NexthopStruct)
+	w.replacedNeighbors = make(map[NeighKey]*NeighStruct)
+}
+
+// replaceRoute records the current route of the key before the update changes it
+func (w *Watcher) replaceRoute(key RouteKey) {
+	if _, ok := w.replacedRoutes[key]; !ok && w.replacedRoutes != nil {
+		w.replacedRoutes[key] = w.latestRoutes[key]
+	}
+}
+
+// replaceNexthop records the current nexthop of the key before the update changes it
+func (w *Watcher) replaceNexthop(key NexthopKey) {
+	if _, ok := w.replacedNexthops[key]; !ok && w.replacedNexthops != nil {
+		w.replacedNexthops[key] = w.latestNexthop[key]
+	}
+}
+
+// replaceNeighbor records the current neighbor of the key before the update changes it
+func (w *Watcher) replaceNeighbor(key NeighKey) {
+	if _, ok := w.replacedNeighbors[key]; !ok && w.replacedNeighbors != nil {
+		var n *NeighStruct
+		if old, ok := w.latestNeighbors[key]; ok {
+			n = &old
+		}
+		w.replacedNeighbors[key] = n
+	}
+}
+
+// currentNexthop returns the current nexthop of the key as published by the last resync
+func (w *Watcher) currentNexthop(key NexthopKey) *NexthopStruct {
+	if nh, ok := w.replacedNexthops[key]; ok {
+		return nh
+	}
+	return w.nexthops[key]
+}
+
+// ownNexthop returns the nexthop of the latest databases to change, a current nexthop is copied
+// to the latest databases first
+func (w *Watcher) ownNexthop(nexthop *NexthopStruct) *NexthopStruct {
+	if w.currentNexthop(nexthop.Key) != nexthop {
+		return nexthop
+	}
+	c := *nexthop
+	c.RouteRefs = append([]*RouteStruct(nil), nexthop.RouteRefs...)
+	w.replaceNexthop(c.Key)
+	w.latestNexthop[c.Key] = &c
+	w.copiedNexthops[c.Key] = &c
+	return &c
+}
+
+// publishLatestDB swaps the latest databases for the current ones
+func (w *Watcher) publishLatestDB() {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+
+	w.routes = w.latestRoutes
+	w.nexthops = w.latestNexthop
+	w.fDB = w.latestFDB
+	w.l2Nexthops = w.latestL2Nexthop
+	w.neighbors = w.latestNeighbors
+}
+
+// releaseNexthopIDs releases the ids of the nexthops and l2 nexthops that are not in the latest databases,
+// and persists the ids assigned and released by the resync
+func (w *Watcher) releaseNexthopIDs() {
+	nexthops := w.nexthops
+	if w.replacedNexthops != nil {
+		nexthops = w.replacedNexthops
+	}
+	for key, nh := range nexthops {
+		if _, ok := w.latestNexthop[key]; !ok && nh != nil {
+			w.nhIDPool.ReleaseID(nexthopIDKey(key))
+		}
+	}
+	for key := range w.l2Nexthops {
+		if _, ok := w.latestL2Nexthop[key]; !ok {
+			w.l2NhIDPool.ReleaseID(l2NexthopIDKey(key))
+		}
+	}
+	for _, pool := range []*utils.IDPool{&w.nhIDPool, &w.l2NhIDPool} {
+		if err := pool.Flush(); err != nil {
+			logger.Errorw("netlink: Failed to persist the nexthop ids", "error", err)
+		}
+	}
+}
+
+// Tables returns a copy of the current databases
+func (w *Watcher) Tables() Tables {
+	w.mtx.RLock()
+	defer w.mtx.RUnlock()
+
+	return Tables{
+		Routes:     copyMap(w.routes),
+		Nexthops:   copyMap(w.nexthops),
+		FDB:        copyMap(w.fDB),
+		L2Nexthops: copyMap(w.l2Nexthops),
+	}
+}
+
+// Routes returns copies of the current routes of the vrf, of all the vrfs if vrf is empty
+func (w *Watcher) Routes(vrf string) []*RouteStruct {
+	w.mtx.RLock()
+	defer w.mtx.RUnlock()
+
+	var routes []*RouteStruct
+	for _, r := range w.routes {
+		if vrf == "" || (r.Vrf != nil && r.Vrf.Name == vrf) {
+			routes = append(routes, r.copy())
+		}
+	}
+	return routes
+}
+
+// Nexthops returns copies of the current nexthops of the vrf, of all the vrfs if vrf is empty
+func (w *Watcher) Nexthops(vrf string) []*NexthopStruct {
+	w.mtx.RLock()
+	defer w.mtx.RUnlock()
+
+	var nexthops []*NexthopStruct
+	for k, nh := range w.nexthops {
+		if vrf == "" || k.VrfName == vrf {
+			nexthops = append(nexthops, nh.copy())
+		}
+	}
+	return nexthops
+}
+
+// FDB returns copies of the current fdb entries
+func (w *Watcher) FDB() []*FdbEntryStruct {
+	w.mtx.RLock()
+	defer w.mtx.RUnlock()
+
+	fdb := make([]*FdbEntryStruct, 0, len(w.fDB))
+	for _, f := range w.fDB {
+		fdb = append(fdb, f.copy())
+	}
+	return fdb
+}
+
+// L2Nexthops returns copies of the current l2 nexthops
+func (w *Watcher) L2Nexthops() []*L2NexthopStruct {
+	w.mtx.RLock()
+	defer w.mtx.RUnlock()
+
+	l2Nexthops := make([]*L2NexthopStruct, 0, len(w.l2Nexthops))
+	for _, l2n := range w.l2Nexthops {
+		l2Nexthops = append(l2Nexthops, l2n.copy())
+	}
+	return l2Nexthops
+}
+
+// copy returns a copy of the route for the readers, the nexthops it refers to are the current ones
+func (route *RouteStruct) copy() *RouteStruct {
+	c := *route
+	c.Nexthops = append([]*NexthopStruct(nil), route.Nexthops...)
+	c.Metadata = copyMetadata(route.Metadata)
+	return &c
+}
+
+// copy returns a copy of the nexthop for the readers, the routes it refers to are the current ones
+func (nexthop *NexthopStruct) copy() *NexthopStruct {
+	c := *nexthop
+	c.RouteRefs = append([]*RouteStruct(nil), nexthop.RouteRefs...)
+	c.Hashes = append([]int(nil), nexthop.Hashes...)
+	c.Metadata = copyMetadata(nexthop.Metadata)
+	if nexthop.Neighbor != nil {
+		neighbor := *nexthop.Neighbor
+		c.Neighbor = &neighbor
+	}
+	return &c
+}
+
+// copy returns a copy of the fdb entry for the readers, the l2 nexthop it refers to is the current one
+func (fdb *FdbEntryStruct) copy() *FdbEntryStruct {
+	c := *fdb
+	c.Metadata = copyMetadata(fdb.Metadata)
+	return &c
+}
+
+// copy returns a copy of the l2 nexthop for the readers, the fdb entries it refers to are the current ones
+func (l2n *L2NexthopStruct) copy() *L2NexthopStruct {
+	c := *l2n
+	c.Dst = append(net.IP(nil), l2n.Dst...)
+	c.FdbRefs = append([]*FdbEntryStruct(nil), l2n.FdbRefs...)
+	c.Metadata = copyMetadata(l2n.Metadata)
+	return &c
+}
+
+// copyMetadata returns a copy of the metadata, nil if there is none
+func copyMetadata(metadata map[interface{}]interface{}) map[interface{}]interface{} {
+	if metadata == nil {
+		return nil
+	}
+	return deepCopyMetadata(metadata)
+}
+
+// LinkName returns the name of the link as last known by the watcher
+func (w *Watcher) LinkName(index int) string {
+	w.namesMtx.RLock()
+	defer w.namesMtx.RUnlock()
+
+	return w.nameIndex[index]
+}
+
+// lookupLinkName returns the name of the link and whether it is known
+func (w *Watcher) lookupLinkName(index int) (string, bool) {
+	w.namesMtx.RLock()
+	defer w.namesMtx.RUnlock()
+
+	name, ok := w.nameIndex[index]
+	return name, ok
+}
+
+// setLinkName records the name of the link
+func (w *Watcher) setLinkName(index int, name string) {
+	w.namesMtx.Lock()
+	defer w.namesMtx.Unlock()
+
+	w.nameIndex[index] = name
+}
+
+// deleteLinkName forgets the name of the link
+func (w *Watcher) deleteLinkName(index int) {
+	w.namesMtx.Lock()
+	defer w.namesMtx.Unlock()
+
+	delete(w.nameIndex, index)
+}
+
+// setLinkNames replaces the names of all the links
+func (w *Watcher) setLinkNames(names map[int]string) {
+	w.namesMtx.Lock()
+	defer w.namesMtx.Unlock()
+
+	w.nameIndex = names
+}
@@ -0,0 +1,341 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package operations implements the long-running operations of the intent API
+package operations
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/longrunning/autogen/longrunningpb"
+	"github.com/google/uuid"
+	"go.einride.tech/aip/resourcename"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/taskmanager"
+	"github.com/opiproject/opi-evpn-bridge/pkg/logging"
+)
+
+// logger is the logger of the grpc subsystem
+var logger = logging.Logger(logging.Grpc).Sugar()
+
+const (
+	// MetadataKey is the gRPC metadata key that a client sets to "true" on a Create or Delete
+	// request to get an operation for it. The name of the operation is returned in the
+	// response header with the same key.
+	MetadataKey = "x-opi-operation"
+
+	// operationRetention is how long a completed operation is kept
+	operationRetention = time.Hour
+
+	// maxFailures is the number of component errors after which an operation fails,
+	// the task manager retries the task after each of them and keeps retrying afterwards
+	maxFailures = 5
+)
+
+// Ops holds the operations of the intent API
+var Ops = NewManager(taskmanager.TaskMan)
+
+// ResponseFunc returns the response of an operation once the realization has completed
+type ResponseFunc func() (proto.Message, error)
+
+// Operation tracks the realization of a single Create or Delete request.
+// All the methods are safe to call on a nil Operation, which is what
+// Begin returns when the client has not asked for an operation.
+type Operation struct {
+	manager *Manager
+	object  string
+	op      *longrunningpb.Operation
+	done    chan struct{}
+	doneAt  time.Time
+
+	committed       bool
+	resourceVersion string
+	response        ResponseFunc
+	// pending holds the task results that arrive before the resource version is known
+	pending []*taskmanager.TaskResult
+}
+
+// Manager keeps the operations and completes them from the task manager results
+type Manager struct {
+	mtx      sync.Mutex
+	tasks    *taskmanager.TaskManager
+	ops      map[string]*Operation
+	byObject map[string][]*Operation
+	// retention is how long a completed operation is kept
+	retention time.Duration
+}
+
+// NewManager creates a Manager that follows the results of the task manager
+func NewManager(tasks *taskmanager.TaskManager) *Manager {
+	m := &Manager{
+		tasks:     tasks,
+		ops:       make(map[string]*Operation),
+		byObject:  make(map[string][]*Operation),
+		retention: operationRetention,
+	}
+	tasks.AddResultListener(m.onResult)
+	return m
+}
+
+// Requested checks if the client has asked for an operation
+func Requested(ctx context.Context) bool {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return false
+	}
+	values := md.Get(MetadataKey)
+	return len(values) > 0 && strings.EqualFold(strings.TrimSpace(values[0]), "true")
+}
+
+// Begin starts an operation for a request on the named object if the client has asked for one.
+// It has to be called before the object is changed so that no task result gets lost.
+func (m *Manager) Begin(ctx context.Context, object string) *Operation {
+	if !Requested(ctx) {
+		return nil
+	}
+
+	o := &Operation{
+		manager: m,
+		object:  object,
+		op: &longrunningpb.Operation{
+			Name: resourcename.Join("//network.opiproject.org/", "operations", uuid.NewString()),
+		},
+		done: make(chan struct{}),
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.prune()
+	m.ops[o.op.Name] = o
+	m.byObject[object] = append(m.byObject[object], o)
+
+	if err := grpc.SetHeader(ctx, metadata.Pairs(MetadataKey, o.op.Name)); err != nil {
+		logger.Errorw("Begin(): Failed to set the operation header", "error", err)
+	}
+	logger.Infow("Begin(): Operation has started", logging.Name(o.op.Name), "object", object)
+	return o
+}
+
+// Commit sets the resource version whose realization completes the operation.
+// An empty resource version means that the object no longer exists, which completes the operation.
+func (o *Operation) Commit(resourceVersion string, response ResponseFunc) {
+	if o == nil {
+		return
+	}
+	m := o.manager
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	o.committed = true
+	o.resourceVersion = resourceVersion
+	o.response = response
+
+	if resourceVersion == "" {
+		m.complete(o, nil)
+		return
+	}
+	for _, result := range o.pending {
+		if result.ResourceVersion == resourceVersion && !retried(result) {
+			m.complete(o, result.Err)
+			break
+		}
+	}
+	o.pending = nil
+}
+
+// Done completes the operation right away with the given response,
+// e.g. when the request did not need any realization
+func (o *Operation) Done(response proto.Message) {
+	o.Commit("", func() (proto.Message, error) {
+		return response, nil
+	})
+}
+
+// Abort fails the operation with the error of the request
+func (o *Operation) Abort(err error) {
+	if o == nil {
+		return
+	}
+	m := o.manager
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	o.committed = true
+	m.finish(o, status.Convert(err).Proto(), nil)
+}
+
+// onResult completes the operations that wait for the result of a task
+func (m *Manager) onResult(result *taskmanager.TaskResult) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	for _, o := range m.byObject[result.Name] {
+		switch {
+		case !o.committed:
+			o.pending = append(o.pending, result)
+		case o.resourceVersion == result.ResourceVersion && !retried(result):
+			m.complete(o, result.Err)
+		}
+	}
+}
+
+// retried checks if the task of the result is retried before the operation fails,
+// a component error only fails the operation once the task has failed maxFailures times
+func retried(result *taskmanager.TaskResult) bool {
+	var compErr *taskmanager.ComponentError
+	return errors.As(result.Err, &compErr) && result.Failures < maxFailures
+}
+
+// complete finishes the operation with the result of the realization.
+// The response is fetched in the background as it reads the object from the database.
+// The manager lock must be held.
+func (m *Manager) complete(o *Operation, err error) {
+	if err != nil {
+		m.finish(o, taskStatus(err).Proto(), nil)
+		return
+	}
+	m.untrack(o)
+
+	go func() {
+		var result *anypb.Any
+		var rpcStatus *spb.Status
+
+		response, err := o.response()
+		if err == nil {
+			result, err = anypb.New(response)
+		}
+		if err != nil {
+			rpcStatus = status.Convert(err).Proto()
+		}
+
+		m.mtx.Lock()
+		defer m.mtx.Unlock()
+		m.finish(o, rpcStatus, result)
+	}()
+}
+
+// finish marks the operation as done. The manager lock must be held.
+func (m *Manager) finish(o *Operation, rpcStatus *spb.Status, response *anypb.Any) {
+	if o.op.Done {
+		return
+	}
+	m.untrack(o)
+
+	o.op.Done = true
+	if rpcStatus != nil {
+		o.op.Result = &longrunningpb.Operation_Error{Error: rpcStatus}
+	} else {
+		o.op.Result = &longrunningpb.Operation_Response{Response: response}
+	}
+	o.doneAt = time.Now()
+	close(o.done)
+	logger.Debugw("finish(): Operation has completed", logging.Name(o.op.Name), "result", o.op.Result)
+}
+
+// untrack stops the delivery of task results to the operation. The manager lock must be held.
+func (m *Manager) untrack(o *Operation) {
+	ops := m.byObject[o.object]
+	for i := range ops {
+		if ops[i] == o {
+			ops = append(ops[:i], ops[i+1:]...)
+			break
+		}
+	}
+	if len(ops) == 0 {
+		delete(m.byObject, o.object)
+	} else {
+		m.byObject[o.object] = ops
+	}
+}
+
+// prune removes the operations that have completed more than the retention ago,
+// it is called whenever the operations are read or added. The manager lock must be held.
+func (m *Manager) prune() {
+	for name, o := range m.ops {
+		if o.op.Done && time.Since(o.doneAt) > m.retention {
+			delete(m.ops, name)
+		}
+	}
+}
+
+// get returns a snapshot of an operation together with its done channel
+func (m *Manager) get(name string) (*longrunningpb.Operation, <-chan struct{}, bool) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.prune()
+	o, ok := m.ops[name]
+	if !ok {
+		return nil, nil, false
+	}
+	return proto.Clone(o.op).(*longrunningpb.Operation), o.done, true
+}
+
+// list returns a snapshot of all the operations
+func (m *Manager) list() []*longrunningpb.Operation {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.prune()
+	ops := make([]*longrunningpb.Operation, 0, len(m.ops))
+	for _, o := range m.ops {
+		ops = append(ops, proto.Clone(o.op).(*longrunningpb.Operation))
+	}
+	return ops
+}
+
+// remove deletes an operation
+func (m *Manager) remove(name string) bool {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	o, ok := m.ops[name]
+	if !ok {
+		return false
+	}
+	m.untrack(o)
+	delete(m.ops, name)
+	return true
+}
+
+// cancel cancels the task of an operation and completes it with a Canceled error
+func (m *Manager) cancel(name string) bool {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	o, ok := m.ops[name]
+	if !ok {
+		return false
+	}
+	if o.op.Done {
+		return true
+	}
+	if o.committed && o.resourceVersion != "" {
+		m.tasks.CancelTask(o.object, o.resourceVersion)
+	}
+	m.finish(o, status.New(codes.Canceled, "the operation has been canceled").Proto(), nil)
+	return true
+}
+
+// taskStatus converts the error of a task result to a gRPC status
+func taskStatus(err error) *status.Status {
+	var compErr *taskmanager.ComponentError
+	switch {
+	case errors.Is(err, taskmanager.ErrTaskCanceled):
+		return status.New(codes.Canceled, err.Error())
+	case errors.Is(err, taskmanager.ErrTaskDropped):
+		return status.New(codes.Aborted, err.Error())
+	case errors.As(err, &compErr):
+		return status.New(codes.Internal, err.Error())
+	default:
+		return status.Convert(err)
+	}
+}
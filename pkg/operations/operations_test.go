@@ -0,0 +1,202 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package operations implements the long-running operations of the intent API
+package operations
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/longrunning/autogen/longrunningpb"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/taskmanager"
+)
+
+func operationContext() context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs(MetadataKey, "true"))
+}
+
+func waitOperation(t *testing.T, s *Server, name string) *longrunningpb.Operation {
+	op, err := s.WaitOperation(context.Background(), &longrunningpb.WaitOperationRequest{
+		Name:    name,
+		Timeout: durationpb.New(time.Second),
+	})
+	assert.NoError(t, err)
+	return op
+}
+
+func Test_OperationNotRequested(t *testing.T) {
+	m := NewManager(taskmanager.TaskMan)
+
+	op := m.Begin(context.Background(), "obj")
+	assert.Nil(t, op)
+
+	// all the methods are safe on a nil Operation
+	op.Commit("v1", nil)
+	op.Done(&emptypb.Empty{})
+	op.Abort(status.Error(codes.Internal, "failure"))
+	assert.Empty(t, m.list())
+}
+
+func Test_OperationResults(t *testing.T) {
+	tests := map[string]struct {
+		before  []*taskmanager.TaskResult
+		after   []*taskmanager.TaskResult
+		errCode codes.Code
+		done    bool
+	}{
+		"success": {
+			after:   []*taskmanager.TaskResult{{Name: "obj", ResourceVersion: "v1"}},
+			errCode: codes.OK,
+			done:    true,
+		},
+		"result before commit": {
+			before:  []*taskmanager.TaskResult{{Name: "obj", ResourceVersion: "v1"}},
+			errCode: codes.OK,
+			done:    true,
+		},
+		"other resource version": {
+			after: []*taskmanager.TaskResult{{Name: "obj", ResourceVersion: "v0"}},
+			done:  false,
+		},
+		"other object": {
+			after: []*taskmanager.TaskResult{{Name: "other", ResourceVersion: "v1"}},
+			done:  false,
+		},
+		"component error retried": {
+			after: []*taskmanager.TaskResult{{Name: "obj", ResourceVersion: "v1",
+				Err: &taskmanager.ComponentError{Component: "frr", Details: "failure"}, Failures: 1}},
+			done: false,
+		},
+		"success after component error": {
+			before: []*taskmanager.TaskResult{{Name: "obj", ResourceVersion: "v1",
+				Err: &taskmanager.ComponentError{Component: "frr", Details: "failure"}, Failures: 1}},
+			after:   []*taskmanager.TaskResult{{Name: "obj", ResourceVersion: "v1", Failures: 1}},
+			errCode: codes.OK,
+			done:    true,
+		},
+		"component error retries exhausted": {
+			after: []*taskmanager.TaskResult{{Name: "obj", ResourceVersion: "v1",
+				Err: &taskmanager.ComponentError{Component: "frr", Details: "failure"}, Failures: maxFailures}},
+			errCode: codes.Internal,
+			done:    true,
+		},
+		"task dropped": {
+			after:   []*taskmanager.TaskResult{{Name: "obj", ResourceVersion: "v1", Err: taskmanager.ErrTaskDropped}},
+			errCode: codes.Aborted,
+			done:    true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := NewManager(taskmanager.TaskMan)
+			s := NewServerWithManager(m)
+
+			op := m.Begin(operationContext(), "obj")
+			assert.NotNil(t, op)
+			for _, result := range tt.before {
+				m.onResult(result)
+			}
+			op.Commit("v1", func() (proto.Message, error) {
+				return wrapperspb.String("obj"), nil
+			})
+			for _, result := range tt.after {
+				m.onResult(result)
+			}
+
+			if !tt.done {
+				got, err := s.WaitOperation(context.Background(), &longrunningpb.WaitOperationRequest{
+					Name:    op.op.Name,
+					Timeout: durationpb.New(10 * time.Millisecond),
+				})
+				assert.NoError(t, err)
+				assert.False(t, got.Done)
+				return
+			}
+
+			got := waitOperation(t, s, op.op.Name)
+			assert.True(t, got.Done)
+			if tt.errCode != codes.OK {
+				assert.Equal(t, int32(tt.errCode), got.GetError().GetCode())
+				return
+			}
+			response := &wrapperspb.StringValue{}
+			assert.NoError(t, got.GetResponse().UnmarshalTo(response))
+			assert.Equal(t, "obj", response.Value)
+		})
+	}
+}
+
+func Test_OperationsServer(t *testing.T) {
+	m := NewManager(taskmanager.TaskMan)
+	s := NewServerWithManager(m)
+	ctx := context.Background()
+
+	done := m.Begin(operationContext(), "obj-a")
+	done.Done(&emptypb.Empty{})
+	pending := m.Begin(operationContext(), "obj-b")
+	pending.Commit("v1", nil)
+
+	op, err := s.GetOperation(ctx, &longrunningpb.GetOperationRequest{Name: pending.op.Name})
+	assert.NoError(t, err)
+	assert.False(t, op.Done)
+
+	_, err = s.GetOperation(ctx, &longrunningpb.GetOperationRequest{Name: "unknown"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	waitOperation(t, s, done.op.Name)
+	list, err := s.ListOperations(ctx, &longrunningpb.ListOperationsRequest{Filter: "NOT done"})
+	assert.NoError(t, err)
+	assert.Len(t, list.Operations, 1)
+	assert.Equal(t, pending.op.Name, list.Operations[0].Name)
+
+	_, err = s.CancelOperation(ctx, &longrunningpb.CancelOperationRequest{Name: pending.op.Name})
+	assert.NoError(t, err)
+	op = waitOperation(t, s, pending.op.Name)
+	assert.True(t, op.Done)
+	assert.Equal(t, int32(codes.Canceled), op.GetError().GetCode())
+
+	_, err = s.DeleteOperation(ctx, &longrunningpb.DeleteOperationRequest{Name: pending.op.Name})
+	assert.NoError(t, err)
+	_, err = s.GetOperation(ctx, &longrunningpb.GetOperationRequest{Name: pending.op.Name})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = s.WaitOperation(ctx, &longrunningpb.WaitOperationRequest{Name: "unknown"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func Test_OperationRetention(t *testing.T) {
+	m := NewManager(taskmanager.TaskMan)
+	s := NewServerWithManager(m)
+	ctx := context.Background()
+
+	done := m.Begin(operationContext(), "obj-a")
+	done.Done(&emptypb.Empty{})
+	waitOperation(t, s, done.op.Name)
+	pending := m.Begin(operationContext(), "obj-b")
+	pending.Commit("v1", nil)
+
+	m.mtx.Lock()
+	m.retention = 0
+	m.mtx.Unlock()
+
+	// the completed operation expires, the pending one is kept
+	_, err := s.GetOperation(ctx, &longrunningpb.GetOperationRequest{Name: done.op.Name})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	list, err := s.ListOperations(ctx, &longrunningpb.ListOperationsRequest{})
+	assert.NoError(t, err)
+	assert.Len(t, list.Operations, 1)
+	assert.Equal(t, pending.op.Name, list.Operations[0].Name)
+}
@@ -0,0 +1,122 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package operations implements the long-running operations of the intent API
+package operations
+
+import (
+	"context"
+	"time"
+
+	"cloud.google.com/go/longrunning/autogen/longrunningpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
+)
+
+const (
+	// defaultWaitTimeout is used when a WaitOperation request has no timeout
+	defaultWaitTimeout = 30 * time.Second
+	// maxWaitTimeout caps the timeout of a WaitOperation request
+	maxWaitTimeout = 5 * time.Minute
+)
+
+// Server represents the Server object
+type Server struct {
+	longrunningpb.UnimplementedOperationsServer
+	manager *Manager
+}
+
+// NewServer creates initialized instance of the Operations server
+func NewServer() *Server {
+	return NewServerWithManager(Ops)
+}
+
+// NewServerWithManager creates initialized instance of the Operations server for the given Manager
+func NewServerWithManager(manager *Manager) *Server {
+	return &Server{
+		manager: manager,
+	}
+}
+
+// ListOperations lists the operations
+func (s *Server) ListOperations(_ context.Context, in *longrunningpb.ListOperationsRequest) (*longrunningpb.ListOperationsResponse, error) {
+	ops, token, err := utils.ListPage(s.manager.list(), in.PageSize, in.PageToken, in.Filter, "")
+	if err != nil {
+		logger.Infow("ListOperations(): Invalid list options", "error", err)
+		return nil, err
+	}
+	return &longrunningpb.ListOperationsResponse{Operations: ops, NextPageToken: token}, nil
+}
+
+// GetOperation gets an operation
+func (s *Server) GetOperation(_ context.Context, in *longrunningpb.GetOperationRequest) (*longrunningpb.Operation, error) {
+	op, _, ok := s.manager.get(in.Name)
+	if !ok {
+		err := status.Errorf(codes.NotFound, "unable to find key %s", in.Name)
+		logger.Infow("GetOperation(): Operation not found", "error", err)
+		return nil, err
+	}
+	return op, nil
+}
+
+// DeleteOperation deletes an operation. The realization of the request is not affected.
+func (s *Server) DeleteOperation(_ context.Context, in *longrunningpb.DeleteOperationRequest) (*emptypb.Empty, error) {
+	if !s.manager.remove(in.Name) {
+		err := status.Errorf(codes.NotFound, "unable to find key %s", in.Name)
+		logger.Infow("DeleteOperation(): Operation not found", "error", err)
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// CancelOperation cancels an operation.
+// The task of the request is dropped unless a component is processing it at the moment,
+// in which case the changes of that component are kept.
+func (s *Server) CancelOperation(_ context.Context, in *longrunningpb.CancelOperationRequest) (*emptypb.Empty, error) {
+	if !s.manager.cancel(in.Name) {
+		err := status.Errorf(codes.NotFound, "unable to find key %s", in.Name)
+		logger.Infow("CancelOperation(): Operation not found", "error", err)
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// WaitOperation waits until an operation is done or the timeout expires and returns its latest state
+func (s *Server) WaitOperation(ctx context.Context, in *longrunningpb.WaitOperationRequest) (*longrunningpb.Operation, error) {
+	timeout := defaultWaitTimeout
+	if in.Timeout != nil {
+		if err := in.Timeout.CheckValid(); err != nil || in.Timeout.AsDuration() < 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid timeout %v", in.Timeout)
+		}
+		timeout = in.Timeout.AsDuration()
+	}
+	if timeout > maxWaitTimeout {
+		timeout = maxWaitTimeout
+	}
+
+	_, done, ok := s.manager.get(in.Name)
+	if !ok {
+		err := status.Errorf(codes.NotFound, "unable to find key %s", in.Name)
+		logger.Infow("WaitOperation(): Operation not found", "error", err)
+		return nil, err
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+	case <-timer.C:
+	case <-ctx.Done():
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+
+	op, _, ok := s.manager.get(in.Name)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "unable to find key %s", in.Name)
+	}
+	return op, nil
+}
@@ -0,0 +1,79 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package port is the main package of the application
+package port
+
+import (
+	"context"
+
+	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+	"go.einride.tech/aip/resourceid"
+
+	epb "github.com/opiproject/opi-evpn-bridge/api/opi_evpn_bridge/v1"
+	"github.com/opiproject/opi-evpn-bridge/pkg/batch"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+// BatchCreateBridgePorts creates a batch of BridgePorts.
+// All the items are validated before anything is stored and either all or none
+// of the new BridgePorts are created. Already existing BridgePorts are returned as they are.
+func (s *Server) BatchCreateBridgePorts(ctx context.Context, in *epb.BatchCreateBridgePortsRequest) (*epb.BatchCreateBridgePortsResponse, error) {
+	create := batch.Create[*pb.CreateBridgePortRequest, *pb.BridgePort, *infradb.BridgePort]{
+		Kind:       "BridgePort",
+		ObjectType: "bridge-port",
+		Prepare: func(req *pb.CreateBridgePortRequest) (*pb.BridgePort, error) {
+			if err := s.validateCreateBridgePortRequest(req); err != nil {
+				return nil, err
+			}
+			// see https://google.aip.dev/133#user-specified-ids
+			resourceID := resourceid.NewSystemGenerated()
+			if req.BridgePortId != "" {
+				resourceID = req.BridgePortId
+			}
+			req.BridgePort.Name = resourceIDToFullName(resourceID)
+			return req.BridgePort, nil
+		},
+		Get: s.getBridgePort,
+		Build: func(bp *pb.BridgePort, createdBy string) (*infradb.BridgePort, error) {
+			if err := s.validateBridgePortSpec(bp); err != nil {
+				return nil, err
+			}
+			domainBP, err := infradb.NewBridgePort(bp)
+			if err != nil {
+				return nil, err
+			}
+			domainBP.Metadata.Audit.CreatedBy = createdBy
+			return domainBP, nil
+		},
+		Store: infradb.CreateBPs,
+		Result: func(domainBP *infradb.BridgePort) (*pb.BridgePort, string) {
+			return domainBP.ToPb(), domainBP.ResourceVersion
+		},
+	}
+	statuses, err := create.Apply(ctx, in.GetRequests())
+	if err != nil {
+		return nil, err
+	}
+	return &epb.BatchCreateBridgePortsResponse{Statuses: statuses}, nil
+}
+
+// BatchDeleteBridgePorts deletes a batch of BridgePorts.
+// Either all or none of the BridgePorts are deleted.
+func (s *Server) BatchDeleteBridgePorts(ctx context.Context, in *epb.BatchDeleteBridgePortsRequest) (*epb.BatchDeleteBridgePortsResponse, error) {
+	del := batch.Delete[*pb.DeleteBridgePortRequest, *pb.BridgePort]{
+		Kind:            "BridgePort",
+		ObjectType:      "bridge-port",
+		Validate:        s.validateDeleteBridgePortRequest,
+		Get:             s.getBridgePort,
+		Store:           infradb.DeleteBPs,
+		ResourceVersion: s.resourceVersion,
+	}
+	statuses, err := del.Apply(ctx, in.GetRequests())
+	if err != nil {
+		return nil, err
+	}
+	return &epb.BatchDeleteBridgePortsResponse{Statuses: statuses}, nil
+}
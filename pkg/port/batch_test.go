@@ -0,0 +1,169 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package port is the main package of the application
+package port
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+
+	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+
+	epb "github.com/opiproject/opi-evpn-bridge/api/opi_evpn_bridge/v1"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+func batchCreateRequest(id string, mac []byte, lbs ...string) *pb.CreateBridgePortRequest {
+	return &pb.CreateBridgePortRequest{
+		BridgePortId: id,
+		BridgePort: &pb.BridgePort{Spec: &pb.BridgePortSpec{
+			MacAddress:     mac,
+			Ptype:          pb.BridgePortType_BRIDGE_PORT_TYPE_TRUNK,
+			LogicalBridges: lbs,
+		}},
+	}
+}
+
+// statusCodes returns the codes of the statuses of the batch items
+func statusCodes(statuses []*spb.Status) []codes.Code {
+	results := []codes.Code{}
+	for _, s := range statuses {
+		results = append(results, codes.Code(s.Code))
+	}
+	return results
+}
+
+func Test_BatchCreateBridgePorts(t *testing.T) {
+	mac1 := []byte{0xCB, 0xB8, 0x33, 0x4C, 0x88, 0x01}
+	mac2 := []byte{0xCB, 0xB8, 0x33, 0x4C, 0x88, 0x02}
+
+	tests := map[string]struct {
+		in      []*pb.CreateBridgePortRequest
+		out     []codes.Code
+		created bool
+	}{
+		"successful call": {
+			in: []*pb.CreateBridgePortRequest{
+				batchCreateRequest("batch-bp1", mac1, testLogicalBridgeName),
+				batchCreateRequest("batch-bp2", mac2, testLogicalBridgeName),
+			},
+			out:     []codes.Code{codes.OK, codes.OK},
+			created: true,
+		},
+		"unknown logical bridge aborts the batch": {
+			in: []*pb.CreateBridgePortRequest{
+				batchCreateRequest("batch-bp1", mac1, testLogicalBridgeName),
+				batchCreateRequest("batch-bp2", mac2, "//network.opiproject.org/bridges/unknown-id"),
+			},
+			out: []codes.Code{codes.Aborted, codes.FailedPrecondition},
+		},
+		"same mac on the same logical bridge": {
+			in: []*pb.CreateBridgePortRequest{
+				batchCreateRequest("batch-bp1", mac1, testLogicalBridgeName),
+				batchCreateRequest("batch-bp2", mac1, testLogicalBridgeName),
+			},
+			out: []codes.Code{codes.Aborted, codes.Unknown},
+		},
+		"duplicate name in batch": {
+			in: []*pb.CreateBridgePortRequest{
+				batchCreateRequest("batch-bp1", mac1, testLogicalBridgeName),
+				batchCreateRequest("batch-bp1", mac2, testLogicalBridgeName),
+			},
+			out: []codes.Code{codes.Aborted, codes.InvalidArgument},
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			env := newTestEnv(ctx, t)
+			defer env.Close()
+
+			testLogicalBridgeFull := pb.LogicalBridge{
+				Name: testLogicalBridgeName,
+				Spec: testLogicalBridge.Spec,
+			}
+			_, _ = env.lbServer.TestCreateLogicalBridge(&testLogicalBridgeFull)
+
+			client := epb.NewBridgePortBatchServiceClient(env.conn)
+			response, err := client.BatchCreateBridgePorts(ctx, &epb.BatchCreateBridgePortsRequest{Requests: tt.in})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.out, statusCodes(response.GetStatuses()))
+
+			// the Logical Bridge references only the ports of a successful batch
+			lb, err := infradb.GetLB(testLogicalBridgeName)
+			assert.NoError(t, err)
+			if tt.created {
+				assert.Len(t, lb.BridgePorts, len(tt.in))
+			} else {
+				assert.Empty(t, lb.BridgePorts)
+			}
+			bps, _ := infradb.GetAllBPs()
+			if tt.created {
+				assert.Len(t, bps, len(tt.in))
+			} else {
+				assert.Empty(t, bps)
+			}
+		})
+	}
+}
+
+func Test_BatchDeleteBridgePorts(t *testing.T) {
+	tests := map[string]struct {
+		in  []*pb.DeleteBridgePortRequest
+		out []codes.Code
+	}{
+		"successful call": {
+			in: []*pb.DeleteBridgePortRequest{
+				{Name: testBridgePortName},
+				{Name: resourceIDToFullName("unknown-id"), AllowMissing: true},
+			},
+			out: []codes.Code{codes.OK, codes.OK},
+		},
+		"unknown key aborts the batch": {
+			in: []*pb.DeleteBridgePortRequest{
+				{Name: testBridgePortName},
+				{Name: resourceIDToFullName("unknown-id")},
+			},
+			out: []codes.Code{codes.Aborted, codes.NotFound},
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			env := newTestEnv(ctx, t)
+			defer env.Close()
+
+			testLogicalBridgeFull := pb.LogicalBridge{
+				Name: testLogicalBridgeName,
+				Spec: testLogicalBridge.Spec,
+			}
+			_, _ = env.lbServer.TestCreateLogicalBridge(&testLogicalBridgeFull)
+			testBridgePortFull := pb.BridgePort{
+				Name: testBridgePortName,
+				Spec: testBridgePort.Spec,
+			}
+			_, _ = env.opi.createBridgePort(ctx, &testBridgePortFull)
+
+			client := epb.NewBridgePortBatchServiceClient(env.conn)
+			response, err := client.BatchDeleteBridgePorts(ctx, &epb.BatchDeleteBridgePortsRequest{Requests: tt.in})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.out, statusCodes(response.GetStatuses()))
+
+			bp, err := infradb.GetBP(testBridgePortName)
+			assert.NoError(t, err)
+			deleted := bp.Status.BPOperStatus == infradb.BridgePortOperStatusToBeDeleted
+			assert.Equal(t, tt.out[0] == codes.OK, deleted)
+		})
+	}
+}
@@ -9,9 +9,8 @@ package port
 import (
 	"context"
 	"fmt"
-	"log"
 	"net"
-	"sort"
+	"strings"
 	"testing"
 
 	"go.einride.tech/aip/resourcename"
@@ -23,19 +22,21 @@ import (
 	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
 	pc "github.com/opiproject/opi-api/network/opinetcommon/v1alpha1/gen/go"
 
+	epb "github.com/opiproject/opi-evpn-bridge/api/opi_evpn_bridge/v1"
+	"github.com/opiproject/opi-evpn-bridge/pkg/audit"
 	"github.com/opiproject/opi-evpn-bridge/pkg/bridge"
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
 	"github.com/opiproject/opi-evpn-bridge/pkg/utils/mocks"
 )
 
-func sortBridgePorts(ports []*pb.BridgePort) {
-	sort.Slice(ports, func(i int, j int) bool {
-		return ports[i].Name < ports[j].Name
-	})
-}
+func (s *Server) createBridgePort(ctx context.Context, bp *pb.BridgePort) (response *pb.BridgePort, err error) {
+	var resourceVersion string
+	defer func() {
+		audit.Mutation(ctx, audit.ActionCreate, "bridge-port", bp.Name, bp, nil, response, resourceVersion, err)
+	}()
 
-func (s *Server) createBridgePort(bp *pb.BridgePort) (*pb.BridgePort, error) {
 	// check parameters
 	if err := s.validateBridgePortSpec(bp); err != nil {
 		return nil, err
@@ -46,16 +47,23 @@ func (s *Server) createBridgePort(bp *pb.BridgePort) (*pb.BridgePort, error) {
 	if err != nil {
 		return nil, err
 	}
+	domainBP.Metadata.Audit.CreatedBy = utils.ClientIdentity(ctx)
 	// Note: The status of the object will be generated in infraDB operation not here
 	if err := infradb.CreateBP(domainBP); err != nil {
 		return nil, err
 	}
+	resourceVersion = domainBP.ResourceVersion
 	return domainBP.ToPb(), nil
 }
 
-func (s *Server) deleteBridgePort(name string) error {
+func (s *Server) deleteBridgePort(ctx context.Context, name string) (err error) {
+	before, _ := s.getBridgePort(name)
+	defer func() {
+		audit.Mutation(ctx, audit.ActionDelete, "bridge-port", name, nil, before, nil, s.resourceVersion(name), err)
+	}()
+
 	// Note: The status of the object will be generated in infraDB operation not here
-	if err := infradb.DeleteBP(name); err != nil {
+	if err := infradb.DeleteBP(name, utils.ClientIdentity(ctx)); err != nil {
 		return err
 	}
 	return nil
@@ -82,7 +90,13 @@ func (s *Server) getAllBridgePorts() ([]*pb.BridgePort, error) {
 	return bps, nil
 }
 
-func (s *Server) updateBridgePort(bp *pb.BridgePort) (*pb.BridgePort, error) {
+func (s *Server) updateBridgePort(ctx context.Context, bp *pb.BridgePort) (response *pb.BridgePort, err error) {
+	before, _ := s.getBridgePort(bp.Name)
+	var resourceVersion string
+	defer func() {
+		audit.Mutation(ctx, audit.ActionUpdate, "bridge-port", bp.Name, bp, before, response, resourceVersion, err)
+	}()
+
 	// check parameters
 	if err := s.validateBridgePortSpec(bp); err != nil {
 		return nil, err
@@ -93,13 +107,25 @@ func (s *Server) updateBridgePort(bp *pb.BridgePort) (*pb.BridgePort, error) {
 	if err != nil {
 		return nil, err
 	}
+	domainBP.Metadata.Audit.UpdatedBy = utils.ClientIdentity(ctx)
 	// Note: The status of the object will be generated in infraDB operation not here
 	if err := infradb.UpdateBP(domainBP); err != nil {
 		return nil, err
 	}
+	resourceVersion = domainBP.ResourceVersion
 	return domainBP.ToPb(), nil
 }
 
+// resourceVersion returns the resource version of the stored object
+// or an empty string if the object does not exist anymore
+func (s *Server) resourceVersion(name string) string {
+	obj, err := infradb.GetBP(name)
+	if err != nil {
+		return ""
+	}
+	return obj.ResourceVersion
+}
+
 func resourceIDToFullName(resourceID string) string {
 	return resourcename.Join(
 		"//network.opiproject.org/",
@@ -107,6 +133,15 @@ func resourceIDToFullName(resourceID string) string {
 	)
 }
 
+// fullName converts the relative name that the HTTP gateway takes from the URL path,
+// e.g. "bridgePorts/{id}", to the full resource name
+func fullName(name string) string {
+	if strings.HasPrefix(name, "bridgePorts/") {
+		return resourceIDToFullName(strings.TrimPrefix(name, "bridgePorts/"))
+	}
+	return name
+}
+
 func checkTobeDeletedStatus(bp *pb.BridgePort) error {
 	if bp.Status.OperStatus == pb.BPOperStatus_BP_OPER_STATUS_TO_BE_DELETED {
 		return fmt.Errorf("bridge Port %s in to be deleted status", bp.Name)
@@ -148,7 +183,7 @@ type testEnv struct {
 func (e *testEnv) Close() {
 	err := e.conn.Close()
 	if err != nil {
-		log.Fatal(err)
+		logger.Fatal(err)
 	}
 }
 
@@ -167,7 +202,7 @@ func newTestEnv(ctx context.Context, t *testing.T) *testEnv {
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
 		grpc.WithContextDialer(dialer(env.opi)))
 	if err != nil {
-		log.Fatal(err)
+		logger.Fatal(err)
 	}
 	env.conn = conn
 	return env
@@ -178,10 +213,11 @@ func dialer(opi *Server) func(context.Context, string) (net.Conn, error) {
 	server := grpc.NewServer()
 
 	pb.RegisterBridgePortServiceServer(server, opi)
+	epb.RegisterBridgePortBatchServiceServer(server, opi)
 
 	go func() {
 		if err := server.Serve(listener); err != nil {
-			log.Fatal(err)
+			logger.Fatal(err)
 		}
 	}()
 
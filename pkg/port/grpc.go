@@ -8,11 +8,11 @@ package port
 
 import (
 	"context"
-	"log"
 	"reflect"
 
-	"github.com/google/uuid"
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/logging"
+	"github.com/opiproject/opi-evpn-bridge/pkg/operations"
 	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
 
 	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
@@ -20,98 +20,116 @@ import (
 	"go.einride.tech/aip/resourceid"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/emptypb"
 )
 
 // CreateBridgePort executes the creation of the port
-func (s *Server) CreateBridgePort(_ context.Context, in *pb.CreateBridgePortRequest) (*pb.BridgePort, error) {
+func (s *Server) CreateBridgePort(ctx context.Context, in *pb.CreateBridgePortRequest) (*pb.BridgePort, error) {
 	// check input correctness
 	if err := s.validateCreateBridgePortRequest(in); err != nil {
-		log.Printf("CreateBridgePort(): validation failure: %v", err)
+		logger.Errorw("CreateBridgePort(): Validation failure", "error", err)
 		return nil, err
 	}
 	// see https://google.aip.dev/133#user-specified-ids
 	resourceID := resourceid.NewSystemGenerated()
 	if in.BridgePortId != "" {
-		log.Printf("CreateBridgePort(): client provided the ID of a resource %v, ignoring the name field %v", in.BridgePortId, in.BridgePort.Name)
+		logger.Infow("CreateBridgePort(): Client provided the ID of a resource, ignoring the name field", "bridgePortId", in.BridgePortId, logging.Name(in.BridgePort.Name))
 		resourceID = in.BridgePortId
 	}
 	in.BridgePort.Name = resourceIDToFullName(resourceID)
+	op := operations.Ops.Begin(ctx, in.BridgePort.Name)
 	// idempotent API when called with same key, should return same object
 	bpObj, err := s.getBridgePort(in.BridgePort.Name)
 	if err != nil {
 		if err != infradb.ErrKeyNotFound {
-			log.Printf("CreateBridgePort(): Failed to interact with store: %v", err)
+			logger.Errorw("CreateBridgePort(): Failed to interact with store", "error", err)
+			op.Abort(err)
 			return nil, err
 		}
 	} else {
-		log.Printf("CreateBridgePort(): Already existing BridgePort with id %v", in.BridgePort.Name)
+		logger.Infow("CreateBridgePort(): Bridge Port already exists", logging.Name(in.BridgePort.Name))
+		op.Done(bpObj)
 		return bpObj, nil
 	}
 	// Store the domain object into DB
-	response, err := s.createBridgePort(in.BridgePort)
+	response, err := s.createBridgePort(ctx, in.BridgePort)
 	if err != nil {
-		log.Printf("CreateBridgePort(): BridgePort with id %v, Create Bridge Port to DB failure: %v", in.BridgePort.Name, err)
+		logger.Errorw("CreateBridgePort(): Create Bridge Port to DB failure", logging.Name(in.BridgePort.Name), "error", err)
+		op.Abort(err)
 		return nil, err
 	}
+	op.Commit(s.resourceVersion(response.Name), func() (proto.Message, error) {
+		return s.getBridgePort(response.Name)
+	})
 	return response, nil
 }
 
 // DeleteBridgePort deletes a port
-func (s *Server) DeleteBridgePort(_ context.Context, in *pb.DeleteBridgePortRequest) (*emptypb.Empty, error) {
+func (s *Server) DeleteBridgePort(ctx context.Context, in *pb.DeleteBridgePortRequest) (*emptypb.Empty, error) {
 	// check input correctness
 	if err := s.validateDeleteBridgePortRequest(in); err != nil {
-		log.Printf("DeleteBridgePort(): validation failure: %v", err)
+		logger.Errorw("DeleteBridgePort(): Validation failure", "error", err)
 		return nil, err
 	}
+	in.Name = fullName(in.Name)
+	op := operations.Ops.Begin(ctx, in.Name)
 	// fetch object from the database
 	_, err := s.getBridgePort(in.Name)
 	if err != nil {
 		if err != infradb.ErrKeyNotFound {
-			log.Printf("Failed to interact with store: %v", err)
+			logger.Errorw("DeleteBridgePort(): Failed to interact with store", "error", err)
+			op.Abort(err)
 			return nil, err
 		}
 		if !in.AllowMissing {
 			err = status.Errorf(codes.NotFound, "unable to find key %s", in.Name)
-			log.Printf("DeleteBridgePort(): BridgePort with id %v: Not Found %v", in.Name, err)
+			logger.Warnw("DeleteBridgePort(): Bridge Port not found", logging.Name(in.Name), "error", err)
+			op.Abort(err)
 			return nil, err
 		}
+		op.Done(&emptypb.Empty{})
 		return &emptypb.Empty{}, nil
 	}
 
-	if err := s.deleteBridgePort(in.Name); err != nil {
-		log.Printf("DeleteBridgePort(): BridgePort with id %v, Delete Bridge Port from DB failure: %v", in.Name, err)
+	if err := s.deleteBridgePort(ctx, in.Name); err != nil {
+		logger.Errorw("DeleteBridgePort(): Delete Bridge Port from DB failure", logging.Name(in.Name), "error", err)
+		op.Abort(err)
 		return nil, err
 	}
+	op.Commit(s.resourceVersion(in.Name), func() (proto.Message, error) {
+		return &emptypb.Empty{}, nil
+	})
 	return &emptypb.Empty{}, nil
 }
 
 // UpdateBridgePort updates an Nvme Subsystem
-func (s *Server) UpdateBridgePort(_ context.Context, in *pb.UpdateBridgePortRequest) (*pb.BridgePort, error) {
+func (s *Server) UpdateBridgePort(ctx context.Context, in *pb.UpdateBridgePortRequest) (*pb.BridgePort, error) {
 	// check input correctness
 	if err := s.validateUpdateBridgePortRequest(in); err != nil {
-		log.Printf("UpdateBridgePort(): validation failure: %v", err)
+		logger.Errorw("UpdateBridgePort(): Validation failure", "error", err)
 		return nil, err
 	}
+	in.BridgePort.Name = fullName(in.BridgePort.Name)
 	// fetch object from the
 	bpObj, err := s.getBridgePort(in.BridgePort.Name)
 	if err != nil {
 		if err != infradb.ErrKeyNotFound {
-			log.Printf("UpdateBridgePort(): Failed to interact with store: %v", err)
+			logger.Errorw("UpdateBridgePort(): Failed to interact with store", "error", err)
 			return nil, err
 		}
 		if !in.AllowMissing {
 			err = status.Errorf(codes.NotFound, "unable to find key %s", in.BridgePort.Name)
-			log.Printf("UpdateBridgePort(): BridgePort with id %v: Not Found %v", in.BridgePort.Name, err)
+			logger.Warnw("UpdateBridgePort(): Bridge Port not found", logging.Name(in.BridgePort.Name), "error", err)
 			return nil, err
 		}
 
-		log.Printf("UpdateBridgePort(): Bridge Port with id %v is not found so it will be created", in.BridgePort.Name)
+		logger.Warnw("UpdateBridgePort(): Bridge Port is not found so it will be created", logging.Name(in.BridgePort.Name))
 
 		// Store the domain object into DB
-		response, err := s.createBridgePort(in.BridgePort)
+		response, err := s.createBridgePort(ctx, in.BridgePort)
 		if err != nil {
-			log.Printf("UpdateBridgePort(): BridgePort with id %v, Create Bridge Port to DB failure: %v", in.BridgePort.Name, err)
+			logger.Errorw("UpdateBridgePort(): Create Bridge Port to DB failure", logging.Name(in.BridgePort.Name), "error", err)
 			return nil, err
 		}
 		return response, nil
@@ -119,7 +137,7 @@ func (s *Server) UpdateBridgePort(_ context.Context, in *pb.UpdateBridgePortRequ
 
 	// Check if the object for update is currently in TO_BE_DELETED status
 	if err := checkTobeDeletedStatus(bpObj); err != nil {
-		log.Printf("UpdateBridgePort(): Bridge Port with id %v, Error: %v", in.BridgePort.Name, err)
+		logger.Errorw("UpdateBridgePort(): Bridge Port cannot be updated", logging.Name(in.BridgePort.Name), "error", err)
 		return nil, err
 	}
 
@@ -136,9 +154,9 @@ func (s *Server) UpdateBridgePort(_ context.Context, in *pb.UpdateBridgePortRequ
 		return bpObj, nil
 	}
 
-	response, err := s.updateBridgePort(updatedbpObj)
+	response, err := s.updateBridgePort(ctx, updatedbpObj)
 	if err != nil {
-		log.Printf("UpdateBridgePort(): BridgePort with id %v, Update Bridge Port to DB failure: %v", in.BridgePort.Name, err)
+		logger.Errorw("UpdateBridgePort(): Update Bridge Port to DB failure", logging.Name(in.BridgePort.Name), "error", err)
 		return nil, err
 	}
 
@@ -149,18 +167,19 @@ func (s *Server) UpdateBridgePort(_ context.Context, in *pb.UpdateBridgePortRequ
 func (s *Server) GetBridgePort(_ context.Context, in *pb.GetBridgePortRequest) (*pb.BridgePort, error) {
 	// check input correctness
 	if err := s.validateGetBridgePortRequest(in); err != nil {
-		log.Printf("GetBridgePort(): validation failure: %v", err)
+		logger.Errorw("GetBridgePort(): Validation failure", "error", err)
 		return nil, err
 	}
+	in.Name = fullName(in.Name)
 	// fetch object from the database
 	bpObj, err := s.getBridgePort(in.Name)
 	if err != nil {
 		if err != infradb.ErrKeyNotFound {
-			log.Printf("Failed to interact with store: %v", err)
+			logger.Errorw("GetBridgePort(): Failed to interact with store", "error", err)
 			return nil, err
 		}
 		err = status.Errorf(codes.NotFound, "unable to find key %s", in.Name)
-		log.Printf("GetBridgePort(): BridgePort with id %v: Not Found %v", in.Name, err)
+		logger.Warnw("GetBridgePort(): Bridge Port not found", logging.Name(in.Name), "error", err)
 		return nil, err
 	}
 
@@ -168,36 +187,29 @@ func (s *Server) GetBridgePort(_ context.Context, in *pb.GetBridgePortRequest) (
 }
 
 // ListBridgePorts lists logical bridges
-func (s *Server) ListBridgePorts(_ context.Context, in *pb.ListBridgePortsRequest) (*pb.ListBridgePortsResponse, error) {
+func (s *Server) ListBridgePorts(ctx context.Context, in *pb.ListBridgePortsRequest) (*pb.ListBridgePortsResponse, error) {
 	// check required fields
 	if err := s.validateListBridgePortsRequest(in); err != nil {
-		log.Printf("ListBridgePorts(): validation failure: %v", err)
-		return nil, err
-	}
-	// fetch pagination from the database, calculate size and offset
-	size, offset, err := utils.ExtractPagination(in.PageSize, in.PageToken, s.Pagination)
-	if err != nil {
+		logger.Errorw("ListBridgePorts(): Validation failure", "error", err)
 		return nil, err
 	}
 	// fetch object from the database
 	Blobarray, err := s.getAllBridgePorts()
 	if err != nil {
 		if err != infradb.ErrKeyNotFound {
-			log.Printf("Failed to interact with store: %v", err)
+			logger.Errorw("ListBridgePorts(): Failed to interact with store", "error", err)
 			return nil, err
 		}
 		err := status.Errorf(codes.NotFound, "Error: %v", err)
-		log.Printf("ListBridgePorts(): %v", err)
+		logger.Infow("ListBridgePorts(): No Bridge Ports found", "error", err)
 		return nil, err
 	}
-	// sort is needed, since MAP is unsorted in golang, and we might get different results
-	sortBridgePorts(Blobarray)
-	log.Printf("Limiting result len(%d) to [%d:%d]", len(Blobarray), offset, size)
-	Blobarray, hasMoreElements := utils.LimitPagination(Blobarray, offset, size)
-	token := ""
-	if hasMoreElements {
-		token = uuid.New().String()
-		s.Pagination[token] = offset + size
+	// filter, sort and paginate, since MAP is unsorted in golang, and we might get different results
+	filter, orderBy := utils.ExtractListOptions(ctx)
+	Blobarray, token, err := utils.ListPage(Blobarray, in.PageSize, in.PageToken, filter, orderBy)
+	if err != nil {
+		logger.Infow("ListBridgePorts(): Invalid list options", "error", err)
+		return nil, err
 	}
 	return &pb.ListBridgePortsResponse{BridgePorts: Blobarray, NextPageToken: token}, nil
 }
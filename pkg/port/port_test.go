@@ -19,6 +19,8 @@ import (
 	"google.golang.org/protobuf/types/known/fieldmaskpb"
 
 	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
 	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
 	"github.com/opiproject/opi-evpn-bridge/pkg/utils/mocks"
 )
@@ -168,7 +170,7 @@ func Test_CreateBridgePort(t *testing.T) {
 					Name: testBridgePortName,
 					Spec: testBridgePort.Spec,
 				}
-				_, _ = env.opi.createBridgePort(&testBridgePortFull)
+				_, _ = env.opi.createBridgePort(ctx, &testBridgePortFull)
 			}
 			if tt.out != nil {
 				tt.out = utils.ProtoClone(tt.out)
@@ -260,7 +262,7 @@ func Test_DeleteBridgePort(t *testing.T) {
 				Name: testBridgePortName,
 				Spec: testBridgePort.Spec,
 			}
-			_, _ = env.opi.createBridgePort(&testBridgePortFull)
+			_, _ = env.opi.createBridgePort(ctx, &testBridgePortFull)
 			if tt.on != nil {
 				tt.on(env.mockNetlink, env.mockFrr, tt.errMsg)
 			}
@@ -345,7 +347,7 @@ func Test_UpdateBridgePort(t *testing.T) {
 					Name: testBridgePortName,
 					Spec: testBridgePort.Spec,
 				}
-				_, _ = env.opi.createBridgePort(&testBridgePortFull)
+				_, _ = env.opi.createBridgePort(ctx, &testBridgePortFull)
 			}
 			if tt.out != nil {
 				tt.out = utils.ProtoClone(tt.out)
@@ -372,6 +374,53 @@ func Test_UpdateBridgePort(t *testing.T) {
 	}
 }
 
+func Test_UpdateBridgePortKeepsMetadata(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(ctx, t)
+	defer env.Close()
+	client := pb.NewBridgePortServiceClient(env.conn)
+
+	testLogicalBridgeFull := pb.LogicalBridge{
+		Name: testLogicalBridgeName,
+		Spec: testLogicalBridge.Spec,
+	}
+	_, _ = env.lbServer.TestCreateLogicalBridge(&testLogicalBridgeFull)
+	testBridgePortFull := pb.BridgePort{
+		Name: testBridgePortName,
+		Spec: testBridgePort.Spec,
+	}
+	created, err := env.opi.createBridgePort(ctx, &testBridgePortFull)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the vendor module reports the vport of the bridge port in the metadata
+	domainBP, err := infradb.GetBP(testBridgePortName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	component := common.Component{Name: "dummy", CompStatus: common.ComponentStatusSuccess}
+	err = infradb.UpdateBPStatus(testBridgePortName, domainBP.ResourceVersion, "", &infradb.BridgePortMetadata{VPort: "vport-1"}, component)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	updated := utils.ProtoClone(created)
+	updated.Spec.MacAddress = []byte{0xCB, 0xB8, 0x33, 0x4C, 0x88, 0x50}
+	request := &pb.UpdateBridgePortRequest{BridgePort: updated, UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"spec.mac_address"}}}
+	if _, err := client.UpdateBridgePort(ctx, request); err != nil {
+		t.Fatal(err)
+	}
+
+	domainBP, err = infradb.GetBP(testBridgePortName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if domainBP.Metadata == nil || domainBP.Metadata.VPort != "vport-1" {
+		t.Error("metadata: expected vport", "vport-1", "received", domainBP.Metadata)
+	}
+}
+
 func Test_GetBridgePort(t *testing.T) {
 	tests := map[string]struct {
 		in      string
@@ -419,7 +468,7 @@ func Test_GetBridgePort(t *testing.T) {
 				Name: testBridgePortName,
 				Spec: testBridgePort.Spec,
 			}
-			_, _ = env.opi.createBridgePort(&testBridgePortFull)
+			_, _ = env.opi.createBridgePort(ctx, &testBridgePortFull)
 
 			request := &pb.GetBridgePortRequest{Name: tt.in}
 			response, err := client.GetBridgePort(ctx, request)
@@ -469,8 +518,8 @@ func Test_ListBridgePorts(t *testing.T) {
 		"pagination error": {
 			in:      "",
 			out:     nil,
-			errCode: codes.NotFound,
-			errMsg:  fmt.Sprintf("unable to find pagination token %s", "unknown-pagination-token"),
+			errCode: codes.InvalidArgument,
+			errMsg:  fmt.Sprintf("invalid pagination token %s", "unknown-pagination-token"),
 			size:    0,
 			token:   "unknown-pagination-token",
 		},
@@ -518,10 +567,14 @@ func Test_ListBridgePorts(t *testing.T) {
 				Name: testBridgePortName,
 				Spec: testBridgePort.Spec,
 			}
-			_, _ = env.opi.createBridgePort(&testBridgePortFull)
-			env.opi.Pagination["existing-pagination-token"] = 1
+			_, _ = env.opi.createBridgePort(ctx, &testBridgePortFull)
+			token := tt.token
+			if token == "existing-pagination-token" {
+				// cursor positioned after the only object in the store
+				token, _ = utils.PageTokenAfter(&testBridgePortFull, "", "")
+			}
 
-			request := &pb.ListBridgePortsRequest{PageSize: tt.size, PageToken: tt.token}
+			request := &pb.ListBridgePortsRequest{PageSize: tt.size, PageToken: token}
 			response, err := client.ListBridgePorts(ctx, request)
 			if !utils.EqualProtoSlices(response.GetBridgePorts(), tt.out) {
 				t.Error("response: expected", tt.out, "received", response.GetBridgePorts())
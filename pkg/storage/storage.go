@@ -7,13 +7,17 @@ package storage
 
 import (
 	"fmt"
-	"log"
 
 	"github.com/philippgille/gokv"
 	"github.com/philippgille/gokv/gomap"
 	"github.com/philippgille/gokv/redis"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/logging"
 )
 
+// logger is the logger of the db subsystem
+var logger = logging.Logger(logging.DB).Sugar()
+
 var st *Storage
 
 // Storage is an implementation of KeyValueStore using the gokv library.
@@ -51,7 +55,7 @@ func NewStore(backend, address string) (*Storage, error) {
 // GetStore returns the underlying database client.
 func GetStore() gokv.Store {
 	if st.store == nil {
-		log.Fatal(" Store not present ")
+		logger.Fatal(" Store not present ")
 	}
 	return st.store
 }
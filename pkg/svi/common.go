@@ -9,9 +9,8 @@ package svi
 import (
 	"context"
 	"fmt"
-	"log"
 	"net"
-	"sort"
+	"strings"
 	"testing"
 
 	"go.einride.tech/aip/resourcename"
@@ -23,20 +22,21 @@ import (
 	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
 	pc "github.com/opiproject/opi-api/network/opinetcommon/v1alpha1/gen/go"
 
+	"github.com/opiproject/opi-evpn-bridge/pkg/audit"
 	"github.com/opiproject/opi-evpn-bridge/pkg/bridge"
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
 	"github.com/opiproject/opi-evpn-bridge/pkg/utils/mocks"
 	"github.com/opiproject/opi-evpn-bridge/pkg/vrf"
 )
 
-func sortSvis(svis []*pb.Svi) {
-	sort.Slice(svis, func(i int, j int) bool {
-		return svis[i].Name < svis[j].Name
-	})
-}
+func (s *Server) createSvi(ctx context.Context, svi *pb.Svi) (response *pb.Svi, err error) {
+	var resourceVersion string
+	defer func() {
+		audit.Mutation(ctx, audit.ActionCreate, "svi", svi.Name, svi, nil, response, resourceVersion, err)
+	}()
 
-func (s *Server) createSvi(svi *pb.Svi) (*pb.Svi, error) {
 	// check parameters
 	if err := s.validateSviSpec(svi); err != nil {
 		return nil, err
@@ -47,16 +47,23 @@ func (s *Server) createSvi(svi *pb.Svi) (*pb.Svi, error) {
 	if err != nil {
 		return nil, err
 	}
+	domainSvi.Metadata.Audit.CreatedBy = utils.ClientIdentity(ctx)
 	// Note: The status of the object will be generated in infraDB operation not here
 	if err := infradb.CreateSvi(domainSvi); err != nil {
 		return nil, err
 	}
+	resourceVersion = domainSvi.ResourceVersion
 	return domainSvi.ToPb(), nil
 }
 
-func (s *Server) deleteSvi(name string) error {
+func (s *Server) deleteSvi(ctx context.Context, name string) (err error) {
+	before, _ := s.getSvi(name)
+	defer func() {
+		audit.Mutation(ctx, audit.ActionDelete, "svi", name, nil, before, nil, s.resourceVersion(name), err)
+	}()
+
 	// Note: The status of the object will be generated in infraDB operation not here
-	if err := infradb.DeleteSvi(name); err != nil {
+	if err := infradb.DeleteSvi(name, utils.ClientIdentity(ctx)); err != nil {
 		return err
 	}
 	return nil
@@ -83,7 +90,13 @@ func (s *Server) getAllSvis() ([]*pb.Svi, error) {
 	return svis, nil
 }
 
-func (s *Server) updateSvi(svi *pb.Svi) (*pb.Svi, error) {
+func (s *Server) updateSvi(ctx context.Context, svi *pb.Svi) (response *pb.Svi, err error) {
+	before, _ := s.getSvi(svi.Name)
+	var resourceVersion string
+	defer func() {
+		audit.Mutation(ctx, audit.ActionUpdate, "svi", svi.Name, svi, before, response, resourceVersion, err)
+	}()
+
 	// check parameters
 	if err := s.validateSviSpec(svi); err != nil {
 		return nil, err
@@ -94,13 +107,25 @@ func (s *Server) updateSvi(svi *pb.Svi) (*pb.Svi, error) {
 	if err != nil {
 		return nil, err
 	}
+	domainSvi.Metadata.Audit.UpdatedBy = utils.ClientIdentity(ctx)
 	// Note: The status of the object will be generated in infraDB operation not here
 	if err := infradb.UpdateSvi(domainSvi); err != nil {
 		return nil, err
 	}
+	resourceVersion = domainSvi.ResourceVersion
 	return domainSvi.ToPb(), nil
 }
 
+// resourceVersion returns the resource version of the stored object
+// or an empty string if the object does not exist anymore
+func (s *Server) resourceVersion(name string) string {
+	obj, err := infradb.GetSvi(name)
+	if err != nil {
+		return ""
+	}
+	return obj.ResourceVersion
+}
+
 func resourceIDToFullName(resourceID string) string {
 	return resourcename.Join(
 		"//network.opiproject.org/",
@@ -108,6 +133,15 @@ func resourceIDToFullName(resourceID string) string {
 	)
 }
 
+// fullName converts the relative name that the HTTP gateway takes from the URL path,
+// e.g. "svis/{id}", to the full resource name
+func fullName(name string) string {
+	if strings.HasPrefix(name, "svis/") {
+		return resourceIDToFullName(strings.TrimPrefix(name, "svis/"))
+	}
+	return name
+}
+
 func checkTobeDeletedStatus(svi *pb.Svi) error {
 	if svi.Status.OperStatus == pb.SVIOperStatus_SVI_OPER_STATUS_TO_BE_DELETED {
 		return fmt.Errorf("SVI %s in to be deleted status", svi.Name)
@@ -175,7 +209,7 @@ type testEnv struct {
 func (e *testEnv) Close() {
 	err := e.conn.Close()
 	if err != nil {
-		log.Fatal(err)
+		logger.Fatal(err)
 	}
 }
 
@@ -196,7 +230,7 @@ func newTestEnv(ctx context.Context, t *testing.T) *testEnv {
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
 		grpc.WithContextDialer(dialer(env.opi)))
 	if err != nil {
-		log.Fatal(err)
+		logger.Fatal(err)
 	}
 	env.conn = conn
 	return env
@@ -210,7 +244,7 @@ func dialer(opi *Server) func(context.Context, string) (net.Conn, error) {
 
 	go func() {
 		if err := server.Serve(listener); err != nil {
-			log.Fatal(err)
+			logger.Fatal(err)
 		}
 	}()
 
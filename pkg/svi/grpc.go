@@ -8,111 +8,129 @@ package svi
 
 import (
 	"context"
-	"log"
 	"reflect"
 
-	"github.com/google/uuid"
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/logging"
+	"github.com/opiproject/opi-evpn-bridge/pkg/operations"
 	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
 
 	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
 	"go.einride.tech/aip/resourceid"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/emptypb"
 )
 
 // CreateSvi executes the creation of the Svi
-func (s *Server) CreateSvi(_ context.Context, in *pb.CreateSviRequest) (*pb.Svi, error) {
+func (s *Server) CreateSvi(ctx context.Context, in *pb.CreateSviRequest) (*pb.Svi, error) {
 	// check input correctness
 	if err := s.validateCreateSviRequest(in); err != nil {
-		log.Printf("CreateSvi(): validation failure: %v", err)
+		logger.Errorw("CreateSvi(): Validation failure", "error", err)
 		return nil, err
 	}
 	// see https://google.aip.dev/133#user-specified-ids
 	resourceID := resourceid.NewSystemGenerated()
 	if in.SviId != "" {
-		log.Printf("CreateSvi(): client provided the ID of a resource %v, ignoring the name field %v", in.SviId, in.Svi.Name)
+		logger.Infow("CreateSvi(): Client provided the ID of a resource, ignoring the name field", "sviId", in.SviId, logging.Name(in.Svi.Name))
 		resourceID = in.SviId
 	}
 	in.Svi.Name = resourceIDToFullName(resourceID)
+	op := operations.Ops.Begin(ctx, in.Svi.Name)
 	// idempotent API when called with same key, should return same object
 	sviObj, err := s.getSvi(in.Svi.Name)
 	if err != nil {
 		if err != infradb.ErrKeyNotFound {
-			log.Printf("CreateSvi(): Failed to interact with store: %v", err)
+			logger.Errorw("CreateSvi(): Failed to interact with store", "error", err)
+			op.Abort(err)
 			return nil, err
 		}
 	} else {
-		log.Printf("CreateSvi(): Already existing Svi with id %v", in.Svi.Name)
+		logger.Infow("CreateSvi(): Svi already exists", logging.Name(in.Svi.Name))
+		op.Done(sviObj)
 		return sviObj, nil
 	}
 
 	// Store the domain object into DB
-	response, err := s.createSvi(in.Svi)
+	response, err := s.createSvi(ctx, in.Svi)
 	if err != nil {
-		log.Printf("CreateSvi(): Svi with id %v, Create Svi to DB failure: %v", in.Svi.Name, err)
+		logger.Errorw("CreateSvi(): Create Svi to DB failure", logging.Name(in.Svi.Name), "error", err)
+		op.Abort(err)
 		return nil, err
 	}
+	op.Commit(s.resourceVersion(response.Name), func() (proto.Message, error) {
+		return s.getSvi(response.Name)
+	})
 	return response, nil
 }
 
 // DeleteSvi deletes a Svi
-func (s *Server) DeleteSvi(_ context.Context, in *pb.DeleteSviRequest) (*emptypb.Empty, error) {
+func (s *Server) DeleteSvi(ctx context.Context, in *pb.DeleteSviRequest) (*emptypb.Empty, error) {
 	// check input correctness
 	if err := s.validateDeleteSviRequest(in); err != nil {
-		log.Printf("DeleteSvi(): validation failure: %v", err)
+		logger.Errorw("DeleteSvi(): Validation failure", "error", err)
 		return nil, err
 	}
+	in.Name = fullName(in.Name)
+	op := operations.Ops.Begin(ctx, in.Name)
 	// fetch object from the database
 	_, err := s.getSvi(in.Name)
 	if err != nil {
 		if err != infradb.ErrKeyNotFound {
-			log.Printf("Failed to interact with store: %v", err)
+			logger.Errorw("DeleteSvi(): Failed to interact with store", "error", err)
+			op.Abort(err)
 			return nil, err
 		}
 		if !in.AllowMissing {
 			err = status.Errorf(codes.NotFound, "unable to find key %s", in.Name)
-			log.Printf("DeleteSvi(): Svi with id %v: Not Found %v", in.Name, err)
+			logger.Warnw("DeleteSvi(): Svi not found", logging.Name(in.Name), "error", err)
+			op.Abort(err)
 			return nil, err
 		}
+		op.Done(&emptypb.Empty{})
 		return &emptypb.Empty{}, nil
 	}
 
-	if err := s.deleteSvi(in.Name); err != nil {
-		log.Printf("DeleteSvi(): Svi with id %v, Delete Svi from DB failure: %v", in.Name, err)
+	if err := s.deleteSvi(ctx, in.Name); err != nil {
+		logger.Errorw("DeleteSvi(): Delete Svi from DB failure", logging.Name(in.Name), "error", err)
+		op.Abort(err)
 		return nil, err
 	}
+	op.Commit(s.resourceVersion(in.Name), func() (proto.Message, error) {
+		return &emptypb.Empty{}, nil
+	})
 
 	return &emptypb.Empty{}, nil
 }
 
 // UpdateSvi updates a Svi
-func (s *Server) UpdateSvi(_ context.Context, in *pb.UpdateSviRequest) (*pb.Svi, error) {
+func (s *Server) UpdateSvi(ctx context.Context, in *pb.UpdateSviRequest) (*pb.Svi, error) {
 	// check input correctness
 	if err := s.validateUpdateSviRequest(in); err != nil {
-		log.Printf("UpdateSvi(): validation failure: %v", err)
+		logger.Errorw("UpdateSvi(): Validation failure", "error", err)
 		return nil, err
 	}
+	in.Svi.Name = fullName(in.Svi.Name)
 	// fetch object from the database
 	sviObj, err := s.getSvi(in.Svi.Name)
 	if err != nil {
 		if err != infradb.ErrKeyNotFound {
-			log.Printf("UpdateSvi(): Failed to interact with store: %v", err)
+			logger.Errorw("UpdateSvi(): Failed to interact with store", "error", err)
 			return nil, err
 		}
 		if !in.AllowMissing {
 			err = status.Errorf(codes.NotFound, "unable to find key %s", in.Svi.Name)
-			log.Printf("UpdateSvi(): Svi with id %v: Not Found %v", in.Svi.Name, err)
+			logger.Warnw("UpdateSvi(): Svi not found", logging.Name(in.Svi.Name), "error", err)
 			return nil, err
 		}
 
-		log.Printf("UpdateSvi(): Svi with id %v is not found so it will be created", in.Svi.Name)
+		logger.Warnw("UpdateSvi(): Svi is not found so it will be created", logging.Name(in.Svi.Name))
 
 		// Store the domain object into DB
-		response, err := s.createSvi(in.Svi)
+		response, err := s.createSvi(ctx, in.Svi)
 		if err != nil {
-			log.Printf("UpdateSvi(): Svi with id %v, Create Svi to DB failure: %v", in.Svi.Name, err)
+			logger.Errorw("UpdateSvi(): Create Svi to DB failure", logging.Name(in.Svi.Name), "error", err)
 			return nil, err
 		}
 		return response, nil
@@ -120,7 +138,7 @@ func (s *Server) UpdateSvi(_ context.Context, in *pb.UpdateSviRequest) (*pb.Svi,
 
 	// Check if the object for update is currently in TO_BE_DELETED status
 	if err := checkTobeDeletedStatus(sviObj); err != nil {
-		log.Printf("UpdateSvi(): SVI with id %v, Error: %v", in.Svi.Name, err)
+		logger.Errorw("UpdateSvi(): Svi cannot be updated", logging.Name(in.Svi.Name), "error", err)
 		return nil, err
 	}
 
@@ -137,9 +155,15 @@ func (s *Server) UpdateSvi(_ context.Context, in *pb.UpdateSviRequest) (*pb.Svi,
 		return sviObj, nil
 	}
 
-	response, err := s.updateSvi(updatedsviObj)
+	// Check that the update does not change any immutable field
+	if err := s.validateSviImmutableFields(sviObj, updatedsviObj); err != nil {
+		logger.Errorw("UpdateSvi(): Svi cannot be updated", logging.Name(in.Svi.Name), "error", err)
+		return nil, err
+	}
+
+	response, err := s.updateSvi(ctx, updatedsviObj)
 	if err != nil {
-		log.Printf("UpdateSvi(): Svi with id %v, Update Svi to DB failure: %v", in.Svi.Name, err)
+		logger.Errorw("UpdateSvi(): Update Svi to DB failure", logging.Name(in.Svi.Name), "error", err)
 		return nil, err
 	}
 
@@ -150,18 +174,19 @@ func (s *Server) UpdateSvi(_ context.Context, in *pb.UpdateSviRequest) (*pb.Svi,
 func (s *Server) GetSvi(_ context.Context, in *pb.GetSviRequest) (*pb.Svi, error) {
 	// check input correctness
 	if err := s.validateGetSviRequest(in); err != nil {
-		log.Printf("GetSvi(): validation failure: %v", err)
+		logger.Errorw("GetSvi(): Validation failure", "error", err)
 		return nil, err
 	}
+	in.Name = fullName(in.Name)
 	// fetch object from the database
 	sviObj, err := s.getSvi(in.Name)
 	if err != nil {
 		if err != infradb.ErrKeyNotFound {
-			log.Printf("Failed to interact with store: %v", err)
+			logger.Errorw("GetSvi(): Failed to interact with store", "error", err)
 			return nil, err
 		}
 		err = status.Errorf(codes.NotFound, "unable to find key %s", in.Name)
-		log.Printf("GetSvi(): Svi with id %v: Not Found %v", in.Name, err)
+		logger.Warnw("GetSvi(): Svi not found", logging.Name(in.Name), "error", err)
 		return nil, err
 	}
 
@@ -169,36 +194,29 @@ func (s *Server) GetSvi(_ context.Context, in *pb.GetSviRequest) (*pb.Svi, error
 }
 
 // ListSvis lists logical bridges
-func (s *Server) ListSvis(_ context.Context, in *pb.ListSvisRequest) (*pb.ListSvisResponse, error) {
+func (s *Server) ListSvis(ctx context.Context, in *pb.ListSvisRequest) (*pb.ListSvisResponse, error) {
 	// check required fields
 	if err := s.validateListSvisRequest(in); err != nil {
-		log.Printf("ListSvis(): validation failure: %v", err)
-		return nil, err
-	}
-	// fetch pagination from the database, calculate size and offset
-	size, offset, err := utils.ExtractPagination(in.PageSize, in.PageToken, s.Pagination)
-	if err != nil {
+		logger.Errorw("ListSvis(): Validation failure", "error", err)
 		return nil, err
 	}
 	// fetch object from the database
 	Blobarray, err := s.getAllSvis()
 	if err != nil {
 		if err != infradb.ErrKeyNotFound {
-			log.Printf("Failed to interact with store: %v", err)
+			logger.Errorw("ListSvis(): Failed to interact with store", "error", err)
 			return nil, err
 		}
 		err := status.Errorf(codes.NotFound, "Error: %v", err)
-		log.Printf("ListSvis(): %v", err)
+		logger.Infow("ListSvis(): No Svis found", "error", err)
 		return nil, err
 	}
-	// sort is needed, since MAP is unsorted in golang, and we might get different results
-	sortSvis(Blobarray)
-	log.Printf("Limiting result len(%d) to [%d:%d]", len(Blobarray), offset, size)
-	Blobarray, hasMoreElements := utils.LimitPagination(Blobarray, offset, size)
-	token := ""
-	if hasMoreElements {
-		token = uuid.New().String()
-		s.Pagination[token] = offset + size
+	// filter, sort and paginate, since MAP is unsorted in golang, and we might get different results
+	filter, orderBy := utils.ExtractListOptions(ctx)
+	Blobarray, token, err := utils.ListPage(Blobarray, in.PageSize, in.PageToken, filter, orderBy)
+	if err != nil {
+		logger.Infow("ListSvis(): Invalid list options", "error", err)
+		return nil, err
 	}
 	return &pb.ListSvisResponse{Svis: Blobarray, NextPageToken: token}, nil
 }
@@ -11,19 +11,22 @@ import (
 	"go.opentelemetry.io/otel/trace"
 
 	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/logging"
 )
 
+// logger is the logger of the grpc subsystem
+var logger = logging.Logger(logging.Grpc).Sugar()
+
 // Server represents the Server object
 type Server struct {
 	pb.UnimplementedSviServiceServer
-	Pagination map[string]int
-	tracer     trace.Tracer
+	tracer trace.Tracer
 }
 
 // NewServer creates initialized instance of EVPN server
 func NewServer() *Server {
 	return &Server{
-		Pagination: make(map[string]int),
-		tracer:     otel.Tracer(""),
+		tracer: otel.Tracer(""),
 	}
 }
@@ -13,6 +13,7 @@ import (
 	"testing"
 
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/emptypb"
@@ -243,7 +244,7 @@ func Test_CreateSvi(t *testing.T) {
 					Name: testSviName,
 					Spec: testSvi.Spec,
 				}
-				_, _ = env.opi.createSvi(&testSviFull)
+				_, _ = env.opi.createSvi(ctx, &testSviFull)
 			}
 			if tt.out != nil {
 				tt.out = utils.ProtoClone(tt.out)
@@ -341,7 +342,7 @@ func Test_DeleteSvi(t *testing.T) {
 				Name: testSviName,
 				Spec: testSvi.Spec,
 			}
-			_, _ = env.opi.createSvi(&testSviFull)
+			_, _ = env.opi.createSvi(ctx, &testSviFull)
 
 			if tt.on != nil {
 				tt.on(env.mockNetlink, env.mockFrr, tt.errMsg)
@@ -408,6 +409,23 @@ func Test_UpdateSvi(t *testing.T) {
 			start:   false,
 			exist:   true,
 		},
+		"immutable logical bridge": {
+			mask: nil,
+			in: &pb.Svi{
+				Name: testSviName,
+				Spec: &pb.SviSpec{
+					Vrf:           testVrfName,
+					LogicalBridge: "//network.opiproject.org/bridges/other-bridge",
+					MacAddress:    testSvi.Spec.MacAddress,
+					GwIpPrefix:    testSvi.Spec.GwIpPrefix,
+				},
+			},
+			out:     nil,
+			errCode: codes.InvalidArgument,
+			errMsg:  fmt.Sprintf("Logical Bridge of SVI %v cannot be changed", testSviName),
+			start:   false,
+			exist:   true,
+		},
 	}
 
 	// run tests
@@ -435,7 +453,7 @@ func Test_UpdateSvi(t *testing.T) {
 					Name: testSviName,
 					Spec: testSvi.Spec,
 				}
-				_, _ = env.opi.createSvi(&testSviFull)
+				_, _ = env.opi.createSvi(ctx, &testSviFull)
 			}
 			if tt.out != nil {
 				tt.out = utils.ProtoClone(tt.out)
@@ -516,7 +534,7 @@ func Test_GetSvi(t *testing.T) {
 				Name: testSviName,
 				Spec: testSvi.Spec,
 			}
-			_, _ = env.opi.createSvi(&testSviFull)
+			_, _ = env.opi.createSvi(ctx, &testSviFull)
 
 			request := &pb.GetSviRequest{Name: tt.in}
 			response, err := client.GetSvi(ctx, request)
@@ -546,6 +564,7 @@ func Test_ListSvis(t *testing.T) {
 		errMsg  string
 		size    int32
 		token   string
+		filter  string
 	}{
 		"example test": {
 			in:      "",
@@ -566,8 +585,8 @@ func Test_ListSvis(t *testing.T) {
 		"pagination error": {
 			in:      "",
 			out:     nil,
-			errCode: codes.NotFound,
-			errMsg:  fmt.Sprintf("unable to find pagination token %s", "unknown-pagination-token"),
+			errCode: codes.InvalidArgument,
+			errMsg:  fmt.Sprintf("invalid pagination token %s", "unknown-pagination-token"),
 			size:    0,
 			token:   "unknown-pagination-token",
 		},
@@ -595,6 +614,33 @@ func Test_ListSvis(t *testing.T) {
 			size:    1,
 			token:   "existing-pagination-token",
 		},
+		"filter match": {
+			in:      "",
+			out:     []*pb.Svi{&testSviWithStatus},
+			errCode: codes.OK,
+			errMsg:  "",
+			size:    0,
+			token:   "",
+			filter:  fmt.Sprintf("spec.vrf = %q AND status.components.status = COMP_STATUS_PENDING", testVrfName),
+		},
+		"filter no match": {
+			in:      "",
+			out:     []*pb.Svi{},
+			errCode: codes.OK,
+			errMsg:  "",
+			size:    0,
+			token:   "",
+			filter:  "status.components.status = COMP_STATUS_ERROR",
+		},
+		"filter unknown field": {
+			in:      "",
+			out:     nil,
+			errCode: codes.InvalidArgument,
+			errMsg:  "invalid filter \"spec.vni = 10\": check call expr: check select expr: undeclared identifier 'spec'",
+			size:    0,
+			token:   "",
+			filter:  "spec.vni = 10",
+		},
 	}
 
 	// run tests
@@ -621,10 +667,17 @@ func Test_ListSvis(t *testing.T) {
 				Name: testSviName,
 				Spec: testSvi.Spec,
 			}
-			_, _ = env.opi.createSvi(&testSviFull)
-			env.opi.Pagination["existing-pagination-token"] = 1
+			_, _ = env.opi.createSvi(ctx, &testSviFull)
+			token := tt.token
+			if token == "existing-pagination-token" {
+				// cursor positioned after the only object in the store
+				token, _ = utils.PageTokenAfter(&testSviFull, "", "")
+			}
 
-			request := &pb.ListSvisRequest{PageSize: tt.size, PageToken: tt.token}
+			if tt.filter != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, utils.FilterMetadataKey, tt.filter)
+			}
+			request := &pb.ListSvisRequest{PageSize: tt.size, PageToken: token}
 			response, err := client.ListSvis(ctx, request)
 			if !utils.EqualProtoSlices(response.GetSvis(), tt.out) {
 				t.Error("response: expected", tt.out, "received", response.GetSvis())
@@ -18,6 +18,7 @@ import (
 	"google.golang.org/grpc/status"
 
 	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
 	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
 )
 
@@ -55,7 +56,13 @@ func (s *Server) validateSviSpec(svi *pb.Svi) error {
 		return status.Errorf(codes.InvalidArgument, msg)
 	}
 
-	// Dimitris: Should I validate also the gw_ip_prefix ?
+	// Validate that the gateway IPs are valid IPv4 or IPv6 prefixes
+	for _, gwIPPrefix := range svi.Spec.GwIpPrefix {
+		if _, err := common.ConvertToIPNet(gwIPPrefix); err != nil {
+			msg := fmt.Sprintf("Invalid gateway IP prefix: %v", err)
+			return status.Errorf(codes.InvalidArgument, msg)
+		}
+	}
 
 	// Dimitris: Do we need to change the type of RemoteAs to something else than uint32 ?
 	// because now the default value is "0" which is not good. I think "optional uint32" in protobuf is better
@@ -81,6 +88,18 @@ func validateASN(asn uint32) error {
 	return nil
 }
 
+func (s *Server) validateSviImmutableFields(stored, updated *pb.Svi) error {
+	if stored.Spec.Vrf != updated.Spec.Vrf {
+		msg := fmt.Sprintf("VRF of SVI %v cannot be changed", stored.Name)
+		return status.Errorf(codes.InvalidArgument, msg)
+	}
+	if stored.Spec.LogicalBridge != updated.Spec.LogicalBridge {
+		msg := fmt.Sprintf("Logical Bridge of SVI %v cannot be changed", stored.Name)
+		return status.Errorf(codes.InvalidArgument, msg)
+	}
+	return nil
+}
+
 func (s *Server) validateDeleteSviRequest(in *pb.DeleteSviRequest) error {
 	// check required fields
 	if err := fieldbehavior.ValidateRequiredFields(in); err != nil {
@@ -0,0 +1,319 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package utils contains utility functions
+package utils
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/x509"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/logging"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+)
+
+// Role is the access level granted to a client
+type Role int
+
+const (
+	// RoleNone grants no access
+	RoleNone Role = iota
+	// RoleViewer grants read only access
+	RoleViewer
+	// RoleOperator grants access to read, create and update objects
+	RoleOperator
+	// RoleAdmin grants full access, including deletion of objects
+	RoleAdmin
+)
+
+var roleNames = map[Role]string{
+	RoleNone:     "none",
+	RoleViewer:   "viewer",
+	RoleOperator: "operator",
+	RoleAdmin:    "admin",
+}
+
+// String returns the name of the role
+func (r Role) String() string {
+	if name, ok := roleNames[r]; ok {
+		return name
+	}
+	return fmt.Sprintf("Role(%d)", int(r))
+}
+
+// ParseRole returns the role with the given name
+func ParseRole(name string) (Role, error) {
+	for role, roleName := range roleNames {
+		if roleName == strings.ToLower(name) {
+			return role, nil
+		}
+	}
+	return RoleNone, fmt.Errorf("unknown role %q", name)
+}
+
+// AnonymousIdentity is recorded for the clients that have not been authenticated
+const AnonymousIdentity = "anonymous"
+
+// Identity is the authenticated identity of a client
+type Identity struct {
+	Name string
+	Role Role
+}
+
+type identityKey struct{}
+
+// ContextWithIdentity returns a copy of the context that carries the client identity
+func ContextWithIdentity(ctx context.Context, id Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, id)
+}
+
+// IdentityFromContext returns the client identity carried by the context
+func IdentityFromContext(ctx context.Context) (Identity, bool) {
+	id, ok := ctx.Value(identityKey{}).(Identity)
+	return id, ok
+}
+
+// ClientIdentity returns the name of the client that issued the request
+// to be recorded in the logs and in the metadata of the objects
+func ClientIdentity(ctx context.Context) string {
+	if id, ok := IdentityFromContext(ctx); ok && id.Name != "" {
+		return id.Name
+	}
+	return AnonymousIdentity
+}
+
+// IdentityLogFields returns the client identity as logging fields
+func IdentityLogFields(ctx context.Context) logging.Fields {
+	return logging.Fields{"grpc.client", ClientIdentity(ctx)}
+}
+
+type methodRule struct {
+	pattern string
+	role    Role
+}
+
+// Authorizer authenticates the clients of the gRPC server and checks
+// that their role is allowed to call the requested method
+type Authorizer struct {
+	enabled bool
+	certs   map[string]Identity
+	tokens  map[string]Identity
+	rules   []methodRule
+}
+
+// NewAuthorizer creates an authorizer from the auth config.
+// When the authorization is disabled the clients are still identified
+// so that their identity is recorded, but every call is allowed.
+func NewAuthorizer(cfg config.AuthConfig) (*Authorizer, error) {
+	a := &Authorizer{
+		enabled: cfg.Enabled,
+		certs:   make(map[string]Identity),
+		tokens:  make(map[string]Identity),
+	}
+	for _, client := range cfg.Clients {
+		role, err := ParseRole(client.Role)
+		if err != nil {
+			return nil, fmt.Errorf("client %q: %w", client.Name, err)
+		}
+		if client.Cert == "" && client.Token == "" {
+			return nil, fmt.Errorf("client %q: a certificate name or a token is required", client.Name)
+		}
+		name := client.Name
+		if name == "" {
+			name = client.Cert
+		}
+		if name == "" {
+			return nil, fmt.Errorf("a name is required for the clients that use a token")
+		}
+		if client.Cert != "" {
+			a.certs[client.Cert] = Identity{Name: name, Role: role}
+		}
+		if client.Token != "" {
+			a.tokens[client.Token] = Identity{Name: name, Role: role}
+		}
+	}
+	for _, method := range cfg.Methods {
+		role, err := ParseRole(method.Role)
+		if err != nil {
+			return nil, fmt.Errorf("method %q: %w", method.Method, err)
+		}
+		if _, err := path.Match(method.Method, ""); err != nil {
+			return nil, fmt.Errorf("method %q: %w", method.Method, err)
+		}
+		a.rules = append(a.rules, methodRule{pattern: method.Method, role: role})
+	}
+	return a, nil
+}
+
+// authenticate identifies the client by its bearer token or by its certificate.
+// A client with an unknown certificate is identified by the certificate subject
+// but gets no role.
+func (a *Authorizer) authenticate(ctx context.Context) (Identity, bool) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for _, value := range md.Get("authorization") {
+			if !strings.HasPrefix(value, "Bearer ") {
+				continue
+			}
+			token := strings.TrimPrefix(value, "Bearer ")
+			for known, id := range a.tokens {
+				if subtle.ConstantTimeCompare([]byte(known), []byte(token)) == 1 {
+					return id, true
+				}
+			}
+		}
+	}
+
+	cert := peerCertificate(ctx)
+	if cert == nil {
+		return Identity{}, false
+	}
+	for _, name := range certificateNames(cert) {
+		if id, ok := a.certs[name]; ok {
+			return id, true
+		}
+	}
+	return Identity{Name: cert.Subject.CommonName, Role: RoleNone}, true
+}
+
+// requiredRole returns the role that is needed to call the method.
+// The configured rules are checked in order and the first matching one wins,
+// otherwise the health service is open to all, read methods need a viewer,
+// deletions, the audit trail and the netlink tables an admin and the rest an operator.
+func (a *Authorizer) requiredRole(fullMethod string) Role {
+	for _, rule := range a.rules {
+		if ok, _ := path.Match(rule.pattern, fullMethod); ok {
+			return rule.role
+		}
+	}
+	// The orchestrator probes the health service without credentials
+	if strings.HasPrefix(fullMethod, "/grpc.health.v1.Health/") {
+		return RoleNone
+	}
+	if strings.HasPrefix(fullMethod, "/grpc.reflection.") {
+		return RoleViewer
+	}
+	// The audit trail tells who has changed what so it is reserved to the admins
+	if strings.HasPrefix(fullMethod, "/opi_evpn_bridge.v1.AuditService/") {
+		return RoleAdmin
+	}
+	// The netlink tables show the routes, nexthops and fdb entries of every tenant so they are reserved to the admins
+	if strings.HasPrefix(fullMethod, "/opi_evpn_bridge.v1.NetlinkService/") {
+		return RoleAdmin
+	}
+	// Debug logs may leak the intent of every tenant and a reload of the config
+	// changes the behavior of the whole bridge so both are reserved to the admins
+	if strings.HasPrefix(fullMethod, "/opi_evpn_bridge.v1.LoggingService/") ||
+		strings.HasPrefix(fullMethod, "/opi_evpn_bridge.v1.ConfigService/") {
+		return RoleAdmin
+	}
+	method := path.Base(fullMethod)
+	switch {
+	case strings.HasPrefix(method, "Get"), strings.HasPrefix(method, "List"),
+		strings.HasPrefix(method, "Wait"), strings.HasPrefix(method, "ServerReflection"):
+		return RoleViewer
+	case strings.HasPrefix(method, "Delete"), strings.HasPrefix(method, "BatchDelete"):
+		return RoleAdmin
+	default:
+		return RoleOperator
+	}
+}
+
+// authorize identifies the client and returns the context that carries its identity
+func (a *Authorizer) authorize(ctx context.Context, fullMethod string) (context.Context, error) {
+	id, ok := a.authenticate(ctx)
+	if ok {
+		ctx = ContextWithIdentity(ctx, id)
+	}
+	if !a.enabled {
+		return ctx, nil
+	}
+	required := a.requiredRole(fullMethod)
+	if required == RoleNone {
+		return ctx, nil
+	}
+	if !ok {
+		logger.Infow("Authorize(): Unauthenticated call", "method", fullMethod)
+		return nil, status.Errorf(codes.Unauthenticated, "missing client credentials")
+	}
+	if id.Role < required {
+		logger.Infow("Authorize(): Client is not allowed to call the method", "client", id.Name, "role", id.Role, "method", fullMethod)
+		return nil, status.Errorf(codes.PermissionDenied, "client %s with role %s is not allowed to call %s", id.Name, id.Role, fullMethod)
+	}
+	return ctx, nil
+}
+
+// UnaryServerInterceptor returns the unary interceptor that authorizes the calls
+func (a *Authorizer) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := a.authorize(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns the stream interceptor that authorizes the calls
+func (a *Authorizer) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := a.authorize(stream.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &identityServerStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+// identityServerStream overrides the context of a server stream
+type identityServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the context that carries the client identity
+func (s *identityServerStream) Context() context.Context {
+	return s.ctx
+}
+
+// peerCertificate returns the verified certificate of the client if any
+func peerCertificate(ctx context.Context) *x509.Certificate {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return nil
+	}
+	if len(tlsInfo.State.VerifiedChains) != 0 && len(tlsInfo.State.VerifiedChains[0]) != 0 {
+		return tlsInfo.State.VerifiedChains[0][0]
+	}
+	return nil
+}
+
+// certificateNames returns the subject alternative names and the common name of a certificate
+func certificateNames(cert *x509.Certificate) []string {
+	names := append([]string{}, cert.DNSNames...)
+	names = append(names, cert.EmailAddresses...)
+	for _, ip := range cert.IPAddresses {
+		names = append(names, ip.String())
+	}
+	for _, uri := range cert.URIs {
+		names = append(names, uri.String())
+	}
+	if cert.Subject.CommonName != "" {
+		names = append(names, cert.Subject.CommonName)
+	}
+	return names
+}
@@ -0,0 +1,212 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package utils contains utility functions
+package utils
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+)
+
+const (
+	testVrfService  = "/opi_api.network.evpn_gw.v1alpha1.VrfService/"
+	testSviService  = "/opi_api.network.evpn_gw.v1alpha1.SviService/"
+	testAuthToken   = "s3cr3t"
+	testAuthCertDNS = "operator.opiproject.org"
+)
+
+var testAuthConfig = config.AuthConfig{
+	Enabled: true,
+	Clients: []config.AuthClientConfig{
+		{Name: "monitoring", Token: testAuthToken, Role: "viewer"},
+		{Cert: testAuthCertDNS, Role: "operator"},
+	},
+	Methods: []config.AuthMethodConfig{
+		{Method: testSviService + "*", Role: "admin"},
+	},
+}
+
+func tokenContext(token string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+}
+
+func certContext(cert *x509.Certificate) context.Context {
+	return peer.NewContext(context.Background(), &peer.Peer{
+		AuthInfo: credentials.TLSInfo{
+			State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}},
+		},
+	})
+}
+
+func TestNewAuthorizer(t *testing.T) {
+	tests := map[string]struct {
+		cfg       config.AuthConfig
+		expectErr bool
+	}{
+		"valid config": {
+			cfg:       testAuthConfig,
+			expectErr: false,
+		},
+		"unknown client role": {
+			cfg: config.AuthConfig{
+				Clients: []config.AuthClientConfig{{Name: "a", Token: "t", Role: "superuser"}},
+			},
+			expectErr: true,
+		},
+		"client without credentials": {
+			cfg: config.AuthConfig{
+				Clients: []config.AuthClientConfig{{Name: "a", Role: "admin"}},
+			},
+			expectErr: true,
+		},
+		"token client without name": {
+			cfg: config.AuthConfig{
+				Clients: []config.AuthClientConfig{{Token: "t", Role: "admin"}},
+			},
+			expectErr: true,
+		},
+		"malformed method pattern": {
+			cfg: config.AuthConfig{
+				Methods: []config.AuthMethodConfig{{Method: "/svc/[", Role: "admin"}},
+			},
+			expectErr: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewAuthorizer(tt.cfg)
+			if (err != nil) != tt.expectErr {
+				t.Errorf("Expected error %v, received %v", tt.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestAuthorizer_RequiredRole(t *testing.T) {
+	a, err := NewAuthorizer(testAuthConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]struct {
+		method string
+		role   Role
+	}{
+		"get":               {method: testVrfService + "GetVrf", role: RoleViewer},
+		"list":              {method: testVrfService + "ListVrfs", role: RoleViewer},
+		"create":            {method: testVrfService + "CreateVrf", role: RoleOperator},
+		"update":            {method: testVrfService + "UpdateVrf", role: RoleOperator},
+		"delete":            {method: testVrfService + "DeleteVrf", role: RoleAdmin},
+		"batch delete":      {method: "/opi_api.network.evpn_gw.v1alpha1.LogicalBridgeBatchService/BatchDeleteLogicalBridges", role: RoleAdmin},
+		"configured method": {method: testSviService + "GetSvi", role: RoleAdmin},
+		"reflection":        {method: "/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo", role: RoleViewer},
+		"audit trail":       {method: "/opi_evpn_bridge.v1.AuditService/ListAuditRecords", role: RoleAdmin},
+		"health":            {method: "/grpc.health.v1.Health/Check", role: RoleNone},
+		"log levels":        {method: "/opi_evpn_bridge.v1.LoggingService/GetLogLevels", role: RoleAdmin},
+		"config reload":     {method: "/opi_evpn_bridge.v1.ConfigService/ReloadConfig", role: RoleAdmin},
+		"netlink tables":    {method: "/opi_evpn_bridge.v1.NetlinkService/ShowNetlinkTables", role: RoleAdmin},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if role := a.requiredRole(tt.method); role != tt.role {
+				t.Errorf("Expected role %v, received %v", tt.role, role)
+			}
+		})
+	}
+}
+
+func TestAuthorizer_Authorize(t *testing.T) {
+	operatorCert := &x509.Certificate{DNSNames: []string{testAuthCertDNS}, Subject: pkix.Name{CommonName: "operator"}}
+	unknownCert := &x509.Certificate{Subject: pkix.Name{CommonName: "intruder"}}
+	tests := map[string]struct {
+		ctx      context.Context
+		method   string
+		disabled bool
+		errCode  codes.Code
+		identity string
+	}{
+		"token with enough role": {
+			ctx:      tokenContext(testAuthToken),
+			method:   testVrfService + "GetVrf",
+			errCode:  codes.OK,
+			identity: "monitoring",
+		},
+		"token without enough role": {
+			ctx:     tokenContext(testAuthToken),
+			method:  testVrfService + "CreateVrf",
+			errCode: codes.PermissionDenied,
+		},
+		"unknown token": {
+			ctx:     tokenContext("guess"),
+			method:  testVrfService + "GetVrf",
+			errCode: codes.Unauthenticated,
+		},
+		"certificate with enough role": {
+			ctx:      certContext(operatorCert),
+			method:   testVrfService + "CreateVrf",
+			errCode:  codes.OK,
+			identity: testAuthCertDNS,
+		},
+		"certificate without enough role": {
+			ctx:     certContext(operatorCert),
+			method:  testVrfService + "DeleteVrf",
+			errCode: codes.PermissionDenied,
+		},
+		"unknown certificate": {
+			ctx:     certContext(unknownCert),
+			method:  testVrfService + "GetVrf",
+			errCode: codes.PermissionDenied,
+		},
+		"no credentials": {
+			ctx:     context.Background(),
+			method:  testVrfService + "GetVrf",
+			errCode: codes.Unauthenticated,
+		},
+		"disabled authorization": {
+			ctx:      certContext(unknownCert),
+			method:   testVrfService + "DeleteVrf",
+			disabled: true,
+			errCode:  codes.OK,
+			identity: "intruder",
+		},
+		"disabled authorization without credentials": {
+			ctx:      context.Background(),
+			method:   testVrfService + "DeleteVrf",
+			disabled: true,
+			errCode:  codes.OK,
+			identity: AnonymousIdentity,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := testAuthConfig
+			cfg.Enabled = !tt.disabled
+			a, err := NewAuthorizer(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx, err := a.authorize(tt.ctx, tt.method)
+			if er := status.Code(err); er != tt.errCode {
+				t.Errorf("Expected error code %v, received %v", tt.errCode, er)
+			}
+			if err == nil && ClientIdentity(ctx) != tt.identity {
+				t.Errorf("Expected identity %v, received %v", tt.identity, ClientIdentity(ctx))
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package utils has some utility functions and interfaces
+package utils
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netlink/nl"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/logging"
+)
+
+// driftLogger is the logger of the linux subsystem
+var driftLogger = logging.Logger(logging.Linux).Sugar()
+
+// driftSettle is the time the link changes are left to settle before a check,
+// as the realization of an object changes several links in a row
+const driftSettle = time.Second
+
+// DriftDetector runs the check of the realized objects against the kernel
+// every interval and shortly after each link change
+type DriftDetector struct {
+	ctx      context.Context
+	nlink    Netlink
+	name     string
+	interval time.Duration
+	check    func()
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// NewDriftDetector creates a drift detector for the module, subscribed to the link changes through nlink
+func NewDriftDetector(ctx context.Context, nlink Netlink, name string, interval time.Duration, check func()) *DriftDetector {
+	return &DriftDetector{
+		ctx:      ctx,
+		nlink:    nlink,
+		name:     name,
+		interval: interval,
+		check:    check,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Start starts the checks in the background
+func (d *DriftDetector) Start() {
+	go d.run()
+}
+
+// Stop stops the checks and waits for the one in progress, if any
+func (d *DriftDetector) Stop() {
+	close(d.stop)
+	<-d.done
+}
+
+func (d *DriftDetector) run() {
+	defer close(d.done)
+
+	updates := make(chan netlink.LinkUpdate, 64)
+	subDone := make(chan struct{})
+	defer close(subDone)
+	err := d.nlink.LinkSubscribeWithOptions(d.ctx, updates, subDone, netlink.LinkSubscribeOptions{
+		ErrorCallback: func(err error) {
+			driftLogger.Warnw("Drift: link subscription error", "module", d.name, "error", err)
+		},
+	})
+	if err != nil {
+		driftLogger.Warnw("Drift: drift is only checked periodically, failed to subscribe to the link changes", "module", d.name, "interval", d.interval, "error", err)
+		updates = nil
+	}
+
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+	// settle is only set while link changes are pending
+	var settle <-chan time.Time
+	for {
+		select {
+		case <-d.stop:
+			return
+		case <-ticker.C:
+			d.check()
+		case _, ok := <-updates:
+			if !ok {
+				// The subscription has failed, the checks go on every interval
+				updates = nil
+				continue
+			}
+			if settle == nil {
+				settle = time.After(driftSettle)
+			}
+		case <-settle:
+			settle = nil
+			d.check()
+		}
+	}
+}
+
+// LinkDrift returns the link and how it differs from the expected one: up,
+// enslaved to the master and with the mtu, when set
+func LinkDrift(ctx context.Context, nlink Netlink, name string, master netlink.Link, mtu int) (netlink.Link, []string) {
+	link, err := nlink.LinkByName(ctx, name)
+	if err != nil {
+		return nil, []string{fmt.Sprintf("link %s not found", name)}
+	}
+	var drift []string
+	attrs := link.Attrs()
+	if attrs.Flags&net.FlagUp == 0 {
+		drift = append(drift, fmt.Sprintf("link %s is down", name))
+	}
+	if master != nil && attrs.MasterIndex != master.Attrs().Index {
+		drift = append(drift, fmt.Sprintf("link %s is not enslaved to %s", name, master.Attrs().Name))
+	}
+	if mtu != 0 && attrs.MTU != mtu {
+		drift = append(drift, fmt.Sprintf("link %s has mtu %d instead of %d", name, attrs.MTU, mtu))
+	}
+	return link, drift
+}
+
+// VlanDrift returns how the vlan of the bridge port differs from the expected one,
+// an access vlan is the untagged pvid of the port
+func VlanDrift(vlans map[int32][]*nl.BridgeVlanInfo, link netlink.Link, vid uint16, access bool) []string {
+	for _, info := range vlans[int32(link.Attrs().Index)] {
+		if info.Vid != vid {
+			continue
+		}
+		if access && (!info.PortVID() || !info.EngressUntag()) {
+			return []string{fmt.Sprintf("vlan %d of link %s is not the untagged pvid", vid, link.Attrs().Name)}
+		}
+		return nil
+	}
+	return []string{fmt.Sprintf("vlan %d not found on link %s", vid, link.Attrs().Name)}
+}
@@ -0,0 +1,124 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// The mocks import utils, so the tests using them are external
+package utils_test
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netlink/nl"
+	"golang.org/x/sys/unix"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils/mocks"
+)
+
+func TestLinkDrift(t *testing.T) {
+	bridge := &netlink.Bridge{LinkAttrs: netlink.LinkAttrs{Name: "br-tenant", Index: 2}}
+	tests := map[string]struct {
+		link  netlink.Link
+		err   error
+		drift []string
+	}{
+		"as expected": {
+			link: &netlink.Vxlan{LinkAttrs: netlink.LinkAttrs{Name: "vxlan-10", Flags: net.FlagUp, MasterIndex: 2, MTU: 1500}},
+		},
+		"not found": {
+			err:   unix.ENODEV,
+			drift: []string{"link vxlan-10 not found"},
+		},
+		"down, detached and other mtu": {
+			link: &netlink.Vxlan{LinkAttrs: netlink.LinkAttrs{Name: "vxlan-10", MTU: 1400}},
+			drift: []string{
+				"link vxlan-10 is down",
+				"link vxlan-10 is not enslaved to br-tenant",
+				"link vxlan-10 has mtu 1400 instead of 1500",
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockNetlink := mocks.NewNetlink(t)
+			mockNetlink.EXPECT().LinkByName(mock.Anything, "vxlan-10").Return(tt.link, tt.err).Once()
+
+			link, drift := utils.LinkDrift(context.Background(), mockNetlink, "vxlan-10", bridge, 1500)
+			assert.Equal(t, tt.drift, drift)
+			if tt.err == nil {
+				assert.Equal(t, tt.link, link)
+			}
+		})
+	}
+}
+
+func TestVlanDrift(t *testing.T) {
+	link := &netlink.Vxlan{LinkAttrs: netlink.LinkAttrs{Name: "vxlan-10", Index: 5}}
+	tagged := &nl.BridgeVlanInfo{Vid: 10}
+	untagged := &nl.BridgeVlanInfo{Vid: 10, Flags: nl.BRIDGE_VLAN_INFO_PVID | nl.BRIDGE_VLAN_INFO_UNTAGGED}
+	tests := map[string]struct {
+		vlans  map[int32][]*nl.BridgeVlanInfo
+		access bool
+		drift  []string
+	}{
+		"trunk":           {vlans: map[int32][]*nl.BridgeVlanInfo{5: {tagged}}},
+		"access":          {vlans: map[int32][]*nl.BridgeVlanInfo{5: {untagged}}, access: true},
+		"access tagged":   {vlans: map[int32][]*nl.BridgeVlanInfo{5: {tagged}}, access: true, drift: []string{"vlan 10 of link vxlan-10 is not the untagged pvid"}},
+		"missing":         {vlans: map[int32][]*nl.BridgeVlanInfo{5: {{Vid: 20}}}, drift: []string{"vlan 10 not found on link vxlan-10"}},
+		"on another link": {vlans: map[int32][]*nl.BridgeVlanInfo{6: {tagged}}, drift: []string{"vlan 10 not found on link vxlan-10"}},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.drift, utils.VlanDrift(tt.vlans, link, 10, tt.access))
+		})
+	}
+}
+
+func TestDriftDetector(t *testing.T) {
+	var checks atomic.Int64
+	mockNetlink := mocks.NewNetlink(t)
+	// The checks go on every interval without the link changes
+	mockNetlink.EXPECT().LinkSubscribeWithOptions(mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(unix.EPERM).Once()
+	d := utils.NewDriftDetector(context.Background(), mockNetlink, "test", 10*time.Millisecond, func() { checks.Add(1) })
+	d.Start()
+	deadline := time.Now().Add(5 * time.Second)
+	for checks.Load() < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	d.Stop()
+	assert.GreaterOrEqual(t, checks.Load(), int64(2))
+
+	stopped := checks.Load()
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, stopped, checks.Load(), "no check is expected once stopped")
+}
+
+func TestDriftDetectorLinkChanges(t *testing.T) {
+	var checks atomic.Int64
+	updates := make(chan chan<- netlink.LinkUpdate, 1)
+	mockNetlink := mocks.NewNetlink(t)
+	mockNetlink.EXPECT().LinkSubscribeWithOptions(mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Run(func(_ context.Context, ch chan<- netlink.LinkUpdate, _ <-chan struct{}, _ netlink.LinkSubscribeOptions) {
+			updates <- ch
+		}).Return(nil).Once()
+	d := utils.NewDriftDetector(context.Background(), mockNetlink, "test", time.Hour, func() { checks.Add(1) })
+	d.Start()
+	defer d.Stop()
+
+	// A link change is checked once settled, long before the interval
+	(<-updates) <- netlink.LinkUpdate{}
+	deadline := time.Now().Add(5 * time.Second)
+	for checks.Load() < 1 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, int64(1), checks.Load())
+}
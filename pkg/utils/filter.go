@@ -0,0 +1,509 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package utils has some utility functions and interfaces
+package utils
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"go.einride.tech/aip/filtering"
+	"go.einride.tech/aip/ordering"
+	expr "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+const (
+	// FilterMetadataKey is the gRPC metadata key that carries the AIP-160 filter of a List request.
+	// The evpn-gw List request messages have no filter field so the filter travels as metadata,
+	// which the HTTP gateway also forwards from the "Grpc-Metadata-X-Opi-Filter" header.
+	FilterMetadataKey = "x-opi-filter"
+	// OrderByMetadataKey is the gRPC metadata key that carries the AIP-132 order_by of a List request
+	OrderByMetadataKey = "x-opi-order-by"
+
+	// maxFilterFieldDepth limits how deep nested messages are exposed to filters
+	maxFilterFieldDepth = 4
+)
+
+// ExtractListOptions fetches the filter and order_by of a List request from the incoming metadata
+func ExtractListOptions(ctx context.Context) (filter string, orderBy string) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", ""
+	}
+	if values := md.Get(FilterMetadataKey); len(values) > 0 {
+		filter = strings.TrimSpace(values[0])
+	}
+	if values := md.Get(OrderByMetadataKey); len(values) > 0 {
+		orderBy = strings.TrimSpace(values[0])
+	}
+	return filter, orderBy
+}
+
+// FilterAndOrder returns the objects that match the AIP-160 filter sorted per the AIP-132 order_by.
+// Field paths use the protobuf field names, e.g. `spec.vni = 1000 AND status.oper_status = SVI_OPER_STATUS_UP`.
+// Repeated fields match when any of their elements matches, e.g. `status.components.status = COMP_STATUS_ERROR`.
+// The name is always used as the last sort key, so the result is stable between calls.
+func FilterAndOrder[T proto.Message](objects []T, filter string, orderBy string) ([]T, error) {
+	result, _, err := filterAndOrder(objects, filter, orderBy)
+	return result, err
+}
+
+// filterAndOrder filters and sorts the objects and returns the sort fields that have been used
+func filterAndOrder[T proto.Message](objects []T, filter string, orderBy string) ([]T, []ordering.Field, error) {
+	var zero T
+	desc := zero.ProtoReflect().Descriptor()
+
+	checked, declarations, err := parseFilter(desc, filter)
+	if err != nil {
+		return nil, nil, status.Errorf(codes.InvalidArgument, "invalid filter %q: %v", filter, err)
+	}
+
+	order := ordering.OrderBy{}
+	if err := order.UnmarshalString(orderBy); err != nil {
+		return nil, nil, status.Errorf(codes.InvalidArgument, "invalid order_by %q: %v", orderBy, err)
+	}
+	if err := order.ValidateForMessage(zero); err != nil {
+		return nil, nil, status.Errorf(codes.InvalidArgument, "invalid order_by %q: %v", orderBy, err)
+	}
+
+	result := make([]T, 0, len(objects))
+	for _, obj := range objects {
+		if checked != nil {
+			match, err := evalBool(checked, obj.ProtoReflect(), declarations)
+			if err != nil {
+				return nil, nil, status.Errorf(codes.InvalidArgument, "invalid filter %q: %v", filter, err)
+			}
+			if !match {
+				continue
+			}
+		}
+		result = append(result, obj)
+	}
+
+	fields := append(order.Fields, ordering.Field{Path: "name"})
+	sort.SliceStable(result, func(i, j int) bool {
+		return compareKeys(sortKey(result[i], fields), sortKey(result[j], fields), fields) < 0
+	})
+
+	return result, fields, nil
+}
+
+// sortKey returns the values of the sort fields of an object
+func sortKey[T proto.Message](obj T, fields []ordering.Field) []interface{} {
+	key := make([]interface{}, 0, len(fields))
+	for _, field := range fields {
+		key = append(key, firstValue(fieldValues(obj.ProtoReflect(), field.Path)))
+	}
+	return key
+}
+
+// compareKeys orders two sort keys honoring the direction of each sort field
+func compareKeys(x, y []interface{}, fields []ordering.Field) int {
+	for i, field := range fields {
+		if i >= len(x) || i >= len(y) {
+			break
+		}
+		c := compareValues(x[i], y[i])
+		if c == 0 {
+			continue
+		}
+		if field.Desc {
+			return -c
+		}
+		return c
+	}
+	return 0
+}
+
+// parseFilter parses and type checks the filter against the fields of the message
+func parseFilter(desc protoreflect.MessageDescriptor, filter string) (*expr.Expr, *filtering.Declarations, error) {
+	if filter == "" {
+		return nil, nil, nil
+	}
+
+	opts := []filtering.DeclarationOption{filtering.DeclareStandardFunctions()}
+	opts = append(opts, declareFields(desc, "", 0)...)
+	declarations, err := filtering.NewDeclarations(opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var parser filtering.Parser
+	parser.Init(filter)
+	parsed, err := parser.Parse()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var checker filtering.Checker
+	checker.Init(parsed.Expr, parsed.SourceInfo, declarations)
+	checked, err := checker.Check()
+	if err != nil {
+		return nil, nil, err
+	}
+	return checked.Expr, declarations, nil
+}
+
+// declareFields declares an ident for every scalar field path of the message
+func declareFields(desc protoreflect.MessageDescriptor, prefix string, depth int) []filtering.DeclarationOption {
+	opts := []filtering.DeclarationOption{}
+	if depth >= maxFilterFieldDepth {
+		return opts
+	}
+
+	fields := desc.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+		name := prefix + string(field.Name())
+		if field.IsMap() {
+			continue
+		}
+
+		switch field.Kind() {
+		case protoreflect.MessageKind, protoreflect.GroupKind:
+			opts = append(opts, declareFields(field.Message(), name+".", depth+1)...)
+		case protoreflect.EnumKind:
+			enumType, err := protoregistry.GlobalTypes.FindEnumByName(field.Enum().FullName())
+			if err == nil {
+				opts = append(opts, filtering.DeclareEnumIdent(name, enumType))
+			}
+		case protoreflect.BoolKind:
+			opts = append(opts, filtering.DeclareIdent(name, filtering.TypeBool))
+		case protoreflect.StringKind:
+			opts = append(opts, filtering.DeclareIdent(name, filtering.TypeString))
+		case protoreflect.FloatKind, protoreflect.DoubleKind:
+			opts = append(opts, filtering.DeclareIdent(name, filtering.TypeFloat))
+		case protoreflect.BytesKind:
+		default:
+			opts = append(opts, filtering.DeclareIdent(name, filtering.TypeInt))
+		}
+	}
+	return opts
+}
+
+// evalBool evaluates a type checked boolean filter expression against a message
+func evalBool(e *expr.Expr, msg protoreflect.Message, declarations *filtering.Declarations) (bool, error) {
+	switch kind := e.ExprKind.(type) {
+	case *expr.Expr_ConstExpr:
+		return kind.ConstExpr.GetBoolValue(), nil
+	case *expr.Expr_IdentExpr, *expr.Expr_SelectExpr:
+		values, err := evalValues(e, msg, declarations)
+		if err != nil {
+			return false, err
+		}
+		for _, v := range values {
+			if b, ok := v.(bool); ok && b {
+				return true, nil
+			}
+		}
+		return false, nil
+	case *expr.Expr_CallExpr:
+		return evalCall(kind.CallExpr, msg, declarations)
+	default:
+		return false, fmt.Errorf("unsupported expression %v", e)
+	}
+}
+
+// evalCall evaluates a logical or comparison function call
+func evalCall(call *expr.Expr_Call, msg protoreflect.Message, declarations *filtering.Declarations) (bool, error) {
+	args := call.GetArgs()
+
+	switch call.GetFunction() {
+	case filtering.FunctionAnd, filtering.FunctionFuzzyAnd:
+		for _, arg := range args {
+			match, err := evalBool(arg, msg, declarations)
+			if err != nil || !match {
+				return false, err
+			}
+		}
+		return true, nil
+	case filtering.FunctionOr:
+		for _, arg := range args {
+			match, err := evalBool(arg, msg, declarations)
+			if err != nil || match {
+				return match, err
+			}
+		}
+		return false, nil
+	case filtering.FunctionNot:
+		if len(args) != 1 {
+			return false, fmt.Errorf("NOT expects one argument")
+		}
+		match, err := evalBool(args[0], msg, declarations)
+		return !match, err
+	}
+
+	if len(args) != 2 {
+		return false, fmt.Errorf("function %s expects two arguments", call.GetFunction())
+	}
+	lhs, err := evalValues(args[0], msg, declarations)
+	if err != nil {
+		return false, err
+	}
+	rhs, err := evalValues(args[1], msg, declarations)
+	if err != nil {
+		return false, err
+	}
+
+	// Repeated fields match when any of their elements matches
+	for _, x := range lhs {
+		for _, y := range rhs {
+			match, err := compareWith(call.GetFunction(), x, y)
+			if err != nil {
+				return false, err
+			}
+			if match {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// compareWith applies a comparison function on two values
+func compareWith(function string, x, y interface{}) (bool, error) {
+	switch function {
+	case filtering.FunctionEquals:
+		return matchString(x, y), nil
+	case filtering.FunctionNotEquals:
+		return !matchString(x, y), nil
+	case filtering.FunctionHas:
+		if s, ok := y.(string); ok && s == "*" {
+			return true, nil
+		}
+		return matchString(x, y), nil
+	case filtering.FunctionLessThan:
+		return compareValues(x, y) < 0, nil
+	case filtering.FunctionLessEquals:
+		return compareValues(x, y) <= 0, nil
+	case filtering.FunctionGreaterThan:
+		return compareValues(x, y) > 0, nil
+	case filtering.FunctionGreaterEquals:
+		return compareValues(x, y) >= 0, nil
+	default:
+		return false, fmt.Errorf("unsupported function %s", function)
+	}
+}
+
+// matchString checks two values for equality, strings may contain "*" wildcards
+func matchString(x, y interface{}) bool {
+	xs, xok := x.(string)
+	ys, yok := y.(string)
+	if xok && yok && strings.Contains(ys, "*") {
+		match, err := path.Match(ys, xs)
+		return err == nil && match
+	}
+	return compareValues(x, y) == 0
+}
+
+// evalValues returns the values of a constant, enum value or field path
+func evalValues(e *expr.Expr, msg protoreflect.Message, declarations *filtering.Declarations) ([]interface{}, error) {
+	switch kind := e.ExprKind.(type) {
+	case *expr.Expr_ConstExpr:
+		return []interface{}{constValue(kind.ConstExpr)}, nil
+	case *expr.Expr_IdentExpr, *expr.Expr_SelectExpr:
+		name, ok := qualifiedName(e)
+		if !ok {
+			return nil, fmt.Errorf("unsupported field expression %v", e)
+		}
+		if decl, ok := declarations.LookupIdent(name); ok && decl.GetIdent().GetValue() != nil {
+			// Enum values are declared as string constants
+			return []interface{}{constValue(decl.GetIdent().GetValue())}, nil
+		}
+		return fieldValues(msg, name), nil
+	default:
+		return nil, fmt.Errorf("unsupported expression %v", e)
+	}
+}
+
+// qualifiedName returns the dotted field path of an ident or select expression
+func qualifiedName(e *expr.Expr) (string, bool) {
+	switch kind := e.ExprKind.(type) {
+	case *expr.Expr_IdentExpr:
+		return kind.IdentExpr.GetName(), true
+	case *expr.Expr_SelectExpr:
+		parent, ok := qualifiedName(kind.SelectExpr.GetOperand())
+		if !ok {
+			return "", false
+		}
+		return parent + "." + kind.SelectExpr.GetField(), true
+	default:
+		return "", false
+	}
+}
+
+// constValue converts a constant to a comparable value
+func constValue(c *expr.Constant) interface{} {
+	switch kind := c.ConstantKind.(type) {
+	case *expr.Constant_BoolValue:
+		return kind.BoolValue
+	case *expr.Constant_Int64Value:
+		return kind.Int64Value
+	case *expr.Constant_Uint64Value:
+		return int64(kind.Uint64Value)
+	case *expr.Constant_DoubleValue:
+		return kind.DoubleValue
+	case *expr.Constant_StringValue:
+		return kind.StringValue
+	default:
+		return nil
+	}
+}
+
+// fieldValues returns all the values found on a dotted field path,
+// walking through repeated fields and skipping unset messages
+func fieldValues(msg protoreflect.Message, fieldPath string) []interface{} {
+	name, rest, nested := strings.Cut(fieldPath, ".")
+	field := msg.Descriptor().Fields().ByName(protoreflect.Name(name))
+	if field == nil || field.IsMap() {
+		return nil
+	}
+
+	if field.Kind() == protoreflect.MessageKind || field.Kind() == protoreflect.GroupKind {
+		if !nested || !msg.Has(field) {
+			return nil
+		}
+		if field.IsList() {
+			values := []interface{}{}
+			list := msg.Get(field).List()
+			for i := 0; i < list.Len(); i++ {
+				values = append(values, fieldValues(list.Get(i).Message(), rest)...)
+			}
+			return values
+		}
+		return fieldValues(msg.Get(field).Message(), rest)
+	}
+
+	if nested {
+		return nil
+	}
+	if field.IsList() {
+		values := []interface{}{}
+		list := msg.Get(field).List()
+		for i := 0; i < list.Len(); i++ {
+			values = append(values, scalarValue(field, list.Get(i)))
+		}
+		return values
+	}
+	if field.HasPresence() && !msg.Has(field) {
+		return nil
+	}
+	return []interface{}{scalarValue(field, msg.Get(field))}
+}
+
+// scalarValue converts a protobuf scalar to a comparable value
+func scalarValue(field protoreflect.FieldDescriptor, v protoreflect.Value) interface{} {
+	switch field.Kind() {
+	case protoreflect.BoolKind:
+		return v.Bool()
+	case protoreflect.StringKind:
+		return v.String()
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return v.Float()
+	case protoreflect.EnumKind:
+		value := enumValue{number: int64(v.Enum())}
+		if desc := field.Enum().Values().ByNumber(v.Enum()); desc != nil {
+			value.name = string(desc.Name())
+		}
+		return value
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return int64(v.Uint())
+	case protoreflect.BytesKind:
+		return string(v.Bytes())
+	default:
+		return v.Int()
+	}
+}
+
+// enumValue keeps both the name of an enum value, which filters compare
+// against, and its number, which orders the values as declared
+type enumValue struct {
+	name   string
+	number int64
+}
+
+// firstValue returns the first value of a field or nil when the field is unset
+func firstValue(values []interface{}) interface{} {
+	if len(values) == 0 {
+		return nil
+	}
+	return values[0]
+}
+
+// compareValues orders two values, unset values sort first
+// nolint: gocognit
+func compareValues(x, y interface{}) int {
+	switch {
+	case x == nil && y == nil:
+		return 0
+	case x == nil:
+		return -1
+	case y == nil:
+		return 1
+	}
+
+	switch xv := x.(type) {
+	case enumValue:
+		switch yv := y.(type) {
+		case enumValue:
+			return compareNumbers(xv.number, yv.number)
+		case string:
+			return strings.Compare(xv.name, yv)
+		}
+	case string:
+		switch yv := y.(type) {
+		case string:
+			return strings.Compare(xv, yv)
+		case enumValue:
+			return strings.Compare(xv, yv.name)
+		}
+	case bool:
+		if yv, ok := y.(bool); ok {
+			switch {
+			case xv == yv:
+				return 0
+			case !xv:
+				return -1
+			default:
+				return 1
+			}
+		}
+	case int64:
+		switch yv := y.(type) {
+		case int64:
+			return compareNumbers(xv, yv)
+		case float64:
+			return compareNumbers(float64(xv), yv)
+		}
+	case float64:
+		switch yv := y.(type) {
+		case int64:
+			return compareNumbers(xv, float64(yv))
+		case float64:
+			return compareNumbers(xv, yv)
+		}
+	}
+	return strings.Compare(fmt.Sprint(x), fmt.Sprint(y))
+}
+
+// compareNumbers orders two numbers
+func compareNumbers[N int64 | float64](x, y N) int {
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	default:
+		return 0
+	}
+}
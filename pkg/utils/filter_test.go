@@ -0,0 +1,98 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package utils has some utility functions and interfaces
+package utils
+
+import (
+	"testing"
+
+	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func newTestLB(name string, vlan uint32, vni *uint32, operStatus pb.LBOperStatus) *pb.LogicalBridge {
+	return &pb.LogicalBridge{
+		Name:   name,
+		Spec:   &pb.LogicalBridgeSpec{VlanId: vlan, Vni: vni},
+		Status: &pb.LogicalBridgeStatus{OperStatus: operStatus},
+	}
+}
+
+func lbNames(lbs []*pb.LogicalBridge) []string {
+	names := []string{}
+	for _, lb := range lbs {
+		names = append(names, lb.Name)
+	}
+	return names
+}
+
+func TestFilterAndOrder(t *testing.T) {
+	vni := uint32(1000)
+	lbs := []*pb.LogicalBridge{
+		newTestLB("lb-c", 30, nil, pb.LBOperStatus_LB_OPER_STATUS_UP),
+		newTestLB("lb-a", 10, &vni, pb.LBOperStatus_LB_OPER_STATUS_DOWN),
+		newTestLB("lb-b", 20, nil, pb.LBOperStatus_LB_OPER_STATUS_UP),
+	}
+
+	tests := map[string]struct {
+		filter  string
+		orderBy string
+		out     []string
+		errCode codes.Code
+	}{
+		"no filter sorts by name": {
+			out: []string{"lb-a", "lb-b", "lb-c"},
+		},
+		"enum equality": {
+			filter: "status.oper_status = LB_OPER_STATUS_UP",
+			out:    []string{"lb-b", "lb-c"},
+		},
+		"numeric comparison": {
+			filter: "spec.vlan_id >= 20",
+			out:    []string{"lb-b", "lb-c"},
+		},
+		"unset optional field does not match": {
+			filter: "spec.vni = 1000",
+			out:    []string{"lb-a"},
+		},
+		"or and not": {
+			filter: "spec.vlan_id = 10 OR NOT status.oper_status = LB_OPER_STATUS_UP",
+			out:    []string{"lb-a"},
+		},
+		"string wildcard": {
+			filter: `name = "lb-*" AND spec.vlan_id < 30`,
+			out:    []string{"lb-a", "lb-b"},
+		},
+		"order by descending": {
+			orderBy: "spec.vlan_id desc",
+			out:     []string{"lb-c", "lb-b", "lb-a"},
+		},
+		"order by with name tie-break": {
+			orderBy: "status.oper_status desc",
+			out:     []string{"lb-a", "lb-b", "lb-c"},
+		},
+		"invalid filter": {
+			filter:  "spec.vlan_id = ",
+			errCode: codes.InvalidArgument,
+		},
+		"invalid order by": {
+			orderBy: "spec.unknown",
+			errCode: codes.InvalidArgument,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			result, err := FilterAndOrder(lbs, tt.filter, tt.orderBy)
+			if tt.errCode != codes.OK {
+				assert.Equal(t, tt.errCode, status.Code(err))
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.out, lbNames(result))
+		})
+	}
+}
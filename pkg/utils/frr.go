@@ -8,12 +8,15 @@ import (
 	"bufio"
 	"context"
 	"fmt"
+	"net"
 	"os/exec"
 	"strings"
 	"time"
 
 	"github.com/ziutek/telnet"
 
+	"github.com/opiproject/opi-evpn-bridge/pkg/metrics"
+
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
@@ -137,27 +140,44 @@ func checkFrrResult(data string, show bool) bool {
 // FrrZebraCmd connects to Zebra telnet with password and runs command
 func (n *FrrWrapper) FrrZebraCmd(ctx context.Context, command string, cmdTypeShow bool) (string, error) {
 	// ports defined here https://docs.frrouting.org/en/latest/setup.html#services
-	cmdOutput, cmdError := n.TelnetDialAndCommunicate(ctx, command, zebra)
-	if cmdError != nil {
-		return "", cmdError
-	} else if checkFrrResult(cmdOutput, cmdTypeShow) {
-		return "", fmt.Errorf("%s", cmdOutput)
-	}
-	return cmdOutput, cmdError
+	return n.daemonCmd(ctx, "zebra", zebra, command, cmdTypeShow)
 }
 
 // FrrBgpCmd connects to Bgp telnet with password and runs command
 func (n *FrrWrapper) FrrBgpCmd(ctx context.Context, command string, cmdTypeShow bool) (string, error) {
 	// ports defined here https://docs.frrouting.org/en/latest/setup.html#services
-	cmdOutput, cmdError := n.TelnetDialAndCommunicate(ctx, command, bgpd)
+	return n.daemonCmd(ctx, "bgpd", bgpd, command, cmdTypeShow)
+}
+
+// daemonCmd runs the command on the daemon listening on the port and records its latency and failures
+func (n *FrrWrapper) daemonCmd(ctx context.Context, daemon string, port int, command string, cmdTypeShow bool) (string, error) {
+	start := time.Now()
+	defer func() { metrics.FrrCommandLatency.WithLabelValues(daemon).Observe(metrics.Since(start)) }()
+
+	cmdOutput, cmdError := n.TelnetDialAndCommunicate(ctx, command, port)
 	if cmdError != nil {
+		metrics.FrrCommandFailures.WithLabelValues(daemon).Inc()
 		return "", cmdError
 	} else if checkFrrResult(cmdOutput, cmdTypeShow) {
+		metrics.FrrCommandFailures.WithLabelValues(daemon).Inc()
 		return "", fmt.Errorf("%s", cmdOutput)
 	}
 	return cmdOutput, cmdError
 }
 
+// Ping checks that the vty ports of the zebra and bgpd daemons accept connections
+func (n *FrrWrapper) Ping(ctx context.Context) error {
+	dialer := &net.Dialer{Timeout: timeout}
+	for _, port := range []int{zebra, bgpd} {
+		conn, err := dialer.DialContext(ctx, network, fmt.Sprintf("%s:%d", n.address, port))
+		if err != nil {
+			return err
+		}
+		_ = conn.Close()
+	}
+	return nil
+}
+
 // Save command save the current config to /etc/frr/frr.conf
 func (n *FrrWrapper) Save(ctx context.Context) error {
 	cmd := exec.CommandContext(ctx, "vtysh", "-c", "write")
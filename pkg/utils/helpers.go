@@ -6,7 +6,6 @@ package utils
 
 import (
 	"context"
-	"log"
 	"net"
 	"os/exec"
 	"regexp"
@@ -15,8 +14,13 @@ import (
 	"go.einride.tech/aip/fieldmask"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/fieldmaskpb"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/logging"
 )
 
+// logger is the logger of the grpc subsystem
+var logger = logging.Logger(logging.Grpc).Sugar()
+
 // ApplyMaskToStoredPbObject updates the stored PB object with the one provided
 // in the update grpc request based on the provided field mask
 func ApplyMaskToStoredPbObject[T proto.Message](updateMask *fieldmaskpb.FieldMask, dst, src T) {
@@ -52,7 +56,7 @@ func GetIPAddress(dev string) net.IPNet {
 	ctx := context.Background()
 	link, err := nlink.LinkByName(ctx, dev)
 	if err != nil {
-		log.Printf("Error in LinkByName %+v\n", err)
+		logger.Errorw("Error in LinkByName", "error", err)
 		return net.IPNet{
 			IP: net.ParseIP("0.0.0.0"),
 		}
@@ -60,7 +64,7 @@ func GetIPAddress(dev string) net.IPNet {
 
 	addrs, err := nlink.AddrList(ctx, link, netlink.FAMILY_V4) // ip address show
 	if err != nil {
-		log.Printf("Error in AddrList\n")
+		logger.Errorw("Error in AddrList")
 		return net.IPNet{
 			IP: net.ParseIP("0.0.0.0"),
 		}
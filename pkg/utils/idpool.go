@@ -7,9 +7,13 @@ package utils
 
 import (
 	"fmt"
-	"log"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/logging"
 )
 
+// idpoolLogger is the logger of the linux subsystem
+var idpoolLogger = logging.Logger(logging.Linux).Sugar()
+
 // IDPool structure
 /*  Helper class for uniquely assigning IDs from a specified integer set (e.g. a
 #   range) to keys. IDs are assigned (or read) with GetID(key) and returned back
@@ -32,13 +36,29 @@ type IDPool struct {
 	idsInUse    map[interface{}]uint32 // Mapping key: id for currently assigned ids
 	idsForReuse map[interface{}]uint32 // Mapping key: id for previously assigned ids
 	refs        map[uint32]map[interface{}]bool
-	size        int // Size of the pool
+	size        int         // Size of the pool
+	store       IDPoolStore // Optional store the assignments are persisted to
+	dirty       bool        // The assignments have changed since they were last persisted
+}
+
+// IDPoolStore persists the assignments of the pools, it is implemented by the infra db
+type IDPoolStore interface {
+	Set(key string, value interface{}) error
+	Get(key string, value interface{}) (bool, error)
+}
+
+// idPoolState is the persisted state of a pool.
+// Only the string keys and references are persisted.
+type idPoolState struct {
+	InUse    map[string]uint32   `json:"inuse"`
+	ForReuse map[string]uint32   `json:"forreuse"`
+	Refs     map[uint32][]string `json:"refs"`
 }
 
 // IDPoolInit initialize mod ptr pool
 func IDPoolInit(name string, min uint32, max uint32) (IDPool, bool) {
 	if max < min {
-		log.Printf("IDPool: Failed to Init pool for %s\n", name)
+		idpoolLogger.Errorw("IDPool: Failed to Init pool", "pool", name)
 		return IDPool{}, false
 	}
 	var pool IDPool
@@ -56,6 +76,129 @@ func IDPoolInit(name string, min uint32, max uint32) (IDPool, bool) {
 	return pool, true
 }
 
+// storeKey is the key the state of the pool is persisted under
+func (ip *IDPool) storeKey() string {
+	return "idpool/" + ip.name
+}
+
+// Persist restores the assignments and references previously persisted for the pool
+// and persists them to the store on each Flush from now on.
+// The keys and references of a persisted pool must be strings.
+func (ip *IDPool) Persist(store IDPoolStore) error {
+	state := idPoolState{}
+	found, err := store.Get(ip.storeKey(), &state)
+	if err != nil {
+		return fmt.Errorf("failed to restore pool %s: %w", ip.name, err)
+	}
+	if found {
+		ip.restore(&state)
+	}
+	ip.store = store
+	ip.changed()
+	return ip.Flush()
+}
+
+// restore applies the persisted state, the ids out of the pool or in use by another key are dropped
+func (ip *IDPool) restore(state *idPoolState) {
+	for key, id := range state.InUse {
+		if !ip.ReserveID(key, id) {
+			continue
+		}
+		for _, ref := range state.Refs[id] {
+			if ip.refs[id] == nil {
+				ip.refs[id] = make(map[interface{}]bool)
+			}
+			ip.refs[id][ref] = true
+		}
+	}
+	for key, id := range state.ForReuse {
+		if _, ok := ip.idsInUse[key]; ok {
+			continue
+		}
+		for i, v := range ip.unusedIDs {
+			if v == id {
+				ip.unusedIDs = append(ip.unusedIDs[:i], ip.unusedIDs[i+1:]...)
+				ip.idsForReuse[key] = id
+				break
+			}
+		}
+	}
+	idpoolLogger.Infow("IDPool: Restored the ids", "pool", ip.name, "inUse", len(ip.idsInUse), "forReuse", len(ip.idsForReuse))
+}
+
+// changed records that the assignments have to be persisted by the next Flush
+func (ip *IDPool) changed() {
+	ip.dirty = ip.store != nil
+}
+
+// Flush persists the assignments changed since the last Flush, if the pool has a store.
+// The owner of the pool flushes it once for a batch of changes, e.g. at the end of an event.
+func (ip *IDPool) Flush() error {
+	if !ip.dirty {
+		return nil
+	}
+	state := idPoolState{
+		InUse:    make(map[string]uint32, len(ip.idsInUse)),
+		ForReuse: make(map[string]uint32, len(ip.idsForReuse)),
+		Refs:     make(map[uint32][]string, len(ip.refs)),
+	}
+	for key, id := range ip.idsInUse {
+		if k, ok := key.(string); ok {
+			state.InUse[k] = id
+		}
+	}
+	for key, id := range ip.idsForReuse {
+		if k, ok := key.(string); ok {
+			state.ForReuse[k] = id
+		}
+	}
+	for id, refs := range ip.refs {
+		for ref := range refs {
+			if r, ok := ref.(string); ok {
+				state.Refs[id] = append(state.Refs[id], r)
+			}
+		}
+	}
+	if err := ip.store.Set(ip.storeKey(), state); err != nil {
+		idpoolLogger.Errorw("IDPool: Failed to persist pool", "pool", ip.name, "error", err)
+		return fmt.Errorf("failed to persist pool %s: %w", ip.name, err)
+	}
+	ip.dirty = false
+	return nil
+}
+
+// CheckConsistency aligns the pool with the ids actually in use, e.g. in the kernel.
+// An id in use for a key takes precedence over the assignment of the pool,
+// the key the pool had assigned the id to loses it. It returns the inconsistencies that have been fixed.
+func (ip *IDPool) CheckConsistency(inUse map[string]uint32) []string {
+	var fixed []string
+	for key, id := range inUse {
+		if current, ok := ip.idsInUse[key]; ok && current == id {
+			continue
+		}
+		for other, otherID := range ip.idsInUse {
+			if otherID == id {
+				fixed = append(fixed, fmt.Sprintf("id %d is in use by %v instead of %v", id, key, other))
+				delete(ip.idsInUse, other)
+				delete(ip.refs, id)
+				ip.unusedIDs = append(ip.unusedIDs, id)
+				break
+			}
+		}
+		if current, ok := ip.idsInUse[key]; ok {
+			fixed = append(fixed, fmt.Sprintf("%v uses id %d instead of %d", key, id, current))
+		}
+		if !ip.ReserveID(key, id) {
+			fixed = append(fixed, fmt.Sprintf("%v cannot be assigned id %d", key, id))
+		}
+	}
+	if len(fixed) != 0 {
+		idpoolLogger.Warnw("IDPool: Fixed the inconsistencies of pool", "pool", ip.name, "fixed", fixed)
+		ip.changed()
+	}
+	return fixed
+}
+
 // GetPoolStatus get status of a pool
 func (ip *IDPool) GetPoolStatus() string {
 	str := fmt.Sprintf("name=%s\n Inuse=%+v\n Refs=%+v\n Forreuse=%+v\n Unused=%+v\n ", ip.name, ip.idsInUse, ip.refs, ip.idsForReuse, ip.unusedIDs)
@@ -65,8 +208,9 @@ func (ip *IDPool) GetPoolStatus() string {
 func (ip *IDPool) assignid(key interface{}) uint32 {
 	// Check if there was an id assigned for that key earlier
 	var id uint32
-	if _, ok := ip.idsForReuse[key]; ok {
+	if oldID, ok := ip.idsForReuse[key]; ok {
 		// Re-use the old id
+		id = oldID
 		delete(ip.idsForReuse, key)
 	} else {
 		if len(ip.unusedIDs) != 0 {
@@ -76,12 +220,13 @@ func (ip *IDPool) assignid(key interface{}) uint32 {
 		} else {
 			if len(ip.idsForReuse) != 0 {
 				// Pick one of the ids earlier used for another key
-				for oldKey := range ip.idsForReuse {
+				for oldKey, oldID := range ip.idsForReuse {
+					id = oldID
 					delete(ip.idsForReuse, oldKey)
 					break
 				}
 			} else {
-				log.Printf("IDPool: Failed to allocate id for %+v. No free ids in pool.", key)
+				idpoolLogger.Errorw("IDPool: Failed to allocate id, no free ids in pool", "pool", ip.name, "key", key)
 				return 0
 			}
 		}
@@ -89,6 +234,7 @@ func (ip *IDPool) assignid(key interface{}) uint32 {
 	// Store the assigned id, if any
 	if id != 0 {
 		ip.idsInUse[key] = id
+		ip.changed()
 	}
 	return id
 }
@@ -99,14 +245,65 @@ func (ip *IDPool) GetID(key interface{}) uint32 {
 	var id uint32
 	if id, ok = ip.idsInUse[key]; !ok {
 		if id = ip.assignid(key); id == 0 {
-			log.Printf("IDPool: GetID Assigning failed id %v for key %v ", id, key)
+			idpoolLogger.Errorw("IDPool: GetID Assigning failed", "pool", ip.name, "id", id, "key", key)
 			return 0
 		}
-		log.Printf("IDPool: GetID Assigning id %v for key %v ", id, key)
+		idpoolLogger.Infow("IDPool: GetID Assigning id", "pool", ip.name, "id", id, "key", key)
 	}
 	return id
 }
 
+// ReserveID assigns a specific id to the key, e.g. an id found in use at startup.
+// The id must be part of the pool and not be in use by another key.
+// Any other id assigned to the key is released back into the pool.
+func (ip *IDPool) ReserveID(key interface{}, id uint32) bool {
+	if current, ok := ip.idsInUse[key]; ok {
+		if current == id {
+			return true
+		}
+		if ip.refs[current] != nil {
+			idpoolLogger.Errorw("IDPool: ReserveID failed, the id of the key has references", "pool", ip.name, "id", id, "key", key, "current", current)
+			return false
+		}
+	}
+	for k, v := range ip.idsInUse {
+		if v == id {
+			idpoolLogger.Errorw("IDPool: ReserveID failed, id is in use by another key", "pool", ip.name, "id", id, "key", key, "inUseBy", k)
+			return false
+		}
+	}
+	found := false
+	for i, v := range ip.unusedIDs {
+		if v == id {
+			ip.unusedIDs = append(ip.unusedIDs[:i], ip.unusedIDs[i+1:]...)
+			found = true
+			break
+		}
+	}
+	for k, v := range ip.idsForReuse {
+		if v == id {
+			delete(ip.idsForReuse, k)
+			found = true
+			break
+		}
+	}
+	if !found {
+		idpoolLogger.Errorw("IDPool: ReserveID failed, id is not part of the pool", "pool", ip.name, "id", id, "key", key)
+		return false
+	}
+	if current, ok := ip.idsInUse[key]; ok {
+		ip.unusedIDs = append(ip.unusedIDs, current)
+	}
+	if old, ok := ip.idsForReuse[key]; ok {
+		delete(ip.idsForReuse, key)
+		ip.unusedIDs = append(ip.unusedIDs, old)
+	}
+	ip.idsInUse[key] = id
+	ip.changed()
+	idpoolLogger.Infow("IDPool: ReserveID Assigning id", "pool", ip.name, "id", id, "key", key)
+	return true
+}
+
 // GetIDWithRef get the mod ptr id from pool with Reference
 func (ip *IDPool) GetIDWithRef(key interface{}, ref interface{}) (uint32, uint32) {
 	var id uint32
@@ -114,14 +311,15 @@ func (ip *IDPool) GetIDWithRef(key interface{}, ref interface{}) (uint32, uint32
 		return 0, 0
 	}
 	if ref != nil {
-		log.Printf("IDPool: GetID  Assigning key : %+v , id  %+v for ref %v", id, key, ref)
+		idpoolLogger.Debugw("IDPool: GetID Assigning id", "pool", ip.name, "id", id, "key", key, "ref", ref)
 		if ip.refs[id] == nil {
 			ip.refs[id] = make(map[interface{}]bool, 0)
 		}
 		ip.refs[id][ref] = true
+		ip.changed()
 		return id, uint32(len(ip.refs[id]))
 	}
-	log.Printf("IDPool: GetID Assigning id %v for key %v and ref %v", id, key, ref)
+	idpoolLogger.Infow("IDPool: GetID Assigning id", "pool", ip.name, "id", id, "key", key, "ref", ref)
 	return id, uint32(0)
 }
 
@@ -130,17 +328,18 @@ func (ip *IDPool) ReleaseID(key interface{}) uint32 {
 	var ok bool
 	var id uint32
 	if id, ok = ip.idsInUse[key]; !ok {
-		log.Printf("No id to release for key %v", key)
+		idpoolLogger.Infow("IDPool: No id to release", "pool", ip.name, "key", key)
 		return 0
 	}
 	if ip.refs[id] != nil {
-		log.Printf("IDPool:ReleaseID %d for  key %v is having references cannot be released,use ReleaseIDWithRef function instead", id, key)
+		idpoolLogger.Errorw("IDPool: ReleaseID failed, the id has references, use ReleaseIDWithRef instead", "pool", ip.name, "id", id, "key", key)
 		return 0
 	}
-	log.Printf("IDPool:ReleaseID  Releasing id for key %v", key)
+	idpoolLogger.Infow("IDPool: ReleaseID Releasing id", "pool", ip.name, "key", key)
 	delete(ip.idsInUse, key)
 	ip.idsForReuse[key] = id
-	log.Printf("IDPool:ReleaseID Id %v has been released", id)
+	ip.changed()
+	idpoolLogger.Infow("IDPool: ReleaseID Id has been released", "pool", ip.name, "id", id)
 	return id
 }
 
@@ -149,10 +348,10 @@ func (ip *IDPool) ReleaseIDWithRef(key interface{}, ref interface{}) (uint32, ui
 	var ok bool
 	var id uint32
 	if id, ok = ip.idsInUse[key]; !ok {
-		log.Printf("No id to release for key %v", key)
+		idpoolLogger.Infow("IDPool: No id to release", "pool", ip.name, "key", key)
 		return 0, 0
 	}
-	log.Printf("IDPool:ReleaseIDWithRef  Releasing id for key %v", key)
+	idpoolLogger.Infow("IDPool: ReleaseIDWithRef Releasing id", "pool", ip.name, "key", key)
 	refSet := ip.refs[id]
 	if refSet != nil && ref != nil {
 		delete(refSet, ref)
@@ -161,9 +360,11 @@ func (ip *IDPool) ReleaseIDWithRef(key interface{}, ref interface{}) (uint32, ui
 		delete(ip.idsInUse, key)
 		delete(ip.refs, id)
 		ip.idsForReuse[key] = id
-		log.Printf("IDPool:ReleaseIDWithId Id %v has been released", id)
+		ip.changed()
+		idpoolLogger.Infow("IDPool: ReleaseIDWithRef Id has been released", "pool", ip.name, "id", id)
 	} else {
-		log.Printf("IDPool:ReleaseIDWithRef Keep id:%+v remaining references %+v", id, len(refSet))
+		ip.changed()
+		idpoolLogger.Debugw("IDPool: ReleaseIDWithRef Keep id", "pool", ip.name, "id", id, "references", len(refSet))
 	}
 	if ref != nil {
 		return id, uint32(len(refSet))
@@ -0,0 +1,174 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package utils has some utility functions and interfaces
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/storage"
+)
+
+func TestIDPoolReuse(t *testing.T) {
+	pool, ok := IDPoolInit("test", 1000, 1001)
+	assert.True(t, ok)
+
+	assert.Equal(t, uint32(1000), pool.GetID("blue"))
+	assert.Equal(t, uint32(1000), pool.ReleaseID("blue"))
+	// The released id is kept for the same key
+	assert.Equal(t, uint32(1001), pool.GetID("red"))
+	assert.Equal(t, uint32(1000), pool.GetID("blue"))
+	// Once the pool is exhausted the released ids of other keys are recycled
+	assert.Equal(t, uint32(1001), pool.ReleaseID("red"))
+	assert.Equal(t, uint32(1001), pool.GetID("green"))
+	assert.Equal(t, uint32(0), pool.GetID("yellow"))
+}
+
+func TestIDPoolReserveID(t *testing.T) {
+	tests := map[string]struct {
+		setup    func(pool *IDPool)
+		key      string
+		id       uint32
+		expected bool
+		inUse    map[interface{}]uint32
+	}{
+		"unused id": {
+			key: "blue", id: 1002, expected: true,
+			inUse: map[interface{}]uint32{"blue": 1002},
+		},
+		"same id": {
+			setup: func(pool *IDPool) { pool.GetID("blue") },
+			key:   "blue", id: 1000, expected: true,
+			inUse: map[interface{}]uint32{"blue": 1000},
+		},
+		"other id of the key": {
+			setup: func(pool *IDPool) { pool.GetID("blue") },
+			key:   "blue", id: 1001, expected: true,
+			inUse: map[interface{}]uint32{"blue": 1001},
+		},
+		"released id of another key": {
+			setup: func(pool *IDPool) { pool.GetID("red"); pool.ReleaseID("red") },
+			key:   "blue", id: 1000, expected: true,
+			inUse: map[interface{}]uint32{"blue": 1000},
+		},
+		"id in use by another key": {
+			setup: func(pool *IDPool) { pool.GetID("red") },
+			key:   "blue", id: 1000,
+			inUse: map[interface{}]uint32{"red": 1000},
+		},
+		"id out of the pool": {
+			key: "blue", id: 254,
+			inUse: map[interface{}]uint32{},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			pool, _ := IDPoolInit("test", 1000, 1002)
+			if tt.setup != nil {
+				tt.setup(&pool)
+			}
+			assert.Equal(t, tt.expected, pool.ReserveID(tt.key, tt.id))
+			assert.Equal(t, tt.inUse, pool.idsInUse)
+			// The ids are neither lost nor handed out twice
+			ids := map[uint32]bool{}
+			for _, id := range pool.unusedIDs {
+				ids[id] = true
+			}
+			for _, id := range pool.idsForReuse {
+				ids[id] = true
+			}
+			for _, id := range pool.idsInUse {
+				ids[id] = true
+			}
+			assert.Len(t, ids, pool.size)
+		})
+	}
+}
+
+func TestIDPoolPersist(t *testing.T) {
+	store, err := storage.NewStore("gomap", "")
+	assert.NoError(t, err)
+
+	pool, _ := IDPoolInit("test", 1000, 1003)
+	assert.NoError(t, pool.Persist(store))
+	assert.Equal(t, uint32(1000), pool.GetID("blue"))
+	id, refs := pool.GetIDWithRef("red", "svi-10")
+	assert.Equal(t, uint32(1001), id)
+	assert.Equal(t, uint32(1), refs)
+	assert.Equal(t, uint32(1002), pool.GetID("green"))
+	assert.Equal(t, uint32(1002), pool.ReleaseID("green"))
+
+	// The changes are persisted together by the flush
+	state := idPoolState{}
+	_, err = store.Get("idpool/test", &state)
+	assert.NoError(t, err)
+	assert.Empty(t, state.InUse)
+	assert.NoError(t, pool.Flush())
+	_, err = store.Get("idpool/test", &state)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]uint32{"blue": 1000, "red": 1001}, state.InUse)
+
+	// A new pool, as after a restart, gets the same assignments back
+	restored, _ := IDPoolInit("test", 1000, 1003)
+	assert.NoError(t, restored.Persist(store))
+	assert.Equal(t, map[interface{}]uint32{"blue": 1000, "red": 1001}, restored.idsInUse)
+	assert.Equal(t, map[interface{}]uint32{"green": 1002}, restored.idsForReuse)
+	assert.Equal(t, []uint32{1003}, restored.unusedIDs)
+	_, refs = restored.ReleaseIDWithRef("red", "svi-10")
+	assert.Equal(t, uint32(0), refs)
+	assert.Equal(t, uint32(1003), restored.GetID("yellow"))
+	assert.Equal(t, uint32(1002), restored.GetID("green"))
+
+	// A pool with another name is independent
+	other, _ := IDPoolInit("other", 1000, 1003)
+	assert.NoError(t, other.Persist(store))
+	assert.Empty(t, other.idsInUse)
+}
+
+func TestIDPoolCheckConsistency(t *testing.T) {
+	tests := map[string]struct {
+		inUse    map[string]uint32
+		fixed    int
+		expected map[interface{}]uint32
+	}{
+		"consistent": {
+			inUse:    map[string]uint32{"blue": 1000},
+			expected: map[interface{}]uint32{"blue": 1000, "red": 1001},
+		},
+		"another id in use": {
+			inUse:    map[string]uint32{"blue": 1002},
+			fixed:    1,
+			expected: map[interface{}]uint32{"blue": 1002, "red": 1001},
+		},
+		"id in use by another key": {
+			inUse:    map[string]uint32{"green": 1001},
+			fixed:    1,
+			expected: map[interface{}]uint32{"blue": 1000, "green": 1001},
+		},
+		"swapped ids": {
+			inUse:    map[string]uint32{"blue": 1001, "red": 1000},
+			fixed:    2,
+			expected: map[interface{}]uint32{"blue": 1001, "red": 1000},
+		},
+		"id out of the pool": {
+			inUse:    map[string]uint32{"green": 254},
+			fixed:    1,
+			expected: map[interface{}]uint32{"blue": 1000, "red": 1001},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			pool, _ := IDPoolInit("test", 1000, 1003)
+			pool.GetID("blue")
+			pool.GetID("red")
+			assert.Len(t, pool.CheckConsistency(tt.inUse), tt.fixed)
+			assert.Equal(t, tt.expected, pool.idsInUse)
+		})
+	}
+}
@@ -8,26 +8,31 @@ package utils
 import (
 	"context"
 	"fmt"
-	"log"
 
 	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/logging"
+	"go.uber.org/zap"
 )
 
-// InterceptorLogger creates logger for interceptors based on default Go logger
-func InterceptorLogger(l *log.Logger) logging.Logger {
+// InterceptorLogger creates logger for interceptors based on the zap logger of the grpc subsystem
+func InterceptorLogger(l *zap.Logger) logging.Logger {
 	return logging.LoggerFunc(func(_ context.Context, lvl logging.Level, msg string, fields ...any) {
+		f := make([]zap.Field, 0, len(fields)/2)
+		for i := 0; i+1 < len(fields); i += 2 {
+			f = append(f, zap.Any(fmt.Sprint(fields[i]), fields[i+1]))
+		}
+		// The caller is the interceptor, not the handler
+		l := l.WithOptions(zap.WithCaller(false))
 		switch lvl {
 		case logging.LevelDebug:
-			msg = fmt.Sprintf("DEBUG :%v", msg)
+			l.Debug(msg, f...)
 		case logging.LevelInfo:
-			msg = fmt.Sprintf("INFO :%v", msg)
+			l.Info(msg, f...)
 		case logging.LevelWarn:
-			msg = fmt.Sprintf("WARN :%v", msg)
+			l.Warn(msg, f...)
 		case logging.LevelError:
-			msg = fmt.Sprintf("ERROR :%v", msg)
+			l.Error(msg, f...)
 		default:
 			panic(fmt.Sprintf("unknown level %v", lvl))
 		}
-		l.Println(append([]any{"msg", msg}, fields...))
 	})
 }
@@ -12,6 +12,10 @@ import (
 	mock "github.com/stretchr/testify/mock"
 
 	netlink "github.com/vishvananda/netlink"
+
+	nl "github.com/vishvananda/netlink/nl"
+
+	utils "github.com/opiproject/opi-evpn-bridge/pkg/utils"
 )
 
 // Netlink is an autogenerated mock type for the Netlink type
@@ -184,7 +188,7 @@ func (_c *Netlink_AddrList_Call) RunAndReturn(run func(context.Context, netlink.
 }
 
 // BridgeFdbAdd provides a mock function with given fields: _a0, _a1, _a2
-func (_m *Netlink) BridgeFdbAdd(_a0 context.Context, _a1 string, _a2 string) error {
+func (_m *Netlink) BridgeFdbAdd(_a0 context.Context, _a1 netlink.Link, _a2 net.HardwareAddr) error {
 	ret := _m.Called(_a0, _a1, _a2)
 
 	if len(ret) == 0 {
@@ -192,7 +196,7 @@ func (_m *Netlink) BridgeFdbAdd(_a0 context.Context, _a1 string, _a2 string) err
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, netlink.Link, net.HardwareAddr) error); ok {
 		r0 = rf(_a0, _a1, _a2)
 	} else {
 		r0 = ret.Error(0)
@@ -208,15 +212,15 @@ type Netlink_BridgeFdbAdd_Call struct {
 
 // BridgeFdbAdd is a helper method to define mock.On call
 //   - _a0 context.Context
-//   - _a1 string
-//   - _a2 string
+//   - _a1 netlink.Link
+//   - _a2 net.HardwareAddr
 func (_e *Netlink_Expecter) BridgeFdbAdd(_a0 interface{}, _a1 interface{}, _a2 interface{}) *Netlink_BridgeFdbAdd_Call {
 	return &Netlink_BridgeFdbAdd_Call{Call: _e.mock.On("BridgeFdbAdd", _a0, _a1, _a2)}
 }
 
-func (_c *Netlink_BridgeFdbAdd_Call) Run(run func(_a0 context.Context, _a1 string, _a2 string)) *Netlink_BridgeFdbAdd_Call {
+func (_c *Netlink_BridgeFdbAdd_Call) Run(run func(_a0 context.Context, _a1 netlink.Link, _a2 net.HardwareAddr)) *Netlink_BridgeFdbAdd_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(string), args[2].(string))
+		run(args[0].(context.Context), args[1].(netlink.Link), args[2].(net.HardwareAddr))
 	})
 	return _c
 }
@@ -226,7 +230,7 @@ func (_c *Netlink_BridgeFdbAdd_Call) Return(_a0 error) *Netlink_BridgeFdbAdd_Cal
 	return _c
 }
 
-func (_c *Netlink_BridgeFdbAdd_Call) RunAndReturn(run func(context.Context, string, string) error) *Netlink_BridgeFdbAdd_Call {
+func (_c *Netlink_BridgeFdbAdd_Call) RunAndReturn(run func(context.Context, netlink.Link, net.HardwareAddr) error) *Netlink_BridgeFdbAdd_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -335,6 +339,64 @@ func (_c *Netlink_BridgeVlanDel_Call) RunAndReturn(run func(context.Context, net
 	return _c
 }
 
+// BridgeVlanList provides a mock function with given fields: _a0
+func (_m *Netlink) BridgeVlanList(_a0 context.Context) (map[int32][]*nl.BridgeVlanInfo, error) {
+	ret := _m.Called(_a0)
+
+	if len(ret) == 0 {
+		panic("no return value specified for BridgeVlanList")
+	}
+
+	var r0 map[int32][]*nl.BridgeVlanInfo
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (map[int32][]*nl.BridgeVlanInfo, error)); ok {
+		return rf(_a0)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) map[int32][]*nl.BridgeVlanInfo); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[int32][]*nl.BridgeVlanInfo)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Netlink_BridgeVlanList_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'BridgeVlanList'
+type Netlink_BridgeVlanList_Call struct {
+	*mock.Call
+}
+
+// BridgeVlanList is a helper method to define mock.On call
+//   - _a0 context.Context
+func (_e *Netlink_Expecter) BridgeVlanList(_a0 interface{}) *Netlink_BridgeVlanList_Call {
+	return &Netlink_BridgeVlanList_Call{Call: _e.mock.On("BridgeVlanList", _a0)}
+}
+
+func (_c *Netlink_BridgeVlanList_Call) Run(run func(_a0 context.Context)) *Netlink_BridgeVlanList_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *Netlink_BridgeVlanList_Call) Return(_a0 map[int32][]*nl.BridgeVlanInfo, _a1 error) *Netlink_BridgeVlanList_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Netlink_BridgeVlanList_Call) RunAndReturn(run func(context.Context) (map[int32][]*nl.BridgeVlanInfo, error)) *Netlink_BridgeVlanList_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // LinkAdd provides a mock function with given fields: _a0, _a1
 func (_m *Netlink) LinkAdd(_a0 context.Context, _a1 netlink.Link) error {
 	ret := _m.Called(_a0, _a1)
@@ -488,6 +550,64 @@ func (_c *Netlink_LinkDel_Call) RunAndReturn(run func(context.Context, netlink.L
 	return _c
 }
 
+// LinkList provides a mock function with given fields: _a0
+func (_m *Netlink) LinkList(_a0 context.Context) ([]netlink.Link, error) {
+	ret := _m.Called(_a0)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LinkList")
+	}
+
+	var r0 []netlink.Link
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]netlink.Link, error)); ok {
+		return rf(_a0)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []netlink.Link); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]netlink.Link)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Netlink_LinkList_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LinkList'
+type Netlink_LinkList_Call struct {
+	*mock.Call
+}
+
+// LinkList is a helper method to define mock.On call
+//   - _a0 context.Context
+func (_e *Netlink_Expecter) LinkList(_a0 interface{}) *Netlink_LinkList_Call {
+	return &Netlink_LinkList_Call{Call: _e.mock.On("LinkList", _a0)}
+}
+
+func (_c *Netlink_LinkList_Call) Run(run func(_a0 context.Context)) *Netlink_LinkList_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *Netlink_LinkList_Call) Return(_a0 []netlink.Link, _a1 error) *Netlink_LinkList_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Netlink_LinkList_Call) RunAndReturn(run func(context.Context) ([]netlink.Link, error)) *Netlink_LinkList_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // LinkModify provides a mock function with given fields: _a0, _a1
 func (_m *Netlink) LinkModify(_a0 context.Context, _a1 netlink.Link) error {
 	ret := _m.Called(_a0, _a1)
@@ -1210,27 +1330,78 @@ func (_c *Netlink_LinkSetVfTrust_Call) RunAndReturn(run func(context.Context, ne
 	return _c
 }
 
-// ReadFDB provides a mock function with given fields: _a0
-func (_m *Netlink) ReadFDB(_a0 context.Context) (string, error) {
-	ret := _m.Called(_a0)
+// LinkSubscribeWithOptions provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *Netlink) LinkSubscribeWithOptions(_a0 context.Context, _a1 chan<- netlink.LinkUpdate, _a2 <-chan struct{}, _a3 netlink.LinkSubscribeOptions) error {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LinkSubscribeWithOptions")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, chan<- netlink.LinkUpdate, <-chan struct{}, netlink.LinkSubscribeOptions) error); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Netlink_LinkSubscribeWithOptions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LinkSubscribeWithOptions'
+type Netlink_LinkSubscribeWithOptions_Call struct {
+	*mock.Call
+}
+
+// LinkSubscribeWithOptions is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 chan<- netlink.LinkUpdate
+//   - _a2 <-chan struct{}
+//   - _a3 netlink.LinkSubscribeOptions
+func (_e *Netlink_Expecter) LinkSubscribeWithOptions(_a0 interface{}, _a1 interface{}, _a2 interface{}, _a3 interface{}) *Netlink_LinkSubscribeWithOptions_Call {
+	return &Netlink_LinkSubscribeWithOptions_Call{Call: _e.mock.On("LinkSubscribeWithOptions", _a0, _a1, _a2, _a3)}
+}
+
+func (_c *Netlink_LinkSubscribeWithOptions_Call) Run(run func(_a0 context.Context, _a1 chan<- netlink.LinkUpdate, _a2 <-chan struct{}, _a3 netlink.LinkSubscribeOptions)) *Netlink_LinkSubscribeWithOptions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(chan<- netlink.LinkUpdate), args[2].(<-chan struct{}), args[3].(netlink.LinkSubscribeOptions))
+	})
+	return _c
+}
+
+func (_c *Netlink_LinkSubscribeWithOptions_Call) Return(_a0 error) *Netlink_LinkSubscribeWithOptions_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Netlink_LinkSubscribeWithOptions_Call) RunAndReturn(run func(context.Context, chan<- netlink.LinkUpdate, <-chan struct{}, netlink.LinkSubscribeOptions) error) *Netlink_LinkSubscribeWithOptions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ReadFDB provides a mock function with given fields: _a0, _a1
+func (_m *Netlink) ReadFDB(_a0 context.Context, _a1 string) ([]netlink.Neigh, error) {
+	ret := _m.Called(_a0, _a1)
 
 	if len(ret) == 0 {
 		panic("no return value specified for ReadFDB")
 	}
 
-	var r0 string
+	var r0 []netlink.Neigh
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context) (string, error)); ok {
-		return rf(_a0)
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]netlink.Neigh, error)); ok {
+		return rf(_a0, _a1)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context) string); ok {
-		r0 = rf(_a0)
+	if rf, ok := ret.Get(0).(func(context.Context, string) []netlink.Neigh); ok {
+		r0 = rf(_a0, _a1)
 	} else {
-		r0 = ret.Get(0).(string)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]netlink.Neigh)
+		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
-		r1 = rf(_a0)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(_a0, _a1)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -1245,44 +1416,47 @@ type Netlink_ReadFDB_Call struct {
 
 // ReadFDB is a helper method to define mock.On call
 //   - _a0 context.Context
-func (_e *Netlink_Expecter) ReadFDB(_a0 interface{}) *Netlink_ReadFDB_Call {
-	return &Netlink_ReadFDB_Call{Call: _e.mock.On("ReadFDB", _a0)}
+//   - _a1 string
+func (_e *Netlink_Expecter) ReadFDB(_a0 interface{}, _a1 interface{}) *Netlink_ReadFDB_Call {
+	return &Netlink_ReadFDB_Call{Call: _e.mock.On("ReadFDB", _a0, _a1)}
 }
 
-func (_c *Netlink_ReadFDB_Call) Run(run func(_a0 context.Context)) *Netlink_ReadFDB_Call {
+func (_c *Netlink_ReadFDB_Call) Run(run func(_a0 context.Context, _a1 string)) *Netlink_ReadFDB_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context))
+		run(args[0].(context.Context), args[1].(string))
 	})
 	return _c
 }
 
-func (_c *Netlink_ReadFDB_Call) Return(_a0 string, _a1 error) *Netlink_ReadFDB_Call {
+func (_c *Netlink_ReadFDB_Call) Return(_a0 []netlink.Neigh, _a1 error) *Netlink_ReadFDB_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *Netlink_ReadFDB_Call) RunAndReturn(run func(context.Context) (string, error)) *Netlink_ReadFDB_Call {
+func (_c *Netlink_ReadFDB_Call) RunAndReturn(run func(context.Context, string) ([]netlink.Neigh, error)) *Netlink_ReadFDB_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
 // ReadNeigh provides a mock function with given fields: _a0, _a1
-func (_m *Netlink) ReadNeigh(_a0 context.Context, _a1 string) (string, error) {
+func (_m *Netlink) ReadNeigh(_a0 context.Context, _a1 string) ([]utils.Neigh, error) {
 	ret := _m.Called(_a0, _a1)
 
 	if len(ret) == 0 {
 		panic("no return value specified for ReadNeigh")
 	}
 
-	var r0 string
+	var r0 []utils.Neigh
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) (string, error)); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]utils.Neigh, error)); ok {
 		return rf(_a0, _a1)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string) string); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, string) []utils.Neigh); ok {
 		r0 = rf(_a0, _a1)
 	} else {
-		r0 = ret.Get(0).(string)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]utils.Neigh)
+		}
 	}
 
 	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
@@ -1313,36 +1487,38 @@ func (_c *Netlink_ReadNeigh_Call) Run(run func(_a0 context.Context, _a1 string))
 	return _c
 }
 
-func (_c *Netlink_ReadNeigh_Call) Return(_a0 string, _a1 error) *Netlink_ReadNeigh_Call {
+func (_c *Netlink_ReadNeigh_Call) Return(_a0 []utils.Neigh, _a1 error) *Netlink_ReadNeigh_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *Netlink_ReadNeigh_Call) RunAndReturn(run func(context.Context, string) (string, error)) *Netlink_ReadNeigh_Call {
+func (_c *Netlink_ReadNeigh_Call) RunAndReturn(run func(context.Context, string) ([]utils.Neigh, error)) *Netlink_ReadNeigh_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
 // ReadRoute provides a mock function with given fields: _a0, _a1
-func (_m *Netlink) ReadRoute(_a0 context.Context, _a1 string) (string, error) {
+func (_m *Netlink) ReadRoute(_a0 context.Context, _a1 int) ([]netlink.Route, error) {
 	ret := _m.Called(_a0, _a1)
 
 	if len(ret) == 0 {
 		panic("no return value specified for ReadRoute")
 	}
 
-	var r0 string
+	var r0 []netlink.Route
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) (string, error)); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]netlink.Route, error)); ok {
 		return rf(_a0, _a1)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string) string); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, int) []netlink.Route); ok {
 		r0 = rf(_a0, _a1)
 	} else {
-		r0 = ret.Get(0).(string)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]netlink.Route)
+		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
 		r1 = rf(_a0, _a1)
 	} else {
 		r1 = ret.Error(1)
@@ -1358,24 +1534,24 @@ type Netlink_ReadRoute_Call struct {
 
 // ReadRoute is a helper method to define mock.On call
 //   - _a0 context.Context
-//   - _a1 string
+//   - _a1 int
 func (_e *Netlink_Expecter) ReadRoute(_a0 interface{}, _a1 interface{}) *Netlink_ReadRoute_Call {
 	return &Netlink_ReadRoute_Call{Call: _e.mock.On("ReadRoute", _a0, _a1)}
 }
 
-func (_c *Netlink_ReadRoute_Call) Run(run func(_a0 context.Context, _a1 string)) *Netlink_ReadRoute_Call {
+func (_c *Netlink_ReadRoute_Call) Run(run func(_a0 context.Context, _a1 int)) *Netlink_ReadRoute_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(string))
+		run(args[0].(context.Context), args[1].(int))
 	})
 	return _c
 }
 
-func (_c *Netlink_ReadRoute_Call) Return(_a0 string, _a1 error) *Netlink_ReadRoute_Call {
+func (_c *Netlink_ReadRoute_Call) Return(_a0 []netlink.Route, _a1 error) *Netlink_ReadRoute_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *Netlink_ReadRoute_Call) RunAndReturn(run func(context.Context, string) (string, error)) *Netlink_ReadRoute_Call {
+func (_c *Netlink_ReadRoute_Call) RunAndReturn(run func(context.Context, int) ([]netlink.Route, error)) *Netlink_ReadRoute_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -1428,7 +1604,7 @@ func (_c *Netlink_RouteAdd_Call) RunAndReturn(run func(context.Context, *netlink
 }
 
 // RouteFlushTable provides a mock function with given fields: _a0, _a1
-func (_m *Netlink) RouteFlushTable(_a0 context.Context, _a1 string) error {
+func (_m *Netlink) RouteFlushTable(_a0 context.Context, _a1 int) error {
 	ret := _m.Called(_a0, _a1)
 
 	if len(ret) == 0 {
@@ -1436,7 +1612,7 @@ func (_m *Netlink) RouteFlushTable(_a0 context.Context, _a1 string) error {
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
 		r0 = rf(_a0, _a1)
 	} else {
 		r0 = ret.Error(0)
@@ -1452,14 +1628,14 @@ type Netlink_RouteFlushTable_Call struct {
 
 // RouteFlushTable is a helper method to define mock.On call
 //   - _a0 context.Context
-//   - _a1 string
+//   - _a1 int
 func (_e *Netlink_Expecter) RouteFlushTable(_a0 interface{}, _a1 interface{}) *Netlink_RouteFlushTable_Call {
 	return &Netlink_RouteFlushTable_Call{Call: _e.mock.On("RouteFlushTable", _a0, _a1)}
 }
 
-func (_c *Netlink_RouteFlushTable_Call) Run(run func(_a0 context.Context, _a1 string)) *Netlink_RouteFlushTable_Call {
+func (_c *Netlink_RouteFlushTable_Call) Run(run func(_a0 context.Context, _a1 int)) *Netlink_RouteFlushTable_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(string))
+		run(args[0].(context.Context), args[1].(int))
 	})
 	return _c
 }
@@ -1469,7 +1645,7 @@ func (_c *Netlink_RouteFlushTable_Call) Return(_a0 error) *Netlink_RouteFlushTab
 	return _c
 }
 
-func (_c *Netlink_RouteFlushTable_Call) RunAndReturn(run func(context.Context, string) error) *Netlink_RouteFlushTable_Call {
+func (_c *Netlink_RouteFlushTable_Call) RunAndReturn(run func(context.Context, int) error) *Netlink_RouteFlushTable_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -1536,7 +1712,7 @@ func (_c *Netlink_RouteListFiltered_Call) RunAndReturn(run func(context.Context,
 }
 
 // RouteListIPTable provides a mock function with given fields: _a0, _a1
-func (_m *Netlink) RouteListIPTable(_a0 context.Context, _a1 string) bool {
+func (_m *Netlink) RouteListIPTable(_a0 context.Context, _a1 net.IP) bool {
 	ret := _m.Called(_a0, _a1)
 
 	if len(ret) == 0 {
@@ -1544,7 +1720,7 @@ func (_m *Netlink) RouteListIPTable(_a0 context.Context, _a1 string) bool {
 	}
 
 	var r0 bool
-	if rf, ok := ret.Get(0).(func(context.Context, string) bool); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, net.IP) bool); ok {
 		r0 = rf(_a0, _a1)
 	} else {
 		r0 = ret.Get(0).(bool)
@@ -1560,14 +1736,14 @@ type Netlink_RouteListIPTable_Call struct {
 
 // RouteListIPTable is a helper method to define mock.On call
 //   - _a0 context.Context
-//   - _a1 string
+//   - _a1 net.IP
 func (_e *Netlink_Expecter) RouteListIPTable(_a0 interface{}, _a1 interface{}) *Netlink_RouteListIPTable_Call {
 	return &Netlink_RouteListIPTable_Call{Call: _e.mock.On("RouteListIPTable", _a0, _a1)}
 }
 
-func (_c *Netlink_RouteListIPTable_Call) Run(run func(_a0 context.Context, _a1 string)) *Netlink_RouteListIPTable_Call {
+func (_c *Netlink_RouteListIPTable_Call) Run(run func(_a0 context.Context, _a1 net.IP)) *Netlink_RouteListIPTable_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(string))
+		run(args[0].(context.Context), args[1].(net.IP))
 	})
 	return _c
 }
@@ -1577,31 +1753,33 @@ func (_c *Netlink_RouteListIPTable_Call) Return(_a0 bool) *Netlink_RouteListIPTa
 	return _c
 }
 
-func (_c *Netlink_RouteListIPTable_Call) RunAndReturn(run func(context.Context, string) bool) *Netlink_RouteListIPTable_Call {
+func (_c *Netlink_RouteListIPTable_Call) RunAndReturn(run func(context.Context, net.IP) bool) *Netlink_RouteListIPTable_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
 // RouteLookup provides a mock function with given fields: _a0, _a1, _a2
-func (_m *Netlink) RouteLookup(_a0 context.Context, _a1 string, _a2 string) (string, error) {
+func (_m *Netlink) RouteLookup(_a0 context.Context, _a1 net.IP, _a2 string) ([]netlink.Route, error) {
 	ret := _m.Called(_a0, _a1, _a2)
 
 	if len(ret) == 0 {
 		panic("no return value specified for RouteLookup")
 	}
 
-	var r0 string
+	var r0 []netlink.Route
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) (string, error)); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, net.IP, string) ([]netlink.Route, error)); ok {
 		return rf(_a0, _a1, _a2)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) string); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, net.IP, string) []netlink.Route); ok {
 		r0 = rf(_a0, _a1, _a2)
 	} else {
-		r0 = ret.Get(0).(string)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]netlink.Route)
+		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+	if rf, ok := ret.Get(1).(func(context.Context, net.IP, string) error); ok {
 		r1 = rf(_a0, _a1, _a2)
 	} else {
 		r1 = ret.Error(1)
@@ -1617,25 +1795,25 @@ type Netlink_RouteLookup_Call struct {
 
 // RouteLookup is a helper method to define mock.On call
 //   - _a0 context.Context
-//   - _a1 string
+//   - _a1 net.IP
 //   - _a2 string
 func (_e *Netlink_Expecter) RouteLookup(_a0 interface{}, _a1 interface{}, _a2 interface{}) *Netlink_RouteLookup_Call {
 	return &Netlink_RouteLookup_Call{Call: _e.mock.On("RouteLookup", _a0, _a1, _a2)}
 }
 
-func (_c *Netlink_RouteLookup_Call) Run(run func(_a0 context.Context, _a1 string, _a2 string)) *Netlink_RouteLookup_Call {
+func (_c *Netlink_RouteLookup_Call) Run(run func(_a0 context.Context, _a1 net.IP, _a2 string)) *Netlink_RouteLookup_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(string), args[2].(string))
+		run(args[0].(context.Context), args[1].(net.IP), args[2].(string))
 	})
 	return _c
 }
 
-func (_c *Netlink_RouteLookup_Call) Return(_a0 string, _a1 error) *Netlink_RouteLookup_Call {
+func (_c *Netlink_RouteLookup_Call) Return(_a0 []netlink.Route, _a1 error) *Netlink_RouteLookup_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *Netlink_RouteLookup_Call) RunAndReturn(run func(context.Context, string, string) (string, error)) *Netlink_RouteLookup_Call {
+func (_c *Netlink_RouteLookup_Call) RunAndReturn(run func(context.Context, net.IP, string) ([]netlink.Route, error)) *Netlink_RouteLookup_Call {
 	_c.Call.Return(run)
 	return _c
 }
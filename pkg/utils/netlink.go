@@ -8,12 +8,13 @@ package utils
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 
 	"github.com/vishvananda/netlink"
-
-	"errors"
+	"github.com/vishvananda/netlink/nl"
+	"golang.org/x/sys/unix"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
@@ -24,6 +25,7 @@ import (
 // Netlink represents limited subset of functions from netlink package
 type Netlink interface {
 	LinkByName(context.Context, string) (netlink.Link, error)
+	LinkList(context.Context) ([]netlink.Link, error)
 	LinkModify(context.Context, netlink.Link) error
 	LinkSetHardwareAddr(context.Context, netlink.Link, net.HardwareAddr) error
 	LinkSetVfHardwareAddr(context.Context, netlink.Link, int, net.HardwareAddr) error
@@ -42,19 +44,21 @@ type Netlink interface {
 	LinkSetVfSpoofchk(context.Context, netlink.Link, int, bool) error
 	LinkSetVfTrust(context.Context, netlink.Link, int, bool) error
 	LinkSetVfState(context.Context, netlink.Link, int, uint32) error
+	LinkSubscribeWithOptions(context.Context, chan<- netlink.LinkUpdate, <-chan struct{}, netlink.LinkSubscribeOptions) error
 	BridgeVlanAdd(context.Context, netlink.Link, uint16, bool, bool, bool, bool) error
 	BridgeVlanDel(context.Context, netlink.Link, uint16, bool, bool, bool, bool) error
+	BridgeVlanList(context.Context) (map[int32][]*nl.BridgeVlanInfo, error)
 	LinkSetMTU(context.Context, netlink.Link, int) error
-	BridgeFdbAdd(context.Context, string, string) error
+	BridgeFdbAdd(context.Context, netlink.Link, net.HardwareAddr) error
 	RouteAdd(context.Context, *netlink.Route) error
 	RouteListFiltered(context.Context, int, *netlink.Route, uint64) ([]netlink.Route, error)
-	RouteFlushTable(context.Context, string) error
-	RouteListIPTable(context.Context, string) bool
+	RouteFlushTable(context.Context, int) error
+	RouteListIPTable(context.Context, net.IP) bool
 	LinkSetBrNeighSuppress(context.Context, netlink.Link, bool) error
-	ReadNeigh(context.Context, string) (string, error)
-	ReadRoute(context.Context, string) (string, error)
-	ReadFDB(context.Context) (string, error)
-	RouteLookup(context.Context, string, string) (string, error)
+	ReadNeigh(context.Context, string) ([]Neigh, error)
+	ReadRoute(context.Context, int) ([]netlink.Route, error)
+	ReadFDB(context.Context, string) ([]netlink.Neigh, error)
+	RouteLookup(context.Context, net.IP, string) ([]netlink.Route, error)
 }
 
 // NetlinkWrapper wrapper for netlink package
@@ -90,6 +94,14 @@ func (n *NetlinkWrapper) LinkByName(ctx context.Context, name string) (netlink.L
 	return netlink.LinkByName(name)
 }
 
+// LinkList is a wrapper for netlink.LinkList
+func (n *NetlinkWrapper) LinkList(ctx context.Context) ([]netlink.Link, error) {
+	_, childSpan := n.tracer.Start(ctx, "netlink.LinkList")
+	defer childSpan.End()
+
+	return netlink.LinkList()
+}
+
 // LinkModify is a wrapper for netlink.LinkModify
 func (n *NetlinkWrapper) LinkModify(ctx context.Context, link netlink.Link) error {
 	_, childSpan := n.tracer.Start(ctx, "netlink.LinkModify")
@@ -258,6 +270,13 @@ func (n *NetlinkWrapper) BridgeVlanDel(ctx context.Context, link netlink.Link, v
 	return netlink.BridgeVlanDel(link, vid, pvid, untagged, self, master)
 }
 
+// BridgeVlanList is a wrapper for netlink.BridgeVlanList
+func (n *NetlinkWrapper) BridgeVlanList(ctx context.Context) (map[int32][]*nl.BridgeVlanInfo, error) {
+	_, childSpan := n.tracer.Start(ctx, "netlink.BridgeVlanList")
+	defer childSpan.End()
+	return netlink.BridgeVlanList()
+}
+
 // RouteListFiltered is a wrapper for netlink.RouteListFiltered
 func (n *NetlinkWrapper) RouteListFiltered(ctx context.Context, family int, route *netlink.Route, filter uint64) ([]netlink.Route, error) {
 	_, childSpan := n.tracer.Start(ctx, "netlink.RouteListFiltered")
@@ -276,76 +295,235 @@ func (n *NetlinkWrapper) RouteAdd(ctx context.Context, route *netlink.Route) err
 	return netlink.RouteAdd(route)
 }
 
-// RouteFlushTable is a wrapper for netlink.RouteFlushTable
-func (n *NetlinkWrapper) RouteFlushTable(_ context.Context, routingTable string) error {
-	_, err := Run([]string{"ip", "route", "flush", "table", routingTable}, false)
-	if err != 0 {
-		return fmt.Errorf("lgm: Error in executing command ip route flush table %s", routingTable)
+// RouteFlushTable deletes all the routes of the routing table, like ip route flush table
+func (n *NetlinkWrapper) RouteFlushTable(ctx context.Context, table int) error {
+	_, childSpan := n.tracer.Start(ctx, "netlink.RouteFlushTable")
+	childSpan.SetAttributes(attribute.Int("route.Table", table))
+	defer childSpan.End()
+	if table == unix.RT_TABLE_UNSPEC {
+		return errors.New("no routing table to flush")
+	}
+	routes, err := netlink.RouteListFiltered(netlink.FAMILY_ALL, &netlink.Route{Table: table}, netlink.RT_FILTER_TABLE)
+	if err != nil {
+		return fmt.Errorf("failed to list the routes of table %d: %w", table, err)
+	}
+	for i := range routes {
+		// The routes of a deleted link may already be gone
+		if err := netlink.RouteDel(&routes[i]); err != nil && !errors.Is(err, unix.ESRCH) {
+			return fmt.Errorf("failed to delete route %s of table %d: %w", routes[i].Dst, table, err)
+		}
 	}
 	return nil
 }
 
-// RouteListIPTable is a wrapper for netlink.RouteListIPTable
-func (n *NetlinkWrapper) RouteListIPTable(_ context.Context, vtip string) bool {
-	_, err := Run([]string{"ip", "route", "list", "exact", vtip, "table", "local"}, false)
-	return err == 0
+// RouteListIPTable checks the ip is a local address, like ip route list exact <ip> table local
+func (n *NetlinkWrapper) RouteListIPTable(ctx context.Context, ip net.IP) bool {
+	_, childSpan := n.tracer.Start(ctx, "netlink.RouteListIPTable")
+	childSpan.SetAttributes(attribute.String("route.Dst", ip.String()))
+	defer childSpan.End()
+	dst := &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}
+	family := netlink.FAMILY_V6
+	if ip4 := ip.To4(); ip4 != nil {
+		dst = &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}
+		family = netlink.FAMILY_V4
+	}
+	routes, err := netlink.RouteListFiltered(family, &netlink.Route{Table: unix.RT_TABLE_LOCAL, Dst: dst}, netlink.RT_FILTER_TABLE|netlink.RT_FILTER_DST)
+	return err == nil && len(routes) != 0
 }
 
-// BridgeFdbAdd is a wrapper for netlink.BridgeFdbAdd
-func (n *NetlinkWrapper) BridgeFdbAdd(_ context.Context, link string, macAddress string) error {
-	_, err := Run([]string{"bridge", "fdb", "add", macAddress, "dev", link, "master", "static", "extern_learn"}, false)
-	if err != 0 {
-		return errors.New("failed to add fdb entry")
+// BridgeFdbAdd adds a static FDB entry learned externally, like bridge fdb add <mac> dev <link> master static extern_learn
+func (n *NetlinkWrapper) BridgeFdbAdd(ctx context.Context, link netlink.Link, mac net.HardwareAddr) error {
+	_, childSpan := n.tracer.Start(ctx, "netlink.BridgeFdbAdd")
+	childSpan.SetAttributes(attribute.String("link.name", link.Attrs().Name))
+	defer childSpan.End()
+	return netlink.NeighAdd(&netlink.Neigh{
+		LinkIndex:    link.Attrs().Index,
+		Family:       unix.AF_BRIDGE,
+		State:        netlink.NUD_NOARP,
+		Flags:        netlink.NTF_MASTER | netlink.NTF_EXT_LEARNED,
+		HardwareAddr: mac,
+	})
+}
+
+// Neigh is a neighbor with the protocol that has installed it, zebra for the ones from FRR
+type Neigh struct {
+	netlink.Neigh
+	Protocol int
+}
+
+// ndaProtocol is the NDA_PROTOCOL attribute, not parsed by netlink.NeighDeserialize
+const ndaProtocol = 12
+
+// neighList dumps the neighbors of the family, only the ones of the links enslaved to the master when set.
+// netlink.NeighListExecute cannot filter by master and ignores the protocol of the neighbors.
+func neighList(family int, master string) ([]Neigh, error) {
+	req := nl.NewNetlinkRequest(unix.RTM_GETNEIGH, unix.NLM_F_DUMP)
+	msg := &netlink.Ndmsg{Family: uint8(family)}
+	req.AddData(msg)
+	if master != "" {
+		link, err := netlink.LinkByName(master)
+		if err != nil {
+			return nil, err
+		}
+		req.AddData(nl.NewRtAttr(netlink.NDA_MASTER, nl.Uint32Attr(uint32(link.Attrs().Index))))
 	}
-	return nil
+	msgs, err := req.Execute(unix.NETLINK_ROUTE, unix.RTM_NEWNEIGH)
+	if err != nil {
+		return nil, err
+	}
+	neighs := make([]Neigh, 0, len(msgs))
+	for _, m := range msgs {
+		entry, err := deserializeNeigh(m)
+		if err != nil {
+			continue
+		}
+		neighs = append(neighs, entry)
+	}
+	return neighs, nil
 }
 
-// ReadNeigh is a wrapper for netlink.ReadNeigh
-func (n *NetlinkWrapper) ReadNeigh(_ context.Context, link string) (string, error) {
-	var out string
-	var err int
-	if link == "" {
-		out, err = Run([]string{"ip", "-j", "-d", "neighbor", "show"}, false)
-	} else {
-		out, err = Run([]string{"ip", "-j", "-d", "neighbor", "show", "vrf", link}, false)
+// deserializeNeigh parses a neighbor message along with the protocol of the neighbor
+func deserializeNeigh(m []byte) (Neigh, error) {
+	neigh, err := netlink.NeighDeserialize(m)
+	if err != nil {
+		return Neigh{}, err
 	}
-	if err != 0 {
-		return "", errors.New("failed routelookup")
+	entry := Neigh{Neigh: *neigh}
+	attrs, err := nl.ParseRouteAttr(m[unix.SizeofNdMsg:])
+	if err != nil {
+		return Neigh{}, err
 	}
-	return out, nil
+	for _, attr := range attrs {
+		if attr.Attr.Type == ndaProtocol && len(attr.Value) != 0 {
+			entry.Protocol = int(attr.Value[0])
+		}
+	}
+	return entry, nil
+}
+
+// ReadNeigh returns the IPv4 and IPv6 neighbors of the vrf, or all of them if not set, like ip neighbor show vrf <vrf>
+func (n *NetlinkWrapper) ReadNeigh(ctx context.Context, vrf string) ([]Neigh, error) {
+	_, childSpan := n.tracer.Start(ctx, "netlink.ReadNeigh")
+	childSpan.SetAttributes(attribute.String("vrf.name", vrf))
+	defer childSpan.End()
+	neighs, err := neighList(unix.AF_UNSPEC, vrf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the neighbors: %w", err)
+	}
+	ipNeighs := neighs[:0]
+	for _, neigh := range neighs {
+		if neigh.Family == netlink.FAMILY_V4 || neigh.Family == netlink.FAMILY_V6 {
+			ipNeighs = append(ipNeighs, neigh)
+		}
+	}
+	return ipNeighs, nil
+}
+
+// NeighUpdate is a neighbor change with the protocol of the neighbor, the type is unix.RTM_NEWNEIGH or unix.RTM_DELNEIGH
+type NeighUpdate struct {
+	Type uint16
+	Neigh
 }
 
-// ReadRoute is a wrapper for netlink.ReadRoute
-func (n *NetlinkWrapper) ReadRoute(_ context.Context, table string) (string, error) {
-	out, err := Run([]string{"ip", "-j", "-d", "route", "show", "table", table}, false)
-	if err != 0 {
-		return "", errors.New("failed to read route")
+// NeighSubscribeWithOptions sends the neighbor and fdb changes to ch until done is closed, then closes ch.
+// Unlike netlink.NeighSubscribeWithOptions it keeps the protocol of the neighbors, only the error callback
+// and the receive buffer size of the options are supported.
+func NeighSubscribeWithOptions(ch chan<- NeighUpdate, done <-chan struct{}, options netlink.NeighSubscribeOptions) error {
+	s, err := nl.Subscribe(unix.NETLINK_ROUTE, unix.RTNLGRP_NEIGH)
+	if err != nil {
+		return err
+	}
+	if options.ReceiveBufferSize != 0 {
+		if err := s.SetReceiveBufferSize(options.ReceiveBufferSize, false); err != nil {
+			s.Close()
+			return err
+		}
+	}
+	onError := func(err error) {
+		if options.ErrorCallback != nil {
+			options.ErrorCallback(err)
+		}
 	}
-	return out, nil
+	go func() {
+		<-done
+		s.Close()
+	}()
+	go func() {
+		defer close(ch)
+		for {
+			msgs, from, err := s.Receive()
+			if err != nil {
+				onError(err)
+				return
+			}
+			if from.Pid != nl.PidKernel {
+				onError(fmt.Errorf("wrong sender portid %d, expected %d", from.Pid, nl.PidKernel))
+				continue
+			}
+			for _, m := range msgs {
+				if m.Header.Type == unix.NLMSG_DONE {
+					continue
+				}
+				if m.Header.Type == unix.NLMSG_ERROR {
+					if errno := -int32(nl.NativeEndian().Uint32(m.Data[0:4])); errno != 0 {
+						onError(fmt.Errorf("failed to receive the neighbor changes: %w", unix.Errno(errno)))
+						return
+					}
+					continue
+				}
+				entry, err := deserializeNeigh(m.Data)
+				if err != nil {
+					onError(err)
+					return
+				}
+				ch <- NeighUpdate{Type: m.Header.Type, Neigh: entry}
+			}
+		}
+	}()
+	return nil
 }
 
-// ReadFDB is a wrapper for netlink.ReadFDB
-func (n *NetlinkWrapper) ReadFDB(_ context.Context) (string, error) {
-	out, err := Run([]string{"bridge", "-d", "-j", "fdb", "show", "br", "br-tenant", "dynamic"}, false)
-	if err != 0 {
-		return "", errors.New("failed to read fdb")
+// ReadRoute returns the IPv4 and IPv6 routes of the routing table, like ip route show table <table>
+func (n *NetlinkWrapper) ReadRoute(ctx context.Context, table int) ([]netlink.Route, error) {
+	_, childSpan := n.tracer.Start(ctx, "netlink.ReadRoute")
+	childSpan.SetAttributes(attribute.Int("route.Table", table))
+	defer childSpan.End()
+	routes, err := netlink.RouteListFiltered(netlink.FAMILY_ALL, &netlink.Route{Table: table}, netlink.RT_FILTER_TABLE)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the routes of table %d: %w", table, err)
 	}
-	return out, nil
+	return routes, nil
 }
 
-// RouteLookup is a wrapper for netlink.RouteLookup
-func (n *NetlinkWrapper) RouteLookup(_ context.Context, dst string, link string) (string, error) {
-	var out string
-	var err int
-	if link == "" {
-		out, err = Run([]string{"ip", "-j", "route", "get", dst, "fibmatch"}, false)
-	} else {
-		out, err = Run([]string{"ip", "-j", "route", "get", dst, "vrf", link, "fibmatch"}, false)
+// ReadFDB returns the dynamic FDB entries of the ports of the bridge, like bridge fdb show br <bridge> dynamic
+func (n *NetlinkWrapper) ReadFDB(ctx context.Context, bridge string) ([]netlink.Neigh, error) {
+	_, childSpan := n.tracer.Start(ctx, "netlink.ReadFDB")
+	childSpan.SetAttributes(attribute.String("link.name", bridge))
+	defer childSpan.End()
+	neighs, err := neighList(unix.AF_BRIDGE, bridge)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the fdb: %w", err)
 	}
-	if err != 0 {
-		return "", errors.New("failed routelookup")
+	var fdb []netlink.Neigh
+	for _, neigh := range neighs {
+		if neigh.State&(netlink.NUD_PERMANENT|netlink.NUD_NOARP) == 0 {
+			fdb = append(fdb, neigh.Neigh)
+		}
+	}
+	return fdb, nil
+}
+
+// RouteLookup returns the route matching the destination in the vrf, or in the default one if not set,
+// like ip route get <dst> vrf <vrf> fibmatch
+func (n *NetlinkWrapper) RouteLookup(ctx context.Context, dst net.IP, vrf string) ([]netlink.Route, error) {
+	_, childSpan := n.tracer.Start(ctx, "netlink.RouteLookup")
+	childSpan.SetAttributes(attribute.String("route.Dst", dst.String()), attribute.String("vrf.name", vrf))
+	defer childSpan.End()
+	routes, err := netlink.RouteGetWithOptions(dst, &netlink.RouteGetOptions{VrfName: vrf, FIBMatch: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to lookup the route to %s: %w", dst, err)
 	}
-	return out, nil
+	return routes, nil
 }
 
 // LinkSetBrNeighSuppress is a wrapper for netlink.LinkSetBrNeighSuppress
@@ -355,3 +533,10 @@ func (n *NetlinkWrapper) LinkSetBrNeighSuppress(ctx context.Context, link netlin
 	defer childSpan.End()
 	return netlink.LinkSetBrNeighSuppress(link, neighSuppress)
 }
+
+// LinkSubscribeWithOptions is a wrapper for netlink.LinkSubscribeWithOptions
+func (n *NetlinkWrapper) LinkSubscribeWithOptions(ctx context.Context, ch chan<- netlink.LinkUpdate, done <-chan struct{}, options netlink.LinkSubscribeOptions) error {
+	_, childSpan := n.tracer.Start(ctx, "netlink.LinkSubscribeWithOptions")
+	defer childSpan.End()
+	return netlink.LinkSubscribeWithOptions(ch, done, options)
+}
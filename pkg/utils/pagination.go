@@ -5,49 +5,292 @@
 package utils
 
 import (
-	"log"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
 
+	"go.einride.tech/aip/ordering"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 )
 
-// ExtractPagination fetches pagination from the database, calculate size and offset
-func ExtractPagination(pageSize int32, pageToken string, pagination map[string]int) (size int, offset int, err error) {
-	const (
-		maxPageSize     = 250
-		defaultPageSize = 50
-	)
+const (
+	maxPageSize     = 250
+	defaultPageSize = 50
+
+	pageTokenVersion = 1
+)
+
+var (
+	pageTokenKey []byte
+	pageTokenMtx sync.RWMutex
+
+	errInvalidPageToken = errors.New("invalid page token")
+)
+
+func init() {
+	// Until a key is configured the tokens are signed with a random key,
+	// which means that they do not survive a restart.
+	key := make([]byte, sha256.Size)
+	if _, err := rand.Read(key); err != nil {
+		logger.Panicw("Unable to generate pagination token key", "error", err)
+	}
+	pageTokenKey = key
+}
+
+// SetPageTokenKey sets the secret that signs the pagination tokens.
+// All the instances that share the same key accept each other's tokens.
+func SetPageTokenKey(key string) {
+	if key == "" {
+		return
+	}
+	sum := sha256.Sum256([]byte(key))
+
+	pageTokenMtx.Lock()
+	defer pageTokenMtx.Unlock()
+	pageTokenKey = sum[:]
+}
+
+// pageCursor is the self describing content of a pagination token.
+// Key holds the sort key of the last object of the previous page
+// and Query the hash of the filter and order_by that produced it.
+type pageCursor struct {
+	Version int           `json:"v"`
+	Query   string        `json:"q"`
+	Key     []cursorValue `json:"k"`
+}
+
+// cursorValue is the serialized form of a single sort key value
+type cursorValue struct {
+	Type  string  `json:"t,omitempty"`
+	Str   string  `json:"s,omitempty"`
+	Int   int64   `json:"i,omitempty"`
+	Float float64 `json:"f,omitempty"`
+	Bool  bool    `json:"b,omitempty"`
+}
+
+// ListPage filters and sorts the objects of a List request and returns the page
+// that follows the cursor of the page token together with the token of the next page.
+// Tokens carry the position in the result, so inserts and deletes between two calls
+// neither skip nor repeat objects and the server keeps no pagination state.
+func ListPage[T proto.Message](objects []T, pageSize int32, pageToken, filter, orderBy string) ([]T, string, error) {
+	size, err := limitPageSize(pageSize)
+	if err != nil {
+		return nil, "", err
+	}
+
+	query := queryHash(filter, orderBy)
+	var after []interface{}
+	if pageToken != "" {
+		var err error
+		after, err = decodePageToken(pageToken, query)
+		if err != nil {
+			logger.Infow("ListPage(): Invalid page token", "error", err)
+			return nil, "", status.Errorf(codes.InvalidArgument, "invalid pagination token %s", pageToken)
+		}
+	}
+
+	objects, fields, err := filterAndOrder(objects, filter, orderBy)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// continue with the first object that sorts after the last one of the previous page
+	offset := 0
+	if after != nil {
+		offset = sort.Search(len(objects), func(i int) bool {
+			return compareKeys(sortKey(objects[i], fields), after, fields) > 0
+		})
+	}
+
+	logger.Infow("Limiting result", "count", len(objects), "offset", offset, "size", size)
+	end := offset + size
+	if end >= len(objects) {
+		return objects[offset:], "", nil
+	}
+
+	page := objects[offset:end]
+	token, err := encodePageToken(sortKey(page[len(page)-1], fields), query)
+	if err != nil {
+		return nil, "", status.Errorf(codes.Internal, "unable to create pagination token: %v", err)
+	}
+	return page, token, nil
+}
+
+// KeyPage returns the bounds of the page of a list sorted by increasing keys that follows the cursor
+// of the page token, together with the token of the next page. It pages the lists that are not made
+// of proto messages, the keys are made of strings, int64, float64 and bool values and query binds
+// the tokens to the request that created them.
+func KeyPage(keys [][]interface{}, pageSize int32, pageToken, query string) (int, int, string, error) {
+	size, err := limitPageSize(pageSize)
+	if err != nil {
+		return 0, 0, "", err
+	}
+
+	hash := queryHash(query, "")
+	offset := 0
+	if pageToken != "" {
+		after, err := decodePageToken(pageToken, hash)
+		if err != nil {
+			logger.Infow("KeyPage(): Invalid page token", "error", err)
+			return 0, 0, "", status.Errorf(codes.InvalidArgument, "invalid pagination token %s", pageToken)
+		}
+		offset = sort.Search(len(keys), func(i int) bool {
+			return compareKeyValues(keys[i], after) > 0
+		})
+	}
+
+	end := offset + size
+	if end >= len(keys) {
+		return offset, len(keys), "", nil
+	}
+	token, err := encodePageToken(keys[end-1], hash)
+	if err != nil {
+		return 0, 0, "", status.Errorf(codes.Internal, "unable to create pagination token: %v", err)
+	}
+	return offset, end, token, nil
+}
+
+// limitPageSize returns the size of the page of a request, the default one if none is requested
+func limitPageSize(pageSize int32) (int, error) {
 	switch {
 	case pageSize < 0:
-		return -1, -1, status.Error(codes.InvalidArgument, "negative PageSize is not allowed")
+		return 0, status.Error(codes.InvalidArgument, "negative PageSize is not allowed")
 	case pageSize == 0:
-		size = defaultPageSize
+		return defaultPageSize, nil
 	case pageSize > maxPageSize:
-		size = maxPageSize
+		return maxPageSize, nil
 	default:
-		size = int(pageSize)
+		return int(pageSize), nil
 	}
-	// fetch offset from the database using opaque token
-	offset = 0
-	if pageToken != "" {
-		var ok bool
-		offset, ok = pagination[pageToken]
-		if !ok {
-			return -1, -1, status.Errorf(codes.NotFound, "unable to find pagination token %s", pageToken)
+}
+
+// compareKeyValues orders two keys by increasing values, a key sorts before the longer keys it starts
+func compareKeyValues(x, y []interface{}) int {
+	for i := 0; i < len(x) && i < len(y); i++ {
+		if c := compareValues(x[i], y[i]); c != 0 {
+			return c
 		}
-		log.Printf("Found offset %d from pagination token: %s", offset, pageToken)
 	}
-	return size, offset, nil
+	return compareNumbers(int64(len(x)), int64(len(y)))
 }
 
-// LimitPagination returns subset of the slice per gived size and offset
-func LimitPagination[T any](result []T, offset int, size int) ([]T, bool) {
-	end := offset + size
-	hasMoreElements := false
-	if end < len(result) {
-		hasMoreElements = true
-	} else {
-		end = len(result)
+// PageTokenAfter returns the token of the page that follows the given object
+func PageTokenAfter[T proto.Message](last T, filter, orderBy string) (string, error) {
+	order := ordering.OrderBy{}
+	if err := order.UnmarshalString(orderBy); err != nil {
+		return "", err
+	}
+	fields := append(order.Fields, ordering.Field{Path: "name"})
+	return encodePageToken(sortKey(last, fields), queryHash(filter, orderBy))
+}
+
+// queryHash binds a token to the filter and order_by of the request that created it
+func queryHash(filter, orderBy string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(filter) + "\x00" + strings.TrimSpace(orderBy)))
+	return base64.RawURLEncoding.EncodeToString(sum[:8])
+}
+
+// signPageToken returns the HMAC of the token payload
+func signPageToken(payload []byte) []byte {
+	pageTokenMtx.RLock()
+	mac := hmac.New(sha256.New, pageTokenKey)
+	pageTokenMtx.RUnlock()
+
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// encodePageToken serializes and signs the cursor
+func encodePageToken(key []interface{}, query string) (string, error) {
+	cursor := pageCursor{Version: pageTokenVersion, Query: query}
+	for _, v := range key {
+		cursor.Key = append(cursor.Key, toCursorValue(v))
+	}
+
+	payload, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(payload) + "." +
+		base64.RawURLEncoding.EncodeToString(signPageToken(payload)), nil
+}
+
+// decodePageToken verifies the signature of the token and returns its cursor
+func decodePageToken(token string, query string) ([]interface{}, error) {
+	encodedPayload, encodedSig, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, errInvalidPageToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return nil, errInvalidPageToken
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(encodedSig)
+	if err != nil {
+		return nil, errInvalidPageToken
+	}
+	if !hmac.Equal(sig, signPageToken(payload)) {
+		return nil, errors.New("page token signature mismatch")
+	}
+
+	cursor := pageCursor{}
+	if err := json.Unmarshal(payload, &cursor); err != nil {
+		return nil, errInvalidPageToken
+	}
+	if cursor.Version != pageTokenVersion {
+		return nil, errors.New("unsupported page token version")
+	}
+	if cursor.Query != query {
+		return nil, errors.New("page token does not match the filter and order_by of the request")
+	}
+
+	key := make([]interface{}, 0, len(cursor.Key))
+	for _, v := range cursor.Key {
+		key = append(key, v.value())
+	}
+	return key, nil
+}
+
+// toCursorValue serializes a sort key value
+func toCursorValue(v interface{}) cursorValue {
+	switch val := v.(type) {
+	case string:
+		return cursorValue{Type: "s", Str: val}
+	case int64:
+		return cursorValue{Type: "i", Int: val}
+	case float64:
+		return cursorValue{Type: "f", Float: val}
+	case bool:
+		return cursorValue{Type: "b", Bool: val}
+	case enumValue:
+		return cursorValue{Type: "e", Str: val.name, Int: val.number}
+	default:
+		return cursorValue{}
+	}
+}
+
+// value deserializes a sort key value
+func (c cursorValue) value() interface{} {
+	switch c.Type {
+	case "s":
+		return c.Str
+	case "i":
+		return c.Int
+	case "f":
+		return c.Float
+	case "b":
+		return c.Bool
+	case "e":
+		return enumValue{name: c.Str, number: c.Int}
+	default:
+		return nil
 	}
-	return result[offset:end], hasMoreElements
 }
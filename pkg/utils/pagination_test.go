@@ -0,0 +1,128 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package utils has some utility functions and interfaces
+package utils
+
+import (
+	"testing"
+
+	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestListPage_StableUnderInserts(t *testing.T) {
+	lbs := []*pb.LogicalBridge{
+		newTestLB("lb-b", 20, nil, pb.LBOperStatus_LB_OPER_STATUS_UP),
+		newTestLB("lb-d", 40, nil, pb.LBOperStatus_LB_OPER_STATUS_UP),
+		newTestLB("lb-f", 60, nil, pb.LBOperStatus_LB_OPER_STATUS_UP),
+	}
+
+	page, token, err := ListPage(lbs, 2, "", "", "")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"lb-b", "lb-d"}, lbNames(page))
+	assert.NotEmpty(t, token)
+
+	// An object added before the cursor must neither shift nor repeat the next page
+	lbs = append(lbs,
+		newTestLB("lb-a", 10, nil, pb.LBOperStatus_LB_OPER_STATUS_UP),
+		newTestLB("lb-e", 50, nil, pb.LBOperStatus_LB_OPER_STATUS_UP),
+	)
+	page, token, err = ListPage(lbs, 2, token, "", "")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"lb-e", "lb-f"}, lbNames(page))
+	assert.Empty(t, token)
+}
+
+func TestListPage_OrderBy(t *testing.T) {
+	lbs := []*pb.LogicalBridge{
+		newTestLB("lb-a", 10, nil, pb.LBOperStatus_LB_OPER_STATUS_UP),
+		newTestLB("lb-b", 30, nil, pb.LBOperStatus_LB_OPER_STATUS_UP),
+		newTestLB("lb-c", 20, nil, pb.LBOperStatus_LB_OPER_STATUS_UP),
+	}
+
+	page, token, err := ListPage(lbs, 1, "", "", "spec.vlan_id desc")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"lb-b"}, lbNames(page))
+
+	page, _, err = ListPage(lbs, 2, token, "", "spec.vlan_id desc")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"lb-c", "lb-a"}, lbNames(page))
+}
+
+func TestListPage_InvalidTokens(t *testing.T) {
+	lbs := []*pb.LogicalBridge{
+		newTestLB("lb-a", 10, nil, pb.LBOperStatus_LB_OPER_STATUS_UP),
+		newTestLB("lb-b", 20, nil, pb.LBOperStatus_LB_OPER_STATUS_DOWN),
+	}
+
+	_, token, err := ListPage(lbs, 1, "", "", "")
+	assert.NoError(t, err)
+
+	tests := map[string]struct {
+		token  string
+		filter string
+	}{
+		"garbage":          {token: "unknown-pagination-token"},
+		"tampered payload": {token: "x" + token},
+		"tampered sig":     {token: token + "x"},
+		"other filter":     {token: token, filter: "spec.vlan_id > 0"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, _, err := ListPage(lbs, 1, tt.token, tt.filter, "")
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		})
+	}
+}
+
+func TestListPage_SharedKey(t *testing.T) {
+	lbs := []*pb.LogicalBridge{
+		newTestLB("lb-a", 10, nil, pb.LBOperStatus_LB_OPER_STATUS_UP),
+		newTestLB("lb-b", 20, nil, pb.LBOperStatus_LB_OPER_STATUS_UP),
+	}
+
+	SetPageTokenKey("first-secret")
+	_, token, err := ListPage(lbs, 1, "", "", "")
+	assert.NoError(t, err)
+
+	SetPageTokenKey("second-secret")
+	_, _, err = ListPage(lbs, 1, token, "", "")
+	assert.Equal(t, codes.InvalidArgument, status.Code(err), "Expected token signed with another key to be rejected")
+
+	SetPageTokenKey("first-secret")
+	page, _, err := ListPage(lbs, 1, token, "", "")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"lb-b"}, lbNames(page))
+}
+
+func TestKeyPage(t *testing.T) {
+	keys := [][]interface{}{
+		{int64(0), int64(1001), "10.0.0.0/24"},
+		{int64(0), int64(1001), "10.0.1.0/24"},
+		{int64(1), int64(16)},
+		{int64(1), int64(17)},
+	}
+
+	start, end, token, err := KeyPage(keys, 3, "", "blue")
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0, 3}, []int{start, end})
+	assert.NotEmpty(t, token)
+
+	// A key added before the cursor must neither shift nor repeat the next page
+	keys = append(keys[:1], append([][]interface{}{{int64(0), int64(1001), "10.0.0.128/25"}}, keys[1:]...)...)
+	start, end, token, err = KeyPage(keys, 3, token, "blue")
+	assert.NoError(t, err)
+	assert.Equal(t, []int{4, 5}, []int{start, end})
+	assert.Empty(t, token)
+
+	_, _, token, err = KeyPage(keys, 1, "", "blue")
+	assert.NoError(t, err)
+	_, _, _, err = KeyPage(keys, 1, token, "green")
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, _, _, err = KeyPage(keys, -1, "", "blue")
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
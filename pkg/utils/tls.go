@@ -7,9 +7,9 @@ package utils
 import (
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/pem"
 	"errors"
 	"fmt"
-	"log"
 	"os"
 	"strings"
 
@@ -86,7 +86,7 @@ func setupTLSCredentials(config TLSConfig,
 	}
 
 	c.ClientCAs = x509.NewCertPool()
-	log.Println("Loading client ca certificate:", config.CaCertPath)
+	logger.Infoln("Loading client ca certificate:", config.CaCertPath)
 
 	clientCaCert, err := readFile(config.CaCertPath)
 	if err != nil {
@@ -99,3 +99,72 @@ func setupTLSCredentials(config TLSConfig,
 
 	return grpc.Creds(credentials.NewTLS(c)), nil
 }
+
+// SetupTLSDialCredentials returns a dial option to connect with TLS to the gRPC server,
+// e.g. from the HTTP gateway. The certificate of the config is presented as the client
+// certificate, it is the identity of the client and not the one of the server, and the
+// CA certificate is used to verify the server certificate, that has to be issued for serverName.
+func SetupTLSDialCredentials(config TLSConfig, serverName string) (grpc.DialOption, error) {
+	return setupTLSDialCredentials(config, serverName, tls.LoadX509KeyPair, os.ReadFile)
+}
+
+func setupTLSDialCredentials(config TLSConfig, serverName string,
+	loadX509KeyPair func(string, string) (tls.Certificate, error),
+	readFile func(string) ([]byte, error),
+) (grpc.DialOption, error) {
+	clientCert, err := loadX509KeyPair(config.ServerCertPath, config.ServerKeyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &tls.Config{
+		Certificates: []tls.Certificate{clientCert},
+		ServerName:   serverName,
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	c.RootCAs = x509.NewCertPool()
+	logger.Infoln("Loading server ca certificate:", config.CaCertPath)
+
+	caCert, err := readFile(config.CaCertPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read certificate: %v. error: %v", config.CaCertPath, err)
+	}
+
+	if !c.RootCAs.AppendCertsFromPEM(caCert) {
+		return nil, fmt.Errorf("failed to add server CA's certificate: %v", config.CaCertPath)
+	}
+
+	return grpc.WithTransportCredentials(credentials.NewTLS(c)), nil
+}
+
+// CertificateServerName returns the name the certificate has been issued for,
+// its first DNS or IP subject alternative name or else its common name
+func CertificateServerName(certPath string) (string, error) {
+	return certificateServerName(certPath, os.ReadFile)
+}
+
+func certificateServerName(certPath string, readFile func(string) ([]byte, error)) (string, error) {
+	data, err := readFile(certPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read certificate: %v. error: %v", certPath, err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return "", fmt.Errorf("failed to decode certificate: %v", certPath)
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse certificate: %v. error: %v", certPath, err)
+	}
+	switch {
+	case len(cert.DNSNames) != 0:
+		return cert.DNSNames[0], nil
+	case len(cert.IPAddresses) != 0:
+		return cert.IPAddresses[0].String(), nil
+	case cert.Subject.CommonName != "":
+		return cert.Subject.CommonName, nil
+	default:
+		return "", fmt.Errorf("no server name in certificate: %v", certPath)
+	}
+}
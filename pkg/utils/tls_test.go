@@ -175,3 +175,100 @@ func TestServer_SetupTLSCredentials(t *testing.T) {
 		})
 	}
 }
+
+func TestServer_SetupTLSDialCredentials(t *testing.T) {
+	tests := map[string]struct {
+		expectErr   bool
+		loadKeyErr  error
+		readFileErr error
+		validCaCert bool
+	}{
+		"failed to load key pair": {
+			expectErr:   true,
+			loadKeyErr:  errors.New("Key load failed"),
+			readFileErr: nil,
+			validCaCert: true,
+		},
+		"failed to read file": {
+			expectErr:   true,
+			loadKeyErr:  nil,
+			readFileErr: errors.New("Failed to read file"),
+			validCaCert: true,
+		},
+		"invalid CA certificate": {
+			expectErr:   true,
+			loadKeyErr:  nil,
+			readFileErr: nil,
+			validCaCert: false,
+		},
+		"valid CA certificate": {
+			expectErr:   false,
+			loadKeyErr:  nil,
+			readFileErr: nil,
+			validCaCert: true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			caCert := make([]byte, len(validCa))
+			copy(caCert, validCa)
+			if !tt.validCaCert {
+				caCert[0] = caCert[0] - 1
+			}
+
+			out, err := setupTLSDialCredentials(TLSConfig{
+				ServerCertPath: "a",
+				ServerKeyPath:  "b",
+				CaCertPath:     "c",
+			}, "localhost", func(_, _ string) (tls.Certificate, error) {
+				return tls.Certificate{}, tt.loadKeyErr
+			}, func(_ string) ([]byte, error) {
+				return caCert, tt.readFileErr
+			})
+
+			if (err != nil) != tt.expectErr {
+				t.Error("Expect error", tt.expectErr, "received", err)
+			}
+			if !tt.expectErr && out == nil {
+				t.Error("Expect not nil dial option, received nil")
+			}
+		})
+	}
+}
+
+func TestServer_CertificateServerName(t *testing.T) {
+	tests := map[string]struct {
+		cert        []byte
+		readFileErr error
+		expectErr   bool
+		serverName  string
+	}{
+		"failed to read file": {
+			cert:        validCa,
+			readFileErr: errors.New("Failed to read file"),
+			expectErr:   true,
+		},
+		"invalid certificate": {
+			cert:      []byte("invalid"),
+			expectErr: true,
+		},
+		"common name": {
+			cert:       validCa,
+			serverName: "*.opi.com",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			serverName, err := certificateServerName("a", func(_ string) ([]byte, error) {
+				return tt.cert, tt.readFileErr
+			})
+
+			if (err != nil) != tt.expectErr {
+				t.Error("Expect error", tt.expectErr, "received", err)
+			}
+			if serverName != tt.serverName {
+				t.Error("Expect server name", tt.serverName, "received", serverName)
+			}
+		})
+	}
+}
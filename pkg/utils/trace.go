@@ -6,7 +6,6 @@ package utils
 
 import (
 	"context"
-	"log"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
@@ -25,7 +24,7 @@ func InitTracerProvider(service string) *sdktrace.TracerProvider {
 	ctx := context.Background()
 	exporter, err := otlptracegrpc.New(ctx)
 	if err != nil {
-		log.Panicf("OTLP Trace gRPC Creation: %v", err)
+		logger.Panicw("OTLP Trace gRPC Creation failure", "error", err)
 	}
 	tp := sdktrace.NewTracerProvider(
 		// Always be sure to batch in production.
@@ -8,9 +8,8 @@ package vrf
 import (
 	"context"
 	"fmt"
-	"log"
 	"net"
-	"sort"
+	"strings"
 	"testing"
 
 	"go.einride.tech/aip/resourcename"
@@ -19,18 +18,19 @@ import (
 	"google.golang.org/grpc/test/bufconn"
 
 	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+	"github.com/opiproject/opi-evpn-bridge/pkg/audit"
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
 	"github.com/opiproject/opi-evpn-bridge/pkg/utils/mocks"
 )
 
-func sortVrfs(vrfs []*pb.Vrf) {
-	sort.Slice(vrfs, func(i int, j int) bool {
-		return vrfs[i].Name < vrfs[j].Name
-	})
-}
+func (s *Server) createVrf(ctx context.Context, vrf *pb.Vrf) (response *pb.Vrf, err error) {
+	var resourceVersion string
+	defer func() {
+		audit.Mutation(ctx, audit.ActionCreate, "vrf", vrf.Name, vrf, nil, response, resourceVersion, err)
+	}()
 
-func (s *Server) createVrf(vrf *pb.Vrf) (*pb.Vrf, error) {
 	// check parameters
 	if err := s.validateVrfSpec(vrf); err != nil {
 		return nil, err
@@ -41,16 +41,23 @@ func (s *Server) createVrf(vrf *pb.Vrf) (*pb.Vrf, error) {
 	if err != nil {
 		return nil, err
 	}
+	domainVrf.Metadata.Audit.CreatedBy = utils.ClientIdentity(ctx)
 	// Note: The status of the object will be generated in infraDB operation not here
 	if err := infradb.CreateVrf(domainVrf); err != nil {
 		return nil, err
 	}
+	resourceVersion = domainVrf.ResourceVersion
 	return domainVrf.ToPb(), nil
 }
 
-func (s *Server) deleteVrf(name string) error {
+func (s *Server) deleteVrf(ctx context.Context, name string) (err error) {
+	before, _ := s.getVrf(name)
+	defer func() {
+		audit.Mutation(ctx, audit.ActionDelete, "vrf", name, nil, before, nil, s.resourceVersion(name), err)
+	}()
+
 	// Note: The status of the object will be generated in infraDB operation not here
-	if err := infradb.DeleteVrf(name); err != nil {
+	if err := infradb.DeleteVrf(name, utils.ClientIdentity(ctx)); err != nil {
 		return err
 	}
 	return nil
@@ -77,7 +84,13 @@ func (s *Server) getAllVrfs() ([]*pb.Vrf, error) {
 	return vrfs, nil
 }
 
-func (s *Server) updateVrf(vrf *pb.Vrf) (*pb.Vrf, error) {
+func (s *Server) updateVrf(ctx context.Context, vrf *pb.Vrf) (response *pb.Vrf, err error) {
+	before, _ := s.getVrf(vrf.Name)
+	var resourceVersion string
+	defer func() {
+		audit.Mutation(ctx, audit.ActionUpdate, "vrf", vrf.Name, vrf, before, response, resourceVersion, err)
+	}()
+
 	// check parameters
 	if err := s.validateVrfSpec(vrf); err != nil {
 		return nil, err
@@ -88,13 +101,25 @@ func (s *Server) updateVrf(vrf *pb.Vrf) (*pb.Vrf, error) {
 	if err != nil {
 		return nil, err
 	}
+	domainVrf.Metadata.Audit.UpdatedBy = utils.ClientIdentity(ctx)
 	// Note: The status of the object will be generated in infraDB operation not here
 	if err := infradb.UpdateVrf(domainVrf); err != nil {
 		return nil, err
 	}
+	resourceVersion = domainVrf.ResourceVersion
 	return domainVrf.ToPb(), nil
 }
 
+// resourceVersion returns the resource version of the stored object
+// or an empty string if the object does not exist anymore
+func (s *Server) resourceVersion(name string) string {
+	obj, err := infradb.GetVrf(name)
+	if err != nil {
+		return ""
+	}
+	return obj.ResourceVersion
+}
+
 func resourceIDToFullName(resourceID string) string {
 	return resourcename.Join(
 		"//network.opiproject.org/",
@@ -102,6 +127,15 @@ func resourceIDToFullName(resourceID string) string {
 	)
 }
 
+// fullName converts the relative name that the HTTP gateway takes from the URL path,
+// e.g. "vrfs/{id}", to the full resource name
+func fullName(name string) string {
+	if strings.HasPrefix(name, "vrfs/") {
+		return resourceIDToFullName(strings.TrimPrefix(name, "vrfs/"))
+	}
+	return name
+}
+
 func checkTobeDeletedStatus(vrf *pb.Vrf) error {
 	if vrf.Status.OperStatus == pb.VRFOperStatus_VRF_OPER_STATUS_TO_BE_DELETED {
 		return fmt.Errorf("VRF %s in to be deleted status", vrf.Name)
@@ -122,7 +156,7 @@ type testEnv struct {
 func (e *testEnv) Close() {
 	err := e.conn.Close()
 	if err != nil {
-		log.Fatal(err)
+		logger.Fatal(err)
 	}
 }
 
@@ -158,7 +192,7 @@ func newTestEnv(ctx context.Context, t *testing.T) *testEnv {
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
 		grpc.WithContextDialer(dialer(env.opi)))
 	if err != nil {
-		log.Fatal(err)
+		logger.Fatal(err)
 	}
 	env.conn = conn
 	return env
@@ -172,7 +206,7 @@ func dialer(opi *Server) func(context.Context, string) (net.Conn, error) {
 
 	go func() {
 		if err := server.Serve(listener); err != nil {
-			log.Fatal(err)
+			logger.Fatal(err)
 		}
 	}()
 
@@ -8,111 +8,129 @@ package vrf
 
 import (
 	"context"
-	"log"
 	"reflect"
 
-	"github.com/google/uuid"
+	"github.com/opiproject/opi-evpn-bridge/pkg/logging"
 	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
 
 	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/operations"
 	"go.einride.tech/aip/resourceid"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/emptypb"
 )
 
 // CreateVrf executes the creation of the VRF
-func (s *Server) CreateVrf(_ context.Context, in *pb.CreateVrfRequest) (*pb.Vrf, error) {
+func (s *Server) CreateVrf(ctx context.Context, in *pb.CreateVrfRequest) (*pb.Vrf, error) {
 	// check input correctness
 	if err := s.validateCreateVrfRequest(in); err != nil {
-		log.Printf("CreateVrf(): validation failure: %v", err)
+		logger.Errorw("CreateVrf(): Validation failure", "error", err)
 		return nil, err
 	}
 	// see https://google.aip.dev/133#user-specified-ids
 	resourceID := resourceid.NewSystemGenerated()
 	if in.VrfId != "" {
-		log.Printf("CreateVrf(): client provided the ID of a resource %v, ignoring the name field %v", in.VrfId, in.Vrf.Name)
+		logger.Infow("CreateVrf(): Client provided the ID of a resource, ignoring the name field", "vrfId", in.VrfId, logging.Name(in.Vrf.Name))
 		resourceID = in.VrfId
 	}
 	in.Vrf.Name = resourceIDToFullName(resourceID)
+	op := operations.Ops.Begin(ctx, in.Vrf.Name)
 	// idempotent API when called with same key, should return same object
 	vrfObj, err := s.getVrf(in.Vrf.Name)
 	if err != nil {
 		if err != infradb.ErrKeyNotFound {
-			log.Printf("CreateVrf(): Failed to interact with store: %v", err)
+			logger.Errorw("CreateVrf(): Failed to interact with store", "error", err)
+			op.Abort(err)
 			return nil, err
 		}
 	} else {
-		log.Printf("CreateVrf(): Already existing Vrf with id %v", in.Vrf.Name)
+		logger.Infow("CreateVrf(): Vrf already exists", logging.Name(in.Vrf.Name))
+		op.Done(vrfObj)
 		return vrfObj, nil
 	}
 
 	// Store the domain object into DB
-	response, err := s.createVrf(in.Vrf)
+	response, err := s.createVrf(ctx, in.Vrf)
 	if err != nil {
-		log.Printf("CreateVrf(): Vrf with id %v, Create Vrf to DB failure: %v", in.Vrf.Name, err)
+		logger.Errorw("CreateVrf(): Create Vrf to DB failure", logging.Name(in.Vrf.Name), "error", err)
+		op.Abort(err)
 		return nil, err
 	}
+	op.Commit(s.resourceVersion(response.Name), func() (proto.Message, error) {
+		return s.getVrf(response.Name)
+	})
 	return response, nil
 }
 
 // DeleteVrf deletes a VRF
-func (s *Server) DeleteVrf(_ context.Context, in *pb.DeleteVrfRequest) (*emptypb.Empty, error) {
+func (s *Server) DeleteVrf(ctx context.Context, in *pb.DeleteVrfRequest) (*emptypb.Empty, error) {
 	// check input correctness
 	if err := s.validateDeleteVrfRequest(in); err != nil {
-		log.Printf("DeleteVrf(): validation failure: %v", err)
+		logger.Errorw("DeleteVrf(): Validation failure", "error", err)
 		return nil, err
 	}
+	in.Name = fullName(in.Name)
+	op := operations.Ops.Begin(ctx, in.Name)
 	// fetch object from the database
 	_, err := s.getVrf(in.Name)
 	if err != nil {
 		if err != infradb.ErrKeyNotFound {
-			log.Printf("Failed to interact with store: %v", err)
+			logger.Errorw("DeleteVrf(): Failed to interact with store", "error", err)
+			op.Abort(err)
 			return nil, err
 		}
 		if !in.AllowMissing {
 			err = status.Errorf(codes.NotFound, "unable to find key %s", in.Name)
-			log.Printf("DeleteVrf(): Vrf with id %v: Not Found %v", in.Name, err)
+			logger.Warnw("DeleteVrf(): Vrf not found", logging.Name(in.Name), "error", err)
+			op.Abort(err)
 			return nil, err
 		}
+		op.Done(&emptypb.Empty{})
 		return &emptypb.Empty{}, nil
 	}
 
-	if err := s.deleteVrf(in.Name); err != nil {
-		log.Printf("DeleteVrf(): Vrf with id %v, Delete Vrf from DB failure: %v", in.Name, err)
+	if err := s.deleteVrf(ctx, in.Name); err != nil {
+		logger.Errorw("DeleteVrf(): Delete Vrf from DB failure", logging.Name(in.Name), "error", err)
+		op.Abort(err)
 		return nil, err
 	}
+	op.Commit(s.resourceVersion(in.Name), func() (proto.Message, error) {
+		return &emptypb.Empty{}, nil
+	})
 
 	return &emptypb.Empty{}, nil
 }
 
 // UpdateVrf updates an VRF
-func (s *Server) UpdateVrf(_ context.Context, in *pb.UpdateVrfRequest) (*pb.Vrf, error) {
+func (s *Server) UpdateVrf(ctx context.Context, in *pb.UpdateVrfRequest) (*pb.Vrf, error) {
 	// check input correctness
 	if err := s.validateUpdateVrfRequest(in); err != nil {
-		log.Printf("UpdateVrf(): validation failure: %v", err)
+		logger.Errorw("UpdateVrf(): Validation failure", "error", err)
 		return nil, err
 	}
+	in.Vrf.Name = fullName(in.Vrf.Name)
 	// fetch object from the database
 	vrfObj, err := s.getVrf(in.Vrf.Name)
 	if err != nil {
 		if err != infradb.ErrKeyNotFound {
-			log.Printf("UpdateVrf(): Failed to interact with store: %v", err)
+			logger.Errorw("UpdateVrf(): Failed to interact with store", "error", err)
 			return nil, err
 		}
 		if !in.AllowMissing {
 			err = status.Errorf(codes.NotFound, "unable to find key %s", in.Vrf.Name)
-			log.Printf("UpdateVrf(): Vrf with id %v: Not Found %v", in.Vrf.Name, err)
+			logger.Warnw("UpdateVrf(): Vrf not found", logging.Name(in.Vrf.Name), "error", err)
 			return nil, err
 		}
 
-		log.Printf("UpdateVrf(): Vrf with id %v is not found so it will be created", in.Vrf.Name)
+		logger.Warnw("UpdateVrf(): Vrf is not found so it will be created", logging.Name(in.Vrf.Name))
 
 		// Store the domain object into DB
-		response, err := s.createVrf(in.Vrf)
+		response, err := s.createVrf(ctx, in.Vrf)
 		if err != nil {
-			log.Printf("UpdateVrf(): Vrf with id %v, Create Vrf to DB failure: %v", in.Vrf.Name, err)
+			logger.Errorw("UpdateVrf(): Create Vrf to DB failure", logging.Name(in.Vrf.Name), "error", err)
 			return nil, err
 		}
 		return response, nil
@@ -120,7 +138,7 @@ func (s *Server) UpdateVrf(_ context.Context, in *pb.UpdateVrfRequest) (*pb.Vrf,
 
 	// Check if the object for update is currently in TO_BE_DELETED status
 	if err := checkTobeDeletedStatus(vrfObj); err != nil {
-		log.Printf("UpdateVrf(): Vrf with id %v, Error: %v", in.Vrf.Name, err)
+		logger.Errorw("UpdateVrf(): Vrf cannot be updated", logging.Name(in.Vrf.Name), "error", err)
 		return nil, err
 	}
 
@@ -137,9 +155,15 @@ func (s *Server) UpdateVrf(_ context.Context, in *pb.UpdateVrfRequest) (*pb.Vrf,
 		return vrfObj, nil
 	}
 
-	response, err := s.updateVrf(updatedvrfObj)
+	// Check that the update does not change any immutable field
+	if err := s.validateVrfImmutableFields(vrfObj, updatedvrfObj); err != nil {
+		logger.Errorw("UpdateVrf(): Vrf cannot be updated", logging.Name(in.Vrf.Name), "error", err)
+		return nil, err
+	}
+
+	response, err := s.updateVrf(ctx, updatedvrfObj)
 	if err != nil {
-		log.Printf("UpdateVrf(): Vrf with id %v, Update Vrf to DB failure: %v", in.Vrf.Name, err)
+		logger.Errorw("UpdateVrf(): Update Vrf to DB failure", logging.Name(in.Vrf.Name), "error", err)
 		return nil, err
 	}
 
@@ -150,18 +174,19 @@ func (s *Server) UpdateVrf(_ context.Context, in *pb.UpdateVrfRequest) (*pb.Vrf,
 func (s *Server) GetVrf(_ context.Context, in *pb.GetVrfRequest) (*pb.Vrf, error) {
 	// check input correctness
 	if err := s.validateGetVrfRequest(in); err != nil {
-		log.Printf("GetVrf(): validation failure: %v", err)
+		logger.Errorw("GetVrf(): Validation failure", "error", err)
 		return nil, err
 	}
+	in.Name = fullName(in.Name)
 	// fetch object from the database
 	vrfObj, err := s.getVrf(in.Name)
 	if err != nil {
 		if err != infradb.ErrKeyNotFound {
-			log.Printf("Failed to interact with store: %v", err)
+			logger.Errorw("GetVrf(): Failed to interact with store", "error", err)
 			return nil, err
 		}
 		err = status.Errorf(codes.NotFound, "unable to find key %s", in.Name)
-		log.Printf("GetVrf(): Vrf with id %v: Not Found %v", in.Name, err)
+		logger.Warnw("GetVrf(): Vrf not found", logging.Name(in.Name), "error", err)
 		return nil, err
 	}
 
@@ -169,15 +194,10 @@ func (s *Server) GetVrf(_ context.Context, in *pb.GetVrfRequest) (*pb.Vrf, error
 }
 
 // ListVrfs lists logical bridges
-func (s *Server) ListVrfs(_ context.Context, in *pb.ListVrfsRequest) (*pb.ListVrfsResponse, error) {
+func (s *Server) ListVrfs(ctx context.Context, in *pb.ListVrfsRequest) (*pb.ListVrfsResponse, error) {
 	// check required fields
 	if err := s.validateListVrfsRequest(in); err != nil {
-		log.Printf("ListVrfs(): validation failure: %v", err)
-		return nil, err
-	}
-	// fetch pagination from the database, calculate size and offset
-	size, offset, err := utils.ExtractPagination(in.PageSize, in.PageToken, s.Pagination)
-	if err != nil {
+		logger.Errorw("ListVrfs(): Validation failure", "error", err)
 		return nil, err
 	}
 	// fetch object from the database
@@ -185,21 +205,19 @@ func (s *Server) ListVrfs(_ context.Context, in *pb.ListVrfsRequest) (*pb.ListVr
 	Blobarray, err := s.getAllVrfs()
 	if err != nil {
 		if err != infradb.ErrKeyNotFound {
-			log.Printf("Failed to interact with store: %v", err)
+			logger.Errorw("ListVrfs(): Failed to interact with store", "error", err)
 			return nil, err
 		}
 		err := status.Errorf(codes.NotFound, "Error: %v", err)
-		log.Printf("ListVrfs(): %v", err)
+		logger.Infow("ListVrfs(): No Vrfs found", "error", err)
 		return nil, err
 	}
-	// sort is needed, since MAP is unsorted in golang, and we might get different results
-	sortVrfs(Blobarray)
-	log.Printf("Limiting result len(%d) to [%d:%d]", len(Blobarray), offset, size)
-	Blobarray, hasMoreElements := utils.LimitPagination(Blobarray, offset, size)
-	token := ""
-	if hasMoreElements {
-		token = uuid.New().String()
-		s.Pagination[token] = offset + size
+	// filter, sort and paginate, since MAP is unsorted in golang, and we might get different results
+	filter, orderBy := utils.ExtractListOptions(ctx)
+	Blobarray, token, err := utils.ListPage(Blobarray, in.PageSize, in.PageToken, filter, orderBy)
+	if err != nil {
+		logger.Infow("ListVrfs(): Invalid list options", "error", err)
+		return nil, err
 	}
 	return &pb.ListVrfsResponse{Vrfs: Blobarray, NextPageToken: token}, nil
 }
@@ -16,6 +16,7 @@ import (
 	"google.golang.org/grpc/status"
 
 	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
 )
 
 func (s *Server) validateCreateVrfRequest(in *pb.CreateVrfRequest) error {
@@ -38,7 +39,25 @@ func (s *Server) validateVrfSpec(vrf *pb.Vrf) error {
 		msg := fmt.Sprintf("Vni value (%d) have to be between 1 and 16777215", *vrf.Spec.Vni)
 		return status.Errorf(codes.InvalidArgument, msg)
 	}
-	// Dimitris: Do we need to validate the loopback_ip_prefix, vtep_ip_prefix ?
+	// Validate that the loopback and vtep IPs are valid IPv4 or IPv6 prefixes
+	if _, err := common.ConvertToIPNet(vrf.Spec.LoopbackIpPrefix); err != nil {
+		msg := fmt.Sprintf("Invalid loopback IP prefix: %v", err)
+		return status.Errorf(codes.InvalidArgument, msg)
+	}
+	if vrf.Spec.VtepIpPrefix != nil {
+		if _, err := common.ConvertToIPNet(vrf.Spec.VtepIpPrefix); err != nil {
+			msg := fmt.Sprintf("Invalid VTEP IP prefix: %v", err)
+			return status.Errorf(codes.InvalidArgument, msg)
+		}
+	}
+	return nil
+}
+
+func (s *Server) validateVrfImmutableFields(stored, updated *pb.Vrf) error {
+	if (stored.Spec.Vni == nil) != (updated.Spec.Vni == nil) || stored.Spec.GetVni() != updated.Spec.GetVni() {
+		msg := fmt.Sprintf("Vni of VRF %v cannot be changed", stored.Name)
+		return status.Errorf(codes.InvalidArgument, msg)
+	}
 	return nil
 }
 
@@ -8,6 +8,7 @@ package vrf
 import (
 	"context"
 	"fmt"
+	"net"
 	"reflect"
 	"testing"
 
@@ -62,6 +63,37 @@ var (
 			Components: []*pb.Component{{Name: "dummy", Status: pb.CompStatus_COMP_STATUS_PENDING}},
 		},
 	}
+	testVrfIPv6 = pb.Vrf{
+		Spec: &pb.VrfSpec{
+			Vni: proto.Uint32(1000),
+			LoopbackIpPrefix: &pc.IPPrefix{
+				Addr: &pc.IPAddress{
+					Af: pc.IpAf_IP_AF_INET6,
+					V4OrV6: &pc.IPAddress_V6Addr{
+						V6Addr: net.ParseIP("2001:db8::1"),
+					},
+				},
+				Len: 128,
+			},
+			VtepIpPrefix: &pc.IPPrefix{
+				Addr: &pc.IPAddress{
+					Af: pc.IpAf_IP_AF_INET6,
+					V4OrV6: &pc.IPAddress_V6Addr{
+						V6Addr: net.ParseIP("2001:db8:1::1"),
+					},
+				},
+				Len: 64,
+			},
+		},
+	}
+	testVrfIPv6WithStatus = pb.Vrf{
+		Name: testVrfName,
+		Spec: testVrfIPv6.Spec,
+		Status: &pb.VrfStatus{
+			OperStatus: pb.VRFOperStatus_VRF_OPER_STATUS_DOWN,
+			Components: []*pb.Component{{Name: "dummy", Status: pb.CompStatus_COMP_STATUS_PENDING}},
+		},
+	}
 )
 
 func Test_CreateVrf(t *testing.T) {
@@ -166,6 +198,36 @@ func Test_CreateVrf(t *testing.T) {
 			exist:   false,
 			on:      nil,
 		},
+		"successful call ipv6": {
+			id:      testVrfID,
+			in:      &testVrfIPv6,
+			out:     &testVrfIPv6WithStatus,
+			errCode: codes.OK,
+			errMsg:  "",
+			exist:   false,
+			on:      nil,
+		},
+		"invalid ipv6 loopback_ip_prefix": {
+			id: testVrfID,
+			in: &pb.Vrf{
+				Spec: &pb.VrfSpec{
+					LoopbackIpPrefix: &pc.IPPrefix{
+						Addr: &pc.IPAddress{
+							Af: pc.IpAf_IP_AF_INET6,
+							V4OrV6: &pc.IPAddress_V6Addr{
+								V6Addr: []byte{0x20, 0x01, 0x0d, 0xb8},
+							},
+						},
+						Len: 64,
+					},
+				},
+			},
+			out:     nil,
+			errCode: codes.InvalidArgument,
+			errMsg:  "Invalid loopback IP prefix: invalid ipv6 address length 4",
+			exist:   false,
+			on:      nil,
+		},
 	}
 
 	// run tests
@@ -181,7 +243,7 @@ func Test_CreateVrf(t *testing.T) {
 					Name: testVrfName,
 					Spec: testVrf.Spec,
 				}
-				_, _ = env.opi.createVrf(&testVrfFull)
+				_, _ = env.opi.createVrf(ctx, &testVrfFull)
 			}
 			if tt.out != nil {
 				tt.out = utils.ProtoClone(tt.out)
@@ -267,7 +329,7 @@ func Test_DeleteVrf(t *testing.T) {
 				Name: testVrfName,
 				Spec: testVrf.Spec,
 			}
-			_, _ = env.opi.createVrf(&testVrfFull)
+			_, _ = env.opi.createVrf(ctx, &testVrfFull)
 			if tt.on != nil {
 				tt.on(env.mockNetlink, env.mockFrr, tt.errMsg)
 			}
@@ -339,6 +401,22 @@ func Test_UpdateVrf(t *testing.T) {
 			start:   false,
 			exist:   true,
 		},
+		"immutable vni": {
+			mask: nil,
+			in: &pb.Vrf{
+				Name: testVrfName,
+				Spec: &pb.VrfSpec{
+					Vni:              proto.Uint32(1001),
+					LoopbackIpPrefix: testVrf.Spec.LoopbackIpPrefix,
+					VtepIpPrefix:     testVrf.Spec.VtepIpPrefix,
+				},
+			},
+			out:     nil,
+			errCode: codes.InvalidArgument,
+			errMsg:  fmt.Sprintf("Vni of VRF %v cannot be changed", testVrfName),
+			start:   false,
+			exist:   true,
+		},
 	}
 
 	// run tests
@@ -354,7 +432,7 @@ func Test_UpdateVrf(t *testing.T) {
 					Name: testVrfName,
 					Spec: testVrf.Spec,
 				}
-				_, _ = env.opi.createVrf(&testVrfFull)
+				_, _ = env.opi.createVrf(ctx, &testVrfFull)
 			}
 			if tt.out != nil {
 				tt.out = utils.ProtoClone(tt.out)
@@ -423,7 +501,7 @@ func Test_GetVrf(t *testing.T) {
 				Name: testVrfName,
 				Spec: testVrf.Spec,
 			}
-			_, _ = env.opi.createVrf(&testVrfFull)
+			_, _ = env.opi.createVrf(ctx, &testVrfFull)
 
 			request := &pb.GetVrfRequest{Name: tt.in}
 			response, err := client.GetVrf(ctx, request)
@@ -473,8 +551,8 @@ func Test_ListVrfs(t *testing.T) {
 		"pagination error": {
 			in:      "",
 			out:     nil,
-			errCode: codes.NotFound,
-			errMsg:  fmt.Sprintf("unable to find pagination token %s", "unknown-pagination-token"),
+			errCode: codes.InvalidArgument,
+			errMsg:  fmt.Sprintf("invalid pagination token %s", "unknown-pagination-token"),
 			size:    0,
 			token:   "unknown-pagination-token",
 		},
@@ -516,10 +594,14 @@ func Test_ListVrfs(t *testing.T) {
 				Name: testVrfName,
 				Spec: testVrf.Spec,
 			}
-			_, _ = env.opi.createVrf(&testVrfFull)
-			env.opi.Pagination["existing-pagination-token"] = 1
+			_, _ = env.opi.createVrf(ctx, &testVrfFull)
+			token := tt.token
+			if token == "existing-pagination-token" {
+				// cursor positioned after the only object in the store
+				token, _ = utils.PageTokenAfter(&testVrfFull, "", "")
+			}
 
-			request := &pb.ListVrfsRequest{PageSize: tt.size, PageToken: tt.token}
+			request := &pb.ListVrfsRequest{PageSize: tt.size, PageToken: token}
 			response, err := client.ListVrfs(ctx, request)
 			if !utils.EqualProtoSlices(response.GetVrfs(), tt.out) {
 				t.Error("response: expected", tt.out, "received", response.GetVrfs())